@@ -0,0 +1,199 @@
+// Package alerts evaluates alert rules against completed runs and sends
+// the alerts that fire to notification channels, without repeating an
+// alert while its condition keeps holding.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/notify"
+	"github.com/lesnerd/fair-stock-value/go/screener"
+)
+
+// Alert is a rule that fired for one stock
+type Alert struct {
+	Rule      string  `json:"rule"`
+	Ticker    string  `json:"ticker"`
+	Reason    string  `json:"reason"`
+	Status    string  `json:"status"`
+	Price     float64 `json:"price"`
+	FairValue float64 `json:"fair_value"`
+	Upside    float64 `json:"upside"`
+}
+
+// String formats the alert as one line
+func (a Alert) String() string {
+	return fmt.Sprintf("%s: %s (price $%.2f, fair value $%.2f, upside %.1f%%) [%s]",
+		a.Ticker, a.Reason, a.Price, a.FairValue, a.Upside, a.Rule)
+}
+
+// rule is a parsed alert rule
+type rule struct {
+	config.AlertRule
+	criteria screener.Criteria
+	tickers  map[string]bool // nil for any stock
+}
+
+// Engine evaluates alert rules after each run
+type Engine struct {
+	rules     []rule
+	notifiers []notify.Notifier
+	repeat    time.Duration
+	statePath string
+	mutex     sync.Mutex
+}
+
+// New creates an engine from the alerts configuration, keeping its state
+// in statePath. It returns nil when no rules are configured.
+func New(cfg config.AlertsConfig, statePath string) (*Engine, error) {
+	if len(cfg.Rules) == 0 {
+		return nil, nil
+	}
+
+	notifiers, err := notify.NewAll(cfg.Channels)
+	if err != nil {
+		return nil, fmt.Errorf("alerts: %w", err)
+	}
+
+	engine := &Engine{
+		notifiers: notifiers,
+		repeat:    time.Duration(cfg.RepeatHours) * time.Hour,
+		statePath: statePath,
+	}
+	for _, r := range cfg.Rules {
+		criteria, err := screener.Parse(r.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %q: %w", r.Name, err)
+		}
+
+		parsed := rule{AlertRule: r, criteria: criteria}
+		if len(r.Tickers) > 0 {
+			parsed.tickers = make(map[string]bool, len(r.Tickers))
+			for _, ticker := range r.Tickers {
+				parsed.tickers[strings.ToUpper(ticker)] = true
+			}
+		}
+		engine.rules = append(engine.rules, parsed)
+	}
+	return engine, nil
+}
+
+// Evaluate checks every rule against the results of run and sends the
+// alerts that fire. An alert is sent when its rule starts matching a stock
+// and again only after the repeat interval while the rule keeps matching.
+// Alerts are not marked as sent when every channel failed, so they are
+// retried after the next run. Stocks missing from run keep their state.
+func (e *Engine) Evaluate(ctx context.Context, run *models.Run) ([]Alert, []error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	st, err := loadState(e.statePath)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	now := run.FinishedAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var alerts []Alert
+	var keys []string
+	for _, result := range run.Results {
+		// A failed valuation neither fires nor clears alerts
+		if result.Failed() {
+			continue
+		}
+		previousStatus, known := st.Statuses[result.Ticker]
+		for _, r := range e.rules {
+			if r.tickers != nil && !r.tickers[result.Ticker] {
+				continue
+			}
+
+			key := r.Name + "/" + result.Ticker
+			reason, matched := r.match(result, previousStatus, known)
+			if !matched {
+				delete(st.Active, key)
+				continue
+			}
+
+			lastSent, active := st.Active[key]
+			if active && (e.repeat == 0 || now.Sub(lastSent) < e.repeat) {
+				continue
+			}
+			alerts = append(alerts, newAlert(r.Name, reason, result))
+			keys = append(keys, key)
+		}
+		st.Statuses[result.Ticker] = result.Status
+	}
+
+	var errs []error
+	if len(alerts) > 0 {
+		errs = notify.NotifyAll(ctx, e.notifiers, message(alerts))
+	}
+	if len(e.notifiers) == 0 || len(errs) < len(e.notifiers) {
+		for _, key := range keys {
+			st.Active[key] = now
+		}
+	}
+
+	if err := st.save(e.statePath); err != nil {
+		errs = append(errs, err)
+	}
+	return alerts, errs
+}
+
+// match reports whether the rule holds for result and why
+func (r rule) match(result *models.ValuationResult, previousStatus string, known bool) (string, bool) {
+	var reasons []string
+	if r.StatusTo != "" {
+		// A stock seen for the first time has no status to change from
+		if !known || previousStatus == result.Status || result.Status != r.StatusTo {
+			return "", false
+		}
+		if r.StatusFrom != "" && previousStatus != r.StatusFrom {
+			return "", false
+		}
+		reasons = append(reasons, fmt.Sprintf("changed from %s to %s", previousStatus, result.Status))
+	}
+	if len(r.criteria) > 0 {
+		if !r.criteria.Match(result) {
+			return "", false
+		}
+		reasons = append(reasons, r.criteria.String())
+	}
+	return strings.Join(reasons, ", "), true
+}
+
+// newAlert describes a fired rule
+func newAlert(rule, reason string, result *models.ValuationResult) Alert {
+	return Alert{
+		Rule:      rule,
+		Ticker:    result.Ticker,
+		Reason:    reason,
+		Status:    result.Status,
+		Price:     result.CurrentPrice,
+		FairValue: result.FairValue,
+		Upside:    result.UpsidePercentage,
+	}
+}
+
+// message combines the alerts of one run into a notification
+func message(alerts []Alert) notify.Message {
+	subject := fmt.Sprintf("Fair Stock Value: %d alerts", len(alerts))
+	if len(alerts) == 1 {
+		subject = fmt.Sprintf("Fair Stock Value alert: %s", alerts[0].Ticker)
+	}
+
+	lines := make([]string, len(alerts))
+	for i, alert := range alerts {
+		lines[i] = alert.String()
+	}
+	return notify.Message{Subject: subject, Text: strings.Join(lines, "\n"), Data: alerts}
+}
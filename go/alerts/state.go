@@ -0,0 +1,61 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// state is what the engine remembers between runs
+type state struct {
+	// Active maps "rule/ticker" to when the alert was last sent, for alerts
+	// whose condition still held at the last run
+	Active map[string]time.Time `json:"active"`
+
+	// Statuses is the last known status of each stock
+	Statuses map[string]string `json:"statuses"`
+}
+
+// loadState reads the state file; a missing file is an empty state
+func loadState(path string) (*state, error) {
+	st := &state{Active: make(map[string]time.Time), Statuses: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert state: %w", err)
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("failed to parse alert state %s: %w", path, err)
+	}
+	if st.Active == nil {
+		st.Active = make(map[string]time.Time)
+	}
+	if st.Statuses == nil {
+		st.Statuses = make(map[string]string)
+	}
+	return st, nil
+}
+
+// save writes the state file
+func (st *state) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create alert state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode alert state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write alert state: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
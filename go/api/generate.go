@@ -0,0 +1,17 @@
+// Package api holds the definitions of the APIs of server mode: the
+// protobuf definitions of the gRPC API and the OpenAPI document of the
+// REST API. The Go code in fairvaluepb is generated from proto/ with buf,
+// protoc-gen-go and protoc-gen-go-grpc; the REST client in client is
+// generated from openapi.yaml with oapi-codegen.
+package api
+
+import _ "embed"
+
+//go:generate buf generate
+//go:generate oapi-codegen -config oapi-codegen.yaml openapi.yaml
+
+// OpenAPI is the OpenAPI 3 document of the REST API, served at
+// /openapi.yaml
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
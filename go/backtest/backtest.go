@@ -0,0 +1,229 @@
+// Package backtest replays recorded analysis runs to measure how stocks
+// the model found undervalued went on to perform against a benchmark.
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/valuation"
+)
+
+// Options controls a backtest
+type Options struct {
+	Horizon   time.Duration // holding period of each pick
+	MinUpside float64       // minimum upside, in percent, for a stock to be picked
+
+	// Benchmark is the ticker whose return picks are compared with. When
+	// empty, the benchmark is the equal-weighted return of every stock
+	// valued at the formation date.
+	Benchmark string
+
+	// Calculator, when set, re-values the recorded fundamentals with its
+	// parameters instead of using the recorded fair values
+	Calculator *valuation.Calculator
+}
+
+// Pick is a stock picked as undervalued at a formation date
+type Pick struct {
+	Ticker     string  `json:"ticker"`
+	Upside     float64 `json:"upside"` // percent, at formation
+	EntryPrice float64 `json:"entry_price"`
+	ExitPrice  float64 `json:"exit_price"`
+	Return     float64 `json:"return"` // percent
+}
+
+// Period is one holding period starting at a recorded run
+type Period struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"` // date of the run the exit prices come from
+	Picks           []Pick    `json:"picks"`
+	Return          float64   `json:"return"`           // equal-weighted return of the picks, percent
+	BenchmarkReturn float64   `json:"benchmark_return"` // percent
+}
+
+// Excess returns the return of the picks above the benchmark
+func (p Period) Excess() float64 {
+	return p.Return - p.BenchmarkReturn
+}
+
+// Report summarizes a backtest
+type Report struct {
+	Runs      int      `json:"runs"` // recorded runs replayed
+	Benchmark string   `json:"benchmark"`
+	Periods   []Period `json:"periods"`
+
+	// Compounded returns over all periods, percent
+	TotalReturn          float64 `json:"total_return"`
+	TotalBenchmarkReturn float64 `json:"total_benchmark_return"`
+
+	// HitRate is the share of picks that beat the benchmark over their period
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Run backtests the model over runs. Holding periods do not overlap: each
+// starts at the first run on or after the end of the previous one and ends
+// at the first run at least opts.Horizon later, whose prices are the exit
+// prices.
+func Run(runs []*models.Run, opts Options) (*Report, error) {
+	if opts.Horizon <= 0 {
+		return nil, fmt.Errorf("horizon must be positive")
+	}
+
+	runs = append([]*models.Run(nil), runs...)
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+
+	report := &Report{Runs: len(runs), Benchmark: opts.Benchmark}
+	if report.Benchmark == "" {
+		report.Benchmark = "equal-weighted universe"
+	}
+
+	totalGrowth, benchmarkGrowth := 1.0, 1.0
+	picks, hits := 0, 0
+	for start := 0; start < len(runs); {
+		end := exitRun(runs, start, opts.Horizon)
+		if end < 0 {
+			break
+		}
+
+		period, ok := evaluate(runs[start], runs[end], opts)
+		if ok {
+			report.Periods = append(report.Periods, period)
+			totalGrowth *= 1 + period.Return/100
+			benchmarkGrowth *= 1 + period.BenchmarkReturn/100
+			for _, pick := range period.Picks {
+				picks++
+				if pick.Return > period.BenchmarkReturn {
+					hits++
+				}
+			}
+		}
+		start = end
+	}
+
+	if len(report.Periods) == 0 {
+		return nil, fmt.Errorf("not enough history: need runs at least %.0f days apart with stocks in common", opts.Horizon.Hours()/24)
+	}
+
+	report.TotalReturn = (totalGrowth - 1) * 100
+	report.TotalBenchmarkReturn = (benchmarkGrowth - 1) * 100
+	if picks > 0 {
+		report.HitRate = float64(hits) / float64(picks)
+	}
+	return report, nil
+}
+
+// exitRun returns the index of the first run at least horizon after the
+// run at start, or -1
+func exitRun(runs []*models.Run, start int, horizon time.Duration) int {
+	target := runs[start].StartedAt.Add(horizon)
+	for i := start + 1; i < len(runs); i++ {
+		if !runs[i].StartedAt.Before(target) {
+			return i
+		}
+	}
+	return -1
+}
+
+// evaluate picks undervalued stocks from the entry run and measures their
+// returns to the exit run. It reports false when no return can be measured.
+func evaluate(entry, exit *models.Run, opts Options) (Period, bool) {
+	period := Period{Start: entry.StartedAt, End: exit.StartedAt}
+
+	exitPrices := make(map[string]float64, len(exit.Results))
+	for _, result := range exit.Results {
+		if result.CurrentPrice > 0 {
+			exitPrices[result.Ticker] = result.CurrentPrice
+		}
+	}
+
+	var universeReturns []float64
+	benchmarkFound := false
+	for _, recorded := range entry.Results {
+		exitPrice, ok := exitPrices[recorded.Ticker]
+		if !ok || recorded.CurrentPrice <= 0 {
+			continue
+		}
+		ret := (exitPrice - recorded.CurrentPrice) / recorded.CurrentPrice * 100
+
+		if opts.Benchmark != "" && recorded.Ticker == opts.Benchmark {
+			period.BenchmarkReturn = ret
+			benchmarkFound = true
+			continue
+		}
+		universeReturns = append(universeReturns, ret)
+
+		result := revalue(recorded, entry.StartedAt, opts.Calculator)
+		if result.Status != models.StatusUnderpriced || result.UpsidePercentage < opts.MinUpside {
+			continue
+		}
+		period.Picks = append(period.Picks, Pick{
+			Ticker:     recorded.Ticker,
+			Upside:     result.UpsidePercentage,
+			EntryPrice: recorded.CurrentPrice,
+			ExitPrice:  exitPrice,
+			Return:     ret,
+		})
+	}
+
+	if len(universeReturns) == 0 || (opts.Benchmark != "" && !benchmarkFound) {
+		return period, false
+	}
+	if opts.Benchmark == "" {
+		period.BenchmarkReturn = mean(universeReturns)
+	}
+
+	returns := make([]float64, len(period.Picks))
+	for i, pick := range period.Picks {
+		returns[i] = pick.Return
+	}
+	// With no picks the period is spent in cash
+	period.Return = mean(returns)
+
+	sort.Slice(period.Picks, func(i, j int) bool {
+		return period.Picks[i].Upside > period.Picks[j].Upside
+	})
+	return period, true
+}
+
+// revalue returns the recorded valuation, or re-values the recorded
+// fundamentals with calculator when one is given. Results recording their
+// inputs are re-valued from those; older ones from their own fields.
+func revalue(r *models.ValuationResult, at time.Time, calculator *valuation.Calculator) *models.ValuationResult {
+	if calculator == nil {
+		return r
+	}
+	if r.Inputs != nil {
+		return calculator.CalculateFairValue(r.Inputs)
+	}
+
+	return calculator.CalculateFairValue(&models.StockData{
+		Ticker:       r.Ticker,
+		CompanyName:  r.CompanyName,
+		CurrentPrice: r.CurrentPrice,
+		FCFPerShare:  r.FCFPerShare,
+		EPS:          r.EPS,
+		BookValue:    r.BookValue,
+		Sector:       r.Sector,
+		GrowthRate:   r.GrowthRate,
+		PERatio:      r.PERatio,
+		MarketCap:    r.MarketCap,
+		FetchTime:    at,
+	})
+}
+
+// mean returns the average of values, or 0 for none
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
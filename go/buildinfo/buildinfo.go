@@ -0,0 +1,65 @@
+// Package buildinfo reports the version of the running binary, so runs
+// can be traced back to the code that produced them.
+package buildinfo
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// version overrides the detected version when set at build time:
+//
+//	go build -ldflags "-X github.com/lesnerd/fair-stock-value/go/buildinfo.version=v1.2.0" ./cmd/fair-stock-value
+var version string
+
+var (
+	once     sync.Once
+	resolved string
+)
+
+// Version returns the version set at build time, else the git revision
+// the Go toolchain recorded (with a "-dirty" suffix for uncommitted
+// changes), else the module version, else "devel"
+func Version() string {
+	once.Do(func() {
+		resolved = detect()
+	})
+	return resolved
+}
+
+// detect derives the version from the build information
+func detect() string {
+	if version != "" {
+		return version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" {
+		if len(revision) > 12 {
+			revision = revision[:12]
+		}
+		if modified {
+			revision += "-dirty"
+		}
+		return revision
+	}
+
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "devel"
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/backtest"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+	"github.com/lesnerd/fair-stock-value/go/valuation"
+)
+
+// runBacktest replays recorded runs and reports forward returns of the
+// stocks the model found undervalued
+func runBacktest(ctx context.Context, args []string) error {
+	fs := newFlagSet("backtest")
+	cfgFlags := registerConfigFlags(fs)
+	runsDir := fs.String("runs-dir", "", "Directory of runs stored as JSON files to replay (default: the configured history)")
+	from := fs.String("from", "", "Replay runs from a JSON-lines file written by a jsonl sink instead")
+	archive := fs.String("archive", "", "Replay runs from a directory written by an archive sink instead")
+	horizonDays := fs.Int("horizon", 30, "Holding period of each pick in days")
+	minUpside := fs.Float64("min-upside", 0, "Minimum upside percentage for a stock to be picked")
+	benchmark := fs.String("benchmark", "", "Benchmark ticker recorded in the runs (default: equal-weighted universe)")
+	recorded := fs.Bool("recorded", false, "Use the recorded fair values instead of re-valuing with the current parameters")
+	showColors := fs.Bool("colors", true, "Enable colored output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *horizonDays <= 0 {
+		return fmt.Errorf("-horizon must be positive")
+	}
+
+	cfg, err := cfgFlags.load(fs)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	store, err := openRecordedRuns(cfg, *from, *archive, *runsDir)
+	if err != nil {
+		return err
+	}
+
+	runs, err := storage.ListTagged(store, cfg.RunTags)
+	if err != nil {
+		return err
+	}
+
+	// Runs interrupted part-way would skew the universe they represent
+	complete := runs[:0]
+	for _, run := range runs {
+		if !run.Partial {
+			complete = append(complete, run)
+		}
+	}
+
+	opts := backtest.Options{
+		Horizon:   time.Duration(*horizonDays) * 24 * time.Hour,
+		MinUpside: *minUpside,
+		Benchmark: strings.ToUpper(*benchmark),
+	}
+	if !*recorded {
+		opts.Calculator = valuation.NewCalculator()
+		opts.Calculator.SetDCFParameters(cfg.DCFParams)
+		opts.Calculator.SetCompsParameters(cfg.CompsParams)
+		opts.Calculator.SetWeights(cfg.Weights)
+		opts.Calculator.SetDecimalMoney(cfg.Processing.DecimalMoney)
+	}
+
+	report, err := backtest.Run(complete, opts)
+	if err != nil {
+		return fmt.Errorf("%d complete runs recorded: %w", len(complete), err)
+	}
+
+	utils.DisplayBacktest(report, *showColors)
+	return nil
+}
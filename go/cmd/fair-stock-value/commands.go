@@ -0,0 +1,813 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/buildinfo"
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/jobs"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/screener"
+	"github.com/lesnerd/fair-stock-value/go/services"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+// command is a CLI subcommand
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(ctx context.Context, args []string) error
+	hidden      bool // omitted from help and completion
+}
+
+// allCommands returns every subcommand in the order shown by help
+func allCommands() []command {
+	return []command{
+		{"analyze", "analyze [options] [TICKER...]", "Value the ticker universe, or just the given tickers in detail (default command)", runAnalyze, false},
+		{"fetch", "fetch [options] [TICKER...]", "Fetch the data of the universe into the cache for later -cache-only runs, continuing where an interrupted fetch stopped", runFetch, false},
+		{"quote", "quote [options] TICKER...", "Show fetched market and fundamental data without valuing", runQuote, false},
+		{"screen", "screen [options] [CONDITION...]", "Value the universe and list stocks matching conditions such as upside>20 pe<15", runScreen, false},
+		{"compare", "compare [options] TICKER TICKER...", "Compare the valuation of several tickers side by side", runCompare, false},
+		{"profiles", "profiles [options] [PROFILE PROFILE...]", "Value the universe under several assumption profiles, such as conservative and aggressive, side by side", runProfiles, false},
+		{"explain", "explain [options] TICKER", "Show every step of the valuation for one ticker", runExplain, false},
+		{"backtest", "backtest [options]", "Replay recorded runs and compare returns of undervalued picks with a benchmark", runBacktest, false},
+		{"portfolio", "portfolio -file HOLDINGS.csv [options]", "Value a portfolio of holdings and suggest rebalancing candidates", runPortfolio, false},
+		{"repl", "repl [options]", "Start an interactive session for tweaking assumptions", runREPL, false},
+		{"serve", "serve [options]", "Serve valuations over a REST API", runServe, false},
+		{"telegram", "telegram [options]", "Answer /value and /screen commands sent to a Telegram bot", runTelegram, false},
+		{"cache", "cache stats|list|clear|skipped|unskip", "Inspect or clear the stock data cache and the skip list of failing tickers", runCache, false},
+		{"config", "config show|init|validate|selectors [options]", "Show, create or validate a configuration file, or show the scraper selectors", runConfig, false},
+		{"history", "history [options] TICKER | -tags", "Show past valuations of a ticker from recorded runs", runHistory, false},
+		{"notes", "notes [options] add|list|delete ...", "Keep notes and ratings on tickers that later runs show with their results", runNotes, false},
+		{"trends", "trends [options] [TICKER...]", "Chart fair value against price over recorded runs as an HTML report", runTrends, false},
+		{"completion", "completion bash|zsh|fish", "Print a shell completion script", runCompletion, false},
+		{"version", "version", "Print the build version recorded with each run", runVersion, false},
+		{"help", "help [command]", "Show help for a command", runHelp, false},
+		{"__complete", "__complete WORD...", "Print completion candidates for the shell scripts", runComplete, true},
+	}
+}
+
+// lookupCommand finds a subcommand by name
+func lookupCommand(name string) (command, bool) {
+	for _, cmd := range allCommands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// usageHook, when set, replaces printing a command's help. Completion uses
+// it to capture a command's flag set by running the command with -help.
+var usageHook func(fs *flag.FlagSet)
+
+// newFlagSet creates a flag set whose usage prints the command's help
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		if usageHook != nil {
+			usageHook(fs)
+			return
+		}
+		showCommandHelp(fs)
+	}
+	return fs
+}
+
+// runAnalyze values the whole ticker universe and prints the results table
+func runAnalyze(ctx context.Context, args []string) error {
+	fs := newFlagSet("analyze")
+	cfgFlags := registerConfigFlags(fs)
+	outFlags := registerOutputFlags(fs)
+	watch := fs.Bool("watch", false, "Keep running and periodically re-analyze")
+	watchInterval := fs.Duration("watch-interval", 0, "Time between price refreshes in watch mode (default from config, 5m)")
+	fundamentalsInterval := fs.Duration("fundamentals-interval", 0, "Time between full fundamental re-fetches in watch mode (default from config, 6h)")
+	marketHours := fs.Bool("market-hours", false, "Pause watch mode refreshes while the configured market is closed")
+	resume := fs.Bool("resume", false, "Resume an interrupted run, skipping tickers valued within the cache expiry")
+	retrySkipped := fs.Bool("retry-skipped", false, "Also value the tickers on the skip list for failing the runs before")
+	refresh := fs.String("refresh", "", "Which tickers to fetch: stale (only those without data recorded or cached within the cache expiry) or all")
+	watchlist := fs.String("watchlist", "", "Comma-separated tickers to value ahead of the rest of the universe (default from config)")
+	stopAfter := fs.Int("stop-after", 0, "Stop once this many underpriced stocks are found (0 = value the whole universe)")
+	schedule := fs.Bool("schedule", false, "Keep running the jobs in the configured schedule")
+	format := fs.String("format", "table", "Output format: table, csv, json or parquet (written to -output)")
+	outputPath := fs.String("output", "", "File the csv, json or parquet output is written to (default fair-value-<run ID>.<format>)")
+	withInputs := fs.Bool("with-inputs", false, "Add the fetched fundamentals of each ticker to parquet output")
+	stream := fs.Bool("stream", false, "Read tickers and write results to -output as they complete, for very large universes")
+	profFlags := registerProfileFlags(fs)
+	tickers, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	output, err := newExportOptions(*format, *outputPath, *withInputs)
+	if err != nil {
+		return err
+	}
+	if *stream && output == nil {
+		return fmt.Errorf("-stream requires -format csv, json or parquet")
+	}
+	if *stopAfter < 0 {
+		return fmt.Errorf("-stop-after must not be negative")
+	}
+	if *stopAfter > 0 && (*watch || *schedule || *stream) {
+		return fmt.Errorf("-stop-after cannot be combined with -watch, -schedule or -stream")
+	}
+
+	cfg, err := cfgFlags.load(fs)
+	if err != nil {
+		return err
+	}
+	if cfg.Processing.CacheOnly && (*watch || *schedule) {
+		return fmt.Errorf("-cache-only cannot be combined with -watch or -schedule, which refresh prices")
+	}
+	outFlags.apply(fs, cfg)
+	if *watchInterval > 0 {
+		cfg.Watch.IntervalSeconds = int(watchInterval.Seconds())
+	}
+	if *fundamentalsInterval > 0 {
+		cfg.Watch.FundamentalsIntervalMinutes = int(fundamentalsInterval.Minutes())
+	}
+	if *marketHours {
+		cfg.Watch.MarketHoursOnly = true
+	}
+	if *watchlist != "" {
+		cfg.DataSources.Watchlist = normalizeTickers(strings.Split(*watchlist, ","))
+	}
+
+	app, err := NewApplication(cfg)
+	if err != nil {
+		return err
+	}
+	app.resume = *resume
+	app.retrySkipped = *retrySkipped
+	app.output = output
+	app.stopAfter = *stopAfter
+	if err := app.setRefresh(*refresh); err != nil {
+		return err
+	}
+
+	stopTelemetry, err := startTelemetry(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer stopTelemetry()
+
+	stopProfiling, err := profFlags.start()
+	if err != nil {
+		return err
+	}
+	defer stopProfiling()
+
+	if *watch || *schedule {
+		if output != nil {
+			return fmt.Errorf("-format %s cannot be combined with -watch or -schedule", *format)
+		}
+
+		// Tickers given on the command line become the watched universe
+		if len(tickers) > 0 {
+			cfg.DataSources.Tickers = normalizeTickers(tickers)
+		}
+
+		if *schedule {
+			return app.RunSchedule(ctx, true)
+		}
+		return app.Watch(ctx)
+	}
+
+	if *stream {
+		if len(tickers) > 0 {
+			cfg.DataSources.Tickers = normalizeTickers(tickers)
+		}
+		return app.RunStream(ctx)
+	}
+
+	// Quick mode: value only the named tickers with full detail
+	if len(tickers) > 0 {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+
+		startedAt := app.analyzer.Now()
+		valuations := app.analyzer.ValuateAll(ctx, normalizeTickers(tickers))
+		if output != nil {
+			run := app.analyzer.NewRun(startedAt, valuations)
+			app.analyzer.Stamp(run)
+			app.annotate(run.Results)
+			if err := app.export(run, output); err != nil {
+				return err
+			}
+		} else {
+			app.DisplayDetails(valuations)
+		}
+		return ctx.Err()
+	}
+
+	return app.Run(ctx)
+}
+
+// runScreen values the universe and shows only stocks matching the criteria
+func runScreen(ctx context.Context, args []string) error {
+	fs := newFlagSet("screen")
+	cfgFlags := registerConfigFlags(fs)
+	outFlags := registerOutputFlags(fs)
+	minUpside := fs.Float64("min-upside", 0, "Minimum upside percentage")
+	maxPE := fs.Float64("max-pe", 0, "Maximum P/E ratio (0 = no limit)")
+	maxPEG := fs.Float64("max-peg", 0, "Maximum PEG ratio (0 = no limit)")
+	minMarketCap := fs.Float64("min-market-cap", 0, "Minimum market capitalization in dollars")
+	sector := fs.String("sector", "", "Only include stocks in this sector")
+	name := fs.String("screen", "", "Run a screen saved in the configuration")
+	save := fs.String("save", "", "Save the conditions as a named screen in the -config file")
+	list := fs.Bool("list", false, "List the saved screens")
+	changes := fs.Bool("changes", false, "Only report stocks that entered or left the screen since it last ran")
+	every := fs.Duration("every", 0, "Re-run the screen at this interval, reporting membership changes")
+	resume := fs.Bool("resume", false, "Resume an interrupted run, skipping tickers valued within the cache expiry")
+	retrySkipped := fs.Bool("retry-skipped", false, "Also value the tickers on the skip list for failing the runs before")
+	profFlags := registerProfileFlags(fs)
+	conditions, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	// Screens are about finding ideas, so default to underpriced only
+	if !visitedFlags(fs)["underpriced"] {
+		if err := fs.Set("underpriced", "true"); err != nil {
+			return err
+		}
+	}
+
+	// Conditions from flags are combined with positional ones
+	setFlags := visitedFlags(fs)
+	if setFlags["min-upside"] {
+		conditions = append(conditions, fmt.Sprintf("upside>=%g", *minUpside))
+	}
+	if *maxPE > 0 {
+		conditions = append(conditions, fmt.Sprintf("pe<=%g", *maxPE))
+	}
+	if *maxPEG > 0 {
+		conditions = append(conditions, fmt.Sprintf("peg<=%g", *maxPEG))
+	}
+	if *minMarketCap > 0 {
+		conditions = append(conditions, fmt.Sprintf("market_cap>=%g", *minMarketCap))
+	}
+	if *sector != "" {
+		conditions = append(conditions, fmt.Sprintf("sector=%q", *sector))
+	}
+
+	if *save != "" {
+		if *cfgFlags.configFile == "" {
+			return fmt.Errorf("-save requires -config to name the file the screen is saved in")
+		}
+		criteria, err := screener.Parse(strings.Join(conditions, " "))
+		if err != nil {
+			return err
+		}
+		if err := config.SaveScreen(*cfgFlags.configFile, *save, config.ScreenConfig{Criteria: criteria.String()}); err != nil {
+			return err
+		}
+		fmt.Printf("Saved screen %q (%s) to %s\n", *save, criteria, *cfgFlags.configFile)
+		return nil
+	}
+
+	cfg, err := cfgFlags.load(fs)
+	if err != nil {
+		return err
+	}
+	outFlags.apply(fs, cfg)
+
+	if *list {
+		listScreens(cfg.Screens)
+		return nil
+	}
+
+	spec := screenSpec{name: *name, changes: *changes, every: *every}
+	expr := strings.Join(conditions, " ")
+	if *name != "" {
+		saved, ok := cfg.Screens[*name]
+		if !ok {
+			return fmt.Errorf("unknown screen %q (see screen -list)", *name)
+		}
+		expr = strings.TrimSpace(saved.Criteria + " " + expr)
+		if len(saved.Tickers) > 0 {
+			cfg.DataSources.Tickers = normalizeTickers(saved.Tickers)
+		}
+	}
+	if spec.criteria, err = screener.Parse(expr); err != nil {
+		return err
+	}
+
+	if spec.every < 0 {
+		return fmt.Errorf("-every must be positive")
+	}
+	if spec.every > 0 {
+		spec.changes = true
+	}
+
+	app, err := NewApplication(cfg)
+	if err != nil {
+		return err
+	}
+	app.resume = *resume
+	app.retrySkipped = *retrySkipped
+
+	stopProfiling, err := profFlags.start()
+	if err != nil {
+		return err
+	}
+	defer stopProfiling()
+
+	return app.Screen(ctx, spec)
+}
+
+// runQuote prints fetched stock data for the given tickers
+func runQuote(ctx context.Context, args []string) error {
+	fs := newFlagSet("quote")
+	cfgFlags := registerConfigFlags(fs)
+	showColors := fs.Bool("colors", true, "Enable colored output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("at least one ticker is required")
+	}
+
+	app, err := newApplicationFromFlags(fs, cfgFlags, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	var quotes []*models.StockData
+	for _, ticker := range normalizeTickers(fs.Args()) {
+		stockData, err := app.analyzer.FetchStockData(ctx, ticker)
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			continue
+		}
+		quotes = append(quotes, stockData)
+	}
+
+	utils.DisplayQuotes(quotes, *showColors)
+	return nil
+}
+
+// runExplain prints the full valuation breakdown for a single ticker
+func runExplain(ctx context.Context, args []string) error {
+	fs := newFlagSet("explain")
+	cfgFlags := registerConfigFlags(fs)
+	showColors := fs.Bool("colors", true, "Enable colored output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("exactly one ticker is required")
+	}
+
+	app, err := newApplicationFromFlags(fs, cfgFlags, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	app.config.Output.ShowColors = *showColors
+	valuations := app.analyzer.ValuateAll(ctx, normalizeTickers(fs.Args()))
+	if valuations[0].Err != nil {
+		return valuations[0].Err
+	}
+
+	app.DisplayDetails(valuations)
+	return nil
+}
+
+// runCompare prints the valuations of several tickers in aligned columns
+func runCompare(ctx context.Context, args []string) error {
+	fs := newFlagSet("compare")
+	cfgFlags := registerConfigFlags(fs)
+	showColors := fs.Bool("colors", true, "Enable colored output")
+	tickers, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	tickers = normalizeTickers(tickers)
+	if len(tickers) < 2 {
+		return fmt.Errorf("at least two tickers are required")
+	}
+
+	app, err := newApplicationFromFlags(fs, cfgFlags, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	var stocks []utils.Comparison
+	for _, v := range app.analyzer.ValuateAll(ctx, tickers) {
+		if v.Err != nil {
+			fmt.Printf("Warning: %v\n", v.Err)
+			continue
+		}
+		if v.Result.Failed() {
+			fmt.Printf("Warning: %s\n", v.Result.Error)
+			continue
+		}
+		stocks = append(stocks, utils.Comparison{StockData: v.StockData, Result: v.Result, Breakdown: v.Breakdown})
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(stocks) == 0 {
+		return fmt.Errorf("none of the tickers could be valued")
+	}
+
+	utils.DisplayComparison(stocks, *showColors)
+	return nil
+}
+
+// runServe serves valuations over a REST API until interrupted
+func runServe(ctx context.Context, args []string) error {
+	fs := newFlagSet("serve")
+	cfgFlags := registerConfigFlags(fs)
+	addr := fs.String("addr", "", "Address to listen on (default from config, :8080)")
+	grpcAddr := fs.String("grpc-addr", "", "Address for the gRPC API (disabled unless set here or in config)")
+	runsDir := fs.String("runs-dir", "", "Store analysis runs as JSON files in this directory instead of the history database")
+	jobsDir := fs.String("jobs-dir", "", "Directory where queued analysis jobs are stored")
+	schedule := fs.Bool("schedule", false, "Also run the jobs in the configured schedule")
+	profFlags := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := cfgFlags.load(fs)
+	if err != nil {
+		return err
+	}
+	if *addr != "" {
+		cfg.Server.Addr = *addr
+	}
+	if *grpcAddr != "" {
+		cfg.Server.GRPCAddr = *grpcAddr
+	}
+	if *runsDir != "" {
+		cfg.Server.RunsDir = *runsDir
+		cfg.History.Backend = config.HistoryJSON
+	}
+	if *jobsDir != "" {
+		cfg.Server.JobsDir = *jobsDir
+	}
+
+	app, err := NewApplication(cfg)
+	if err != nil {
+		return err
+	}
+	// Requests for the same tickers share data kept in memory
+	app.analyzer.EnableMemoryCache()
+
+	stopTelemetry, err := startTelemetry(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer stopTelemetry()
+
+	stopProfiling, err := profFlags.start()
+	if err != nil {
+		return err
+	}
+	defer stopProfiling()
+
+	store, err := openRunStore(cfg)
+	if err != nil {
+		return err
+	}
+	// Scheduled runs are recorded in the store the API serves from, as
+	// runs analyzed through the API are
+	app.history = store
+
+	jobStore, err := storage.NewJobStore(cfg.Server.JobsDir)
+	if err != nil {
+		return err
+	}
+	queue := jobs.NewQueue(app.analyzer, jobStore, store, services.NewWriterLogger(os.Stdout))
+
+	// Serve REST, gRPC and the schedule side by side; any failing stops all
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var servers []func(ctx context.Context) error
+	servers = append(servers, func(ctx context.Context) error {
+		return newAPIServer(app, store, queue).ListenAndServe(ctx, cfg.Server.Addr)
+	})
+	servers = append(servers, queue.Run)
+	if cfg.DataSources.ScrapeCheck.Enabled {
+		servers = append(servers, app.analyzer.MonitorScrapers)
+	}
+	if cfg.Server.GRPCAddr != "" {
+		servers = append(servers, func(ctx context.Context) error {
+			return newGRPCServer(app, store).ListenAndServe(ctx, cfg.Server.GRPCAddr)
+		})
+	}
+	if *schedule {
+		servers = append(servers, func(ctx context.Context) error {
+			return app.RunSchedule(ctx, false)
+		})
+	}
+
+	errChan := make(chan error, len(servers))
+	for _, server := range servers {
+		go func() {
+			errChan <- server(ctx)
+			cancel()
+		}()
+	}
+
+	var firstErr error
+	for range servers {
+		if err := <-errChan; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// runCache inspects or clears the stock data cache
+func runCache(ctx context.Context, args []string) error {
+	fs := newFlagSet("cache")
+	configFile := fs.String("config", "", "Path to JSON configuration file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 || (fs.NArg() > 1 && fs.Arg(0) != "unskip") {
+		return fmt.Errorf("expected one of: stats, list, clear, skipped, unskip [TICKER...]")
+	}
+
+	cfg := config.NewDefaultConfig()
+	if *configFile != "" {
+		loaded, err := config.LoadFromFile(*configFile)
+		if err != nil {
+			return err
+		}
+		cfg = loaded
+	}
+
+	cache, err := services.NewCache(cfg.Processing.CacheDir, cfg.Processing.CacheTTL())
+	if err != nil {
+		return err
+	}
+
+	switch fs.Arg(0) {
+	case "stats":
+		stats, err := cache.Stats()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Cache directory: %s\n", stats.Dir)
+		fmt.Printf("Entries:         %d (%d expired)\n", stats.Entries, stats.Expired)
+		fmt.Printf("Size:            %.1f KB\n", float64(stats.SizeBytes)/1024)
+		fmt.Printf("Expiry:          %d hours\n", cfg.Processing.CacheExpiryHours)
+	case "list":
+		tickers, err := cache.Tickers()
+		if err != nil {
+			return err
+		}
+		for _, ticker := range tickers {
+			fmt.Println(ticker)
+		}
+	case "clear":
+		removed, err := cache.Clear()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d cache entries from %s\n", removed, cache.Dir())
+	case "skipped":
+		list, err := loadSkipList(cfg)
+		if err != nil {
+			return err
+		}
+		entries := list.Entries()
+		if len(entries) == 0 {
+			fmt.Printf("No failing tickers in %s\n", list.Path())
+			return nil
+		}
+		// Entries are active on the analyzer's clock, which records and
+		// checks them
+		analyzer, err := fairvalue.New(cfg)
+		if err != nil {
+			return err
+		}
+		now := analyzer.Now()
+		fmt.Printf("%-10s %8s %-17s %-12s %s\n", "TICKER", "FAILURES", "SKIPPED UNTIL", "LAST FAILED", "CAUSE")
+		for _, entry := range entries {
+			until := "-"
+			if entry.Until.After(now) {
+				until = entry.Until.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%-10s %8d %-17s %-12s %s\n", entry.Ticker, entry.Failures, until,
+				entry.LastFailed.Local().Format("2006-01-02"), entry.Cause)
+		}
+	case "unskip":
+		list, err := loadSkipList(cfg)
+		if err != nil {
+			return err
+		}
+		removed := list.Remove(normalizeTickers(fs.Args()[1:])...)
+		if err := list.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d tickers from the skip list %s\n", removed, list.Path())
+	default:
+		return fmt.Errorf("unknown cache action %q (expected stats, list, clear, skipped or unskip)", fs.Arg(0))
+	}
+	return nil
+}
+
+// loadSkipList reads the skip list of failing tickers cfg names
+func loadSkipList(cfg *config.Config) (*storage.SkipList, error) {
+	path, err := cfg.Processing.SkipListPath()
+	if err != nil {
+		return nil, err
+	}
+	return storage.LoadSkipList(path, cfg.Processing.SkipList.Threshold(), cfg.Processing.SkipList.Duration())
+}
+
+// runConfig shows, writes or validates configuration
+func runConfig(ctx context.Context, args []string) error {
+	fs := newFlagSet("config")
+	configFile := fs.String("config", "", "Path to JSON configuration file")
+	output := fs.String("output", "config.json", "File written by \"config init\"")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected one of: show, init, validate, selectors")
+	}
+
+	cfg := config.NewDefaultConfig()
+	if *configFile != "" {
+		loaded, err := config.LoadFromFile(*configFile)
+		if err != nil {
+			return err
+		}
+		cfg = loaded
+	}
+
+	switch fs.Arg(0) {
+	case "show":
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case "init":
+		if _, err := os.Stat(*output); err == nil {
+			return fmt.Errorf("%s already exists", *output)
+		}
+		data, err := json.MarshalIndent(config.NewDefaultConfig(), "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*output, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", *output, err)
+		}
+		fmt.Printf("Wrote default configuration to %s\n", *output)
+	case "validate":
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("configuration is invalid: %w", err)
+		}
+		fmt.Println("Configuration is valid")
+	case "selectors":
+		// The built-in selectors with those of the selectors file, as a
+		// starting point for editing it
+		selectors := services.DefaultSelectors()
+		if cfg.DataSources.SelectorsFile != "" {
+			loaded, err := services.LoadSelectors(cfg.DataSources.SelectorsFile)
+			if err != nil {
+				return err
+			}
+			selectors = loaded
+		}
+		data, err := json.MarshalIndent(selectors, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	default:
+		return fmt.Errorf("unknown config action %q (expected show, init, validate or selectors)", fs.Arg(0))
+	}
+	return nil
+}
+
+// runVersion prints the build version
+func runVersion(ctx context.Context, args []string) error {
+	fs := newFlagSet("version")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	fmt.Println(buildinfo.Version())
+	return nil
+}
+
+// runHelp shows general or command-specific help
+func runHelp(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		showUsage()
+		return nil
+	}
+
+	cmd, ok := lookupCommand(args[0])
+	if !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd.run(ctx, []string{"-help"})
+}
+
+// newApplicationFromFlags builds configuration from flags and creates the application
+func newApplicationFromFlags(fs *flag.FlagSet, cfgFlags *configFlags, outFlags *outputFlags) (*Application, error) {
+	cfg, err := cfgFlags.load(fs)
+	if err != nil {
+		return nil, err
+	}
+	if outFlags != nil {
+		outFlags.apply(fs, cfg)
+	}
+
+	return NewApplication(cfg)
+}
+
+// normalizeTickers upper-cases ticker arguments and drops duplicates
+func normalizeTickers(args []string) []string {
+	seen := make(map[string]bool)
+	var tickers []string
+	for _, arg := range args {
+		ticker := strings.ToUpper(strings.TrimSpace(arg))
+		if ticker == "" || seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+		tickers = append(tickers, ticker)
+	}
+	return tickers
+}
+
+// showUsage displays general help information
+func showUsage() {
+	fmt.Println("Stock Fair Value Estimation Tool")
+	fmt.Println("=================================")
+	fmt.Println()
+	fmt.Println("This tool calculates fair value prices for stocks using a hybrid approach:")
+	fmt.Println("- 60% Discounted Cash Flow (DCF) analysis")
+	fmt.Println("- 40% Comparable Company Analysis (Comps)")
+	fmt.Println("- Tangible book value as a conservative floor")
+	fmt.Println()
+	fmt.Println("Usage:")
+	fmt.Println("  fair-stock-value [command] [options]")
+	fmt.Println("  fair-stock-value [options] TICKER...")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, cmd := range allCommands() {
+		if cmd.hidden {
+			continue
+		}
+		fmt.Printf("  %-10s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Println()
+	fmt.Println("Without a command, options are passed to \"analyze\"; bare tickers are")
+	fmt.Println("valued individually with full detail.")
+	fmt.Println("Run \"fair-stock-value help <command>\" for command options.")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  fair-stock-value -test")
+	fmt.Println("  fair-stock-value AAPL MSFT")
+	fmt.Println("  fair-stock-value analyze -workers 4 -sort ticker")
+	fmt.Println("  fair-stock-value -watch -watch-interval 1m")
+	fmt.Println("  fair-stock-value screen -min-upside 20 -limit 20")
+	fmt.Println("  fair-stock-value explain AAPL")
+	fmt.Println("  fair-stock-value quote MSFT GOOGL")
+	fmt.Println("  source <(fair-stock-value completion bash)")
+	fmt.Println()
+}
+
+// showCommandHelp displays help for a single command
+func showCommandHelp(fs *flag.FlagSet) {
+	if fs.Name() == "analyze" {
+		showUsage()
+	}
+
+	cmd, _ := lookupCommand(fs.Name())
+	fmt.Printf("Usage:\n  fair-stock-value %s\n\n", cmd.usage)
+	fmt.Println(cmd.description)
+	fmt.Println()
+	fmt.Println("Options:")
+	fs.SetOutput(os.Stdout)
+	fs.PrintDefaults()
+	fmt.Println()
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+// completionScripts are the shell integrations printed by "completion".
+// Each one delegates to the hidden __complete command, which knows the
+// commands, their flags and the configured ticker universe.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for fair-stock-value
+# Load with: source <(fair-stock-value completion bash)
+_fair_stock_value() {
+    local IFS=$'\n'
+    COMPREPLY=($(fair-stock-value __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _fair_stock_value fair-stock-value
+`,
+	"zsh": `#compdef fair-stock-value
+# zsh completion for fair-stock-value
+# Load with: source <(fair-stock-value completion zsh)
+_fair_stock_value() {
+  local -a candidates
+  candidates=("${(@f)$(fair-stock-value __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+  if [[ -n "${candidates[1]}" ]]; then
+    compadd -- "${candidates[@]}"
+  else
+    _files
+  fi
+}
+compdef _fair_stock_value fair-stock-value
+`,
+	"fish": `# fish completion for fair-stock-value
+# Load with: fair-stock-value completion fish | source
+function __fair_stock_value_complete
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    fair-stock-value __complete $tokens (commandline -ct) 2>/dev/null
+end
+complete -c fair-stock-value -a '(__fair_stock_value_complete)'
+`,
+}
+
+// runCompletion prints the completion script for a shell
+func runCompletion(ctx context.Context, args []string) error {
+	fs := newFlagSet("completion")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected one of: bash, zsh, fish")
+	}
+
+	script, ok := completionScripts[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q (expected bash, zsh or fish)", fs.Arg(0))
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// runComplete prints the completion candidates for the last of args, given
+// the words before it, one per line
+func runComplete(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	for _, candidate := range completeWords(ctx, args[:len(args)-1], args[len(args)-1]) {
+		fmt.Println(candidate)
+	}
+	return nil
+}
+
+// completeWords returns the candidates for current following the words
+// already on the command line (excluding the program name)
+func completeWords(ctx context.Context, words []string, current string) []string {
+	// The first word selects the command; anything else goes to analyze
+	if len(words) == 0 && !strings.HasPrefix(current, "-") {
+		candidates := commandNames()
+		if current != "" {
+			candidates = append(candidates, universeTickers(words)...)
+		}
+		return filterPrefix(candidates, current, false)
+	}
+
+	cmd, ok := command{}, false
+	if len(words) > 0 {
+		cmd, ok = lookupCommand(words[0])
+	}
+	if ok && !cmd.hidden {
+		words = words[1:]
+	} else {
+		cmd, _ = lookupCommand("analyze")
+	}
+
+	flags := commandFlags(ctx, cmd)
+
+	// Value of the preceding flag
+	if len(words) > 0 {
+		if name, ok := flagName(words[len(words)-1]); ok {
+			if f := flags[name]; f != nil && !isBoolFlag(f) {
+				return completeFlagValue(name, current, words)
+			}
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		names := make([]string, 0, len(flags))
+		for name := range flags {
+			names = append(names, "-"+name)
+		}
+		sort.Strings(names)
+		return filterPrefix(names, current, false)
+	}
+
+	return filterPrefix(completeArgument(cmd.name, words), current, true)
+}
+
+// completeArgument returns the candidates for a positional argument of a command
+func completeArgument(name string, words []string) []string {
+	switch name {
+	case "analyze", "fetch", "quote", "compare", "explain", "history":
+		return universeTickers(words)
+	case "profiles":
+		return completionConfig(words).ProfileNames()
+	case "cache":
+		return []string{"stats", "list", "clear", "skipped", "unskip"}
+	case "notes":
+		return []string{"add", "list", "delete"}
+	case "config":
+		return []string{"show", "init", "validate", "selectors"}
+	case "completion":
+		return []string{"bash", "fish", "zsh"}
+	case "help":
+		return commandNames()
+	}
+	return nil
+}
+
+// completeFlagValue returns the candidates for the value of a flag. Flags
+// taking file names return nothing so the shell falls back to files.
+func completeFlagValue(name, current string, words []string) []string {
+	switch name {
+	case "sort":
+		return filterPrefix(utils.SortKeys, current, false)
+	case "trend":
+		return filterPrefix(utils.Trends, current, false)
+	case "refresh":
+		return filterPrefix([]string{refreshAll, refreshStale}, current, false)
+	case "preset":
+		cfg := completionConfig(words)
+		names := make([]string, 0)
+		for preset := range config.GetColumnPresets() {
+			names = append(names, preset)
+		}
+		for preset := range cfg.Output.Presets {
+			names = append(names, preset)
+		}
+		sort.Strings(names)
+		return filterPrefix(names, current, false)
+	case "screen":
+		names := make([]string, 0)
+		for screen := range completionConfig(words).Screens {
+			names = append(names, screen)
+		}
+		sort.Strings(names)
+		return filterPrefix(names, current, false)
+	case "columns":
+		return completeList(utils.ColumnKeys(), current, false)
+	case "watchlist":
+		return completeList(universeTickers(words), current, true)
+	}
+	return nil
+}
+
+// completeList completes the last entry of a comma-separated list
+func completeList(candidates []string, current string, ignoreCase bool) []string {
+	done, last := "", current
+	if i := strings.LastIndex(current, ","); i >= 0 {
+		done, last = current[:i+1], current[i+1:]
+	}
+	var completions []string
+	for _, candidate := range filterPrefix(candidates, last, ignoreCase) {
+		completions = append(completions, done+candidate)
+	}
+	return completions
+}
+
+// commandFlags captures the flag set of a command by running it with -help
+// while usageHook is set, so completion never drifts from the real flags
+func commandFlags(ctx context.Context, cmd command) map[string]*flag.Flag {
+	flags := make(map[string]*flag.Flag)
+	usageHook = func(fs *flag.FlagSet) {
+		fs.VisitAll(func(f *flag.Flag) {
+			flags[f.Name] = f
+		})
+	}
+	defer func() { usageHook = nil }()
+
+	// Commands without flags return their own error instead of flag.ErrHelp
+	_ = cmd.run(ctx, []string{"-help"})
+	return flags
+}
+
+// universeTickers returns the configured ticker universe
+func universeTickers(words []string) []string {
+	analyzer, err := fairvalue.New(completionConfig(words))
+	if err != nil {
+		return nil
+	}
+	return analyzer.Universe()
+}
+
+// completionConfig loads the configuration named by -config and -tickers
+// on the command line, falling back to the defaults
+func completionConfig(words []string) *config.Config {
+	cfg := config.NewDefaultConfig()
+	for i := 0; i+1 < len(words); i++ {
+		switch name, _ := flagName(words[i]); name {
+		case "config":
+			if loaded, err := config.LoadFromFile(words[i+1]); err == nil {
+				cfg = loaded
+			}
+		case "tickers":
+			cfg.DataSources.TickerFile = words[i+1]
+		}
+	}
+	return cfg
+}
+
+// commandNames returns the names of the visible commands
+func commandNames() []string {
+	var names []string
+	for _, cmd := range allCommands() {
+		if !cmd.hidden {
+			names = append(names, cmd.name)
+		}
+	}
+	return names
+}
+
+// flagName returns the name of a flag argument such as -sort or --sort
+func flagName(word string) (string, bool) {
+	if !strings.HasPrefix(word, "-") || strings.Contains(word, "=") {
+		return "", false
+	}
+	return strings.TrimLeft(word, "-"), true
+}
+
+// isBoolFlag reports whether a flag takes no value
+func isBoolFlag(f *flag.Flag) bool {
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// filterPrefix returns the candidates starting with prefix
+func filterPrefix(candidates []string, prefix string, ignoreCase bool) []string {
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) ||
+			(ignoreCase && strings.HasPrefix(candidate, strings.ToUpper(prefix))) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/sinks"
+)
+
+// exportFormats are the -format values written to a file instead of the
+// results table, with their file extensions
+var exportFormats = map[string]string{
+	"csv":     "csv",
+	"json":    "json",
+	"parquet": "parquet",
+}
+
+// exportOptions select the file a run's results are written to
+type exportOptions struct {
+	format     string
+	path       string // defaults to fair-value-<run ID>.<extension>
+	withInputs bool   // add the fetched fundamentals (parquet only)
+}
+
+// newExportOptions validates the export flags, returning nil for the
+// results table
+func newExportOptions(format, path string, withInputs bool) (*exportOptions, error) {
+	if withInputs && format != "parquet" {
+		return nil, fmt.Errorf("-with-inputs requires -format parquet")
+	}
+	if format == "" || format == "table" {
+		if path != "" {
+			return nil, fmt.Errorf("-output requires -format csv, json or parquet")
+		}
+		return nil, nil
+	}
+	if _, ok := exportFormats[format]; !ok {
+		return nil, fmt.Errorf("unknown format %q (want table, csv, json or parquet)", format)
+	}
+	return &exportOptions{format: format, path: path, withInputs: withInputs}, nil
+}
+
+// filePath returns the file the results of run are written to
+func (opts *exportOptions) filePath(run *models.Run) string {
+	if opts.path != "" {
+		return opts.path
+	}
+	return fmt.Sprintf("fair-value-%s.%s", run.ID, exportFormats[opts.format])
+}
+
+// resultWriter starts the file of run in the selected format on w, for
+// writing its results as they complete
+func (opts *exportOptions) resultWriter(w io.Writer, run *models.Run) (sinks.ResultWriter, error) {
+	switch opts.format {
+	case "csv":
+		return sinks.NewCSVWriter(w, run)
+	case "json":
+		return sinks.NewJSONWriter(w, run)
+	default:
+		return sinks.NewParquetWriter(w, run, opts.withInputs), nil
+	}
+}
+
+// export writes the results of run to the file selected by opts
+func (app *Application) export(run *models.Run, opts *exportOptions) error {
+	path := opts.filePath(run)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	switch opts.format {
+	case "csv":
+		err = sinks.WriteCSV(file, run)
+	case "json":
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(run)
+	case "parquet":
+		err = sinks.WriteParquet(file, run, opts.withInputs)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	fmt.Printf("Wrote %d results to %s\n", len(run.Results), path)
+	return nil
+}
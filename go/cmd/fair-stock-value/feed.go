@@ -0,0 +1,307 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/sinks"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+)
+
+// feedEntries is the maximum number of entries of a feed
+const feedEntries = 50
+
+// feedTitle is the title of the feeds
+const feedTitle = "Newly undervalued stocks"
+
+// crossing is a stock that turned Underpriced in a stored run
+type crossing struct {
+	run    *models.Run
+	result *models.ValuationResult
+	from   string // status in the run before
+}
+
+// id returns a unique, stable identifier of the entry for c
+func (c crossing) id() string {
+	return "urn:fair-stock-value:" + c.run.ID + ":" + c.result.Ticker
+}
+
+// at returns when c was recorded
+func (c crossing) at() time.Time {
+	if c.run.FinishedAt.IsZero() {
+		return c.run.StartedAt
+	}
+	return c.run.FinishedAt
+}
+
+// title summarizes c in a line
+func (c crossing) title() string {
+	return fmt.Sprintf("%s is undervalued: fair value $%.2f, %.1f%% upside",
+		c.result.Ticker, c.result.FairValue, c.result.UpsidePercentage)
+}
+
+// text describes c
+func (c crossing) text() string {
+	name := c.result.Ticker
+	if c.result.CompanyName != "" {
+		name = fmt.Sprintf("%s (%s)", c.result.CompanyName, c.result.Ticker)
+	}
+	text := fmt.Sprintf("%s turned %s from %s. Price $%.2f, fair value $%.2f (DCF $%.2f, Comps $%.2f), upside %.1f%%.",
+		name, c.result.Status, c.from, c.result.CurrentPrice, c.result.FairValue,
+		c.result.DCFValue, c.result.CompsValue, c.result.UpsidePercentage)
+	if c.result.Sector != "" {
+		text += " Sector: " + c.result.Sector + "."
+	}
+	return text
+}
+
+// crossings returns the stocks that turned Underpriced in the runs started
+// since then, newest first. A stock's first valuation since then has
+// nothing to compare with and is never a crossing.
+func crossings(store storage.RunStore, since time.Time) ([]crossing, error) {
+	runs, err := listSince(store, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []crossing
+	statuses := make(map[string]string)
+	for _, run := range runs {
+		if run.StartedAt.Before(since) {
+			continue
+		}
+		for _, result := range run.Results {
+			if result.Failed() {
+				continue
+			}
+			from, seen := statuses[result.Ticker]
+			statuses[result.Ticker] = result.Status
+			if seen && from != models.StatusUnderpriced && result.Status == models.StatusUnderpriced {
+				found = append(found, crossing{run: run, result: result, from: from})
+			}
+		}
+	}
+	slices.Reverse(found)
+	if len(found) > feedEntries {
+		found = found[:feedEntries]
+	}
+	return found, nil
+}
+
+// latestResults returns the latest valuation of each stock valued in the
+// runs started since then
+func latestResults(store storage.RunStore, since time.Time) ([]*models.ValuationResult, error) {
+	runs, err := listSince(store, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*models.ValuationResult
+	latest := make(map[string]int)
+	for _, run := range runs {
+		if run.StartedAt.Before(since) {
+			continue
+		}
+		for _, result := range run.Results {
+			if result.Failed() {
+				continue
+			}
+			if i, ok := latest[result.Ticker]; ok {
+				results[i] = result
+			} else {
+				latest[result.Ticker] = len(results)
+				results = append(results, result)
+			}
+		}
+	}
+	return results, nil
+}
+
+// listSince returns the stored runs, oldest first, leaving out those
+// started before since when the store can
+func listSince(store storage.RunStore, since time.Time) ([]*models.Run, error) {
+	if s, ok := store.(storage.SinceStore); ok {
+		return s.ListSince(since)
+	}
+	return store.List()
+}
+
+// atomFeed is an Atom 1.0 feed
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomAuthor is the author of an Atom feed
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// atomLink is a link of an Atom feed or entry
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+// atomEntry is an entry of an Atom feed
+type atomEntry struct {
+	Title    string       `xml:"title"`
+	ID       string       `xml:"id"`
+	Updated  string       `xml:"updated"`
+	Link     atomLink     `xml:"link"`
+	Category atomCategory `xml:"category"`
+	Summary  string       `xml:"summary"`
+}
+
+// atomCategory is the category of an Atom entry
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// rssFeed is an RSS 2.0 feed
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel is the channel of an RSS feed
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+// rssItem is an item of an RSS feed
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Category    string  `xml:"category"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+// rssGUID is the identifier of an RSS item, which is not a URL
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// handleAtomFeed serves the stocks that turned Underpriced as an Atom feed
+func (s *apiServer) handleAtomFeed(w http.ResponseWriter, r *http.Request) {
+	found, err := crossings(s.store, time.Now().Add(-s.app.config.Server.FeedWindow()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	base := baseURL(r)
+	feed := atomFeed{
+		Title:   feedTitle,
+		ID:      base + "/feed.atom",
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: "fair-stock-value"},
+		Links:   []atomLink{{Href: base + "/feed.atom", Rel: "self"}},
+	}
+	if len(found) > 0 {
+		feed.Updated = found[0].at().UTC().Format(time.RFC3339)
+	}
+	for _, c := range found {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:    c.title(),
+			ID:       c.id(),
+			Updated:  c.at().UTC().Format(time.RFC3339),
+			Link:     atomLink{Href: base + "/api/v1/runs/" + c.run.ID},
+			Category: atomCategory{Term: c.result.Ticker},
+			Summary:  c.text(),
+		})
+	}
+	writeXML(w, "application/atom+xml", feed)
+}
+
+// handleRSSFeed serves the stocks that turned Underpriced as an RSS feed
+func (s *apiServer) handleRSSFeed(w http.ResponseWriter, r *http.Request) {
+	found, err := crossings(s.store, time.Now().Add(-s.app.config.Server.FeedWindow()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	base := baseURL(r)
+	feed := rssFeed{Version: "2.0", Channel: rssChannel{
+		Title:       feedTitle,
+		Link:        base + "/feed.rss",
+		Description: "Stocks that turned Underpriced, with their fair value and upside",
+	}}
+	for _, c := range found {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       c.title(),
+			Link:        base + "/api/v1/runs/" + c.run.ID,
+			Description: c.text(),
+			Category:    c.result.Ticker,
+			GUID:        rssGUID{Value: c.id()},
+			PubDate:     c.at().UTC().Format(time.RFC1123Z),
+		})
+	}
+	writeXML(w, "application/rss+xml", feed)
+}
+
+// handleCalendar serves the upcoming earnings dates of the top undervalued
+// stocks as an iCalendar file; ?top= sets how many stocks are included
+func (s *apiServer) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	topN := sinks.DefaultCalendarSize
+	if top := r.URL.Query().Get("top"); top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("top must be a positive number, got %q", top))
+			return
+		}
+		topN = n
+	}
+
+	results, err := latestResults(s.store, time.Now().Add(-s.app.config.Server.FeedWindow()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	now := time.Now()
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="earnings.ics"`)
+	if err := sinks.WriteEarningsCalendar(w, sinks.EarningsEvents(results, topN, now), now); err != nil {
+		log.Printf("Warning: failed to write response: %v", err)
+	}
+}
+
+// baseURL returns the scheme and host r was sent to
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// writeXML writes v as an XML document of contentType
+func writeXML(w http.ResponseWriter, contentType string, v interface{}) {
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		log.Printf("Warning: failed to write response: %v", err)
+	}
+}
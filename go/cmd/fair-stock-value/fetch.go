@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/services"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+// rateLimitStreak is the number of tickers in a row rate limited despite
+// retries after which fetch stops, leaving the rest for a later run
+const rateLimitStreak = 10
+
+// runFetch fetches the data of the ticker universe into the cache without
+// valuing it, so runs with -cache-only can value it without waiting on the
+// data sources
+func runFetch(ctx context.Context, args []string) error {
+	fs := newFlagSet("fetch")
+	cfgFlags := registerConfigFlags(fs)
+	showProgress := fs.Bool("progress", true, "Show progress indicators")
+	force := fs.Bool("force", false, "Fetch tickers whose cached data has not expired too")
+	retrySkipped := fs.Bool("retry-skipped", false, "Also fetch the tickers on the skip list for failing the runs before")
+	tickers, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := cfgFlags.load(fs)
+	if err != nil {
+		return err
+	}
+	if cfg.Processing.CacheOnly {
+		return fmt.Errorf("fetch cannot be combined with -cache-only")
+	}
+	if !cfg.Processing.EnableCaching {
+		return fmt.Errorf("fetch fills the stock data cache, which is disabled")
+	}
+	if len(tickers) > 0 {
+		cfg.DataSources.Tickers = normalizeTickers(tickers)
+	}
+
+	app, err := NewApplication(cfg)
+	if err != nil {
+		return err
+	}
+	// Tickers named on the command line are fetched even if skipped
+	app.retrySkipped = *retrySkipped || len(tickers) > 0
+	if *force {
+		if err := app.setRefresh(refreshAll); err != nil {
+			return err
+		}
+	}
+
+	// Data kept for price refreshes would grow with the universe
+	app.analyzer.SetRetainStockData(false)
+
+	stopTelemetry, err := startTelemetry(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer stopTelemetry()
+
+	return app.Fetch(ctx, *showProgress)
+}
+
+// Fetch fetches the data of the ticker universe into the cache. Tickers
+// with cached data that has not expired are left out, so a fetch that was
+// interrupted or stopped for rate limiting continues where it left off
+// when run again.
+func (app *Application) Fetch(ctx context.Context, showProgress bool) error {
+	started := time.Now()
+	app.loadTickers()
+
+	skipList, err := app.openSkipList()
+	if err != nil {
+		return err
+	}
+	var pending []string
+	cached, skipped := 0, 0
+	for _, ticker := range app.tickers {
+		switch {
+		case skipList != nil && !app.retrySkipped && skipList.Skipped(ticker, app.analyzer.Now()):
+			skipped++
+		case app.refresh != refreshAll && app.analyzer.Cached(ticker):
+			cached++
+		default:
+			pending = append(pending, ticker)
+		}
+	}
+	if skipped > 0 {
+		fmt.Printf("Skipping %d stocks that failed %d or more runs in a row (see cache skipped)\n",
+			skipped, app.config.Processing.SkipList.Threshold())
+	}
+	if cached > 0 {
+		fmt.Printf("%d stocks are already cached within %gh\n", cached, app.config.Processing.CacheTTL().Hours())
+	}
+	if len(pending) == 0 {
+		fmt.Println("Nothing to fetch")
+		return nil
+	}
+	app.analyzer.CheckScrapers(ctx)
+	fmt.Printf("Fetching %d stocks with %d parallel workers...\n", len(pending), app.analyzer.Workers())
+
+	// Tickers rate limited one after another mean the sources are turning
+	// the fetch away, so it stops rather than keep asking
+	fetchCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	var fetched []string
+	var failures []fairvalue.Valuation
+	completed, limited, stopped := 0, 0, false
+	app.analyzer.FetchEach(fetchCtx, pending, func(i int, err error) {
+		ticker := pending[i]
+		completed++
+		if showProgress {
+			utils.ShowProgress(completed, len(pending), ticker)
+		}
+		if fetchCtx.Err() != nil && err != nil {
+			return
+		}
+		if err != nil {
+			failures = append(failures, fairvalue.Valuation{Ticker: ticker, Err: err})
+		} else {
+			fetched = append(fetched, ticker)
+		}
+		if !errors.Is(err, services.ErrRateLimited) {
+			limited = 0
+		} else if limited++; limited == rateLimitStreak {
+			stopped = true
+			stop()
+		}
+	})
+
+	reportFailures(failures)
+	if ctx.Err() == nil {
+		app.updateSkipList(skipList, fetched, failures)
+	}
+
+	dir, err := app.config.Processing.CachePath()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nFetched %d of %d stocks into %s in %s\n", len(fetched), len(pending),
+		dir, time.Since(started).Round(time.Second))
+	if showProgress {
+		app.reportSourceStats()
+		app.reportHTTPTrace()
+	}
+	if stopped {
+		fmt.Printf("Stopped after %d stocks in a row were rate limited\n", rateLimitStreak)
+	}
+	if missing := len(pending) - len(fetched); missing > 0 {
+		fmt.Printf("%d stocks are not cached; run fetch again to retry them\n", missing)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if stopped {
+		return fmt.Errorf("stopped fetching: %w", services.ErrRateLimited)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// configFlags are the flags shared by every command that loads configuration
+type configFlags struct {
+	configFile *string
+	testMode   *bool
+	tickerFile *string
+	screener   *string
+	universe   *string
+	maxWorkers *int
+	adaptive   *bool
+	tickerWait *time.Duration
+	apiOnly    *bool
+	noCache    *bool
+	cacheOnly  *bool
+	strictData *int
+
+	deterministic *bool
+	decimal       *bool
+	assumptions   config.Assumptions
+	runTags       []string
+	traceHTTP     *string
+}
+
+// registerConfigFlags defines the configuration flags on fs
+func registerConfigFlags(fs *flag.FlagSet) *configFlags {
+	f := &configFlags{
+		configFile: fs.String("config", "", "Path to JSON configuration file"),
+		testMode:   fs.Bool("test", false, "Run in test mode with limited stocks"),
+		tickerFile: fs.String("tickers", "", "Path to ticker CSV file"),
+		universe:   fs.String("universe", "", "Value the constituents of an index in place of -tickers: dow30, nasdaq100 or sp500"),
+		screener:   fs.String("screener", "", "Yahoo Finance screener ID or URL whose matches are the universe, in place of -tickers"),
+		maxWorkers: fs.Int("workers", 8, "Maximum number of parallel workers"),
+		adaptive:   fs.Bool("adaptive-workers", false, "Adapt the number of workers to rate limiting and latency of the data sources"),
+		tickerWait: fs.Duration("ticker-timeout", 60*time.Second, "Maximum time to fetch a single ticker before valuing it on partial data (0 = no limit)"),
+		apiOnly:    fs.Bool("api-only", false, "Disable scraping, growth consensus and fallback data"),
+		noCache:    fs.Bool("no-cache", false, "Bypass the stock data cache"),
+		cacheOnly:  fs.Bool("cache-only", false, "Value on cached data only, however old, without fetching anything; fill the cache with the fetch command first"),
+		strictData: fs.Int("strict-data", 0, "Minimum number of the 7 valuation inputs fetched rather than filled in from fallback data; stocks with fewer are not valued (0 = no minimum)"),
+
+		deterministic: fs.Bool("deterministic", false, "Fix the clock and random seed so runs over the same cached data produce identical output"),
+		decimal:       fs.Bool("decimal", false, "Value prices and per-share values in exact decimal arithmetic"),
+		traceHTTP:     fs.String("trace-http", "", "Write every HTTP request of the data sources (URL, status, latency, bytes) and cache lookup to this file as JSON lines"),
+	}
+	fs.Func("assume", `What-if overrides for this run, such as "growth=0.10,discount=0.09" or "AAPL:growth=0.12" (repeatable)`,
+		f.assumptions.Parse)
+	fs.Func("run-tag", `Tag recorded runs with a workflow name, such as "weekly-screen"; history, trends and backtest read only runs tagged with it (repeatable)`,
+		func(tag string) error {
+			if err := config.ValidateRunTag(tag); err != nil {
+				return err
+			}
+			if !slices.Contains(f.runTags, tag) {
+				f.runTags = append(f.runTags, tag)
+			}
+			return nil
+		})
+	return f
+}
+
+// load builds the configuration from the config file and explicitly set flags
+func (f *configFlags) load(fs *flag.FlagSet) (*config.Config, error) {
+	cfg := config.NewDefaultConfig()
+	if *f.configFile != "" {
+		loaded, err := config.LoadFromFile(*f.configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+		cfg = loaded
+	}
+	if *f.testMode {
+		cfg.ApplyTestMode()
+	}
+
+	setFlags := visitedFlags(fs)
+	if *f.tickerFile != "" {
+		cfg.DataSources.TickerFile = *f.tickerFile
+	}
+	// The universe and screener flags replace each other's configuration
+	if *f.universe != "" && *f.screener != "" {
+		return nil, fmt.Errorf("-universe and -screener cannot be combined")
+	}
+	if *f.universe != "" {
+		cfg.DataSources.Universe = *f.universe
+		cfg.DataSources.YahooScreener = ""
+	}
+	if *f.screener != "" {
+		cfg.DataSources.YahooScreener = *f.screener
+		cfg.DataSources.Universe = ""
+	}
+	if setFlags["workers"] && *f.maxWorkers > 0 {
+		cfg.Processing.MaxWorkers = *f.maxWorkers
+	}
+	if setFlags["adaptive-workers"] {
+		cfg.Processing.AdaptiveWorkers = *f.adaptive
+	}
+	if setFlags["ticker-timeout"] {
+		if *f.tickerWait < 0 {
+			return nil, fmt.Errorf("-ticker-timeout must not be negative")
+		}
+		cfg.Processing.TickerTimeoutSeconds = int(f.tickerWait.Round(time.Second) / time.Second)
+	}
+	if *f.apiOnly {
+		cfg.DataSources.SetAPIOnly()
+	}
+	if *f.noCache {
+		cfg.Processing.EnableCaching = false
+	}
+	if *f.cacheOnly {
+		if *f.noCache {
+			return nil, fmt.Errorf("-cache-only cannot be combined with -no-cache")
+		}
+		cfg.Processing.CacheOnly = true
+	}
+	if setFlags["strict-data"] {
+		if *f.strictData < 0 || *f.strictData > len(models.ValuationInputs) {
+			return nil, fmt.Errorf("-strict-data must be between 0 and %d", len(models.ValuationInputs))
+		}
+		cfg.Validation.MinRealInputs = *f.strictData
+	}
+	if *f.deterministic {
+		cfg.Processing.Deterministic = true
+	}
+	if *f.decimal {
+		cfg.Processing.DecimalMoney = true
+	}
+	if !f.assumptions.IsEmpty() {
+		cfg.Assume(f.assumptions)
+	}
+	cfg.RunTags = f.runTags
+	cfg.TraceHTTP = *f.traceHTTP
+
+	return cfg, nil
+}
+
+// outputFlags are the flags controlling the results table
+type outputFlags struct {
+	showColors      *bool
+	showProgress    *bool
+	sortBy          *string
+	onlyUnderpriced *bool
+	maxResults      *int
+	showExtra       *bool
+	preset          *string
+	columns         *string
+	trend           *string
+	paging          *bool
+	pageSize        *int
+}
+
+// registerOutputFlags defines the output flags on fs
+func registerOutputFlags(fs *flag.FlagSet) *outputFlags {
+	return &outputFlags{
+		showColors:      fs.Bool("colors", true, "Enable colored output"),
+		showProgress:    fs.Bool("progress", true, "Show progress indicators"),
+		sortBy:          fs.String("sort", "upside", "Sort results by: upside, ticker, fair_value"),
+		onlyUnderpriced: fs.Bool("underpriced", false, "Show only underpriced stocks"),
+		maxResults:      fs.Int("limit", 0, "Maximum number of results to show (0 = no limit)"),
+		showExtra:       fs.Bool("extra", false, "Show additional fields (P/E, EPS, FCF/Share, FCF, earnings and dividend yield, payout ratio, Sector, Company)"),
+		preset:          fs.String("preset", "", "Column preset: default, extra, compact, analyst, quant, trend or a config-defined name"),
+		columns:         fs.String("columns", "", "Comma-separated list of output columns"),
+		trend:           fs.String("trend", "", "Show only stocks trading above or below their 200-day moving average: above or below"),
+		paging:          fs.Bool("paging", true, "Pause the results table after every page on a terminal (never when piped)"),
+		pageSize:        fs.Int("page-size", 0, "Rows per page of the results table (0 = fit the terminal)"),
+	}
+}
+
+// apply overrides the output configuration with explicitly set flags
+func (f *outputFlags) apply(fs *flag.FlagSet, cfg *config.Config) {
+	setFlags := visitedFlags(fs)
+	if setFlags["colors"] {
+		cfg.Output.ShowColors = *f.showColors
+	}
+	if setFlags["progress"] {
+		cfg.Output.ShowProgress = *f.showProgress
+	}
+	if setFlags["sort"] {
+		cfg.Output.SortBy = *f.sortBy
+	}
+	if setFlags["underpriced"] {
+		cfg.Output.ShowOnlyUnderpriced = *f.onlyUnderpriced
+	}
+	if setFlags["extra"] {
+		cfg.Output.ShowExtra = *f.showExtra
+	}
+	if *f.maxResults > 0 {
+		cfg.Output.MaxResults = *f.maxResults
+	}
+	if *f.preset != "" {
+		cfg.Output.Preset = *f.preset
+		cfg.Output.Columns = nil
+	}
+	if *f.columns != "" {
+		cfg.Output.Columns = splitList(*f.columns)
+	}
+	if setFlags["trend"] {
+		cfg.Output.Trend = *f.trend
+	}
+	if setFlags["paging"] {
+		cfg.Output.Paging = *f.paging
+	}
+	if *f.pageSize > 0 {
+		cfg.Output.PageSize = *f.pageSize
+	}
+
+	// A preset's sort order applies unless -sort was given explicitly
+	if _, presetSort := cfg.Output.ResolveColumns(); presetSort != "" && !setFlags["sort"] {
+		cfg.Output.SortBy = presetSort
+	}
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments, returning the positional arguments in order
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// visitedFlags returns the names of the flags explicitly set on fs
+func visitedFlags(fs *flag.FlagSet) map[string]bool {
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+	return setFlags
+}
+
+// splitList splits a comma-separated flag value, trimming whitespace
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/graphql"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/screener"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+)
+
+// resultArguments select and order the results of a valuations or run
+// results field
+var resultArguments = []*graphql.Argument{
+	{Name: "where", Type: graphql.String, Description: "Screen conditions such as \"upside>20 sector=Technology\""},
+	{Name: "tickers", Type: graphql.List{Of: graphql.NonNull{Of: graphql.String}}},
+	{Name: "sort_by", Type: graphql.String, Default: "upside", Description: "Any field of Result"},
+	{Name: "descending", Type: graphql.Boolean, Default: true},
+	{Name: "first", Type: graphql.Int, Default: 20, Description: "Number of results, 0 for all"},
+}
+
+// newGraphQLSchema returns the schema of the GraphQL API of s, over the
+// stored runs and live valuations
+func newGraphQLSchema(s *apiServer) *graphql.Schema {
+	result := &graphql.Object{
+		Name:        "Result",
+		Description: "Valuation of a stock; every screening field is a field, null when unknown",
+		Fields: map[string]*graphql.Field{
+			"incomplete": {Type: graphql.Boolean, Resolve: resultField(func(r *models.ValuationResult) any { return r.Incomplete })},
+			"error":      {Type: graphql.String, Resolve: resultField(func(r *models.ValuationResult) any { return nullString(r.Error) })},
+		},
+	}
+	for _, name := range screener.Fields() {
+		field := &graphql.Field{Type: graphql.String}
+		if screener.IsNumeric(name) {
+			field.Type = graphql.Float
+		}
+		field.Resolve = resultField(func(r *models.ValuationResult) any {
+			value, _ := screener.Value(r, name)
+			return value
+		})
+		result.Fields[name] = field
+	}
+
+	tickerError := &graphql.Object{
+		Name: "TickerError",
+		Fields: map[string]*graphql.Field{
+			"ticker": {Type: graphql.NonNull{Of: graphql.String}},
+			"error":  {Type: graphql.NonNull{Of: graphql.String}},
+		},
+	}
+
+	run := &graphql.Object{
+		Name:        "Run",
+		Description: "Recorded analysis run",
+		Fields: map[string]*graphql.Field{
+			"id":          {Type: graphql.NonNull{Of: graphql.ID}, Resolve: runField(func(r *models.Run) any { return r.ID })},
+			"started_at":  {Type: graphql.NonNull{Of: graphql.String}, Resolve: runField(func(r *models.Run) any { return formatTime(r.StartedAt) })},
+			"finished_at": {Type: graphql.String, Resolve: runField(func(r *models.Run) any { return formatTime(r.FinishedAt) })},
+			"prices_only": {Type: graphql.Boolean, Resolve: runField(func(r *models.Run) any { return r.PricesOnly })},
+			"partial":     {Type: graphql.Boolean, Resolve: runField(func(r *models.Run) any { return r.Partial })},
+			"job":         {Type: graphql.String, Resolve: runField(func(r *models.Run) any { return nullString(r.Job) })},
+			"version":     {Type: graphql.String, Resolve: runField(func(r *models.Run) any { return nullString(r.Version) })},
+			"config_hash": {Type: graphql.String, Resolve: runField(func(r *models.Run) any { return nullString(r.ConfigHash) })},
+			"valued":      {Type: graphql.Int, Resolve: runField(func(r *models.Run) any { return r.Valued() })},
+			"errors": {
+				Type: graphql.NonNull{Of: graphql.List{Of: graphql.NonNull{Of: tickerError}}},
+				Resolve: runField(func(r *models.Run) any {
+					errs := make([]any, 0, len(r.Errors))
+					for _, ticker := range slices.Sorted(maps.Keys(r.Errors)) {
+						errs = append(errs, map[string]any{"ticker": ticker, "error": r.Errors[ticker]})
+					}
+					return errs
+				}),
+			},
+			"results": {
+				Type:        graphql.NonNull{Of: graphql.List{Of: graphql.NonNull{Of: result}}},
+				Description: "Results of the valued tickers",
+				Args:        resultArguments,
+				Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+					return selectResults(source.(*models.Run).Results, args)
+				},
+			},
+		},
+	}
+
+	historyPoint := &graphql.Object{
+		Name: "HistoryPoint",
+		Fields: map[string]*graphql.Field{
+			"run_id":      {Type: graphql.NonNull{Of: graphql.ID}},
+			"at":          {Type: graphql.NonNull{Of: graphql.String}},
+			"prices_only": {Type: graphql.Boolean},
+			"result":      {Type: graphql.NonNull{Of: result}},
+		},
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: map[string]*graphql.Field{
+			"valuations": {
+				Type:        graphql.NonNull{Of: graphql.List{Of: graphql.NonNull{Of: result}}},
+				Description: "Latest recorded valuation of each stock valued within the feed window",
+				Args:        resultArguments,
+				Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+					results, err := latestResults(s.store, time.Now().Add(-s.app.config.Server.FeedWindow()))
+					if err != nil {
+						return nil, err
+					}
+					return selectResults(results, args)
+				},
+			},
+			"valuation": {
+				Type:        result,
+				Description: "Values a ticker now, as GET /api/v1/valuation/{ticker} does",
+				Args:        []*graphql.Argument{{Name: "ticker", Type: graphql.NonNull{Of: graphql.String}}},
+				Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+					ticker := strings.ToUpper(args["ticker"].(string))
+					if !isTickerSymbol(ticker) {
+						return nil, fmt.Errorf("invalid ticker %q", ticker)
+					}
+					ctx, cancel := context.WithTimeout(ctx, time.Minute)
+					defer cancel()
+					v := s.app.analyzer.Valuate(ctx, ticker)
+					if v.Err != nil {
+						return nil, v.Err
+					}
+					return v.Result, nil
+				},
+			},
+			"runs": {
+				Type:        graphql.NonNull{Of: graphql.List{Of: graphql.NonNull{Of: run}}},
+				Description: "Recorded runs, newest first",
+				Args:        []*graphql.Argument{{Name: "first", Type: graphql.Int, Default: 20}},
+				Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+					runs, err := s.store.List()
+					if err != nil {
+						return nil, err
+					}
+					slices.Reverse(runs)
+					return firstN(runs, args)
+				},
+			},
+			"run": {
+				Type: run,
+				Args: []*graphql.Argument{{Name: "id", Type: graphql.NonNull{Of: graphql.ID}}},
+				Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+					run, err := s.store.Get(args["id"].(string))
+					if errors.Is(err, storage.ErrRunNotFound) {
+						return nil, nil
+					}
+					return run, err
+				},
+			},
+			"history": {
+				Type:        graphql.NonNull{Of: graphql.List{Of: graphql.NonNull{Of: historyPoint}}},
+				Description: "Recorded valuations of a ticker, oldest first",
+				Args: []*graphql.Argument{
+					{Name: "ticker", Type: graphql.NonNull{Of: graphql.String}},
+					{Name: "last", Type: graphql.Int, Description: "Only the most recent valuations"},
+				},
+				Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+					ticker := strings.ToUpper(args["ticker"].(string))
+					runs, err := s.store.List()
+					if err != nil {
+						return nil, err
+					}
+					points := []any{}
+					for _, run := range runs {
+						for _, r := range run.Results {
+							if r.Ticker == ticker && !r.Failed() {
+								points = append(points, map[string]any{
+									"run_id": run.ID, "at": formatTime(run.StartedAt), "prices_only": run.PricesOnly, "result": r,
+								})
+								break
+							}
+						}
+					}
+					if last, ok := args["last"].(int); ok && last >= 0 && len(points) > last {
+						points = points[len(points)-last:]
+					}
+					return points, nil
+				},
+			},
+			"fields": {
+				Type:        graphql.NonNull{Of: graphql.List{Of: graphql.NonNull{Of: graphql.String}}},
+				Description: "Fields screen conditions and sort_by can use",
+				Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+					return screener.Fields(), nil
+				},
+			},
+		},
+	}
+	return &graphql.Schema{Query: query}
+}
+
+// resultField resolves a field of a valuation result
+func resultField(read func(*models.ValuationResult) any) graphql.ResolveFunc {
+	return func(ctx context.Context, source any, args map[string]any) (any, error) {
+		return read(source.(*models.ValuationResult)), nil
+	}
+}
+
+// runField resolves a field of a run
+func runField(read func(*models.Run) any) graphql.ResolveFunc {
+	return func(ctx context.Context, source any, args map[string]any) (any, error) {
+		return read(source.(*models.Run)), nil
+	}
+}
+
+// selectResults filters, sorts and limits results by the arguments of a
+// results field, leaving out failed tickers
+func selectResults(results []*models.ValuationResult, args map[string]any) ([]*models.ValuationResult, error) {
+	where, _ := args["where"].(string)
+	criteria, err := screener.Parse(where)
+	if err != nil {
+		return nil, err
+	}
+	selected := criteria.Filter(results)
+
+	if tickers, ok := args["tickers"].([]any); ok {
+		wanted := make(map[string]bool, len(tickers))
+		for _, ticker := range tickers {
+			wanted[strings.ToUpper(ticker.(string))] = true
+		}
+		selected = slices.DeleteFunc(selected, func(r *models.ValuationResult) bool { return !wanted[r.Ticker] })
+	}
+
+	sortBy, _ := args["sort_by"].(string)
+	if _, ok := screener.Value(&models.ValuationResult{}, sortBy); !ok {
+		return nil, fmt.Errorf("unknown sort_by field %q", sortBy)
+	}
+	descending, _ := args["descending"].(bool)
+	sort.SliceStable(selected, func(i, j int) bool {
+		a, _ := screener.Value(selected[i], sortBy)
+		b, _ := screener.Value(selected[j], sortBy)
+		if x, ok := a.(float64); ok {
+			y := b.(float64)
+			// Unknown values go last either way
+			if math.IsNaN(x) || math.IsNaN(y) {
+				return !math.IsNaN(x) && math.IsNaN(y)
+			}
+			if descending {
+				return x > y
+			}
+			return x < y
+		}
+		if descending {
+			return a.(string) > b.(string)
+		}
+		return a.(string) < b.(string)
+	})
+	return firstN(selected, args)
+}
+
+// firstN returns the number of items the first argument asks for
+func firstN[T any](items []T, args map[string]any) ([]T, error) {
+	first, ok := args["first"].(int)
+	if !ok || first == 0 {
+		return items, nil
+	}
+	if first < 0 {
+		return nil, fmt.Errorf("first cannot be negative")
+	}
+	return items[:min(first, len(items))], nil
+}
+
+// formatTime formats t for the GraphQL API, null when zero
+func formatTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
+
+// nullString returns s, or null when empty
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// handleGraphQL executes a GraphQL query posted as JSON, or sent as the
+// query, operationName and variables parameters of a GET request
+func (s *apiServer) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if r.Method == http.MethodGet {
+		params := r.URL.Query()
+		req.Query, req.OperationName = params.Get("query"), params.Get("operationName")
+		if variables := params.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid variables: %w", err))
+				return
+			}
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	resp := s.schema.Execute(ctx, req)
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, resp)
+}
+
+// handleGraphQLSchema describes the GraphQL API in the schema definition
+// language
+func (s *apiServer) handleGraphQLSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, s.schema.String())
+}
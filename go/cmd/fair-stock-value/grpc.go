@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/lesnerd/fair-stock-value/go/api/fairvaluepb"
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+)
+
+// grpcServer implements the ValuationService gRPC API
+type grpcServer struct {
+	fairvaluepb.UnimplementedValuationServiceServer
+
+	app   *Application
+	store storage.RunStore
+}
+
+// newGRPCServer creates a gRPC service backed by app and store
+func newGRPCServer(app *Application, store storage.RunStore) *grpcServer {
+	return &grpcServer{app: app, store: store}
+}
+
+// ListenAndServe serves the gRPC API on addr until ctx is cancelled
+func (s *grpcServer) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(traceUnary),
+		grpc.StreamInterceptor(traceStream),
+	)
+	fairvaluepb.RegisterValuationServiceServer(server, s)
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.Serve(listener)
+	}()
+	log.Printf("Serving gRPC valuation API on %s", addr)
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Let in-flight calls finish before exiting
+	server.GracefulStop()
+	return nil
+}
+
+// Valuate values a single ticker
+func (s *grpcServer) Valuate(ctx context.Context, req *fairvaluepb.ValuateRequest) (*fairvaluepb.ValuateResponse, error) {
+	ticker := strings.ToUpper(strings.TrimSpace(req.GetTicker()))
+	if !isTickerSymbol(ticker) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid ticker %q", ticker)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	v := s.app.analyzer.Valuate(ctx, ticker)
+	if v.Err != nil {
+		return nil, status.Error(codes.Unavailable, v.Err.Error())
+	}
+
+	return valuateResponse(v), nil
+}
+
+// ValuateStream values a list of tickers, streaming each as it completes,
+// and stores the completed run
+func (s *grpcServer) ValuateStream(req *fairvaluepb.ValuateStreamRequest, stream fairvaluepb.ValuationService_ValuateStreamServer) error {
+	tickers := normalizeTickers(req.GetTickers())
+	if err := validateTickers(tickers, s.app.config.Server.MaxTickersPerRequest); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(stream.Context(), 5*time.Minute)
+	defer cancel()
+
+	startedAt := s.app.analyzer.Now()
+	valuations := make([]fairvalue.Valuation, len(tickers))
+	var sendErr error
+	s.app.analyzer.ValuateEach(ctx, tickers, func(i int, v fairvalue.Valuation) {
+		valuations[i] = v
+		if sendErr != nil {
+			return
+		}
+		if sendErr = stream.Send(valuateResponse(v)); sendErr != nil {
+			// The client is gone, so stop fetching the remaining tickers
+			cancel()
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+
+	run := s.app.analyzer.NewRun(startedAt, valuations)
+	s.app.analyzer.Stamp(run)
+	if err := s.store.Save(run); err != nil {
+		return status.Errorf(codes.Internal, "failed to store run: %v", err)
+	}
+	return nil
+}
+
+// GetRunHistory returns stored runs, newest first
+func (s *grpcServer) GetRunHistory(ctx context.Context, req *fairvaluepb.GetRunHistoryRequest) (*fairvaluepb.GetRunHistoryResponse, error) {
+	runs, err := s.store.List()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	ticker := strings.ToUpper(strings.TrimSpace(req.GetTicker()))
+	resp := &fairvaluepb.GetRunHistoryResponse{}
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runProto(runs[i], ticker)
+		if ticker != "" && len(run.Results) == 0 && len(run.Errors) == 0 {
+			continue
+		}
+		resp.Runs = append(resp.Runs, run)
+		if req.GetLimit() > 0 && len(resp.Runs) >= int(req.GetLimit()) {
+			break
+		}
+	}
+	return resp, nil
+}
+
+// valuateResponse converts a ticker's valuation to its protobuf form
+func valuateResponse(v fairvalue.Valuation) *fairvaluepb.ValuateResponse {
+	if v.Err != nil {
+		return &fairvaluepb.ValuateResponse{Ticker: v.Ticker, Error: v.Err.Error()}
+	}
+	return &fairvaluepb.ValuateResponse{
+		Ticker:    v.Ticker,
+		StockData: stockDataProto(v.StockData),
+		Result:    resultProto(v.Result),
+	}
+}
+
+// stockDataProto converts stock data to its protobuf form
+func stockDataProto(data *models.StockData) *fairvaluepb.StockData {
+	return &fairvaluepb.StockData{
+		Ticker:       data.Ticker,
+		CompanyName:  data.CompanyName,
+		CurrentPrice: data.CurrentPrice,
+		FcfPerShare:  data.FCFPerShare,
+		Eps:          data.EPS,
+		BookValue:    data.BookValue,
+		Sector:       data.Sector,
+		GrowthRate:   data.GrowthRate,
+		PeRatio:      data.PERatio,
+		MarketCap:    data.MarketCap,
+		FetchTime:    timestamppb.New(data.FetchTime),
+	}
+}
+
+// resultProto converts a valuation result to its protobuf form
+func resultProto(result *models.ValuationResult) *fairvaluepb.ValuationResult {
+	return &fairvaluepb.ValuationResult{
+		Ticker:           result.Ticker,
+		FairValue:        result.FairValue,
+		CurrentPrice:     result.CurrentPrice,
+		PriceDifference:  result.PriceDifference,
+		BookValue:        result.BookValue,
+		Status:           result.Status,
+		DcfValue:         result.DCFValue,
+		CompsValue:       result.CompsValue,
+		UpsidePercentage: result.UpsidePercentage,
+		PeRatio:          result.PERatio,
+		Eps:              result.EPS,
+		FcfPerShare:      result.FCFPerShare,
+		MarketCap:        result.MarketCap,
+		Sector:           result.Sector,
+		GrowthRate:       result.GrowthRate,
+		CompanyName:      result.CompanyName,
+		Error:            result.Error,
+	}
+}
+
+// runProto converts a run to its protobuf form, keeping only ticker's
+// results and errors when ticker is not empty
+func runProto(run *models.Run, ticker string) *fairvaluepb.Run {
+	pb := &fairvaluepb.Run{
+		Id:         run.ID,
+		StartedAt:  timestamppb.New(run.StartedAt),
+		FinishedAt: timestamppb.New(run.FinishedAt),
+		PricesOnly: run.PricesOnly,
+		Version:    run.Version,
+		ConfigHash: run.ConfigHash,
+		Config:     string(run.Config),
+	}
+	for _, result := range run.Results {
+		if ticker == "" || result.Ticker == ticker {
+			pb.Results = append(pb.Results, resultProto(result))
+		}
+	}
+	for errTicker, reason := range run.Errors {
+		if ticker == "" || errTicker == ticker {
+			if pb.Errors == nil {
+				pb.Errors = make(map[string]string)
+			}
+			pb.Errors[errTicker] = reason
+		}
+	}
+	return pb
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+// runHistory shows the past valuations of a ticker from recorded runs
+func runHistory(ctx context.Context, args []string) error {
+	fs := newFlagSet("history")
+	cfgFlags := registerConfigFlags(fs)
+	runsDir := fs.String("runs-dir", "", "Directory of runs recorded as JSON files (default: the configured history)")
+	from := fs.String("from", "", "Read runs from a JSON-lines file written by a jsonl sink instead")
+	archive := fs.String("archive", "", "Read runs from a directory written by an archive sink instead")
+	chart := fs.Bool("chart", false, "Plot price and fair value as an ASCII chart")
+	listTags := fs.Bool("tags", false, "List the run tags with the number and dates of their runs instead of a ticker's history")
+	limit := fs.Int("limit", 0, "Show only the most recent runs (0 = all)")
+	showColors := fs.Bool("colors", true, "Enable colored output")
+	tickers, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if *listTags != (len(tickers) == 0) || len(tickers) > 1 {
+		return fmt.Errorf("exactly one ticker is required, or -tags alone")
+	}
+
+	cfg, err := cfgFlags.load(fs)
+	if err != nil {
+		return err
+	}
+
+	store, err := openRecordedRuns(cfg, *from, *archive, *runsDir)
+	if err != nil {
+		return err
+	}
+
+	runs, err := storage.ListTagged(store, cfg.RunTags)
+	if err != nil {
+		return err
+	}
+	if *listTags {
+		if len(runs) == 0 {
+			return fmt.Errorf("no recorded runs%s", taggedWith(cfg.RunTags))
+		}
+		utils.DisplayRunTags(storage.SummarizeTags(runs), *showColors)
+		return nil
+	}
+	ticker := normalizeTickers(tickers)[0]
+
+	var points []utils.HistoryPoint
+	for _, run := range runs {
+		for _, result := range run.Results {
+			if result.Ticker == ticker && !result.Failed() {
+				points = append(points, utils.HistoryPoint{At: run.StartedAt, Result: result, PricesOnly: run.PricesOnly})
+				break
+			}
+		}
+	}
+	if len(points) == 0 {
+		return fmt.Errorf("no recorded runs include %s (%d runs recorded%s)", ticker, len(runs), taggedWith(cfg.RunTags))
+	}
+	if *limit > 0 && len(points) > *limit {
+		points = points[len(points)-*limit:]
+	}
+
+	if *chart {
+		utils.DisplayHistoryChart(ticker, points, *showColors)
+	} else {
+		utils.DisplayHistory(ticker, points, *showColors)
+		if hasPriceRefreshes(points) {
+			fmt.Println("* price refresh; fundamentals from the previous full run")
+		}
+	}
+	return nil
+}
+
+// taggedWith describes the run tags recorded runs were read under, for
+// messages about them
+func taggedWith(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return " tagged " + strings.Join(tags, ", ")
+}
+
+// hasPriceRefreshes reports whether any point comes from a price-only run
+func hasPriceRefreshes(points []utils.HistoryPoint) bool {
+	for _, point := range points {
+		if point.PricesOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// openRecordedRuns opens the runs read by history, backtest and trends:
+// the JSON-lines file from, else the archive directory archive, else the
+// JSON runs directory runsDir, else the configured history store
+func openRecordedRuns(cfg *config.Config, from, archive, runsDir string) (storage.RunStore, error) {
+	if from != "" {
+		return storage.NewJSONLinesStore(from), nil
+	}
+	if archive != "" {
+		return storage.NewArchiveStore(archive)
+	}
+	if runsDir != "" {
+		return storage.NewJSONStore(runsDir)
+	}
+	return openRunStore(cfg)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+)
+
+// jobResponse is a job together with its run once it has completed
+type jobResponse struct {
+	*models.Job
+	Run *models.Run `json:"run,omitempty"`
+}
+
+// handleSubmitJob queues an analysis job. Without tickers it analyzes the
+// configured universe.
+func (s *apiServer) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	var req analyzeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	tickers := normalizeTickers(req.Tickers)
+	if len(tickers) == 0 {
+		tickers = s.app.analyzer.Universe()
+	}
+	if err := validateTickers(tickers, s.app.config.Server.MaxTickersPerJob); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := s.jobs.Submit(tickers)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// handleListJobs returns all jobs without their ticker lists
+func (s *apiServer) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.jobs.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	for _, job := range jobs {
+		job.Tickers = nil
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// handleGetJob returns a job's progress, and its run once completed
+func (s *apiServer) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.jobs.Get(r.PathValue("id"))
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+
+	resp, err := s.jobResponse(job)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleCancelJob cancels a queued or running job
+func (s *apiServer) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.jobs.Cancel(r.PathValue("id"))
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// handleJobEvents streams a job's progress as server-sent events: a
+// "progress" event whenever tickers are valued and a final "done" event
+// with the finished job and its run
+func (s *apiServer) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	updates, stop, err := s.jobs.Subscribe(id)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher := http.NewResponseController(w)
+
+	send := func(event string, v interface{}) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return false
+		}
+		return flusher.Flush() == nil
+	}
+
+	// Start with the current state so clients need not poll first
+	if job, err := s.jobs.Get(id); err == nil && !job.Finished() {
+		job.Tickers = nil
+		if !send("progress", job) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case job, ok := <-updates:
+			if !ok {
+				s.sendJobDone(id, send)
+				return
+			}
+			job.Tickers = nil
+			if !send("progress", job) {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-s.shutdown:
+			return
+		}
+	}
+}
+
+// sendJobDone sends the final event of a job's event stream
+func (s *apiServer) sendJobDone(id string, send func(string, interface{}) bool) {
+	job, err := s.jobs.Get(id)
+	if err != nil {
+		send("error", errorResponse{Error: err.Error()})
+		return
+	}
+	resp, err := s.jobResponse(job)
+	if err != nil {
+		send("error", errorResponse{Error: err.Error()})
+		return
+	}
+	send("done", resp)
+}
+
+// jobResponse attaches the run of a completed job
+func (s *apiServer) jobResponse(job *models.Job) (jobResponse, error) {
+	resp := jobResponse{Job: job}
+	if job.RunID == "" {
+		return resp, nil
+	}
+	run, err := s.store.Get(job.RunID)
+	if err != nil {
+		return resp, fmt.Errorf("failed to load run of job %s: %w", job.ID, err)
+	}
+	resp.Run = run
+	return resp, nil
+}
+
+// writeJobError maps job lookup errors to a status
+func writeJobError(w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrJobNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
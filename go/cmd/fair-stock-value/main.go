@@ -0,0 +1,774 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"regexp"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata" // schedule timezones work without system zone data
+
+	"github.com/lesnerd/fair-stock-value/go/alerts"
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/market"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/services"
+	"github.com/lesnerd/fair-stock-value/go/sinks"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+	"github.com/lesnerd/fair-stock-value/go/symbols"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+func main() {
+	cmd, args, ok := dispatch(os.Args[1:])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+		showUsage()
+		os.Exit(2)
+	}
+
+	// The first SIGINT/SIGTERM cancels the context so commands can stop
+	// cleanly and report partial results; a second one kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := cmd.run(ctx, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintln(os.Stderr, "Interrupted")
+			os.Exit(130)
+		}
+		log.Fatalf("%s failed: %v", cmd.name, err)
+	}
+}
+
+// dispatch returns the command args invoke and the arguments left for it,
+// or false when the first argument is neither a command nor a ticker.
+// Without a subcommand the tool behaves like "analyze", so existing
+// invocations such as "fair-stock-value -test" keep working, and bare
+// tickers ("fair-stock-value AAPL MSFT") are valued in quick mode.
+func dispatch(args []string) (command, []string, bool) {
+	cmd, _ := lookupCommand("analyze")
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		found, ok := lookupCommand(args[0])
+		switch {
+		case ok:
+			return found, args[1:], true
+		case !isTickerSymbol(args[0]):
+			return command{}, args, false
+		}
+	}
+	return cmd, args, true
+}
+
+// stdin is the input of interactive commands
+var stdin io.Reader = os.Stdin
+
+// tickerPattern matches ticker symbols such as AAPL, BRK-B, BRK.B, BTC-USD
+// or the GC=F of a commodity future
+var tickerPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9.=\-]{0,9}$`)
+
+// isTickerSymbol reports whether arg looks like a ticker rather than a
+// command, ISINs and CUSIPs with a valid check digit included
+func isTickerSymbol(arg string) bool {
+	return tickerPattern.MatchString(arg) || symbols.IsIdentifier(arg)
+}
+
+// Application is the CLI front end of a fairvalue.Analyzer
+type Application struct {
+	config   *config.Config
+	analyzer *fairvalue.Analyzer
+	sinks    []sinks.Sink
+	alerts   *alerts.Engine   // nil when no alert rules are configured
+	history  storage.RunStore // nil when history is disabled
+	tickers  []string
+	market   *market.Calendar // trading hours of the configured exchange
+
+	// resume skips tickers recorded in the checkpoint of an interrupted run
+	resume bool
+
+	// retrySkipped values the tickers on the skip list along with the rest
+	retrySkipped bool
+
+	// refresh is refreshStale to value tickers recorded with fresh data on
+	// that data, fetching only the stale ones
+	refresh string
+
+	// stopAfter ends a run once this many underpriced stocks are found;
+	// 0 values the whole universe
+	stopAfter int
+
+	// output writes the results to a file instead of the table; nil shows
+	// the table
+	output *exportOptions
+
+	// httpTrace writes the requests of the data sources to the file
+	// config.TraceHTTP names; nil unless tracing
+	httpTrace *services.HTTPTrace
+}
+
+// NewApplication creates a new application instance
+func NewApplication(cfg *config.Config) (*Application, error) {
+	opts := []fairvalue.Option{fairvalue.WithLogger(services.NewWriterLogger(os.Stdout))}
+	var httpTrace *services.HTTPTrace
+	if cfg.TraceHTTP != "" {
+		file, err := os.Create(cfg.TraceHTTP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP trace: %w", err)
+		}
+		// Entries are written unbuffered as requests complete, so the
+		// file is left for the process to close
+		httpTrace = services.NewHTTPTrace(file)
+		opts = append(opts, fairvalue.WithHTTPTrace(httpTrace))
+	}
+	analyzer, err := fairvalue.New(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	sinkList, err := sinks.NewAll(cfg.Sinks)
+	if err != nil {
+		return nil, err
+	}
+
+	statePath, err := cfg.AlertStatePath()
+	if err != nil {
+		return nil, err
+	}
+	alertEngine, err := alerts.New(cfg.Alerts, statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err := cfg.Market.Calendar()
+	if err != nil {
+		return nil, err
+	}
+
+	// Table headers show the time runs are stamped with
+	if cfg.Processing.Deterministic {
+		utils.Now = analyzer.Now
+	}
+
+	app := &Application{config: cfg, analyzer: analyzer, sinks: sinkList, alerts: alertEngine, market: calendar, httpTrace: httpTrace}
+	if cfg.History.Enabled {
+		if app.history, err = openRunStore(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return app, nil
+}
+
+// openRunStore opens the store runs are recorded in. A new SQLite database
+// starts out with the runs kept as JSON files in the runs directory.
+func openRunStore(cfg *config.Config) (storage.RunStore, error) {
+	if cfg.History.Backend == config.HistoryJSON {
+		store, err := storage.NewJSONStore(cfg.Server.RunsDir)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+
+	path, err := cfg.HistoryDatabasePath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := storage.NewSQLiteStore(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := importJSONRuns(store, cfg.Server.RunsDir); err != nil {
+		fmt.Printf("Warning: failed to import runs into %s: %v\n", path, err)
+	}
+	return store, nil
+}
+
+// importJSONRuns copies the runs in the JSON runs directory into an empty
+// history database
+func importJSONRuns(db *storage.SQLiteStore, dir string) error {
+	if empty, err := db.Empty(); err != nil || !empty {
+		return err
+	}
+
+	if dir == "" {
+		var err error
+		if dir, err = storage.DefaultRunsDir(); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	runs, err := storage.NewJSONStore(dir)
+	if err != nil {
+		return err
+	}
+	n, err := storage.ImportRuns(db, runs)
+	if n > 0 {
+		fmt.Printf("Imported %d runs from %s into %s\n", n, dir, db.Path())
+	}
+	return err
+}
+
+// Run runs the stock valuation analysis, displays the results and
+// publishes the run. An interrupted run is still displayed and published,
+// marked as partial.
+func (app *Application) Run(ctx context.Context) error {
+	run, err := app.Analyze(ctx)
+	if run == nil {
+		return err
+	}
+
+	if app.output != nil {
+		if exportErr := app.export(run, app.output); exportErr != nil {
+			fmt.Printf("Warning: %v\n", exportErr)
+		}
+	} else {
+		app.Display(run.Results)
+	}
+	app.publish(ctx, run)
+	return err
+}
+
+// Analyze loads the ticker universe and values every stock in it. If ctx
+// is cancelled or processing times out, the stocks valued so far are
+// returned as a partial run together with the error.
+func (app *Application) Analyze(ctx context.Context) (*models.Run, error) {
+	fmt.Println("Starting stock valuation analysis...")
+
+	// Load tickers
+	app.loadTickers()
+
+	// Process stocks
+	run, err := app.processStocks(ctx)
+	if err != nil {
+		return run, fmt.Errorf("failed to process stocks: %w", err)
+	}
+
+	return run, nil
+}
+
+// publish records run in the history, sends it to the configured sinks
+// and evaluates alert rules against it
+func (app *Application) publish(ctx context.Context, run *models.Run) {
+	if app.history != nil && (!run.PricesOnly || app.config.History.RecordPriceRefreshes) {
+		if err := app.history.Save(run); err != nil {
+			fmt.Printf("Warning: failed to record run: %v\n", err)
+		}
+	}
+	app.notify(ctx, run)
+}
+
+// notify sends run to the configured sinks and evaluates alert rules
+// against it
+func (app *Application) notify(ctx context.Context, run *models.Run) {
+	// Partial runs are published after an interrupt, so don't let the
+	// cancelled context abort the export
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	for _, err := range sinks.PublishAll(ctx, app.sinks, run) {
+		fmt.Printf("Warning: failed to publish run: %v\n", err)
+	}
+
+	if app.alerts == nil {
+		return
+	}
+	fired, errs := app.alerts.Evaluate(ctx, run)
+	for _, alert := range fired {
+		fmt.Printf("Alert: %s\n", alert)
+	}
+	for _, err := range errs {
+		fmt.Printf("Warning: failed to send alerts: %v\n", err)
+	}
+}
+
+// DisplayDetails renders the full valuation of each ticker
+func (app *Application) DisplayDetails(valuations []fairvalue.Valuation) {
+	calculator := app.analyzer.Calculator()
+	var results []*models.ValuationResult
+	for _, v := range valuations {
+		if v.Err == nil {
+			results = append(results, v.Result)
+		}
+	}
+	app.annotate(results)
+	for i, v := range valuations {
+		if i > 0 {
+			fmt.Println()
+			fmt.Println(strings.Repeat("=", 60))
+		}
+		if v.Err != nil {
+			fmt.Printf("Error: %v\n", v.Err)
+			continue
+		}
+		if v.Result.Failed() {
+			fmt.Printf("%s: %s, price %.2f\n", v.Ticker, v.Result.Error, v.Result.CurrentPrice)
+			continue
+		}
+		utils.DisplayExplanation(v.StockData, v.Result, v.Breakdown,
+			calculator.GetDCFParameters(), calculator.GetCompsParameters(), app.config.Output.ShowColors)
+	}
+}
+
+// Display renders results using the configured output options
+func (app *Application) Display(results []*models.ValuationResult) {
+	columns, _ := app.config.Output.ResolveColumns()
+	utils.DisplayResults(results, utils.DisplayOptions{
+		ShowColors:          app.config.Output.ShowColors,
+		SortBy:              app.config.Output.SortBy,
+		ShowOnlyUnderpriced: app.config.Output.ShowOnlyUnderpriced,
+		MaxResults:          app.config.Output.MaxResults,
+		Columns:             columns,
+		Trend:               app.config.Output.Trend,
+		Benchmark:           app.analyzer.Benchmark(),
+		PageSize:            app.pageSize(),
+	})
+}
+
+// pageSize returns the rows per page of the results table, zero when it
+// is not paged
+func (app *Application) pageSize() int {
+	if !app.config.Output.Paging {
+		return 0
+	}
+	return utils.PageSize(app.config.Output.PageSize)
+}
+
+// loadTickers loads the ticker universe from config, CSV file or defaults
+func (app *Application) loadTickers() {
+	app.tickers = app.analyzer.Universe()
+	if len(app.config.DataSources.Tickers) > 0 {
+		fmt.Printf("Using configured tickers: %d stocks\n", len(app.tickers))
+		return
+	}
+	fmt.Printf("Loaded %d tickers for analysis\n", len(app.tickers))
+}
+
+// processStocks processes all stocks and returns them as a run. Every
+// valued ticker is recorded in the checkpoint file so an interrupted run
+// can be resumed with -resume.
+func (app *Application) processStocks(ctx context.Context) (*models.Run, error) {
+	startedAt := app.analyzer.Now()
+	valuations := make([]fairvalue.Valuation, len(app.tickers))
+
+	checkpoint, resumed, err := app.openCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the first pass of watch mode or a schedule reuses fresh data;
+	// later ones fetch everything anew
+	stale := app.refresh == refreshStale
+	app.refresh = ""
+	fresh, err := app.freshInputs(stale)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tickers valued by the interrupted run are taken from the checkpoint,
+	// and those with fresh recorded data are valued on it
+	var pending []string
+	var pendingIndex []int
+	reused := 0
+	for i, ticker := range app.tickers {
+		if entry, ok := resumed[ticker]; ok {
+			valuations[i] = fairvalue.Valuation{Ticker: ticker, Result: entry.Result}
+			continue
+		}
+		if stockData, ok := fresh[ticker]; ok {
+			valuations[i] = app.analyzer.ValuateStockData(ctx, stockData)
+			if valuations[i].Err == nil {
+				reused++
+				if err := checkpoint.Record(valuations[i].Result, app.analyzer.Now()); err != nil {
+					fmt.Printf("Warning: %v\n", err)
+				}
+				continue
+			}
+		}
+		pending = append(pending, ticker)
+		pendingIndex = append(pendingIndex, i)
+	}
+
+	// Tickers that kept failing are left out until their time on the skip
+	// list is up
+	skipList, err := app.openSkipList()
+	if err != nil {
+		return nil, err
+	}
+	skipped := make(map[string]bool)
+	if skipList != nil && !app.retrySkipped {
+		kept, keptIndex := pending[:0], pendingIndex[:0]
+		for j, ticker := range pending {
+			if skipList.Skipped(ticker, app.analyzer.Now()) {
+				skipped[ticker] = true
+				continue
+			}
+			kept, keptIndex = append(kept, ticker), append(keptIndex, pendingIndex[j])
+		}
+		pending, pendingIndex = kept, keptIndex
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("Skipping %d stocks that failed %d or more runs in a row (see cache skipped): %s\n",
+			len(skipped), app.config.Processing.SkipList.Threshold(), strings.Join(slices.Sorted(maps.Keys(skipped)), ", "))
+	}
+	if app.resume {
+		fmt.Printf("Resuming: %d stocks already valued, %d remaining\n",
+			len(app.tickers)-len(pending), len(pending))
+	}
+	if stale {
+		fmt.Printf("Refreshing stale data: %d stocks valued on data recorded within %gh, %d to refresh\n",
+			reused, app.config.Processing.CacheTTL().Hours(), len(pending))
+	}
+
+	pending, pendingIndex = app.prioritize(pending, pendingIndex)
+
+	// Growth sources whose layout changed are left out before valuing
+	if len(pending) > 0 {
+		app.analyzer.CheckScrapers(ctx)
+	}
+
+	if app.config.Processing.CacheOnly {
+		fmt.Println("Valuing on cached data only; stocks missing from the cache fail (fill it with the fetch command)")
+	}
+	if app.config.Processing.AdaptiveWorkers {
+		fmt.Printf("Processing %d stocks with adaptive workers (%d to start, %d-%d)...\n",
+			len(pending), app.analyzer.Workers(), app.config.Processing.MinWorkerCount(), app.config.Processing.MaxWorkers)
+	} else {
+		fmt.Printf("Processing %d stocks with %d parallel workers...\n",
+			len(pending), app.config.Processing.MaxWorkers)
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	// With -stop-after, valuing stops once enough underpriced stocks are
+	// found, cancelling the tickers still in flight
+	workCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	underpriced, stopped := 0, false
+
+	var failures []fairvalue.Valuation
+	var fetched []string // tickers valued on newly fetched data
+	completed, valued := 0, len(app.tickers)-len(pending)-len(skipped)
+	app.analyzer.ValuateEach(workCtx, pending, func(i int, v fairvalue.Valuation) {
+		valuations[pendingIndex[i]] = v
+		completed++
+		if app.config.Output.ShowProgress {
+			utils.ShowProgress(completed, len(pending), v.Ticker)
+		}
+
+		// Tickers cut short by an interrupt are reported as a partial run
+		// rather than as individual failures
+		if v.Err != nil && workCtx.Err() == nil {
+			failures = append(failures, v)
+		} else if v.Err == nil {
+			valued++
+			fetched = append(fetched, v.Ticker)
+			if err := checkpoint.Record(v.Result, app.analyzer.Now()); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+			if app.stopAfter > 0 && v.Result.Status == models.StatusUnderpriced && !stopped {
+				if underpriced++; underpriced == app.stopAfter {
+					stopped = true
+					stop()
+				}
+			}
+		}
+	})
+
+	// Tickers left unvalued by stopping early are left out of the run,
+	// unlike those that failed before it stopped
+	if stopped && ctx.Err() == nil {
+		failed := make(map[string]bool, len(failures))
+		for _, v := range failures {
+			failed[v.Ticker] = true
+		}
+		valuations = slices.DeleteFunc(valuations, func(v fairvalue.Valuation) bool {
+			return v.Err != nil && !failed[v.Ticker]
+		})
+	}
+
+	// Skipped tickers were never valued
+	if len(skipped) > 0 {
+		valuations = slices.DeleteFunc(valuations, func(v fairvalue.Valuation) bool {
+			return v.Result == nil && v.Err == nil
+		})
+	}
+
+	app.analyzer.ComparePeers(valuations)
+	run := app.analyzer.NewRun(startedAt, valuations)
+	app.analyzer.Stamp(run)
+	app.reviseEstimates(run)
+	app.annotate(run.Results)
+	run.Skipped = slices.Sorted(maps.Keys(skipped))
+
+	reportFailures(failures)
+	reportDegradedScrapers(run.DegradedScrapers)
+	if ctx.Err() == nil && !app.config.Processing.CacheOnly {
+		app.updateSkipList(skipList, fetched, failures)
+	}
+
+	if err := ctx.Err(); err != nil {
+		run.Partial = true
+		reason := "interrupted"
+		if errors.Is(err, context.DeadlineExceeded) {
+			reason = "timed out"
+			err = fmt.Errorf("processing timed out: %w", err)
+		}
+		fmt.Printf("\nWarning: PARTIAL RUN - processing %s after valuing %d of %d stocks\n",
+			reason, valued, len(app.tickers))
+		fmt.Println("Run again with -resume to continue where this run left off")
+		if closeErr := checkpoint.Close(); closeErr != nil {
+			fmt.Printf("Warning: %v\n", closeErr)
+		}
+		return run, err
+	}
+
+	if stopped {
+		run.Partial = true
+		fmt.Printf("\nStopped after finding %d underpriced stocks (valued %d of %d)\n",
+			underpriced, valued, len(app.tickers))
+		fmt.Println("Run again with -resume to value the rest")
+		if err := checkpoint.Close(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		return run, nil
+	}
+
+	// The run is complete, so there is nothing left to resume
+	if err := checkpoint.Remove(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	if app.config.Output.ShowProgress {
+		fmt.Printf("\nCompleted processing %d stocks\n", len(run.Results))
+		app.reportSourceStats()
+		app.reportHTTPTrace()
+	}
+
+	return run, nil
+}
+
+// reportFailures prints the tickers that could not be valued, grouped by
+// the cause of their failure
+func reportFailures(failures []fairvalue.Valuation) {
+	if len(failures) == 0 {
+		return
+	}
+
+	var causes []string
+	byCause := make(map[string][]fairvalue.Valuation)
+	for _, v := range failures {
+		cause := services.Cause(v.Err)
+		if _, ok := byCause[cause]; !ok {
+			causes = append(causes, cause)
+		}
+		byCause[cause] = append(byCause[cause], v)
+	}
+
+	fmt.Printf("\nWarning: %d stocks failed to process:\n", len(failures))
+	for _, cause := range causes {
+		group := byCause[cause]
+		tickers := make([]string, len(group))
+		for i, v := range group {
+			tickers[i] = v.Ticker
+		}
+		fmt.Printf("  %s (%d): %s\n", cause, len(group), strings.Join(tickers, ", "))
+		for _, v := range group {
+			fmt.Printf("    - %s: %v\n", v.Ticker, v.Err)
+		}
+	}
+}
+
+// openSkipList loads the skip list, or returns nil when it is disabled
+func (app *Application) openSkipList() (*storage.SkipList, error) {
+	if !app.config.Processing.SkipList.Enabled {
+		return nil, nil
+	}
+	return loadSkipList(app.config)
+}
+
+// updateSkipList records which tickers were valued and which failed for
+// reasons that retrying will not fix, such as unknown symbols. A run that
+// valued none says more about the network than about the tickers, so it
+// is not recorded.
+func (app *Application) updateSkipList(list *storage.SkipList, fetched []string, failures []fairvalue.Valuation) {
+	if list == nil || len(fetched) == 0 {
+		return
+	}
+	for _, ticker := range fetched {
+		list.Succeeded(ticker)
+	}
+	var added []string
+	for _, v := range failures {
+		if services.Retryable(v.Err) {
+			continue
+		}
+		if list.Failed(v.Ticker, services.Cause(v.Err), v.Err.Error(), app.analyzer.Now()) {
+			added = append(added, v.Ticker)
+		}
+	}
+	if err := list.Save(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if len(added) > 0 {
+		settings := app.config.Processing.SkipList
+		fmt.Printf("Added to the skip list for %d days after failing %d runs in a row: %s\n",
+			int(settings.Duration().Hours()/24), settings.Threshold(), strings.Join(added, ", "))
+	}
+}
+
+// reportDegradedScrapers prints the scrapers whose pages yielded nothing
+// for the known ticker of the scraper check, which contributed no data
+func reportDegradedScrapers(sources []string) {
+	if len(sources) == 0 {
+		return
+	}
+	fmt.Printf("\nWarning: %d scrapers found no values on their pages and are degraded: %s\n",
+		len(sources), strings.Join(sources, ", "))
+	fmt.Println("Their sites may have changed layout; degraded growth sources are left out of the consensus")
+}
+
+// reportHTTPTrace prints where the requests of the run were traced to,
+// or why the trace stopped being written
+func (app *Application) reportHTTPTrace() {
+	if app.httpTrace == nil {
+		return
+	}
+	if err := app.httpTrace.Err(); err != nil {
+		fmt.Printf("Warning: failed to write HTTP trace %s: %v\n", app.config.TraceHTTP, err)
+		return
+	}
+	fmt.Printf("Traced %d HTTP requests to %s\n", app.httpTrace.Requests(), app.config.TraceHTTP)
+}
+
+// reportSourceStats prints a table of how each data source responded, so
+// sources that contribute little stand out
+func (app *Application) reportSourceStats() {
+	stats := app.analyzer.SourceStats()
+	if len(stats) == 0 {
+		return
+	}
+	if app.config.Processing.AdaptiveWorkers {
+		fmt.Printf("Workers: %d (adaptive)\n", app.analyzer.Workers())
+	}
+	fmt.Println("\nData sources:")
+	fmt.Printf("  %-24s %8s %8s %7s %5s %5s %9s %7s %11s\n",
+		"SOURCE", "REQUESTS", "SUCCESS", "RATE", "4XX", "5XX", "THROTTLED", "FAILED", "AVG LATENCY")
+	for _, s := range stats {
+		latency := "-"
+		if s.Latency > 0 {
+			latency = s.Latency.Round(time.Millisecond).String()
+		}
+		fmt.Printf("  %-24s %8d %8d %6.0f%% %5d %5d %9d %7d %11s\n",
+			s.Source, s.Requests, s.Succeeded, s.SuccessRate()*100,
+			s.ClientErrors, s.ServerErrors, s.Throttled, s.Failed, latency)
+	}
+}
+
+// Values of -refresh
+const (
+	refreshStale = "stale" // fetch only tickers without fresh data
+	refreshAll   = "all"   // fetch every ticker, ignoring cached data
+)
+
+// freshInputs returns, when stale tickers are to be refreshed, the data of
+// the recorded runs fetched within the cache expiry, by ticker. Stale
+// tickers may still be served by the cache.
+func (app *Application) freshInputs(stale bool) (map[string]*models.StockData, error) {
+	if !stale || app.history == nil {
+		return nil, nil
+	}
+	since := app.analyzer.Now().Add(-app.config.Processing.CacheTTL())
+	fresh, err := storage.FreshInputs(app.history, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded data: %w", err)
+	}
+	return fresh, nil
+}
+
+// reviseEstimates sets the estimate revisions of the results of run from
+// the recorded runs of the revision window
+func (app *Application) reviseEstimates(run *models.Run) {
+	window := app.config.History.RevisionWindow()
+	if app.history == nil || window <= 0 {
+		return
+	}
+	if err := storage.ReviseEstimates(app.history, run, window); err != nil {
+		fmt.Printf("Warning: failed to read recorded estimates: %v\n", err)
+	}
+}
+
+// annotate sets the notes kept on the tickers of results in the history
+// database
+func (app *Application) annotate(results []*models.ValuationResult) {
+	store, ok := app.history.(storage.NoteStore)
+	if !ok {
+		return
+	}
+	if err := storage.AnnotateResults(store, results); err != nil {
+		fmt.Printf("Warning: failed to read notes: %v\n", err)
+	}
+}
+
+// setRefresh applies a -refresh mode
+func (app *Application) setRefresh(mode string) error {
+	switch mode {
+	case "":
+	case refreshStale:
+		if app.history == nil && !app.config.Processing.EnableCaching {
+			return fmt.Errorf("-refresh stale needs recorded runs or the cache to take fresh data from")
+		}
+	case refreshAll:
+		if app.config.Processing.CacheOnly {
+			return fmt.Errorf("-refresh all cannot be combined with -cache-only")
+		}
+		app.analyzer.SetForceRefresh(true)
+	default:
+		return fmt.Errorf("unknown -refresh mode %q (expected %s or %s)", mode, refreshStale, refreshAll)
+	}
+	app.refresh = mode
+	return nil
+}
+
+// openCheckpoint opens the checkpoint file for this run and, when resuming,
+// returns the tickers already valued within the cache TTL
+func (app *Application) openCheckpoint() (*storage.Checkpoint, map[string]storage.CheckpointEntry, error) {
+	path, err := app.config.Processing.CheckpointPath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resumed map[string]storage.CheckpointEntry
+	if app.resume {
+		if resumed, err = storage.LoadCheckpoint(path, app.config.Processing.CacheTTL(), app.analyzer.Now()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	checkpoint, err := storage.OpenCheckpoint(path, app.resume)
+	if err != nil {
+		return nil, nil, err
+	}
+	return checkpoint, resumed, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDispatch(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		command string // "" when the arguments are rejected
+		rest    []string
+	}{
+		{nil, "analyze", nil},
+		{[]string{"-test"}, "analyze", []string{"-test"}},
+		{[]string{"serve", "-addr", ":8080"}, "serve", []string{"-addr", ":8080"}},
+		{[]string{"AAPL", "MSFT"}, "analyze", []string{"AAPL", "MSFT"}},
+		{[]string{"BRK.B"}, "analyze", []string{"BRK.B"}},
+		{[]string{"GC=F"}, "analyze", []string{"GC=F"}},
+		{[]string{"US0378331005"}, "analyze", []string{"US0378331005"}},           // ISIN of Apple
+		{[]string{"037833100", "AAPL"}, "analyze", []string{"037833100", "AAPL"}}, // CUSIP of Apple
+		{[]string{"US0378331006"}, "", nil},                                       // wrong check digit
+		{[]string{"037833101"}, "", nil},
+		{[]string{"analyse"}, "analyze", []string{"analyse"}}, // looks like a ticker
+		{[]string{"no-such_command"}, "", nil},
+	} {
+		cmd, rest, ok := dispatch(tc.args)
+		if tc.command == "" {
+			if ok {
+				t.Errorf("dispatch(%q) chose %s, want the arguments rejected", tc.args, cmd.name)
+			}
+			continue
+		}
+		if !ok || cmd.name != tc.command || !slices.Equal(rest, tc.rest) {
+			t.Errorf("dispatch(%q) = %s %q (%v), want %s %q", tc.args, cmd.name, rest, ok, tc.command, tc.rest)
+		}
+	}
+}
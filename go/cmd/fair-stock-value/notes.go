@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+// runNotes adds, lists or deletes the notes kept on tickers in the
+// history database, which later runs show with the tickers' results
+func runNotes(ctx context.Context, args []string) error {
+	fs := newFlagSet("notes")
+	configFile := fs.String("config", "", "Path to JSON configuration file")
+	rating := fs.Int("rating", 0, fmt.Sprintf("Rate the ticker from 1 to %d with \"notes add\"", models.MaxRating))
+	showColors := fs.Bool("colors", true, "Enable colored output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("expected one of: add, list, delete")
+	}
+	// Flags may also follow the action; words after the ticker of a note
+	// added are its text, even those starting with a dash
+	action := fs.Arg(0)
+	var err error
+	if action == "add" {
+		err = fs.Parse(fs.Args()[1:])
+		args = fs.Args()
+	} else {
+		args, err = parseInterspersed(fs, fs.Args()[1:])
+	}
+	if err != nil {
+		return err
+	}
+
+	cfg := config.NewDefaultConfig()
+	if *configFile != "" {
+		loaded, err := config.LoadFromFile(*configFile)
+		if err != nil {
+			return err
+		}
+		cfg = loaded
+	}
+	store, err := openNoteStore(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "add":
+		if len(args) == 0 {
+			return fmt.Errorf("usage: notes add [-rating N] TICKER [TEXT...]")
+		}
+		if *rating < 0 || *rating > models.MaxRating {
+			return fmt.Errorf("rating must be between 1 and %d", models.MaxRating)
+		}
+		note := &models.Note{
+			Ticker: normalizeTickers(args[:1])[0],
+			Text:   strings.Join(args[1:], " "),
+			Rating: *rating,
+			At:     time.Now(),
+		}
+		if note.Text == "" && note.Rating == 0 {
+			return fmt.Errorf("a note needs a text, a -rating or both")
+		}
+		if err := store.AddNote(note); err != nil {
+			return err
+		}
+		fmt.Printf("Added note %d on %s\n", note.ID, note.Ticker)
+	case "list":
+		if len(args) > 1 {
+			return fmt.Errorf("usage: notes list [TICKER]")
+		}
+		ticker := ""
+		if len(args) == 1 {
+			ticker = normalizeTickers(args)[0]
+		}
+		notes, err := store.Notes(ticker)
+		if err != nil {
+			return err
+		}
+		if len(notes) == 0 {
+			fmt.Println("No notes kept")
+			return nil
+		}
+		utils.DisplayNotes(notes, *showColors)
+	case "delete":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: notes delete ID")
+		}
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid note ID %q", args[0])
+		}
+		if err := store.DeleteNote(id); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted note %d\n", id)
+	default:
+		return fmt.Errorf("unknown notes action %q (expected add, list or delete)", action)
+	}
+	return nil
+}
+
+// openNoteStore opens the history database notes are kept in
+func openNoteStore(cfg *config.Config) (storage.NoteStore, error) {
+	if cfg.History.Backend == config.HistoryJSON {
+		return nil, fmt.Errorf("notes are kept in the history database, not with the JSON history backend")
+	}
+	store, err := openRunStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return store.(storage.NoteStore), nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/portfolio"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+// runPortfolio values the holdings in a CSV file
+func runPortfolio(ctx context.Context, args []string) error {
+	fs := newFlagSet("portfolio")
+	cfgFlags := registerConfigFlags(fs)
+	file := fs.String("file", "", "Holdings CSV with ticker, shares and cost basis columns, or a brokerage positions export")
+	broker := fs.String("broker", "", "Format of -file: generic, "+strings.Join(portfolio.Brokers, ", ")+" (default detected)")
+	showColors := fs.Bool("colors", true, "Enable colored output")
+	showProgress := fs.Bool("progress", true, "Show progress indicators")
+	threshold := fs.Float64("threshold", portfolio.DefaultRebalanceOptions().Threshold, "Minimum upside or downside percentage for rebalancing candidates")
+	maxWeight := fs.Float64("max-weight", portfolio.DefaultRebalanceOptions().MaxWeight*100, "Portfolio percentage above which a position is a trim candidate (0 = no limit)")
+	trials := fs.Int("simulate", 0, "Monte Carlo trials of the total fair value on drawn assumptions (0 = none)")
+	correlation := fs.Float64("correlation", portfolio.DefaultSimulationOptions().Correlation, "Correlation of the assumption draws of any two holdings in -simulate, from 0 to 1")
+	seed := fs.Int64("seed", portfolio.DefaultSimulationOptions().Seed, "Seed of the -simulate draws")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+	if *trials < 0 {
+		return fmt.Errorf("-simulate cannot be negative")
+	}
+	if *correlation < 0 || *correlation > 1 {
+		return fmt.Errorf("-correlation must be between 0 and 1")
+	}
+
+	imported, err := portfolio.ImportHoldings(*file, strings.ToLower(*broker))
+	if err != nil {
+		return err
+	}
+	holdings := imported.Holdings
+	if imported.Broker != portfolio.Generic {
+		fmt.Printf("Read %d holdings (%s export)\n", len(holdings), imported.Broker)
+	}
+	if len(imported.Skipped) > 0 {
+		skipped := make([]string, len(imported.Skipped))
+		for i, row := range imported.Skipped {
+			skipped[i] = fmt.Sprintf("%s (%s)", row.Symbol, row.Reason)
+		}
+		fmt.Printf("Skipped positions other than stocks: %s\n", strings.Join(skipped, ", "))
+	}
+
+	app, err := newApplicationFromFlags(fs, cfgFlags, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	// Holdings are valued under canonical tickers, so custodian exports
+	// listing ISINs or CUSIPs and other notations report like the rest;
+	// identifiers without a listing stay as they are and fail
+	tickers := make([]string, len(holdings))
+	for i, holding := range holdings {
+		tickers[i] = holding.Ticker
+	}
+	resolved, err := app.analyzer.Resolve(ctx, tickers)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	holdings = portfolio.MapTickers(holdings, func(ticker string) string {
+		if canonical, ok := resolved[ticker]; ok {
+			return canonical
+		}
+		return ticker
+	})
+	tickers = tickers[:len(holdings)]
+	for i, holding := range holdings {
+		tickers[i] = holding.Ticker
+	}
+
+	valuations := make([]fairvalue.Valuation, len(tickers))
+	completed := 0
+	app.analyzer.ValuateEach(ctx, tickers, func(i int, v fairvalue.Valuation) {
+		completed++
+		if *showProgress {
+			utils.ShowProgress(completed, len(tickers), v.Ticker)
+		}
+		valuations[i] = v
+	})
+	if *showProgress {
+		utils.ClearLine()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	app.analyzer.ComparePeers(valuations)
+	results := make(map[string]*models.ValuationResult)
+	failures := make(map[string]string)
+	for _, v := range valuations {
+		if v.Err != nil {
+			failures[v.Ticker] = v.Err.Error()
+		} else {
+			results[v.Ticker] = v.Result
+		}
+	}
+
+	report := portfolio.NewReport(holdings, results, failures, portfolio.RebalanceOptions{
+		Threshold: *threshold,
+		MaxWeight: *maxWeight / 100,
+	})
+	utils.DisplayPortfolio(report, *showColors)
+
+	opts := portfolio.DefaultSimulationOptions()
+	opts.Trials, opts.Correlation, opts.Seed = *trials, *correlation, *seed
+	if simulation := portfolio.Simulate(report, app.analyzer.Calculator(), opts); simulation != nil {
+		utils.DisplaySimulation(simulation, *showColors)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/lesnerd/fair-stock-value/go/storage"
+)
+
+// Priorities of pending tickers, lowest first
+const (
+	priorityWatchlist = iota
+	priorityFailed
+	priorityOther
+)
+
+// prioritize reorders the pending tickers, along with their positions in
+// the universe, so watchlist tickers are valued first and those that
+// failed in the previous run next. Tickers otherwise keep their order.
+func (app *Application) prioritize(pending []string, index []int) ([]string, []int) {
+	watchlist := make(map[string]bool)
+	for _, ticker := range app.config.DataSources.Watchlist {
+		watchlist[app.analyzer.Canonical(ticker)] = true
+	}
+	failed := app.previousFailures()
+
+	priorities := make([]int, len(pending))
+	counts := make([]int, priorityOther+1)
+	for i, ticker := range pending {
+		switch {
+		case watchlist[ticker]:
+			priorities[i] = priorityWatchlist
+		case failed[ticker]:
+			priorities[i] = priorityFailed
+		default:
+			priorities[i] = priorityOther
+		}
+		counts[priorities[i]]++
+	}
+	if counts[priorityWatchlist] == 0 && counts[priorityFailed] == 0 {
+		return pending, index
+	}
+	fmt.Printf("Prioritizing %d watchlist and %d previously failed stocks\n",
+		counts[priorityWatchlist], counts[priorityFailed])
+
+	order := make([]int, len(pending))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return priorities[order[i]] < priorities[order[j]]
+	})
+	tickers := make([]string, len(pending))
+	positions := make([]int, len(pending))
+	for i, k := range order {
+		tickers[i] = pending[k]
+		positions[i] = index[k]
+	}
+	return tickers, positions
+}
+
+// previousFailures returns the tickers that could not be valued in the
+// latest recorded run, or nil without history
+func (app *Application) previousFailures() map[string]bool {
+	if app.history == nil {
+		return nil
+	}
+	run, err := storage.LatestRun(app.history)
+	if err != nil {
+		if !errors.Is(err, storage.ErrRunNotFound) {
+			fmt.Printf("Warning: could not read the previous run: %v\n", err)
+		}
+		return nil
+	}
+	failed := make(map[string]bool, len(run.Errors))
+	for ticker := range run.Errors {
+		failed[ticker] = true
+	}
+	return failed
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	rpprof "runtime/pprof"
+)
+
+// profileFlags are the flags for diagnosing the performance of a command
+type profileFlags struct {
+	pprofAddr  *string
+	cpuProfile *string
+	memProfile *string
+}
+
+// registerProfileFlags defines the profiling flags on fs
+func registerProfileFlags(fs *flag.FlagSet) *profileFlags {
+	return &profileFlags{
+		pprofAddr:  fs.String("pprof", "", "Serve net/http/pprof profiles on this address, such as localhost:6060, while the command runs"),
+		cpuProfile: fs.String("cpuprofile", "", "Write a CPU profile of the command to this file"),
+		memProfile: fs.String("memprofile", "", "Write a heap profile to this file when the command finishes"),
+	}
+}
+
+// start starts the requested profiling and returns a function that stops
+// it, writing the profiles to their files
+func (f *profileFlags) start() (func(), error) {
+	var stops []func()
+	stop := func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
+	if *f.pprofAddr != "" {
+		closeServer, err := servePprof(*f.pprofAddr)
+		if err != nil {
+			return nil, err
+		}
+		stops = append(stops, closeServer)
+	}
+
+	if *f.cpuProfile != "" {
+		file, err := os.Create(*f.cpuProfile)
+		if err != nil {
+			stop()
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := rpprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			stop()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		stops = append(stops, func() {
+			rpprof.StopCPUProfile()
+			if err := file.Close(); err != nil {
+				fmt.Printf("Warning: failed to write CPU profile: %v\n", err)
+			}
+		})
+	}
+
+	if *f.memProfile != "" {
+		path := *f.memProfile
+		stops = append(stops, func() {
+			if err := writeHeapProfile(path); err != nil {
+				fmt.Printf("Warning: failed to write heap profile: %v\n", err)
+			}
+		})
+	}
+
+	return stop, nil
+}
+
+// servePprof serves the net/http/pprof handlers on addr until the returned
+// function is called. The handlers get a mux of their own, so they are
+// only reachable on this address and never through the REST API.
+func servePprof(addr string) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serve pprof: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("Warning: pprof server stopped: %v\n", err)
+		}
+	}()
+	fmt.Printf("Serving pprof on http://%s/debug/pprof/\n", listener.Addr())
+	return func() { server.Close() }, nil
+}
+
+// writeHeapProfile writes the live heap, after a garbage collection, to path
+func writeHeapProfile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	runtime.GC()
+	if err := rpprof.WriteHeapProfile(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
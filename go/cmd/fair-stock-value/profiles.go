@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+// runProfiles values the universe under several assumption profiles,
+// fetching every stock once, and compares the fair values side by side
+func runProfiles(ctx context.Context, args []string) error {
+	fs := newFlagSet("profiles")
+	cfgFlags := registerConfigFlags(fs)
+	onlyFlips := fs.Bool("flips", false, "Only list stocks whose status differs between profiles")
+	list := fs.Bool("list", false, "List the available profiles")
+	showColors := fs.Bool("colors", true, "Enable colored output")
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	app, err := newApplicationFromFlags(fs, cfgFlags, nil)
+	if err != nil {
+		return err
+	}
+	if *list {
+		fmt.Println(strings.Join(app.config.ProfileNames(), "\n"))
+		return nil
+	}
+	if len(names) == 0 {
+		names = app.config.ProfileNames()
+	}
+	if len(names) < 2 {
+		return fmt.Errorf("at least two profiles are required")
+	}
+
+	// Profiles are checked before anything is fetched
+	profiles := make([]*config.Config, len(names))
+	for i, name := range names {
+		if profiles[i], err = app.config.Profile(name); err != nil {
+			return err
+		}
+	}
+
+	app.loadTickers()
+	fmt.Printf("Processing %d stocks under %d profiles: %s\n", len(app.tickers), len(names), strings.Join(names, ", "))
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	valuations := app.analyzer.ValuateAll(ctx, app.tickers)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	stocks := make([]utils.ProfileComparison, 0, len(valuations))
+	for _, v := range valuations {
+		if v.Err != nil {
+			fmt.Printf("Warning: %v\n", v.Err)
+			continue
+		}
+		if v.Result.Failed() {
+			fmt.Printf("Warning: %s\n", v.Result.Error)
+			continue
+		}
+		stocks = append(stocks, utils.ProfileComparison{
+			Ticker:  v.Ticker,
+			Price:   v.StockData.CurrentPrice,
+			Results: make([]*models.ValuationResult, len(profiles)),
+		})
+	}
+	if len(stocks) == 0 {
+		return fmt.Errorf("none of the tickers could be valued")
+	}
+	for i, profile := range profiles {
+		j := 0
+		for _, v := range app.analyzer.ValueUnder(profile, valuations) {
+			if v.Err == nil && !v.Result.Failed() {
+				stocks[j].Results[i] = v.Result
+				j++
+			}
+		}
+	}
+
+	utils.DisplayProfileComparison(names, stocks, *onlyFlips, *showColors)
+	return nil
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/screener"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+// replParam is a setting that can be changed with "set" in the REPL
+type replParam struct {
+	name        string
+	description string
+	get         func(cfg *config.Config) string
+	set         func(cfg *config.Config, value string) error
+}
+
+// replParams are the settings adjustable in the REPL
+var replParams = []replParam{
+	{"discount", "DCF discount rate",
+		func(cfg *config.Config) string { return formatFloat(cfg.DCFParams.DiscountRate) },
+		func(cfg *config.Config, v string) error { return parseFloatInto(v, &cfg.DCFParams.DiscountRate) }},
+	{"terminal", "DCF terminal growth rate",
+		func(cfg *config.Config) string { return formatFloat(cfg.DCFParams.TerminalGrowthRate) },
+		func(cfg *config.Config, v string) error { return parseFloatInto(v, &cfg.DCFParams.TerminalGrowthRate) }},
+	{"max-growth", "Cap on the projected growth rate",
+		func(cfg *config.Config) string { return formatFloat(cfg.DCFParams.MaxGrowthRate) },
+		func(cfg *config.Config, v string) error { return parseFloatInto(v, &cfg.DCFParams.MaxGrowthRate) }},
+	{"years", "DCF projection years",
+		func(cfg *config.Config) string { return strconv.Itoa(cfg.DCFParams.ProjectionYears) },
+		func(cfg *config.Config, v string) error { return parseIntInto(v, &cfg.DCFParams.ProjectionYears) }},
+	{"pe-factor", "Conservative factor applied to the P/E ratio",
+		func(cfg *config.Config) string { return formatFloat(cfg.CompsParams.PEConservativeFactor) },
+		func(cfg *config.Config, v string) error {
+			return parseFloatInto(v, &cfg.CompsParams.PEConservativeFactor)
+		}},
+	{"min-pe", "Lower bound of the P/E ratio",
+		func(cfg *config.Config) string { return formatFloat(cfg.CompsParams.MinPERatio) },
+		func(cfg *config.Config, v string) error { return parseFloatInto(v, &cfg.CompsParams.MinPERatio) }},
+	{"max-pe", "Upper bound of the P/E ratio",
+		func(cfg *config.Config) string { return formatFloat(cfg.CompsParams.MaxPERatio) },
+		func(cfg *config.Config, v string) error { return parseFloatInto(v, &cfg.CompsParams.MaxPERatio) }},
+	{"dcf-weight", "Weight of the DCF value (Comps gets the rest)",
+		func(cfg *config.Config) string { return formatFloat(cfg.Weights.DCFWeight) },
+		func(cfg *config.Config, v string) error {
+			if err := parseFloatInto(v, &cfg.Weights.DCFWeight); err != nil {
+				return err
+			}
+			cfg.Weights.CompsWeight = 1 - cfg.Weights.DCFWeight
+			return nil
+		}},
+	{"sort", "Sort order: " + strings.Join(utils.SortKeys, ", "),
+		func(cfg *config.Config) string { return cfg.Output.SortBy },
+		func(cfg *config.Config, v string) error {
+			for _, key := range utils.SortKeys {
+				if key == v {
+					cfg.Output.SortBy = v
+					return nil
+				}
+			}
+			return fmt.Errorf("unknown sort order %q", v)
+		}},
+	{"limit", "Maximum number of rows shown (0 = no limit)",
+		func(cfg *config.Config) string { return strconv.Itoa(cfg.Output.MaxResults) },
+		func(cfg *config.Config, v string) error { return parseIntInto(v, &cfg.Output.MaxResults) }},
+	{"preset", "Column preset",
+		func(cfg *config.Config) string { return cfg.Output.Preset },
+		func(cfg *config.Config, v string) error {
+			cfg.Output.Preset = v
+			cfg.Output.Columns = nil
+			return nil
+		}},
+}
+
+// replSession is the state of an interactive session
+type replSession struct {
+	app      *Application
+	baseline config.Config // configuration restored by "reset"
+
+	// stockData holds every stock fetched in the session; calculations
+	// are re-run against it without fetching again
+	stockData map[string]*models.StockData
+}
+
+// runREPL starts an interactive session
+func runREPL(ctx context.Context, args []string) error {
+	fs := newFlagSet("repl")
+	cfgFlags := registerConfigFlags(fs)
+	outFlags := registerOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app, err := newApplicationFromFlags(fs, cfgFlags, outFlags)
+	if err != nil {
+		return err
+	}
+
+	session := &replSession{
+		app:       app,
+		baseline:  *app.config,
+		stockData: make(map[string]*models.StockData),
+	}
+	return session.run(ctx, bufio.NewScanner(readerWithContext(ctx)))
+}
+
+// run reads and executes commands until "quit", end of input or ctx is cancelled
+func (s *replSession) run(ctx context.Context, scanner *bufio.Scanner) error {
+	fmt.Println("Fair Stock Value interactive mode. Type \"help\" for commands.")
+	for {
+		fmt.Print("fsv> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return scanner.Err()
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		name, args := strings.ToLower(fields[0]), fields[1:]
+		if name == "quit" || name == "exit" {
+			return nil
+		}
+		if err := s.execute(ctx, name, args); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	}
+}
+
+// execute runs a single REPL command
+func (s *replSession) execute(ctx context.Context, name string, args []string) error {
+	switch name {
+	case "help", "?":
+		s.showHelp()
+	case "value":
+		return s.value(ctx, args)
+	case "explain":
+		return s.explain(ctx, args)
+	case "load":
+		return s.load(ctx, args)
+	case "screen":
+		return s.screen(args)
+	case "set":
+		return s.set(args)
+	case "params":
+		s.showParams()
+	case "reset":
+		*s.app.config = s.baseline
+		s.applyParams()
+		fmt.Println("Parameters reset")
+	case "tickers":
+		fmt.Println(strings.Join(s.loadedTickers(), " "))
+	default:
+		return fmt.Errorf("unknown command %q (type \"help\" for commands)", name)
+	}
+	return nil
+}
+
+// showHelp lists the REPL commands
+func (s *replSession) showHelp() {
+	fmt.Println("Commands:")
+	fmt.Println("  value TICKER...        Value stocks (fetched once, then recalculated from memory)")
+	fmt.Println("  explain TICKER         Show every step of a stock's valuation")
+	fmt.Println("  load [TICKER...]       Fetch stocks, or the whole configured universe")
+	fmt.Println("  screen [CONDITION...]  Re-value loaded stocks and list those matching, e.g. upside>20 pe<15")
+	fmt.Println("  set PARAM VALUE        Change a parameter, e.g. set discount 0.10")
+	fmt.Println("  params                 Show current parameters")
+	fmt.Println("  reset                  Restore the parameters from the configuration")
+	fmt.Println("  tickers                List loaded stocks")
+	fmt.Println("  quit                   Leave interactive mode")
+	fmt.Println()
+	fmt.Printf("Screen fields: %s\n", strings.Join(screener.Fields(), ", "))
+}
+
+// value values the given tickers with the current parameters
+func (s *replSession) value(ctx context.Context, tickers []string) error {
+	if len(tickers) == 0 {
+		return fmt.Errorf("usage: value TICKER...")
+	}
+
+	tickers = normalizeTickers(tickers)
+	s.fetch(ctx, tickers)
+	s.app.Display(s.calculate(tickers))
+	return nil
+}
+
+// explain shows the full valuation of a single ticker
+func (s *replSession) explain(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: explain TICKER")
+	}
+
+	ticker := normalizeTickers(args)[0]
+	s.fetch(ctx, []string{ticker})
+	stockData, ok := s.stockData[ticker]
+	if !ok {
+		return fmt.Errorf("no data for %s", ticker)
+	}
+	if stockData.PriceOnly() {
+		return fmt.Errorf("%s", models.NewNotValuableResult(stockData).Error)
+	}
+
+	calculator := s.app.analyzer.Calculator()
+	utils.DisplayExplanation(stockData, calculator.CalculateFairValue(stockData), calculator.Explain(stockData),
+		calculator.GetDCFParameters(), calculator.GetCompsParameters(), s.app.config.Output.ShowColors)
+	return nil
+}
+
+// load fetches the given tickers, or the configured universe
+func (s *replSession) load(ctx context.Context, tickers []string) error {
+	if len(tickers) == 0 {
+		tickers = s.app.analyzer.Universe()
+	}
+	s.fetch(ctx, normalizeTickers(tickers))
+	fmt.Printf("%d stocks loaded\n", len(s.stockData))
+	return nil
+}
+
+// screen re-values every loaded stock and shows those matching the conditions
+func (s *replSession) screen(args []string) error {
+	criteria, err := screener.Parse(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	if len(s.stockData) == 0 {
+		return fmt.Errorf("no stocks loaded; use \"load\" first")
+	}
+
+	results := s.calculate(s.loadedTickers())
+	matches := criteria.Filter(results)
+	fmt.Printf("%d of %d stocks match\n", len(matches), len(results))
+	s.app.Display(matches)
+	return nil
+}
+
+// set changes a parameter after validating the resulting configuration
+func (s *replSession) set(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: set PARAM VALUE (type \"params\" for parameters)")
+	}
+
+	for _, param := range replParams {
+		if param.name != strings.ToLower(args[0]) {
+			continue
+		}
+
+		updated := *s.app.config
+		if err := param.set(&updated, args[1]); err != nil {
+			return err
+		}
+		if err := updated.Validate(); err != nil {
+			return err
+		}
+
+		*s.app.config = updated
+		s.applyParams()
+		fmt.Printf("%s = %s\n", param.name, param.get(s.app.config))
+		return nil
+	}
+	return fmt.Errorf("unknown parameter %q (type \"params\" for parameters)", args[0])
+}
+
+// showParams prints every adjustable parameter
+func (s *replSession) showParams() {
+	for _, param := range replParams {
+		fmt.Printf("  %-12s %-10s %s\n", param.name, param.get(s.app.config), param.description)
+	}
+}
+
+// applyParams pushes the configured model parameters to the calculator
+func (s *replSession) applyParams() {
+	calculator := s.app.analyzer.Calculator()
+	calculator.SetDCFParameters(s.app.config.DCFParams)
+	calculator.SetCompsParameters(s.app.config.CompsParams)
+	calculator.SetWeights(s.app.config.Weights)
+	calculator.SetDecimalMoney(s.app.config.Processing.DecimalMoney)
+}
+
+// fetch loads data for the tickers not yet in memory
+func (s *replSession) fetch(ctx context.Context, tickers []string) {
+	var missing []string
+	for _, ticker := range tickers {
+		if _, ok := s.stockData[ticker]; !ok {
+			missing = append(missing, ticker)
+		}
+	}
+
+	valuations := make([]fairvalue.Valuation, len(missing))
+	completed := 0
+	s.app.analyzer.ValuateEach(ctx, missing, func(i int, v fairvalue.Valuation) {
+		completed++
+		if len(missing) > 1 && s.app.config.Output.ShowProgress {
+			utils.ShowProgress(completed, len(missing), v.Ticker)
+		}
+		valuations[i] = v
+	})
+
+	// The peers of the loaded stocks are kept while parameters change
+	s.app.analyzer.ComparePeers(valuations)
+	for _, v := range valuations {
+		if v.Err != nil {
+			fmt.Printf("Warning: %v\n", v.Err)
+			continue
+		}
+		s.stockData[v.Ticker] = v.StockData
+	}
+}
+
+// calculate values the given loaded tickers with the current parameters
+func (s *replSession) calculate(tickers []string) []*models.ValuationResult {
+	calculator := s.app.analyzer.Calculator()
+	var results []*models.ValuationResult
+	for _, ticker := range tickers {
+		if stockData, ok := s.stockData[ticker]; ok && stockData.PriceOnly() {
+			results = append(results, models.NewNotValuableResult(stockData))
+		} else if ok {
+			results = append(results, calculator.CalculateFairValue(stockData))
+		}
+	}
+	return results
+}
+
+// loadedTickers returns the tickers in memory in alphabetical order
+func (s *replSession) loadedTickers() []string {
+	tickers := make([]string, 0, len(s.stockData))
+	for ticker := range s.stockData {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+	return tickers
+}
+
+// readerWithContext returns stdin as a reader that reports end of input
+// once ctx is cancelled, so Ctrl+C leaves the REPL cleanly
+func readerWithContext(ctx context.Context) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := io.Copy(pw, stdin)
+		pw.CloseWithError(err)
+	}()
+	go func() {
+		<-ctx.Done()
+		pw.Close()
+	}()
+	return pr
+}
+
+// formatFloat formats a parameter value without trailing zeros
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// parseFloatInto parses a numeric parameter value into target
+func parseFloatInto(value string, target *float64) error {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*target = parsed
+	return nil
+}
+
+// parseIntInto parses an integer parameter value into target
+func parseIntInto(value string, target *int) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", value)
+	}
+	*target = parsed
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/scheduler"
+)
+
+// RunSchedule runs the configured jobs until ctx is cancelled. With
+// display set, every run is shown as a table; otherwise a one-line summary
+// is printed, as suits the server.
+func (app *Application) RunSchedule(ctx context.Context, display bool) error {
+	if len(app.config.Schedule.Jobs) == 0 {
+		return fmt.Errorf("no scheduled jobs configured (see \"schedule\" in the configuration)")
+	}
+
+	loc, err := app.config.Schedule.Location()
+	if err != nil {
+		return err
+	}
+	app.analyzer.EnableMemoryCache()
+	// A page waiting for a key would hold up the next job
+	app.config.Output.Paging = false
+
+	jobs := make([]scheduler.Job, 0, len(app.config.Schedule.Jobs))
+	for _, cfg := range app.config.Schedule.Jobs {
+		schedule, err := scheduler.Parse(cfg.Cron, loc)
+		if err != nil {
+			return fmt.Errorf("scheduled job %q: %w", cfg.Name, err)
+		}
+		if cfg.MarketHours {
+			schedule = scheduler.WhileOpen(schedule, app.market)
+		}
+
+		job := cfg
+		jobs = append(jobs, scheduler.Job{
+			Name:     job.Name,
+			Schedule: schedule,
+			Run: func(ctx context.Context) error {
+				return app.runScheduledJob(ctx, job, display)
+			},
+		})
+	}
+
+	s := scheduler.New(jobs, func(job string, err error) {
+		fmt.Printf("Warning: scheduled job %s failed: %v\n", job, err)
+	})
+	app.showUpcoming(s, loc)
+	return s.Run(ctx)
+}
+
+// runScheduledJob runs the action of a scheduled job and publishes its
+// run, tagged with the job's tags
+func (app *Application) runScheduledJob(ctx context.Context, job config.ScheduleJob, display bool) error {
+	name, action := job.Name, job.Action
+	fmt.Printf("[%s] Running scheduled job %s (%s)\n", time.Now().Format("2006-01-02 15:04:05"), name, action)
+
+	var run *models.Run
+	var err error
+	// Prices can only be refreshed for stocks fetched before
+	if action == "refresh_prices" && len(app.tickers) > 0 {
+		run = app.analyzer.RefreshPrices(ctx)
+	} else {
+		run, err = app.Analyze(ctx)
+		if err == nil {
+			// Later analyses must not be served stale fundamentals from the cache
+			app.analyzer.SetForceRefresh(true)
+		}
+	}
+	if run == nil {
+		return err
+	}
+	run.Job = name
+	for _, tag := range job.Tags {
+		if !slices.Contains(run.Tags, tag) {
+			run.Tags = append(run.Tags, tag)
+		}
+	}
+
+	if display {
+		app.Display(run.Results)
+	} else {
+		underpriced := 0
+		for _, result := range run.Results {
+			if result.Status == models.StatusUnderpriced {
+				underpriced++
+			}
+		}
+		fmt.Printf("Scheduled job %s valued %d stocks (%d underpriced, %d failed)\n",
+			name, run.Valued(), underpriced, len(run.Errors))
+	}
+	app.publish(ctx, run)
+	return err
+}
+
+// showUpcoming prints when each job runs next
+func (app *Application) showUpcoming(s *scheduler.Scheduler, loc *time.Location) {
+	fmt.Println("Scheduled jobs (Ctrl+C to stop):")
+	for _, upcoming := range s.Upcoming() {
+		fmt.Printf("  %-20s next run %s\n", upcoming.Job, upcoming.At.In(loc).Format("Mon 2006-01-02 15:04 MST"))
+	}
+}
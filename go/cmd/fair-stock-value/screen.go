@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/screener"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+// screenSpec describes a screen to run
+type screenSpec struct {
+	name     string // saved screen name; membership is only recorded for named screens
+	criteria screener.Criteria
+	changes  bool          // report only membership changes
+	every    time.Duration // re-run interval, zero to run once
+}
+
+// Screen values the universe and reports the stocks matching spec. With an
+// interval it keeps re-running until ctx is cancelled, re-fetching data on
+// every pass after the first.
+func (app *Application) Screen(ctx context.Context, spec screenSpec) error {
+	if spec.every > 0 {
+		// A page waiting for a key would hold up the next pass
+		app.config.Output.Paging = false
+	}
+	var previous []string
+	if spec.name != "" {
+		membership, err := app.loadMembership(spec.name)
+		if err != nil {
+			return err
+		}
+		if membership != nil {
+			previous = membership.Tickers
+		}
+	}
+
+	for {
+		run, err := app.Analyze(ctx)
+		if run == nil {
+			return err
+		}
+
+		matches := app.screenMatches(spec.criteria, run.Results)
+		members := screener.Members(matches)
+
+		// Stocks that failed to value keep their previous membership
+		// instead of appearing to leave the screen
+		for _, ticker := range previous {
+			if _, failed := run.Errors[ticker]; failed {
+				members = append(members, ticker)
+			}
+		}
+		sort.Strings(members)
+		switch {
+		case run.Partial:
+			// Stocks missing from a partial run would look like they left
+			fmt.Printf("%d of %d stocks match the screen; membership not recorded for a partial run\n",
+				len(matches), len(run.Results))
+			app.Display(matches)
+		case spec.changes && previous != nil:
+			app.reportChanges(previous, members, matches)
+		default:
+			fmt.Printf("%d of %d stocks match the screen\n", len(matches), len(run.Results))
+			app.Display(matches)
+		}
+
+		if !run.Partial {
+			if spec.name != "" {
+				if err := app.saveMembership(spec.name, members, run.FinishedAt); err != nil {
+					fmt.Printf("Warning: %v\n", err)
+				}
+			}
+			previous = members
+		}
+
+		if spec.every == 0 || ctx.Err() != nil {
+			return err
+		}
+
+		// Later passes must not be served stale data from the cache
+		app.analyzer.SetForceRefresh(true)
+		fmt.Printf("\nNext screen at %s (Ctrl+C to stop)\n", time.Now().Add(spec.every).Format("15:04:05"))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(spec.every):
+		}
+	}
+}
+
+// screenMatches returns the results matching criteria and, when only
+// underpriced stocks or a trend are shown, that are underpriced and on
+// the trend
+func (app *Application) screenMatches(criteria screener.Criteria, results []*models.ValuationResult) []*models.ValuationResult {
+	var matches []*models.ValuationResult
+	for _, result := range criteria.Filter(results) {
+		if app.config.Output.ShowOnlyUnderpriced && result.Status != models.StatusUnderpriced {
+			continue
+		}
+		if !utils.MatchesTrend(result, app.config.Output.Trend) {
+			continue
+		}
+		matches = append(matches, result)
+	}
+	return matches
+}
+
+// reportChanges prints the stocks that entered and left the screen
+func (app *Application) reportChanges(previous, members []string, matches []*models.ValuationResult) {
+	added, removed := screener.Diff(previous, members)
+	fmt.Printf("[%s] ", time.Now().Format("2006-01-02 15:04:05"))
+	if len(added) == 0 && len(removed) == 0 {
+		fmt.Printf("No screen membership changes (%d stocks match)\n", len(members))
+		return
+	}
+
+	fmt.Printf("Screen membership changed: %d entered, %d left (%d stocks match)\n",
+		len(added), len(removed), len(members))
+	if len(removed) > 0 {
+		fmt.Printf("Left the screen: %s\n", strings.Join(removed, ", "))
+	}
+	if len(added) > 0 {
+		entered := make(map[string]bool, len(added))
+		for _, ticker := range added {
+			entered[ticker] = true
+		}
+		var results []*models.ValuationResult
+		for _, result := range matches {
+			if entered[result.Ticker] {
+				results = append(results, result)
+			}
+		}
+		fmt.Printf("Entered the screen: %s\n", strings.Join(added, ", "))
+		app.Display(results)
+	}
+}
+
+// loadMembership reads the membership recorded the last time a named
+// screen ran
+func (app *Application) loadMembership(name string) (*screener.Membership, error) {
+	dir, err := app.config.Processing.CachePath()
+	if err != nil {
+		return nil, err
+	}
+	return screener.LoadMembership(screener.MembershipPath(dir, name))
+}
+
+// saveMembership records the current membership of a named screen
+func (app *Application) saveMembership(name string, members []string, at time.Time) error {
+	dir, err := app.config.Processing.CachePath()
+	if err != nil {
+		return err
+	}
+	return screener.SaveMembership(screener.MembershipPath(dir, name), &screener.Membership{
+		Screen:    name,
+		UpdatedAt: at,
+		Tickers:   members,
+	})
+}
+
+// listScreens prints the saved screens
+func listScreens(screens map[string]config.ScreenConfig) {
+	if len(screens) == 0 {
+		fmt.Println("No saved screens (save one with: screen -config FILE -save NAME CONDITION...)")
+		return
+	}
+
+	names := make([]string, 0, len(screens))
+	for name := range screens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		screen := screens[name]
+		fmt.Printf("%-20s %s", name, screen.Criteria)
+		if len(screen.Tickers) > 0 {
+			fmt.Printf("  [%d tickers]", len(screen.Tickers))
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/api"
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/graphql"
+	"github.com/lesnerd/fair-stock-value/go/jobs"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+	"github.com/lesnerd/fair-stock-value/go/valuation"
+)
+
+// apiServer exposes valuations over a JSON REST API
+type apiServer struct {
+	app   *Application
+	store storage.RunStore
+	jobs  *jobs.Queue
+
+	// schema is the schema of the GraphQL API
+	schema *graphql.Schema
+
+	// shutdown is closed when the server stops, ending event streams
+	shutdown chan struct{}
+}
+
+// valuationResponse is the full valuation of a single ticker
+type valuationResponse struct {
+	Ticker    string                  `json:"ticker"`
+	StockData *models.StockData       `json:"stock_data"`
+	Result    *models.ValuationResult `json:"result"`
+	Breakdown *valuation.Breakdown    `json:"breakdown"`
+}
+
+// analyzeRequest is the body of POST /api/v1/analyze
+type analyzeRequest struct {
+	Tickers []string `json:"tickers"`
+}
+
+// errorResponse is returned with every non-2xx status
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// newAPIServer creates an API server backed by app and store, running
+// asynchronous jobs on queue
+func newAPIServer(app *Application, store storage.RunStore, queue *jobs.Queue) *apiServer {
+	s := &apiServer{app: app, store: store, jobs: queue, shutdown: make(chan struct{})}
+	s.schema = newGraphQLSchema(s)
+	return s
+}
+
+// route is an endpoint of the API
+type route struct {
+	pattern string // method and path, as http.ServeMux patterns them
+	handler http.HandlerFunc
+}
+
+// endpoints returns every endpoint of the API. Those of the REST API are
+// described by api/openapi.yaml, which the tests hold them to.
+func (s *apiServer) endpoints() []route {
+	return []route{
+		{"GET /api/v1/valuation/{ticker}", s.handleValuation},
+		{"POST /api/v1/analyze", s.handleAnalyze},
+		{"GET /api/v1/runs", s.handleListRuns},
+		{"GET /api/v1/runs/{id}", s.handleGetRun},
+		{"POST /api/v1/jobs", s.handleSubmitJob},
+		{"GET /api/v1/jobs", s.handleListJobs},
+		{"GET /api/v1/jobs/{id}", s.handleGetJob},
+		{"DELETE /api/v1/jobs/{id}", s.handleCancelJob},
+		{"GET /api/v1/jobs/{id}/events", s.handleJobEvents},
+		{"POST /graphql", s.handleGraphQL},
+		{"GET /graphql", s.handleGraphQL},
+		{"GET /graphql/schema", s.handleGraphQLSchema},
+		{"GET /feed.atom", s.handleAtomFeed},
+		{"GET /feed.rss", s.handleRSSFeed},
+		{"GET /calendar.ics", s.handleCalendar},
+		{"GET /openapi.yaml", s.handleOpenAPI},
+		{"GET /healthz", s.handleHealthz},
+		{"GET /readyz", s.handleReadyz},
+	}
+}
+
+// routes returns the HTTP handler for all API endpoints
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	for _, route := range s.endpoints() {
+		mux.HandleFunc(route.pattern, route.handler)
+	}
+	return traceHTTP(mux)
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled
+func (s *apiServer) ListenAndServe(ctx context.Context, addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	server.RegisterOnShutdown(func() { close(s.shutdown) })
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.ListenAndServe()
+	}()
+	log.Printf("Serving valuation API on %s", addr)
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Let in-flight requests finish before exiting
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// handleValuation values a single ticker
+func (s *apiServer) handleValuation(w http.ResponseWriter, r *http.Request) {
+	ticker := strings.ToUpper(r.PathValue("ticker"))
+	if !isTickerSymbol(ticker) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid ticker %q", ticker))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Minute)
+	defer cancel()
+
+	v := s.app.analyzer.Valuate(ctx, ticker)
+	if v.Err != nil {
+		writeError(w, http.StatusBadGateway, v.Err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, valuationResponse{
+		Ticker:    ticker,
+		StockData: v.StockData,
+		Result:    v.Result,
+		Breakdown: v.Breakdown,
+	})
+}
+
+// handleAnalyze values a list of tickers and stores the result as a run
+func (s *apiServer) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	var req analyzeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	tickers := normalizeTickers(req.Tickers)
+	if err := validateTickers(tickers, s.app.config.Server.MaxTickersPerRequest); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	run, err := s.app.analyzer.Analyze(ctx, tickers)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	if err := s.store.Save(run); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, run)
+}
+
+// handleListRuns returns stored runs without their results
+func (s *apiServer) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := s.store.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	type runSummary struct {
+		ID         string    `json:"id"`
+		StartedAt  time.Time `json:"started_at"`
+		FinishedAt time.Time `json:"finished_at"`
+		Tags       []string  `json:"tags,omitempty"`
+		Results    int       `json:"results"`
+		Errors     int       `json:"errors"`
+	}
+	summaries := make([]runSummary, 0, len(runs))
+	for _, run := range runs {
+		summaries = append(summaries, runSummary{
+			ID:         run.ID,
+			StartedAt:  run.StartedAt,
+			FinishedAt: run.FinishedAt,
+			Tags:       run.Tags,
+			Results:    run.Valued(),
+			Errors:     len(run.Errors),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// handleGetRun returns a stored run
+func (s *apiServer) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	run, err := s.store.Get(r.PathValue("id"))
+	if errors.Is(err, storage.ErrRunNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, run)
+}
+
+// handleOpenAPI serves the OpenAPI document describing the REST API
+func (s *apiServer) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/vnd.oai.openapi")
+	w.Write(api.OpenAPI)
+}
+
+// handleHealthz reports that the server is alive. It checks nothing
+// external, so a provider outage does not get the process restarted.
+func (s *apiServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": fairvalue.HealthOK})
+}
+
+// handleReadyz reports whether the server can serve valuations, with the
+// outcome of each check. It answers 503 when any check failed.
+func (s *apiServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	health := s.app.analyzer.CheckHealth(ctx)
+	status := http.StatusOK
+	if !health.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, health)
+}
+
+// validateTickers checks a requested ticker list against the request limit
+func validateTickers(tickers []string, max int) error {
+	if len(tickers) == 0 {
+		return errors.New("no tickers given")
+	}
+	if len(tickers) > max {
+		return fmt.Errorf("too many tickers: %d (maximum %d)", len(tickers), max)
+	}
+	for _, ticker := range tickers {
+		if !isTickerSymbol(ticker) {
+			return fmt.Errorf("invalid ticker %q", ticker)
+		}
+	}
+	return nil
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Warning: failed to write response: %v", err)
+	}
+}
+
+// writeError writes err as a JSON error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/api"
+	"github.com/lesnerd/fair-stock-value/go/api/client"
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/services/servicestest"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// undocumentedRoutes are the routes api/openapi.yaml leaves out on
+// purpose, with the operation whose description covers them
+var undocumentedRoutes = map[string]string{
+	"GET /graphql": "POST /graphql",
+}
+
+// openAPIOperations returns the operations of api/openapi.yaml as
+// http.ServeMux patterns, such as "GET /api/v1/runs/{id}"
+func openAPIOperations(t *testing.T) []string {
+	t.Helper()
+	var spec struct {
+		Paths map[string]map[string]yaml.Node `yaml:"paths"`
+	}
+	if err := yaml.Unmarshal(api.OpenAPI, &spec); err != nil {
+		t.Fatalf("parse openapi.yaml: %v", err)
+	}
+	var operations []string
+	for path, item := range spec.Paths {
+		for key := range item {
+			switch key {
+			case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+				operations = append(operations, strings.ToUpper(key)+" "+path)
+			}
+		}
+	}
+	slices.Sort(operations)
+	return operations
+}
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	operations := openAPIOperations(t)
+	if len(operations) == 0 {
+		t.Fatal("openapi.yaml describes no operations")
+	}
+
+	var routes []string
+	for _, route := range (&apiServer{}).endpoints() {
+		routes = append(routes, route.pattern)
+	}
+	for _, operation := range operations {
+		if !slices.Contains(routes, operation) {
+			t.Errorf("openapi.yaml describes %s, which the server does not route", operation)
+		}
+	}
+	for _, route := range routes {
+		if _, ok := undocumentedRoutes[route]; ok {
+			continue
+		}
+		if !slices.Contains(operations, route) {
+			t.Errorf("the server routes %s, which openapi.yaml does not describe", route)
+		}
+	}
+	for route, operation := range undocumentedRoutes {
+		if !slices.Contains(routes, route) || !slices.Contains(operations, operation) {
+			t.Errorf("undocumented route %s or the operation %q covering it is gone", route, operation)
+		}
+	}
+}
+
+func TestClientAgainstServer(t *testing.T) {
+	store, err := storage.NewJSONStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	startedAt := time.Date(2025, time.January, 2, 21, 0, 0, 0, time.UTC)
+	run := &models.Run{
+		ID:         models.NewRunID(startedAt),
+		StartedAt:  startedAt,
+		FinishedAt: startedAt.Add(time.Minute),
+		Results: []*models.ValuationResult{
+			{Ticker: "AAPL", Status: models.StatusUnderpriced, FairValue: 250, CurrentPrice: 200, PriceDifference: 50, UpsidePercentage: 25},
+			models.NewErrorResult("NOSUCH", "symbol not found"),
+		},
+		Errors: map[string]string{"NOSUCH": "symbol not found"},
+	}
+	if err := store.Save(run); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer((&apiServer{store: store}).routes())
+	defer server.Close()
+	c, err := client.NewClientWithResponses(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	health, err := c.GetHealthzWithResponse(ctx)
+	if err != nil {
+		t.Fatalf("GetHealthz: %v", err)
+	}
+	if health.JSON200 == nil || health.JSON200.Status == "" {
+		t.Errorf("GetHealthz answered %s %q, want a status", health.Status(), health.Body)
+	}
+
+	spec, err := c.GetOpenAPIWithResponse(ctx)
+	if err != nil {
+		t.Fatalf("GetOpenAPI: %v", err)
+	}
+	if spec.StatusCode() != http.StatusOK || string(spec.Body) != string(api.OpenAPI) {
+		t.Errorf("GetOpenAPI answered %s, want the embedded document", spec.Status())
+	}
+
+	runs, err := c.ListRunsWithResponse(ctx)
+	if err != nil {
+		t.Fatalf("ListRuns: %v", err)
+	}
+	if runs.JSON200 == nil || len(*runs.JSON200) != 1 {
+		t.Fatalf("ListRuns answered %s %q, want the stored run", runs.Status(), runs.Body)
+	}
+	if summary := (*runs.JSON200)[0]; summary.Id != run.ID || summary.Results != 1 || summary.Errors != 1 {
+		t.Errorf("ListRuns summary %+v, want run %s with 1 result and 1 error", summary, run.ID)
+	}
+
+	got, err := c.GetRunWithResponse(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("GetRun: %v", err)
+	}
+	if got.JSON200 == nil || got.JSON200.Id != run.ID || len(got.JSON200.Results) != len(run.Results) {
+		t.Fatalf("GetRun answered %s %q, want the stored run", got.Status(), got.Body)
+	}
+
+	missing, err := c.GetRunWithResponse(ctx, "no-such-run")
+	if err != nil {
+		t.Fatalf("GetRun: %v", err)
+	}
+	if missing.JSON404 == nil || missing.JSON404.Error == "" {
+		t.Errorf("GetRun of an unknown run answered %s %q, want a 404 error", missing.Status(), missing.Body)
+	}
+}
+
+// listings resolves the identifiers it holds to their tickers
+type listings map[string]string
+
+func (l listings) ResolveIdentifiers(_ context.Context, ids []string) (map[string]string, error) {
+	resolved := make(map[string]string)
+	for _, id := range ids {
+		if ticker, ok := l[id]; ok {
+			resolved[id] = ticker
+		}
+	}
+	return resolved, nil
+}
+
+func TestValuationAcceptsIdentifiers(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Processing.EnableCaching = false
+	analyzer, err := fairvalue.New(cfg,
+		fairvalue.WithStockDataProvider(servicestest.NewProvider(servicestest.Stocks()...)),
+		fairvalue.WithIdentifierResolver(listings{"US0378331005": "AAPL", "037833100": "AAPL"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer((&apiServer{app: &Application{config: cfg, analyzer: analyzer}}).routes())
+	defer server.Close()
+
+	for _, tc := range []struct {
+		id     string
+		status int
+	}{
+		{"AAPL", http.StatusOK},
+		{"US0378331005", http.StatusOK}, // ISIN of Apple
+		{"037833100", http.StatusOK},    // CUSIP of Apple
+		{"US0378331006", http.StatusBadRequest},
+		{"not a ticker", http.StatusBadRequest},
+	} {
+		resp, err := http.Get(server.URL + "/api/v1/valuation/" + url.PathEscape(tc.id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body valuationResponse
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("valuation of %s answered %d, want %d", tc.id, resp.StatusCode, tc.status)
+			continue
+		}
+		if tc.status != http.StatusOK {
+			continue
+		}
+		if err != nil || body.Result == nil || body.Result.Ticker != "AAPL" || body.Result.FairValue <= 0 {
+			t.Errorf("valuation of %s = %+v (%v), want AAPL valued", tc.id, body.Result, err)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/sinks"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+)
+
+// streamRun writes the results of a streamed run to the output file and
+// history as they complete
+type streamRun struct {
+	run      *models.Run
+	writer   sinks.ResultWriter
+	recorder storage.RunRecorder // nil when the history cannot record incrementally
+	keep     bool                // keep results, without their inputs, for the end of the run
+
+	mu          sync.Mutex
+	written     int
+	valued      int
+	underpriced int
+	err         error // first failed write, which stops the run
+}
+
+// add writes result to the output file, the history and, when kept, the run
+func (s *streamRun) add(result *models.ValuationResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.writer.Write(result); err != nil {
+		s.err = fmt.Errorf("failed to write %s: %w", result.Ticker, err)
+		return s.err
+	}
+	if s.recorder != nil {
+		if err := s.recorder.Record(result); err != nil {
+			s.err = err
+			return s.err
+		}
+	}
+
+	s.written++
+	if !result.Failed() {
+		s.valued++
+		if result.Status == models.StatusUnderpriced {
+			s.underpriced++
+		}
+	}
+	if s.keep {
+		slim := *result
+		slim.Inputs = nil
+		s.run.Results = append(s.run.Results, &slim)
+	}
+	return nil
+}
+
+// RunStream values the universe without holding it in memory: tickers are
+// read from the ticker file as workers become free, and every result is
+// written to the output file and the history as soon as it completes.
+// Results are only kept, without their inputs, when sinks, alerts or a
+// history that cannot record incrementally need the whole run at the end.
+func (app *Application) RunStream(ctx context.Context) error {
+	fmt.Println("Starting streaming stock valuation analysis...")
+
+	// Data kept for price refreshes would grow with the universe
+	app.analyzer.SetRetainStockData(false)
+
+	tickers, doneReading := app.analyzer.UniverseSeq()
+
+	startedAt := app.analyzer.Now()
+	run := &models.Run{ID: app.analyzer.NewRunID(startedAt), StartedAt: startedAt}
+	app.analyzer.Stamp(run)
+
+	path := app.output.filePath(run)
+	file, err := os.Create(path)
+	if err != nil {
+		doneReading()
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	writer, err := app.output.resultWriter(file, run)
+	if err != nil {
+		doneReading()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	stream := &streamRun{run: run, writer: writer}
+
+	if streaming, ok := app.history.(storage.StreamingStore); ok {
+		if stream.recorder, err = streaming.Start(run); err != nil {
+			fmt.Printf("Warning: failed to record run: %v\n", err)
+		}
+	}
+	stream.keep = len(app.sinks) > 0 || app.alerts != nil || (app.history != nil && stream.recorder == nil)
+
+	checkpoint, resumed, err := app.openCheckpoint()
+	if err != nil {
+		doneReading()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Tickers valued by an interrupted run are taken from the checkpoint
+	// as they come up instead of being valued again
+	pending := func(yield func(string) bool) {
+		for ticker := range tickers {
+			if entry, ok := resumed[ticker]; ok {
+				if stream.add(entry.Result) != nil {
+					cancel()
+				}
+				continue
+			}
+			if !yield(ticker) {
+				return
+			}
+		}
+	}
+
+	fmt.Printf("Streaming results to %s with %d parallel workers...\n", path, app.config.Processing.MaxWorkers)
+	var failures []fairvalue.Valuation
+	completed := 0
+	app.analyzer.ValuateSeq(ctx, pending, func(_ int, v fairvalue.Valuation) {
+		// Tickers cut short by an interrupt are left for -resume rather
+		// than written as failures
+		if v.Err != nil && ctx.Err() != nil {
+			return
+		}
+
+		completed++
+		if app.config.Output.ShowProgress {
+			fmt.Printf("\rProcessed %d stocks (%s)", completed, v.Ticker)
+		}
+
+		result := v.Result
+		if v.Err != nil {
+			failures = append(failures, v)
+			if run.Errors == nil {
+				run.Errors = make(map[string]string)
+			}
+			run.Errors[v.Ticker] = v.Err.Error()
+			result = models.NewFailedResult(v.Ticker, v.Err)
+		}
+		if err := stream.add(result); err != nil {
+			cancel()
+			return
+		}
+		if v.Err == nil {
+			if err := checkpoint.Record(v.Result, app.analyzer.Now()); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+	})
+	if app.config.Output.ShowProgress && completed > 0 {
+		fmt.Println()
+	}
+	readErr := doneReading()
+
+	run.FinishedAt = app.analyzer.Now()
+	run.Partial = ctx.Err() != nil || readErr != nil
+	if err := stream.writer.Close(run); err != nil && stream.err == nil {
+		stream.err = fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil && stream.err == nil {
+		stream.err = fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if stream.recorder != nil {
+		if err := stream.recorder.Finish(run); err != nil {
+			fmt.Printf("Warning: failed to record run: %v\n", err)
+		}
+	}
+
+	reportFailures(failures)
+	fmt.Printf("\nWrote %d results to %s: %d valued (%d underpriced), %d failed\n",
+		stream.written, path, stream.valued, stream.underpriced, len(failures))
+
+	if stream.recorder == nil && app.history != nil {
+		app.publish(ctx, run)
+	} else {
+		app.notify(ctx, run)
+	}
+
+	switch {
+	case stream.err != nil:
+		checkpoint.Close()
+		return stream.err
+	case readErr != nil:
+		checkpoint.Close()
+		return fmt.Errorf("failed to read tickers: %w", readErr)
+	case run.Partial:
+		fmt.Println("Warning: PARTIAL RUN - run again with -resume to continue where this run left off")
+		if err := checkpoint.Close(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		return ctx.Err()
+	}
+
+	// The run is complete, so there is nothing left to resume
+	if err := checkpoint.Remove(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/screener"
+	"github.com/lesnerd/fair-stock-value/go/telegram"
+)
+
+// Limits of the requests the bot answers
+const (
+	telegramMaxTickers   = 5                // tickers per /value
+	telegramScreenLength = 15               // matches listed per /screen
+	telegramPoll         = 50 * time.Second // long polling timeout
+)
+
+// telegramHelp is the answer to /start and /help
+const telegramHelp = `Commands:
+/value TICKER [TICKER...] - value up to 5 stocks
+/screen CONDITIONS - list undervalued stocks matching conditions such as upside>25 pe<15, or a saved screen by name
+/help - show this message`
+
+// runTelegram answers valuation requests sent to a Telegram bot
+func runTelegram(ctx context.Context, args []string) error {
+	fs := newFlagSet("telegram")
+	cfgFlags := registerConfigFlags(fs)
+	token := fs.String("token", "", "Bot token (default from config)")
+	schedule := fs.Bool("schedule", false, "Also run the jobs in the configured schedule, such as digests sent by telegram sinks")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := cfgFlags.load(fs)
+	if err != nil {
+		return err
+	}
+	if *token != "" {
+		cfg.Telegram.Token = *token
+	}
+	client, err := telegram.NewClient(cfg.Telegram.Token)
+	if err != nil {
+		return err
+	}
+
+	app, err := NewApplication(cfg)
+	if err != nil {
+		return err
+	}
+	// Requests for the same tickers share data kept in memory
+	app.analyzer.EnableMemoryCache()
+	bot := newTelegramBot(app, client)
+	if !*schedule {
+		return bot.Run(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errChan := make(chan error, 2)
+	for _, run := range []func(context.Context) error{bot.Run, func(ctx context.Context) error {
+		return app.RunSchedule(ctx, false)
+	}} {
+		go func() {
+			errChan <- run(ctx)
+			cancel()
+		}()
+	}
+	firstErr := <-errChan
+	if err := <-errChan; firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// telegramBot answers the commands sent to a bot with valuations
+type telegramBot struct {
+	app     *Application
+	client  *telegram.Client
+	allowed map[int64]bool // nil when every chat is answered
+
+	// screening is held while a screen runs, so only one values the
+	// universe at a time
+	screening sync.Mutex
+}
+
+// newTelegramBot creates a bot answering through client
+func newTelegramBot(app *Application, client *telegram.Client) *telegramBot {
+	b := &telegramBot{app: app, client: client}
+	if chats := app.config.Telegram.AllowedChats; len(chats) > 0 {
+		b.allowed = make(map[int64]bool, len(chats))
+		for _, chat := range chats {
+			b.allowed[chat] = true
+		}
+	}
+	return b
+}
+
+// Run polls for messages and answers them until ctx is cancelled
+func (b *telegramBot) Run(ctx context.Context) error {
+	log.Printf("Telegram bot waiting for messages")
+	var handlers sync.WaitGroup
+	defer handlers.Wait()
+
+	var offset int64
+	for {
+		updates, err := b.client.Updates(ctx, offset, telegramPoll)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+				return nil
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.ID + 1
+			if update.Message == nil || !strings.HasPrefix(update.Message.Text, "/") {
+				continue
+			}
+			message := *update.Message
+			handlers.Add(1)
+			go func() {
+				defer handlers.Done()
+				b.handle(ctx, message)
+			}()
+		}
+	}
+}
+
+// handle answers one command
+func (b *telegramBot) handle(ctx context.Context, message telegram.Message) {
+	chat := strconv.FormatInt(message.Chat.ID, 10)
+	reply := func(text string) {
+		if err := b.client.SendMessage(ctx, chat, text); err != nil && ctx.Err() == nil {
+			log.Printf("Warning: failed to answer chat %s: %v", chat, err)
+		}
+	}
+	if b.allowed != nil && !b.allowed[message.Chat.ID] {
+		reply(fmt.Sprintf("This bot does not answer chat %s.", chat))
+		return
+	}
+
+	// Commands may be addressed to the bot in groups, as /value@SomeBot
+	command, args, _ := strings.Cut(strings.TrimSpace(message.Text), " ")
+	command, _, _ = strings.Cut(strings.TrimPrefix(command, "/"), "@")
+	switch strings.ToLower(command) {
+	case "start", "help":
+		reply(telegramHelp)
+	case "value":
+		reply(b.value(ctx, strings.Fields(args)))
+	case "screen":
+		b.screen(ctx, strings.TrimSpace(args), reply)
+	default:
+		reply(fmt.Sprintf("Unknown command /%s.\n\n%s", command, telegramHelp))
+	}
+}
+
+// value answers /value with the valuation of each ticker
+func (b *telegramBot) value(ctx context.Context, args []string) string {
+	tickers := normalizeTickers(args)
+	if err := validateTickers(tickers, telegramMaxTickers); err != nil {
+		return fmt.Sprintf("%v. Usage: /value TICKER [TICKER...]", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	parts := make([]string, 0, len(tickers))
+	for _, v := range b.app.analyzer.ValuateAll(ctx, tickers) {
+		parts = append(parts, formatTelegramValuation(v))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// formatTelegramValuation renders a valuation as a message
+func formatTelegramValuation(v fairvalue.Valuation) string {
+	if v.Err != nil {
+		return fmt.Sprintf("%s: could not be valued: %v", v.Ticker, v.Err)
+	}
+	r := v.Result
+
+	var b strings.Builder
+	b.WriteString(r.Ticker)
+	if r.CompanyName != "" {
+		b.WriteString(" - " + r.CompanyName)
+	}
+	fmt.Fprintf(&b, "\n%s, %+.1f%% upside", r.Status, r.UpsidePercentage)
+	fmt.Fprintf(&b, "\nPrice $%.2f, fair value $%.2f", r.CurrentPrice, r.FairValue)
+	fmt.Fprintf(&b, "\nDCF $%.2f, Comps $%.2f", r.DCFValue, r.CompsValue)
+	if r.Sector != "" {
+		b.WriteString("\nSector: " + r.Sector)
+	}
+	if r.Incomplete {
+		b.WriteString("\nValued on incomplete data")
+	}
+	return b.String()
+}
+
+// screen answers /screen with the undervalued stocks of the universe
+// matching the conditions or saved screen in expr
+func (b *telegramBot) screen(ctx context.Context, expr string, reply func(string)) {
+	if expr == "" {
+		reply("Usage: /screen CONDITIONS, such as /screen upside>25 pe<15")
+		return
+	}
+
+	var tickers []string
+	if saved, ok := b.app.config.Screens[expr]; ok {
+		expr = saved.Criteria
+		if len(saved.Tickers) > 0 {
+			tickers = normalizeTickers(saved.Tickers)
+		}
+	}
+	criteria, err := screener.Parse(expr)
+	if err != nil {
+		reply(fmt.Sprintf("%v.\n\nFields: %s", err, strings.Join(screener.Fields(), ", ")))
+		return
+	}
+	if len(tickers) == 0 {
+		tickers = b.app.analyzer.Universe()
+	}
+
+	if !b.screening.TryLock() {
+		reply("Another screen is running; try again when it has finished.")
+		return
+	}
+	defer b.screening.Unlock()
+
+	reply(fmt.Sprintf("Screening %d stocks for %s, this may take a few minutes...", len(tickers), criteria))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+	run, err := b.app.analyzer.Analyze(ctx, tickers)
+	if run == nil || (err != nil && ctx.Err() != nil) {
+		reply(fmt.Sprintf("The screen failed: %v", err))
+		return
+	}
+
+	var matches []*models.ValuationResult
+	for _, result := range criteria.Filter(run.Results) {
+		if result.Status == models.StatusUnderpriced {
+			matches = append(matches, result)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].UpsidePercentage > matches[j].UpsidePercentage
+	})
+	reply(formatTelegramScreen(matches, run.Valued()))
+}
+
+// formatTelegramScreen lists the best matches of a screen
+func formatTelegramScreen(matches []*models.ValuationResult, valued int) string {
+	if len(matches) == 0 {
+		return fmt.Sprintf("No undervalued stock of the %d valued matches the screen.", valued)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d stocks match the screen", len(matches), valued)
+	if len(matches) > telegramScreenLength {
+		fmt.Fprintf(&b, ", the top %d by upside", telegramScreenLength)
+		matches = matches[:telegramScreenLength]
+	}
+	b.WriteString(":")
+	for i, r := range matches {
+		fmt.Fprintf(&b, "\n%2d. %-6s $%.2f -> $%.2f (%+.1f%%)", i+1, r.Ticker, r.CurrentPrice, r.FairValue, r.UpsidePercentage)
+	}
+	return b.String()
+}
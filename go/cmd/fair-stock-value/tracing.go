@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/telemetry"
+)
+
+// tracer creates the spans of the API servers
+var tracer = otel.Tracer("github.com/lesnerd/fair-stock-value/go/cmd/fair-stock-value")
+
+// startTelemetry installs tracing as configured and returns a function
+// flushing the spans not yet exported
+func startTelemetry(ctx context.Context, cfg *config.Config) (func(), error) {
+	shutdown, err := telemetry.Setup(ctx, cfg.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			fmt.Printf("Warning: failed to flush traces: %v\n", err)
+		}
+	}, nil
+}
+
+// statusRecorder captures the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records status before writing it
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// traceHTTP records every request to h as a server span, continuing the
+// caller's trace when the request carries a traceparent header
+func traceHTTP(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			))
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		r = r.WithContext(ctx)
+		h.ServeHTTP(recorder, r)
+
+		// The mux records the matched route on the request
+		if r.Pattern != "" {
+			span.SetName(r.Pattern)
+			span.SetAttributes(attribute.String("http.route", r.Pattern))
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		if recorder.status >= 500 {
+			span.SetStatus(otelcodes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
+
+// metadataCarrier adapts incoming gRPC metadata for trace propagation
+type metadataCarrier metadata.MD
+
+// Get returns the first value of key
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Set replaces the values of key
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys returns every key
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// startRPCSpan starts a server span for a gRPC call
+func startRPCSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+	return tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("rpc.method", method)))
+}
+
+// endRPCSpan records the outcome of a gRPC call and ends its span
+func endRPCSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", status.Code(err).String()))
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceUnary records every unary call as a server span
+func traceUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, span := startRPCSpan(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	endRPCSpan(span, err)
+	return resp, err
+}
+
+// tracedStream carries the span context into a streaming handler
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context holding the call's span
+func (s tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+// traceStream records every streaming call as a server span
+func traceStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := startRPCSpan(stream.Context(), info.FullMethod)
+	err := handler(srv, tracedStream{ServerStream: stream, ctx: ctx})
+	endRPCSpan(span, err)
+	return err
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/sinks"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+)
+
+// runTrends writes an HTML report charting the fair value of tickers
+// against their price over recorded runs
+func runTrends(ctx context.Context, args []string) error {
+	fs := newFlagSet("trends")
+	cfgFlags := registerConfigFlags(fs)
+	archive := fs.String("archive", "", "Directory of runs written by an archive sink (default: the configured history)")
+	runsDir := fs.String("runs-dir", "", "Directory of runs recorded as JSON files instead")
+	from := fs.String("from", "", "Read runs from a JSON-lines file written by a jsonl sink instead")
+	output := fs.String("output", "trends.html", "File to write the report to")
+	limit := fs.Int("limit", 0, "Chart only the most recent runs (0 = all)")
+	top := fs.Int("top", 20, "Without tickers, chart the stocks with the most upside in the latest run")
+	tickers, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := cfgFlags.load(fs)
+	if err != nil {
+		return err
+	}
+
+	store, err := openRecordedRuns(cfg, *from, *archive, *runsDir)
+	if err != nil {
+		return err
+	}
+	runs, err := storage.ListTagged(store, cfg.RunTags)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("no recorded runs%s", taggedWith(cfg.RunTags))
+	}
+	if *limit > 0 && len(runs) > *limit {
+		runs = runs[len(runs)-*limit:]
+	}
+
+	if len(tickers) > 0 {
+		tickers = normalizeTickers(tickers)
+	} else {
+		tickers = topTickers(runs[len(runs)-1], *top)
+	}
+	trends := sinks.Trends(runs, tickers)
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *output, err)
+	}
+	defer file.Close()
+	if err := sinks.WriteTrendReport(file, trends, len(runs), time.Now()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+
+	fmt.Printf("Charted %d tickers over %d runs to %s\n", len(trends), len(runs), *output)
+	return nil
+}
+
+// topTickers returns the tickers valued by run, most upside first, at
+// most n of them unless n is 0
+func topTickers(run *models.Run, n int) []string {
+	results := make([]*models.ValuationResult, 0, len(run.Results))
+	for _, result := range run.Results {
+		if !result.Failed() {
+			results = append(results, result)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].UpsidePercentage > results[j].UpsidePercentage
+	})
+	if n > 0 && len(results) > n {
+		results = results[:n]
+	}
+
+	tickers := make([]string, len(results))
+	for i, result := range results {
+		tickers[i] = result.Ticker
+	}
+	return tickers
+}
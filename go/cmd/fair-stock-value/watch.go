@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/utils"
+)
+
+// Watch keeps re-valuing the universe until ctx is cancelled. Prices are
+// refreshed every watch interval; fundamentals are re-fetched once the
+// fundamentals interval has elapsed (or on every tick when it is zero).
+// With market hours only, refreshes pause while the market is closed and
+// resume when it opens; the first pass runs regardless. Every pass is
+// published to the configured sinks.
+func (app *Application) Watch(ctx context.Context) error {
+	interval := app.config.Watch.Interval()
+	fundamentalsInterval := app.config.Watch.FundamentalsInterval()
+	app.analyzer.EnableMemoryCache()
+	// A page waiting for a key would hold up the next refresh
+	app.config.Output.Paging = false
+
+	var lastFull time.Time
+	for {
+		var run *models.Run
+		var err error
+		if lastFull.IsZero() || time.Since(lastFull) >= fundamentalsInterval {
+			run, err = app.Analyze(ctx)
+			if err == nil {
+				lastFull = run.StartedAt
+				// Later passes must not be served stale fundamentals from the cache
+				app.analyzer.SetForceRefresh(true)
+			}
+		} else {
+			run = app.analyzer.RefreshPrices(ctx)
+		}
+
+		if err != nil && ctx.Err() == nil {
+			fmt.Printf("Warning: analysis failed: %v\n", err)
+		}
+		if run != nil {
+			if utils.IsTerminal() && !run.Partial {
+				utils.ClearScreen()
+			}
+			app.Display(run.Results)
+			app.publish(ctx, run)
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		next := time.Now().Add(interval)
+		if open := app.nextRefresh(next); !open.Equal(next) {
+			next = open
+			fmt.Printf("\n%s is closed - watching %d stocks, next refresh when it opens at %s (Ctrl+C to stop)\n",
+				app.market.Name, len(app.tickers), next.In(app.market.Location).Format("Mon 2006-01-02 15:04 MST"))
+		} else {
+			fmt.Printf("\nWatching %d stocks - next refresh at %s (Ctrl+C to stop)\n",
+				len(app.tickers), next.Format("15:04:05"))
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// nextRefresh returns when watch mode refreshes after a pass due at next:
+// next itself, or with market hours only and the market closed at next,
+// when it opens
+func (app *Application) nextRefresh(next time.Time) time.Time {
+	if !app.config.Watch.MarketHoursOnly {
+		return next
+	}
+	if open := app.market.NextOpen(next); !open.IsZero() {
+		return open
+	}
+	return next
+}
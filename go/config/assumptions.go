@@ -0,0 +1,213 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// Assumptions override fetched inputs and model parameters for a single
+// run, for what-if checks without editing the configuration
+type Assumptions struct {
+	// Parameters are model parameters by name, for every ticker
+	Parameters map[string]float64 `json:"parameters,omitempty"`
+	// Inputs are fetched inputs by name, for every ticker
+	Inputs map[string]float64 `json:"inputs,omitempty"`
+	// Tickers are inputs by name for single tickers, over Inputs
+	Tickers map[string]map[string]float64 `json:"tickers,omitempty"`
+}
+
+// assumedInputs are the fetched inputs an assumption can override
+var assumedInputs = map[string]func(*models.StockData) *float64{
+	"growth": func(s *models.StockData) *float64 { return &s.GrowthRate },
+	"pe":     func(s *models.StockData) *float64 { return &s.PERatio },
+	"eps":    func(s *models.StockData) *float64 { return &s.EPS },
+	"fcf":    func(s *models.StockData) *float64 { return &s.FCFPerShare },
+	"book":   func(s *models.StockData) *float64 { return &s.BookValue },
+}
+
+// assumedParameters are the model parameters an assumption can override
+var assumedParameters = map[string]func(*Config) *float64{
+	"discount":   func(c *Config) *float64 { return &c.DCFParams.DiscountRate },
+	"terminal":   func(c *Config) *float64 { return &c.DCFParams.TerminalGrowthRate },
+	"max_growth": func(c *Config) *float64 { return &c.DCFParams.MaxGrowthRate },
+	"oil":        func(c *Config) *float64 { return &c.Commodity.Scenario },
+}
+
+// AssumptionNames returns the names assumptions can be made about: the
+// inputs, for every ticker or single ones, then the parameters
+func AssumptionNames() (inputs, parameters []string) {
+	return slices.Sorted(maps.Keys(assumedInputs)), slices.Sorted(maps.Keys(assumedParameters))
+}
+
+// IsEmpty reports whether a assumes nothing
+func (a Assumptions) IsEmpty() bool {
+	return len(a.Parameters) == 0 && len(a.Inputs) == 0 && len(a.Tickers) == 0
+}
+
+// Parse adds the assumptions of value, comma-separated NAME=VALUE pairs
+// such as "growth=0.10,discount=0.09". A TICKER: prefix, as in
+// "AAPL:growth=0.12,pe=25", makes the pairs up to the next prefix apply
+// to that ticker alone; parameters apply to every ticker and take no
+// prefix. Values are fractions or numbers, or percentages with a %
+// suffix. Later assumptions replace earlier ones.
+func (a *Assumptions) Parse(value string) error {
+	ticker := ""
+	for _, pair := range splitAssumptions(value) {
+		if prefix, rest, ok := strings.Cut(pair, ":"); ok {
+			ticker, pair = strings.ToUpper(strings.TrimSpace(prefix)), strings.TrimSpace(rest)
+			if ticker == "" {
+				return fmt.Errorf("assumption %q names no ticker", pair)
+			}
+		}
+		name, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("assumption %q is not NAME=VALUE", pair)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		number, err := parseAssumedValue(raw)
+		if err != nil {
+			return fmt.Errorf("assumption %s: %w", name, err)
+		}
+
+		switch {
+		case assumedInputs[name] != nil && ticker != "":
+			if a.Tickers == nil {
+				a.Tickers = make(map[string]map[string]float64)
+			}
+			if a.Tickers[ticker] == nil {
+				a.Tickers[ticker] = make(map[string]float64)
+			}
+			a.Tickers[ticker][name] = number
+		case assumedInputs[name] != nil:
+			if a.Inputs == nil {
+				a.Inputs = make(map[string]float64)
+			}
+			a.Inputs[name] = number
+		case assumedParameters[name] != nil && ticker != "":
+			return fmt.Errorf("assumption %s applies to every ticker, not %s alone", name, ticker)
+		case assumedParameters[name] != nil:
+			if a.Parameters == nil {
+				a.Parameters = make(map[string]float64)
+			}
+			a.Parameters[name] = number
+		default:
+			inputs, parameters := AssumptionNames()
+			return fmt.Errorf("unknown assumption %q (inputs: %s; parameters: %s)", name,
+				strings.Join(inputs, ", "), strings.Join(parameters, ", "))
+		}
+	}
+	return nil
+}
+
+// splitAssumptions splits value into its trimmed, non-empty pairs
+func splitAssumptions(value string) []string {
+	var pairs []string
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// parseAssumedValue parses a number, or a percentage with a % suffix
+func parseAssumedValue(raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	scale := 1.0
+	if trimmed, ok := strings.CutSuffix(raw, "%"); ok {
+		raw, scale = trimmed, 0.01
+	}
+	number, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", raw)
+	}
+	return number * scale, nil
+}
+
+// Assume makes the assumptions of a for the run, replacing the model
+// parameters they name. The configuration should be validated again.
+func (c *Config) Assume(a Assumptions) {
+	merge := func(into *map[string]float64, from map[string]float64) {
+		if len(from) > 0 && *into == nil {
+			*into = make(map[string]float64)
+		}
+		maps.Copy(*into, from)
+	}
+	merge(&c.Assumptions.Parameters, a.Parameters)
+	merge(&c.Assumptions.Inputs, a.Inputs)
+	for ticker, inputs := range a.Tickers {
+		if c.Assumptions.Tickers == nil {
+			c.Assumptions.Tickers = make(map[string]map[string]float64)
+		}
+		assumed := c.Assumptions.Tickers[ticker]
+		merge(&assumed, inputs)
+		c.Assumptions.Tickers[ticker] = assumed
+	}
+	for name, value := range a.Parameters {
+		*assumedParameters[name](c) = value
+	}
+}
+
+// For returns the inputs a assumes for ticker, matching the tickers of a
+// in the notation canonical puts them in
+func (a Assumptions) For(ticker string, canonical func(string) string) map[string]float64 {
+	inputs := maps.Clone(a.Inputs)
+	for assumedTicker, assumed := range a.Tickers {
+		if canonical(assumedTicker) == ticker {
+			if inputs == nil {
+				inputs = make(map[string]float64)
+			}
+			maps.Copy(inputs, assumed)
+		}
+	}
+	return inputs
+}
+
+// Apply returns a copy of stockData with the inputs a assumes for it, or
+// stockData itself when it assumes none
+func (a Assumptions) Apply(stockData *models.StockData, canonical func(string) string) *models.StockData {
+	inputs := a.For(stockData.Ticker, canonical)
+	if len(inputs) == 0 {
+		return stockData
+	}
+
+	assumed := stockData.Snapshot()
+	for name, value := range inputs {
+		*assumedInputs[name](assumed) = value
+	}
+	// An assumed P/E is the stock's own rather than a stand-in
+	if _, ok := inputs["pe"]; ok {
+		assumed.PERatioEstimated = false
+	}
+	return assumed
+}
+
+// String formats the assumptions as -assume would take them
+func (a Assumptions) String() string {
+	format := func(values map[string]float64) string {
+		pairs := make([]string, 0, len(values))
+		for _, name := range slices.Sorted(maps.Keys(values)) {
+			pairs = append(pairs, name+"="+strconv.FormatFloat(values[name], 'f', -1, 64))
+		}
+		return strings.Join(pairs, ",")
+	}
+
+	var groups []string
+	global := maps.Clone(a.Inputs)
+	if global == nil {
+		global = make(map[string]float64)
+	}
+	maps.Copy(global, a.Parameters)
+	if len(global) > 0 {
+		groups = append(groups, format(global))
+	}
+	for _, ticker := range slices.Sorted(maps.Keys(a.Tickers)) {
+		groups = append(groups, ticker+":"+format(a.Tickers[ticker]))
+	}
+	return strings.Join(groups, " ")
+}
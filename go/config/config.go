@@ -1,9 +1,26 @@
 package config
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	
-	"fair-stock-value/models"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/market"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/peers"
+	"github.com/lesnerd/fair-stock-value/go/sanity"
+	"github.com/lesnerd/fair-stock-value/go/scheduler"
+	"github.com/lesnerd/fair-stock-value/go/screener"
+	"github.com/lesnerd/fair-stock-value/go/services"
+	"github.com/lesnerd/fair-stock-value/go/utils"
 )
 
 // Config holds application configuration
@@ -11,19 +28,492 @@ type Config struct {
 	DCFParams     models.DCFParameters     `json:"dcf_parameters"`
 	CompsParams   models.CompsParameters   `json:"comps_parameters"`
 	Weights       models.ValuationWeights  `json:"valuation_weights"`
+	Floor         models.BookFloor         `json:"floor"` // tangible_book, book or none
 	DataSources   DataSourcesConfig        `json:"data_sources"`
 	Processing    ProcessingConfig         `json:"processing"`
 	Output        OutputConfig             `json:"output"`
+	Watch         WatchConfig              `json:"watch"`
+	Sinks         []SinkConfig             `json:"sinks,omitempty"`
+	Server        ServerConfig             `json:"server"`
+	Screens       map[string]ScreenConfig  `json:"screens,omitempty"`
+	Profiles      map[string]ProfileConfig `json:"profiles,omitempty"`
+	Alerts        AlertsConfig             `json:"alerts"`
+	History       HistoryConfig            `json:"history"`
+	Schedule      ScheduleConfig           `json:"schedule"`
+	Telemetry     TelemetryConfig          `json:"telemetry"`
+	Market        MarketConfig             `json:"market"`
+	Benchmark     BenchmarkConfig          `json:"benchmark"`
+	Peers         PeersConfig              `json:"peers"`
+	Sentiment     SentimentConfig          `json:"sentiment"`
+	Size          SizeConfig               `json:"size"`
+	Commodity     CommodityConfig          `json:"commodity"`
+	ADR           models.ADRParameters     `json:"adr"`
+	Validation    ValidationConfig         `json:"validation"`
+	Telegram      TelegramConfig           `json:"telegram"`
+
+	// Fallbacks set how a free cash flow or EPS that is not positive is
+	// replaced; the zero value replaces them with constants
+	Fallbacks models.FallbackParameters `json:"fallbacks"`
+
+	// Assumptions are the what-if overrides of a single run, given on the
+	// command line rather than in the configuration file
+	Assumptions Assumptions `json:"-"`
+
+	// RunTags name the workflows the runs of this invocation belong to,
+	// given on the command line. Recorded runs carry them, and the
+	// commands reading recorded runs read only the runs carrying them.
+	RunTags []string `json:"-"`
+
+	// TraceHTTP is the file every HTTP request of the data sources is
+	// traced to, given on the command line; empty traces nothing
+	TraceHTTP string `json:"-"`
+}
+
+// TelegramConfig configures the Telegram bot of the telegram command
+type TelegramConfig struct {
+	Token string `json:"token,omitempty"` // from @BotFather
+
+	// AllowedChats are the IDs of the chats the bot answers; it answers
+	// every chat when empty
+	AllowedChats []int64 `json:"allowed_chats,omitempty"`
+}
+
+// BenchmarkConfig configures the market benchmark whose P/E and upside each
+// stock's are related to
+type BenchmarkConfig struct {
+	Disabled  bool    `json:"disabled,omitempty"`
+	Symbol    string  `json:"symbol"`      // fund tracking the index; "SPY" for the S&P 500
+	LongRunPE float64 `json:"long_run_pe"` // P/E the benchmark's upside is measured against
+}
+
+// MarketConfig names the exchange whose trading hours watch mode and
+// market-hours scheduled jobs follow
+type MarketConfig struct {
+	Exchange string   `json:"exchange,omitempty"` // "NYSE" (also for Nasdaq), "LSE" or "XETRA"; defaults to "NYSE"
+	Holidays []string `json:"holidays,omitempty"` // extra closed days (YYYY-MM-DD), such as unscheduled closures
+}
+
+// PeersConfig configures the peer groups whose median P/E Comps values are
+// anchored on
+type PeersConfig struct {
+	Enabled        bool    `json:"enabled"`
+	MinPeers       int     `json:"min_peers"`        // fewer comparable companies value a stock on its own P/E
+	MaxPeers       int     `json:"max_peers"`        // the closest in market cap are chosen first
+	MarketCapRatio float64 `json:"market_cap_ratio"` // how many times larger or smaller than the stock a peer may be
+}
+
+// Rules returns the bounds of the peer groups
+func (p PeersConfig) Rules() peers.Rules {
+	return peers.Rules{
+		MinPeers:       p.MinPeers,
+		MaxPeers:       p.MaxPeers,
+		MarketCapRatio: p.MarketCapRatio,
+	}
+}
+
+// defaultPeers chooses peer groups with the default rules
+func defaultPeers() PeersConfig {
+	rules := peers.DefaultRules()
+	return PeersConfig{
+		Enabled:        true,
+		MinPeers:       rules.MinPeers,
+		MaxPeers:       rules.MaxPeers,
+		MarketCapRatio: rules.MarketCapRatio,
+	}
+}
+
+// SentimentConfig configures the news sentiment score of each stock,
+// fetched from Finnhub, and how far extreme sentiment moves fair values
+type SentimentConfig struct {
+	Enabled       bool    `json:"enabled"`
+	FinnhubAPIKey string  `json:"finnhub_api_key,omitempty"`
+	Threshold     float64 `json:"threshold"`  // score, from 0 to 1, beyond which sentiment is extreme in either direction
+	Adjustment    float64 `json:"adjustment"` // fraction fair values are moved by beyond the threshold; 0 only scores
+}
+
+// Parameters returns how far sentiment moves fair values, nothing when
+// sentiment is disabled
+func (s SentimentConfig) Parameters() models.SentimentParameters {
+	if !s.Enabled {
+		return models.SentimentParameters{}
+	}
+	return models.SentimentParameters{Threshold: s.Threshold, Adjustment: s.Adjustment}
+}
+
+// maxSentimentAdjustment keeps the sentiment adjustment a nudge rather
+// than a model of its own
+const maxSentimentAdjustment = 0.2
+
+// SizeConfig configures the discounts of the fair values of small and
+// thinly traded companies, so micro-caps valued on thin data do not screen
+// as deeply undervalued
+type SizeConfig struct {
+	Enabled            bool              `json:"enabled"`
+	Tiers              []models.SizeTier `json:"tiers"`               // size premiums by market cap; the smallest tier a company fits in applies
+	MinDollarVolume    float64           `json:"min_dollar_volume"`   // average value traded a day below which a stock is illiquid
+	IlliquidityHaircut float64           `json:"illiquidity_haircut"` // fraction fair values of illiquid stocks are lowered by
+}
+
+// Parameters returns how far fair values are discounted, nothing when the
+// discounts are disabled
+func (s SizeConfig) Parameters() models.SizeParameters {
+	if !s.Enabled {
+		return models.SizeParameters{}
+	}
+	return models.SizeParameters{
+		Tiers:              s.Tiers,
+		MinDollarVolume:    s.MinDollarVolume,
+		IlliquidityHaircut: s.IlliquidityHaircut,
+	}
+}
+
+// defaultSize discounts micro-caps the most and small caps a little, and
+// stocks trading under a million a day
+func defaultSize() SizeConfig {
+	return SizeConfig{
+		Tiers: []models.SizeTier{
+			{MaxMarketCap: 300_000_000, Premium: 0.15},
+			{MaxMarketCap: 2_000_000_000, Premium: 0.05},
+		},
+		MinDollarVolume:    1_000_000,
+		IlliquidityHaircut: 0.10,
+	}
+}
+
+// maxSizeDiscount bounds each of the size premiums and the illiquidity
+// haircut, which lower fair values rather than replace them
+const maxSizeDiscount = 0.5
+
+// CommodityConfig configures the oil price scenario the free cash flow of
+// energy companies is scaled to before it is discounted
+type CommodityConfig struct {
+	Scenario       float64 `json:"scenario,omitempty"` // oil price, in dollars a barrel, to value energy companies at; 0 leaves their FCF as it is
+	ReferencePrice float64 `json:"reference_price"`    // oil price trailing FCF was earned at, such as last year's average WTI price
+	Sensitivity    float64 `json:"sensitivity"`        // fractional change of FCF per fractional change of the oil price
+}
+
+// Parameters returns the scenario FCF is scaled to, nothing when none is
+// set
+func (c CommodityConfig) Parameters() models.CommodityParameters {
+	if c.Scenario <= 0 {
+		return models.CommodityParameters{}
+	}
+	return models.CommodityParameters{
+		Scenario:       c.Scenario,
+		ReferencePrice: c.ReferencePrice,
+		Sensitivity:    c.Sensitivity,
+	}
+}
+
+// maxCommoditySensitivity bounds how much faster than the oil price FCF
+// may be assumed to move, as the fixed costs of producers lever it
+const maxCommoditySensitivity = 5
+
+// Validation modes: what happens to stocks whose data fails its sanity
+// checks
+const (
+	ValidationOff    = "off"    // skip the checks
+	ValidationFlag   = "flag"   // value them, listing the violations with the result
+	ValidationReject = "reject" // fail them instead of valuing them
+)
+
+// ValidationConfig holds the sanity checks of fetched data
+type ValidationConfig struct {
+	Mode               string  `json:"mode"`                 // "off", "flag" or "reject"
+	PETolerance        float64 `json:"pe_tolerance"`         // allowed relative gap between P/E and price / EPS
+	MarketCapTolerance float64 `json:"market_cap_tolerance"` // allowed relative gap between market cap and price × shares
+	MaxFCFYield        float64 `json:"max_fcf_yield"`        // largest plausible FCF per share as a fraction of the price
+
+	// MinRealInputs is the number of valuation inputs, of the seven in
+	// models.ValuationInputs, that must have been fetched rather than
+	// filled in from fallback data for a stock to be valued. Stocks with
+	// fewer get status InsufficientData. Zero values every stock.
+	MinRealInputs int `json:"min_real_inputs,omitempty"`
+}
+
+// Rules returns the tolerances of the sanity checks
+func (v ValidationConfig) Rules() sanity.Rules {
+	return sanity.Rules{
+		PETolerance:        v.PETolerance,
+		MarketCapTolerance: v.MarketCapTolerance,
+		MaxFCFYield:        v.MaxFCFYield,
+	}
+}
+
+// TelemetryConfig controls OpenTelemetry tracing of "analyze" and "serve"
+type TelemetryConfig struct {
+	Enabled     bool    `json:"enabled"`
+	Endpoint    string  `json:"endpoint,omitempty"`     // OTLP/gRPC collector, defaults to OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4317
+	Insecure    bool    `json:"insecure,omitempty"`     // connect without TLS
+	ServiceName string  `json:"service_name,omitempty"` // defaults to "fair-stock-value"
+	SampleRatio float64 `json:"sample_ratio,omitempty"` // fraction of traces recorded, defaults to 1
+}
+
+// ScheduleConfig holds the jobs run by "analyze -schedule" and
+// "serve -schedule"
+type ScheduleConfig struct {
+	Timezone string        `json:"timezone,omitempty"` // IANA name such as "America/New_York", defaults to local time
+	Jobs     []ScheduleJob `json:"jobs,omitempty"`
+}
+
+// HasJob reports whether a job named name is scheduled
+func (s ScheduleConfig) HasJob(name string) bool {
+	for _, job := range s.Jobs {
+		if job.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduleJob is an action run on a cron-style schedule
+type ScheduleJob struct {
+	Name        string `json:"name"`
+	Cron        string `json:"cron"`                   // e.g. "0 7 * * mon-fri" or "@every 15m"
+	Action      string `json:"action"`                 // "analyze" or "refresh_prices"
+	MarketHours bool   `json:"market_hours,omitempty"` // skip runs while the market is closed
+
+	// Tags are added to the tags of the job's runs, so its runs can be
+	// told apart from those of other workflows
+	Tags []string `json:"tags,omitempty"`
+}
+
+// runTagPattern is what a run tag may consist of, such as "weekly-screen"
+var runTagPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateRunTag checks that tag is a single word of letters, digits,
+// dots, dashes and underscores
+func ValidateRunTag(tag string) error {
+	if !runTagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid run tag %q: use letters, digits, '.', '-' and '_'", tag)
+	}
+	return nil
+}
+
+// History backends
+const (
+	HistorySQLite = "sqlite"
+	HistoryJSON   = "json"
+)
+
+// HistoryConfig controls recording of runs for the history and backtest
+// commands and the runs served by the API. Runs are kept in a SQLite
+// database by default, or as JSON files in the server's runs directory.
+type HistoryConfig struct {
+	Enabled              bool   `json:"enabled"`
+	RecordPriceRefreshes bool   `json:"record_price_refreshes"` // also record watch mode price-only passes
+	Backend              string `json:"backend"`                // "sqlite" or "json"
+	Database             string `json:"database,omitempty"`     // defaults to history.db in the cache directory
+
+	// RevisionDays is how many days back growth estimates are compared
+	// with to find their revisions, 0 to skip them
+	RevisionDays int `json:"revision_days"`
+}
+
+// RevisionWindow returns how far back growth estimates are compared with
+func (h HistoryConfig) RevisionWindow() time.Duration {
+	return time.Duration(h.RevisionDays) * 24 * time.Hour
+}
+
+// AlertsConfig holds alert rules and the channels alerts are sent to
+type AlertsConfig struct {
+	Rules       []AlertRule      `json:"rules,omitempty"`
+	Channels    []NotifierConfig `json:"channels,omitempty"`
+	RepeatHours int              `json:"repeat_hours"`         // re-send an alert still in effect after this long, 0 = never
+	StateFile   string           `json:"state_file,omitempty"` // defaults to alerts.json in the cache directory
+}
+
+// AlertRule fires for stocks matching a condition, a status change, or both
+type AlertRule struct {
+	Name       string   `json:"name"`
+	Tickers    []string `json:"tickers,omitempty"`     // any stock when empty
+	Condition  string   `json:"condition,omitempty"`   // screen conditions, e.g. "upside>20"
+	StatusFrom string   `json:"status_from,omitempty"` // fires when the status changes from this...
+	StatusTo   string   `json:"status_to,omitempty"`   // ...to this
+}
+
+// NotifierConfig configures a channel notifications are sent to
+type NotifierConfig struct {
+	Type string `json:"type"`          // "webhook", "slack", "discord", "email" or "telegram"
+	URL  string `json:"url,omitempty"` // webhook, slack and discord
+
+	// Email settings
+	SMTPHost string   `json:"smtp_host,omitempty"`
+	SMTPPort int      `json:"smtp_port,omitempty"` // defaults to 587
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+
+	// Telegram settings: the bot token and the chat sent to, a numeric
+	// chat ID or the @username of a channel
+	Token  string `json:"token,omitempty"`
+	ChatID string `json:"chat_id,omitempty"`
+}
+
+// ScreenConfig is a named screen saved for reuse with "screen -screen NAME"
+type ScreenConfig struct {
+	Criteria string   `json:"criteria"`          // e.g. "upside>20 pe<15"
+	Tickers  []string `json:"tickers,omitempty"` // screens the configured universe when empty
+}
+
+// screenNamePattern restricts screen names to characters safe in file names
+var screenNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ServerConfig holds configuration for the REST API server
+type ServerConfig struct {
+	Addr                 string `json:"addr"`
+	GRPCAddr             string `json:"grpc_addr,omitempty"` // gRPC API is disabled when empty
+	RunsDir              string `json:"runs_dir,omitempty"` // defaults to a "runs" directory in the user cache directory
+	JobsDir              string `json:"jobs_dir,omitempty"` // defaults to a "jobs" directory in the user cache directory
+	MaxTickersPerRequest int    `json:"max_tickers_per_request"`
+	MaxTickersPerJob     int    `json:"max_tickers_per_job"`
+	FeedDays             int    `json:"feed_days,omitempty"` // history the feeds look back on, defaults to 30
+}
+
+// FeedWindow returns how far back the feeds of newly undervalued stocks
+// look
+func (s ServerConfig) FeedWindow() time.Duration {
+	if s.FeedDays <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(s.FeedDays) * 24 * time.Hour
+}
+
+// WatchConfig holds configuration for watch mode
+type WatchConfig struct {
+	IntervalSeconds             int  `json:"interval_seconds"`
+	FundamentalsIntervalMinutes int  `json:"fundamentals_interval_minutes"`
+	MarketHoursOnly             bool `json:"market_hours_only,omitempty"` // pause refreshes while the market is closed
+}
+
+// SinkConfig configures a destination that receives every completed run
+type SinkConfig struct {
+	Type string `json:"type"` // "jsonl", "webhook", "slack", "discord", "email", "telegram", "influxdb", "notion", "ics" or "archive"
+	Path string `json:"path,omitempty"` // jsonl, ics and archive (a directory)
+	URL  string `json:"url,omitempty"`  // webhook, slack, discord, influxdb and archive (an object store prefix, uploaded to with Token)
+
+	// Include selects the sections of the posted summary: summary,
+	// top_undervalued, status_changes, failures and results. Defaults to
+	// all but results.
+	Include []string `json:"include,omitempty"`
+	TopN    int      `json:"top_n,omitempty"` // length of top_undervalued, or stocks in an ics calendar; defaults to 10
+
+	// Email settings, as for alert channels
+	SMTPHost  string   `json:"smtp_host,omitempty"`
+	SMTPPort  int      `json:"smtp_port,omitempty"` // defaults to 587
+	Username  string   `json:"username,omitempty"`
+	Password  string   `json:"password,omitempty"`
+	From      string   `json:"from,omitempty"`
+	To        []string `json:"to,omitempty"`
+	AttachCSV bool     `json:"attach_csv,omitempty"` // attach the results as CSV
+
+	// InfluxDB settings: the bucket (or InfluxDB 1 database) points are
+	// written to, and the organization and token of InfluxDB 2. Without an
+	// organization the InfluxDB 1 write API is used, as VictoriaMetrics
+	// takes it.
+	Bucket      string `json:"bucket,omitempty"`
+	Org         string `json:"org,omitempty"`
+	Token       string `json:"token,omitempty"`
+	Measurement string `json:"measurement,omitempty"` // defaults to "fair_value"
+
+	// Telegram settings: the bot token (in Token) and the chat sent to
+	ChatID string `json:"chat_id,omitempty"`
+
+	// Notion settings: the integration token (in Token) and the database
+	// rows are upserted into
+	DatabaseID string `json:"database_id,omitempty"`
+
+	// Routing: a sink only receives runs of the listed scheduled jobs, and
+	// only the results of the listed tickers and sectors. Empty lists
+	// match everything.
+	Jobs    []string `json:"jobs,omitempty"`
+	Tickers []string `json:"tickers,omitempty"`
+	Sectors []string `json:"sectors,omitempty"`
+}
+
+// sinkSections are the sections a sink may include
+var sinkSections = map[string]bool{
+	"summary": true, "top_undervalued": true, "status_changes": true, "failures": true, "results": true,
 }
 
 // DataSourcesConfig holds configuration for data sources
 type DataSourcesConfig struct {
-	TickerFile          string `json:"ticker_file"`
+	TickerFile          string   `json:"ticker_file"`
+	Tickers             []string `json:"tickers,omitempty"` // overrides ticker_file when set
+	Watchlist           []string `json:"watchlist,omitempty"` // valued ahead of the rest of the universe
+
+	// Universe names an index whose constituents are the universe in
+	// place of ticker_file: dow30, nasdaq100 or sp500. The constituents
+	// are refreshed when scraping is enabled, and otherwise are those
+	// built into the release.
+	Universe string `json:"universe,omitempty"`
+
+	// YahooScreener is a saved Yahoo Finance screener, by ID or page
+	// address, whose matches are the universe in place of ticker_file.
+	// ScreenerLimit bounds the number of matches used; 0 uses them all.
+	YahooScreener string `json:"yahoo_screener,omitempty"`
+	ScreenerLimit int    `json:"screener_limit,omitempty"`
+
+	// Aliases map other notations of tickers to their canonical one, such
+	// as "BRKB" to "BRK-B", in addition to symbols.DefaultAliases
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// ISINs and CUSIPs given as tickers are resolved with OpenFIGI to the
+	// listing on IdentifierExchange, an OpenFIGI exchange code ("US" when
+	// empty). An API key raises OpenFIGI's rate limit.
+	OpenFIGIAPIKey     string `json:"openfigi_api_key,omitempty"`
+	IdentifierExchange string `json:"identifier_exchange,omitempty"`
 	UseYahooFinance     bool   `json:"use_yahoo_finance"`
 	UseAlphaVantage     bool   `json:"use_alpha_vantage"`
 	AlphaVantageAPIKey  string `json:"alpha_vantage_api_key"`
 	RequestTimeout      int    `json:"request_timeout_seconds"`
 	MaxRetries          int    `json:"max_retries"`
+
+	// Capability toggles. Disabling scraping, growth consensus and fallback
+	// data restricts the tool to API sources only.
+	EnableScraping        bool `json:"enable_scraping"`
+	EnableGrowthConsensus bool `json:"enable_growth_consensus"` // requires enable_scraping
+	EnableFallbackData    bool `json:"enable_fallback_data"`
+
+	// SelectorsFile holds the page URLs and CSS selectors of the scrapers,
+	// over the built-in ones, so a changed page layout can be followed
+	// without a new release
+	SelectorsFile string `json:"selectors_file,omitempty"`
+
+	// Hosts hold extra headers, cookies and bearer tokens the fetchers
+	// send to a host and its subdomains, by host name, for data sites
+	// the user has a subscription with
+	Hosts map[string]services.HostAuth `json:"hosts,omitempty"`
+
+	// ScrapeCheck periodically runs every scraper against a known ticker
+	// to find the sources whose pages no longer match their extractors
+	ScrapeCheck ScrapeCheckConfig `json:"scrape_check"`
+
+	// GrowthModel adds a model predicting growth from the trends of
+	// revenue and margins to the sources of the growth consensus
+	GrowthModel GrowthModelConfig `json:"growth_model"`
+}
+
+// GrowthModelConfig configures the growth model source of the consensus
+type GrowthModelConfig struct {
+	Enabled    bool    `json:"enabled"`
+	File       string  `json:"file,omitempty"`       // coefficients of a retrained model, defaults to the built-in one
+	Confidence float64 `json:"confidence,omitempty"` // weight in the consensus, 0-1; defaults to the model's own
+}
+
+// ScrapeCheckConfig configures the self-test of the scrapers. A scraper
+// whose page for the ticker yields no values is degraded; degraded growth
+// sources are left out of the consensus until a later check passes.
+type ScrapeCheckConfig struct {
+	Enabled       bool   `json:"enabled"`
+	Ticker        string `json:"ticker"`         // a company every source covers
+	IntervalHours int    `json:"interval_hours"` // checks are reused for this long, also across runs
+}
+
+// Interval returns how long the outcome of a scraper check is reused
+func (s ScrapeCheckConfig) Interval() time.Duration {
+	return time.Duration(s.IntervalHours) * time.Hour
 }
 
 // ProcessingConfig holds configuration for processing
@@ -31,7 +521,119 @@ type ProcessingConfig struct {
 	MaxWorkers        int  `json:"max_workers"`
 	EnableCaching     bool `json:"enable_caching"`
 	CacheExpiryHours  int  `json:"cache_expiry_hours"`
+	CacheDir          string `json:"cache_dir,omitempty"` // defaults to the user cache directory
+	CheckpointFile    string `json:"checkpoint_file,omitempty"` // defaults to checkpoint.jsonl in the cache directory
 	EnableParallel    bool `json:"enable_parallel"`
+
+	// TickerTimeoutSeconds bounds fetching a single ticker; 0 disables it.
+	// Tickers that hit it are valued on the data fetched so far.
+	TickerTimeoutSeconds int `json:"ticker_timeout_seconds"`
+
+	// Adaptive workers start at half of max_workers and move between
+	// min_workers and max_workers depending on how sources respond
+	AdaptiveWorkers bool `json:"adaptive_workers"`
+	MinWorkers      int  `json:"min_workers,omitempty"` // defaults to 1
+
+	// Deterministic fixes the clock and seeds the random source, so runs
+	// over the same cached data produce byte-identical outputs
+	Deterministic bool `json:"deterministic,omitempty"`
+
+	// DecimalMoney values prices and per-share values in exact decimal
+	// arithmetic, so displayed and exported amounts carry no binary
+	// floating point artifacts
+	DecimalMoney bool `json:"decimal_money,omitempty"`
+
+	// MemoryCache keeps recently used data in memory in long-running modes
+	MemoryCache MemoryCacheConfig `json:"memory_cache"`
+
+	// SkipList leaves tickers that keep failing out of universe runs for
+	// a while
+	SkipList SkipListConfig `json:"skip_list"`
+
+	// CacheOnly values stocks on the data in the cache, however old, and
+	// fetches nothing: tickers the cache does not hold fail. The fetch
+	// command fills the cache ahead of such runs.
+	CacheOnly bool `json:"cache_only,omitempty"`
+}
+
+// SkipListConfig sets when tickers that fail run after run, such as
+// delisted or renamed ones, are skipped and for how long
+type SkipListConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Failures int    `json:"failures,omitempty"` // runs in a row a ticker fails before it is skipped, defaults to 3
+	Days     int    `json:"days,omitempty"`     // how long it is skipped, defaults to 7
+	File     string `json:"file,omitempty"`     // defaults to skip_list.json in the cache directory
+}
+
+// Threshold returns the runs in a row a ticker fails before it is skipped
+func (s SkipListConfig) Threshold() int {
+	if s.Failures <= 0 {
+		return 3
+	}
+	return s.Failures
+}
+
+// Duration returns how long a ticker that keeps failing is skipped
+func (s SkipListConfig) Duration() time.Duration {
+	if s.Days <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return time.Duration(s.Days) * 24 * time.Hour
+}
+
+// MemoryCacheConfig bounds the in-memory cache of stock data and growth
+// consensus that the server, watch mode and schedules share across
+// analyses
+type MemoryCacheConfig struct {
+	Enabled             bool `json:"enabled"`
+	MaxEntries          int  `json:"max_entries,omitempty"`           // tickers kept, defaults to 2000
+	PriceTTLMinutes     int  `json:"price_ttl_minutes,omitempty"`     // defaults to 15
+	FundamentalsTTLDays int  `json:"fundamentals_ttl_days,omitempty"` // defaults to 3
+}
+
+// Capacity returns the number of tickers the memory cache keeps
+func (m MemoryCacheConfig) Capacity() int {
+	if m.MaxEntries <= 0 {
+		return 2000
+	}
+	return m.MaxEntries
+}
+
+// PriceTTL returns how long a price kept in memory is current
+func (m MemoryCacheConfig) PriceTTL() time.Duration {
+	if m.PriceTTLMinutes <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(m.PriceTTLMinutes) * time.Minute
+}
+
+// FundamentalsTTL returns how long fundamentals and growth consensus kept
+// in memory are current
+func (m MemoryCacheConfig) FundamentalsTTL() time.Duration {
+	if m.FundamentalsTTLDays <= 0 {
+		return 3 * 24 * time.Hour
+	}
+	return time.Duration(m.FundamentalsTTLDays) * 24 * time.Hour
+}
+
+// MinWorkerCount returns the lower bound of adaptive workers
+func (p ProcessingConfig) MinWorkerCount() int {
+	if p.MinWorkers <= 0 {
+		return 1
+	}
+	return p.MinWorkers
+}
+
+// InitialWorkers returns the number of concurrent fetches to start with
+func (p ProcessingConfig) InitialWorkers() int {
+	if !p.AdaptiveWorkers {
+		return p.MaxWorkers
+	}
+	initial := (p.MaxWorkers + 1) / 2
+	if min := p.MinWorkerCount(); initial < min {
+		initial = min
+	}
+	return initial
 }
 
 // OutputConfig holds configuration for output formatting
@@ -42,6 +644,24 @@ type OutputConfig struct {
 	ShowOnlyUnderpriced bool `json:"show_only_underpriced"`
 	MaxResults        int  `json:"max_results"`
 	ShowExtra         bool `json:"show_extra"`
+	Trend             string `json:"trend,omitempty"` // "above" or "below": only stocks trading above or below their 200-day moving average
+
+	// Paging pauses the results table after every page when it is shown
+	// on a terminal; PageSize rows make a page, or as many as fit the
+	// terminal when it is zero
+	Paging   bool `json:"paging"`
+	PageSize int  `json:"page_size,omitempty"`
+
+	// Table layout: explicit columns win over a named preset
+	Columns []string                `json:"columns,omitempty"`
+	Preset  string                  `json:"preset,omitempty"`
+	Presets map[string]ColumnPreset `json:"presets,omitempty"` // user-defined presets
+}
+
+// ColumnPreset is a named table layout with an optional default sort order
+type ColumnPreset struct {
+	Columns []string `json:"columns"`
+	SortBy  string   `json:"sort_by,omitempty"`
 }
 
 // NewDefaultConfig creates a new configuration with default values
@@ -52,6 +672,7 @@ func NewDefaultConfig() *Config {
 			TerminalGrowthRate: 0.08,
 			MaxGrowthRate:      0.08,
 			ProjectionYears:    5,
+			MaxBuybackYield:    0.05,
 		},
 		CompsParams: models.CompsParameters{
 			PEConservativeFactor: 0.85,
@@ -62,6 +683,7 @@ func NewDefaultConfig() *Config {
 			DCFWeight:   0.6,
 			CompsWeight: 0.4,
 		},
+		Floor: models.FloorTangibleBook,
 		DataSources: DataSourcesConfig{
 			TickerFile:         "data/fortune_500_tickers.csv",
 			UseYahooFinance:    true,
@@ -69,12 +691,37 @@ func NewDefaultConfig() *Config {
 			AlphaVantageAPIKey: "",
 			RequestTimeout:     10,
 			MaxRetries:         3,
+
+			EnableScraping:        true,
+			EnableGrowthConsensus: true,
+			EnableFallbackData:    true,
+
+			ScrapeCheck: ScrapeCheckConfig{
+				Enabled:       true,
+				Ticker:        "AAPL",
+				IntervalHours: 24,
+			},
 		},
 		Processing: ProcessingConfig{
 			MaxWorkers:       8,
 			EnableCaching:    true,
 			CacheExpiryHours: 24,
 			EnableParallel:   true,
+
+			TickerTimeoutSeconds: 60,
+
+			MemoryCache: MemoryCacheConfig{
+				Enabled:             true,
+				MaxEntries:          2000,
+				PriceTTLMinutes:     15,
+				FundamentalsTTLDays: 3,
+			},
+
+			SkipList: SkipListConfig{
+				Enabled:  true,
+				Failures: 3,
+				Days:     7,
+			},
 		},
 		Output: OutputConfig{
 			ShowColors:          true,
@@ -82,23 +729,317 @@ func NewDefaultConfig() *Config {
 			SortBy:             "upside",
 			ShowOnlyUnderpriced: false,
 			MaxResults:         0, // 0 means no limit
+			Paging:             true,
+		},
+		Watch: WatchConfig{
+			IntervalSeconds:             300,
+			FundamentalsIntervalMinutes: 360,
+		},
+		Server: ServerConfig{
+			Addr:                 ":8080",
+			MaxTickersPerRequest: 100,
+			MaxTickersPerJob:     10000,
+		},
+		History: HistoryConfig{
+			Enabled:      true,
+			Backend:      HistorySQLite,
+			RevisionDays: 30,
+		},
+		Benchmark: BenchmarkConfig{
+			Symbol:    "SPY",
+			LongRunPE: 16,
+		},
+		Validation: defaultValidation(),
+		Peers:      defaultPeers(),
+		Sentiment: SentimentConfig{
+			Threshold:  0.5,
+			Adjustment: 0.05,
 		},
+		Size: defaultSize(),
+		Commodity: CommodityConfig{
+			ReferencePrice: 75,
+			Sensitivity:    1,
+		},
+		ADR:       defaultADR(),
+		Fallbacks: defaultFallbacks(),
+	}
+}
+
+// defaultFallbacks replace a free cash flow or EPS that is not positive
+// with the conservative constants the models always used
+func defaultFallbacks() models.FallbackParameters {
+	return models.FallbackParameters{
+		FCF: models.FallbackRule{Policy: models.FallbackConstant, Value: models.DefaultFallbackFCF},
+		EPS: models.FallbackRule{Policy: models.FallbackConstant, Value: models.DefaultFallbackEPS},
+	}
+}
+
+// defaultADR withholds 15%, the common treaty rate for US holders, and
+// charges a typical depositary fee of two cents a year, with the UK, which
+// withholds nothing, and Canada, whose shares list directly without a
+// depositary, as exceptions
+func defaultADR() models.ADRParameters {
+	return models.ADRParameters{
+		Countries: map[string]models.ADRCosts{
+			"United Kingdom": {Withholding: 0, FeePerShare: 0.02},
+			"Canada":         {Withholding: 0.15, FeePerShare: 0},
+		},
+		Default: models.ADRCosts{Withholding: 0.15, FeePerShare: 0.02},
+	}
+}
+
+// defaultValidation flags data failing the default sanity checks
+func defaultValidation() ValidationConfig {
+	rules := sanity.DefaultRules()
+	return ValidationConfig{
+		Mode:               ValidationFlag,
+		PETolerance:        rules.PETolerance,
+		MarketCapTolerance: rules.MarketCapTolerance,
+		MaxFCFYield:        rules.MaxFCFYield,
 	}
 }
 
+// LoadFromFile loads configuration from a JSON file on top of the defaults,
+// so a config file only needs to contain the values it overrides
+func LoadFromFile(path string) (*Config, error) {
+	config := NewDefaultConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// CacheTTL returns how long cached stock data stays fresh
+func (p ProcessingConfig) CacheTTL() time.Duration {
+	return time.Duration(p.CacheExpiryHours) * time.Hour
+}
+
+// TickerTimeout returns how long fetching a single ticker may take, or 0
+// for no limit
+func (p ProcessingConfig) TickerTimeout() time.Duration {
+	return time.Duration(p.TickerTimeoutSeconds) * time.Second
+}
+
+// ScrapeCheckPath returns where the outcome of the last scraper check is
+// kept between runs
+func (p ProcessingConfig) ScrapeCheckPath() (string, error) {
+	dir, err := p.CachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "scrape_checks.jsonl"), nil
+}
+
+// SkipListPath returns where the tickers that keep failing are kept
+func (p ProcessingConfig) SkipListPath() (string, error) {
+	if p.SkipList.File != "" {
+		return p.SkipList.File, nil
+	}
+
+	dir, err := p.CachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "skip_list.json"), nil
+}
+
+// CheckpointPath returns where progress of universe runs is recorded
+func (p ProcessingConfig) CheckpointPath() (string, error) {
+	if p.CheckpointFile != "" {
+		return p.CheckpointFile, nil
+	}
+
+	dir, err := p.CachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "checkpoint.jsonl"), nil
+}
+
+// Location returns the timezone schedules are evaluated in
+func (s ScheduleConfig) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule timezone %q: %w", s.Timezone, err)
+	}
+	return loc, nil
+}
+
+// Calendar returns the trading calendar of the configured exchange,
+// including the configured holidays
+func (m MarketConfig) Calendar() (*market.Calendar, error) {
+	exchange := m.Exchange
+	if exchange == "" {
+		exchange = "NYSE"
+	}
+	calendar, err := market.Lookup(exchange)
+	if err != nil {
+		return nil, err
+	}
+	for _, holiday := range m.Holidays {
+		day, err := market.ParseDate(holiday)
+		if err != nil {
+			return nil, fmt.Errorf("market holidays: %w", err)
+		}
+		calendar.AddHolidays(day)
+	}
+	return calendar, nil
+}
+
+// AlertStatePath returns where alert deduplication state is kept
+func (c *Config) AlertStatePath() (string, error) {
+	if c.Alerts.StateFile != "" {
+		return c.Alerts.StateFile, nil
+	}
+
+	dir, err := c.Processing.CachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "alerts.json"), nil
+}
+
+// HistoryDatabasePath returns the location of the SQLite history database
+func (c *Config) HistoryDatabasePath() (string, error) {
+	if c.History.Database != "" {
+		return c.History.Database, nil
+	}
+
+	dir, err := c.Processing.CachePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.db"), nil
+}
+
+// Snapshot returns the settings that determine valuations, recorded with
+// each run so runs valued under different assumptions can be told apart.
+// Credentials and destinations are left out.
+func (c *Config) Snapshot() json.RawMessage {
+	snapshot := struct {
+		DCFParams   models.DCFParameters        `json:"dcf_parameters"`
+		CompsParams models.CompsParameters      `json:"comps_parameters"`
+		Weights     models.ValuationWeights     `json:"valuation_weights"`
+		Floor       models.BookFloor            `json:"floor"`
+		Features    models.DataFeatures         `json:"data_features"`
+		Decimal     bool                        `json:"decimal_money,omitempty"`
+		Peers       PeersConfig                 `json:"peers"`
+		Sentiment   *models.SentimentParameters `json:"sentiment,omitempty"` // only when it moves fair values
+		Size        *models.SizeParameters      `json:"size,omitempty"`      // only when enabled
+		Commodity   *models.CommodityParameters `json:"commodity,omitempty"` // only with a scenario
+		Fallbacks   *models.FallbackParameters  `json:"fallbacks,omitempty"` // only when not the default
+		Assumptions *Assumptions                `json:"assumptions,omitempty"`
+	}{c.DCFParams, c.CompsParams, c.Weights, c.Floor, c.DataSources.Features(), c.Processing.DecimalMoney, c.Peers, nil, nil, nil, nil, nil}
+	if params := c.Sentiment.Parameters(); params.Adjustment > 0 {
+		snapshot.Sentiment = &params
+	}
+	if c.Size.Enabled {
+		params := c.Size.Parameters()
+		snapshot.Size = &params
+	}
+	if params := c.Commodity.Parameters(); params.Scenario > 0 {
+		snapshot.Commodity = &params
+	}
+	if c.Fallbacks != defaultFallbacks() {
+		snapshot.Fallbacks = &c.Fallbacks
+	}
+	if !c.Assumptions.IsEmpty() {
+		snapshot.Assumptions = &c.Assumptions
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// Hash returns a short hash of the complete configuration, equal for runs
+// made with identical settings and assumptions
+func (c *Config) Hash() string {
+	hashed := struct {
+		*Config
+		Assumptions *Assumptions `json:"assumptions,omitempty"`
+	}{Config: c}
+	if !c.Assumptions.IsEmpty() {
+		hashed.Assumptions = &c.Assumptions
+	}
+	data, err := json.Marshal(hashed)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// CachePath returns the cache directory, which also holds other state
+// kept between runs
+func (p ProcessingConfig) CachePath() (string, error) {
+	if p.CacheDir != "" {
+		return p.CacheDir, nil
+	}
+	return services.DefaultCacheDir()
+}
+
+// Interval returns the time between price refreshes in watch mode
+func (w WatchConfig) Interval() time.Duration {
+	return time.Duration(w.IntervalSeconds) * time.Second
+}
+
+// FundamentalsInterval returns the time between full re-fetches in watch mode
+func (w WatchConfig) FundamentalsInterval() time.Duration {
+	return time.Duration(w.FundamentalsIntervalMinutes) * time.Minute
+}
+
+// Features returns the data acquisition capabilities enabled by this config
+func (d DataSourcesConfig) Features() models.DataFeatures {
+	return models.DataFeatures{
+		EnableYahooAPI:        d.UseYahooFinance,
+		EnableScraping:        d.EnableScraping,
+		EnableGrowthConsensus: d.EnableScraping && d.EnableGrowthConsensus,
+		EnableFallbackData:    d.EnableFallbackData,
+	}
+}
+
+// SetAPIOnly disables every capability that talks to non-API sources
+// or substitutes hardcoded data
+func (d *DataSourcesConfig) SetAPIOnly() {
+	d.EnableScraping = false
+	d.EnableGrowthConsensus = false
+	d.EnableFallbackData = false
+}
+
 // GetTestConfig returns a configuration optimized for testing
 func GetTestConfig() *Config {
 	config := NewDefaultConfig()
-	
-	// Modify for testing
-	config.Processing.MaxWorkers = 4
-	config.Processing.EnableParallel = true
-	config.Output.ShowProgress = true
-	config.Output.MaxResults = 10
-	
+	config.ApplyTestMode()
 	return config
 }
 
+// TestTickers is the limited universe analyzed in test mode
+var TestTickers = []string{
+	"AAPL", "MSFT", "GOOGL", "AMZN", "NVDA",
+	"META", "TSLA", "BRK-B", "UNH", "JNJ",
+}
+
+// ApplyTestMode modifies the configuration for testing
+func (c *Config) ApplyTestMode() {
+	c.DataSources.Tickers = TestTickers
+	c.Processing.MaxWorkers = 4
+	c.Processing.EnableParallel = true
+	c.Output.ShowProgress = true
+	c.Output.MaxResults = 10
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	// Validate DCF parameters
@@ -114,6 +1055,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("projection years must be positive")
 	}
 	
+	if c.DCFParams.IncludeBuybacks && (c.DCFParams.MaxBuybackYield <= 0 || c.DCFParams.MaxBuybackYield >= c.DCFParams.DiscountRate) {
+		return fmt.Errorf("max buyback yield must be positive and less than discount rate")
+	}
+	
 	// Validate Comps parameters
 	if c.CompsParams.PEConservativeFactor <= 0 || c.CompsParams.PEConservativeFactor > 1 {
 		return fmt.Errorf("P/E conservative factor must be between 0 and 1")
@@ -138,17 +1083,302 @@ func (c *Config) Validate() error {
 		c.Weights.DCFWeight /= totalWeight
 		c.Weights.CompsWeight /= totalWeight
 	}
+	if !c.Floor.Valid() {
+		return fmt.Errorf("invalid floor %q, expected tangible_book, book or none", c.Floor)
+	}
+	for _, fallback := range []struct {
+		input string
+		rule  models.FallbackRule
+	}{{"fcf", c.Fallbacks.FCF}, {"eps", c.Fallbacks.EPS}} {
+		if !fallback.rule.Policy.Valid() {
+			return fmt.Errorf("invalid %s fallback policy %q, expected constant, skip, sector_median or revenue_multiple",
+				fallback.input, string(fallback.rule.Policy))
+		}
+		if fallback.rule.Value < 0 || fallback.rule.Multiple < 0 {
+			return fmt.Errorf("%s fallback value and multiple must not be negative", fallback.input)
+		}
+	}
 	
+	// Validate output layout
+	if c.Output.Preset != "" {
+		if _, exists := c.Output.LookupPreset(c.Output.Preset); !exists {
+			return fmt.Errorf("unknown column preset %q", c.Output.Preset)
+		}
+	}
+
+	for name, preset := range c.Output.Presets {
+		if err := validateColumns(preset.Columns); err != nil {
+			return fmt.Errorf("preset %q: %w", name, err)
+		}
+	}
+
+	if err := validateColumns(c.Output.Columns); err != nil {
+		return err
+	}
+	if c.Output.PageSize < 0 {
+		return fmt.Errorf("page size cannot be negative")
+	}
+	if c.Output.Trend != "" && !slices.Contains(utils.Trends, c.Output.Trend) {
+		return fmt.Errorf("unknown trend %q (expected %s)", c.Output.Trend, strings.Join(utils.Trends, " or "))
+	}
+
+	// Validate watch parameters
+	if c.Watch.IntervalSeconds <= 0 {
+		return fmt.Errorf("watch interval must be positive")
+	}
+
+	if c.Watch.FundamentalsIntervalMinutes < 0 {
+		return fmt.Errorf("watch fundamentals interval cannot be negative")
+	}
+
+	for i, sink := range c.Sinks {
+		if sink.Type == "" {
+			return fmt.Errorf("sink %d: type is required", i)
+		}
+		for _, section := range sink.Include {
+			if !sinkSections[section] {
+				return fmt.Errorf("sink %d: unknown section %q (valid: summary, top_undervalued, status_changes, failures, results)", i, section)
+			}
+		}
+		if sink.TopN < 0 {
+			return fmt.Errorf("sink %d: top_n cannot be negative", i)
+		}
+		for _, job := range sink.Jobs {
+			if !c.Schedule.HasJob(job) {
+				return fmt.Errorf("sink %d: unknown scheduled job %q", i, job)
+			}
+		}
+	}
+
+	// Validate peer groups
+	if c.Peers.Enabled {
+		if c.Peers.MinPeers < 1 {
+			return fmt.Errorf("peer groups need at least one peer")
+		}
+		if c.Peers.MaxPeers < c.Peers.MinPeers {
+			return fmt.Errorf("max peers cannot be less than min peers")
+		}
+		if c.Peers.MarketCapRatio <= 1 {
+			return fmt.Errorf("peer market cap ratio must be greater than 1")
+		}
+	}
+
+	// Validate news sentiment
+	if c.Sentiment.Enabled {
+		if c.Sentiment.Threshold <= 0 || c.Sentiment.Threshold > 1 {
+			return fmt.Errorf("sentiment threshold must be between 0 and 1")
+		}
+		if c.Sentiment.Adjustment < 0 || c.Sentiment.Adjustment > maxSentimentAdjustment {
+			return fmt.Errorf("sentiment adjustment must be between 0 and %.2f", maxSentimentAdjustment)
+		}
+	}
+
+	// Validate the size and liquidity discounts
+	if c.Size.Enabled {
+		for _, tier := range c.Size.Tiers {
+			if tier.MaxMarketCap <= 0 {
+				return fmt.Errorf("size tier market cap must be positive")
+			}
+			if tier.Premium < 0 || tier.Premium > maxSizeDiscount {
+				return fmt.Errorf("size premium must be between 0 and %.2f", maxSizeDiscount)
+			}
+		}
+		if c.Size.MinDollarVolume < 0 {
+			return fmt.Errorf("min dollar volume cannot be negative")
+		}
+		if c.Size.IlliquidityHaircut < 0 || c.Size.IlliquidityHaircut > maxSizeDiscount {
+			return fmt.Errorf("illiquidity haircut must be between 0 and %.2f", maxSizeDiscount)
+		}
+	}
+
+	// Validate the commodity scenario
+	if c.Commodity.Scenario < 0 {
+		return fmt.Errorf("commodity scenario price cannot be negative")
+	}
+	if c.Commodity.Scenario > 0 {
+		if c.Commodity.ReferencePrice <= 0 {
+			return fmt.Errorf("commodity reference price must be positive")
+		}
+		if c.Commodity.Sensitivity <= 0 || c.Commodity.Sensitivity > maxCommoditySensitivity {
+			return fmt.Errorf("commodity sensitivity must be between 0 and %d", maxCommoditySensitivity)
+		}
+	}
+
+	// Validate the ADR costs
+	for country, costs := range c.ADR.Countries {
+		if err := validateADRCosts(costs); err != nil {
+			return fmt.Errorf("ADR costs of %s: %w", country, err)
+		}
+	}
+	if err := validateADRCosts(c.ADR.Default); err != nil {
+		return fmt.Errorf("default ADR costs: %w", err)
+	}
+
+	// Validate the benchmark
+	if !c.Benchmark.Disabled {
+		if c.Benchmark.Symbol == "" {
+			return fmt.Errorf("benchmark symbol is required unless the benchmark is disabled")
+		}
+		if c.Benchmark.LongRunPE <= 0 {
+			return fmt.Errorf("benchmark long-run P/E must be positive")
+		}
+	}
+
+	// Validate server parameters
+	if c.Server.Addr == "" {
+		return fmt.Errorf("server address is required")
+	}
+
+	if c.Server.MaxTickersPerRequest <= 0 {
+		return fmt.Errorf("max tickers per request must be positive")
+	}
+
+	if c.Server.MaxTickersPerJob <= 0 {
+		return fmt.Errorf("max tickers per job must be positive")
+	}
+	if c.Server.FeedDays < 0 {
+		return fmt.Errorf("feed days cannot be negative")
+	}
+
+	// Validate saved screens
+	for name, screen := range c.Screens {
+		if err := ValidateScreenName(name); err != nil {
+			return err
+		}
+		if _, err := screener.Parse(screen.Criteria); err != nil {
+			return fmt.Errorf("screen %q: %w", name, err)
+		}
+	}
+
+	// Validate assumption profiles
+	for name := range c.Profiles {
+		if name == ProfileBase {
+			return fmt.Errorf("profile %q is reserved for the configuration as it is", name)
+		}
+		if _, err := c.Profile(name); err != nil {
+			return err
+		}
+	}
+
+	// Validate alerts
+	if c.Alerts.RepeatHours < 0 {
+		return fmt.Errorf("alert repeat hours cannot be negative")
+	}
+
+	ruleNames := make(map[string]bool)
+	for i, rule := range c.Alerts.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("alert rule %d: name is required", i)
+		}
+		if ruleNames[rule.Name] {
+			return fmt.Errorf("alert rule %q is defined twice", rule.Name)
+		}
+		ruleNames[rule.Name] = true
+
+		if rule.Condition == "" && rule.StatusTo == "" {
+			return fmt.Errorf("alert rule %q: a condition or status_to is required", rule.Name)
+		}
+		if _, err := screener.Parse(rule.Condition); err != nil {
+			return fmt.Errorf("alert rule %q: %w", rule.Name, err)
+		}
+		for _, status := range []string{rule.StatusFrom, rule.StatusTo} {
+			if status != "" && status != models.StatusUnderpriced && status != models.StatusOverpriced {
+				return fmt.Errorf("alert rule %q: unknown status %q", rule.Name, status)
+			}
+		}
+	}
+
+	for i, channel := range c.Alerts.Channels {
+		if channel.Type == "" {
+			return fmt.Errorf("alert channel %d: type is required", i)
+		}
+	}
+
+	if c.Telemetry.SampleRatio < 0 || c.Telemetry.SampleRatio > 1 {
+		return fmt.Errorf("telemetry sample ratio must be between 0 and 1")
+	}
+
+	if c.History.Backend != HistorySQLite && c.History.Backend != HistoryJSON {
+		return fmt.Errorf("unknown history backend %q (expected %q or %q)", c.History.Backend, HistorySQLite, HistoryJSON)
+	}
+	if c.History.RevisionDays < 0 {
+		return fmt.Errorf("history revision days cannot be negative")
+	}
+
+	if _, err := c.Market.Calendar(); err != nil {
+		return err
+	}
+
+	switch c.Validation.Mode {
+	case ValidationOff, ValidationFlag, ValidationReject:
+	default:
+		return fmt.Errorf("unknown validation mode %q (expected %s, %s or %s)",
+			c.Validation.Mode, ValidationOff, ValidationFlag, ValidationReject)
+	}
+	if c.Validation.PETolerance < 0 || c.Validation.MarketCapTolerance < 0 || c.Validation.MaxFCFYield < 0 {
+		return fmt.Errorf("validation tolerances cannot be negative")
+	}
+	if c.Validation.MinRealInputs < 0 || c.Validation.MinRealInputs > len(models.ValuationInputs) {
+		return fmt.Errorf("validation min_real_inputs must be between 0 and %d", len(models.ValuationInputs))
+	}
+
+	// Validate schedule
+	loc, err := c.Schedule.Location()
+	if err != nil {
+		return err
+	}
+	for i, job := range c.Schedule.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("scheduled job %d: name is required", i)
+		}
+		if _, err := scheduler.Parse(job.Cron, loc); err != nil {
+			return fmt.Errorf("scheduled job %q: %w", job.Name, err)
+		}
+		if job.Action != "analyze" && job.Action != "refresh_prices" {
+			return fmt.Errorf("scheduled job %q: unknown action %q (expected analyze or refresh_prices)", job.Name, job.Action)
+		}
+		for _, tag := range job.Tags {
+			if err := ValidateRunTag(tag); err != nil {
+				return fmt.Errorf("scheduled job %q: %w", job.Name, err)
+			}
+		}
+	}
+	for _, tag := range c.RunTags {
+		if err := ValidateRunTag(tag); err != nil {
+			return err
+		}
+	}
+
 	// Validate processing parameters
 	if c.Processing.MaxWorkers <= 0 {
 		return fmt.Errorf("max workers must be positive")
 	}
+	if c.Processing.TickerTimeoutSeconds < 0 {
+		return fmt.Errorf("ticker timeout must not be negative")
+	}
+	if c.Processing.MinWorkers < 0 || c.Processing.MinWorkers > c.Processing.MaxWorkers {
+		return fmt.Errorf("min workers must be between 0 and max workers (%d)", c.Processing.MaxWorkers)
+	}
 	
 	if c.Processing.CacheExpiryHours < 0 {
 		return fmt.Errorf("cache expiry hours cannot be negative")
 	}
+	if m := c.Processing.MemoryCache; m.MaxEntries < 0 || m.PriceTTLMinutes < 0 || m.FundamentalsTTLDays < 0 {
+		return fmt.Errorf("memory cache size and TTLs cannot be negative")
+	}
+	if s := c.Processing.SkipList; s.Failures < 0 || s.Days < 0 {
+		return fmt.Errorf("skip list failures and days cannot be negative")
+	}
+	if c.Processing.CacheOnly && !c.Processing.EnableCaching {
+		return fmt.Errorf("cache_only needs enable_caching")
+	}
 	
 	// Validate data source parameters
+	for alias, ticker := range c.DataSources.Aliases {
+		if strings.TrimSpace(alias) == "" || strings.TrimSpace(ticker) == "" {
+			return fmt.Errorf("ticker aliases must map a non-empty alias to a non-empty ticker")
+		}
+	}
 	if c.DataSources.RequestTimeout <= 0 {
 		return fmt.Errorf("request timeout must be positive")
 	}
@@ -156,24 +1386,193 @@ func (c *Config) Validate() error {
 	if c.DataSources.MaxRetries < 0 {
 		return fmt.Errorf("max retries cannot be negative")
 	}
+	if check := c.DataSources.ScrapeCheck; check.Enabled {
+		if strings.TrimSpace(check.Ticker) == "" {
+			return fmt.Errorf("scrape check ticker is required")
+		}
+		if check.IntervalHours < 0 {
+			return fmt.Errorf("scrape check interval cannot be negative")
+		}
+	}
+	if confidence := c.DataSources.GrowthModel.Confidence; confidence < 0 || confidence > 1 {
+		return fmt.Errorf("growth model confidence must be between 0 and 1")
+	}
+	for host, auth := range c.DataSources.Hosts {
+		if err := auth.Validate(host); err != nil {
+			return err
+		}
+	}
+	if u := c.DataSources.Universe; u != "" && !services.IsIndex(u) {
+		return fmt.Errorf("invalid universe %q, expected one of %s", u, strings.Join(services.Indices(), ", "))
+	}
+	if c.DataSources.Universe != "" && c.DataSources.YahooScreener != "" {
+		return fmt.Errorf("universe and yahoo_screener cannot both be set")
+	}
+	if c.DataSources.YahooScreener != "" {
+		if _, err := services.ScreenerID(c.DataSources.YahooScreener); err != nil {
+			return err
+		}
+		if c.Processing.CacheOnly {
+			return fmt.Errorf("yahoo_screener cannot be combined with cache_only, as its matches are fetched")
+		}
+	}
+	if c.DataSources.ScreenerLimit < 0 {
+		return fmt.Errorf("screener limit cannot be negative")
+	}
 	
 	return nil
 }
 
-// GetIndustryPERatios returns the default industry P/E ratios
+// ValidateScreenName checks that name can identify a saved screen
+func ValidateScreenName(name string) error {
+	if !screenNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid screen name %q (use letters, digits, '-' and '_')", name)
+	}
+	return nil
+}
+
+// SaveScreen adds or replaces a named screen in the config file at path,
+// leaving every other setting in the file as it is. The file is created
+// when it does not exist.
+func SaveScreen(path, name string, screen ScreenConfig) error {
+	if err := ValidateScreenName(name); err != nil {
+		return err
+	}
+
+	settings := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	screens := make(map[string]ScreenConfig)
+	if raw, ok := settings["screens"]; ok {
+		if err := json.Unmarshal(raw, &screens); err != nil {
+			return fmt.Errorf("failed to parse screens in %s: %w", path, err)
+		}
+	}
+	screens[name] = screen
+
+	// Criteria are written unescaped so the file stays readable
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(screens); err != nil {
+		return err
+	}
+	settings["screens"] = json.RawMessage(buf.Bytes())
+
+	buf.Reset()
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(settings); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// GetColumnPresets returns the built-in table layouts
+func GetColumnPresets() map[string]ColumnPreset {
+	return map[string]ColumnPreset{
+		"default": {
+			Columns: utils.DefaultColumns,
+		},
+		"extra": {
+			Columns: append(append([]string{}, utils.DefaultColumns...),
+				"pe", "eps", "fcf_per_share", "fcf_yield", "earnings_yield", "dividend_yield", "payout_ratio", "sector", "company"),
+		},
+		"compact": {
+			Columns: []string{"ticker", "fair_value", "current_price", "upside_pct", "status"},
+		},
+		"analyst": {
+			Columns: []string{"ticker", "company", "sector", "fair_value", "current_price", "upside_pct", "status", "pe", "growth", "revision"},
+			SortBy:  "upside",
+		},
+		"quant": {
+			Columns: []string{"ticker", "fair_value", "dcf_value", "comps_value", "current_price", "upside_pct", "pe", "eps", "fcf_per_share", "growth", "market_cap"},
+			SortBy:  "fair_value",
+		},
+		"trend": {
+			Columns: []string{"ticker", "fair_value", "current_price", "upside_pct", "ma_50", "ma_200", "vs_200dma", "status"},
+		},
+		"relative": {
+			Columns: []string{"ticker", "fair_value", "current_price", "upside_pct", "pe", "rel_pe", "ey_spread", "rel_upside", "status"},
+		},
+	}
+}
+
+// LookupPreset finds a preset by name, preferring user-defined presets
+func (o OutputConfig) LookupPreset(name string) (ColumnPreset, bool) {
+	if preset, exists := o.Presets[name]; exists {
+		return preset, true
+	}
+	preset, exists := GetColumnPresets()[name]
+	return preset, exists
+}
+
+// ResolveColumns returns the columns to display and the preset's sort order,
+// if any. Explicit columns take priority, then the named preset, then the
+// legacy show_extra switch.
+func (o OutputConfig) ResolveColumns() ([]string, string) {
+	if len(o.Columns) > 0 {
+		return o.Columns, ""
+	}
+
+	name := o.Preset
+	if name == "" {
+		name = "default"
+		if o.ShowExtra {
+			name = "extra"
+		}
+	}
+
+	preset, _ := o.LookupPreset(name)
+	return preset.Columns, preset.SortBy
+}
+
+// GetIndustryPERatios returns the default P/E ratios by sector of the
+// canonical taxonomy; see models.NormalizeSector
 func GetIndustryPERatios() map[string]float64 {
 	return map[string]float64{
-		"Technology":             22.0,
-		"Healthcare":             18.0,
-		"Financial Services":     10.0,
-		"Consumer Cyclical":      16.0,
-		"Consumer Defensive":     20.0,
-		"Energy":                 12.0,
-		"Industrials":            13.0,
-		"Materials":              12.0,
-		"Real Estate":            14.0,
-		"Utilities":              16.0,
-		"Communication Services": 18.0,
-		"Default":                18.0,
-	}
-}
\ No newline at end of file
+		models.SectorTechnology:            22.0,
+		models.SectorHealthcare:            18.0,
+		models.SectorFinancialServices:     10.0,
+		models.SectorConsumerCyclical:      16.0,
+		models.SectorConsumerDefensive:     20.0,
+		models.SectorEnergy:                12.0,
+		models.SectorIndustrials:           13.0,
+		models.SectorBasicMaterials:        12.0,
+		models.SectorRealEstate:            14.0,
+		models.SectorUtilities:             16.0,
+		models.SectorCommunicationServices: 18.0,
+		"Default":                          18.0,
+	}
+}
+
+// validateColumns checks that every column key is known
+func validateColumns(columns []string) error {
+	for _, column := range columns {
+		if !utils.IsValidColumn(column) {
+			return fmt.Errorf("unknown output column %q (available: %v)", column, utils.ColumnKeys())
+		}
+	}
+	return nil
+}
+
+// validateADRCosts checks that costs withhold a fraction of dividends and
+// charge no negative fee
+func validateADRCosts(costs models.ADRCosts) error {
+	if costs.Withholding < 0 || costs.Withholding > 1 {
+		return fmt.Errorf("withholding must be between 0 and 1")
+	}
+	if costs.FeePerShare < 0 {
+		return fmt.Errorf("fee per share cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ProfileBase names the configuration as it is, without a profile
+const ProfileBase = "base"
+
+// ProfileConfig is a named set of model parameters the profiles command
+// compares. Its sections are laid over those of the configuration, so a
+// profile names only the parameters it changes: a conservative profile can
+// raise the discount rate and keep every other DCF parameter.
+type ProfileConfig struct {
+	DCFParams   json.RawMessage  `json:"dcf_parameters,omitempty"`
+	CompsParams json.RawMessage  `json:"comps_parameters,omitempty"`
+	Weights     json.RawMessage  `json:"valuation_weights,omitempty"`
+	Commodity   json.RawMessage  `json:"commodity,omitempty"`
+	Floor       models.BookFloor `json:"floor,omitempty"` // keeps the configured floor when empty
+}
+
+// builtinProfiles are the profiles available without configuring any.
+// Profiles of the same name in the configuration replace them.
+var builtinProfiles = map[string]ProfileConfig{
+	"conservative": {
+		DCFParams:   json.RawMessage(`{"discount_rate": 0.14, "max_growth_rate": 0.06}`),
+		CompsParams: json.RawMessage(`{"pe_conservative_factor": 0.75}`),
+	},
+	"aggressive": {
+		DCFParams:   json.RawMessage(`{"discount_rate": 0.11, "max_growth_rate": 0.10}`),
+		CompsParams: json.RawMessage(`{"pe_conservative_factor": 0.95}`),
+	},
+}
+
+// ProfileNames returns the names of the built-in and configured profiles,
+// base first
+func (c *Config) ProfileNames() []string {
+	names := maps.Clone(builtinProfiles)
+	maps.Copy(names, c.Profiles)
+	delete(names, ProfileBase)
+	return append([]string{ProfileBase}, slices.Sorted(maps.Keys(names))...)
+}
+
+// Profile returns a validated copy of the configuration with the model
+// parameters of the named profile, or the configuration as it is for
+// ProfileBase
+func (c *Config) Profile(name string) (*Config, error) {
+	profile := *c
+	if name == ProfileBase {
+		return &profile, nil
+	}
+	settings, ok := c.Profiles[name]
+	if !ok {
+		settings, ok = builtinProfiles[name]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	for _, section := range []struct {
+		name string
+		data json.RawMessage
+		into any
+	}{
+		{"dcf_parameters", settings.DCFParams, &profile.DCFParams},
+		{"comps_parameters", settings.CompsParams, &profile.CompsParams},
+		{"valuation_weights", settings.Weights, &profile.Weights},
+		{"commodity", settings.Commodity, &profile.Commodity},
+	} {
+		if len(section.data) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(section.data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(section.into); err != nil {
+			return nil, fmt.Errorf("profile %q: %s: %w", name, section.name, err)
+		}
+	}
+	if settings.Floor != "" {
+		profile.Floor = settings.Floor
+	}
+
+	// The copy values stocks under this profile alone
+	profile.Profiles = nil
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return &profile, nil
+}
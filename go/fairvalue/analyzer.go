@@ -0,0 +1,966 @@
+// Package fairvalue is the library API of fair-stock-value. It combines data
+// fetching, caching and valuation so other programs can value stocks without
+// going through the CLI:
+//
+//	analyzer, err := fairvalue.New(config.NewDefaultConfig())
+//	if err != nil {
+//		return err
+//	}
+//	run, err := analyzer.Analyze(ctx, []string{"AAPL", "MSFT"})
+package fairvalue
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"math/rand"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/buildinfo"
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/sanity"
+	"github.com/lesnerd/fair-stock-value/go/services"
+	"github.com/lesnerd/fair-stock-value/go/symbols"
+	"github.com/lesnerd/fair-stock-value/go/valuation"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+)
+
+// tracer creates the spans of the valuation pipeline
+var tracer = otel.Tracer("github.com/lesnerd/fair-stock-value/go/fairvalue")
+
+// DefaultTickers is the universe used when neither the configuration nor
+// the ticker file provide one: the built-in constituents of
+// services.DefaultIndex
+var DefaultTickers = services.DefaultConstituents(services.DefaultIndex)
+
+// Valuation holds the complete valuation of a single ticker
+type Valuation struct {
+	Ticker    string
+	StockData *models.StockData
+	Result    *models.ValuationResult
+	Breakdown *valuation.Breakdown
+	Err       error // set instead of the other fields when the ticker failed
+}
+
+// Analyzer values stocks using the data sources, cache and model
+// parameters of a configuration. It is safe for concurrent use.
+type Analyzer struct {
+	config      *config.Config
+	dataFetcher *services.DataFetcher
+	provider    services.StockDataProvider // dataFetcher unless replaced
+	calculator  *valuation.Calculator
+	symbols     *symbols.Mapper
+	resolver    services.IdentifierResolver
+	sentiment   services.SentimentSource // nil unless news sentiment is enabled
+	cache       *services.Cache       // nil when caching is disabled
+	memory      *services.MemoryCache // nil until EnableMemoryCache
+	logger      services.Logger
+	clock       services.Clock
+	random      *services.Random  // nil leaves the fetchers their own source
+	transport   http.RoundTripper // nil for the transport shared by all fetchers
+	runIDs      io.Reader         // random part of run IDs, nil for crypto/rand
+
+	// workers bounds concurrent fetches across all calls
+	workers *workerLimiter
+	// tuner adjusts the worker limit when adaptive workers are enabled
+	tuner *workerTuner
+	// metrics count the requests of every data source
+	metrics *sourceMetrics
+	// httpTrace logs every request and cache lookup; nil unless tracing
+	httpTrace *services.HTTPTrace
+
+	// probes caches provider reachability for health checks
+	probes providerProbes
+
+	// scrapers keeps the last self-test of the scrapers
+	scrapers scrapeMonitor
+
+	// benchmark caches the market benchmark results are related to
+	benchmark benchmarkCache
+
+	// forceRefresh skips cache reads while still writing fresh data back
+	forceRefresh atomic.Bool
+
+	// stockData keeps the latest fetched data per ticker for price-only
+	// refreshes, unless discardStockData is set
+	stockData        map[string]*models.StockData
+	dataMutex        sync.Mutex
+	discardStockData atomic.Bool
+
+	// hints are the hints of the ticker file by ticker, for the tickers it
+	// gives any for
+	hints      map[string]models.TickerInfo
+	hintsMutex sync.RWMutex
+}
+
+// Option customizes an Analyzer
+type Option func(*Analyzer)
+
+// WithLogger sets where progress and diagnostic messages are written.
+// By default they are discarded.
+func WithLogger(logger services.Logger) Option {
+	return func(a *Analyzer) {
+		a.logger = logger
+	}
+}
+
+// DeterministicTime and DeterministicSeed are the time and random seed of
+// analyzers whose configuration sets processing.deterministic
+var (
+	DeterministicTime       = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	DeterministicSeed int64 = 1
+)
+
+// WithClock sets the clock that stamps fetched data, runs and cache
+// expiry, in place of the system clock
+func WithClock(clock services.Clock) Option {
+	return func(a *Analyzer) {
+		a.clock = clock
+	}
+}
+
+// WithRandom sets the source of the user agents and request delays the
+// fetchers pick
+func WithRandom(random *services.Random) Option {
+	return func(a *Analyzer) {
+		a.random = random
+	}
+}
+
+// WithStockDataProvider values stocks on the data of provider instead of
+// fetching it from the configured sources, which then go unused along
+// with the data source settings and WithGrowthSource. Data is still
+// cached when caching is enabled.
+func WithStockDataProvider(provider services.StockDataProvider) Option {
+	return func(a *Analyzer) {
+		a.provider = provider
+	}
+}
+
+// WithGrowthSource sets where consensus growth rates come from, in place
+// of the analyzer's own fetcher, when growth consensus is enabled. The
+// source is shared by all workers.
+func WithGrowthSource(growth services.GrowthSource) Option {
+	return func(a *Analyzer) {
+		a.dataFetcher.SetGrowthSource(growth)
+	}
+}
+
+// WithIdentifierResolver sets how ISINs and CUSIPs given as tickers are
+// resolved, in place of OpenFIGI
+func WithIdentifierResolver(resolver services.IdentifierResolver) Option {
+	return func(a *Analyzer) {
+		a.resolver = resolver
+	}
+}
+
+// WithSentimentSource sets where news sentiment is scored, in place of
+// Finnhub, when sentiment is enabled
+func WithSentimentSource(source services.SentimentSource) Option {
+	return func(a *Analyzer) {
+		a.sentiment = source
+	}
+}
+
+// WithTransport sends the analyzer's HTTP requests through transport. By
+// default every analyzer shares one transport tuned for concurrent
+// fetches, created by services.NewTransport. The credentials configured
+// for hosts are added on top of transport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(a *Analyzer) {
+		a.transport = transport
+	}
+}
+
+// WithHTTPTrace writes every HTTP request of the analyzer's data sources,
+// and every cache lookup of a ticker's data, to trace
+func WithHTTPTrace(trace *services.HTTPTrace) Option {
+	return func(a *Analyzer) {
+		a.httpTrace = trace
+	}
+}
+
+// New validates cfg and creates an Analyzer for it
+func New(cfg *config.Config, opts ...Option) (*Analyzer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	a := &Analyzer{
+		config:      cfg,
+		dataFetcher: services.NewDataFetcher(),
+		calculator:  newCalculator(cfg),
+		symbols:     symbols.NewMapper(cfg.DataSources.Aliases),
+		logger:      services.NopLogger,
+		clock:       services.SystemClock,
+		workers:     newWorkerLimiter(cfg.Processing.InitialWorkers()),
+		stockData:   make(map[string]*models.StockData),
+		hints:       make(map[string]models.TickerInfo),
+	}
+	if cfg.Processing.Deterministic {
+		// Run IDs have a source of their own, as the order in which
+		// concurrent fetches draw from theirs varies
+		a.clock = services.FixedClock(DeterministicTime)
+		a.random = services.NewRandom(rand.NewSource(DeterministicSeed))
+		a.runIDs = services.NewRandom(rand.NewSource(DeterministicSeed))
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.provider == nil {
+		a.provider = a.dataFetcher
+	}
+	if a.resolver == nil {
+		openFIGI := services.NewOpenFIGI(cfg.DataSources.OpenFIGIAPIKey, cfg.DataSources.IdentifierExchange)
+		openFIGI.SetLogger(a.logger)
+		a.resolver = openFIGI
+	}
+	if !cfg.Sentiment.Enabled {
+		a.sentiment = nil
+	} else if a.sentiment == nil {
+		if cfg.Sentiment.FinnhubAPIKey != "" {
+			finnhub := services.NewFinnhub(cfg.Sentiment.FinnhubAPIKey)
+			finnhub.SetClock(a.clock)
+			a.sentiment = finnhub
+		} else {
+			a.logger.Printf("Warning: news sentiment is enabled without a Finnhub API key; stocks are not scored\n")
+		}
+	}
+
+	// Requests to hosts with credentials carry them, over any transport
+	// given. Traces are taken above the credentials, so they never show.
+	transport := a.transport
+	if len(cfg.DataSources.Hosts) > 0 {
+		transport = services.NewAuthTransport(transport, cfg.DataSources.Hosts)
+	}
+	if a.httpTrace != nil {
+		transport = a.httpTrace.Transport(transport)
+	}
+	if transport != nil {
+		a.dataFetcher.SetTransport(transport)
+	}
+
+	a.dataFetcher.SetClock(a.clock)
+	if a.random != nil {
+		a.dataFetcher.SetRandom(a.random)
+	}
+
+	// Restrict data acquisition to the enabled capabilities
+	a.dataFetcher.SetFeatures(cfg.DataSources.Features())
+	a.dataFetcher.SetLogger(a.logger)
+	if cfg.DataSources.SelectorsFile != "" {
+		selectors, err := services.LoadSelectors(cfg.DataSources.SelectorsFile)
+		if err != nil {
+			return nil, err
+		}
+		a.dataFetcher.SetSelectors(selectors)
+	}
+	if settings := cfg.DataSources.GrowthModel; settings.Enabled {
+		model := services.DefaultGrowthModel()
+		if settings.File != "" {
+			loaded, err := services.LoadGrowthModel(settings.File)
+			if err != nil {
+				return nil, err
+			}
+			model = loaded
+		}
+		if settings.Confidence > 0 {
+			model.Confidence = settings.Confidence
+		}
+		a.dataFetcher.SetGrowthModel(model)
+	}
+
+	var next services.RequestObserver
+	if cfg.Processing.AdaptiveWorkers {
+		a.tuner = newWorkerTuner(a.workers, cfg.Processing.MinWorkerCount(), cfg.Processing.MaxWorkers, a.logger)
+		next = a.tuner
+	}
+	a.metrics = newSourceMetrics(next)
+	a.dataFetcher.SetRequestObserver(a.metrics)
+
+	if !cfg.Assumptions.IsEmpty() {
+		a.logger.Printf("Assuming %s for this run\n", cfg.Assumptions)
+	}
+
+	if cfg.Processing.EnableCaching {
+		cache, err := services.NewCache(cfg.Processing.CacheDir, cfg.Processing.CacheTTL())
+		if err != nil {
+			return nil, fmt.Errorf("failed to open cache: %w", err)
+		}
+		cache.SetClock(a.clock)
+		a.cache = cache
+	}
+
+	return a, nil
+}
+
+// newCalculator creates a calculator with the model parameters of cfg
+func newCalculator(cfg *config.Config) *valuation.Calculator {
+	calculator := valuation.NewCalculator()
+	calculator.SetDCFParameters(cfg.DCFParams)
+	calculator.SetCompsParameters(cfg.CompsParams)
+	calculator.SetWeights(cfg.Weights)
+	calculator.SetBookFloor(cfg.Floor)
+	calculator.SetFallbackParameters(cfg.Fallbacks)
+	calculator.SetDecimalMoney(cfg.Processing.DecimalMoney)
+	calculator.SetSentimentParameters(cfg.Sentiment.Parameters())
+	calculator.SetSizeParameters(cfg.Size.Parameters())
+	calculator.SetCommodityParameters(cfg.Commodity.Parameters())
+	calculator.SetADRParameters(cfg.ADR)
+	return calculator
+}
+
+// Now returns the time on the analyzer's clock, which runs are stamped with
+func (a *Analyzer) Now() time.Time {
+	return a.clock.Now()
+}
+
+// NewRunID returns a new run identifier for a run started at startedAt
+func (a *Analyzer) NewRunID(startedAt time.Time) string {
+	if a.runIDs == nil {
+		return models.NewRunID(startedAt)
+	}
+	return models.NewRunIDFrom(startedAt, a.runIDs)
+}
+
+// Config returns the configuration the analyzer was created with
+func (a *Analyzer) Config() *config.Config {
+	return a.config
+}
+
+// Calculator returns the configured valuation calculator
+func (a *Analyzer) Calculator() *valuation.Calculator {
+	return a.calculator
+}
+
+// Workers returns the current number of concurrent fetches. It changes
+// over time when adaptive workers are enabled.
+func (a *Analyzer) Workers() int {
+	return a.workers.current()
+}
+
+// SourceStats returns statistics of the requests sent to each data source
+// since the analyzer was created, ordered by source
+func (a *Analyzer) SourceStats() []SourceStats {
+	return a.metrics.stats()
+}
+
+// SetForceRefresh makes later fetches skip cached data. Fresh data is
+// still written to the cache.
+func (a *Analyzer) SetForceRefresh(enabled bool) {
+	a.forceRefresh.Store(enabled)
+}
+
+// EnableMemoryCache keeps the stock data and growth consensus of recently
+// used tickers in memory, as configured by processing.memory_cache, for
+// long-running modes that value the same tickers repeatedly. Fetches then
+// reuse current data from memory, fetching only the price once it is
+// stale. It does nothing when the memory cache is disabled, and must be
+// called before any valuation.
+func (a *Analyzer) EnableMemoryCache() {
+	settings := a.config.Processing.MemoryCache
+	if !settings.Enabled || a.memory != nil {
+		return
+	}
+	a.memory = services.NewMemoryCache(settings.Capacity(), settings.PriceTTL(), settings.FundamentalsTTL())
+	a.memory.SetClock(a.clock)
+	a.dataFetcher.SetMemoryCache(a.memory)
+}
+
+// SetRetainStockData controls whether fetched data is kept for
+// RefreshPrices. Streaming a large universe turns it off, so memory does
+// not grow with the number of tickers; RefreshPrices then only re-values
+// tickers fetched while it was on.
+func (a *Analyzer) SetRetainStockData(enabled bool) {
+	a.discardStockData.Store(!enabled)
+}
+
+// Universe returns the configured ticker universe: the explicit ticker list,
+// else the constituents of the index preset, else the matches of the Yahoo
+// Finance screener, else the ticker file, else DefaultTickers, in
+// canonical notation
+func (a *Analyzer) Universe() []string {
+	// An explicit ticker list (e.g. test mode) bypasses the CSV file
+	if len(a.config.DataSources.Tickers) > 0 {
+		infos := make([]models.TickerInfo, len(a.config.DataSources.Tickers))
+		for i, ticker := range a.config.DataSources.Tickers {
+			infos[i].Ticker = ticker
+		}
+		return a.resolveInfos(infos)
+	}
+	if a.config.DataSources.Universe != "" {
+		return a.indexUniverse()
+	}
+	if a.config.DataSources.YahooScreener != "" {
+		return a.screenerUniverse()
+	}
+
+	infos, err := a.dataFetcher.LoadTickerInfosFromCSV(a.config.DataSources.TickerFile)
+	if err != nil {
+		a.logger.Printf("Warning: Could not load tickers from CSV, using defaults: %v\n", err)
+		return DefaultTickers
+	}
+	return a.resolveInfos(infos)
+}
+
+// indexUniverse returns the current constituents of the configured index,
+// or its built-in constituents when they cannot be fetched or the analyzer
+// is cache-only
+func (a *Analyzer) indexUniverse() []string {
+	index := a.config.DataSources.Universe
+	tickers := services.DefaultConstituents(index)
+	if !a.config.Processing.CacheOnly {
+		ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+		defer cancel()
+		fetched, err := a.dataFetcher.FetchIndexConstituents(ctx, index)
+		if err != nil {
+			a.logger.Printf("Warning: Could not refresh the constituents of %s, using the built-in list: %v\n", index, err)
+		} else {
+			tickers = fetched
+		}
+	}
+	infos := make([]models.TickerInfo, len(tickers))
+	for i, ticker := range tickers {
+		infos[i].Ticker = ticker
+	}
+	return a.resolveInfos(infos)
+}
+
+// screenerUniverse returns the matches of the configured Yahoo Finance
+// screener, or DefaultTickers when it cannot be run
+func (a *Analyzer) screenerUniverse() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	infos, err := a.dataFetcher.ScreenerTickers(ctx, a.config.DataSources.YahooScreener, a.config.DataSources.ScreenerLimit)
+	if err != nil {
+		a.logger.Printf("Warning: Could not run screener %s, using defaults: %v\n", a.config.DataSources.YahooScreener, err)
+		return DefaultTickers
+	}
+	if len(infos) == 0 {
+		a.logger.Printf("Warning: Screener %s matches no stocks, using defaults\n", a.config.DataSources.YahooScreener)
+		return DefaultTickers
+	}
+	return a.resolveInfos(infos)
+}
+
+// universeBatch is the number of ticker file rows UniverseSeq resolves
+// identifiers for at once
+const universeBatch = 100
+
+// resolveInfos returns the canonical tickers of infos, recording their
+// hints. ISINs and CUSIPs are resolved to tickers first; those that cannot
+// be are reported and left out.
+func (a *Analyzer) resolveInfos(infos []models.TickerInfo) []string {
+	ids := make([]string, len(infos))
+	for i, info := range infos {
+		ids[i] = info.Ticker
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	resolved, err := a.Resolve(ctx, ids)
+	if err != nil {
+		a.logger.Printf("Warning: %v\n", err)
+	}
+
+	tickers := make([]string, 0, len(infos))
+	for _, info := range infos {
+		ticker, ok := resolved[info.Ticker]
+		if !ok {
+			a.logger.Printf("Warning: Skipping %s, which matches no listing\n", info.Ticker)
+			continue
+		}
+		info.Ticker = ticker
+		tickers = append(tickers, ticker)
+		a.setHints(info)
+	}
+	return tickers
+}
+
+// UniverseSeq returns the configured ticker universe like Universe, but
+// reads the ticker file as the sequence is consumed instead of loading it
+// first. Call done once the sequence has been consumed to close the file
+// and learn whether reading it failed.
+func (a *Analyzer) UniverseSeq() (tickers iter.Seq[string], done func() error) {
+	// Index constituents and screener matches are fetched whole
+	sources := a.config.DataSources
+	if len(sources.Tickers) > 0 || sources.Universe != "" || sources.YahooScreener != "" {
+		return slices.Values(a.Universe()), func() error { return nil }
+	}
+
+	reader, err := services.OpenTickerFile(a.config.DataSources.TickerFile)
+	if err != nil {
+		a.logger.Printf("Warning: Could not load tickers from CSV, using defaults: %v\n", err)
+		return slices.Values(DefaultTickers), func() error { return nil }
+	}
+	tickers = func(yield func(string) bool) {
+		// Rows are resolved a batch at a time, so the identifiers among
+		// them take few OpenFIGI requests
+		batch := make([]models.TickerInfo, 0, universeBatch)
+		flush := func() bool {
+			defer func() { batch = batch[:0] }()
+			for _, ticker := range a.resolveInfos(batch) {
+				if !yield(ticker) {
+					return false
+				}
+			}
+			return true
+		}
+		for info := range reader.AllInfo() {
+			if batch = append(batch, info); len(batch) == universeBatch && !flush() {
+				return
+			}
+		}
+		flush()
+	}
+	return tickers, func() error {
+		reader.Close()
+		return reader.Err()
+	}
+}
+
+// setHints records the hints the ticker file gives for a ticker, which
+// are applied to its data whenever it is fetched
+func (a *Analyzer) setHints(info models.TickerInfo) {
+	if !info.HasHints() {
+		return
+	}
+	a.hintsMutex.Lock()
+	defer a.hintsMutex.Unlock()
+	a.hints[info.Ticker] = info
+}
+
+// Canonical returns ticker in the canonical notation the analyzer values
+// it under, resolving the configured aliases, so BRK.B, BRK/B and BRKB
+// are all BRK-B
+func (a *Analyzer) Canonical(ticker string) string {
+	return a.symbols.Canonical(ticker)
+}
+
+// resolveTimeout bounds the identifier resolution of Universe and
+// UniverseSeq, which take no context
+const resolveTimeout = 2 * time.Minute
+
+// Resolve returns the canonical ticker of each of tickers, keyed by the
+// ticker as given. ISINs and CUSIPs among them are resolved to the ticker
+// of their listing; those without one are left out of the map, as are all
+// identifiers when resolving them fails, which the error then says.
+func (a *Analyzer) Resolve(ctx context.Context, tickers []string) (map[string]string, error) {
+	resolved := make(map[string]string, len(tickers))
+	var ids []string
+	for _, ticker := range tickers {
+		if symbols.IsIdentifier(ticker) {
+			ids = append(ids, ticker)
+		} else {
+			resolved[ticker] = a.Canonical(ticker)
+		}
+	}
+	if len(ids) == 0 {
+		return resolved, nil
+	}
+
+	listings, err := a.resolver.ResolveIdentifiers(ctx, ids)
+	for _, id := range ids {
+		if ticker, ok := listings[strings.ToUpper(strings.TrimSpace(id))]; ok {
+			resolved[id] = a.Canonical(ticker)
+		}
+	}
+	return resolved, err
+}
+
+// Analyze values the given tickers and returns them as a run. Tickers that
+// fail are recorded in the run's errors rather than failing the whole run.
+// If ctx is cancelled, the valuations completed so far are returned as a
+// partial run together with the context's error.
+func (a *Analyzer) Analyze(ctx context.Context, tickers []string) (*models.Run, error) {
+	startedAt := a.Now()
+	a.CheckScrapers(ctx)
+	valuations := a.ValuateAll(ctx, tickers)
+
+	run := a.NewRun(startedAt, valuations)
+	a.Stamp(run)
+	if err := ctx.Err(); err != nil {
+		run.Partial = true
+		return run, fmt.Errorf("analysis interrupted: %w", err)
+	}
+	return run, nil
+}
+
+// Valuate values a single ticker
+func (a *Analyzer) Valuate(ctx context.Context, ticker string) Valuation {
+	var result Valuation
+	a.ValuateEach(ctx, []string{ticker}, func(_ int, v Valuation) {
+		result = v
+	})
+	return result
+}
+
+// ValuateAll values the given tickers, returning valuations in input order
+// with their Comps values anchored on their peers
+func (a *Analyzer) ValuateAll(ctx context.Context, tickers []string) []Valuation {
+	valuations := make([]Valuation, len(tickers))
+	a.ValuateEach(ctx, tickers, func(i int, v Valuation) {
+		valuations[i] = v
+	})
+	a.ComparePeers(valuations)
+	return valuations
+}
+
+// ValuateEach values the given tickers concurrently, calling fn with each
+// ticker's index and valuation as soon as it completes. See ValuateSeq.
+func (a *Analyzer) ValuateEach(ctx context.Context, tickers []string, fn func(int, Valuation)) {
+	a.ValuateSeq(ctx, slices.Values(tickers), fn)
+}
+
+// ValuateSeq values the tickers of a sequence concurrently, calling fn with
+// each ticker's position in the sequence and its valuation as soon as it
+// completes. The sequence is consumed only as workers become free, so at
+// most the worker limit of tickers is held at once. fn is called exactly
+// once per ticker, and calls to fn are serialized, so fn needs no locking
+// of its own. A ticker whose valuation panics is reported with the panic as
+// its error. Once ctx is cancelled, tickers not yet started are reported
+// with the context's error without being fetched.
+func (a *Analyzer) ValuateSeq(ctx context.Context, tickers iter.Seq[string], fn func(int, Valuation)) {
+	var mu sync.Mutex
+	report := func(i int, v Valuation) {
+		mu.Lock()
+		defer mu.Unlock()
+		fn(i, v)
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	next := 0
+	for ticker := range tickers {
+		i := next
+		next++
+
+		// Taking the worker slot before starting the goroutine keeps only
+		// the tickers being valued in flight
+		if err := a.workers.acquire(groupCtx); err != nil {
+			report(i, Valuation{Ticker: ticker, Err: err})
+			continue
+		}
+		group.Go(func() error {
+			defer a.workers.release()
+			report(i, a.valuate(groupCtx, ticker))
+			return nil
+		})
+	}
+	group.Wait()
+}
+
+// valuate fetches and values a single ticker
+func (a *Analyzer) valuate(ctx context.Context, ticker string) Valuation {
+	if symbols.IsIdentifier(ticker) {
+		resolved, err := a.Resolve(ctx, []string{ticker})
+		if err != nil {
+			return Valuation{Ticker: ticker, Err: err}
+		}
+		if _, ok := resolved[ticker]; !ok {
+			return Valuation{Ticker: ticker, Err: fmt.Errorf("%w: %s matches no listing", services.ErrSymbolNotFound, ticker)}
+		}
+		ticker = resolved[ticker]
+	}
+	ticker = a.Canonical(ticker)
+	ctx, span := tracer.Start(ctx, "Analyzer.Valuate", trace.WithAttributes(attribute.String("ticker", ticker)))
+	v := a.valuateTraced(ctx, ticker)
+	if v.Err != nil {
+		span.RecordError(v.Err)
+		span.SetStatus(codes.Error, v.Err.Error())
+	} else {
+		span.SetAttributes(
+			attribute.String("status", v.Result.Status),
+			attribute.Float64("upside_pct", v.Result.UpsidePercentage),
+			attribute.Bool("incomplete", v.Result.Incomplete),
+		)
+	}
+	span.End()
+	return v
+}
+
+// valuateTraced does the work of valuate inside its span, turning a panic
+// into the ticker's error so one bad ticker cannot stop the others
+func (a *Analyzer) valuateTraced(ctx context.Context, ticker string) (v Valuation) {
+	defer func() {
+		if r := recover(); r != nil {
+			a.logger.Printf("Error: valuing %s panicked: %v\n%s", ticker, r, debug.Stack())
+			v = Valuation{Ticker: ticker, Err: fmt.Errorf("valuing %s panicked: %v", ticker, r)}
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return Valuation{Ticker: ticker, Err: err}
+	}
+
+	stockData, err := a.FetchStockData(ctx, ticker)
+	if err != nil {
+		return Valuation{Ticker: ticker, Err: err}
+	}
+	return a.valuateData(ctx, stockData)
+}
+
+// ValuateStockData values data fetched earlier, such as the inputs of a
+// recorded result, without fetching anything. The data is kept for price
+// refreshes like freshly fetched data.
+func (a *Analyzer) ValuateStockData(ctx context.Context, stockData *models.StockData) Valuation {
+	a.rememberStockData(stockData)
+	return a.valuateData(ctx, stockData)
+}
+
+// valuateData checks and values stockData
+func (a *Analyzer) valuateData(ctx context.Context, stockData *models.StockData) Valuation {
+	ticker := stockData.Ticker
+	// Price-only instruments are shown with their price but not valued
+	if stockData.PriceOnly() {
+		return Valuation{Ticker: ticker, StockData: stockData, Result: models.NewNotValuableResult(stockData)}
+	}
+	violations, err := a.checkInputs(stockData)
+	if err != nil {
+		return Valuation{Ticker: ticker, Err: err}
+	}
+	for _, violation := range violations {
+		a.logger.Printf("Warning: %s failed a sanity check: %s\n", ticker, violation)
+	}
+	// The checks apply to the data as fetched, before the assumptions
+	stockData = a.assume(stockData)
+	// Stocks the fallback policies leave without an FCF or EPS to value
+	// are reported rather than valued at their floor
+	if err := a.calculator.CheckFallbacks(stockData); err != nil {
+		return Valuation{Ticker: ticker, Err: err}
+	}
+
+	_, span := tracer.Start(ctx, "Calculator.CalculateFairValue")
+	result := a.calculator.CalculateFairValue(stockData)
+	var breakdown *valuation.Breakdown
+	if result != nil {
+		breakdown = a.calculator.Explain(stockData)
+	}
+	span.End()
+	if result == nil {
+		return Valuation{Ticker: ticker, Err: fmt.Errorf("failed to calculate valuation for %s", ticker)}
+	}
+	result.Violations = violations
+	result.Relative = a.currentBenchmark(ctx).Relate(result)
+
+	return Valuation{
+		Ticker:    ticker,
+		StockData: stockData,
+		Result:    result,
+		Breakdown: breakdown,
+	}
+}
+
+// assume returns stockData with the inputs the run assumes for it, which
+// are never cached nor kept for price refreshes
+func (a *Analyzer) assume(stockData *models.StockData) *models.StockData {
+	return a.config.Assumptions.Apply(stockData, a.Canonical)
+}
+
+// FetchStockData returns stock data from the cache or fetches it, under
+// the canonical notation of ticker. When the configured ticker timeout
+// passes first, the data fetched until then is returned marked as
+// incomplete and is not cached. A cache-only analyzer returns cached data
+// of any age and fails with services.ErrNotCached for the rest.
+func (a *Analyzer) FetchStockData(ctx context.Context, ticker string) (*models.StockData, error) {
+	ticker = a.Canonical(ticker)
+	ctx = services.WithTicker(ctx, ticker)
+	if a.config.Processing.CacheOnly {
+		stockData, ok := a.cache.Load(ticker)
+		if !ok {
+			a.httpTrace.Cache(ticker, services.CacheMiss)
+			return nil, fmt.Errorf("no data for %s: %w", ticker, services.ErrNotCached)
+		}
+		a.httpTrace.Cache(ticker, services.CacheHit)
+		a.rememberStockData(stockData)
+		return stockData, nil
+	}
+	if a.memory != nil && !a.forceRefresh.Load() {
+		if stockData, ok := a.fromMemory(ctx, ticker); ok {
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("memory_cache_hit", true))
+			a.httpTrace.Cache(ticker, services.CacheMemoryHit)
+			a.rememberStockData(stockData)
+			return stockData, nil
+		}
+	}
+	if a.cache != nil && !a.forceRefresh.Load() {
+		if stockData, ok := a.cache.Get(ticker); ok {
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cache_hit", true))
+			a.httpTrace.Cache(ticker, services.CacheHit)
+			if a.memory != nil {
+				a.memory.PutStockData(stockData)
+			}
+			a.rememberStockData(stockData)
+			return stockData, nil
+		}
+	}
+
+	if a.cache != nil || a.memory != nil {
+		a.httpTrace.Cache(ticker, services.CacheMiss)
+	}
+
+	fetchCtx := ctx
+	if timeout := a.config.Processing.TickerTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		fetchCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	stockData, err := a.provider.FetchStockData(fetchCtx, ticker)
+	timedOut := fetchCtx.Err() != nil && ctx.Err() == nil
+	if err != nil {
+		if timedOut {
+			return nil, fmt.Errorf("failed to fetch data for %s within %s: %w",
+				ticker, a.config.Processing.TickerTimeout(), err)
+		}
+		return nil, fmt.Errorf("failed to fetch data for %s: %w", ticker, err)
+	}
+
+	if timedOut {
+		a.logger.Printf("Warning: %s timed out after %s, valuing it on the data fetched so far\n",
+			ticker, a.config.Processing.TickerTimeout())
+		stockData.Incomplete = true
+		a.rememberStockData(stockData)
+		return stockData, nil
+	}
+
+	if !stockData.PriceOnly() {
+		a.scoreSentiment(ctx, stockData)
+		a.fetchShareCounts(ctx, stockData)
+	}
+	if a.cache != nil {
+		if err := a.cache.Put(stockData); err != nil {
+			a.logger.Printf("Warning: failed to cache data for %s: %v\n", ticker, err)
+		}
+	}
+	if a.memory != nil {
+		a.memory.PutStockData(stockData)
+	}
+
+	a.rememberStockData(stockData)
+	return stockData, nil
+}
+
+// fromMemory returns the data of ticker kept in the memory cache while its
+// fundamentals are current, fetching a fresh price when it is stale. Data
+// whose price cannot be refreshed is fetched anew.
+func (a *Analyzer) fromMemory(ctx context.Context, ticker string) (*models.StockData, bool) {
+	stockData, priceCurrent, ok := a.memory.StockData(ticker)
+	if !ok {
+		return nil, false
+	}
+	if !priceCurrent {
+		price, err := a.provider.FetchPrice(ctx, ticker)
+		if err != nil {
+			a.logger.Printf("Warning: could not refresh the price of %s, fetching it anew: %v\n", ticker, err)
+			return nil, false
+		}
+		stockData.CurrentPrice = price
+		stockData.Stamp(a.Now(), "current_price")
+		a.adjustForSplits(ctx, stockData)
+		a.memory.PutStockData(stockData)
+	}
+	return stockData, true
+}
+
+// checkInputs runs the configured sanity checks on stockData. It returns
+// the violations to list with the result when they are flagged, and an
+// error wrapping sanity.ErrInconsistent when they are rejected. Data with
+// fewer fetched valuation inputs than the configured minimum is rejected
+// first, with an error wrapping models.ErrInsufficientData.
+func (a *Analyzer) checkInputs(stockData *models.StockData) ([]models.Violation, error) {
+	if err := stockData.CheckCompleteness(a.config.Validation.MinRealInputs); err != nil {
+		return nil, err
+	}
+	if a.config.Validation.Mode == config.ValidationOff {
+		return nil, nil
+	}
+	violations := sanity.Check(stockData, a.config.Validation.Rules())
+	if len(violations) == 0 {
+		return nil, nil
+	}
+	if a.config.Validation.Mode == config.ValidationReject {
+		return nil, fmt.Errorf("%s: %w", stockData.Ticker, sanity.Error(violations))
+	}
+	return violations, nil
+}
+
+// rememberStockData applies the ticker file's hints to the latest data
+// fetched for a ticker, normalizes its sector and industry, whichever
+// provider or cache it came from, and records it
+func (a *Analyzer) rememberStockData(stockData *models.StockData) {
+	a.hintsMutex.RLock()
+	info, ok := a.hints[stockData.Ticker]
+	a.hintsMutex.RUnlock()
+	if ok {
+		info.Apply(stockData)
+	}
+	stockData.Classify()
+
+	if a.discardStockData.Load() {
+		return
+	}
+	a.dataMutex.Lock()
+	defer a.dataMutex.Unlock()
+	a.stockData[stockData.Ticker] = stockData
+}
+
+// Stamp records the build version, configuration hash and model
+// parameters of the analyzer with run, so exports of the run can be traced
+// back to the code and settings that produced them, along with the market
+// benchmark its results were related to and the scrapers found degraded.
+// The run is tagged with the run tags of the configuration.
+func (a *Analyzer) Stamp(run *models.Run) {
+	run.Version = buildinfo.Version()
+	run.ConfigHash = a.config.Hash()
+	run.Config = a.config.Snapshot()
+	run.Benchmark = a.Benchmark()
+	run.DegradedScrapers = a.DegradedScrapers()
+	run.Tags = slices.Clone(a.config.RunTags)
+}
+
+// NewRun builds a run from valuations. Failed tickers get a result with
+// status models.StatusError, or models.StatusInsufficientData when their
+// data was incomplete, and are also recorded in the run's errors.
+// Stamp it to record how it was produced.
+func NewRun(startedAt time.Time, valuations []Valuation) *models.Run {
+	return newRun(models.NewRunID(startedAt), startedAt, time.Now(), valuations)
+}
+
+// NewRun builds a run from valuations like the package-level NewRun, with
+// its ID and finish time taken from the analyzer's sources
+func (a *Analyzer) NewRun(startedAt time.Time, valuations []Valuation) *models.Run {
+	return newRun(a.NewRunID(startedAt), startedAt, a.Now(), valuations)
+}
+
+// newRun builds a run with the given ID and times from valuations
+func newRun(id string, startedAt, finishedAt time.Time, valuations []Valuation) *models.Run {
+	run := &models.Run{
+		ID:         id,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		Results:    make([]*models.ValuationResult, 0, len(valuations)),
+	}
+	for _, v := range valuations {
+		if v.Err != nil {
+			if run.Errors == nil {
+				run.Errors = make(map[string]string)
+			}
+			run.Errors[v.Ticker] = v.Err.Error()
+			run.Results = append(run.Results, models.NewFailedResult(v.Ticker, v.Err))
+			continue
+		}
+		run.Results = append(run.Results, v.Result)
+	}
+	return run
+}
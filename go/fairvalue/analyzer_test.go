@@ -0,0 +1,189 @@
+package fairvalue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/services"
+	"github.com/lesnerd/fair-stock-value/go/services/servicestest"
+)
+
+// newTestAnalyzer returns an analyzer valuing the data of provider without
+// caching it, with cfg changed by configure when given
+func newTestAnalyzer(t testing.TB, provider *servicestest.Provider, configure func(cfg *config.Config)) *Analyzer {
+	t.Helper()
+	cfg := config.NewDefaultConfig()
+	cfg.Processing.EnableCaching = false
+	if configure != nil {
+		configure(cfg)
+	}
+	analyzer, err := New(cfg, WithStockDataProvider(provider))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return analyzer
+}
+
+// resultsByTicker returns the results of run by ticker
+func resultsByTicker(run *models.Run) map[string]*models.ValuationResult {
+	results := make(map[string]*models.ValuationResult, len(run.Results))
+	for _, result := range run.Results {
+		results[result.Ticker] = result
+	}
+	return results
+}
+
+func TestAnalyzeValuesCannedStocks(t *testing.T) {
+	stocks := servicestest.Stocks()
+	analyzer := newTestAnalyzer(t, servicestest.NewProvider(stocks...), nil)
+
+	run, err := analyzer.Analyze(context.Background(), servicestest.Tickers())
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if run.Partial || len(run.Errors) > 0 {
+		t.Fatalf("run partial %v with errors %v, want complete without errors", run.Partial, run.Errors)
+	}
+	if len(run.Results) != len(stocks) {
+		t.Fatalf("got %d results, want %d", len(run.Results), len(stocks))
+	}
+	for i, result := range run.Results {
+		stock := stocks[i]
+		if result.Ticker != stock.Ticker {
+			t.Errorf("result %d is %s, want %s in input order", i, result.Ticker, stock.Ticker)
+		}
+		if result.Status != models.StatusUnderpriced && result.Status != models.StatusOverpriced {
+			t.Errorf("%s: status %q (%s), want it valued", stock.Ticker, result.Status, result.Error)
+		}
+		if result.FairValue <= 0 {
+			t.Errorf("%s: fair value %.2f, want positive", stock.Ticker, result.FairValue)
+		}
+		if result.CurrentPrice != stock.CurrentPrice {
+			t.Errorf("%s: price %.2f, want the served %.2f", stock.Ticker, result.CurrentPrice, stock.CurrentPrice)
+		}
+		if got := result.PriceDifference; got != result.FairValue-result.CurrentPrice {
+			t.Errorf("%s: price difference %.2f, want fair value less price", stock.Ticker, got)
+		}
+	}
+}
+
+func TestAnalyzeReportsUnknownTicker(t *testing.T) {
+	provider := servicestest.NewProvider(servicestest.Stocks()...)
+	analyzer := newTestAnalyzer(t, provider, nil)
+
+	run, err := analyzer.Analyze(context.Background(), []string{"AAPL", "NOSUCH", "MSFT"})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	results := resultsByTicker(run)
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want one per ticker", len(results))
+	}
+	for _, ticker := range []string{"AAPL", "MSFT"} {
+		if results[ticker].Failed() {
+			t.Errorf("%s failed with %q alongside the unknown ticker", ticker, results[ticker].Error)
+		}
+	}
+
+	result := results["NOSUCH"]
+	if result.Status != models.StatusError {
+		t.Fatalf("unknown ticker has status %q, want %q", result.Status, models.StatusError)
+	}
+	if !strings.Contains(result.Error, services.ErrSymbolNotFound.Error()) {
+		t.Errorf("unknown ticker error %q, want it to name %q", result.Error, services.ErrSymbolNotFound)
+	}
+	if run.Errors["NOSUCH"] != result.Error {
+		t.Errorf("run errors %v, want the unknown ticker's error recorded", run.Errors)
+	}
+	if result.FairValue != 0 || result.CurrentPrice != 0 {
+		t.Errorf("unknown ticker has fair value %.2f and price %.2f, want no figures", result.FairValue, result.CurrentPrice)
+	}
+}
+
+func TestAnalyzeTimesOutSlowTicker(t *testing.T) {
+	provider := servicestest.NewProvider(servicestest.Stocks()...)
+	provider.SetDelay(time.Minute)
+	analyzer := newTestAnalyzer(t, provider, func(cfg *config.Config) {
+		cfg.Processing.TickerTimeoutSeconds = 1
+	})
+
+	started := time.Now()
+	run, err := analyzer.Analyze(context.Background(), []string{"AAPL"})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 30*time.Second {
+		t.Fatalf("Analyze took %s, want it bounded by the ticker timeout", elapsed)
+	}
+	if run.Partial {
+		t.Error("run is partial, want a timed out ticker recorded in a complete run")
+	}
+
+	result := resultsByTicker(run)["AAPL"]
+	if result == nil || result.Status != models.StatusError {
+		t.Fatalf("timed out ticker has result %+v, want status %q", result, models.StatusError)
+	}
+	if !strings.Contains(result.Error, "within 1s") || !strings.Contains(result.Error, context.DeadlineExceeded.Error()) {
+		t.Errorf("timed out ticker error %q, want the ticker timeout and deadline", result.Error)
+	}
+	if run.Errors["AAPL"] != result.Error {
+		t.Errorf("run errors %v, want the timed out ticker's error recorded", run.Errors)
+	}
+}
+
+func TestAnalyzeReturnsPartialRunWhenCancelled(t *testing.T) {
+	provider := servicestest.NewProvider(servicestest.Stocks()...)
+	provider.SetDelay(time.Minute)
+	analyzer := newTestAnalyzer(t, provider, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	tickers := servicestest.Tickers()
+	started := time.Now()
+	run, err := analyzer.Analyze(ctx, tickers)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Analyze returned %v, want it to wrap %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(started); elapsed > 30*time.Second {
+		t.Fatalf("Analyze took %s after cancellation, want it to stop", elapsed)
+	}
+	if run == nil || !run.Partial {
+		t.Fatalf("got run %+v, want a partial run", run)
+	}
+	if len(run.Results) != len(tickers) {
+		t.Fatalf("got %d results, want every ticker accounted for", len(run.Results))
+	}
+	for _, result := range run.Results {
+		if result.Status != models.StatusError || !strings.Contains(result.Error, context.Canceled.Error()) {
+			t.Errorf("%s: status %q with error %q, want it failed as cancelled", result.Ticker, result.Status, result.Error)
+		}
+	}
+	for _, ticker := range tickers {
+		if n := provider.Requests(ticker); n > 1 {
+			t.Errorf("%s requested %d times after cancellation, want at most once", ticker, n)
+		}
+	}
+}
+
+// BenchmarkValuate runs the pipeline from fetching to valuation on the
+// canned stocks of servicestest, which needs no network
+func BenchmarkValuate(b *testing.B) {
+	analyzer := newTestAnalyzer(b, servicestest.NewProvider(servicestest.Stocks()...), nil)
+
+	tickers := servicestest.Tickers()
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, v := range analyzer.ValuateAll(context.Background(), tickers) {
+			if v.Err != nil {
+				b.Fatal(fmt.Errorf("%s: %w", v.Ticker, v.Err))
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package fairvalue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/services"
+)
+
+// benchmarkInterval is how long a fetched benchmark is reused, so a run
+// fetches it once while watch mode still follows the market through the day
+const benchmarkInterval = time.Hour
+
+// benchmarkCache holds the last benchmark fetched
+type benchmarkCache struct {
+	mu        sync.Mutex
+	checked   time.Time // when it was last fetched, or failed to be
+	benchmark *models.Benchmark
+}
+
+// Benchmark returns the market benchmark results were last related to, or
+// nil when it is disabled, unknown to the provider or could not be fetched
+func (a *Analyzer) Benchmark() *models.Benchmark {
+	a.benchmark.mu.Lock()
+	defer a.benchmark.mu.Unlock()
+	return a.benchmark.benchmark
+}
+
+// currentBenchmark returns the benchmark, fetching it when it has not been
+// fetched in the last benchmarkInterval. A failure is logged and the
+// benchmark fetched before, if any, kept until the next attempt. A
+// cache-only analyzer has none, as it is not cached.
+func (a *Analyzer) currentBenchmark(ctx context.Context) *models.Benchmark {
+	settings := a.config.Benchmark
+	source, ok := a.provider.(services.BenchmarkSource)
+	if settings.Disabled || !ok || a.config.Processing.CacheOnly {
+		return nil
+	}
+
+	a.benchmark.mu.Lock()
+	defer a.benchmark.mu.Unlock()
+	now := a.Now()
+	if !a.benchmark.checked.IsZero() && now.Sub(a.benchmark.checked) < benchmarkInterval {
+		return a.benchmark.benchmark
+	}
+
+	benchmark, err := source.FetchBenchmark(ctx, settings.Symbol)
+	if err != nil {
+		// A ticker timing out says nothing about the benchmark, which the
+		// next ticker fetches again
+		if ctx.Err() == nil {
+			a.benchmark.checked = now
+			a.logger.Printf("Warning: could not fetch the benchmark %s: %v\n", settings.Symbol, err)
+		}
+		return a.benchmark.benchmark
+	}
+	benchmark.SetLongRunPE(settings.LongRunPE)
+	a.benchmark.checked = now
+	a.benchmark.benchmark = benchmark
+	return benchmark
+}
@@ -0,0 +1,28 @@
+package fairvalue
+
+import (
+	"context"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/services"
+)
+
+// fetchShareCounts sets the share count history of freshly fetched
+// stockData when buybacks are part of the DCF growth rate, so it is cached
+// along with the fundamentals. Providers that do not know share counts
+// leave the growth rate as it is.
+func (a *Analyzer) fetchShareCounts(ctx context.Context, stockData *models.StockData) {
+	if !a.config.DCFParams.IncludeBuybacks {
+		return
+	}
+	source, ok := a.provider.(services.ShareCountSource)
+	if !ok {
+		return
+	}
+	counts, err := source.ShareCounts(ctx, stockData.Ticker)
+	if err != nil {
+		a.logger.Printf("Warning: valuing %s without buybacks: %v\n", stockData.Ticker, err)
+		return
+	}
+	stockData.ShareCounts = counts
+}
@@ -0,0 +1,114 @@
+package fairvalue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/services"
+)
+
+// Health check statuses
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded" // usable, but valuations may rely on fallback data
+	HealthFailed   = "failed"
+)
+
+// providerCheckInterval is how long provider probes are reused, so frequent
+// readiness checks do not add load on the data providers
+const providerCheckInterval = 30 * time.Second
+
+// HealthCheck is the outcome of one readiness check
+type HealthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Health is the readiness of an Analyzer. Status is the worst status of
+// its checks.
+type Health struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// Ready reports whether the analyzer can serve valuations
+func (h Health) Ready() bool {
+	return h.Status != HealthFailed
+}
+
+// providerProbes caches the last provider check
+type providerProbes struct {
+	mu      sync.Mutex
+	checked time.Time
+	checks  []services.ProviderCheck
+}
+
+// CheckHealth verifies the configuration, that the cache can be written
+// and that the enabled data providers are reachable, and reports the
+// scrapers the last scraper check found degraded. An unreachable
+// provider fails the check only when fallback data is disabled, since
+// otherwise stocks can still be valued.
+func (a *Analyzer) CheckHealth(ctx context.Context) Health {
+	var checks []HealthCheck
+	add := func(name string, err error, failStatus string) {
+		check := HealthCheck{Name: name, Status: HealthOK}
+		if err != nil {
+			check.Status = failStatus
+			check.Error = err.Error()
+		}
+		checks = append(checks, check)
+	}
+
+	add("config", a.config.Validate(), HealthFailed)
+	if a.cache != nil {
+		add("cache", a.cache.Check(), HealthFailed)
+	}
+
+	providerStatus := HealthFailed
+	if a.config.DataSources.EnableFallbackData {
+		providerStatus = HealthDegraded
+	}
+	for _, probe := range a.providerChecks(ctx) {
+		add("provider:"+probe.Host, probe.Err, providerStatus)
+	}
+
+	// Scrapers are not checked here, as that takes a request per page;
+	// those the last check found degraded leave data to other sources
+	for _, source := range a.DegradedScrapers() {
+		add("scraper:"+source, fmt.Errorf("no values found on the page; its layout may have changed"), HealthDegraded)
+	}
+
+	health := Health{Status: HealthOK, Checks: checks}
+	for _, check := range checks {
+		if check.Status == HealthFailed || health.Status == HealthOK {
+			health.Status = check.Status
+		}
+	}
+	return health
+}
+
+// providerChecks returns recent provider probes, probing again once they
+// are older than providerCheckInterval. There are none when a
+// StockDataProvider replaces the configured sources.
+func (a *Analyzer) providerChecks(ctx context.Context) []services.ProviderCheck {
+	if a.provider != services.StockDataProvider(a.dataFetcher) {
+		return nil
+	}
+
+	a.probes.mu.Lock()
+	defer a.probes.mu.Unlock()
+
+	if a.probes.checks == nil || time.Since(a.probes.checked) >= providerCheckInterval {
+		checks := a.dataFetcher.CheckProviders(ctx)
+		// Probes cut short by the caller say nothing about the providers
+		if ctx.Err() != nil {
+			return checks
+		}
+		a.probes.checks = checks
+		a.probes.checked = time.Now()
+	}
+	return a.probes.checks
+}
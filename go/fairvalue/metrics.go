@@ -0,0 +1,93 @@
+package fairvalue
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/services"
+)
+
+// SourceStats summarizes the requests sent to one data source
+type SourceStats struct {
+	Source       string
+	Requests     int
+	Succeeded    int           // answered with a status below 400
+	ClientErrors int           // answered with a 4xx status
+	ServerErrors int           // answered with a 5xx status
+	Throttled    int           // answered with HTTP 429 or 403, also counted as client errors
+	Failed       int           // requests without a response
+	Latency      time.Duration // average latency of answered requests
+	totalLatency time.Duration
+}
+
+// SuccessRate returns the share of requests that succeeded
+func (s SourceStats) SuccessRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Succeeded) / float64(s.Requests)
+}
+
+// sourceMetrics counts the requests of every data source and passes each
+// on to next, the worker tuner when adaptive workers are enabled
+type sourceMetrics struct {
+	next services.RequestObserver
+
+	mu      sync.Mutex
+	sources map[string]*SourceStats
+}
+
+// newSourceMetrics creates metrics passing requests on to next, which may
+// be nil
+func newSourceMetrics(next services.RequestObserver) *sourceMetrics {
+	return &sourceMetrics{next: next, sources: make(map[string]*SourceStats)}
+}
+
+// ObserveRequest counts the outcome of a request
+func (m *sourceMetrics) ObserveRequest(source string, status int, latency time.Duration, err error) {
+	m.mu.Lock()
+	stats, ok := m.sources[source]
+	if !ok {
+		stats = &SourceStats{Source: source}
+		m.sources[source] = stats
+	}
+	stats.Requests++
+	switch {
+	case err != nil || status == 0:
+		stats.Failed++
+	case status >= 500:
+		stats.ServerErrors++
+	case status >= 400:
+		stats.ClientErrors++
+		if throttled(status) {
+			stats.Throttled++
+		}
+	default:
+		stats.Succeeded++
+	}
+	if err == nil && status != 0 {
+		stats.totalLatency += latency
+		stats.Latency = stats.totalLatency / time.Duration(stats.Requests-stats.Failed)
+	}
+	m.mu.Unlock()
+
+	if m.next != nil {
+		m.next.ObserveRequest(source, status, latency, err)
+	}
+}
+
+// stats returns a copy of the per-source statistics ordered by source
+func (m *sourceMetrics) stats() []SourceStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]SourceStats, 0, len(m.sources))
+	for _, s := range m.sources {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Source < stats[j].Source
+	})
+	return stats
+}
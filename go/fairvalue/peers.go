@@ -0,0 +1,147 @@
+package fairvalue
+
+import (
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/peers"
+)
+
+// ComparePeers anchors the Comps value of each valuation on the median P/E
+// of its peers, replacing its stock data, result and breakdown. Peers are
+// chosen among the valued stocks, the stocks the analyzer fetched before
+// and the fresh entries of the cache, so a few tickers valued on their own
+// are compared with the universe of earlier runs. Stocks with too few
+// comparable companies keep their own P/E. Nothing changes when peer
+// groups are disabled.
+//
+// ValuateAll and Analyze compare peers once every ticker is valued;
+// callers valuing tickers with ValuateEach call it once they all are.
+// Valuations restored from a checkpoint, with a result but no stock data,
+// are compared on the inputs of their result.
+//
+// Each peer carries its own upside: valued on its peers when it is among
+// valuations, and on the data it was fetched with otherwise. Stocks whose
+// P/E the run assumes are valued on it rather than on peers.
+func (a *Analyzer) ComparePeers(valuations []Valuation) {
+	if !a.config.Peers.Enabled {
+		return
+	}
+	universe := a.peerUniverse(valuations)
+	rules := a.config.Peers.Rules()
+
+	groups := make([]*models.PeerGroup, len(valuations))
+	upsides := make(map[string]float64)
+	for i, v := range valuations {
+		stockData := valuationData(v)
+		if stockData == nil {
+			continue
+		}
+		if _, assumed := a.config.Assumptions.For(stockData.Ticker, a.Canonical)["pe"]; !assumed {
+			groups[i] = peers.Select(stockData, universe, rules)
+		}
+		updated := stockData.Snapshot()
+		updated.Peers = groups[i]
+		upsides[stockData.Ticker] = a.calculator.CalculateFairValue(updated).UpsidePercentage
+	}
+	upside := func(ticker string) float64 {
+		if value, ok := upsides[ticker]; ok {
+			return value
+		}
+		for _, stockData := range universe {
+			if stockData.Ticker == ticker {
+				upsides[ticker] = a.calculator.CalculateFairValue(stockData).UpsidePercentage
+			}
+		}
+		return upsides[ticker]
+	}
+
+	for i, v := range valuations {
+		stockData := valuationData(v)
+		if stockData == nil || (groups[i] == nil && stockData.Peers == nil) {
+			continue
+		}
+		if group := groups[i]; group != nil {
+			for j := range group.Peers {
+				group.Peers[j].Upside = upside(group.Peers[j].Ticker)
+			}
+		}
+		updated := stockData.Snapshot()
+		updated.Peers = groups[i]
+		valuations[i] = a.revalue(v, updated)
+	}
+}
+
+// valuationData returns the stock data v was valued on, or nil when it failed
+// or was not valued, as price-only instruments are not
+func valuationData(v Valuation) *models.StockData {
+	switch {
+	case v.Err != nil, v.Result != nil && v.Result.Failed():
+		return nil
+	case v.StockData != nil:
+		return v.StockData
+	case v.Result != nil:
+		return v.Result.Inputs
+	}
+	return nil
+}
+
+// peerUniverse returns the stocks peers are chosen among: those of
+// valuations, then those fetched before and cached for other tickers
+func (a *Analyzer) peerUniverse(valuations []Valuation) []*models.StockData {
+	seen := make(map[string]bool)
+	var universe []*models.StockData
+	add := func(stockData *models.StockData) {
+		if stockData != nil && !seen[stockData.Ticker] {
+			seen[stockData.Ticker] = true
+			universe = append(universe, stockData)
+		}
+	}
+
+	for _, v := range valuations {
+		add(valuationData(v))
+	}
+	a.dataMutex.Lock()
+	for _, stockData := range a.stockData {
+		add(stockData)
+	}
+	a.dataMutex.Unlock()
+
+	if a.cache != nil {
+		tickers, err := a.cache.Tickers()
+		if err != nil {
+			a.logger.Printf("Warning: peers limited to the valued stocks: %v\n", err)
+		}
+		for _, ticker := range tickers {
+			if seen[ticker] {
+				continue
+			}
+			if stockData, ok := a.cache.Get(ticker); ok {
+				add(stockData)
+			}
+		}
+	}
+	return universe
+}
+
+// revalue values v again on stockData, keeping its sanity check violations.
+// The data remembered for price refreshes is replaced, so they keep
+// valuing on the same peers.
+func (a *Analyzer) revalue(v Valuation, stockData *models.StockData) Valuation {
+	result := a.calculator.CalculateFairValue(stockData)
+	if v.Result != nil {
+		result.Violations = v.Result.Violations
+	}
+	result.Relative = a.Benchmark().Relate(result)
+
+	a.dataMutex.Lock()
+	if remembered, ok := a.stockData[stockData.Ticker]; ok && remembered == v.StockData {
+		a.stockData[stockData.Ticker] = stockData
+	}
+	a.dataMutex.Unlock()
+
+	return Valuation{
+		Ticker:    v.Ticker,
+		StockData: stockData,
+		Result:    result,
+		Breakdown: a.calculator.Explain(stockData),
+	}
+}
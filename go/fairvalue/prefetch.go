@@ -0,0 +1,64 @@
+package fairvalue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lesnerd/fair-stock-value/go/services"
+	"golang.org/x/sync/errgroup"
+)
+
+// Cached reports whether the cache holds data of ticker that has not
+// expired
+func (a *Analyzer) Cached(ticker string) bool {
+	if a.cache == nil {
+		return false
+	}
+	_, ok := a.cache.Get(a.Canonical(ticker))
+	return ok
+}
+
+// FetchEach fetches the data of the given tickers concurrently into the
+// cache without valuing them, so later runs can value them on it, calling
+// fn with each ticker's index and the error fetching it, if any, as soon
+// as it completes. Tickers that time out fail with services.ErrTimeout, as
+// their partial data is not cached. Calls to fn are serialized, and once
+// ctx is cancelled, tickers not yet started are reported with its error.
+func (a *Analyzer) FetchEach(ctx context.Context, tickers []string, fn func(int, error)) {
+	var mu sync.Mutex
+	report := func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fn(i, err)
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for i, ticker := range tickers {
+		if err := a.workers.acquire(groupCtx); err != nil {
+			report(i, err)
+			continue
+		}
+		group.Go(func() error {
+			defer a.workers.release()
+			report(i, a.fetch(groupCtx, ticker))
+			return nil
+		})
+	}
+	group.Wait()
+}
+
+// fetch fetches the data of ticker into the cache
+func (a *Analyzer) fetch(ctx context.Context, ticker string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	stockData, err := a.FetchStockData(ctx, ticker)
+	if err != nil {
+		return err
+	}
+	if stockData.Incomplete {
+		return fmt.Errorf("%s: %w, so its partial data was not cached", ticker, services.ErrTimeout)
+	}
+	return nil
+}
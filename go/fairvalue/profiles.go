@@ -0,0 +1,35 @@
+package fairvalue
+
+import (
+	"github.com/lesnerd/fair-stock-value/go/config"
+)
+
+// ValueUnder values the stock data of valuations again with the model
+// parameters of cfg, such as a profile of the analyzer's configuration,
+// without fetching anything. Valuations keep their sanity check
+// violations and peer groups; failed ones, and price-only instruments,
+// are returned as they are.
+func (a *Analyzer) ValueUnder(cfg *config.Config, valuations []Valuation) []Valuation {
+	calculator := newCalculator(cfg)
+	benchmark := a.Benchmark()
+
+	revalued := make([]Valuation, len(valuations))
+	for i, v := range valuations {
+		if v.Err != nil || v.StockData == nil || (v.Result != nil && v.Result.Failed()) {
+			revalued[i] = v
+			continue
+		}
+		result := calculator.CalculateFairValue(v.StockData)
+		if v.Result != nil {
+			result.Violations = v.Result.Violations
+		}
+		result.Relative = benchmark.Relate(result)
+		revalued[i] = Valuation{
+			Ticker:    v.Ticker,
+			StockData: v.StockData,
+			Result:    result,
+			Breakdown: calculator.Explain(v.StockData),
+		}
+	}
+	return revalued
+}
@@ -0,0 +1,112 @@
+package fairvalue
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/services"
+)
+
+// RefreshPrices re-values every previously fetched stock using fresh prices
+// and the fundamentals fetched earlier. Stocks whose price cannot be
+// refreshed keep their previous price. If ctx is cancelled, the remaining
+// stocks keep their previous price and the run is marked partial.
+func (a *Analyzer) RefreshPrices(ctx context.Context) *models.Run {
+	startedAt := a.Now()
+
+	a.dataMutex.Lock()
+	previous := make([]*models.StockData, 0, len(a.stockData))
+	for _, stockData := range a.stockData {
+		previous = append(previous, stockData)
+	}
+	a.dataMutex.Unlock()
+
+	sort.Slice(previous, func(i, j int) bool {
+		return previous[i].Ticker < previous[j].Ticker
+	})
+
+	results := make([]*models.ValuationResult, len(previous))
+	var wg sync.WaitGroup
+	for i, stockData := range previous {
+		wg.Add(1)
+		go func(i int, stockData *models.StockData) {
+			defer wg.Done()
+
+			// The checks apply to the data as fetched, before the new price
+			var violations []models.Violation
+			if !stockData.PriceOnly() {
+				var err error
+				if violations, err = a.checkInputs(stockData); err != nil {
+					results[i] = models.NewFailedResult(stockData.Ticker, err)
+					return
+				}
+			}
+
+			updated := stockData.Snapshot()
+			if err := a.workers.acquire(ctx); err != nil {
+				results[i] = a.revaluePrice(updated, violations, a.Benchmark())
+				return
+			}
+			defer a.workers.release()
+
+			tickerCtx := services.WithTicker(ctx, stockData.Ticker)
+			if price, err := a.provider.FetchPrice(tickerCtx, stockData.Ticker); err == nil {
+				updated.CurrentPrice = price
+				updated.Stamp(a.Now(), "current_price")
+				a.adjustForSplits(tickerCtx, updated)
+				a.rememberStockData(updated)
+			} else {
+				a.logger.Printf("Warning: keeping previous price for %s: %v\n", stockData.Ticker, err)
+			}
+			results[i] = a.revaluePrice(updated, violations, a.currentBenchmark(ctx))
+		}(i, stockData)
+	}
+	wg.Wait()
+
+	run := &models.Run{
+		ID:         a.NewRunID(startedAt),
+		StartedAt:  startedAt,
+		FinishedAt: a.Now(),
+		PricesOnly: true,
+		Partial:    ctx.Err() != nil,
+		Results:    results,
+	}
+	a.Stamp(run)
+	return run
+}
+
+// revaluePrice values stockData with a refreshed price, listing violations
+// with the result and relating it to benchmark. Price-only instruments get
+// their price-only result.
+func (a *Analyzer) revaluePrice(stockData *models.StockData, violations []models.Violation, benchmark *models.Benchmark) *models.ValuationResult {
+	if stockData.PriceOnly() {
+		return models.NewNotValuableResult(stockData)
+	}
+	result := a.calculator.CalculateFairValue(a.assume(stockData))
+	result.Violations = violations
+	result.Relative = benchmark.Relate(result)
+	return result
+}
+
+// adjustForSplits divides the per-share figures of stockData by the splits
+// since it was fetched, so a fresh post-split price is not valued against
+// pre-split earnings and cash flow. Providers that do not know splits leave
+// it as it is.
+func (a *Analyzer) adjustForSplits(ctx context.Context, stockData *models.StockData) {
+	source, ok := a.provider.(services.SplitSource)
+	if !ok {
+		return
+	}
+	splits, err := source.RecentSplits(ctx, stockData.Ticker, stockData.FetchTime)
+	if err != nil {
+		a.logger.Printf("Warning: could not check %s for splits: %v\n", stockData.Ticker, err)
+		return
+	}
+	for _, split := range splits {
+		if stockData.AdjustForSplit(split, a.Now()) {
+			a.logger.Printf("Adjusted %s for its %s split\n", stockData.Ticker, split)
+		}
+	}
+}
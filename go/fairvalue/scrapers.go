@@ -0,0 +1,126 @@
+package fairvalue
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/services"
+)
+
+// minScrapeMonitorPoll bounds how often MonitorScrapers wakes up when the
+// checks are not reused at all
+const minScrapeMonitorPoll = time.Hour
+
+// scrapeMonitor keeps the last scraper check
+type scrapeMonitor struct {
+	mu     sync.Mutex
+	checks []services.ScrapeCheck
+	loaded bool // the checks saved by earlier runs were read
+}
+
+// CheckScrapers returns the last scraper check, running the scrapers
+// against the configured ticker again once it is older than the configured
+// interval. Checks are kept in the cache directory, so runs within the
+// interval share one. Growth sources found degraded are left out of the
+// consensus until a later check finds values on their pages again. There
+// are no checks when they are disabled, a StockDataProvider replaces the
+// configured sources or the analyzer is cache-only.
+func (a *Analyzer) CheckScrapers(ctx context.Context) []services.ScrapeCheck {
+	settings := a.config.DataSources.ScrapeCheck
+	if !settings.Enabled || a.provider != services.StockDataProvider(a.dataFetcher) || a.config.Processing.CacheOnly {
+		return nil
+	}
+
+	a.scrapers.mu.Lock()
+	defer a.scrapers.mu.Unlock()
+
+	path, pathErr := a.config.Processing.ScrapeCheckPath()
+	if !a.scrapers.loaded && pathErr == nil {
+		checks, err := services.LoadScrapeChecks(path)
+		if err != nil {
+			a.logger.Printf("Warning: %v\n", err)
+		}
+		a.scrapers.checks = checks
+		a.scrapers.loaded = true
+	}
+
+	// Checks made before the selectors were last edited say nothing about
+	// the scrapers as they are now
+	checks := a.scrapers.checks
+	if file := a.config.DataSources.SelectorsFile; file != "" {
+		if info, err := os.Stat(file); err == nil {
+			checks = checksSince(checks, info.ModTime())
+		}
+	}
+
+	ticker := a.Canonical(settings.Ticker)
+	if !scrapeChecksCurrent(checks, ticker, a.Now(), settings.Interval()) {
+		a.logger.Printf("Checking scrapers against %s...\n", ticker)
+		checks := a.dataFetcher.CheckScrapers(ctx, ticker)
+		// Checks cut short by the caller say nothing about the scrapers
+		if ctx.Err() != nil {
+			return a.scrapers.checks
+		}
+		a.scrapers.checks = checks
+		if pathErr == nil {
+			if err := services.SaveScrapeChecks(path, checks); err != nil {
+				a.logger.Printf("Warning: %v\n", err)
+			}
+		}
+		for _, source := range services.DegradedScrapers(checks) {
+			a.logger.Printf("Warning: %s found nothing on the page of %s; its layout may have changed\n", source, ticker)
+		}
+	}
+
+	a.dataFetcher.SetDegradedScrapers(services.DegradedScrapers(a.scrapers.checks))
+	return a.scrapers.checks
+}
+
+// DegradedScrapers returns the scrapers the last check found degraded,
+// without checking them
+func (a *Analyzer) DegradedScrapers() []string {
+	a.scrapers.mu.Lock()
+	defer a.scrapers.mu.Unlock()
+	return services.DegradedScrapers(a.scrapers.checks)
+}
+
+// MonitorScrapers checks the scrapers now and again whenever the last check
+// is older than the configured interval, for long-running modes, until ctx
+// is cancelled
+func (a *Analyzer) MonitorScrapers(ctx context.Context) error {
+	poll := max(a.config.DataSources.ScrapeCheck.Interval(), minScrapeMonitorPoll)
+	for {
+		a.CheckScrapers(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(poll):
+		}
+	}
+}
+
+// scrapeChecksCurrent reports whether checks were made against ticker
+// within interval of now
+func scrapeChecksCurrent(checks []services.ScrapeCheck, ticker string, now time.Time, interval time.Duration) bool {
+	if len(checks) == 0 {
+		return false
+	}
+	for _, check := range checks {
+		if check.Ticker != ticker || now.Sub(check.CheckedAt) >= interval {
+			return false
+		}
+	}
+	return true
+}
+
+// checksSince returns checks, or none unless all were made after since
+func checksSince(checks []services.ScrapeCheck, since time.Time) []services.ScrapeCheck {
+	for _, check := range checks {
+		if check.CheckedAt.Before(since) {
+			return nil
+		}
+	}
+	return checks
+}
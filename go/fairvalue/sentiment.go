@@ -0,0 +1,27 @@
+package fairvalue
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/services"
+)
+
+// scoreSentiment sets the news sentiment of freshly fetched stockData, so
+// it is cached along with the fundamentals. Stocks without recent news, or
+// whose sentiment cannot be fetched, are valued without it.
+func (a *Analyzer) scoreSentiment(ctx context.Context, stockData *models.StockData) {
+	if a.sentiment == nil {
+		return
+	}
+	sentiment, err := a.sentiment.FetchSentiment(ctx, stockData.Ticker)
+	switch {
+	case errors.Is(err, services.ErrSymbolNotFound):
+		return
+	case err != nil:
+		a.logger.Printf("Warning: valuing %s without news sentiment: %v\n", stockData.Ticker, err)
+		return
+	}
+	stockData.Sentiment = sentiment
+}
@@ -0,0 +1,197 @@
+package fairvalue
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/services"
+)
+
+// workerLimiter bounds concurrent fetches. Unlike a buffered channel its
+// limit can change while workers hold slots.
+type workerLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	active int
+	wake   chan struct{} // closed whenever a slot may have become free
+}
+
+// newWorkerLimiter creates a limiter allowing limit concurrent workers
+func newWorkerLimiter(limit int) *workerLimiter {
+	return &workerLimiter{limit: limit, wake: make(chan struct{})}
+}
+
+// acquire waits for a free slot or for ctx to be cancelled
+func (l *workerLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.active < l.limit {
+			l.active++
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release frees a slot taken by acquire
+func (l *workerLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.broadcast()
+}
+
+// setLimit changes the number of concurrent workers. Workers above a
+// lowered limit finish their current fetch before it takes effect.
+func (l *workerLimiter) setLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.broadcast()
+}
+
+// current returns the current limit
+func (l *workerLimiter) current() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// broadcast wakes every waiting acquire; l.mu must be held
+func (l *workerLimiter) broadcast() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// Tuning parameters of adaptive workers
+const (
+	// throttleCooldown is the minimum time between reductions, so a burst
+	// of rate limited responses from in-flight requests counts once
+	throttleCooldown = 10 * time.Second
+	// growCooldown is the time after a reduction before scaling up again
+	growCooldown = 30 * time.Second
+	// fastLatency and slowLatency bound the smoothed latency of a source
+	// for scaling up and down
+	fastLatency = 1500 * time.Millisecond
+	slowLatency = 6 * time.Second
+	// latencySmoothing is the weight of the newest sample in the average
+	latencySmoothing = 0.2
+)
+
+// workerTuner adapts the limit of a workerLimiter to how data sources
+// respond: it halves the limit when a source starts rate limiting, lowers
+// it by one while a source is slow, and raises it by one after a full
+// round of quick answers from every source.
+type workerTuner struct {
+	limiter  *workerLimiter
+	min, max int
+	logger   services.Logger
+
+	mu         sync.Mutex
+	latencies  map[string]time.Duration // smoothed latency of answered requests by source
+	fastStreak int
+	lastDown   time.Time
+	now        func() time.Time
+}
+
+// newWorkerTuner creates a tuner adjusting limiter between min and max
+func newWorkerTuner(limiter *workerLimiter, min, max int, logger services.Logger) *workerTuner {
+	return &workerTuner{
+		limiter:   limiter,
+		min:       min,
+		max:       max,
+		logger:    logger,
+		latencies: make(map[string]time.Duration),
+		now:       time.Now,
+	}
+}
+
+// ObserveRequest records the outcome of a request and adjusts the limit
+func (t *workerTuner) ObserveRequest(source string, status int, latency time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case throttled(status):
+		t.fastStreak = 0
+		t.reduce(t.limiter.current()/2, "%s answered HTTP %d", source, status)
+		return
+	case err != nil:
+		return
+	}
+
+	smoothed, ok := t.latencies[source]
+	if !ok {
+		smoothed = latency
+	} else {
+		smoothed = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(smoothed))
+	}
+	t.latencies[source] = smoothed
+
+	if smoothed > slowLatency {
+		t.fastStreak = 0
+		t.reduce(t.limiter.current()-1, "%s is slow (%s)", source, smoothed.Round(time.Millisecond))
+		return
+	}
+	if !t.allFast() {
+		t.fastStreak = 0
+		return
+	}
+
+	// Scale up once every worker has seen a quick answer since the last change
+	t.fastStreak++
+	limit := t.limiter.current()
+	if t.fastStreak < limit || limit >= t.max || t.now().Sub(t.lastDown) < growCooldown {
+		return
+	}
+	t.fastStreak = 0
+	t.limiter.setLimit(limit + 1)
+	t.logger.Printf("Sources responding quickly, increasing workers to %d\n", limit+1)
+}
+
+// reduce lowers the limit to limit, bounded by the minimum, unless it was
+// reduced within the cooldown; t.mu must be held
+func (t *workerTuner) reduce(limit int, reason string, args ...interface{}) {
+	now := t.now()
+	if now.Sub(t.lastDown) < throttleCooldown {
+		return
+	}
+	if limit < t.min {
+		limit = t.min
+	}
+	current := t.limiter.current()
+	if limit >= current {
+		return
+	}
+	t.lastDown = now
+	t.limiter.setLimit(limit)
+	args = append(args, limit)
+	t.logger.Printf("Throttling: "+reason+", reducing workers to %d\n", args...)
+}
+
+// allFast reports whether every source answers within fastLatency; t.mu
+// must be held
+func (t *workerTuner) allFast() bool {
+	for _, latency := range t.latencies {
+		if latency > fastLatency {
+			return false
+		}
+	}
+	return true
+}
+
+// throttled reports whether a source answering with status is rate
+// limiting
+func throttled(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusForbidden
+}
@@ -0,0 +1,302 @@
+// Package finparse parses the numbers scraped from financial web pages:
+// prices such as "$1,234.56" or "1.234,56 €", amounts such as "(1.2B)" or
+// "−350.5M", and percentages such as "12.5%".
+//
+// Unlike strconv, it understands the formatting those pages use, and
+// unlike ad-hoc string cleaning it never guesses: input it cannot read
+// unambiguously fails with an *Error wrapping one of ErrEmpty, ErrSyntax
+// or ErrUnit, so callers can tell a missing value from a broken one.
+package finparse
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrEmpty means the input holds no value, such as "", "N/A" or "--"
+	ErrEmpty = errors.New("no value")
+	// ErrSyntax means the input is not a number
+	ErrSyntax = errors.New("invalid number")
+	// ErrUnit means the input has a unit the caller did not ask for, such
+	// as a percent sign on a price or a suffix on a plain number
+	ErrUnit = errors.New("unexpected unit")
+	// ErrRange means the input is a number, but not a plausible one for
+	// what is being parsed. The parsers never return it; callers checking
+	// bounds wrap it.
+	ErrRange = errors.New("value out of range")
+)
+
+// Error is a parse failure of Input
+type Error struct {
+	Input string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("parse %q: %v", e.Input, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// emptyValues are the placeholders pages show for missing values
+var emptyValues = map[string]bool{
+	"":     true,
+	"-":    true,
+	"--":   true,
+	"—":    true,
+	"–":    true,
+	"n/a":  true,
+	"na":   true,
+	"nm":   true,
+	"null": true,
+	"none": true,
+	"nan":  true,
+}
+
+// multipliers are the amount suffixes, by upper-case letter
+var multipliers = map[byte]float64{
+	'K': 1e3,
+	'M': 1e6,
+	'B': 1e9,
+	'T': 1e12,
+}
+
+// number is a value split from its formatting
+type number struct {
+	value   float64
+	percent bool
+	suffix  byte
+}
+
+// Number parses a plain number such as a price, ratio or per-share value.
+// A percent sign or amount suffix fails with ErrUnit.
+func Number(s string) (float64, error) {
+	n, err := parse(s)
+	if err != nil {
+		return 0, err
+	}
+	if n.percent || n.suffix != 0 {
+		return 0, &Error{Input: s, Err: ErrUnit}
+	}
+	return n.value, nil
+}
+
+// Amount parses an amount with an optional K, M, B or T suffix, such as
+// "2.5T", "(350.5M)" or "-1.2b", into units. A percent sign fails with
+// ErrUnit.
+func Amount(s string) (float64, error) {
+	n, err := parse(s)
+	if err != nil {
+		return 0, err
+	}
+	if n.percent {
+		return 0, &Error{Input: s, Err: ErrUnit}
+	}
+	if n.suffix != 0 {
+		return n.value * multipliers[n.suffix], nil
+	}
+	return n.value, nil
+}
+
+// Percent parses a value known to be in percent, with or without a
+// percent sign, into a fraction: both "12.5%" and "12.5" are 0.125. An
+// amount suffix fails with ErrUnit.
+func Percent(s string) (float64, error) {
+	n, err := parse(s)
+	if err != nil {
+		return 0, err
+	}
+	if n.suffix != 0 {
+		return 0, &Error{Input: s, Err: ErrUnit}
+	}
+	return n.value / 100, nil
+}
+
+// percentPattern matches a number followed by a percent sign in free text
+var percentPattern = regexp.MustCompile(`[(+\-\x{2212}]?[$€£¥]?\d[\d.,\x{00A0}\x{202F}]*\s?%\)?`)
+
+// FindPercent returns the first percentage in free text, such as "EPS
+// growth next year: 12.5%", as a fraction. Numbers without a percent sign
+// are never taken for one; text without a percentage fails with ErrEmpty.
+func FindPercent(text string) (float64, error) {
+	match := percentPattern.FindString(text)
+	if match == "" {
+		return 0, &Error{Input: text, Err: ErrEmpty}
+	}
+	if strings.HasPrefix(match, "(") != strings.HasSuffix(match, ")") {
+		match = strings.Trim(match, "()")
+	}
+	return Percent(match)
+}
+
+// parse splits s into its value, percent sign and suffix
+func parse(s string) (number, error) {
+	fail := func(err error) (number, error) {
+		return number{}, &Error{Input: s, Err: err}
+	}
+
+	cleaned := strings.TrimFunc(s, unicode.IsSpace)
+	if emptyValues[strings.ToLower(cleaned)] {
+		return fail(ErrEmpty)
+	}
+
+	var n number
+	negative := false
+	cleaned = strings.TrimFunc(cleaned, isCurrencyOrSpace)
+
+	// Accounting negatives: "(1,234)"
+	if strings.HasPrefix(cleaned, "(") && strings.HasSuffix(cleaned, ")") {
+		negative = true
+		cleaned = strings.TrimFunc(cleaned[1:len(cleaned)-1], unicode.IsSpace)
+	}
+
+	// Leading sign, including the unicode minus and dashes pages use for it
+	for _, sign := range []string{"-", "−", "–", "+"} {
+		if strings.HasPrefix(cleaned, sign) {
+			if negative {
+				return fail(ErrSyntax)
+			}
+			negative = sign != "+"
+			cleaned = cleaned[len(sign):]
+			break
+		}
+	}
+
+	cleaned = strings.TrimFunc(cleaned, isCurrencyOrSpace)
+	if strings.HasSuffix(cleaned, "%") {
+		n.percent = true
+		cleaned = strings.TrimFunc(strings.TrimSuffix(cleaned, "%"), isCurrencyOrSpace)
+	}
+	if cleaned != "" {
+		last := cleaned[len(cleaned)-1] &^ 0x20 // upper case
+		if _, ok := multipliers[last]; ok {
+			n.suffix = last
+			cleaned = strings.TrimFunc(cleaned[:len(cleaned)-1], isCurrencyOrSpace)
+		}
+	}
+
+	digits, ok := normalizeSeparators(cleaned)
+	if !ok {
+		return fail(ErrSyntax)
+	}
+	value, err := strconv.ParseFloat(digits, 64)
+	if err != nil || math.IsInf(value, 0) {
+		return fail(ErrSyntax)
+	}
+	if negative {
+		value = -value
+	}
+	n.value = value
+	return n, nil
+}
+
+// isCurrencyOrSpace reports whether r is a currency symbol or space
+// surrounding a number
+func isCurrencyOrSpace(r rune) bool {
+	return unicode.Is(unicode.Sc, r) || unicode.IsSpace(r)
+}
+
+// normalizeSeparators rewrites the digits of s with a "." decimal point and
+// without thousands separators. When s has both "," and ".", the last one
+// is the decimal point. A lone "," is a decimal comma unless exactly three
+// digits follow it and the integer part does not start with 0, as in
+// "1,234"; several of one separator, as in "1.234.567", group thousands.
+func normalizeSeparators(s string) (string, bool) {
+	s = strings.NewReplacer("\u00a0", " ", "\u202f", " ", "\u2009", " ", "'", " ").Replace(s)
+	if s == "" {
+		return "", false
+	}
+	for _, r := range s {
+		if !(r >= '0' && r <= '9') && r != '.' && r != ',' && r != ' ' {
+			return "", false
+		}
+	}
+
+	decimal := byte(0)
+	commas, dots := strings.Count(s, ","), strings.Count(s, ".")
+	switch {
+	case commas > 0 && dots > 0:
+		if strings.LastIndex(s, ",") > strings.LastIndex(s, ".") {
+			decimal = ','
+		} else {
+			decimal = '.'
+		}
+	case commas == 1:
+		i := strings.Index(s, ",")
+		if len(s)-i-1 != 3 || strings.HasPrefix(s, "0") {
+			decimal = ','
+		}
+	case dots == 1:
+		decimal = '.'
+	}
+
+	integer, fraction := s, ""
+	if decimal != 0 {
+		i := strings.LastIndexByte(s, decimal)
+		integer, fraction = s[:i], s[i+1:]
+		if strings.ContainsAny(fraction, "., ") {
+			return "", false
+		}
+	}
+	if !validGrouping(integer) {
+		return "", false
+	}
+
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, integer)
+	if digits == "" {
+		digits = "0"
+		if fraction == "" {
+			return "", false
+		}
+	}
+	if fraction != "" {
+		digits += "." + fraction
+	}
+	return digits, true
+}
+
+// validGrouping reports whether the integer part of a number is either
+// plain digits or groups of three digits after the first, split by one
+// kind of separator
+func validGrouping(integer string) bool {
+	separator := rune(0)
+	groups := strings.FieldsFunc(integer, func(r rune) bool {
+		if r >= '0' && r <= '9' {
+			return false
+		}
+		if separator == 0 {
+			separator = r
+		}
+		return true
+	})
+	if separator == 0 {
+		return true
+	}
+	if strings.ContainsFunc(integer, func(r rune) bool {
+		return (r < '0' || r > '9') && r != separator
+	}) {
+		return false
+	}
+	if len(groups) != strings.Count(integer, string(separator))+1 {
+		return false // leading, trailing or doubled separator
+	}
+	for i, group := range groups {
+		if (i == 0 && len(group) > 3) || (i > 0 && len(group) != 3) {
+			return false
+		}
+	}
+	return true
+}
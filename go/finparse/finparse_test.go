@@ -0,0 +1,163 @@
+package finparse
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// parseCase is an input and the value it parses to, or the error it
+// fails with when err is set
+type parseCase struct {
+	input string
+	want  float64
+	err   error
+}
+
+// checkParse runs parse on every case
+func checkParse(t *testing.T, name string, parse func(string) (float64, error), cases []parseCase) {
+	t.Helper()
+	for _, tc := range cases {
+		got, err := parse(tc.input)
+		if tc.err != nil {
+			var parseErr *Error
+			if !errors.Is(err, tc.err) || !errors.As(err, &parseErr) || parseErr.Input != tc.input {
+				t.Errorf("%s(%q) = %v, %v; want an *Error of the input wrapping %v", name, tc.input, got, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || math.Abs(got-tc.want) > 1e-9*math.Max(1, math.Abs(tc.want)) {
+			t.Errorf("%s(%q) = %v, %v; want %v", name, tc.input, got, err, tc.want)
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	checkParse(t, "Number", Number, []parseCase{
+		{input: "185.50", want: 185.5},
+		{input: " 42 ", want: 42},
+		{input: "$1,234.56", want: 1234.56},
+		{input: "1,234", want: 1234},
+		{input: "1,234,567.89", want: 1234567.89},
+		{input: "1.234.567", want: 1234567},
+		{input: "0,5", want: 0.5},
+		{input: "12,5", want: 12.5},
+		{input: "1.234,56 €", want: 1234.56},
+		{input: "1 234,56", want: 1234.56},
+		{input: "1\u00a0234,56", want: 1234.56},
+		{input: "1'234.56", want: 1234.56},
+		{input: ".5", want: 0.5},
+		{input: "(12.34)", want: -12.34},
+		{input: "($1,234)", want: -1234},
+		{input: "-7.5", want: -7.5},
+		{input: "\u22127.5", want: -7.5}, // Unicode minus
+		{input: "\u20137.5", want: -7.5}, // en dash
+		{input: "-$3.10", want: -3.1},
+		{input: "+2", want: 2},
+		{input: "", err: ErrEmpty},
+		{input: "  ", err: ErrEmpty},
+		{input: "N/A", err: ErrEmpty},
+		{input: "--", err: ErrEmpty},
+		{input: "\u2014", err: ErrEmpty},
+		{input: "NM", err: ErrEmpty},
+		{input: "abc", err: ErrSyntax},
+		{input: "1.2.3,4", err: ErrSyntax},
+		{input: "12,34,5", err: ErrSyntax},
+		{input: "(-5)", err: ErrSyntax},
+		{input: "1e400", err: ErrSyntax},
+		{input: "$", err: ErrSyntax},
+		{input: "12.5%", err: ErrUnit},
+		{input: "2.5B", err: ErrUnit},
+	})
+}
+
+func TestAmount(t *testing.T) {
+	checkParse(t, "Amount", Amount, []parseCase{
+		{input: "1,234,567", want: 1234567},
+		{input: "12.3K", want: 12300},
+		{input: "350.5M", want: 350.5e6},
+		{input: "2.5T", want: 2.5e12},
+		{input: "-1.2b", want: -1.2e9},
+		{input: "(350.5M)", want: -350.5e6},
+		{input: "\u22121.5B", want: -1.5e9},
+		{input: "$3.2 B", want: 3.2e9},
+		{input: "1,5 M", want: 1.5e6},
+		{input: "--", err: ErrEmpty},
+		{input: "1.2X", err: ErrSyntax},
+		{input: "BB", err: ErrSyntax},
+		{input: "4%", err: ErrUnit},
+	})
+}
+
+func TestPercent(t *testing.T) {
+	checkParse(t, "Percent", Percent, []parseCase{
+		{input: "12.5%", want: 0.125},
+		{input: "12.5", want: 0.125}, // known to be in percent
+		{input: "0.75 %", want: 0.0075},
+		{input: "+0.75%", want: 0.0075},
+		{input: "(3.2%)", want: -0.032},
+		{input: "\u22121,05 %", want: -0.0105},
+		{input: "1,250%", want: 12.5},
+		{input: "n/a", err: ErrEmpty},
+		{input: "%", err: ErrSyntax},
+		{input: "5M%", err: ErrUnit},
+		{input: "5M", err: ErrUnit},
+	})
+}
+
+func TestFindPercent(t *testing.T) {
+	checkParse(t, "FindPercent", FindPercent, []parseCase{
+		{input: "EPS growth next year: 12.5%", want: 0.125},
+		{input: "Next 5Y (per annum) 8.20%", want: 0.082},
+		{input: "Growth (3.2%) vs sector 4%", want: -0.032},
+		{input: "Estimate: \u22121,5 % p.a.", want: -0.015},
+		{input: "Change (+2.1%", want: 0.021},
+		{input: "Revenue 12.5 billion, up 1,250 %", want: 12.5},
+		{input: "Price target 185.50, P/E 0.25", err: ErrEmpty},
+		{input: "", err: ErrEmpty},
+	})
+}
+
+// The formats scraped pages show numbers in
+var (
+	numberInputs  = []string{"185.50", "$1,234.56", "1.234,56 €", "(12.34)", "−7.5", "N/A"}
+	amountInputs  = []string{"2.5T", "(350.5M)", "-1.2b", "1,234,567", "12.3K", "--"}
+	percentInputs = []string{"12.5%", "(3.2%)", "+0.75%", "−1,05 %", "12.5", "n/a"}
+)
+
+func BenchmarkParseNumber(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, s := range numberInputs {
+			Number(s)
+		}
+	}
+}
+
+func BenchmarkParseAmount(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, s := range amountInputs {
+			Amount(s)
+		}
+	}
+}
+
+func BenchmarkParsePercent(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, s := range percentInputs {
+			Percent(s)
+		}
+	}
+}
+
+func BenchmarkParseFindPercent(b *testing.B) {
+	text := "Next Year (2026) EPS growth estimate: Current Qtr. 4.20% Next Qtr. 6.10% Current Year 12.5%"
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := FindPercent(text); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
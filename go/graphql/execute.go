@@ -0,0 +1,534 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Request is a GraphQL request as clients send it
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the outcome of a request: the data selected by the query,
+// null where resolving failed, and the errors
+type Response struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error is an error of a request, with the response path of the field it
+// occurred in when it occurred resolving one
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// Error returns the message of e
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Execute runs the query of req against the schema. Errors in the
+// request itself, such as syntax errors and unknown fields, fail it
+// without data; errors resolving a field make its value null.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return failed(fmt.Errorf("syntax error: %w", err))
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return failed(err)
+	}
+	if op.kind != "query" {
+		return failed(fmt.Errorf("%s operations are not supported", op.kind))
+	}
+
+	e := &executor{doc: doc}
+	if e.vars, err = coerceVariables(op.variables, req.Variables); err != nil {
+		return failed(err)
+	}
+	declared := make(map[string]bool, len(op.variables))
+	for _, def := range op.variables {
+		declared[def.name] = true
+	}
+	if err := e.validate(s.Query, op.selections, declared, nil); err != nil {
+		return failed(err)
+	}
+
+	data, _ := e.selectionSet(ctx, s.Query, nil, op.selections, nil)
+	resp := &Response{Errors: e.errors}
+	if data != nil {
+		resp.Data = data
+	}
+	return resp
+}
+
+// failed returns the response to a request failing before execution
+func failed(err error) *Response {
+	return &Response{Errors: []*Error{{Message: err.Error()}}}
+}
+
+// operation returns the operation of doc to execute
+func (doc *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required for a document with several operations")
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// executor executes one operation
+type executor struct {
+	doc    *document
+	vars   map[string]any
+	errors []*Error
+}
+
+// coerceVariables coerces the JSON values of the variables of an
+// operation to their declared types, applying defaults
+func coerceVariables(defs []variableDefinition, values map[string]any) (map[string]any, error) {
+	vars := make(map[string]any, len(defs))
+	for _, def := range defs {
+		t, err := inputType(def.typ)
+		if err != nil {
+			return nil, fmt.Errorf("variable $%s: %w", def.name, err)
+		}
+		value, given := values[def.name]
+		if !given {
+			if def.hasDefault {
+				value, given = def.defaultVal, true
+			} else if def.typ.nonNull {
+				return nil, fmt.Errorf("variable $%s of type %s is required", def.name, def.typ)
+			}
+		}
+		if !given {
+			continue
+		}
+		if vars[def.name], err = coerceInput(t, value, nil); err != nil {
+			return nil, fmt.Errorf("variable $%s: %w", def.name, err)
+		}
+	}
+	return vars, nil
+}
+
+// inputType returns the type a variable definition names
+func inputType(ref typeRef) (Type, error) {
+	var t Type
+	if ref.list != nil {
+		elem, err := inputType(*ref.list)
+		if err != nil {
+			return nil, err
+		}
+		t = List{Of: elem}
+	} else {
+		scalar, ok := scalars[ref.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown input type %q", ref.name)
+		}
+		t = scalar
+	}
+	if ref.nonNull {
+		t = NonNull{Of: t}
+	}
+	return t, nil
+}
+
+// coerceInput coerces a value of a document or of JSON variables to t,
+// substituting vars for variables. Variables without a value are nil.
+func coerceInput(t Type, value any, vars map[string]any) (any, error) {
+	if name, ok := value.(variable); ok {
+		value = vars[string(name)]
+		if nonNull, ok := t.(NonNull); ok && value == nil {
+			return nil, fmt.Errorf("expected %s, got null from $%s", nonNull, name)
+		}
+		return value, nil
+	}
+
+	switch t := t.(type) {
+	case NonNull:
+		if value == nil {
+			return nil, fmt.Errorf("expected %s, got null", t)
+		}
+		return coerceInput(t.Of, value, vars)
+	case List:
+		if value == nil {
+			return nil, nil
+		}
+		items, ok := value.([]any)
+		if !ok {
+			items = []any{value}
+		}
+		list := make([]any, len(items))
+		for i, item := range items {
+			var err error
+			if list[i], err = coerceInput(t.Of, item, vars); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case *Scalar:
+		if value == nil {
+			return nil, nil
+		}
+		coerced, ok := t.coerce(value)
+		if !ok {
+			return nil, fmt.Errorf("expected %s, got %s", t, formatValue(value))
+		}
+		return coerced, nil
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// validate checks that selections select fields of o with known
+// arguments and declared variables, and fields of objects only with
+// selections of their own
+func (e *executor) validate(o *Object, selections []*selection, declared map[string]bool, spreading []string) error {
+	for _, sel := range selections {
+		if err := validateDirectives(sel.directives, declared); err != nil {
+			return err
+		}
+
+		if sel.fragment {
+			nested, on := sel.selections, sel.on
+			if sel.spread != "" {
+				frag, ok := e.doc.fragments[sel.spread]
+				if !ok {
+					return fmt.Errorf("unknown fragment %q", sel.spread)
+				}
+				if slices.Contains(spreading, sel.spread) {
+					return fmt.Errorf("fragment %q spreads itself", sel.spread)
+				}
+				nested, on = frag.selections, frag.on
+				spreading = append(spreading, sel.spread)
+			}
+			if on != "" && on != o.Name {
+				return fmt.Errorf("fragment on %s cannot be spread in %s", on, o.Name)
+			}
+			if err := e.validate(o, nested, declared, spreading); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if sel.name == "__typename" {
+			if sel.selections != nil {
+				return fmt.Errorf("field __typename of %s has no fields", o.Name)
+			}
+			continue
+		}
+		field, ok := o.Fields[sel.name]
+		if !ok {
+			return fmt.Errorf("type %s has no field %q", o.Name, sel.name)
+		}
+		if err := validateArguments(field, sel, o, declared); err != nil {
+			return err
+		}
+
+		object, isObject := namedType(field.Type).(*Object)
+		switch {
+		case isObject && sel.selections == nil:
+			return fmt.Errorf("field %q of %s needs a selection of the fields of %s", sel.name, o.Name, object.Name)
+		case !isObject && sel.selections != nil:
+			return fmt.Errorf("field %q of %s is a %s and has no fields", sel.name, o.Name, field.Type)
+		case isObject:
+			if err := e.validate(object, sel.selections, declared, spreading); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateArguments checks the arguments of a field selection
+func validateArguments(field *Field, sel *selection, o *Object, declared map[string]bool) error {
+	given := make(map[string]any, len(sel.args))
+	for _, arg := range sel.args {
+		known := slices.ContainsFunc(field.Args, func(a *Argument) bool { return a.Name == arg.name })
+		if !known {
+			return fmt.Errorf("field %q of %s has no argument %q", sel.name, o.Name, arg.name)
+		}
+		if err := validateVariables(arg.value, declared); err != nil {
+			return err
+		}
+		given[arg.name] = arg.value
+	}
+	for _, arg := range field.Args {
+		_, required := arg.Type.(NonNull)
+		if _, ok := given[arg.Name]; !ok && required && arg.Default == nil {
+			return fmt.Errorf("field %q of %s requires argument %q", sel.name, o.Name, arg.Name)
+		}
+	}
+	return nil
+}
+
+// validateDirectives checks that selections use only @skip and @include
+func validateDirectives(directives []directive, declared map[string]bool) error {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			return fmt.Errorf("unknown directive @%s", d.name)
+		}
+		if len(d.args) != 1 || d.args[0].name != "if" {
+			return fmt.Errorf("directive @%s takes one argument, if", d.name)
+		}
+		if err := validateVariables(d.args[0].value, declared); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateVariables checks that value uses only declared variables
+func validateVariables(value any, declared map[string]bool) error {
+	switch value := value.(type) {
+	case variable:
+		if !declared[string(value)] {
+			return fmt.Errorf("variable $%s is not declared", value)
+		}
+	case []any:
+		for _, item := range value {
+			if err := validateVariables(item, declared); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for _, item := range value {
+			if err := validateVariables(item, declared); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldGroup is the selections of one response key, merged
+type fieldGroup struct {
+	key        string
+	selections []*selection
+}
+
+// collectFields returns the fields selected by selections, spreading
+// fragments and leaving out those skipped by directives
+func (e *executor) collectFields(selections []*selection, groups []*fieldGroup) []*fieldGroup {
+	for _, sel := range selections {
+		if !e.included(sel) {
+			continue
+		}
+		if sel.fragment {
+			nested := sel.selections
+			if sel.spread != "" {
+				nested = e.doc.fragments[sel.spread].selections
+			}
+			groups = e.collectFields(nested, groups)
+			continue
+		}
+
+		key := sel.responseKey()
+		i := slices.IndexFunc(groups, func(g *fieldGroup) bool { return g.key == key })
+		if i < 0 {
+			groups = append(groups, &fieldGroup{key: key})
+			i = len(groups) - 1
+		}
+		groups[i].selections = append(groups[i].selections, sel)
+	}
+	return groups
+}
+
+// included applies the @skip and @include directives of sel
+func (e *executor) included(sel *selection) bool {
+	for _, d := range sel.directives {
+		condition, _ := coerceInput(Boolean, d.args[0].value, e.vars)
+		if condition, _ := condition.(bool); condition == (d.name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// selectionSet resolves the fields selections select of source, a value
+// of o. It reports true when a non-null field resolved to null, which
+// makes the object itself null.
+func (e *executor) selectionSet(ctx context.Context, o *Object, source any, selections []*selection, path []any) (*orderedMap, bool) {
+	result := &orderedMap{}
+	for _, group := range e.collectFields(selections, nil) {
+		sel := group.selections[0]
+		fieldPath := append(slices.Clip(path), group.key)
+		if sel.name == "__typename" {
+			result.add(group.key, o.Name)
+			continue
+		}
+
+		field := o.Fields[sel.name]
+		value, err := e.resolve(ctx, field, sel, source)
+		if err != nil {
+			e.errors = append(e.errors, &Error{Message: err.Error(), Path: fieldPath})
+			if _, nonNull := field.Type.(NonNull); nonNull {
+				return nil, true
+			}
+			result.add(group.key, nil)
+			continue
+		}
+
+		var nested []*selection
+		for _, s := range group.selections {
+			nested = append(nested, s.selections...)
+		}
+		completed, nulled := e.complete(ctx, field.Type, nested, value, fieldPath)
+		if nulled {
+			if _, nonNull := field.Type.(NonNull); nonNull {
+				return nil, true
+			}
+			completed = nil
+		}
+		result.add(group.key, completed)
+	}
+	return result, false
+}
+
+// resolve resolves the value of a field selection of source
+func (e *executor) resolve(ctx context.Context, field *Field, sel *selection, source any) (any, error) {
+	args := make(map[string]any, len(field.Args))
+	for _, arg := range field.Args {
+		var value any = arg.Default
+		given := arg.Default != nil
+		for _, a := range sel.args {
+			if a.name == arg.Name {
+				value, given = a.value, true
+			}
+		}
+		if !given {
+			continue
+		}
+		coerced, err := coerceInput(arg.Type, value, e.vars)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", arg.Name, err)
+		}
+		if coerced != nil {
+			args[arg.Name] = coerced
+		}
+	}
+
+	if field.Resolve != nil {
+		return field.Resolve(ctx, source, args)
+	}
+	if m, ok := source.(map[string]any); ok {
+		return m[sel.name], nil
+	}
+	return nil, nil
+}
+
+// complete converts a resolved value of t to its response form. It
+// reports true when the value is null because a non-null value within it
+// resolved to null, so the nearest nullable value around it is null.
+func (e *executor) complete(ctx context.Context, t Type, selections []*selection, value any, path []any) (any, bool) {
+	if nonNull, ok := t.(NonNull); ok {
+		completed, nulled := e.complete(ctx, nonNull.Of, selections, value, path)
+		if !nulled && completed == nil {
+			e.errors = append(e.errors, &Error{Message: fmt.Sprintf("non-null %s resolved to null", t), Path: path})
+			nulled = true
+		}
+		return completed, nulled
+	}
+	if isNil(value) {
+		return nil, false
+	}
+
+	switch t := t.(type) {
+	case List:
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			e.errors = append(e.errors, &Error{Message: fmt.Sprintf("%s resolved to a %T", t, value), Path: path})
+			return nil, false
+		}
+		list := make([]any, items.Len())
+		for i := range list {
+			item, nulled := e.complete(ctx, t.Of, selections, items.Index(i).Interface(), append(slices.Clip(path), i))
+			if nulled {
+				if _, nonNull := t.Of.(NonNull); nonNull {
+					return nil, true
+				}
+			}
+			list[i] = item
+		}
+		return list, false
+	case *Object:
+		object, nulled := e.selectionSet(ctx, t, value, selections, path)
+		if nulled {
+			return nil, true
+		}
+		return object, false
+	case *Scalar:
+		serialized, ok := t.serialize(value)
+		if !ok {
+			e.errors = append(e.errors, &Error{Message: fmt.Sprintf("%s cannot represent %T", t, value), Path: path})
+			return nil, false
+		}
+		return serialized, false
+	}
+	return nil, false
+}
+
+// isNil reports whether value is nil or a nil pointer or map. A nil slice
+// is an empty list.
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// orderedMap is an object of a response, keeping its fields in the order
+// they were selected
+type orderedMap struct {
+	keys   []string
+	values []any
+}
+
+// add appends a field
+func (m *orderedMap) add(key string, value any) {
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+}
+
+// MarshalJSON writes the fields in the order they were selected
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
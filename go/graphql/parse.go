@@ -0,0 +1,677 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a parsed GraphQL request document
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation is a query of a document
+type operation struct {
+	kind       string // query, mutation or subscription
+	name       string
+	variables  []variableDefinition
+	selections []*selection
+}
+
+// variableDefinition declares a variable of an operation
+type variableDefinition struct {
+	name       string
+	typ        typeRef
+	defaultVal any
+	hasDefault bool
+}
+
+// typeRef is a type named in a variable definition
+type typeRef struct {
+	name    string   // named type, empty for lists
+	list    *typeRef // element type of lists
+	nonNull bool
+}
+
+// String formats t as GraphQL writes it
+func (t typeRef) String() string {
+	s := t.name
+	if t.list != nil {
+		s = "[" + t.list.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// fragment is a named fragment of a document
+type fragment struct {
+	name       string
+	on         string
+	selections []*selection
+}
+
+// selection is a field, fragment spread or inline fragment of a
+// selection set
+type selection struct {
+	// Fields
+	alias      string
+	name       string
+	args       []argument
+	selections []*selection
+
+	// Fragment spreads name the fragment; inline fragments have no name
+	// and may have a type condition
+	fragment bool
+	spread   string
+	on       string
+
+	directives []directive
+}
+
+// responseKey returns the key of a field in the response
+func (s *selection) responseKey() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+// argument is a named argument of a field or directive
+type argument struct {
+	name  string
+	value any
+}
+
+// directive is a directive of a selection, such as @skip(if: true)
+type directive struct {
+	name string
+	args []argument
+}
+
+// Values in a document are parsed to nil, bool, int, float64, string,
+// []any, map[string]any, or these types for enums and variables
+type (
+	enumValue string
+	variable  string
+)
+
+// token kinds
+const (
+	tokenEOF = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// token is a lexical token of a document
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+// parser parses a document from its source
+type parser struct {
+	src string
+	pos int
+	tok token
+}
+
+// parse parses the executable document src
+func parse(src string) (*document, error) {
+	p := &parser{src: src}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: "query", selections: selections})
+		case p.tok.kind == tokenName && p.tok.value == "fragment":
+			frag, err := p.fragmentDefinition()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[frag.name]; ok {
+				return nil, fmt.Errorf("fragment %q is defined more than once", frag.name)
+			}
+			doc.fragments[frag.name] = frag
+		case p.tok.kind == tokenName:
+			op, err := p.operationDefinition()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("document contains no operation")
+	}
+	return doc, nil
+}
+
+// operationDefinition parses "query Name($var: Type) { ... }"
+func (p *parser) operationDefinition() (*operation, error) {
+	op := &operation{kind: p.tok.value}
+	if op.kind != "query" && op.kind != "mutation" && op.kind != "subscription" {
+		return nil, p.unexpected()
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("(") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		for !p.peek(")") {
+			def, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.variables = append(op.variables, def)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	op.selections, err = p.selectionSet()
+	return op, err
+}
+
+// variableDefinition parses "$name: Type = default"
+func (p *parser) variableDefinition() (variableDefinition, error) {
+	var def variableDefinition
+	if err := p.expect("$"); err != nil {
+		return def, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return def, err
+	}
+	def.name = name
+	if err := p.expect(":"); err != nil {
+		return def, err
+	}
+	if def.typ, err = p.typeRef(); err != nil {
+		return def, err
+	}
+	if p.peek("=") {
+		if err := p.next(); err != nil {
+			return def, err
+		}
+		if def.defaultVal, err = p.value(true); err != nil {
+			return def, err
+		}
+		def.hasDefault = true
+	}
+	_, err = p.directives()
+	return def, err
+}
+
+// typeRef parses a type such as "[String!]!"
+func (p *parser) typeRef() (typeRef, error) {
+	var t typeRef
+	if p.peek("[") {
+		if err := p.next(); err != nil {
+			return t, err
+		}
+		elem, err := p.typeRef()
+		if err != nil {
+			return t, err
+		}
+		t.list = &elem
+		if err := p.expect("]"); err != nil {
+			return t, err
+		}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return t, err
+		}
+		t.name = name
+	}
+	if p.peek("!") {
+		t.nonNull = true
+		if err := p.next(); err != nil {
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+// fragmentDefinition parses "fragment Name on Type { ... }"
+func (p *parser) fragmentDefinition() (*fragment, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, fmt.Errorf("fragment cannot be named \"on\"")
+	}
+	if p.tok.kind != tokenName || p.tok.value != "on" {
+		return nil, p.unexpected()
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	frag := &fragment{name: name}
+	if frag.on, err = p.name(); err != nil {
+		return nil, err
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	frag.selections, err = p.selectionSet()
+	return frag, err
+}
+
+// selectionSet parses "{ field alias: field(arg: 1) { ... } ...Fragment }"
+func (p *parser) selectionSet() ([]*selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []*selection
+	for !p.peek("}") {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("selection set at %d is empty", p.tok.pos)
+	}
+	return selections, p.next()
+}
+
+// selection parses one field or fragment of a selection set
+func (p *parser) selection() (*selection, error) {
+	var err error
+	if p.peek("...") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		sel := &selection{fragment: true}
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			sel.spread = p.tok.value
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			sel.directives, err = p.directives()
+			return sel, err
+		}
+		if p.tok.kind == tokenName {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if sel.on, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if sel.directives, err = p.directives(); err != nil {
+			return nil, err
+		}
+		sel.selections, err = p.selectionSet()
+		return sel, err
+	}
+
+	sel := &selection{}
+	if sel.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if p.peek(":") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		sel.alias = sel.name
+		if sel.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if sel.args, err = p.arguments(); err != nil {
+		return nil, err
+	}
+	if sel.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		sel.selections, err = p.selectionSet()
+	}
+	return sel, err
+}
+
+// arguments parses "(name: value, ...)" if present
+func (p *parser) arguments() ([]argument, error) {
+	if !p.peek("(") {
+		return nil, nil
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	var args []argument
+	for !p.peek(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.value(false)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, argument{name: name, value: value})
+	}
+	return args, p.next()
+}
+
+// directives parses "@name(args) ..." if present
+func (p *parser) directives() ([]directive, error) {
+	var directives []directive
+	for p.peek("@") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, directive{name: name, args: args})
+	}
+	return directives, nil
+}
+
+// value parses a value; constant values may not contain variables
+func (p *parser) value(constant bool) (any, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokenPunct && tok.value == "$" && !constant:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		return variable(name), err
+	case tok.kind == tokenPunct && tok.value == "[":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		list := []any{}
+		for !p.peek("]") {
+			item, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, p.next()
+	case tok.kind == tokenPunct && tok.value == "{":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		object := map[string]any{}
+		for !p.peek("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.value(constant); err != nil {
+				return nil, err
+			}
+		}
+		return object, p.next()
+	case tok.kind == tokenInt:
+		n, err := strconv.Atoi(tok.value)
+		if err != nil {
+			return nil, fmt.Errorf("integer %s at %d is out of range", tok.value, tok.pos)
+		}
+		return n, p.next()
+	case tok.kind == tokenFloat:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at %d", tok.value, tok.pos)
+		}
+		return f, p.next()
+	case tok.kind == tokenString:
+		return tok.value, p.next()
+	case tok.kind == tokenName:
+		var v any
+		switch tok.value {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = enumValue(tok.value)
+		}
+		return v, p.next()
+	}
+	return nil, p.unexpected()
+}
+
+// name returns the current name token and advances past it
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.next()
+}
+
+// peek reports whether the current token is the punctuator punct
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+// expect advances past the punctuator punct
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return p.unexpected()
+	}
+	return p.next()
+}
+
+// unexpected returns the error for an unexpected current token
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of document")
+	}
+	return fmt.Errorf("unexpected %q at %d", p.tok.value, p.tok.pos)
+}
+
+// next reads the next token, skipping whitespace, commas and comments
+func (p *parser) next() error {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' && c != ',' {
+			break
+		}
+		p.pos++
+	}
+
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokenEOF, pos: start}
+		return nil
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokenPunct, value: "...", pos: start}
+	case strings.IndexByte("!$():=@[]{}|&", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokenPunct, value: string(c), pos: start}
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenName, value: p.src[start:p.pos], pos: start}
+	case c == '-' || isDigit(c):
+		return p.number()
+	case c == '"':
+		return p.string()
+	default:
+		r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+		return fmt.Errorf("unexpected character %q at %d", r, start)
+	}
+	return nil
+}
+
+// number reads an integer or float token
+func (p *parser) number() error {
+	start := p.pos
+	float := false
+	if p.src[p.pos] == '-' {
+		p.pos++
+	}
+	digits := func() int {
+		from := p.pos
+		for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+			p.pos++
+		}
+		return p.pos - from
+	}
+	if digits() == 0 {
+		return fmt.Errorf("invalid number at %d", start)
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '.' {
+		p.pos++
+		float = true
+		if digits() == 0 {
+			return fmt.Errorf("invalid number at %d", start)
+		}
+	}
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		p.pos++
+		float = true
+		if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+			p.pos++
+		}
+		if digits() == 0 {
+			return fmt.Errorf("invalid number at %d", start)
+		}
+	}
+	kind := tokenInt
+	if float {
+		kind = tokenFloat
+	}
+	p.tok = token{kind: kind, value: p.src[start:p.pos], pos: start}
+	return nil
+}
+
+// string reads a string or block string token
+func (p *parser) string() error {
+	start := p.pos
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		end := strings.Index(p.src[p.pos+3:], `"""`)
+		if end < 0 {
+			return fmt.Errorf("unterminated string at %d", start)
+		}
+		value := p.src[p.pos+3 : p.pos+3+end]
+		p.pos += end + 6
+		p.tok = token{kind: tokenString, value: strings.TrimSpace(value), pos: start}
+		return nil
+	}
+
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) || p.src[p.pos] == '\n' || p.src[p.pos] == '\r' {
+			return fmt.Errorf("unterminated string at %d", start)
+		}
+		c := p.src[p.pos]
+		if c == '"' {
+			p.pos++
+			break
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+		if p.pos+1 >= len(p.src) {
+			return fmt.Errorf("unterminated string at %d", start)
+		}
+		escape := p.src[p.pos+1]
+		p.pos += 2
+		switch escape {
+		case '"', '\\', '/':
+			b.WriteByte(escape)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if p.pos+4 > len(p.src) {
+				return fmt.Errorf("invalid escape in string at %d", start)
+			}
+			code, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+			if err != nil {
+				return fmt.Errorf("invalid escape in string at %d", start)
+			}
+			b.WriteRune(rune(code))
+			p.pos += 4
+		default:
+			return fmt.Errorf("invalid escape \\%c in string at %d", escape, start)
+		}
+	}
+	p.tok = token{kind: tokenString, value: b.String(), pos: start}
+	return nil
+}
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
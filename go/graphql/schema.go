@@ -0,0 +1,284 @@
+// Package graphql executes GraphQL queries against a schema of Go
+// resolvers. It implements the query language without introspection:
+// fields, aliases, arguments, variables, fragments and the @skip and
+// @include directives. A schema describes itself in SDL with String.
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Type is a GraphQL type: a *Scalar, an *Object, or a List or NonNull of
+// another type
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type
+type Scalar struct {
+	Name        string
+	Description string
+
+	// serialize converts a resolved value to its JSON form, reporting
+	// false when the value is not of the type
+	serialize func(any) (any, bool)
+
+	// coerce converts an input value, from a document or from JSON
+	// variables, reporting false when the value is not of the type
+	coerce func(any) (any, bool)
+}
+
+// String returns the name of the scalar
+func (s *Scalar) String() string { return s.Name }
+
+// Object is a type with fields
+type Object struct {
+	Name        string
+	Description string
+	Fields      map[string]*Field
+}
+
+// String returns the name of the object
+func (o *Object) String() string { return o.Name }
+
+// List is a list of values of a type
+type List struct{ Of Type }
+
+// String formats the list as GraphQL writes it
+func (l List) String() string { return "[" + l.Of.String() + "]" }
+
+// NonNull is a type whose values are never null
+type NonNull struct{ Of Type }
+
+// String formats the type as GraphQL writes it
+func (n NonNull) String() string { return n.Of.String() + "!" }
+
+// ResolveFunc resolves a field of source, an object value of the parent
+// type, with the coerced arguments of the field. Arguments without a value
+// or default are left out of args. Lists may be resolved to slices of any
+// type; a nil slice is an empty list.
+type ResolveFunc func(ctx context.Context, source any, args map[string]any) (any, error)
+
+// Field is a field of an object
+type Field struct {
+	Type        Type
+	Description string
+	Args        []*Argument
+
+	// Resolve resolves the field. Without it, the field reads the entry
+	// of its name from a source of type map[string]any.
+	Resolve ResolveFunc
+}
+
+// Argument is an argument of a field
+type Argument struct {
+	Name        string
+	Type        Type
+	Description string
+	Default     any // nil for none
+}
+
+// Schema is a set of types rooted at the query type
+type Schema struct {
+	Query *Object
+}
+
+// Built-in scalars
+var (
+	Int = &Scalar{
+		Name: "Int",
+		serialize: func(v any) (any, bool) {
+			n, ok := toInt(v)
+			return n, ok
+		},
+		coerce: func(v any) (any, bool) {
+			if f, ok := v.(float64); ok && f == math.Trunc(f) && math.Abs(f) <= math.MaxInt32 {
+				return int(f), true
+			}
+			n, ok := v.(int)
+			return n, ok && n >= math.MinInt32 && n <= math.MaxInt32
+		},
+	}
+	Float = &Scalar{
+		Name: "Float",
+		serialize: func(v any) (any, bool) {
+			f, ok := toFloat(v)
+			if !ok {
+				return nil, false
+			}
+			// JSON has no NaN or infinities; they stand for unknown values
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, true
+			}
+			return f, true
+		},
+		coerce: func(v any) (any, bool) {
+			return toFloat(v)
+		},
+	}
+	String = &Scalar{
+		Name: "String",
+		serialize: func(v any) (any, bool) {
+			switch v := v.(type) {
+			case string:
+				return v, true
+			case fmt.Stringer:
+				return v.String(), true
+			}
+			return nil, false
+		},
+		coerce: func(v any) (any, bool) {
+			s, ok := v.(string)
+			return s, ok
+		},
+	}
+	Boolean = &Scalar{
+		Name: "Boolean",
+		serialize: func(v any) (any, bool) {
+			b, ok := v.(bool)
+			return b, ok
+		},
+		coerce: func(v any) (any, bool) {
+			b, ok := v.(bool)
+			return b, ok
+		},
+	}
+	ID = &Scalar{
+		Name: "ID",
+		serialize: func(v any) (any, bool) {
+			if n, ok := toInt(v); ok {
+				return fmt.Sprint(n), true
+			}
+			s, ok := v.(string)
+			return s, ok
+		},
+		coerce: func(v any) (any, bool) {
+			if n, ok := v.(int); ok {
+				return fmt.Sprint(n), true
+			}
+			s, ok := v.(string)
+			return s, ok
+		},
+	}
+)
+
+// toInt converts the integer kinds to int
+func toInt(v any) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case uint32:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// toFloat converts the numeric kinds to float64
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+	if n, ok := toInt(v); ok {
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// scalars are the built-in scalars by name, for variable definitions
+var scalars = map[string]*Scalar{"Int": Int, "Float": Float, "String": String, "Boolean": Boolean, "ID": ID}
+
+// String describes the schema in the GraphQL schema definition language
+func (s *Schema) String() string {
+	var b strings.Builder
+	seen := make(map[string]bool)
+	var describe func(o *Object)
+	describe = func(o *Object) {
+		if seen[o.Name] {
+			return
+		}
+		seen[o.Name] = true
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		writeDescription(&b, "", o.Description)
+		fmt.Fprintf(&b, "type %s {\n", o.Name)
+		names := make([]string, 0, len(o.Fields))
+		for name := range o.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var nested []*Object
+		for _, name := range names {
+			field := o.Fields[name]
+			writeDescription(&b, "  ", field.Description)
+			b.WriteString("  " + name)
+			if len(field.Args) > 0 {
+				args := make([]string, len(field.Args))
+				for i, arg := range field.Args {
+					args[i] = arg.Name + ": " + arg.Type.String()
+					if arg.Default != nil {
+						args[i] += " = " + formatValue(arg.Default)
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + field.Type.String() + "\n")
+			if object, ok := namedType(field.Type).(*Object); ok {
+				nested = append(nested, object)
+			}
+		}
+		b.WriteString("}\n")
+		for _, object := range nested {
+			describe(object)
+		}
+	}
+	describe(s.Query)
+	return b.String()
+}
+
+// writeDescription writes a description as an SDL string, if any
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		fmt.Fprintf(b, "%s%q\n", indent, description)
+	}
+}
+
+// formatValue formats a default value as a GraphQL literal
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
+
+// namedType returns the type t is a list or non-null of
+func namedType(t Type) Type {
+	for {
+		switch wrapped := t.(type) {
+		case List:
+			t = wrapped.Of
+		case NonNull:
+			t = wrapped.Of
+		default:
+			return t
+		}
+	}
+}
@@ -0,0 +1,395 @@
+// Package jobs runs analyses queued through the API in the background.
+// Jobs and the tickers they have valued are persisted, so work queued or
+// in progress when the server stops is picked up when it restarts.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lesnerd/fair-stock-value/go/fairvalue"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/services"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+)
+
+// Queue runs jobs one at a time, each using the analyzer's workers. The
+// results of a completed job are saved as a run.
+type Queue struct {
+	analyzer *fairvalue.Analyzer
+	store    *storage.JobStore
+	runs     storage.RunStore
+	logger   services.Logger
+	wake     chan struct{} // signalled when a job is submitted
+
+	mu          sync.Mutex
+	current     *models.Job        // running job, with live progress
+	cancelJob   context.CancelFunc // stops the running job
+	cancelled   bool               // the running job was cancelled through Cancel
+	subscribers map[string][]chan models.Job
+}
+
+// NewQueue creates a queue of the jobs in store, saving their runs to runs
+func NewQueue(analyzer *fairvalue.Analyzer, store *storage.JobStore, runs storage.RunStore, logger services.Logger) *Queue {
+	return &Queue{
+		analyzer:    analyzer,
+		store:       store,
+		runs:        runs,
+		logger:      logger,
+		wake:        make(chan struct{}, 1),
+		subscribers: make(map[string][]chan models.Job),
+	}
+}
+
+// Submit queues an analysis of tickers
+func (q *Queue) Submit(tickers []string) (*models.Job, error) {
+	now := q.analyzer.Now()
+	job := &models.Job{
+		ID:        q.analyzer.NewRunID(now),
+		Status:    models.JobQueued,
+		Tickers:   tickers,
+		Total:     len(tickers),
+		CreatedAt: now,
+	}
+	if err := q.store.Save(job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Get returns the job with the given ID, including the progress of a
+// running job
+func (q *Queue) Get(id string) (*models.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.get(id)
+}
+
+// get returns a copy of a job; q.mu must be held
+func (q *Queue) get(id string) (*models.Job, error) {
+	if q.current != nil && q.current.ID == id {
+		job := *q.current
+		return &job, nil
+	}
+	return q.store.Get(id)
+}
+
+// List returns every job, oldest first
+func (q *Queue) List() ([]*models.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs, err := q.store.List()
+	if err != nil {
+		return nil, err
+	}
+	for i, job := range jobs {
+		if q.current != nil && q.current.ID == job.ID {
+			current := *q.current
+			jobs[i] = &current
+		}
+	}
+	return jobs, nil
+}
+
+// Cancel stops a queued or running job. A running job keeps the tickers
+// valued so far in its progress but produces no run. Cancelling a finished
+// job has no effect.
+func (q *Queue) Cancel(id string) (*models.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.current != nil && q.current.ID == id {
+		q.cancelled = true
+		q.cancelJob()
+		job := *q.current
+		return &job, nil
+	}
+
+	job, err := q.store.Get(id)
+	if err != nil || job.Finished() {
+		return job, err
+	}
+	q.setFinished(job, models.JobCancelled, "")
+	if err := q.store.Save(job); err != nil {
+		return nil, err
+	}
+	q.notify(job)
+	return job, nil
+}
+
+// Subscribe returns a channel receiving the job each time its progress
+// changes. The channel is closed once the job finishes; updates are
+// dropped while the receiver falls behind. Call stop when no longer
+// interested.
+func (q *Queue) Subscribe(id string) (updates <-chan models.Job, stop func(), err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, err := q.get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan models.Job, 16)
+	if job.Finished() {
+		close(ch)
+		return ch, func() {}, nil
+	}
+	q.subscribers[id] = append(q.subscribers[id], ch)
+
+	stop = func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		subs := q.subscribers[id]
+		for i, sub := range subs {
+			if sub == ch {
+				q.subscribers[id] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+	return ch, stop, nil
+}
+
+// notify sends job to its subscribers, closing their channels once it has
+// finished; q.mu must be held
+func (q *Queue) notify(job *models.Job) {
+	for _, ch := range q.subscribers[job.ID] {
+		if job.Finished() {
+			close(ch)
+			continue
+		}
+		select {
+		case ch <- *job:
+		default:
+		}
+	}
+	if job.Finished() {
+		delete(q.subscribers, job.ID)
+	}
+}
+
+// Run processes jobs until ctx is cancelled. A job interrupted that way
+// stays running in the store and resumes from its checkpoint the next
+// time Run is called.
+func (q *Queue) Run(ctx context.Context) error {
+	for {
+		job, jobCtx, err := q.next(ctx)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			select {
+			case <-q.wake:
+				continue
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		q.process(ctx, jobCtx, job)
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// next makes the oldest unfinished job the current one, returning nil
+// when there is none
+func (q *Queue) next(ctx context.Context) (*models.Job, context.Context, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs, err := q.store.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, job := range jobs {
+		if job.Finished() {
+			continue
+		}
+		jobCtx, cancel := context.WithCancel(ctx)
+		q.current = job
+		q.cancelJob = cancel
+		q.cancelled = false
+		return job, jobCtx, nil
+	}
+	return nil, nil, nil
+}
+
+// process values the tickers of job not recorded in its checkpoint and
+// saves the results as a run
+func (q *Queue) process(ctx, jobCtx context.Context, job *models.Job) {
+	defer func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.cancelJob()
+		q.current = nil
+	}()
+
+	path, err := q.store.CheckpointPath(job.ID)
+	if err != nil {
+		q.fail(job, err)
+		return
+	}
+	valued, err := storage.LoadCheckpoint(path, 0, q.analyzer.Now())
+	if err != nil {
+		q.fail(job, err)
+		return
+	}
+	checkpoint, err := storage.OpenCheckpoint(path, true)
+	if err != nil {
+		q.fail(job, err)
+		return
+	}
+
+	// Tickers valued before a restart are taken from the checkpoint
+	results := make(map[string]*models.ValuationResult)
+	var pending []string
+	for _, ticker := range job.Tickers {
+		if entry, ok := valued[ticker]; ok {
+			results[ticker] = entry.Result
+		} else {
+			pending = append(pending, ticker)
+		}
+	}
+	failures := make(map[string]error)
+
+	q.update(job, true, func(job *models.Job) {
+		job.Status = models.JobRunning
+		job.Valued = len(results)
+		job.Failed = 0
+		if job.StartedAt == nil {
+			now := q.analyzer.Now()
+			job.StartedAt = &now
+		}
+	})
+
+	q.analyzer.ValuateEach(jobCtx, pending, func(_ int, v fairvalue.Valuation) {
+		// Tickers skipped because the job stopped are valued when it resumes
+		if errors.Is(v.Err, context.Canceled) && jobCtx.Err() != nil {
+			return
+		}
+		if v.Err != nil {
+			failures[v.Ticker] = v.Err
+		} else {
+			results[v.Ticker] = v.Result
+			if err := checkpoint.Record(v.Result, q.analyzer.Now()); err != nil {
+				q.logger.Printf("Warning: job %s: %v\n", job.ID, err)
+			}
+		}
+		q.update(job, false, func(job *models.Job) {
+			job.Valued = len(results)
+			job.Failed = len(failures)
+		})
+	})
+
+	if jobCtx.Err() != nil {
+		q.mu.Lock()
+		cancelled := q.cancelled
+		q.mu.Unlock()
+
+		if ctx.Err() != nil && !cancelled {
+			// The server is stopping; the checkpoint lets the job resume
+			if err := checkpoint.Close(); err != nil {
+				q.logger.Printf("Warning: job %s: %v\n", job.ID, err)
+			}
+			return
+		}
+		q.removeCheckpoint(job, checkpoint)
+		q.update(job, true, func(job *models.Job) {
+			q.setFinished(job, models.JobCancelled, "")
+		})
+		return
+	}
+
+	// Comps values are anchored on peers once every ticker is valued
+	valuations := make([]fairvalue.Valuation, 0, len(results))
+	for _, ticker := range job.Tickers {
+		if result, ok := results[ticker]; ok {
+			valuations = append(valuations, fairvalue.Valuation{Ticker: ticker, Result: result})
+		}
+	}
+	q.analyzer.ComparePeers(valuations)
+	for _, v := range valuations {
+		results[v.Ticker] = v.Result
+	}
+
+	run := &models.Run{
+		ID:         q.analyzer.NewRunID(*job.StartedAt),
+		StartedAt:  *job.StartedAt,
+		FinishedAt: q.analyzer.Now(),
+		Results:    make([]*models.ValuationResult, 0, len(results)+len(failures)),
+	}
+	for _, ticker := range job.Tickers {
+		if result, ok := results[ticker]; ok {
+			run.Results = append(run.Results, result)
+		} else if err, ok := failures[ticker]; ok {
+			run.Results = append(run.Results, models.NewFailedResult(ticker, err))
+		}
+	}
+	for ticker, err := range failures {
+		if run.Errors == nil {
+			run.Errors = make(map[string]string)
+		}
+		run.Errors[ticker] = err.Error()
+	}
+	q.analyzer.Stamp(run)
+	if err := q.runs.Save(run); err != nil {
+		checkpoint.Close()
+		q.fail(job, fmt.Errorf("failed to save run: %w", err))
+		return
+	}
+
+	q.removeCheckpoint(job, checkpoint)
+	q.update(job, true, func(job *models.Job) {
+		job.RunID = run.ID
+		q.setFinished(job, models.JobCompleted, "")
+	})
+}
+
+// update applies fn to job under the lock and tells subscribers, saving
+// the job when persist is set
+func (q *Queue) update(job *models.Job, persist bool, fn func(*models.Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	fn(job)
+	if persist {
+		if err := q.store.Save(job); err != nil {
+			q.logger.Printf("Warning: job %s: %v\n", job.ID, err)
+		}
+	}
+	q.notify(job)
+}
+
+// fail records that job could not be run
+func (q *Queue) fail(job *models.Job, err error) {
+	q.logger.Printf("Job %s failed: %v\n", job.ID, err)
+	q.update(job, true, func(job *models.Job) {
+		q.setFinished(job, models.JobFailed, err.Error())
+	})
+}
+
+// setFinished moves job to a final status
+func (q *Queue) setFinished(job *models.Job, status, reason string) {
+	now := q.analyzer.Now()
+	job.Status = status
+	job.Error = reason
+	job.FinishedAt = &now
+}
+
+// removeCheckpoint deletes the checkpoint of a job that will not resume
+func (q *Queue) removeCheckpoint(job *models.Job, checkpoint *storage.Checkpoint) {
+	if err := checkpoint.Remove(); err != nil {
+		q.logger.Printf("Warning: job %s: %v\n", job.ID, err)
+	}
+}
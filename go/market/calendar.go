@@ -0,0 +1,378 @@
+// Package market knows when exchanges trade: their timezones, regular
+// session hours, holidays and half days.
+package market
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Calendar is the trading calendar of an exchange
+type Calendar struct {
+	Name     string
+	Location *time.Location
+
+	open, close  time.Duration // regular session, since midnight
+	halfDayClose time.Duration
+	rules        func(year int) (holidays, halfDays []Date)
+	extra        map[Date]bool // configured closures
+
+	mu    sync.Mutex
+	years map[int]yearDays // derived days, by year
+}
+
+// Date is a calendar day, independent of timezone
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// ParseDate parses a YYYY-MM-DD date
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", s)
+	}
+	return DateOf(t), nil
+}
+
+// DateOf returns the day of t in t's location
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{y, m, d}
+}
+
+// String formats d as YYYY-MM-DD
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// at returns the time of day offset into d in loc
+func (d Date) at(offset time.Duration, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc).Add(offset)
+}
+
+// yearDays are the holidays and half days of one year
+type yearDays struct {
+	holidays, halfDays map[Date]bool
+}
+
+// exchange is a built-in calendar and the timezone it is kept in
+type exchange struct {
+	timezone string
+	calendar func(loc *time.Location) *Calendar
+}
+
+// exchanges are the built-in calendars, by name
+var exchanges = map[string]exchange{
+	"NYSE":  {"America/New_York", nyse},
+	"LSE":   {"Europe/London", lse},
+	"XETRA": {"Europe/Berlin", xetra},
+}
+
+// aliases map other names of an exchange, including the exchange codes
+// Yahoo Finance reports, to its calendar
+var aliases = map[string]string{
+	"NASDAQ":   "NYSE",
+	"NAS":      "NYSE",
+	"NMS":      "NYSE",
+	"NGM":      "NYSE",
+	"NCM":      "NYSE",
+	"NYQ":      "NYSE",
+	"AMEX":     "NYSE",
+	"ASE":      "NYSE",
+	"NYSEARCA": "NYSE",
+	"PCX":      "NYSE",
+	"BTS":      "NYSE",
+	"IOB":      "LSE",
+	"GER":      "XETRA",
+}
+
+// Exchanges returns the names of the built-in calendars
+func Exchanges() []string {
+	names := make([]string, 0, len(exchanges))
+	for name := range exchanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the calendar of the named exchange. Names are case
+// insensitive, and Nasdaq and the Yahoo Finance exchange codes of US
+// markets use the NYSE calendar.
+func Lookup(name string) (*Calendar, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	exchange, ok := exchanges[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown exchange %q (expected one of %s)", name, strings.Join(Exchanges(), ", "))
+	}
+	loc, err := time.LoadLocation(exchange.timezone)
+	if err != nil {
+		return nil, fmt.Errorf("exchange %s: %w", name, err)
+	}
+	return exchange.calendar(loc), nil
+}
+
+// AddHolidays closes the exchange on days, on top of its regular
+// holidays, for closures the rules do not know about. It must not be
+// called while the calendar is in use.
+func (c *Calendar) AddHolidays(days ...Date) {
+	if c.extra == nil {
+		c.extra = make(map[Date]bool)
+	}
+	for _, day := range days {
+		c.extra[day] = true
+	}
+}
+
+// days returns the holidays and half days of year
+func (c *Calendar) days(year int) yearDays {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if days, ok := c.years[year]; ok {
+		return days
+	}
+	holidays, halfDays := c.rules(year)
+	days := yearDays{holidays: make(map[Date]bool), halfDays: make(map[Date]bool)}
+	for _, day := range holidays {
+		days.holidays[day] = true
+	}
+	for _, day := range halfDays {
+		days.halfDays[day] = true
+	}
+	if c.years == nil {
+		c.years = make(map[int]yearDays)
+	}
+	c.years[year] = days
+	return days
+}
+
+// IsHoliday reports whether day is one of the exchange's holidays
+func (c *Calendar) IsHoliday(day Date) bool {
+	return c.extra[day] || c.days(day.Year).holidays[day]
+}
+
+// IsHalfDay reports whether the exchange closes early on day
+func (c *Calendar) IsHalfDay(day Date) bool {
+	return !c.IsHoliday(day) && c.days(day.Year).halfDays[day]
+}
+
+// IsTradingDay reports whether the exchange has a session on day
+func (c *Calendar) IsTradingDay(day Date) bool {
+	switch weekday(day) {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	return !c.IsHoliday(day)
+}
+
+// Session returns the opening and closing time of the session on day, and
+// false when the exchange does not trade that day
+func (c *Calendar) Session(day Date) (open, close time.Time, ok bool) {
+	if !c.IsTradingDay(day) {
+		return time.Time{}, time.Time{}, false
+	}
+	closing := c.close
+	if c.IsHalfDay(day) {
+		closing = c.halfDayClose
+	}
+	return day.at(c.open, c.Location), day.at(closing, c.Location), true
+}
+
+// IsOpen reports whether the exchange is in its regular session at t
+func (c *Calendar) IsOpen(t time.Time) bool {
+	open, close, ok := c.Session(DateOf(t.In(c.Location)))
+	return ok && !t.Before(open) && t.Before(close)
+}
+
+// NextOpen returns t when the exchange is open at t, or else when its next
+// session opens
+func (c *Calendar) NextOpen(t time.Time) time.Time {
+	if c.IsOpen(t) {
+		return t
+	}
+	day := t.In(c.Location)
+	// A year holds far fewer consecutive closed days than this
+	for i := 0; i < 366; i++ {
+		if open, _, ok := c.Session(DateOf(day)); ok && open.After(t) {
+			return open
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, c.Location)
+	}
+	return time.Time{}
+}
+
+// nyse is the calendar of the New York Stock Exchange, which Nasdaq shares
+func nyse(loc *time.Location) *Calendar {
+	return &Calendar{
+		Name:         "NYSE",
+		Location:     loc,
+		open:         9*time.Hour + 30*time.Minute,
+		close:        16 * time.Hour,
+		halfDayClose: 13 * time.Hour,
+		rules: func(year int) (holidays, halfDays []Date) {
+			// New Year's Day falling on a Saturday is not made up for,
+			// since the Friday before ends the previous year
+			newYear := Date{year, time.January, 1}
+			if weekday(newYear) == time.Sunday {
+				newYear.Day = 2
+			}
+			easter := easterSunday(year)
+			independence := observed(Date{year, time.July, 4})
+			thanksgiving := nthWeekday(year, time.November, time.Thursday, 4)
+			christmas := observed(Date{year, time.December, 25})
+			holidays = []Date{
+				newYear,
+				nthWeekday(year, time.January, time.Monday, 3),  // Martin Luther King Jr. Day
+				nthWeekday(year, time.February, time.Monday, 3), // Washington's Birthday
+				addDays(easter, -2),                             // Good Friday
+				lastWeekday(year, time.May, time.Monday),        // Memorial Day
+				independence,
+				nthWeekday(year, time.September, time.Monday, 1), // Labor Day
+				thanksgiving,
+				christmas,
+			}
+			if year >= 2022 {
+				holidays = append(holidays, observed(Date{year, time.June, 19})) // Juneteenth
+			}
+			halfDays = []Date{
+				{year, time.July, 3},
+				addDays(thanksgiving, 1),
+				{year, time.December, 24},
+			}
+			return holidays, halfDays
+		},
+	}
+}
+
+// lse is the calendar of the London Stock Exchange
+func lse(loc *time.Location) *Calendar {
+	return &Calendar{
+		Name:         "LSE",
+		Location:     loc,
+		open:         8 * time.Hour,
+		close:        16*time.Hour + 30*time.Minute,
+		halfDayClose: 12*time.Hour + 30*time.Minute,
+		rules: func(year int) (holidays, halfDays []Date) {
+			easter := easterSunday(year)
+			// Christmas and Boxing Day falling on a weekend are made up
+			// for on the following weekdays
+			christmas, boxingDay := Date{year, time.December, 25}, Date{year, time.December, 26}
+			switch weekday(christmas) {
+			case time.Friday:
+				boxingDay.Day = 28
+			case time.Saturday:
+				christmas.Day, boxingDay.Day = 27, 28
+			case time.Sunday:
+				christmas.Day = 27
+			}
+			holidays = []Date{
+				nextWeekday(Date{year, time.January, 1}),
+				addDays(easter, -2),                         // Good Friday
+				addDays(easter, 1),                          // Easter Monday
+				nthWeekday(year, time.May, time.Monday, 1),  // Early May bank holiday
+				lastWeekday(year, time.May, time.Monday),    // Spring bank holiday
+				lastWeekday(year, time.August, time.Monday), // Summer bank holiday
+				christmas,
+				boxingDay,
+			}
+			halfDays = []Date{
+				{year, time.December, 24},
+				{year, time.December, 31},
+			}
+			return holidays, halfDays
+		},
+	}
+}
+
+// xetra is the calendar of the Deutsche Börse Xetra venue, which has no
+// early closes
+func xetra(loc *time.Location) *Calendar {
+	return &Calendar{
+		Name:     "XETRA",
+		Location: loc,
+		open:     9 * time.Hour,
+		close:    17*time.Hour + 30*time.Minute,
+		rules: func(year int) (holidays, halfDays []Date) {
+			easter := easterSunday(year)
+			return []Date{
+				{year, time.January, 1},
+				addDays(easter, -2), // Good Friday
+				addDays(easter, 1),  // Easter Monday
+				{year, time.May, 1},
+				{year, time.December, 24},
+				{year, time.December, 25},
+				{year, time.December, 26},
+				{year, time.December, 31},
+			}, nil
+		},
+	}
+}
+
+// weekday returns the day of the week of d
+func weekday(d Date) time.Weekday {
+	return d.at(0, time.UTC).Weekday()
+}
+
+// addDays returns the day n days after d
+func addDays(d Date, n int) Date {
+	return DateOf(time.Date(d.Year, d.Month, d.Day+n, 0, 0, 0, 0, time.UTC))
+}
+
+// observed moves a holiday falling on a Saturday to the Friday before and
+// one falling on a Sunday to the Monday after, as US exchanges do
+func observed(d Date) Date {
+	switch weekday(d) {
+	case time.Saturday:
+		return addDays(d, -1)
+	case time.Sunday:
+		return addDays(d, 1)
+	}
+	return d
+}
+
+// nextWeekday moves a holiday falling on a weekend to the Monday after
+func nextWeekday(d Date) Date {
+	for weekday(d) == time.Saturday || weekday(d) == time.Sunday {
+		d = addDays(d, 1)
+	}
+	return d
+}
+
+// nthWeekday returns the nth given weekday of month
+func nthWeekday(year int, month time.Month, day time.Weekday, n int) Date {
+	first := Date{year, month, 1}
+	offset := (int(day) - int(weekday(first)) + 7) % 7
+	return addDays(first, offset+7*(n-1))
+}
+
+// lastWeekday returns the last given weekday of month
+func lastWeekday(year int, month time.Month, day time.Weekday) Date {
+	last := addDays(Date{year, month + 1, 1}, -1)
+	offset := (int(weekday(last)) - int(day) + 7) % 7
+	return addDays(last, -offset)
+}
+
+// easterSunday returns the date of Easter in the Gregorian calendar
+func easterSunday(year int) Date {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return Date{year, time.Month(month), day}
+}
@@ -0,0 +1,229 @@
+package market
+
+import (
+	"testing"
+	"time"
+)
+
+// calendarYear is an exchange's published calendar for one year: the
+// weekdays it is closed and the days it closes early
+type calendarYear struct {
+	exchange string
+	year     int
+	holidays []string
+	halfDays []string
+}
+
+var calendarYears = []calendarYear{
+	{
+		exchange: "NYSE",
+		year:     2026,
+		holidays: []string{
+			"2026-01-01", "2026-01-19", "2026-02-16", "2026-04-03", "2026-05-25",
+			"2026-06-19",
+			"2026-07-03", // Independence Day falls on a Saturday
+			"2026-09-07", "2026-11-26", "2026-12-25",
+		},
+		halfDays: []string{"2026-11-27", "2026-12-24"},
+	},
+	{
+		exchange: "NYSE",
+		year:     2027,
+		holidays: []string{
+			"2027-01-01", "2027-01-18", "2027-02-15", "2027-03-26", "2027-05-31",
+			"2027-06-18", // Juneteenth falls on a Saturday
+			"2027-07-05", // Independence Day falls on a Sunday
+			"2027-09-06", "2027-11-25",
+			"2027-12-24", // Christmas falls on a Saturday
+			// New Year's Day 2028 falls on a Saturday and is not made up for
+		},
+		halfDays: []string{"2027-11-26"},
+	},
+	{
+		exchange: "LSE",
+		year:     2026,
+		holidays: []string{
+			"2026-01-01", "2026-04-03", "2026-04-06", "2026-05-04", "2026-05-25",
+			"2026-08-31", "2026-12-25",
+			"2026-12-28", // Boxing Day falls on a Saturday
+		},
+		halfDays: []string{"2026-12-24", "2026-12-31"},
+	},
+	{
+		exchange: "LSE",
+		year:     2027,
+		holidays: []string{
+			"2027-01-01", "2027-03-26", "2027-03-29", "2027-05-03", "2027-05-31",
+			"2027-08-30",
+			"2027-12-27", "2027-12-28", // Christmas and Boxing Day fall on a weekend
+		},
+		halfDays: []string{"2027-12-24", "2027-12-31"},
+	},
+	{
+		exchange: "XETRA",
+		year:     2026,
+		holidays: []string{
+			"2026-01-01", "2026-04-03", "2026-04-06", "2026-05-01", "2026-12-24",
+			"2026-12-25", "2026-12-31",
+		},
+	},
+}
+
+func TestCalendarYears(t *testing.T) {
+	for _, tc := range calendarYears {
+		cal, err := Lookup(tc.exchange)
+		if err != nil {
+			t.Fatal(err)
+		}
+		holidays, halfDays := dateSet(t, tc.holidays), dateSet(t, tc.halfDays)
+		for day := (Date{tc.year, time.January, 1}); day.Year == tc.year; day = addDays(day, 1) {
+			weekend := weekday(day) == time.Saturday || weekday(day) == time.Sunday
+			if got, want := cal.IsTradingDay(day), !weekend && !holidays[day]; got != want {
+				t.Errorf("%s: IsTradingDay(%s) = %v, want %v", tc.exchange, day, got, want)
+			}
+			if weekend {
+				continue
+			}
+			if got := cal.IsHoliday(day); got != holidays[day] {
+				t.Errorf("%s: IsHoliday(%s) = %v, want %v", tc.exchange, day, got, holidays[day])
+			}
+			if got := cal.IsHalfDay(day); got != halfDays[day] {
+				t.Errorf("%s: IsHalfDay(%s) = %v, want %v", tc.exchange, day, got, halfDays[day])
+			}
+		}
+	}
+}
+
+func TestSession(t *testing.T) {
+	cal, err := Lookup("NYSE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, clock string) time.Time {
+		tm, err := time.ParseInLocation(time.DateTime, day+" "+clock, cal.Location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	for _, tc := range []struct {
+		day         string
+		open, close string // empty when the exchange is closed
+	}{
+		{"2026-11-25", "09:30:00", "16:00:00"},
+		{"2026-11-26", "", ""},
+		{"2026-11-27", "09:30:00", "13:00:00"},
+		{"2026-11-28", "", ""},
+		{"2026-07-03", "", ""},
+	} {
+		open, close, ok := cal.Session(mustDate(t, tc.day))
+		if ok != (tc.open != "") {
+			t.Errorf("Session(%s) ok = %v, want %v", tc.day, ok, !ok)
+			continue
+		}
+		if ok && (!open.Equal(at(tc.day, tc.open)) || !close.Equal(at(tc.day, tc.close))) {
+			t.Errorf("Session(%s) = %s to %s, want %s to %s", tc.day, open, close, tc.open, tc.close)
+		}
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	cal, err := Lookup("NASDAQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		from, want time.Time
+	}{
+		// Open
+		{time.Date(2026, 4, 2, 10, 0, 0, 0, cal.Location), time.Date(2026, 4, 2, 10, 0, 0, 0, cal.Location)},
+		// After the close before Good Friday and a weekend
+		{time.Date(2026, 4, 2, 16, 0, 0, 0, cal.Location), time.Date(2026, 4, 6, 9, 30, 0, 0, cal.Location)},
+		// After an early close
+		{time.Date(2026, 11, 27, 13, 30, 0, 0, cal.Location), time.Date(2026, 11, 30, 9, 30, 0, 0, cal.Location)},
+		// Before the open, given in another timezone
+		{time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC), time.Date(2026, 1, 2, 9, 30, 0, 0, cal.Location)},
+	} {
+		if got := cal.NextOpen(tc.from); !got.Equal(tc.want) {
+			t.Errorf("NextOpen(%s) = %s, want %s", tc.from, got, tc.want)
+		}
+	}
+}
+
+func TestAddHolidays(t *testing.T) {
+	cal, err := Lookup("NYSE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The national day of mourning for President Carter
+	mourning := Date{2025, time.January, 9}
+	if !cal.IsTradingDay(mourning) {
+		t.Fatalf("%s closed before it was added", mourning)
+	}
+	// A closure on a day that would close early replaces the half day
+	cal.AddHolidays(mourning, Date{2025, time.December, 24})
+	for _, day := range []Date{mourning, {2025, time.December, 24}} {
+		if cal.IsTradingDay(day) || !cal.IsHoliday(day) || cal.IsHalfDay(day) {
+			t.Errorf("%s not closed after it was added", day)
+		}
+	}
+	if !cal.IsTradingDay(Date{2025, time.January, 10}) {
+		t.Error("2025-01-10 closed by an added holiday")
+	}
+}
+
+func TestEasterSunday(t *testing.T) {
+	for year, want := range map[int]string{
+		2008: "2008-03-23",
+		2019: "2019-04-21",
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2027: "2027-03-28",
+		2028: "2028-04-16",
+		2038: "2038-04-25",
+	} {
+		if got := easterSunday(year); got.String() != want {
+			t.Errorf("easterSunday(%d) = %s, want %s", year, got, want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for name, want := range map[string]string{
+		"NYSE":    "NYSE",
+		" nasdaq": "NYSE",
+		"NMS":     "NYSE",
+		"lse":     "LSE",
+		"IOB":     "LSE",
+		"GER":     "XETRA",
+	} {
+		cal, err := Lookup(name)
+		if err != nil {
+			t.Errorf("Lookup(%q): %v", name, err)
+		} else if cal.Name != want {
+			t.Errorf("Lookup(%q) = %s, want %s", name, cal.Name, want)
+		}
+	}
+	if _, err := Lookup("TSX"); err == nil {
+		t.Error("Lookup(TSX) succeeded, want an error")
+	}
+}
+
+func mustDate(t *testing.T, s string) Date {
+	t.Helper()
+	d, err := ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func dateSet(t *testing.T, days []string) map[Date]bool {
+	t.Helper()
+	set := make(map[Date]bool, len(days))
+	for _, s := range days {
+		set[mustDate(t, s)] = true
+	}
+	return set
+}
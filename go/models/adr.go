@@ -0,0 +1,68 @@
+package models
+
+import (
+	"math"
+	"strings"
+)
+
+// ADRCosts are what holders of an ADR lose of the dividends of the
+// company, before any tax of their own: the share its home country
+// withholds, and the fees the depositary bank passes through
+type ADRCosts struct {
+	Country     string  `json:"country,omitempty"` // home country of the company
+	Withholding float64 `json:"withholding"`       // fraction of dividends withheld at source
+	FeePerShare float64 `json:"fee_per_share"`     // yearly depositary fee per ADR, in the trading currency
+}
+
+// ADRParameters set the costs of ADRs by the home country of the company.
+// The zero value leaves their dividends as they are.
+type ADRParameters struct {
+	// Countries are the costs by country, as the data sources or ticker
+	// file name it, matched regardless of case
+	Countries map[string]ADRCosts `json:"countries,omitempty"`
+
+	// Default are the costs of ADRs of countries not listed
+	Default ADRCosts `json:"default"`
+}
+
+// domesticCountries are the names of the US, whose companies list their
+// own shares rather than ADRs
+var domesticCountries = map[string]bool{
+	"united states": true, "us": true, "usa": true,
+}
+
+// IsADR reports whether s trades in dollars for a company based outside
+// the US, as an ADR or a foreign share listed directly
+func (s *StockData) IsADR() bool {
+	country := strings.ToLower(strings.TrimSpace(s.Country))
+	return s.Currency == "USD" && country != "" && !domesticCountries[country]
+}
+
+// For returns the costs of the dividends of stockData, nil when it is not
+// an ADR or no costs are configured
+func (p ADRParameters) For(stockData *StockData) *ADRCosts {
+	if !stockData.IsADR() {
+		return nil
+	}
+	costs := p.Default
+	for country, configured := range p.Countries {
+		if strings.EqualFold(country, strings.TrimSpace(stockData.Country)) {
+			costs = configured
+			break
+		}
+	}
+	if costs.Withholding == 0 && costs.FeePerShare == 0 {
+		return nil
+	}
+	costs.Country = stockData.Country
+	return &costs
+}
+
+// NetDividend returns what a holder receives of dividend, the annual
+// dividend per share, after the costs, never below 0
+func (c *ADRCosts) NetDividend(dividend float64) float64 {
+	if c == nil || dividend <= 0 {
+		return dividend
+	}
+	return math.Max(dividend*(1-c.Withholding)-c.FeePerShare, 0)
+}
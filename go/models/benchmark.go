@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Benchmark is the valuation of the market as a whole, read from a fund
+// tracking an index such as the S&P 500, which the multiples and upside of
+// single stocks are set against
+type Benchmark struct {
+	Symbol        string    `json:"symbol"`         // fund tracking the index, such as SPY
+	PERatio       float64   `json:"pe_ratio"`       // trailing P/E of the index
+	EarningsYield float64   `json:"earnings_yield"` // inverse of the P/E, in percent
+	LongRunPE     float64   `json:"long_run_pe"`    // P/E the index is expected to revert to
+	Upside        float64   `json:"upside"`         // percentage the index would move to reach its long-run P/E
+	Fallback      bool      `json:"fallback,omitempty"`
+	FetchTime     time.Time `json:"fetch_time"`
+}
+
+// NewBenchmark returns the benchmark of symbol trading at peRatio
+func NewBenchmark(symbol string, peRatio float64, fetchTime time.Time) *Benchmark {
+	b := &Benchmark{Symbol: symbol, PERatio: peRatio, FetchTime: fetchTime}
+	if peRatio > 0 {
+		b.EarningsYield = 100 / peRatio
+	}
+	return b
+}
+
+// SetLongRunPE sets the P/E the upside of the benchmark is measured against
+func (b *Benchmark) SetLongRunPE(longRunPE float64) {
+	b.LongRunPE = longRunPE
+	b.Upside = 0
+	if b.PERatio > 0 && longRunPE > 0 {
+		b.Upside = (longRunPE/b.PERatio - 1) * 100
+	}
+}
+
+// MarketRelative expresses the multiple and upside of a result relative to
+// the benchmark, so a stock is judged cheap against the market it trades
+// in rather than by absolute rules alone
+type MarketRelative struct {
+	Benchmark string `json:"benchmark"` // symbol of the benchmark
+
+	// PERatio is the stock's P/E as a multiple of the benchmark's, 0 when
+	// the stock has no positive earnings
+	PERatio float64 `json:"pe_ratio,omitempty"`
+
+	// EarningsYieldSpread is the stock's earnings yield minus the
+	// benchmark's, in percentage points
+	EarningsYieldSpread float64 `json:"earnings_yield_spread"`
+
+	// Upside is the stock's upside minus the upside of the benchmark to
+	// its long-run P/E, in percentage points
+	Upside float64 `json:"upside"`
+}
+
+// Relate returns the figures of r relative to b, or nil when b has no P/E
+// or r no price to relate
+func (b *Benchmark) Relate(r *ValuationResult) *MarketRelative {
+	if b == nil || b.PERatio <= 0 || r.CurrentPrice <= 0 {
+		return nil
+	}
+	relative := &MarketRelative{
+		Benchmark: b.Symbol,
+		Upside:    r.UpsidePercentage - b.Upside,
+	}
+	// Without a positive P/E the earnings yield is read from EPS, which
+	// makes it negative for loss-making stocks
+	if r.PERatio > 0 {
+		relative.PERatio = r.PERatio / b.PERatio
+		relative.EarningsYieldSpread = 100/r.PERatio - b.EarningsYield
+	} else {
+		relative.EarningsYieldSpread = r.EPS/r.CurrentPrice*100 - b.EarningsYield
+	}
+	return relative
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// ShareCount is the diluted share count of a company over a fiscal year
+type ShareCount struct {
+	Date   time.Time `json:"date"`   // end of the fiscal year
+	Shares int64     `json:"shares"` // weighted average over the year
+}
+
+// splitJump is the change in share count between consecutive years taken
+// for a split the history was not adjusted for, rather than for buybacks
+// or issuance
+const splitJump = 1.5
+
+// NetBuybackYield returns the yearly rate the share count shrank by over
+// counts, oldest first: positive for net buybacks, negative for net
+// issuance. Counts before a jump of splitJump times or more are left out.
+// It returns false when less than a year of counts remains to compare.
+func NetBuybackYield(counts []ShareCount) (float64, bool) {
+	first := 0
+	for i := 1; i < len(counts); i++ {
+		if counts[i-1].Shares <= 0 || counts[i].Shares <= 0 {
+			first = i
+			continue
+		}
+		ratio := float64(counts[i].Shares) / float64(counts[i-1].Shares)
+		if ratio >= splitJump || ratio <= 1/splitJump {
+			first = i
+		}
+	}
+	if len(counts)-first < 2 {
+		return 0, false
+	}
+
+	oldest, newest := counts[first], counts[len(counts)-1]
+	years := newest.Date.Sub(oldest.Date).Hours() / 24 / 365.25
+	if years < 0.9 || oldest.Shares <= 0 {
+		return 0, false
+	}
+	return 1 - math.Pow(float64(newest.Shares)/float64(oldest.Shares), 1/years), true
+}
@@ -0,0 +1,30 @@
+package models
+
+import "math"
+
+// CommodityParameters scale the free cash flow of energy companies to a
+// commodity price scenario, as trailing FCF earned at a cycle peak or
+// trough says little about the years a DCF projects. The zero value
+// leaves FCF as it is.
+type CommodityParameters struct {
+	// Scenario is the oil price, in dollars a barrel, energy companies are
+	// valued at, such as 60, 80 or 100 for WTI
+	Scenario float64 `json:"scenario"`
+
+	// ReferencePrice is the oil price the trailing FCF was earned at
+	ReferencePrice float64 `json:"reference_price"`
+
+	// Sensitivity is the fractional change of FCF per fractional change
+	// of the oil price: 1 scales FCF in proportion to the price
+	Sensitivity float64 `json:"sensitivity"`
+}
+
+// Scale returns what the FCF of stockData is multiplied by under the
+// scenario, never below 0, and whether it applies: only to the energy
+// sector, and only when a scenario and reference price are set
+func (p CommodityParameters) Scale(stockData *StockData) (float64, bool) {
+	if !stockData.IsEnergy() || p.Scenario <= 0 || p.ReferencePrice <= 0 {
+		return 1, false
+	}
+	return math.Max(1+p.Sensitivity*(p.Scenario/p.ReferencePrice-1), 0), true
+}
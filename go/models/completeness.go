@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInsufficientData means too few of the valuation inputs of a stock were
+// fetched, rather than filled in from fallback data, for it to be valued
+var ErrInsufficientData = errors.New("insufficient data")
+
+// ValuationInputs are the fields, by JSON name, the fair value is
+// calculated from and data completeness is counted over
+var ValuationInputs = []string{
+	"current_price", "fcf_per_share", "eps", "book_value", "pe_ratio", "growth_rate", "market_cap",
+}
+
+// Input returns the valuation input of the JSON name field, or 0 for a
+// field that is not one
+func (s *StockData) Input(field string) float64 {
+	switch field {
+	case "current_price":
+		return s.CurrentPrice
+	case "fcf_per_share":
+		return s.FCFPerShare
+	case "eps":
+		return s.EPS
+	case "book_value":
+		return s.BookValue
+	case "pe_ratio":
+		return s.PERatio
+	case "growth_rate":
+		return s.GrowthRate
+	case "market_cap":
+		return float64(s.MarketCap)
+	}
+	return 0
+}
+
+// RealInputs returns the valuation inputs of s some source reported: those
+// that are set and not among its fallback fields
+func (s *StockData) RealInputs() []string {
+	var real []string
+	for _, field := range ValuationInputs {
+		if s.Input(field) != 0 && !slices.Contains(s.FallbackFields, field) {
+			real = append(real, field)
+		}
+	}
+	return real
+}
+
+// CheckCompleteness returns an error wrapping ErrInsufficientData when
+// fewer than minReal valuation inputs of s are real. A zero minReal
+// accepts any data.
+func (s *StockData) CheckCompleteness(minReal int) error {
+	real := s.RealInputs()
+	if len(real) >= minReal {
+		return nil
+	}
+	var missing []string
+	for _, field := range ValuationInputs {
+		if !slices.Contains(real, field) {
+			missing = append(missing, field)
+		}
+	}
+	return fmt.Errorf("%s: %w: %d of %d valuation inputs fetched, %d required (missing or fallback: %s)",
+		s.Ticker, ErrInsufficientData, len(real), len(ValuationInputs), minReal, strings.Join(missing, ", "))
+}
+
+// NewFailedResult returns the result of a ticker that could not be valued
+// for err, with status StatusInsufficientData when err wraps
+// ErrInsufficientData and StatusError otherwise
+func NewFailedResult(ticker string, err error) *ValuationResult {
+	result := NewErrorResult(ticker, err.Error())
+	if errors.Is(err, ErrInsufficientData) {
+		result.Status = StatusInsufficientData
+	}
+	return result
+}
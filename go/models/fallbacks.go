@@ -0,0 +1,176 @@
+package models
+
+import "fmt"
+
+// FallbackPolicy names how a free cash flow or EPS per share that is not
+// positive, which the models cannot value, is replaced
+type FallbackPolicy string
+
+// Policies replacing inputs that are not positive
+const (
+	FallbackConstant        FallbackPolicy = "constant"         // a fixed amount per share
+	FallbackSkip            FallbackPolicy = "skip"             // none; the stock is not valued
+	FallbackSectorMedian    FallbackPolicy = "sector_median"    // the price at the sector's median yield
+	FallbackRevenueMultiple FallbackPolicy = "revenue_multiple" // a fraction of revenue per share
+)
+
+// Valid reports whether p is a known policy; the empty policy is
+// FallbackConstant
+func (p FallbackPolicy) Valid() bool {
+	switch p {
+	case "", FallbackConstant, FallbackSkip, FallbackSectorMedian, FallbackRevenueMultiple:
+		return true
+	}
+	return false
+}
+
+// Resolved returns the policy, with the empty policy as FallbackConstant
+func (p FallbackPolicy) Resolved() FallbackPolicy {
+	if p == "" {
+		return FallbackConstant
+	}
+	return p
+}
+
+// String returns how the policy is described to users
+func (p FallbackPolicy) String() string {
+	switch p {
+	case FallbackSkip:
+		return "skip"
+	case FallbackSectorMedian:
+		return "sector median"
+	case FallbackRevenueMultiple:
+		return "revenue multiple"
+	default:
+		return "constant"
+	}
+}
+
+// Defaults of the fallback rules
+const (
+	DefaultFallbackFCF = 2.0  // FCF per share of the constant policy
+	DefaultFallbackEPS = 1.0  // EPS of the constant policy
+	defaultFCFMargin   = 0.06 // FCF per dollar of revenue of the revenue_multiple policy
+	defaultNetMargin   = 0.05 // earnings per dollar of revenue of the revenue_multiple policy
+)
+
+// FallbackRule sets how one input that is not positive is replaced
+type FallbackRule struct {
+	Policy FallbackPolicy `json:"policy"`
+
+	// Value is the amount per share of the constant policy; 0 uses the
+	// default
+	Value float64 `json:"value,omitempty"`
+
+	// Multiple is the fraction of revenue per share the revenue_multiple
+	// policy takes, a margin; 0 uses the default
+	Multiple float64 `json:"multiple,omitempty"`
+}
+
+// FallbackParameters set how the DCF replaces a free cash flow per share,
+// and Comps an EPS, that is not positive. The zero value replaces them
+// with DefaultFallbackFCF and DefaultFallbackEPS.
+type FallbackParameters struct {
+	FCF FallbackRule `json:"fcf"`
+	EPS FallbackRule `json:"eps"`
+}
+
+// ReplaceFCF returns the free cash flow per share the DCF values
+// stockData on in place of one that is not positive, or an error wrapping
+// ErrInsufficientData when the policy skips the stock or has nothing to
+// estimate it from
+func (p FallbackParameters) ReplaceFCF(stockData *StockData) (float64, error) {
+	return p.FCF.replace(stockData, "free cash flow", DefaultFallbackFCF, SectorMedianFCFYield(stockData.Sector), defaultFCFMargin)
+}
+
+// ReplaceEPS returns the EPS Comps values stockData on in place of one that is
+// not positive, or an error wrapping ErrInsufficientData when the policy
+// skips the stock or has nothing to estimate it from
+func (p FallbackParameters) ReplaceEPS(stockData *StockData) (float64, error) {
+	return p.EPS.replace(stockData, "EPS", DefaultFallbackEPS, 1/SectorMedianPE(stockData.Sector), defaultNetMargin)
+}
+
+// replace returns the value the rule puts in place of input, given the
+// defaults of the input: the constant, the sector median yield on the
+// price and the fraction of revenue
+func (r FallbackRule) replace(stockData *StockData, input string, constant, sectorYield, margin float64) (float64, error) {
+	switch r.Policy {
+	case FallbackSkip:
+		return 0, fmt.Errorf("%s: %w: %s is not positive and the fallback policy skips the stock",
+			stockData.Ticker, ErrInsufficientData, input)
+	case FallbackSectorMedian:
+		if stockData.CurrentPrice <= 0 {
+			return 0, fmt.Errorf("%s: %w: %s is not positive and there is no price to apply the sector median to",
+				stockData.Ticker, ErrInsufficientData, input)
+		}
+		return stockData.CurrentPrice * sectorYield, nil
+	case FallbackRevenueMultiple:
+		if stockData.Revenue <= 0 || stockData.SharesOutstanding <= 0 {
+			return 0, fmt.Errorf("%s: %w: %s is not positive and there is no revenue per share to estimate it from",
+				stockData.Ticker, ErrInsufficientData, input)
+		}
+		if r.Multiple > 0 {
+			margin = r.Multiple
+		}
+		return stockData.Revenue / float64(stockData.SharesOutstanding) * margin, nil
+	default:
+		if r.Value > 0 {
+			return r.Value, nil
+		}
+		return constant, nil
+	}
+}
+
+// sectorMedian holds typical multiples of the stocks of a sector
+type sectorMedian struct {
+	pe       float64
+	fcfYield float64 // free cash flow over market cap
+	pffo     float64 // price over funds from operations, of REITs
+}
+
+// sectorMedians are keyed by the normalized sector, with defaultSector
+// for sectors not listed
+var sectorMedians = map[string]sectorMedian{
+	SectorTechnology:            {pe: 22.0, fcfYield: 0.035},
+	SectorHealthcare:            {pe: 18.0, fcfYield: 0.045},
+	SectorFinancialServices:     {pe: 10.0, fcfYield: 0.06},
+	SectorConsumerCyclical:      {pe: 16.0, fcfYield: 0.045},
+	SectorConsumerDefensive:     {pe: 20.0, fcfYield: 0.045},
+	SectorEnergy:                {pe: 12.0, fcfYield: 0.08},
+	SectorIndustrials:           {pe: 13.0, fcfYield: 0.045},
+	SectorBasicMaterials:        {pe: 12.0, fcfYield: 0.055},
+	SectorRealEstate:            {pe: 14.0, fcfYield: 0.05, pffo: 16.0},
+	SectorUtilities:             {pe: 16.0, fcfYield: 0.02},
+	SectorCommunicationServices: {pe: 18.0, fcfYield: 0.05},
+}
+
+// defaultSector holds the multiples of sectors that are not listed
+var defaultSector = sectorMedian{pe: 18.0, fcfYield: 0.045, pffo: 16.0}
+
+// medianOf returns the multiples of sector, normalized first
+func medianOf(sector string) sectorMedian {
+	if median, ok := sectorMedians[NormalizeSector(sector)]; ok {
+		return median
+	}
+	return defaultSector
+}
+
+// SectorMedianPE returns the typical P/E of the stocks of sector
+func SectorMedianPE(sector string) float64 {
+	return medianOf(sector).pe
+}
+
+// SectorMedianPFFO returns the typical P/FFO of the REITs of sector, that
+// of sectors without one being the default
+func SectorMedianPFFO(sector string) float64 {
+	if pffo := medianOf(sector).pffo; pffo > 0 {
+		return pffo
+	}
+	return defaultSector.pffo
+}
+
+// SectorMedianFCFYield returns the typical free cash flow yield on the
+// market cap of the stocks of sector
+func SectorMedianFCFYield(sector string) float64 {
+	return medianOf(sector).fcfYield
+}
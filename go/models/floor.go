@@ -0,0 +1,47 @@
+package models
+
+// BookFloor names the per-share value fair values are never lowered below
+type BookFloor string
+
+// Floors fair values may be held to
+const (
+	FloorTangibleBook BookFloor = "tangible_book" // book value less goodwill and other intangibles
+	FloorBook         BookFloor = "book"          // stated book value
+	FloorNone         BookFloor = "none"
+)
+
+// Valid reports whether f is a known floor; the empty floor is
+// FloorTangibleBook
+func (f BookFloor) Valid() bool {
+	switch f {
+	case "", FloorTangibleBook, FloorBook, FloorNone:
+		return true
+	}
+	return false
+}
+
+// Value returns the floor of stockData's fair values. A tangible book
+// value that is unknown or negative, as after large acquisitions, holds
+// fair values to nothing, and neither does FloorNone.
+func (f BookFloor) Value(stockData *StockData) float64 {
+	switch f {
+	case FloorBook:
+		return stockData.BookValue
+	case FloorNone:
+		return 0
+	default:
+		return max(stockData.TangibleBookValue, 0)
+	}
+}
+
+// String returns how the floor is described to users
+func (f BookFloor) String() string {
+	switch f {
+	case FloorBook:
+		return "book value"
+	case FloorNone:
+		return "no floor"
+	default:
+		return "tangible book value"
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import "fmt"
+
+// Instrument types, as Yahoo Finance reports them
+const (
+	InstrumentEquity   = "EQUITY"
+	InstrumentETF      = "ETF"
+	InstrumentCrypto   = "CRYPTOCURRENCY"
+	InstrumentFuture   = "FUTURE"   // commodity and index futures, such as GC=F
+	InstrumentCurrency = "CURRENCY" // currency pairs and spot metals, such as EURUSD=X
+)
+
+// priceOnlyInstruments name the instrument types that have no cash flows
+// or earnings to value, by what they are called in messages
+var priceOnlyInstruments = map[string]string{
+	InstrumentCrypto:   "a cryptocurrency",
+	InstrumentFuture:   "a futures contract",
+	InstrumentCurrency: "a currency",
+}
+
+// PriceOnly reports whether s is an instrument, such as a cryptocurrency
+// or a commodity future, that DCF and Comps cannot value, so only its
+// price is fetched and shown
+func (s *StockData) PriceOnly() bool {
+	_, ok := priceOnlyInstruments[s.InstrumentType]
+	return ok
+}
+
+// NewNotValuableResult returns the result of a price-only instrument: its
+// price and moving averages, with status StatusNotValuable and no fair value
+func NewNotValuableResult(stockData *StockData) *ValuationResult {
+	return &ValuationResult{
+		Ticker:       stockData.Ticker,
+		CurrentPrice: stockData.CurrentPrice,
+		Status:       StatusNotValuable,
+		CompanyName:  stockData.CompanyName,
+		Tag:          stockData.Tag,
+		Incomplete:   stockData.Incomplete,
+		Error:        fmt.Sprintf("%s is %s, not valuable via DCF/Comps", stockData.Ticker, priceOnlyInstruments[stockData.InstrumentType]),
+		Inputs:       stockData.Snapshot(),
+	}
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MoneyScale is the number of Money units in one currency unit: amounts
+// are exact to four decimal places, enough for sub-cent quotes
+const MoneyScale = 10000
+
+// moneyDigits is the number of decimal places of Money
+const moneyDigits = 4
+
+// Money is an exact decimal amount, such as a price or a per-share value,
+// held as a whole number of ten-thousandths. Sums and differences of Money
+// are exact, so values do not pick up binary floating point artifacts
+// such as 0.30000000000000004; products and quotients are rounded to the
+// nearest ten-thousandth, halves away from zero.
+type Money int64
+
+// MoneyFromFloat returns the amount nearest to f
+func MoneyFromFloat(f float64) Money {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return Money(math.Round(f * MoneyScale))
+}
+
+// ParseMoney parses a decimal amount such as "123.45" or "-0.5" exactly,
+// rounding digits beyond the fourth decimal place
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if strings.Trim(s, "+-.") == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	whole, fraction, _ := strings.Cut(s, ".")
+	negative := strings.HasPrefix(whole, "-")
+	if whole == "" || whole == "-" || whole == "+" {
+		whole += "0"
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || strings.ContainsAny(fraction, "+-") {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	var frac int64
+	for i := 0; i <= moneyDigits; i++ {
+		digit := int64(0)
+		if i < len(fraction) {
+			if fraction[i] < '0' || fraction[i] > '9' {
+				return 0, fmt.Errorf("invalid amount %q", s)
+			}
+			digit = int64(fraction[i] - '0')
+		}
+		if i == moneyDigits {
+			if digit >= 5 {
+				frac++
+			}
+			break
+		}
+		frac = frac*10 + digit
+	}
+	if units > math.MaxInt64/MoneyScale || units < math.MinInt64/MoneyScale {
+		return 0, fmt.Errorf("amount %q out of range", s)
+	}
+	amount := Money(units) * MoneyScale
+	if negative {
+		return amount - Money(frac), nil
+	}
+	return amount + Money(frac), nil
+}
+
+// Float returns m as a float64, for the math-heavy steps of valuation
+func (m Money) Float() float64 {
+	return float64(m) / MoneyScale
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+// Mul returns m times other, rounded
+func (m Money) Mul(other Money) Money {
+	return divRound(int64(m)*int64(other), MoneyScale)
+}
+
+// Div returns m divided by other, rounded, or 0 when other is 0
+func (m Money) Div(other Money) Money {
+	if other == 0 {
+		return 0
+	}
+	return divRound(int64(m)*MoneyScale, int64(other))
+}
+
+// Max returns the larger of m and other
+func (m Money) Max(other Money) Money {
+	return max(m, other)
+}
+
+// String writes m with two decimal places, or as many as it needs up to
+// four, such as "12.50" or "0.0125"
+func (m Money) String() string {
+	sign := ""
+	units := int64(m)
+	if units < 0 {
+		sign, units = "-", -units
+	}
+	fraction := strings.TrimRight(fmt.Sprintf("%04d", units%MoneyScale), "0")
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+	return fmt.Sprintf("%s%d.%s", sign, units/MoneyScale, fraction)
+}
+
+// MarshalJSON writes m as a JSON number with its exact decimal digits
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or numeric string exactly
+func (m *Money) UnmarshalJSON(data []byte) error {
+	amount, err := ParseMoney(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*m = amount
+	return nil
+}
+
+// RoundMoney returns the float nearest to f rounded to a Money amount,
+// which formats without binary floating point artifacts
+func RoundMoney(f float64) float64 {
+	return MoneyFromFloat(f).Float()
+}
+
+// divRound returns n / d rounded to the nearest integer, halves away from
+// zero
+func divRound(n, d int64) Money {
+	negative := (n < 0) != (d < 0)
+	if n < 0 {
+		n = -n
+	}
+	if d < 0 {
+		d = -d
+	}
+	q := n / d
+	if 2*(n%d) >= d {
+		q++
+	}
+	if negative {
+		q = -q
+	}
+	return Money(q)
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxRating is the highest rating a note can give a ticker
+const MaxRating = 5
+
+// Note is an entry of the research journal kept in the history database:
+// a remark on a ticker, such as "value trap - pension liabilities", with
+// an optional rating
+type Note struct {
+	ID     int64     `json:"id,omitempty"`
+	Ticker string    `json:"ticker"`
+	Text   string    `json:"text,omitempty"`
+	Rating int       `json:"rating,omitempty"` // 1 to MaxRating, 0 for none
+	At     time.Time `json:"at"`
+}
+
+// Summary returns the rating and text of n, such as "2/5 value trap"
+func (n *Note) Summary() string {
+	var parts []string
+	if n.Rating > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d", n.Rating, MaxRating))
+	}
+	if n.Text != "" {
+		parts = append(parts, n.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// LatestNote sums up the notes of a ticker, oldest first, in one: the text
+// of the latest note with a text and the rating of the latest with a
+// rating, so re-checking a stock without rating it again keeps its rating.
+// It returns nil when there are no notes.
+func LatestNote(notes []Note) *Note {
+	if len(notes) == 0 {
+		return nil
+	}
+	latest := notes[len(notes)-1]
+	for i := len(notes) - 1; i >= 0 && (latest.Text == "" || latest.Rating == 0); i-- {
+		if latest.Text == "" {
+			latest.Text = notes[i].Text
+		}
+		if latest.Rating == 0 {
+			latest.Rating = notes[i].Rating
+		}
+	}
+	return &latest
+}
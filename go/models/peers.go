@@ -0,0 +1,40 @@
+package models
+
+// What a peer group has in common with the stock
+const (
+	PeerBasisIndustry = "industry"
+	PeerBasisSector   = "sector"
+)
+
+// PeerGroup is the group of comparable companies of the analyzed universe
+// whose median P/E a stock's Comps value is anchored on, or for a REIT
+// their median P/FFO
+type PeerGroup struct {
+	Basis      string  `json:"basis"`                 // PeerBasisIndustry or PeerBasisSector
+	MedianPE   float64 `json:"median_pe"`             // of the peers' trailing P/E ratios
+	MedianPFFO float64 `json:"median_pffo,omitempty"` // of the P/FFO of the peers that are REITs with FFO, when enough are
+	Peers      []Peer  `json:"peers"`                 // closest in market cap first
+}
+
+// Peer is a company of a peer group, with the figures it was chosen on
+type Peer struct {
+	Ticker      string  `json:"ticker"`
+	CompanyName string  `json:"company_name,omitempty"`
+	PERatio     float64 `json:"pe_ratio"`
+	PFFO        float64 `json:"pffo,omitempty"` // price over FFO per share, of REITs
+	GrowthRate  float64 `json:"growth_rate"`
+	MarketCap   int64   `json:"market_cap"`
+
+	// Upside is the peer's own upside to its fair value, in percent, so
+	// the anchor can be judged against how the peers themselves are valued
+	Upside float64 `json:"upside_percentage"`
+}
+
+// Tickers returns the tickers of the peers
+func (g *PeerGroup) Tickers() []string {
+	tickers := make([]string, len(g.Peers))
+	for i, peer := range g.Peers {
+		tickers[i] = peer.Ticker
+	}
+	return tickers
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Sentiment is the tone of recent news about a company
+type Sentiment struct {
+	Source    string    `json:"source"`   // such as "finnhub"
+	Score     float64   `json:"score"`    // from -1, every article bearish, to 1, every article bullish
+	Articles  int       `json:"articles"` // articles of the last week the score is read from
+	FetchTime time.Time `json:"fetch_time"`
+}
+
+// SentimentParameters set how far extreme news sentiment moves fair values.
+// The zero value leaves them as they are.
+type SentimentParameters struct {
+	// Threshold is the score, in either direction, beyond which sentiment
+	// is extreme
+	Threshold float64 `json:"threshold"`
+	// Adjustment is the fraction fair values are lowered by when sentiment
+	// is extremely bearish, and raised by when it is extremely bullish
+	Adjustment float64 `json:"adjustment"`
+}
+
+// Adjust returns the fraction a fair value is moved by for sentiment: minus
+// or plus the adjustment beyond the threshold, and 0 otherwise or when
+// sentiment is unknown
+func (p SentimentParameters) Adjust(sentiment *Sentiment) float64 {
+	if sentiment == nil || p.Adjustment <= 0 || p.Threshold <= 0 {
+		return 0
+	}
+	switch {
+	case sentiment.Score <= -p.Threshold:
+		return -p.Adjustment
+	case sentiment.Score >= p.Threshold:
+		return p.Adjustment
+	}
+	return 0
+}
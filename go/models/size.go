@@ -0,0 +1,49 @@
+package models
+
+// SizeTier is the premium fair values of companies no larger than
+// MaxMarketCap are discounted by, for the extra return investors ask of
+// small companies
+type SizeTier struct {
+	MaxMarketCap int64   `json:"max_market_cap"`
+	Premium      float64 `json:"premium"` // fraction fair values are lowered by
+}
+
+// SizeParameters set how far fair values of small and thinly traded
+// companies are discounted. The zero value leaves them as they are.
+type SizeParameters struct {
+	// Tiers are the size premiums by market cap; the smallest tier a
+	// company fits in applies
+	Tiers []SizeTier `json:"tiers,omitempty"`
+
+	// MinDollarVolume is the average value traded a day, in the trading
+	// currency, below which a stock is illiquid
+	MinDollarVolume float64 `json:"min_dollar_volume,omitempty"`
+
+	// IlliquidityHaircut is the fraction fair values of illiquid stocks
+	// are lowered by
+	IlliquidityHaircut float64 `json:"illiquidity_haircut,omitempty"`
+}
+
+// Discount returns the size premium and the illiquidity haircut the fair
+// value of stockData is lowered by, each 0 when it does not apply or the
+// market cap or volume it depends on is unknown
+func (p SizeParameters) Discount(stockData *StockData) (premium, haircut float64) {
+	if stockData.MarketCap > 0 {
+		var smallest int64
+		for _, tier := range p.Tiers {
+			if stockData.MarketCap <= tier.MaxMarketCap && (smallest == 0 || tier.MaxMarketCap < smallest) {
+				smallest, premium = tier.MaxMarketCap, tier.Premium
+			}
+		}
+	}
+	if volume := stockData.AverageDollarVolume(); volume > 0 && volume < p.MinDollarVolume {
+		haircut = p.IlliquidityHaircut
+	}
+	return premium, haircut
+}
+
+// AverageDollarVolume returns the value of the shares traded on an average
+// day at the current price, or 0 when the volume is unknown
+func (s *StockData) AverageDollarVolume() float64 {
+	return float64(s.AverageVolume) * s.CurrentPrice
+}
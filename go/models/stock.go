@@ -1,20 +1,298 @@
 package models
 
-import "time"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
 
 // StockData represents comprehensive stock information
 type StockData struct {
-	Ticker        string    `json:"ticker"`
-	CompanyName   string    `json:"company_name"`
-	CurrentPrice  float64   `json:"current_price"`
-	FCFPerShare   float64   `json:"fcf_per_share"`
-	EPS           float64   `json:"eps"`
-	BookValue     float64   `json:"book_value"`
-	Sector        string    `json:"sector"`
-	GrowthRate    float64   `json:"growth_rate"`
-	PERatio       float64   `json:"pe_ratio"`
-	MarketCap     int64     `json:"market_cap"`
-	FetchTime     time.Time `json:"fetch_time"`
+	Ticker            string    `json:"ticker"`
+	CompanyName       string    `json:"company_name"`
+	CurrentPrice      float64   `json:"current_price"`
+	FCFPerShare       float64   `json:"fcf_per_share"`
+	EPS               float64   `json:"eps"`
+	BookValue         float64   `json:"book_value"`
+	Sector            string    `json:"sector"`
+	Industry          string    `json:"industry,omitempty"`
+	GrowthRate        float64   `json:"growth_rate"`
+	PERatio           float64   `json:"pe_ratio"`
+	PERatioEstimated  bool      `json:"pe_ratio_estimated,omitempty"` // P/E is a stand-in, such as the industry average, not the stock's own
+	MarketCap         int64     `json:"market_cap"`
+	SharesOutstanding int64     `json:"shares_outstanding"`
+	DividendPerShare  float64   `json:"dividend_per_share"`  // annual dividend rate
+	PayoutRatio       float64   `json:"payout_ratio,omitempty"` // trailing dividends over earnings, as a fraction
+	Beta              float64   `json:"beta"`
+	TotalDebt         float64   `json:"total_debt"`
+	Cash              float64   `json:"cash"`                // cash and short-term investments
+	EBITDA            float64   `json:"ebitda"`
+	Revenue           float64   `json:"revenue"`             // trailing twelve months
+	TangibleBookValue float64   `json:"tangible_book_value"` // per share
+	FFOPerShare       float64   `json:"ffo_per_share,omitempty"` // funds from operations of REITs, trailing twelve months
+	ReturnOnEquity    float64   `json:"return_on_equity,omitempty"`    // trailing net income over equity, as a fraction
+	NetInterestMargin float64   `json:"net_interest_margin,omitempty"` // of financials: trailing net interest income over total assets, as a fraction
+	MovingAverage50   float64   `json:"moving_average_50,omitempty"`  // of daily closes
+	MovingAverage200  float64   `json:"moving_average_200,omitempty"` // of daily closes
+	AverageVolume     int64     `json:"average_volume,omitempty"`     // shares traded a day, over the last 50 sessions
+	Currency          string    `json:"currency"`            // ISO 4217 code of the trading price
+	Exchange          string    `json:"exchange,omitempty"`  // exchange code, such as "NMS" for Nasdaq
+	InstrumentType    string    `json:"instrument_type,omitempty"` // such as InstrumentEquity or InstrumentCrypto
+	Country           string    `json:"country,omitempty"`   // home country of the company, such as "Taiwan"
+	Tag               string    `json:"tag,omitempty"`       // custom tag from the ticker file, for grouping
+	ExchangeTimezone  string    `json:"exchange_timezone,omitempty"` // IANA timezone the times below are reported in
+	RegularMarketTime time.Time `json:"regular_market_time,omitzero"` // time of the last regular session trade
+	EarningsDate      time.Time `json:"earnings_date,omitzero"` // next earnings report, or the start of the window it is expected in
+	TTMAsOf           time.Time `json:"ttm_as_of,omitzero"`     // end of the latest quarter EPS and FCF per share are summed to
+	FetchTime         time.Time `json:"fetch_time"`
+	Incomplete        bool      `json:"incomplete,omitempty"` // the ticker timeout passed before every source answered
+	Splits            []Split   `json:"splits,omitempty"`     // splits the figures were fetched before and adjusted for
+
+	// FallbackFields are the valuation inputs, by JSON name, that hold
+	// fallback or default figures rather than ones a source reported
+	FallbackFields []string `json:"fallback_fields,omitempty"`
+
+	// ShareCounts is the yearly history of the diluted share count, oldest
+	// first, when buybacks are part of the DCF growth rate
+	ShareCounts []ShareCount `json:"share_counts,omitempty"`
+
+	// RejectedGrowth are the growth rates found by the sources but left out
+	// of the consensus growth rate as outliers
+	RejectedGrowth []RejectedGrowth `json:"rejected_growth,omitempty"`
+
+	// GrowthSources are the rates the consensus growth rate was weighted
+	// from, one per source, and GrowthStdDev their standard deviation, so
+	// a tight consensus can be told from a noisy one
+	GrowthSources []GrowthEstimate `json:"growth_sources,omitempty"`
+	GrowthStdDev  float64          `json:"growth_std_dev,omitempty"`
+
+	// Peers is the peer group whose median P/E the Comps value is anchored
+	// on in place of the stock's own P/E, when the analyzed universe holds
+	// enough comparable companies
+	Peers *PeerGroup `json:"peers,omitempty"`
+
+	// Sentiment is the tone of recent news about the company, when news
+	// sentiment is enabled and the source knows it
+	Sentiment *Sentiment `json:"sentiment,omitempty"`
+
+	// FieldTimes records when each field was last set, keyed by its JSON name
+	FieldTimes map[string]time.Time `json:"field_times,omitempty"`
+}
+
+// Snapshot returns a copy of the data unaffected by later changes to s
+func (s *StockData) Snapshot() *StockData {
+	snapshot := *s
+	snapshot.FieldTimes = maps.Clone(s.FieldTimes)
+	snapshot.Splits = slices.Clone(s.Splits)
+	snapshot.FallbackFields = slices.Clone(s.FallbackFields)
+	snapshot.RejectedGrowth = slices.Clone(s.RejectedGrowth)
+	snapshot.GrowthSources = slices.Clone(s.GrowthSources)
+	snapshot.ShareCounts = slices.Clone(s.ShareCounts)
+	if s.Peers != nil {
+		peers := *s.Peers
+		peers.Peers = slices.Clone(s.Peers.Peers)
+		snapshot.Peers = &peers
+	}
+	if s.Sentiment != nil {
+		sentiment := *s.Sentiment
+		snapshot.Sentiment = &sentiment
+	}
+	return &snapshot
+}
+
+// Why growth rates were rejected
+const (
+	OutlierOnPage        = "outlier_on_page"        // far from the other rates on the same page
+	OutlierAcrossSources = "outlier_across_sources" // far from the rates of the other sources
+)
+
+// RejectedGrowth is a growth rate left out of the consensus
+type RejectedGrowth struct {
+	Source string  `json:"source"`
+	Rate   float64 `json:"rate"`
+	Reason string  `json:"reason"` // OutlierOnPage or OutlierAcrossSources
+}
+
+// GrowthEstimate is the growth rate of one source of the consensus
+type GrowthEstimate struct {
+	Source     string  `json:"source"`
+	Rate       float64 `json:"rate"`
+	Confidence float64 `json:"confidence"` // weight in the consensus, 0-1
+}
+
+// TickerInfo is a ticker of the universe with the hints its ticker file
+// gives about it. Hints other than the tag only fill in what the data
+// sources leave out.
+type TickerInfo struct {
+	Ticker   string `json:"ticker"`
+	Exchange string `json:"exchange,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Sector   string `json:"sector,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+}
+
+// HasHints reports whether the ticker file says anything about the ticker
+// beyond its symbol
+func (t TickerInfo) HasHints() bool {
+	return t.Exchange != "" || t.Country != "" || t.Sector != "" || t.Tag != ""
+}
+
+// Apply fills in the exchange, country and sector of stockData the data
+// sources left out, and sets its tag
+func (t TickerInfo) Apply(stockData *StockData) {
+	if stockData.Exchange == "" {
+		stockData.Exchange = t.Exchange
+	}
+	if stockData.Country == "" {
+		stockData.Country = t.Country
+	}
+	if stockData.Sector == "" {
+		stockData.Sector = NormalizeSector(t.Sector)
+	}
+	if t.Tag != "" {
+		stockData.Tag = t.Tag
+	}
+}
+
+// Split is a stock split, or a stock dividend paid in shares, taking
+// effect on Date
+type Split struct {
+	Date  time.Time `json:"date"`
+	Ratio float64   `json:"ratio"` // shares after per share before, e.g. 10 for a 10-for-1 split
+}
+
+// String formats the split as "10-for-1 on 2024-06-10"
+func (s Split) String() string {
+	ratio := strconv.FormatFloat(s.Ratio, 'f', -1, 64) + "-for-1"
+	if s.Ratio < 1 {
+		ratio = "1-for-" + strconv.FormatFloat(1/s.Ratio, 'f', -1, 64)
+	}
+	return ratio + " on " + s.Date.Format(time.DateOnly)
+}
+
+// AdjustForSplit adjusts figures set before split took effect for it: the
+// per-share figures are divided by its ratio and the share count is
+// multiplied by it, and the adjusted fields are stamped with at. It
+// reports whether the split was applied; one already adjusted for is not
+// applied again.
+func (s *StockData) AdjustForSplit(split Split, at time.Time) bool {
+	if split.Ratio <= 0 || split.Ratio == 1 {
+		return false
+	}
+	for _, applied := range s.Splits {
+		if applied.Date.Equal(split.Date) {
+			return false
+		}
+	}
+
+	var changed []string
+	adjust := func(field string, value *float64) {
+		if *value != 0 {
+			*value /= split.Ratio
+			changed = append(changed, field)
+		}
+	}
+	adjust("fcf_per_share", &s.FCFPerShare)
+	adjust("eps", &s.EPS)
+	adjust("book_value", &s.BookValue)
+	adjust("dividend_per_share", &s.DividendPerShare)
+	adjust("tangible_book_value", &s.TangibleBookValue)
+	adjust("ffo_per_share", &s.FFOPerShare)
+	adjust("moving_average_50", &s.MovingAverage50)
+	adjust("moving_average_200", &s.MovingAverage200)
+	if s.SharesOutstanding != 0 {
+		s.SharesOutstanding = int64(math.Round(float64(s.SharesOutstanding) * split.Ratio))
+		changed = append(changed, "shares_outstanding")
+	}
+	if s.AverageVolume != 0 {
+		s.AverageVolume = int64(math.Round(float64(s.AverageVolume) * split.Ratio))
+		changed = append(changed, "average_volume")
+	}
+	for i := range s.ShareCounts {
+		s.ShareCounts[i].Shares = int64(math.Round(float64(s.ShareCounts[i].Shares) * split.Ratio))
+	}
+
+	s.Splits = append(s.Splits, split)
+	if len(changed) > 0 {
+		s.Stamp(at, changed...)
+	}
+	return true
+}
+
+// Stamp records at as the time the named fields were set
+func (s *StockData) Stamp(at time.Time, fields ...string) {
+	if s.FieldTimes == nil {
+		s.FieldTimes = make(map[string]time.Time)
+	}
+	for _, field := range fields {
+		s.FieldTimes[field] = at
+	}
+}
+
+// StampChanged records at as the time every field that differs from
+// before was set
+func (s *StockData) StampChanged(before *StockData, at time.Time) {
+	var changed []string
+	add := func(field string, differs bool) {
+		if differs {
+			changed = append(changed, field)
+		}
+	}
+	add("company_name", s.CompanyName != before.CompanyName)
+	add("current_price", s.CurrentPrice != before.CurrentPrice)
+	add("fcf_per_share", s.FCFPerShare != before.FCFPerShare)
+	add("eps", s.EPS != before.EPS)
+	add("book_value", s.BookValue != before.BookValue)
+	add("sector", s.Sector != before.Sector)
+	add("industry", s.Industry != before.Industry)
+	add("growth_rate", s.GrowthRate != before.GrowthRate)
+	add("pe_ratio", s.PERatio != before.PERatio)
+	add("market_cap", s.MarketCap != before.MarketCap)
+	add("shares_outstanding", s.SharesOutstanding != before.SharesOutstanding)
+	add("dividend_per_share", s.DividendPerShare != before.DividendPerShare)
+	add("payout_ratio", s.PayoutRatio != before.PayoutRatio)
+	add("beta", s.Beta != before.Beta)
+	add("total_debt", s.TotalDebt != before.TotalDebt)
+	add("cash", s.Cash != before.Cash)
+	add("ebitda", s.EBITDA != before.EBITDA)
+	add("revenue", s.Revenue != before.Revenue)
+	add("tangible_book_value", s.TangibleBookValue != before.TangibleBookValue)
+	add("ffo_per_share", s.FFOPerShare != before.FFOPerShare)
+	add("return_on_equity", s.ReturnOnEquity != before.ReturnOnEquity)
+	add("net_interest_margin", s.NetInterestMargin != before.NetInterestMargin)
+	add("moving_average_50", s.MovingAverage50 != before.MovingAverage50)
+	add("moving_average_200", s.MovingAverage200 != before.MovingAverage200)
+	add("average_volume", s.AverageVolume != before.AverageVolume)
+	add("currency", s.Currency != before.Currency)
+	add("exchange", s.Exchange != before.Exchange)
+	add("country", s.Country != before.Country)
+	add("instrument_type", s.InstrumentType != before.InstrumentType)
+	add("earnings_date", !s.EarningsDate.Equal(before.EarningsDate))
+	add("ttm_as_of", !s.TTMAsOf.Equal(before.TTMAsOf))
+	if len(changed) > 0 {
+		s.Stamp(at, changed...)
+	}
+}
+
+// In reports the times of s in loc, such as its exchange's timezone
+func (s *StockData) In(loc *time.Location) {
+	s.FetchTime = s.FetchTime.In(loc)
+	if !s.RegularMarketTime.IsZero() {
+		s.RegularMarketTime = s.RegularMarketTime.In(loc)
+	}
+	if !s.EarningsDate.IsZero() {
+		s.EarningsDate = s.EarningsDate.In(loc)
+	}
+	for field, at := range s.FieldTimes {
+		s.FieldTimes[field] = at.In(loc)
+	}
 }
 
 // ValuationResult represents the result of stock valuation
@@ -37,6 +315,187 @@ type ValuationResult struct {
 	Sector             string  `json:"sector"`
 	GrowthRate         float64 `json:"growth_rate"`
 	CompanyName        string  `json:"company_name"`
+	Tag                string  `json:"tag,omitempty"`        // custom tag from the ticker file
+	Incomplete         bool    `json:"incomplete,omitempty"` // valued on the data fetched before the ticker timeout
+	Error              string  `json:"error,omitempty"`      // why the ticker could not be valued, when it failed
+
+	// Violations are the sanity checks the inputs failed, when the
+	// configuration flags rather than rejects inconsistent data
+	Violations []Violation `json:"violations,omitempty"`
+
+	// Relative relates the multiple and upside to the market benchmark,
+	// when its P/E could be fetched
+	Relative *MarketRelative `json:"relative,omitempty"`
+
+	// ADRCosts are the withholding tax and depositary fees taken from the
+	// dividends of ADRs, which the net dividend yield is read after
+	ADRCosts *ADRCosts `json:"adr_costs,omitempty"`
+
+	// SentimentAdjustment is the fraction the fair value was lowered or
+	// raised by for extreme news sentiment, before the book value floor
+	SentimentAdjustment float64 `json:"sentiment_adjustment,omitempty"`
+
+	// SizePremium and IlliquidityHaircut are the fractions the fair value
+	// was lowered by for a small market cap and a thin trading volume,
+	// before the book value floor
+	SizePremium        float64 `json:"size_premium,omitempty"`
+	IlliquidityHaircut float64 `json:"illiquidity_haircut,omitempty"`
+
+	// GrowthSourceCount is the number of sources the consensus growth rate
+	// was weighted from and GrowthStdDev the standard deviation of their
+	// rates; neither is set for a fallback or default growth rate
+	GrowthSourceCount int     `json:"growth_source_count,omitempty"`
+	GrowthStdDev      float64 `json:"growth_std_dev,omitempty"`
+
+	// Revision is how the growth estimate moved since an earlier recorded
+	// run, when the history holds one from the revision window
+	Revision *EstimateRevision `json:"revision,omitempty"`
+
+	// Note is the latest of the notes kept on the ticker when the result
+	// was made; see LatestNote
+	Note *Note `json:"note,omitempty"`
+
+	// Inputs is the data the result was calculated from, so it can be
+	// re-derived and audited later
+	Inputs *StockData `json:"inputs,omitempty"`
+}
+
+// Violation is a sanity check the fetched data of a stock failed
+type Violation struct {
+	Check   string `json:"check"` // such as "pe_ratio" or "market_cap"
+	Message string `json:"message"`
+}
+
+// String formats v as "check: message"
+func (v Violation) String() string {
+	return v.Check + ": " + v.Message
+}
+
+// NewErrorResult returns the result of a ticker that could not be valued
+// for reason
+func NewErrorResult(ticker, reason string) *ValuationResult {
+	return &ValuationResult{Ticker: ticker, Status: StatusError, Error: reason}
+}
+
+// Failed reports whether the ticker could not be valued, for an error,
+// insufficient data or an instrument DCF and Comps do not apply to, in
+// which case Error holds the reason and the figures other than the price
+// of such an instrument are zero
+func (r *ValuationResult) Failed() bool {
+	return r.Status == StatusError || r.Status == StatusInsufficientData || r.Status == StatusNotValuable
+}
+
+// MovingAverage returns the 50- or 200-day moving average of the price the
+// result was valued on, and false when it is unknown
+func (r *ValuationResult) MovingAverage(days int) (float64, bool) {
+	if r.Inputs == nil {
+		return 0, false
+	}
+	var average float64
+	switch days {
+	case 50:
+		average = r.Inputs.MovingAverage50
+	case 200:
+		average = r.Inputs.MovingAverage200
+	}
+	return average, average > 0
+}
+
+// PriceVsMovingAverage returns how far the current price is above its 50-
+// or 200-day moving average in percent, negative when below, and false
+// when the average is unknown
+func (r *ValuationResult) PriceVsMovingAverage(days int) (float64, bool) {
+	average, ok := r.MovingAverage(days)
+	if !ok || r.CurrentPrice <= 0 {
+		return 0, false
+	}
+	return (r.CurrentPrice/average - 1) * 100, true
+}
+
+// FCFYield returns the free cash flow per share as a percentage of the
+// price, and false when the price is unknown
+func (r *ValuationResult) FCFYield() (float64, bool) {
+	if r.CurrentPrice <= 0 {
+		return 0, false
+	}
+	return r.FCFPerShare / r.CurrentPrice * 100, true
+}
+
+// EarningsYield returns the earnings per share as a percentage of the
+// price, the inverse of the P/E, and false when the price is unknown.
+// Loss-making stocks have a negative earnings yield.
+func (r *ValuationResult) EarningsYield() (float64, bool) {
+	if r.CurrentPrice <= 0 {
+		return 0, false
+	}
+	return r.EPS / r.CurrentPrice * 100, true
+}
+
+// DividendYield returns the annual dividend per share as a percentage of
+// the price, zero for stocks paying none, and false when the price or the
+// inputs are unknown
+func (r *ValuationResult) DividendYield() (float64, bool) {
+	if r.Inputs == nil || r.CurrentPrice <= 0 {
+		return 0, false
+	}
+	return r.Inputs.DividendPerShare / r.CurrentPrice * 100, true
+}
+
+// NetDividendYield returns the dividend yield a holder receives, after
+// the withholding tax and depositary fees of ADRs; for other stocks it is
+// the dividend yield
+func (r *ValuationResult) NetDividendYield() (float64, bool) {
+	if r.Inputs == nil || r.CurrentPrice <= 0 {
+		return 0, false
+	}
+	return r.ADRCosts.NetDividend(r.Inputs.DividendPerShare) / r.CurrentPrice * 100, true
+}
+
+// PayoutRatio returns the share of earnings paid out as dividends in
+// percent, zero for stocks paying none. The ratio fetched with the data is
+// preferred; otherwise it is the dividend over the EPS, which is false for
+// loss-making stocks, as is a result without inputs.
+func (r *ValuationResult) PayoutRatio() (float64, bool) {
+	switch {
+	case r.Inputs == nil:
+		return 0, false
+	case r.Inputs.DividendPerShare == 0:
+		return 0, true
+	case r.Inputs.PayoutRatio > 0:
+		return r.Inputs.PayoutRatio * 100, true
+	case r.Inputs.EPS > 0:
+		return r.Inputs.DividendPerShare / r.Inputs.EPS * 100, true
+	}
+	return 0, false
+}
+
+// SentimentScore returns the news sentiment score the result was valued
+// with, from -1 to 1, and false when it is unknown
+func (r *ValuationResult) SentimentScore() (float64, bool) {
+	if r.Inputs == nil || r.Inputs.Sentiment == nil {
+		return 0, false
+	}
+	return r.Inputs.Sentiment.Score, true
+}
+
+// EstimateRevision is how the consensus growth estimate of a stock moved
+// between an earlier run and this one. Upward revisions tell a cheap stock
+// whose prospects improve from a value trap whose estimates keep falling.
+type EstimateRevision struct {
+	PreviousGrowthRate float64   `json:"previous_growth_rate"`
+	Change             float64   `json:"change"` // growth rate less the previous one, as a fraction
+	Since              time.Time `json:"since"`  // when the previous estimate was fetched
+}
+
+// NewEstimateRevision returns the revision of the growth estimate of r
+// since previous, the data of an earlier run. The change is rounded to
+// millionths, so equal estimates do not differ by floating point noise.
+func NewEstimateRevision(r *ValuationResult, previous *StockData) *EstimateRevision {
+	return &EstimateRevision{
+		PreviousGrowthRate: previous.GrowthRate,
+		Change:             math.Round((r.GrowthRate-previous.GrowthRate)*1e6) / 1e6,
+		Since:              previous.FetchTime,
+	}
 }
 
 // IndustryPERatio represents P/E ratios by industry
@@ -61,6 +520,12 @@ type DCFParameters struct {
 	TerminalGrowthRate   float64 `json:"terminal_growth_rate"`
 	MaxGrowthRate        float64 `json:"max_growth_rate"`
 	ProjectionYears      int     `json:"projection_years"`
+
+	// IncludeBuybacks adds the net buyback yield, the yearly rate the
+	// share count shrinks by, to the growth rate of per-share FCF, bounded
+	// by MaxBuybackYield in either direction
+	IncludeBuybacks bool    `json:"include_buybacks,omitempty"`
+	MaxBuybackYield float64 `json:"max_buyback_yield,omitempty"`
 }
 
 // CompsParameters represents parameters for comparable analysis
@@ -76,9 +541,137 @@ type ValuationWeights struct {
 	CompsWeight float64 `json:"comps_weight"`
 }
 
+// DataFeatures controls which data acquisition capabilities a fetcher may use
+type DataFeatures struct {
+	EnableYahooAPI        bool `json:"enable_yahoo_api"`
+	EnableScraping        bool `json:"enable_scraping"`
+	EnableGrowthConsensus bool `json:"enable_growth_consensus"`
+	EnableFallbackData    bool `json:"enable_fallback_data"`
+}
+
+// Run represents the results of a single analysis pass
+type Run struct {
+	ID         string             `json:"id"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+	PricesOnly bool               `json:"prices_only,omitempty"` // fundamentals reused from an earlier pass
+	Partial    bool               `json:"partial,omitempty"`     // interrupted before every ticker was valued
+	Job        string             `json:"job,omitempty"`         // scheduled job that produced the run
+	Tags       []string           `json:"tags,omitempty"`        // workflows the run belongs to, such as "weekly-screen"
+	Version    string             `json:"version,omitempty"`     // build of the tool that produced the run
+	ConfigHash string             `json:"config_hash,omitempty"` // hash of the complete configuration
+	Config     json.RawMessage    `json:"config,omitempty"`      // model parameters the run was valued with
+	Benchmark  *Benchmark         `json:"benchmark,omitempty"`   // market the results are related to
+	Results    []*ValuationResult `json:"results"`
+	Errors     map[string]string  `json:"errors,omitempty"` // failure reason per ticker
+
+	// DegradedScrapers are the scraped sources whose pages yielded nothing
+	// for a known ticker, likely after a layout change, when the run started
+	DegradedScrapers []string `json:"degraded_scrapers,omitempty"`
+
+	// Skipped are the tickers left out of the run for failing the runs
+	// before it, until their time on the skip list is up
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Valued returns the number of tickers of the run that were valued
+func (r *Run) Valued() int {
+	valued := 0
+	for _, result := range r.Results {
+		if !result.Failed() {
+			valued++
+		}
+	}
+	return valued
+}
+
+// HasTags reports whether the run is tagged with every one of tags
+func (r *Run) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(r.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRunID returns a unique, time-ordered run identifier
+func NewRunID(startedAt time.Time) string {
+	return NewRunIDFrom(startedAt, rand.Reader)
+}
+
+// NewRunIDFrom returns a time-ordered run identifier whose random part is
+// read from random, so a seeded source gives reproducible IDs
+func NewRunIDFrom(startedAt time.Time, random io.Reader) string {
+	suffix := make([]byte, 4)
+	if _, err := io.ReadFull(random, suffix); err != nil {
+		return startedAt.UTC().Format("20060102T150405.000000000Z")
+	}
+	return fmt.Sprintf("%s-%s", startedAt.UTC().Format("20060102T150405Z"), hex.EncodeToString(suffix))
+}
+
+// Job states of an asynchronous analysis
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is an analysis queued through the API and run in the background.
+// Its results are stored as the run named by RunID once it completes.
+type Job struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Tickers    []string   `json:"tickers,omitempty"`
+	Total      int        `json:"total"`
+	Valued     int        `json:"valued"` // tickers valued so far
+	Failed     int        `json:"failed"` // tickers that failed so far
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	RunID      string     `json:"run_id,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Finished reports whether the job has reached a final state
+func (j *Job) Finished() bool {
+	return j.Status == JobCompleted || j.Status == JobFailed || j.Status == JobCancelled
+}
+
 // Status constants for valuation results
 const (
 	StatusUnderpriced = "Underpriced"
 	StatusOverpriced  = "Overpriced"
 	StatusError       = "Error"
-)
\ No newline at end of file
+
+	// StatusInsufficientData is the status of tickers with too few fetched
+	// valuation inputs to be valued, under the data completeness gate
+	StatusInsufficientData = "InsufficientData"
+
+	// StatusNotValuable is the status of price-only instruments, such as
+	// cryptocurrencies and commodity futures, which have no fair value
+	StatusNotValuable = "NotValuable"
+)
+
+// IsREIT reports whether s is in the real estate sector, whose earnings
+// are depressed by the depreciation of property that tends to hold its
+// value, so funds from operations measure it better than EPS
+func (s *StockData) IsREIT() bool {
+	return NormalizeSector(s.Sector) == SectorRealEstate
+}
+
+// IsFinancial reports whether s is a bank, insurer or other financial
+// company, whose cash flows are those of its balance sheet, so it is
+// valued on the book value its return on equity justifies rather than on
+// free cash flow
+func (s *StockData) IsFinancial() bool {
+	return NormalizeSector(s.Sector) == SectorFinancialServices
+}
+
+// IsEnergy reports whether s is an oil and gas producer, refiner or other
+// energy company, whose free cash flow follows commodity prices
+func (s *StockData) IsEnergy() bool {
+	return NormalizeSector(s.Sector) == SectorEnergy
+}
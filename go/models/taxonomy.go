@@ -0,0 +1,126 @@
+package models
+
+import "strings"
+
+// Sectors of the canonical taxonomy: the eleven GICS sectors, under the
+// names Yahoo Finance gives them. Sector labels of other sources, such as
+// "Information Technology" or "Financials", are normalized to these.
+const (
+	SectorTechnology            = "Technology"
+	SectorHealthcare            = "Healthcare"
+	SectorFinancialServices     = "Financial Services"
+	SectorRealEstate            = "Real Estate"
+	SectorConsumerCyclical      = "Consumer Cyclical"
+	SectorConsumerDefensive     = "Consumer Defensive"
+	SectorEnergy                = "Energy"
+	SectorIndustrials           = "Industrials"
+	SectorBasicMaterials        = "Basic Materials"
+	SectorUtilities             = "Utilities"
+	SectorCommunicationServices = "Communication Services"
+)
+
+// sectorAliases maps sector labels, by their taxonomyKey, to the sectors
+// of the canonical taxonomy
+var sectorAliases = map[string]string{
+	"technology":                 SectorTechnology,
+	"information technology":     SectorTechnology,
+	"tech":                       SectorTechnology,
+	"healthcare":                 SectorHealthcare,
+	"health care":                SectorHealthcare,
+	"financial services":         SectorFinancialServices,
+	"financials":                 SectorFinancialServices,
+	"financial":                  SectorFinancialServices,
+	"finance":                    SectorFinancialServices,
+	"real estate":                SectorRealEstate,
+	"consumer cyclical":          SectorConsumerCyclical,
+	"consumer discretionary":     SectorConsumerCyclical,
+	"consumer defensive":         SectorConsumerDefensive,
+	"consumer staples":           SectorConsumerDefensive,
+	"consumer non-cyclicals":     SectorConsumerDefensive,
+	"energy":                     SectorEnergy,
+	"industrials":                SectorIndustrials,
+	"industrial":                 SectorIndustrials,
+	"basic materials":            SectorBasicMaterials,
+	"materials":                  SectorBasicMaterials,
+	"communication services":     SectorCommunicationServices,
+	"communications":             SectorCommunicationServices,
+	"telecommunication services": SectorCommunicationServices,
+	"telecommunications":         SectorCommunicationServices,
+	"utilities":                  SectorUtilities,
+}
+
+// industrySectors are the sectors of industries, by the start of their
+// taxonomyKey, for stocks whose sources give an industry but no sector
+var industrySectors = []struct {
+	prefix string
+	sector string
+}{
+	{"reit", SectorRealEstate},
+	{"real estate", SectorRealEstate},
+	{"banks", SectorFinancialServices},
+	{"insurance", SectorFinancialServices},
+	{"capital markets", SectorFinancialServices},
+	{"asset management", SectorFinancialServices},
+	{"credit services", SectorFinancialServices},
+	{"oil and gas", SectorEnergy},
+	{"coal", SectorEnergy},
+	{"software", SectorTechnology},
+	{"semiconductor", SectorTechnology},
+	{"computer hardware", SectorTechnology},
+	{"information technology", SectorTechnology},
+	{"biotechnology", SectorHealthcare},
+	{"drug manufacturers", SectorHealthcare},
+	{"medical", SectorHealthcare},
+	{"healthcare", SectorHealthcare},
+	{"utilities", SectorUtilities},
+	{"telecom", SectorCommunicationServices},
+	{"internet content", SectorCommunicationServices},
+	{"entertainment", SectorCommunicationServices},
+}
+
+// NormalizeSector returns the canonical sector of a sector label. Labels
+// outside the taxonomy are returned with their spacing cleaned up, so
+// stocks of the same source still group together.
+func NormalizeSector(label string) string {
+	label = strings.Join(strings.Fields(label), " ")
+	if sector, ok := sectorAliases[taxonomyKey(label)]; ok {
+		return sector
+	}
+	return label
+}
+
+// NormalizeIndustry returns an industry label in one notation: sources
+// separate an industry from its sub-industry with an em dash, as in
+// "Software—Infrastructure", an en dash or a hyphen, which all become " - "
+func NormalizeIndustry(label string) string {
+	label = strings.NewReplacer("—", " - ", "–", " - ").Replace(label)
+	return strings.Join(strings.Fields(label), " ")
+}
+
+// IndustrySector returns the canonical sector of an industry, or "" when
+// it is not known
+func IndustrySector(industry string) string {
+	key := taxonomyKey(NormalizeIndustry(industry))
+	for _, entry := range industrySectors {
+		if strings.HasPrefix(key, entry.prefix) {
+			return entry.sector
+		}
+	}
+	return ""
+}
+
+// Classify normalizes the sector and industry of s to the canonical
+// taxonomy, taking the sector from the industry when no source gave one
+func (s *StockData) Classify() {
+	s.Industry = NormalizeIndustry(s.Industry)
+	s.Sector = NormalizeSector(s.Sector)
+	if s.Sector == "" {
+		s.Sector = IndustrySector(s.Industry)
+	}
+}
+
+// taxonomyKey is label lower-cased, with "&" spelled out, as sources
+// write both "Oil & Gas" and "Oil and Gas"
+func taxonomyKey(label string) string {
+	return strings.ReplaceAll(strings.ToLower(label), "&", "and")
+}
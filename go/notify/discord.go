@@ -0,0 +1,36 @@
+package notify
+
+import "context"
+
+// discordMaxContent is the longest message Discord accepts
+const discordMaxContent = 2000
+
+// Discord posts messages to a Discord webhook
+type Discord struct {
+	url string
+}
+
+// NewDiscord creates a notifier posting to a Discord webhook URL
+func NewDiscord(webhookURL string) (*Discord, error) {
+	if err := validateURL(webhookURL); err != nil {
+		return nil, err
+	}
+	return &Discord{url: webhookURL}, nil
+}
+
+// Name returns the notifier name used in error messages
+func (d *Discord) Name() string {
+	return "discord:" + redactURL(d.url)
+}
+
+// Notify posts msg with the subject in bold above the text, truncated to
+// the length Discord accepts
+func (d *Discord) Notify(ctx context.Context, msg Message) error {
+	content := "**" + msg.Subject + "**\n" + msg.Text
+	if runes := []rune(content); len(runes) > discordMaxContent {
+		content = string(runes[:discordMaxContent-1]) + "…"
+	}
+	return postJSON(ctx, d.url, map[string]string{
+		"content": content,
+	})
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+)
+
+// Email sends messages as email over SMTP: plain text, or with an HTML
+// alternative and attachments when the message has them
+type Email struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// NewEmail creates an email notifier. Authentication is used when a
+// username is configured.
+func NewEmail(cfg config.NotifierConfig) (*Email, error) {
+	if cfg.SMTPHost == "" {
+		return nil, fmt.Errorf("smtp_host is required")
+	}
+	if cfg.From == "" {
+		return nil, fmt.Errorf("from is required")
+	}
+	if len(cfg.To) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	port := cfg.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+
+	return &Email{
+		addr:     net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(port)),
+		host:     cfg.SMTPHost,
+		username: cfg.Username,
+		password: cfg.Password,
+		from:     cfg.From,
+		to:       cfg.To,
+	}, nil
+}
+
+// Name returns the notifier name used in error messages
+func (e *Email) Name() string {
+	return "email:" + e.addr
+}
+
+// Notify sends msg to every recipient. net/smtp has no context support,
+// so a cancelled ctx abandons the send rather than interrupting it.
+func (e *Email) Notify(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if e.username != "" {
+		auth = smtp.PlainAuth("", e.username, e.password, e.host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(e.addr, auth, e.from, e.to, e.compose(msg))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// compose formats msg as an RFC 5322 message
+func (e *Email) compose(msg Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", e.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" && len(msg.Attachments) == 0 {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		b.WriteString(crlf(msg.Text))
+		b.WriteString("\r\n")
+		return b.Bytes()
+	}
+
+	// multipart/mixed holding the bodies and the attachments
+	mixed := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	var body bytes.Buffer
+	alternative := multipart.NewWriter(&body)
+	writeQuotedPrintable(alternative, "text/plain; charset=UTF-8", crlf(msg.Text))
+	if msg.HTML != "" {
+		writeQuotedPrintable(alternative, "text/html; charset=UTF-8", msg.HTML)
+	}
+	alternative.Close()
+
+	part, _ := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	})
+	part.Write(body.Bytes())
+
+	for _, attachment := range msg.Attachments {
+		part, _ := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		writeBase64(part, attachment.Data)
+	}
+	mixed.Close()
+	return b.Bytes()
+}
+
+// writeQuotedPrintable adds a quoted-printable encoded part to w. Writes
+// to an in-memory multipart writer cannot fail.
+func writeQuotedPrintable(w *multipart.Writer, contentType, content string) {
+	part, _ := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	qp := quotedprintable.NewWriter(part)
+	qp.Write([]byte(content))
+	qp.Close()
+}
+
+// writeBase64 writes data base64 encoded in lines of 76 characters
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		io.WriteString(w, encoded[:76]+"\r\n")
+		encoded = encoded[76:]
+	}
+	io.WriteString(w, encoded+"\r\n")
+}
+
+// crlf converts line endings to the CRLF required by email
+func crlf(text string) string {
+	return strings.ReplaceAll(text, "\n", "\r\n")
+}
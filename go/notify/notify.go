@@ -0,0 +1,82 @@
+// Package notify delivers messages to external channels such as webhooks,
+// Slack, Discord and email.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+)
+
+// Message is a notification. Text is plain text; Data is a structured
+// form of the same content for channels that carry JSON. HTML and
+// Attachments are only sent by email.
+type Message struct {
+	Subject     string       `json:"subject"`
+	Text        string       `json:"text"`
+	Data        any          `json:"data,omitempty"`
+	HTML        string       `json:"-"`
+	Attachments []Attachment `json:"-"`
+}
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Notifier delivers messages to one channel
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, msg Message) error
+}
+
+// httpClient is shared by the HTTP-based notifiers
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// New creates a notifier from its configuration
+func New(cfg config.NotifierConfig) (Notifier, error) {
+	switch cfg.Type {
+	case "webhook":
+		return NewWebhook(cfg.URL)
+	case "slack":
+		return NewSlack(cfg.URL)
+	case "discord":
+		return NewDiscord(cfg.URL)
+	case "email":
+		return NewEmail(cfg)
+	case "telegram":
+		return NewTelegram(cfg.Token, cfg.ChatID)
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", cfg.Type)
+	}
+}
+
+// NewAll creates every configured notifier
+func NewAll(cfgs []config.NotifierConfig) ([]Notifier, error) {
+	notifiers := make([]Notifier, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		notifier, err := New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("channel %d: %w", i, err)
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers, nil
+}
+
+// NotifyAll sends msg to every notifier, collecting rather than stopping
+// on errors
+func NotifyAll(ctx context.Context, notifiers []Notifier, msg Message) []error {
+	var errs []error
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+		}
+	}
+	return errs
+}
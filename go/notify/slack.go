@@ -0,0 +1,28 @@
+package notify
+
+import "context"
+
+// Slack posts messages to a Slack incoming webhook
+type Slack struct {
+	url string
+}
+
+// NewSlack creates a notifier posting to a Slack incoming webhook URL
+func NewSlack(webhookURL string) (*Slack, error) {
+	if err := validateURL(webhookURL); err != nil {
+		return nil, err
+	}
+	return &Slack{url: webhookURL}, nil
+}
+
+// Name returns the notifier name used in error messages
+func (s *Slack) Name() string {
+	return "slack:" + redactURL(s.url)
+}
+
+// Notify posts msg with the subject in bold above the text
+func (s *Slack) Notify(ctx context.Context, msg Message) error {
+	return postJSON(ctx, s.url, map[string]string{
+		"text": "*" + msg.Subject + "*\n" + msg.Text,
+	})
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lesnerd/fair-stock-value/go/telegram"
+)
+
+// Telegram sends messages to a Telegram chat through a bot
+type Telegram struct {
+	client *telegram.Client
+	chatID string
+}
+
+// NewTelegram creates a notifier sending to chatID, a numeric chat ID or
+// the @username of a channel, through the bot with token
+func NewTelegram(token, chatID string) (*Telegram, error) {
+	if chatID == "" {
+		return nil, fmt.Errorf("chat_id is required")
+	}
+	client, err := telegram.NewClient(token)
+	if err != nil {
+		return nil, err
+	}
+	return &Telegram{client: client, chatID: chatID}, nil
+}
+
+// Name returns the notifier name used in error messages
+func (t *Telegram) Name() string {
+	return "telegram:" + t.chatID
+}
+
+// Notify sends msg with the subject above the text
+func (t *Telegram) Notify(ctx context.Context, msg Message) error {
+	return t.client.SendMessage(ctx, t.chatID, msg.Subject+"\n\n"+msg.Text)
+}
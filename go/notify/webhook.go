@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Webhook posts messages as JSON to a URL
+type Webhook struct {
+	url string
+}
+
+// NewWebhook creates a notifier posting to rawURL
+func NewWebhook(rawURL string) (*Webhook, error) {
+	if err := validateURL(rawURL); err != nil {
+		return nil, err
+	}
+	return &Webhook{url: rawURL}, nil
+}
+
+// Name returns the notifier name used in error messages
+func (w *Webhook) Name() string {
+	return "webhook:" + redactURL(w.url)
+}
+
+// Notify posts msg as a JSON object with subject, text and data fields
+func (w *Webhook) Notify(ctx context.Context, msg Message) error {
+	return postJSON(ctx, w.url, msg)
+}
+
+// postJSON posts payload to target, treating any non-2xx status as an error
+func postJSON(ctx context.Context, target string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if detail = bytes.TrimSpace(detail); len(detail) > 0 {
+			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, detail)
+		}
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// validateURL checks that rawURL is an absolute HTTP(S) URL
+func validateURL(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("url is required")
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid url %q", rawURL)
+	}
+	return nil
+}
+
+// redactURL drops the path of a URL, which for chat webhooks is a secret
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "invalid"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
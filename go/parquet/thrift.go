@@ -0,0 +1,108 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol field types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the Thrift compact protocol used by Parquet
+// metadata. Only the subset needed to write files is implemented.
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastIDs []int16 // last field ID of each enclosing struct
+	lastID  int16
+}
+
+// fieldHeader writes the header of field id with the given type
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(zigzag(int64(id)))
+	}
+	t.lastID = id
+}
+
+// i32 writes an i32 field
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(zigzag(int64(v)))
+}
+
+// i64 writes an i64 field
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+// string writes a binary field
+func (t *thriftWriter) string(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+// beginStruct starts a struct field, or a struct list element when id is 0
+func (t *thriftWriter) beginStruct(id int16) {
+	if id != 0 {
+		t.fieldHeader(id, thriftStruct)
+	}
+	t.lastIDs = append(t.lastIDs, t.lastID)
+	t.lastID = 0
+}
+
+// endStruct ends the struct started last
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	t.lastID = t.lastIDs[len(t.lastIDs)-1]
+	t.lastIDs = t.lastIDs[:len(t.lastIDs)-1]
+}
+
+// listHeader starts a list field of n elements of type elem
+func (t *thriftWriter) listHeader(id int16, elem byte, n int) {
+	t.fieldHeader(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | elem)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elem)
+	t.varint(uint64(n))
+}
+
+// i32List writes a list field of i32 values
+func (t *thriftWriter) i32List(id int16, values ...int32) {
+	t.listHeader(id, thriftI32, len(values))
+	for _, v := range values {
+		t.varint(zigzag(int64(v)))
+	}
+}
+
+// stringList writes a list field of binary values
+func (t *thriftWriter) stringList(id int16, values ...string) {
+	t.listHeader(id, thriftBinary, len(values))
+	for _, v := range values {
+		t.varint(uint64(len(v)))
+		t.buf.WriteString(v)
+	}
+}
+
+// varint writes v as an unsigned LEB128 varint
+func (t *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	t.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+// zigzag maps signed integers to unsigned ones with small magnitudes first
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
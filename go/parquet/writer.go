@@ -0,0 +1,407 @@
+// Package parquet writes flat tables as Apache Parquet files, so results
+// load directly into pandas, Polars or Spark with their column types. It
+// implements the subset needed for exports: row groups with one
+// uncompressed, plain-encoded data page per column.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Type is the type of a column's values
+type Type int
+
+// Column types
+const (
+	Int64     Type = iota // int64
+	Double                // float64
+	String                // string, stored as UTF-8
+	Bool                  // bool
+	Timestamp             // time.Time, stored as milliseconds since the epoch in UTC
+)
+
+// Parquet physical types, converted types and encodings
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	pageTypeData = 0
+)
+
+// magic starts and ends every Parquet file
+const magic = "PAR1"
+
+// Column describes one column of a table
+type Column struct {
+	Name     string
+	Type     Type
+	Optional bool // values may be nil
+}
+
+// keyValue is an entry of the file's key-value metadata
+type keyValue struct {
+	key, value string
+}
+
+// Writer collects the rows of a table and writes them as a Parquet file.
+// A writer from NewWriter holds every row until WriteTo; one from
+// NewStreamWriter writes a row group whenever enough rows have been
+// appended, so only the rows of one group are held at a time.
+type Writer struct {
+	columns  []Column
+	values   [][]interface{} // values of each column in the current row group
+	rows     int             // rows in the current row group
+	metadata []keyValue
+
+	groups    []rowGroup // row groups already written
+	totalRows int
+
+	// Set for stream writers
+	file      *fileWriter
+	groupRows int
+}
+
+// rowGroup locates the column chunks of a written row group
+type rowGroup struct {
+	chunks []chunk
+	rows   int
+}
+
+// fileWriter writes to the output file, tracking the offset and the first
+// error
+type fileWriter struct {
+	out    io.Writer
+	offset int64
+	err    error
+}
+
+// write appends data to the file unless an earlier write failed
+func (f *fileWriter) write(data []byte) {
+	if f.err != nil {
+		return
+	}
+	n, err := f.out.Write(data)
+	f.offset += int64(n)
+	f.err = err
+}
+
+// NewWriter creates a writer for a table with the given columns
+func NewWriter(columns ...Column) *Writer {
+	return &Writer{columns: columns, values: make([][]interface{}, len(columns))}
+}
+
+// NewStreamWriter creates a writer for a table with the given columns that
+// writes to out a row group of groupRows rows at a time. Close writes the
+// remaining rows and the footer.
+func NewStreamWriter(out io.Writer, groupRows int, columns ...Column) *Writer {
+	w := NewWriter(columns...)
+	w.file = &fileWriter{out: out}
+	w.groupRows = max(groupRows, 1)
+	return w
+}
+
+// SetMetadata adds a key-value pair to the file metadata
+func (w *Writer) SetMetadata(key, value string) {
+	w.metadata = append(w.metadata, keyValue{key, value})
+}
+
+// Append adds a row with one value per column, nil for a missing value of
+// an optional column
+func (w *Writer) Append(row ...interface{}) error {
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("row has %d values, table has %d columns", len(row), len(w.columns))
+	}
+
+	normalized := make([]interface{}, len(row))
+	for i, value := range row {
+		column := w.columns[i]
+		if value == nil {
+			if !column.Optional {
+				return fmt.Errorf("column %s: missing value", column.Name)
+			}
+			continue
+		}
+
+		var ok bool
+		switch column.Type {
+		case Int64:
+			switch v := value.(type) {
+			case int64:
+				normalized[i], ok = v, true
+			case int:
+				normalized[i], ok = int64(v), true
+			}
+		case Double:
+			normalized[i], ok = value.(float64)
+		case String:
+			normalized[i], ok = value.(string)
+		case Bool:
+			normalized[i], ok = value.(bool)
+		case Timestamp:
+			if t, isTime := value.(time.Time); isTime {
+				normalized[i], ok = t.UnixMilli(), true
+			}
+		}
+		if !ok {
+			return fmt.Errorf("column %s: unexpected value type %T", column.Name, value)
+		}
+	}
+
+	for i, value := range normalized {
+		w.values[i] = append(w.values[i], value)
+	}
+	w.rows++
+
+	if w.file != nil && w.rows >= w.groupRows {
+		w.writeRowGroup(w.file)
+		return w.file.err
+	}
+	return nil
+}
+
+// chunk locates the data of one column in the file
+type chunk struct {
+	offset int64
+	size   int64
+}
+
+// WriteTo writes the table as a Parquet file to out. It is for writers
+// from NewWriter; stream writers are finished with Close.
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+	// Buffer the file so a failed write leaves nothing half written
+	var file bytes.Buffer
+	w.writeRowGroup(&fileWriter{out: &file})
+	w.writeFooter(&fileWriter{out: &file, offset: int64(file.Len())})
+	return file.WriteTo(out)
+}
+
+// Close writes the rows appended since the last row group and the footer
+// of a stream writer
+func (w *Writer) Close() error {
+	if w.rows > 0 || len(w.groups) == 0 {
+		w.writeRowGroup(w.file)
+	}
+	w.writeFooter(w.file)
+	return w.file.err
+}
+
+// writeRowGroup writes the current rows to file as a row group, starting
+// the file when it is empty
+func (w *Writer) writeRowGroup(file *fileWriter) {
+	if file.offset == 0 {
+		file.write([]byte(magic))
+	}
+
+	group := rowGroup{chunks: make([]chunk, len(w.columns)), rows: w.rows}
+	for i, column := range w.columns {
+		page := encodeValues(column, w.values[i])
+
+		var header thriftWriter
+		header.beginStruct(0)
+		header.i32(1, pageTypeData)
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.beginStruct(5)
+		header.i32(1, int32(w.rows))
+		header.i32(2, encodingPlain)
+		header.i32(3, encodingRLE)
+		header.i32(4, encodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		group.chunks[i] = chunk{offset: file.offset, size: int64(header.buf.Len() + len(page))}
+		file.write(header.buf.Bytes())
+		file.write(page)
+		w.values[i] = w.values[i][:0]
+	}
+
+	w.groups = append(w.groups, group)
+	w.totalRows += w.rows
+	w.rows = 0
+}
+
+// writeFooter ends the file with its metadata
+func (w *Writer) writeFooter(file *fileWriter) {
+	footer := w.footer()
+	file.write(footer)
+	file.write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	file.write([]byte(magic))
+}
+
+// footer encodes the file metadata: schema, row groups and key-value pairs
+func (w *Writer) footer() []byte {
+	var t thriftWriter
+	t.beginStruct(0)
+	t.i32(1, 1)
+
+	t.listHeader(2, thriftStruct, len(w.columns)+1)
+	t.beginStruct(0)
+	t.string(4, "schema")
+	t.i32(5, int32(len(w.columns)))
+	t.endStruct()
+	for _, column := range w.columns {
+		physical, converted := physicalType(column.Type)
+		t.beginStruct(0)
+		t.i32(1, physical)
+		t.i32(3, repetition(column))
+		t.string(4, column.Name)
+		if converted >= 0 {
+			t.i32(6, converted)
+		}
+		t.endStruct()
+	}
+
+	t.i64(3, int64(w.totalRows))
+
+	t.listHeader(4, thriftStruct, len(w.groups))
+	for _, group := range w.groups {
+		var totalSize int64
+		t.beginStruct(0)
+		t.listHeader(1, thriftStruct, len(w.columns))
+		for i, column := range w.columns {
+			physical, _ := physicalType(column.Type)
+			chunk := group.chunks[i]
+			t.beginStruct(0)
+			t.i64(2, chunk.offset)
+			t.beginStruct(3)
+			t.i32(1, physical)
+			if column.Optional {
+				t.i32List(2, encodingPlain, encodingRLE)
+			} else {
+				t.i32List(2, encodingPlain)
+			}
+			t.stringList(3, column.Name)
+			t.i32(4, 0) // uncompressed
+			t.i64(5, int64(group.rows))
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+			totalSize += chunk.size
+		}
+		t.i64(2, totalSize)
+		t.i64(3, int64(group.rows))
+		t.endStruct()
+	}
+
+	if len(w.metadata) > 0 {
+		t.listHeader(5, thriftStruct, len(w.metadata))
+		for _, kv := range w.metadata {
+			t.beginStruct(0)
+			t.string(1, kv.key)
+			t.string(2, kv.value)
+			t.endStruct()
+		}
+	}
+	t.string(6, "fair-stock-value")
+	t.endStruct()
+	return t.buf.Bytes()
+}
+
+// encodeValues encodes the data page of a column: definition levels for
+// optional columns followed by the plain-encoded non-null values
+func encodeValues(column Column, values []interface{}) []byte {
+	var page bytes.Buffer
+	if column.Optional {
+		levels := encodeDefinitionLevels(values)
+		binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+		page.Write(levels)
+	}
+
+	if column.Type == Bool {
+		// Booleans are bit-packed, least significant bit first
+		var bits []byte
+		n := 0
+		for _, value := range values {
+			if value == nil {
+				continue
+			}
+			if n%8 == 0 {
+				bits = append(bits, 0)
+			}
+			if value.(bool) {
+				bits[n/8] |= 1 << (n % 8)
+			}
+			n++
+		}
+		page.Write(bits)
+		return page.Bytes()
+	}
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case int64:
+			binary.Write(&page, binary.LittleEndian, v)
+		case float64:
+			binary.Write(&page, binary.LittleEndian, math.Float64bits(v))
+		case string:
+			binary.Write(&page, binary.LittleEndian, uint32(len(v)))
+			page.WriteString(v)
+		}
+	}
+	return page.Bytes()
+}
+
+// encodeDefinitionLevels encodes whether each value is present (1) or
+// null (0) as runs of the RLE/bit-packing hybrid encoding with bit width 1
+func encodeDefinitionLevels(values []interface{}) []byte {
+	var levels thriftWriter
+	for start := 0; start < len(values); {
+		present := values[start] != nil
+		end := start + 1
+		for end < len(values) && (values[end] != nil) == present {
+			end++
+		}
+		levels.varint(uint64(end-start) << 1)
+		if present {
+			levels.buf.WriteByte(1)
+		} else {
+			levels.buf.WriteByte(0)
+		}
+		start = end
+	}
+	return levels.buf.Bytes()
+}
+
+// physicalType returns the physical and converted type of a column type;
+// the converted type is -1 when there is none
+func physicalType(t Type) (physical, converted int32) {
+	switch t {
+	case Double:
+		return physicalDouble, -1
+	case String:
+		return physicalByteArray, convertedUTF8
+	case Bool:
+		return physicalBoolean, -1
+	case Timestamp:
+		return physicalInt64, convertedTimestampMillis
+	default:
+		return physicalInt64, -1
+	}
+}
+
+// repetition returns the repetition type of a column
+func repetition(column Column) int32 {
+	if column.Optional {
+		return repetitionOptional
+	}
+	return repetitionRequired
+}
@@ -0,0 +1,134 @@
+// Package peers chooses the comparable companies a stock's Comps value is
+// anchored on. Rather than a fixed P/E per sector, the peers of a stock are
+// the companies of the analyzed universe in the same industry, or failing
+// that the same sector, with a similar market cap, and the stock is valued
+// on their median P/E.
+package peers
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// Rules bound the peer groups Select chooses
+type Rules struct {
+	// MinPeers is the fewest peers a group may have; stocks with fewer
+	// comparable companies are valued on their own P/E
+	MinPeers int
+	// MaxPeers is the most peers a group may have, the closest in market
+	// cap chosen first
+	MaxPeers int
+	// MarketCapRatio is how many times larger or smaller than the stock a
+	// peer's market cap may be
+	MarketCapRatio float64
+}
+
+// DefaultRules choose groups small enough to stay comparable in size and
+// large enough for their median to be robust to a single outlier
+func DefaultRules() Rules {
+	return Rules{
+		MinPeers:       3,
+		MaxPeers:       8,
+		MarketCapRatio: 4,
+	}
+}
+
+// Select returns the peer group of target among universe, or nil when the
+// universe holds fewer than rules.MinPeers comparable companies. Companies
+// of the same industry are preferred to those of the same sector. Only
+// companies with a market cap and a P/E of their own, not a stand-in, are
+// considered. The upside of the peers is left for the caller to value.
+func Select(target *models.StockData, universe []*models.StockData, rules Rules) *models.PeerGroup {
+	if target.MarketCap <= 0 || rules.MinPeers <= 0 {
+		return nil
+	}
+	for _, basis := range []struct {
+		name  string
+		field func(*models.StockData) string
+	}{
+		{models.PeerBasisIndustry, func(s *models.StockData) string { return models.NormalizeIndustry(s.Industry) }},
+		{models.PeerBasisSector, func(s *models.StockData) string { return models.NormalizeSector(s.Sector) }},
+	} {
+		group := basis.field(target)
+		if group == "" {
+			continue
+		}
+		var candidates []*models.StockData
+		for _, candidate := range universe {
+			if candidate.Ticker != target.Ticker && comparable(target, candidate, rules) &&
+				strings.EqualFold(basis.field(candidate), group) {
+				candidates = append(candidates, candidate)
+			}
+		}
+		if len(candidates) >= rules.MinPeers {
+			return newGroup(basis.name, target, candidates, rules)
+		}
+	}
+	return nil
+}
+
+// comparable reports whether candidate has the figures of a peer and a
+// market cap close enough to target's
+func comparable(target, candidate *models.StockData, rules Rules) bool {
+	if candidate.PERatio <= 0 || candidate.PERatioEstimated || candidate.MarketCap <= 0 || candidate.CurrentPrice <= 0 {
+		return false
+	}
+	ratio := float64(candidate.MarketCap) / float64(target.MarketCap)
+	return rules.MarketCapRatio <= 0 || (ratio <= rules.MarketCapRatio && ratio >= 1/rules.MarketCapRatio)
+}
+
+// newGroup returns the group of the candidates closest to target in market
+// cap, their median P/E and, when at least rules.MinPeers of them are REITs
+// with FFO, the median P/FFO of those
+func newGroup(basis string, target *models.StockData, candidates []*models.StockData, rules Rules) *models.PeerGroup {
+	distance := func(s *models.StockData) float64 {
+		return math.Abs(math.Log(float64(s.MarketCap) / float64(target.MarketCap)))
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		di, dj := distance(candidates[i]), distance(candidates[j])
+		if di != dj {
+			return di < dj
+		}
+		return candidates[i].Ticker < candidates[j].Ticker
+	})
+	if rules.MaxPeers > 0 && len(candidates) > rules.MaxPeers {
+		candidates = candidates[:rules.MaxPeers]
+	}
+
+	group := &models.PeerGroup{Basis: basis, Peers: make([]models.Peer, 0, len(candidates))}
+	ratios := make([]float64, 0, len(candidates))
+	var pffos []float64
+	for _, candidate := range candidates {
+		peer := models.Peer{
+			Ticker:      candidate.Ticker,
+			CompanyName: candidate.CompanyName,
+			PERatio:     candidate.PERatio,
+			GrowthRate:  candidate.GrowthRate,
+			MarketCap:   candidate.MarketCap,
+		}
+		if candidate.IsREIT() && candidate.FFOPerShare > 0 {
+			peer.PFFO = candidate.CurrentPrice / candidate.FFOPerShare
+			pffos = append(pffos, peer.PFFO)
+		}
+		group.Peers = append(group.Peers, peer)
+		ratios = append(ratios, candidate.PERatio)
+	}
+	group.MedianPE = median(ratios)
+	if len(pffos) >= rules.MinPeers {
+		group.MedianPFFO = median(pffos)
+	}
+	return group
+}
+
+// median returns the median of values, which must not be empty
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[middle]
+	}
+	return (values[middle-1] + values[middle]) / 2
+}
@@ -0,0 +1,44 @@
+package peers
+
+import (
+	"testing"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// reit returns a REIT of the net lease industry at price, with FFO per
+// share ffo
+func reit(ticker string, price, ffo float64) *models.StockData {
+	return &models.StockData{
+		Ticker:       ticker,
+		Sector:       models.SectorRealEstate,
+		Industry:     "REIT - Retail",
+		CurrentPrice: price,
+		PERatio:      40,
+		MarketCap:    20_000_000_000,
+		FFOPerShare:  ffo,
+	}
+}
+
+func TestSelectMedianPFFO(t *testing.T) {
+	target := reit("O", 60, 4)
+	universe := []*models.StockData{target, reit("NNN", 44, 4), reit("ADC", 72, 6), reit("EPRT", 26, 2)}
+
+	group := Select(target, universe, DefaultRules())
+	if group == nil {
+		t.Fatal("no peer group")
+	}
+	if group.MedianPFFO != 12 {
+		t.Errorf("median P/FFO %.2f, want 12 of 11, 12 and 13", group.MedianPFFO)
+	}
+	for _, peer := range group.Peers {
+		if peer.PFFO == 0 {
+			t.Errorf("peer %s has no P/FFO", peer.Ticker)
+		}
+	}
+
+	universe[3].FFOPerShare = 0
+	if group := Select(target, universe, DefaultRules()); group == nil || group.MedianPFFO != 0 {
+		t.Errorf("peer group %+v with two peers reporting FFO, want no median P/FFO", group)
+	}
+}
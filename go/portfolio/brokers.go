@@ -0,0 +1,384 @@
+package portfolio
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/finparse"
+)
+
+// Brokerage export formats
+const (
+	Generic  = "generic" // the ticker, shares and cost basis CSV of LoadHoldings
+	Schwab   = "schwab"
+	Fidelity = "fidelity"
+	IBKR     = "ibkr"
+	Vanguard = "vanguard"
+)
+
+// Brokers are the brokerage export formats ImportHoldings reads
+var Brokers = []string{Schwab, Fidelity, IBKR, Vanguard}
+
+// Why rows of an export are not imported as holdings
+const (
+	SkippedCash   = "cash"           // cash, sweep and money market funds
+	SkippedOption = "option"         // options and other derivatives
+	SkippedShort  = "short position" // negative quantities
+	SkippedOther  = "not a stock"    // bonds, futures and other assets
+)
+
+// Skipped is a row of an export that is not a stock position
+type Skipped struct {
+	Symbol string `json:"symbol"`
+	Reason string `json:"reason"`
+}
+
+// Import is the holdings read from a file
+type Import struct {
+	Broker   string    `json:"broker"` // format of the file
+	Holdings []Holding `json:"holdings"`
+	Skipped  []Skipped `json:"skipped,omitempty"`
+}
+
+// ImportHoldings reads holdings from the positions export of a brokerage
+// or from a generic holdings CSV (see LoadHoldings). broker names the
+// format, one of Generic and Brokers; when empty it is detected from the
+// file. Cash, options and short positions are skipped and listed in the
+// import, and positions held in several accounts are merged.
+func ImportHoldings(path, broker string) (*Import, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holdings file: %w", err)
+	}
+	defer file.Close()
+
+	imported, err := ReadImport(file, broker)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return imported, nil
+}
+
+// ReadImport parses holdings in the format described by ImportHoldings
+func ReadImport(r io.Reader, broker string) (*Import, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holdings: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // byte order mark
+
+	if broker == "" {
+		broker = DetectBroker(data)
+	}
+	if broker == Generic {
+		holdings, err := ReadHoldings(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return &Import{Broker: Generic, Holdings: holdings}, nil
+	}
+	parse, ok := brokerParsers[broker]
+	if !ok {
+		return nil, fmt.Errorf("unknown broker %q (want %s or %s)", broker, strings.Join(Brokers, ", "), Generic)
+	}
+
+	rows, err := readRows(data)
+	if err != nil {
+		return nil, err
+	}
+	im := &importer{Import: &Import{Broker: broker}, index: make(map[string]int)}
+	if err := parse(rows, im); err != nil {
+		return nil, err
+	}
+	if len(im.Holdings) == 0 {
+		return nil, fmt.Errorf("no stock positions found in %s export", broker)
+	}
+	return im.Import, nil
+}
+
+// DetectBroker returns the brokerage whose export data looks like, or
+// Generic when it looks like none
+func DetectBroker(data []byte) string {
+	rows, err := readRows(data)
+	if err != nil {
+		return Generic
+	}
+	for i, row := range rows {
+		if i == 20 {
+			break
+		}
+		columns := headerColumns(row)
+		switch {
+		case len(row) > 1 && (row[0] == "Statement" || row[0] == "Open Positions"),
+			columns.has("symbol") && columns.has("assetclass"):
+			return IBKR
+		case columns.has("account number") && columns.has("investment name") && columns.has("shares"):
+			return Vanguard
+		case columns.has("account number") && columns.has("symbol") && columns.has("current value"):
+			return Fidelity
+		case strings.HasPrefix(strings.TrimSpace(strings.Join(row, " ")), "Positions for"),
+			columns.has("symbol") && columns.has("description") && (columns.has("security type") || columns.has("asset type")):
+			return Schwab
+		}
+	}
+	return Generic
+}
+
+// brokerParsers read the rows of each brokerage's export
+var brokerParsers = map[string]func(rows [][]string, im *importer) error{
+	Schwab:   parseSchwab,
+	Fidelity: parseFidelity,
+	IBKR:     parseIBKR,
+	Vanguard: parseVanguard,
+}
+
+// parseSchwab reads a Schwab positions export: a title line per account,
+// then a header row, the positions, a cash row and the account total
+func parseSchwab(rows [][]string, im *importer) error {
+	var columns headerIndex
+	for _, row := range rows {
+		if c := headerColumns(row); c.has("symbol") && c.hasAny("quantity", "qty (quantity)") {
+			columns = c
+			continue
+		}
+		symbol := columns.get(row, "symbol")
+		kind := strings.ToLower(columns.get(row, "security type", "asset type"))
+		switch {
+		case columns == nil || symbol == "" || strings.EqualFold(symbol, "Account Total"):
+		case strings.HasPrefix(strings.ToLower(symbol), "cash") || strings.Contains(kind, "cash") || strings.Contains(kind, "money market"):
+			im.skip(symbol, SkippedCash)
+		case strings.Contains(kind, "option") || strings.Contains(symbol, " "):
+			im.skip(symbol, SkippedOption)
+		case strings.Contains(kind, "fixed income") || strings.Contains(kind, "bond"):
+			im.skip(symbol, SkippedOther)
+		default:
+			// Schwab gives the cost basis of the whole position
+			err := im.position(symbol, columns.get(row, "quantity", "qty (quantity)"), "", columns.get(row, "cost basis"), "USD")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseFidelity reads a Fidelity positions export: a row per position and
+// account, followed by disclaimers
+func parseFidelity(rows [][]string, im *importer) error {
+	var columns headerIndex
+	for _, row := range rows {
+		if c := headerColumns(row); c.has("symbol") && c.has("quantity") {
+			columns = c
+			continue
+		}
+		// Cash core positions are marked with asterisks, as SPAXX**, and
+		// options start with a dash, as -AAPL240119C150
+		symbol := columns.get(row, "symbol")
+		description := strings.ToUpper(columns.get(row, "description"))
+		switch {
+		case columns == nil || len(row) < len(columns)/2 || symbol == "":
+		case strings.HasPrefix(strings.ToLower(symbol), "pending"):
+		case strings.HasSuffix(symbol, "**") || strings.Contains(description, "MONEY MARKET") || isMoneyMarket(symbol):
+			im.skip(strings.TrimRight(symbol, "*"), SkippedCash)
+		case strings.HasPrefix(symbol, "-"):
+			im.skip(strings.TrimPrefix(symbol, "-"), SkippedOption)
+		default:
+			err := im.position(symbol, columns.get(row, "quantity"), columns.get(row, "average cost basis"),
+				columns.get(row, "cost basis total"), "USD")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseIBKR reads the Open Positions section of an Interactive Brokers
+// activity statement, or a Flex Query of open positions. Positions are
+// reported in the currency they trade in.
+func parseIBKR(rows [][]string, im *importer) error {
+	var columns headerIndex
+	for _, row := range rows {
+		// Activity statements prefix each row with its section and kind
+		if len(row) > 1 && (row[1] == "Header" || row[1] == "Data" || row[1] == "Total") && row[0] != "" && !strings.EqualFold(row[0], "symbol") {
+			if row[0] != "Open Positions" {
+				continue
+			}
+			if row[1] == "Header" {
+				columns = headerColumns(row)
+				continue
+			}
+			// Only the summary row of a position, not its lots or totals
+			if row[1] != "Data" || (columns.has("datadiscriminator") && !strings.EqualFold(columns.get(row, "datadiscriminator"), "summary")) {
+				continue
+			}
+		} else if c := headerColumns(row); c.has("symbol") && c.hasAny("quantity", "position") {
+			columns = c
+			continue
+		}
+
+		symbol := columns.get(row, "symbol")
+		kind := strings.ToLower(columns.get(row, "asset category", "assetclass"))
+		switch {
+		case columns == nil || symbol == "":
+		case kind == "stk" || kind == "stocks" || kind == "":
+			// IBKR writes share classes with a space, as BRK B
+			err := im.position(strings.ReplaceAll(symbol, " ", "."), columns.get(row, "quantity", "position"),
+				columns.get(row, "cost price", "costbasisprice"), "",
+				strings.ToUpper(columns.get(row, "currency", "currencyprimary")))
+			if err != nil {
+				return err
+			}
+		case kind == "cash" || kind == "forex":
+			im.skip(symbol, SkippedCash)
+		case strings.Contains(kind, "option") || kind == "opt" || kind == "fop" || kind == "war":
+			im.skip(symbol, SkippedOption)
+		default:
+			im.skip(symbol, SkippedOther)
+		}
+	}
+	return nil
+}
+
+// parseVanguard reads the positions section of a Vanguard download, which
+// is followed by a section of transactions
+func parseVanguard(rows [][]string, im *importer) error {
+	var columns headerIndex
+	for _, row := range rows {
+		c := headerColumns(row)
+		if c.has("trade date") {
+			break
+		}
+		if c.has("symbol") && c.has("shares") {
+			columns = c
+			continue
+		}
+
+		symbol := columns.get(row, "symbol")
+		name := strings.ToUpper(columns.get(row, "investment name"))
+		switch {
+		case columns == nil || (symbol == "" && name == ""):
+		case isMoneyMarket(symbol) || strings.Contains(name, "MONEY MKT") || strings.Contains(name, "MONEY MARKET"):
+			im.skip(symbol, SkippedCash)
+		case symbol == "":
+			// Bonds and CDs are listed by name only
+			im.skip(name, SkippedOther)
+		default:
+			if err := im.position(symbol, columns.get(row, "shares"), "", "", "USD"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isMoneyMarket reports whether symbol is that of a money market mutual
+// fund, five letters ending in XX such as VMFXX or SPAXX
+func isMoneyMarket(symbol string) bool {
+	return len(symbol) == 5 && strings.HasSuffix(strings.ToUpper(symbol), "XX")
+}
+
+// importer collects the holdings of an export
+type importer struct {
+	*Import
+	index map[string]int
+}
+
+// skip records a row that is not imported
+func (im *importer) skip(symbol, reason string) {
+	im.Skipped = append(im.Skipped, Skipped{Symbol: strings.ToUpper(strings.TrimSpace(symbol)), Reason: reason})
+}
+
+// position adds a holding from the cells of an export, with the cost
+// basis given per share or for the whole position
+func (im *importer) position(symbol, quantity, unitCost, totalCost, currency string) error {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	shares, err := finparse.Number(quantity)
+	if err != nil {
+		return fmt.Errorf("invalid quantity %q for %s", quantity, symbol)
+	}
+	if shares <= 0 {
+		im.skip(symbol, SkippedShort)
+		return nil
+	}
+
+	holding := Holding{Ticker: symbol, Shares: shares, Currency: currency}
+	if cost, err := finparse.Number(unitCost); err == nil && cost > 0 {
+		holding.CostBasis = cost
+	} else if cost, err := finparse.Number(totalCost); err == nil && cost > 0 {
+		holding.CostBasis = cost / shares
+	} else if err != nil && !errors.Is(err, finparse.ErrEmpty) {
+		return fmt.Errorf("invalid cost basis %q for %s", totalCost, symbol)
+	}
+
+	if i, exists := im.index[symbol]; exists {
+		im.Holdings[i] = merge(im.Holdings[i], holding)
+		return nil
+	}
+	im.index[symbol] = len(im.Holdings)
+	im.Holdings = append(im.Holdings, holding)
+	return nil
+}
+
+// readRows reads every row of a CSV export, which may mix sections with
+// different numbers of columns
+func readRows(data []byte) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holdings: %w", err)
+	}
+	return rows, nil
+}
+
+// headerIndex maps the lower-case column names of a header row to their
+// index
+type headerIndex map[string]int
+
+// headerColumns returns the columns named by row, were it a header row
+func headerColumns(row []string) headerIndex {
+	columns := make(headerIndex, len(row))
+	for i, name := range row {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, seen := columns[name]; !seen && name != "" {
+			columns[name] = i
+		}
+	}
+	return columns
+}
+
+// has reports whether the header names column
+func (h headerIndex) has(column string) bool {
+	_, ok := h[column]
+	return ok
+}
+
+// hasAny reports whether the header names any of columns
+func (h headerIndex) hasAny(columns ...string) bool {
+	for _, column := range columns {
+		if h.has(column) {
+			return true
+		}
+	}
+	return false
+}
+
+// get returns the cell of row in the first of columns the header names
+func (h headerIndex) get(row []string, columns ...string) string {
+	for _, column := range columns {
+		if i, ok := h[column]; ok {
+			if i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,185 @@
+// Package portfolio values a set of holdings and aggregates the results
+// into portfolio-level figures and rebalancing suggestions.
+package portfolio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Holding is a position in one stock
+type Holding struct {
+	Ticker    string  `json:"ticker"`
+	Shares    float64 `json:"shares"`
+	CostBasis float64 `json:"cost_basis"`         // average cost per share, 0 when unknown
+	Currency  string  `json:"currency,omitempty"` // of the cost basis, when the file gives it
+}
+
+// headerAliases maps accepted CSV header names to holding fields
+var headerAliases = map[string]string{
+	"ticker":     "ticker",
+	"symbol":     "ticker",
+	"isin":       "ticker",
+	"cusip":      "ticker",
+	"shares":     "shares",
+	"quantity":   "shares",
+	"cost_basis": "cost_basis",
+	"cost basis": "cost_basis",
+	"cost":       "cost_basis",
+	"avg_cost":   "cost_basis",
+	"currency":   "currency",
+}
+
+// LoadHoldings reads holdings from a CSV file with ticker, shares and
+// optional per-share cost basis columns. A header row naming the columns
+// is optional; without one the columns are taken in that order. Repeated
+// tickers are merged, averaging their cost basis by shares.
+func LoadHoldings(path string) ([]Holding, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holdings file: %w", err)
+	}
+	defer file.Close()
+
+	holdings, err := ReadHoldings(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return holdings, nil
+}
+
+// ReadHoldings parses holdings in the format described by LoadHoldings
+func ReadHoldings(r io.Reader) ([]Holding, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	columns := map[string]int{"ticker": 0, "shares": 1, "cost_basis": 2}
+	var holdings []Holding
+	index := make(map[string]int)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read holdings: %w", err)
+		}
+
+		if line == 1 {
+			if header, ok := parseHeader(record); ok {
+				columns = header
+				continue
+			}
+		}
+
+		holding, err := parseHolding(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if holding.Ticker == "" {
+			continue
+		}
+
+		if i, exists := index[holding.Ticker]; exists {
+			holdings[i] = merge(holdings[i], holding)
+			continue
+		}
+		index[holding.Ticker] = len(holdings)
+		holdings = append(holdings, holding)
+	}
+
+	if len(holdings) == 0 {
+		return nil, fmt.Errorf("no holdings found")
+	}
+	return holdings, nil
+}
+
+// parseHeader recognizes a header row, returning the column of each field
+func parseHeader(record []string) (map[string]int, bool) {
+	columns := make(map[string]int)
+	for i, name := range record {
+		if field, ok := headerAliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+
+	_, hasTicker := columns["ticker"]
+	_, hasShares := columns["shares"]
+	return columns, hasTicker && hasShares
+}
+
+// parseHolding parses one CSV record
+func parseHolding(record []string, columns map[string]int) (Holding, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	holding := Holding{Ticker: strings.ToUpper(field("ticker")), Currency: strings.ToUpper(field("currency"))}
+	if holding.Ticker == "" {
+		return holding, nil
+	}
+
+	shares, err := parseAmount(field("shares"))
+	if err != nil || shares <= 0 {
+		return holding, fmt.Errorf("invalid share count %q for %s", field("shares"), holding.Ticker)
+	}
+	holding.Shares = shares
+
+	if cost := field("cost_basis"); cost != "" {
+		if holding.CostBasis, err = parseAmount(cost); err != nil || holding.CostBasis < 0 {
+			return holding, fmt.Errorf("invalid cost basis %q for %s", cost, holding.Ticker)
+		}
+	}
+	return holding, nil
+}
+
+// parseAmount parses numbers written as "1,234.50" or "$99"
+func parseAmount(value string) (float64, error) {
+	value = strings.NewReplacer("$", "", ",", "").Replace(value)
+	return strconv.ParseFloat(value, 64)
+}
+
+// MapTickers returns holdings with each ticker replaced by its mapping,
+// such as the canonical ticker of an ISIN, merging holdings that map to
+// the same ticker
+func MapTickers(holdings []Holding, mapping func(string) string) []Holding {
+	mapped := make([]Holding, 0, len(holdings))
+	index := make(map[string]int)
+	for _, holding := range holdings {
+		holding.Ticker = mapping(holding.Ticker)
+		if i, exists := index[holding.Ticker]; exists {
+			mapped[i] = merge(mapped[i], holding)
+			continue
+		}
+		index[holding.Ticker] = len(mapped)
+		mapped = append(mapped, holding)
+	}
+	return mapped
+}
+
+// merge combines two lots of the same stock. Lots bought in different
+// currencies have no common cost basis.
+func merge(a, b Holding) Holding {
+	merged := Holding{Ticker: a.Ticker, Shares: a.Shares + b.Shares, Currency: a.Currency}
+	if a.Currency != "" && b.Currency != "" && a.Currency != b.Currency {
+		merged.Currency = ""
+		return merged
+	}
+	if merged.Currency == "" {
+		merged.Currency = b.Currency
+	}
+	if a.CostBasis > 0 && b.CostBasis > 0 {
+		merged.CostBasis = (a.CostBasis*a.Shares + b.CostBasis*b.Shares) / merged.Shares
+	}
+	return merged
+}
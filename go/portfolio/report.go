@@ -0,0 +1,189 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// Position is a valued holding
+type Position struct {
+	Holding
+	Result *models.ValuationResult `json:"result"`
+
+	MarketValue  float64 `json:"market_value"`
+	FairValue    float64 `json:"fair_value"`
+	CostValue    float64 `json:"cost_value"`   // 0 when the cost basis is unknown
+	Weight       float64 `json:"weight"`       // share of the portfolio market value
+	UpsidePct    float64 `json:"upside_pct"`   // fair value versus market value
+	Contribution float64 `json:"contribution"` // upside weighted by position size, in percentage points
+}
+
+// GainLoss returns the unrealized gain or loss against the cost basis
+func (p Position) GainLoss() float64 {
+	if p.CostValue == 0 {
+		return 0
+	}
+	return p.MarketValue - p.CostValue
+}
+
+// Suggestion is a rebalancing candidate
+type Suggestion struct {
+	Ticker string `json:"ticker"`
+	Action string `json:"action"` // "trim" or "add"
+	Reason string `json:"reason"`
+}
+
+// Report aggregates a valued portfolio
+type Report struct {
+	Positions   []Position        `json:"positions"`
+	MarketValue float64           `json:"market_value"`
+	FairValue   float64           `json:"fair_value"`
+	CostValue   float64           `json:"cost_value"` // positions with a known cost basis only
+	UpsidePct   float64           `json:"upside_pct"`
+	Failed      map[string]string `json:"failed,omitempty"` // holdings that could not be valued
+	Suggestions []Suggestion      `json:"suggestions,omitempty"`
+
+	// Warnings note figures the report could not compare, such as a cost
+	// basis in another currency than the price
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// RebalanceOptions controls which positions are suggested for rebalancing
+type RebalanceOptions struct {
+	Threshold float64 // minimum upside or downside, in percent, to act on
+	MaxWeight float64 // positions above this share of the portfolio are trimmed
+}
+
+// DefaultRebalanceOptions returns the default rebalancing thresholds
+func DefaultRebalanceOptions() RebalanceOptions {
+	return RebalanceOptions{Threshold: 10, MaxWeight: 0.25}
+}
+
+// NewReport values holdings with the given valuation results, keyed by
+// ticker. Holdings without a result are listed in Failed with the reason
+// from errs.
+func NewReport(holdings []Holding, results map[string]*models.ValuationResult, errs map[string]string, opts RebalanceOptions) *Report {
+	report := &Report{Failed: make(map[string]string)}
+	currencies := make(map[string]bool)
+	for _, holding := range holdings {
+		result, ok := results[holding.Ticker]
+		if !ok {
+			reason := errs[holding.Ticker]
+			if reason == "" {
+				reason = "not valued"
+			}
+			report.Failed[holding.Ticker] = reason
+			continue
+		}
+
+		position := Position{
+			Holding:     holding,
+			Result:      result,
+			MarketValue: holding.Shares * result.CurrentPrice,
+			FairValue:   holding.Shares * result.FairValue,
+			CostValue:   holding.Shares * holding.CostBasis,
+		}
+		// There are no exchange rates to convert a cost basis paid in
+		// another currency than the stock trades in
+		if result.Inputs != nil && result.Inputs.Currency != "" {
+			quote := strings.ToUpper(result.Inputs.Currency)
+			currencies[quote] = true
+			if holding.Currency != "" && holding.Currency != quote && position.CostValue > 0 {
+				position.CostValue = 0
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: cost basis in %s but priced in %s, gain/loss left out",
+					holding.Ticker, holding.Currency, quote))
+			}
+		}
+		if position.MarketValue > 0 {
+			position.UpsidePct = (position.FairValue - position.MarketValue) / position.MarketValue * 100
+		}
+
+		report.MarketValue += position.MarketValue
+		report.FairValue += position.FairValue
+		report.CostValue += position.CostValue
+		report.Positions = append(report.Positions, position)
+	}
+
+	if len(currencies) > 1 {
+		names := make([]string, 0, len(currencies))
+		for currency := range currencies {
+			names = append(names, currency)
+		}
+		sort.Strings(names)
+		report.Warnings = append(report.Warnings, fmt.Sprintf("positions are priced in %s; totals and weights add them up without conversion",
+			strings.Join(names, ", ")))
+	}
+
+	if report.MarketValue > 0 {
+		report.UpsidePct = (report.FairValue - report.MarketValue) / report.MarketValue * 100
+		for i := range report.Positions {
+			position := &report.Positions[i]
+			position.Weight = position.MarketValue / report.MarketValue
+			position.Contribution = position.UpsidePct * position.Weight
+		}
+	}
+
+	// Largest positions first
+	sort.SliceStable(report.Positions, func(i, j int) bool {
+		return report.Positions[i].MarketValue > report.Positions[j].MarketValue
+	})
+
+	report.Suggestions = report.rebalance(opts)
+	return report
+}
+
+// GainLoss returns the unrealized gain or loss of positions with a known
+// cost basis
+func (r *Report) GainLoss() float64 {
+	var total float64
+	for _, position := range r.Positions {
+		total += position.GainLoss()
+	}
+	return total
+}
+
+// rebalance suggests trimming overvalued or oversized positions and adding
+// to undervalued positions that are smaller than average
+func (r *Report) rebalance(opts RebalanceOptions) []Suggestion {
+	if len(r.Positions) == 0 {
+		return nil
+	}
+	averageWeight := 1 / float64(len(r.Positions))
+
+	var suggestions []Suggestion
+	for _, position := range r.Positions {
+		switch {
+		case opts.MaxWeight > 0 && position.Weight > opts.MaxWeight:
+			suggestions = append(suggestions, Suggestion{position.Ticker, "trim",
+				fmt.Sprintf("%.1f%% of the portfolio exceeds the %.0f%% limit", position.Weight*100, opts.MaxWeight*100)})
+		case position.UpsidePct <= -opts.Threshold && position.Weight >= averageWeight:
+			suggestions = append(suggestions, Suggestion{position.Ticker, "trim",
+				fmt.Sprintf("%.1f%% above fair value at %.1f%% of the portfolio", -position.UpsidePct, position.Weight*100)})
+		case position.UpsidePct >= opts.Threshold && position.Weight < averageWeight:
+			suggestions = append(suggestions, Suggestion{position.Ticker, "add",
+				fmt.Sprintf("%.1f%% below fair value at only %.1f%% of the portfolio", position.UpsidePct, position.Weight*100)})
+		}
+	}
+
+	// Biggest mispricings first
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return r.mispricing(suggestions[i].Ticker) > r.mispricing(suggestions[j].Ticker)
+	})
+	return suggestions
+}
+
+// mispricing returns the absolute weighted upside of a position
+func (r *Report) mispricing(ticker string) float64 {
+	for _, position := range r.Positions {
+		if position.Ticker == ticker {
+			if position.Contribution < 0 {
+				return -position.Contribution
+			}
+			return position.Contribution
+		}
+	}
+	return 0
+}
@@ -0,0 +1,161 @@
+package portfolio
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/lesnerd/fair-stock-value/go/valuation"
+)
+
+// SimulationOptions set how the assumptions of the holdings are drawn in a
+// Monte Carlo valuation of the portfolio
+type SimulationOptions struct {
+	Trials int
+
+	// GrowthStdDev is the standard deviation of each holding's growth
+	// rate, 0.02 for two percentage points
+	GrowthStdDev float64
+
+	// PEStdDev is the standard deviation of the log of each holding's
+	// P/E, or of the median P/E of its peers
+	PEStdDev float64
+
+	// DiscountRateStdDev is the standard deviation of the DCF discount
+	// rate, drawn once a trial for every holding since it prices the
+	// market as a whole
+	DiscountRateStdDev float64
+
+	// Correlation is the correlation of the growth and P/E draws of any
+	// two holdings, from 0 for independent draws to 1 for draws moving
+	// together
+	Correlation float64
+
+	// Seed makes the draws repeatable
+	Seed int64
+}
+
+// DefaultSimulationOptions returns assumptions about as uncertain as
+// analyst estimates and multiples tend to be, for a portfolio whose
+// holdings share half their uncertainty with the market
+func DefaultSimulationOptions() SimulationOptions {
+	return SimulationOptions{
+		Trials:             1000,
+		GrowthStdDev:       0.02,
+		PEStdDev:           0.2,
+		DiscountRateStdDev: 0.005,
+		Correlation:        0.5,
+		Seed:               1,
+	}
+}
+
+// Simulation is the distribution of the total fair value of a portfolio
+// over Monte Carlo trials
+type Simulation struct {
+	Trials      int     `json:"trials"`
+	Correlation float64 `json:"correlation"`
+	MarketValue float64 `json:"market_value"`
+
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	P5     float64 `json:"p5"`
+	P25    float64 `json:"p25"`
+	Median float64 `json:"median"`
+	P75    float64 `json:"p75"`
+	P95    float64 `json:"p95"`
+
+	// Undervalued is the share of trials whose total fair value exceeds
+	// the market value
+	Undervalued float64 `json:"undervalued"`
+
+	// Fixed is the fair value of the positions without recorded inputs,
+	// which every trial holds at their fair value
+	Fixed float64 `json:"fixed,omitempty"`
+}
+
+// UpsidePct returns the upside of value over the market value, in percent
+func (s *Simulation) UpsidePct(value float64) float64 {
+	if s.MarketValue <= 0 {
+		return 0
+	}
+	return (value - s.MarketValue) / s.MarketValue * 100
+}
+
+// minDiscountSpread keeps drawn discount rates above the terminal growth
+// rate, where the terminal value is undefined
+const minDiscountSpread = 0.01
+
+// Simulate values the positions of report again in each trial, on growth
+// rates, P/E ratios and a discount rate drawn around those they were
+// valued on, and returns the distribution of the total fair value. The
+// growth and P/E draws of each holding are correlated through a market
+// draw shared by every holding of the trial. The other parameters are
+// those of calculator. It returns nil when opts asks for no trials.
+func Simulate(report *Report, calculator *valuation.Calculator, opts SimulationOptions) *Simulation {
+	if opts.Trials <= 0 {
+		return nil
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	correlation := math.Max(0, math.Min(opts.Correlation, 1))
+	draw := func(market float64) float64 {
+		return math.Sqrt(correlation)*market + math.Sqrt(1-correlation)*rng.NormFloat64()
+	}
+
+	simulation := &Simulation{Trials: opts.Trials, Correlation: correlation, MarketValue: report.MarketValue}
+	for _, position := range report.Positions {
+		if position.Result.Inputs == nil {
+			simulation.Fixed += position.FairValue
+		}
+	}
+
+	params := calculator.GetDCFParameters()
+	trial := *calculator
+	totals := make([]float64, opts.Trials)
+	for t := range totals {
+		drawn := params
+		drawn.DiscountRate = math.Max(params.DiscountRate+opts.DiscountRateStdDev*rng.NormFloat64(),
+			params.TerminalGrowthRate+minDiscountSpread)
+		trial.SetDCFParameters(drawn)
+		growthMarket, peMarket := rng.NormFloat64(), rng.NormFloat64()
+
+		total := simulation.Fixed
+		for _, position := range report.Positions {
+			if position.Result.Inputs == nil {
+				continue
+			}
+			stockData := position.Result.Inputs.Snapshot()
+			stockData.GrowthRate += opts.GrowthStdDev * draw(growthMarket)
+			multiple := math.Exp(opts.PEStdDev * draw(peMarket))
+			stockData.PERatio *= multiple
+			if stockData.Peers != nil {
+				stockData.Peers.MedianPE *= multiple
+				stockData.Peers.MedianPFFO *= multiple
+			}
+			total += position.Shares * trial.CalculateFairValue(stockData).FairValue
+		}
+		totals[t] = total
+	}
+
+	sort.Float64s(totals)
+	var sum, undervalued float64
+	for _, total := range totals {
+		sum += total
+		if total > report.MarketValue {
+			undervalued++
+		}
+	}
+	simulation.Mean = sum / float64(len(totals))
+	var squares float64
+	for _, total := range totals {
+		squares += (total - simulation.Mean) * (total - simulation.Mean)
+	}
+	simulation.StdDev = math.Sqrt(squares / float64(len(totals)))
+	simulation.Undervalued = undervalued / float64(len(totals))
+
+	percentile := func(p float64) float64 {
+		return totals[int(math.Round(p*float64(len(totals)-1)))]
+	}
+	simulation.P5, simulation.P25, simulation.Median = percentile(0.05), percentile(0.25), percentile(0.5)
+	simulation.P75, simulation.P95 = percentile(0.75), percentile(0.95)
+	return simulation
+}
@@ -0,0 +1,120 @@
+// Package sanity cross-checks fetched stock data before it is valued.
+// Sources disagree, pages change and scrapers misread units, so figures
+// that must agree with each other, such as the P/E ratio with the price
+// and EPS, are compared, and figures outside plausible bounds are flagged.
+package sanity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ErrInconsistent means fetched data failed its sanity checks and was not
+// valued
+var ErrInconsistent = errors.New("inconsistent data")
+
+// Names of the checks, as reported in violations
+const (
+	CheckPERatio     = "pe_ratio"
+	CheckMarketCap   = "market_cap"
+	CheckFCFPerShare = "fcf_per_share"
+)
+
+// Rules are the tolerances of the checks
+type Rules struct {
+	// PETolerance is how far the reported P/E ratio may be from price
+	// divided by EPS, as a fraction of the latter
+	PETolerance float64
+	// MarketCapTolerance is how far the reported market cap may be from
+	// price times shares outstanding, as a fraction of the latter
+	MarketCapTolerance float64
+	// MaxFCFYield bounds free cash flow per share, as a fraction of the
+	// price, in either direction
+	MaxFCFYield float64
+}
+
+// DefaultRules are tolerant enough for data fetched from different sources
+// at slightly different times, while catching unit and scaling mistakes
+func DefaultRules() Rules {
+	return Rules{
+		PETolerance:        0.25,
+		MarketCapTolerance: 0.25,
+		MaxFCFYield:        0.5,
+	}
+}
+
+// Check returns the checks stockData fails, or nil when it passes them
+// all. Checks whose figures are missing or estimated are skipped, as are
+// those with a zero tolerance.
+func Check(stockData *models.StockData, rules Rules) []models.Violation {
+	var violations []models.Violation
+	price := stockData.CurrentPrice
+	if price <= 0 {
+		return nil
+	}
+
+	if rules.PETolerance > 0 && stockData.PERatio > 0 && !stockData.PERatioEstimated {
+		if stockData.EPS < 0 {
+			violations = append(violations, models.Violation{
+				Check:   CheckPERatio,
+				Message: fmt.Sprintf("positive P/E %.1f with negative EPS %.2f", stockData.PERatio, stockData.EPS),
+			})
+		} else if stockData.EPS > 0 {
+			implied := price / stockData.EPS
+			if deviation(stockData.PERatio, implied) > rules.PETolerance {
+				violations = append(violations, models.Violation{
+					Check:   CheckPERatio,
+					Message: fmt.Sprintf("P/E %.1f but price / EPS is %.1f", stockData.PERatio, implied),
+				})
+			}
+		}
+	}
+
+	if rules.MarketCapTolerance > 0 && stockData.MarketCap > 0 && stockData.SharesOutstanding > 0 {
+		implied := price * float64(stockData.SharesOutstanding)
+		if deviation(float64(stockData.MarketCap), implied) > rules.MarketCapTolerance {
+			violations = append(violations, models.Violation{
+				Check: CheckMarketCap,
+				Message: fmt.Sprintf("market cap %s but price × shares is %s",
+					abbreviate(float64(stockData.MarketCap)), abbreviate(implied)),
+			})
+		}
+	}
+
+	if rules.MaxFCFYield > 0 && math.Abs(stockData.FCFPerShare) > rules.MaxFCFYield*price {
+		violations = append(violations, models.Violation{
+			Check: CheckFCFPerShare,
+			Message: fmt.Sprintf("FCF per share %.2f is %.0f%% of the price %.2f",
+				stockData.FCFPerShare, stockData.FCFPerShare/price*100, price),
+		})
+	}
+
+	return violations
+}
+
+// Error returns an error wrapping ErrInconsistent that lists violations
+func Error(violations []models.Violation) error {
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		messages[i] = v.String()
+	}
+	return fmt.Errorf("%w: %s", ErrInconsistent, strings.Join(messages, "; "))
+}
+
+// deviation returns how far value is from expected, as a fraction of
+// expected
+func deviation(value, expected float64) float64 {
+	return math.Abs(value-expected) / expected
+}
+
+// abbreviate formats an amount with a B or T suffix
+func abbreviate(amount float64) string {
+	if math.Abs(amount) >= 1e12 {
+		return fmt.Sprintf("%.2fT", amount/1e12)
+	}
+	return fmt.Sprintf("%.2fB", amount/1e9)
+}
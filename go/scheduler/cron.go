@@ -0,0 +1,214 @@
+// Package scheduler runs jobs on cron-style schedules.
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when a job runs next
+type Schedule interface {
+	// Next returns the first run time strictly after t
+	Next(t time.Time) time.Time
+}
+
+// cronSchedule is a parsed five-field cron expression
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64 // bit sets of allowed values
+	domAny, dowAny                bool   // the field was "*"
+	loc                           *time.Location
+}
+
+// everySchedule runs at a fixed interval
+type everySchedule struct {
+	interval time.Duration
+}
+
+// field describes the range of one cron field
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// macros are shorthands for common expressions
+var macros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+}
+
+// Parse parses a schedule evaluated in loc: a five-field cron expression
+// ("minute hour day-of-month month day-of-week", e.g. "0 7 * * mon-fri"),
+// a macro such as "@daily", or "@every DURATION" (e.g. "@every 15m").
+// Fields accept "*", values, ranges "a-b", lists "a,b" and steps "*/n" or
+// "a-b/n"; months and weekdays also accept three-letter names. As in cron,
+// when both day fields are restricted a day matching either one matches.
+// Times a clock change skips do not run that day, and times it repeats
+// run once.
+func Parse(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval < time.Minute {
+			return nil, fmt.Errorf("invalid schedule %q: @every needs a duration of at least 1m", spec)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+	if expanded, ok := macros[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	s := &cronSchedule{loc: loc, domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	targets := []*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, f := range []field{minuteField, hourField, domField, monthField, dowField} {
+		bits, err := f.parse(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		*targets[i] = bits
+	}
+
+	// 7 is an alias for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parse parses a comma-separated list of values, ranges and steps
+func (f field) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepExpr, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangeExpr != "*" {
+			lowExpr, highExpr, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if low, err = f.value(lowExpr); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = f.value(highExpr); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = f.max
+			}
+			if high < low {
+				return 0, fmt.Errorf("invalid range %q in %s", rangeExpr, f.name)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single number or name
+func (f field) value(expr string) (int, error) {
+	if v, ok := f.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q (expected %d-%d)", f.name, expr, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first matching minute after t
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc)
+	// Minutes the clock repeats when it falls back are not run again
+	after := wallClock(t)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// Every valid expression matches within a few years (Feb 29 at worst)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = later(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc))
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = later(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc))
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = later(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc))
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 || !wallClock(t).After(after) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// later returns next, the start of the period after t's, or the first hour
+// after it when next does not exist: time.Date puts a time the clock skips
+// when it springs forward before the change, which may be no later than t
+func later(t, next time.Time) time.Time {
+	for !next.After(t) {
+		next = next.Add(time.Hour)
+	}
+	return next
+}
+
+// wallClock returns the date and time t shows, without its zone, so the
+// times a clock repeats compare equal
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// dayMatches applies cron's rule for combining the two day fields
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next returns t plus the interval
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	// Times in New York are given in UTC, as the hour the clock repeats is
+	// ambiguous in local time: EST is UTC-5, EDT UTC-4
+	for _, tc := range []struct {
+		name string
+		spec string
+		loc  *time.Location
+		from time.Time
+		want time.Time // zero when the schedule never runs
+	}{
+		{"every minute", "* * * * *", time.UTC, time.Date(2026, 1, 5, 10, 7, 30, 0, time.UTC), utc(2026, 1, 5, 10, 8)},
+		{"strictly after", "0 8,12,18 * * *", time.UTC, utc(2026, 1, 5, 12, 0), utc(2026, 1, 5, 18, 0)},
+		{"hour range", "0 9-17 * * *", time.UTC, utc(2026, 1, 5, 8, 59), utc(2026, 1, 5, 9, 0)},
+		{"after hour range", "0 9-17 * * *", time.UTC, utc(2026, 1, 5, 17, 30), utc(2026, 1, 6, 9, 0)},
+		{"step", "*/15 * * * *", time.UTC, utc(2026, 1, 5, 10, 7), utc(2026, 1, 5, 10, 15)},
+		{"step into next hour", "*/15 * * * *", time.UTC, utc(2026, 1, 5, 10, 45), utc(2026, 1, 5, 11, 0)},
+		{"step over range", "5-20/5 * * * *", time.UTC, utc(2026, 1, 5, 10, 20), utc(2026, 1, 5, 11, 5)},
+		{"step from value", "10/20 * * * *", time.UTC, utc(2026, 1, 5, 10, 31), utc(2026, 1, 5, 10, 50)},
+		{"weekdays by name", "0 7 * * mon-fri", time.UTC, utc(2026, 1, 9, 8, 0), utc(2026, 1, 12, 7, 0)},
+		{"months by name", "0 0 1 jan,jul *", time.UTC, utc(2026, 2, 1, 0, 0), utc(2026, 7, 1, 0, 0)},
+		{"7 is Sunday", "0 0 * * 7", time.UTC, utc(2026, 1, 10, 0, 0), utc(2026, 1, 11, 0, 0)},
+		{"day of month only", "0 0 13 * *", time.UTC, utc(2026, 1, 14, 0, 0), utc(2026, 2, 13, 0, 0)},
+		{"day of week only", "0 0 * * fri", time.UTC, utc(2026, 1, 1, 0, 0), utc(2026, 1, 2, 0, 0)},
+		{"either day field, weekday first", "0 0 13 * fri", time.UTC, utc(2026, 1, 1, 0, 0), utc(2026, 1, 2, 0, 0)},
+		{"either day field, date first", "0 0 13 * fri", time.UTC, utc(2026, 1, 10, 0, 0), utc(2026, 1, 13, 0, 0)},
+		{"31st skips short months", "0 0 31 * *", time.UTC, utc(2026, 1, 31, 0, 0), utc(2026, 3, 31, 0, 0)},
+		{"leap day", "0 0 29 2 *", time.UTC, utc(2026, 3, 1, 0, 0), utc(2028, 2, 29, 0, 0)},
+		{"never", "0 0 30 2 *", time.UTC, utc(2026, 1, 1, 0, 0), time.Time{}},
+		{"macro", "@daily", time.UTC, utc(2026, 1, 5, 10, 0), utc(2026, 1, 6, 0, 0)},
+		{"interval", "@every 15m", time.UTC, time.Date(2026, 1, 5, 10, 7, 30, 0, time.UTC), time.Date(2026, 1, 5, 10, 22, 30, 0, time.UTC)},
+		{"local time", "0 9 * * *", newYork, utc(2026, 1, 5, 13, 0), utc(2026, 1, 5, 14, 0)},
+
+		// Clocks spring forward from 2:00 EST to 3:00 EDT on 8 March 2026
+		{"across spring forward", "0 9 * * *", newYork, utc(2026, 3, 7, 14, 0), utc(2026, 3, 8, 13, 0)},
+		{"skipped time", "30 2 * * *", newYork, utc(2026, 3, 7, 17, 0), utc(2026, 3, 9, 6, 30)},
+		{"skipped time that day", "30 2 * * *", newYork, utc(2026, 3, 8, 5, 0), utc(2026, 3, 9, 6, 30)},
+		{"step over spring forward", "*/30 * * * *", newYork, utc(2026, 3, 8, 6, 30), utc(2026, 3, 8, 7, 0)},
+
+		// Clocks fall back from 2:00 EDT to 1:00 EST on 1 November 2026
+		{"before fall back", "30 1 * * *", newYork, utc(2026, 11, 1, 4, 0), utc(2026, 11, 1, 5, 30)},
+		{"repeated time runs once", "30 1 * * *", newYork, utc(2026, 11, 1, 5, 30), utc(2026, 11, 2, 6, 30)},
+		{"repeated hour runs once", "0 * * * *", newYork, utc(2026, 11, 1, 5, 0), utc(2026, 11, 1, 7, 0)},
+		{"across fall back", "0 9 * * *", newYork, utc(2026, 10, 31, 13, 0), utc(2026, 11, 1, 14, 0)},
+	} {
+		schedule, err := Parse(tc.spec, tc.loc)
+		if err != nil {
+			t.Errorf("%s: Parse(%q): %v", tc.name, tc.spec, err)
+			continue
+		}
+		if got := schedule.Next(tc.from); !got.Equal(tc.want) {
+			t.Errorf("%s: %q after %s = %s, want %s", tc.name, tc.spec, tc.from.In(tc.loc), got, tc.want.In(tc.loc))
+		}
+	}
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"0 0 * foo *",
+		"0 0 * * someday",
+		"@every 30s",
+		"@every soon",
+		"@fortnightly",
+	} {
+		if _, err := Parse(spec, time.UTC); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import "time"
+
+// Hours tells when a market is open, as market.Calendar does
+type Hours interface {
+	IsOpen(t time.Time) bool
+	// NextOpen returns t when the market is open at t, or else when it
+	// next opens, or the zero time when it never does
+	NextOpen(t time.Time) time.Time
+}
+
+// openSchedule runs a schedule only while a market is open
+type openSchedule struct {
+	schedule Schedule
+	hours    Hours
+}
+
+// WhileOpen returns a schedule running at the times of schedule that fall
+// within hours, skipping those while the market is closed. An "@every"
+// schedule starts over at the opening.
+func WhileOpen(schedule Schedule, hours Hours) Schedule {
+	return openSchedule{schedule: schedule, hours: hours}
+}
+
+// Next returns the first run time after t at which the market is open. A
+// schedule never meeting an open market, such as one running on weekends
+// only, has no next run.
+func (s openSchedule) Next(t time.Time) time.Time {
+	// Bounds the search for schedules that keep missing the session
+	for i := 0; i < 1000; i++ {
+		next := s.schedule.Next(t)
+		if next.IsZero() || s.hours.IsOpen(next) {
+			return next
+		}
+		open := s.hours.NextOpen(next)
+		if open.IsZero() {
+			return time.Time{}
+		}
+		// Intervals start over when the market opens
+		if _, ok := s.schedule.(everySchedule); ok {
+			return open
+		}
+		// The schedule's next time may fall right at the opening
+		t = open.Add(-time.Nanosecond)
+		if t.Before(next) {
+			t = next
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Job is a named task run on a schedule
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs jobs when they are due. Jobs run one at a time; a job
+// that comes due while another is running starts once it finishes, and
+// runs missed while busy are skipped rather than queued.
+type Scheduler struct {
+	jobs    []Job
+	now     func() time.Time
+	onError func(job string, err error)
+}
+
+// New creates a scheduler for jobs. onError, if not nil, is called with
+// the errors jobs return.
+func New(jobs []Job, onError func(job string, err error)) *Scheduler {
+	return &Scheduler{jobs: jobs, now: time.Now, onError: onError}
+}
+
+// Upcoming is a job and when it runs next
+type Upcoming struct {
+	Job string
+	At  time.Time
+}
+
+// Upcoming returns when every job runs next, soonest first
+func (s *Scheduler) Upcoming() []Upcoming {
+	now := s.now()
+	upcoming := make([]Upcoming, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		if at := job.Schedule.Next(now); !at.IsZero() {
+			upcoming = append(upcoming, Upcoming{Job: job.Name, At: at})
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].At.Before(upcoming[j].At)
+	})
+	return upcoming
+}
+
+// Run runs jobs as they come due until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	if len(s.jobs) == 0 {
+		return fmt.Errorf("no jobs scheduled")
+	}
+
+	next := make([]time.Time, len(s.jobs))
+	now := s.now()
+	for i, job := range s.jobs {
+		next[i] = job.Schedule.Next(now)
+	}
+
+	for {
+		due := -1
+		for i, at := range next {
+			if !at.IsZero() && (due < 0 || at.Before(next[due])) {
+				due = i
+			}
+		}
+		if due < 0 {
+			return fmt.Errorf("no job will run again")
+		}
+
+		timer := time.NewTimer(time.Until(next[due]))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		job := s.jobs[due]
+		if err := job.Run(ctx); err != nil && ctx.Err() == nil && s.onError != nil {
+			s.onError(job.Name, err)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Runs of this job missed while it was running are skipped
+		next[due] = job.Schedule.Next(s.now())
+	}
+}
@@ -0,0 +1,308 @@
+// Package screener filters valuation results with simple conditions such
+// as "upside>20", "pe<=15" or "sector=Technology".
+package screener
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// numericFields maps field names to the numeric values they read
+var numericFields = map[string]func(*models.ValuationResult) float64{
+	"upside":         func(r *models.ValuationResult) float64 { return r.UpsidePercentage },
+	"fair_value":     func(r *models.ValuationResult) float64 { return r.FairValue },
+	"price":          func(r *models.ValuationResult) float64 { return r.CurrentPrice },
+	"difference":     func(r *models.ValuationResult) float64 { return r.PriceDifference },
+	"book_value":     func(r *models.ValuationResult) float64 { return r.BookValue },
+	"dcf_value":      func(r *models.ValuationResult) float64 { return r.DCFValue },
+	"comps_value":    func(r *models.ValuationResult) float64 { return r.CompsValue },
+	"pe":             func(r *models.ValuationResult) float64 { return r.PERatio },
+	"eps":            func(r *models.ValuationResult) float64 { return r.EPS },
+	"fcf":            func(r *models.ValuationResult) float64 { return r.FCFPerShare },
+	"growth":         func(r *models.ValuationResult) float64 { return r.GrowthRate * 100 }, // percent
+	"market_cap":     func(r *models.ValuationResult) float64 { return float64(r.MarketCap) },
+	"peg":            peg,
+	"vs_50dma":       func(r *models.ValuationResult) float64 { return priceVsMovingAverage(r, 50) },  // percent
+	"vs_200dma":      func(r *models.ValuationResult) float64 { return priceVsMovingAverage(r, 200) }, // percent
+	"rel_pe":         relativePE,
+	"ey_spread":      relativeField(func(m *models.MarketRelative) float64 { return m.EarningsYieldSpread }), // points
+	"rel_upside":     relativeField(func(m *models.MarketRelative) float64 { return m.Upside }),              // points
+	"fcf_yield":      yield((*models.ValuationResult).FCFYield),                                              // percent
+	"earnings_yield": yield((*models.ValuationResult).EarningsYield),                                         // percent
+	"dividend_yield": yield((*models.ValuationResult).DividendYield),                                         // percent
+	"net_yield":      yield((*models.ValuationResult).NetDividendYield),                                      // percent
+	"payout_ratio":   yield((*models.ValuationResult).PayoutRatio),                                           // percent
+	"sentiment":      sentiment,
+	"revision":       revision, // points
+	"rating":         rating,
+}
+
+// unavailableFields are well-known screening fields the data sources
+// cannot supply, mapped to the reason
+var unavailableFields = map[string]string{
+	"fscore": "the Piotroski F-score needs two years of income statement, balance sheet and cash flow data, which the data sources do not provide",
+}
+
+// peg returns the P/E ratio divided by the growth rate in percent. Stocks
+// without positive earnings or growth have no meaningful PEG and never
+// satisfy an upper bound.
+func peg(r *models.ValuationResult) float64 {
+	if r.PERatio <= 0 || r.GrowthRate <= 0 {
+		return math.Inf(1)
+	}
+	return r.PERatio / (r.GrowthRate * 100)
+}
+
+// priceVsMovingAverage returns how far the price is above its days-day
+// moving average in percent, or NaN when the average is unknown
+func priceVsMovingAverage(r *models.ValuationResult, days int) float64 {
+	if percent, ok := r.PriceVsMovingAverage(days); ok {
+		return percent
+	}
+	return math.NaN()
+}
+
+// relativePE returns the P/E ratio as a multiple of the benchmark's. Like
+// the moving average fields, results without one match no condition.
+func relativePE(r *models.ValuationResult) float64 {
+	if r.Relative == nil || r.Relative.PERatio == 0 {
+		return math.NaN()
+	}
+	return r.Relative.PERatio
+}
+
+// relativeField returns a field reading a figure relative to the market
+// benchmark, which is unknown to results valued without one
+func relativeField(read func(*models.MarketRelative) float64) func(*models.ValuationResult) float64 {
+	return func(r *models.ValuationResult) float64 {
+		if r.Relative == nil {
+			return math.NaN()
+		}
+		return read(r.Relative)
+	}
+}
+
+// yield returns a field reading a yield, which is unknown to results
+// without a price
+func yield(read func(*models.ValuationResult) (float64, bool)) func(*models.ValuationResult) float64 {
+	return func(r *models.ValuationResult) float64 {
+		if value, ok := read(r); ok {
+			return value
+		}
+		return math.NaN()
+	}
+}
+
+// sentiment returns the news sentiment score, from -1 to 1, or NaN when
+// it is unknown
+func sentiment(r *models.ValuationResult) float64 {
+	if score, ok := r.SentimentScore(); ok {
+		return score
+	}
+	return math.NaN()
+}
+
+// revision returns how many percentage points the growth estimate moved
+// since the revision window, or NaN when no earlier estimate is recorded
+func revision(r *models.ValuationResult) float64 {
+	if r.Revision == nil {
+		return math.NaN()
+	}
+	return r.Revision.Change * 100
+}
+
+// rating returns the rating of the notes kept on the ticker, or NaN when
+// it is not rated
+func rating(r *models.ValuationResult) float64 {
+	if r.Note == nil || r.Note.Rating == 0 {
+		return math.NaN()
+	}
+	return float64(r.Note.Rating)
+}
+
+// textFields maps field names to the text values they read
+var textFields = map[string]func(*models.ValuationResult) string{
+	"ticker":  func(r *models.ValuationResult) string { return r.Ticker },
+	"sector":  func(r *models.ValuationResult) string { return models.NormalizeSector(r.Sector) },
+	"status":  func(r *models.ValuationResult) string { return r.Status },
+	"company": func(r *models.ValuationResult) string { return r.CompanyName },
+	"tag":     func(r *models.ValuationResult) string { return r.Tag },
+}
+
+// conditionPattern matches one condition; text values may be quoted to
+// include spaces
+var conditionPattern = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*(>=|<=|!=|=|>|<)\s*("[^"]*"|\S+)`)
+
+// Condition compares one field of a valuation result with a value
+type Condition struct {
+	Field string
+	Op    string
+	Value string
+	num   float64
+}
+
+// Criteria is a set of conditions that must all hold
+type Criteria []Condition
+
+// Parse parses conditions such as "upside>20 pe<15 sector=Technology".
+// Numeric fields accept every operator; text fields accept = and != and
+// compare case-insensitively.
+func Parse(expr string) (Criteria, error) {
+	var criteria Criteria
+	rest := strings.TrimSpace(expr)
+	for rest != "" {
+		match := conditionPattern.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, fmt.Errorf("invalid condition %q (expected FIELD OP VALUE, e.g. upside>20)", strings.Fields(rest)[0])
+		}
+		rest = strings.TrimSpace(rest[len(match[0]):])
+		rest = strings.TrimLeft(rest, ", ")
+
+		cond, err := newCondition(strings.ToLower(match[1]), match[2], strings.Trim(match[3], `"`))
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, cond)
+	}
+	return criteria, nil
+}
+
+// newCondition validates a field, operator and value
+func newCondition(field, op, value string) (Condition, error) {
+	cond := Condition{Field: field, Op: op, Value: value}
+	if _, ok := numericFields[field]; ok {
+		num, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil {
+			return cond, fmt.Errorf("%s needs a numeric value, got %q", field, value)
+		}
+		cond.num = num
+		return cond, nil
+	}
+	if _, ok := textFields[field]; ok {
+		if op != "=" && op != "!=" {
+			return cond, fmt.Errorf("%s only supports = and !=", field)
+		}
+		// Sectors match under any of their labels, such as "Information
+		// Technology" for Technology
+		if field == "sector" {
+			cond.Value = models.NormalizeSector(value)
+		}
+		return cond, nil
+	}
+	if reason, ok := unavailableFields[field]; ok {
+		return cond, fmt.Errorf("%s is not supported: %s", field, reason)
+	}
+	return cond, fmt.Errorf("unknown field %q (available: %s)", field, strings.Join(Fields(), ", "))
+}
+
+// Fields returns the names of all fields conditions can use
+func Fields() []string {
+	fields := make([]string, 0, len(numericFields)+len(textFields))
+	for field := range numericFields {
+		fields = append(fields, field)
+	}
+	for field := range textFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// IsNumeric reports whether field is a numeric field
+func IsNumeric(field string) bool {
+	_, ok := numericFields[field]
+	return ok
+}
+
+// Value returns the value of field for result: a float64 for numeric
+// fields, NaN when result does not know it, or a string for text fields.
+// It reports false for fields conditions cannot use.
+func Value(result *models.ValuationResult, field string) (any, bool) {
+	if read, ok := numericFields[field]; ok {
+		return read(result), true
+	}
+	if read, ok := textFields[field]; ok {
+		return read(result), true
+	}
+	return nil, false
+}
+
+// Match reports whether result satisfies the condition
+func (c Condition) Match(result *models.ValuationResult) bool {
+	if read, ok := numericFields[c.Field]; ok {
+		value := read(result)
+		// Unknown values satisfy no condition
+		if math.IsNaN(value) {
+			return false
+		}
+		switch c.Op {
+		case ">":
+			return value > c.num
+		case ">=":
+			return value >= c.num
+		case "<":
+			return value < c.num
+		case "<=":
+			return value <= c.num
+		case "=":
+			return value == c.num
+		case "!=":
+			return value != c.num
+		}
+		return false
+	}
+
+	equal := strings.EqualFold(textFields[c.Field](result), c.Value)
+	if c.Op == "!=" {
+		return !equal
+	}
+	return equal
+}
+
+// String formats the condition as it would be parsed
+func (c Condition) String() string {
+	if strings.ContainsAny(c.Value, " \t") {
+		return fmt.Sprintf("%s%s%q", c.Field, c.Op, c.Value)
+	}
+	return c.Field + c.Op + c.Value
+}
+
+// Match reports whether result satisfies every condition. Results of
+// tickers that could not be valued match no criteria.
+func (c Criteria) Match(result *models.ValuationResult) bool {
+	if result.Failed() {
+		return false
+	}
+	for _, cond := range c {
+		if !cond.Match(result) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the results satisfying every condition
+func (c Criteria) Filter(results []*models.ValuationResult) []*models.ValuationResult {
+	var matches []*models.ValuationResult
+	for _, result := range results {
+		if c.Match(result) {
+			matches = append(matches, result)
+		}
+	}
+	return matches
+}
+
+// String formats the criteria as they would be parsed
+func (c Criteria) String() string {
+	parts := make([]string, len(c))
+	for i, cond := range c {
+		parts[i] = cond.String()
+	}
+	return strings.Join(parts, " ")
+}
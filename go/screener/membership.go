@@ -0,0 +1,96 @@
+package screener
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// Membership records which tickers matched a screen when it last ran
+type Membership struct {
+	Screen    string    `json:"screen"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Tickers   []string  `json:"tickers"`
+}
+
+// Members returns the sorted tickers of results
+func Members(results []*models.ValuationResult) []string {
+	tickers := make([]string, len(results))
+	for i, result := range results {
+		tickers[i] = result.Ticker
+	}
+	sort.Strings(tickers)
+	return tickers
+}
+
+// Diff returns the tickers that entered and left a screen between two
+// memberships
+func Diff(previous, current []string) (added, removed []string) {
+	before := make(map[string]bool, len(previous))
+	for _, ticker := range previous {
+		before[ticker] = true
+	}
+	now := make(map[string]bool, len(current))
+	for _, ticker := range current {
+		now[ticker] = true
+		if !before[ticker] {
+			added = append(added, ticker)
+		}
+	}
+	for _, ticker := range previous {
+		if !now[ticker] {
+			removed = append(removed, ticker)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// MembershipPath returns the file recording the membership of a named
+// screen in dir
+func MembershipPath(dir, screen string) string {
+	return filepath.Join(dir, "screens", screen+".json")
+}
+
+// LoadMembership reads a recorded membership. A missing file is not an
+// error and returns nil, since the screen has simply not run before.
+func LoadMembership(path string) (*Membership, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read screen membership: %w", err)
+	}
+
+	var membership Membership
+	if err := json.Unmarshal(data, &membership); err != nil {
+		return nil, fmt.Errorf("failed to parse screen membership %s: %w", path, err)
+	}
+	return &membership, nil
+}
+
+// SaveMembership records a membership, replacing the previous one
+func SaveMembership(path string, membership *Membership) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create screens directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(membership, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode screen membership: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write screen membership: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// BenchmarkSource is implemented by providers that know the valuation of
+// the market as a whole, so stocks can be related to it
+type BenchmarkSource interface {
+	// FetchBenchmark returns the trailing P/E of symbol, a fund tracking
+	// an index such as SPY for the S&P 500
+	FetchBenchmark(ctx context.Context, symbol string) (*models.Benchmark, error)
+}
+
+// fallbackBenchmarkPE is the trailing P/E of the S&P 500 when the built-in
+// fallback fundamentals were current
+const fallbackBenchmarkPE = 21.0
+
+// FetchBenchmark returns the trailing P/E of symbol from its Yahoo Finance
+// key statistics. When they cannot be scraped and fallback data is enabled,
+// the P/E of the S&P 500 when the fallback fundamentals were current is
+// returned, marked as a fallback.
+func (df *DataFetcher) FetchBenchmark(ctx context.Context, symbol string) (*models.Benchmark, error) {
+	var err error
+	if df.features.EnableScraping {
+		stockData := &models.StockData{Ticker: symbol}
+		if err = df.scrapePage(ctx, keyStatisticsPage, symbol, stockData); err == nil {
+			if stockData.PERatio > 0 {
+				return models.NewBenchmark(symbol, stockData.PERatio, df.clock.Now()), nil
+			}
+			err = fmt.Errorf("no P/E ratio found for %s: %w", symbol, ErrParse)
+		}
+	} else {
+		err = fmt.Errorf("scraping is disabled")
+	}
+
+	if !df.features.EnableFallbackData {
+		return nil, err
+	}
+	df.logger.Printf("Using the fallback P/E of the benchmark %s: %v\n", symbol, err)
+	benchmark := models.NewBenchmark(symbol, fallbackBenchmarkPE, fallbackDataAsOf)
+	benchmark.Fallback = true
+	return benchmark, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ShareCountSource is implemented by providers that know the history of a
+// company's share count, so the per-share growth that buybacks bring can
+// be valued
+type ShareCountSource interface {
+	// ShareCounts returns the yearly diluted share counts of ticker,
+	// oldest first
+	ShareCounts(ctx context.Context, ticker string) ([]models.ShareCount, error)
+}
+
+// shareCountYears is how many fiscal years of share counts are returned,
+// so buybacks are measured over the three years between the first and last
+const shareCountYears = 4
+
+// yahooTimeseriesResponse is a Yahoo Finance fundamentals timeseries
+// response, whose results each hold one of the requested series under
+// its type
+type yahooTimeseriesResponse struct {
+	Timeseries struct {
+		Result []map[string]json.RawMessage `json:"result"`
+	} `json:"timeseries"`
+}
+
+// yahooTimeseriesPoint is the value of a series for one period
+type yahooTimeseriesPoint struct {
+	AsOfDate      string `json:"asOfDate"`
+	ReportedValue struct {
+		Raw float64 `json:"raw"`
+	} `json:"reportedValue"`
+}
+
+// series returns the values of the named series by the date they are as
+// of, skipping periods without a value
+func (r *yahooTimeseriesResponse) series(name string) map[time.Time]float64 {
+	values := make(map[time.Time]float64)
+	for _, result := range r.Timeseries.Result {
+		raw, ok := result[name]
+		if !ok {
+			continue
+		}
+		var points []*yahooTimeseriesPoint
+		if err := json.Unmarshal(raw, &points); err != nil {
+			continue
+		}
+		for _, point := range points {
+			if point == nil {
+				continue
+			}
+			date, err := time.Parse(time.DateOnly, point.AsOfDate)
+			if err != nil {
+				continue
+			}
+			values[date] = point.ReportedValue.Raw
+		}
+	}
+	return values
+}
+
+// ShareCounts returns the weighted average diluted share counts of the
+// last fiscal years of ticker from the Yahoo Finance fundamentals
+// timeseries API
+func (df *DataFetcher) ShareCounts(ctx context.Context, ticker string) ([]models.ShareCount, error) {
+	if !df.features.EnableYahooAPI {
+		return nil, fmt.Errorf("share counts require the Yahoo Finance API to be enabled")
+	}
+	now := df.clock.Now()
+	query := url.Values{
+		"symbol":  {ticker},
+		"type":    {"annualDilutedAverageShares"},
+		"period1": {strconv.FormatInt(now.AddDate(-shareCountYears-1, 0, 0).Unix(), 10)},
+		"period2": {strconv.FormatInt(now.Unix(), 10)},
+	}
+	response, err := withRetry(ctx, func() (*yahooTimeseriesResponse, error) {
+		return df.fetchTimeseriesOnce(ctx, ticker, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var counts []models.ShareCount
+	for date, shares := range response.series("annualDilutedAverageShares") {
+		if shares > 0 {
+			counts = append(counts, models.ShareCount{Date: date, Shares: int64(shares)})
+		}
+	}
+	if len(counts) == 0 {
+		return nil, &FetchError{Source: "query1.finance.yahoo.com", Cause: ErrSymbolNotFound,
+			Err: fmt.Errorf("no share counts found for %s", ticker)}
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Date.Before(counts[j].Date)
+	})
+	if len(counts) > shareCountYears {
+		counts = counts[len(counts)-shareCountYears:]
+	}
+	return counts, nil
+}
+
+// fetchTimeseriesOnce makes a single request to the Yahoo Finance
+// fundamentals timeseries API
+func (df *DataFetcher) fetchTimeseriesOnce(ctx context.Context, ticker string, query url.Values) (*yahooTimeseriesResponse, error) {
+	u := &url.URL{
+		Scheme:   "https",
+		Host:     "query1.finance.yahoo.com",
+		Path:     "/ws/fundamentals-timeseries/v1/finance/timeseries/" + ticker,
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequestWithContext(withSource(ctx, SourceYahooTimeseries), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := df.httpClient.Do(req)
+	if err != nil {
+		return nil, requestError(req, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, requestError(req, fmt.Errorf("failed to read response body: %w", err))
+	}
+	var response yahooTimeseriesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, parseError(u.Hostname(), fmt.Errorf("failed to parse JSON response: %w", err))
+	}
+	return &response, nil
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// CacheStats summarizes the contents of a cache directory
+type CacheStats struct {
+	Dir       string
+	Entries   int
+	Expired   int
+	SizeBytes int64
+}
+
+// Cache stores fetched stock data on disk, one JSON file per ticker
+type Cache struct {
+	dir   string
+	ttl   time.Duration
+	clock Clock
+	mutex sync.Mutex
+}
+
+// NewCache creates a cache in dir whose entries expire after ttl
+func NewCache(dir string, ttl time.Duration) (*Cache, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultCacheDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return &Cache{dir: dir, ttl: ttl, clock: SystemClock}, nil
+}
+
+// DefaultCacheDir returns the per-user cache directory for the application
+func DefaultCacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(base, "fair-stock-value"), nil
+}
+
+// SetClock sets the clock entries expire by. A fixed clock keeps entries
+// fetched at that time from ever expiring.
+func (c *Cache) SetClock(clock Clock) {
+	c.clock = clock
+}
+
+// Dir returns the directory backing the cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Get returns cached data for ticker if present and not expired
+func (c *Cache) Get(ticker string) (*models.StockData, bool) {
+	stockData, ok := c.Load(ticker)
+	if !ok || c.isExpired(stockData.FetchTime) {
+		return nil, false
+	}
+	return stockData, true
+}
+
+// Load returns cached data for ticker if present, however old it is
+func (c *Cache) Load(ticker string) (*models.StockData, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	data, err := os.ReadFile(c.path(ticker))
+	if err != nil {
+		return nil, false
+	}
+
+	var stockData models.StockData
+	if err := json.Unmarshal(data, &stockData); err != nil {
+		return nil, false
+	}
+	return &stockData, true
+}
+
+// Put stores data for its ticker, replacing any previous entry
+func (c *Cache) Put(stockData *models.StockData) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	data, err := json.MarshalIndent(stockData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see partial entries
+	tmp := c.path(stockData.Ticker) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return os.Rename(tmp, c.path(stockData.Ticker))
+}
+
+// Check verifies that the cache directory can be written to
+func (c *Cache) Check() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	probe := filepath.Join(c.dir, ".probe")
+	if err := os.WriteFile(probe, nil, 0o644); err != nil {
+		return fmt.Errorf("cache directory is not writable: %w", err)
+	}
+	return os.Remove(probe)
+}
+
+// Clear removes all cache entries and returns how many were deleted
+func (c *Cache) Clear() (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	files, err := c.entryFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Stats returns entry counts and disk usage of the cache
+func (c *Cache) Stats() (CacheStats, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := CacheStats{Dir: c.dir}
+	files, err := c.entryFiles()
+	if err != nil {
+		return stats, err
+	}
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		stats.Entries++
+		stats.SizeBytes += info.Size()
+
+		if data, err := os.ReadFile(file); err == nil {
+			var stockData models.StockData
+			if json.Unmarshal(data, &stockData) == nil && c.isExpired(stockData.FetchTime) {
+				stats.Expired++
+			}
+		}
+	}
+	return stats, nil
+}
+
+// Tickers returns the tickers that currently have cache entries
+func (c *Cache) Tickers() ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	files, err := c.entryFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := make([]string, 0, len(files))
+	for _, file := range files {
+		tickers = append(tickers, strings.TrimSuffix(filepath.Base(file), ".json"))
+	}
+	return tickers, nil
+}
+
+// isExpired reports whether an entry fetched at fetchTime is past the TTL
+func (c *Cache) isExpired(fetchTime time.Time) bool {
+	return c.ttl > 0 && c.clock.Now().Sub(fetchTime) > c.ttl
+}
+
+// entryFiles lists the cache entry files
+func (c *Cache) entryFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache entries: %w", err)
+	}
+	return files, nil
+}
+
+// path returns the file path for a ticker's cache entry
+func (c *Cache) path(ticker string) string {
+	// Tickers like BRK/B must not escape the cache directory
+	safe := strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(strings.ToUpper(ticker))
+	return filepath.Join(c.dir, safe+".json")
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Clock tells the fetchers, the cache and the analyzer the time. Replacing
+// the system clock with a fixed one makes fetch times, cache expiry and
+// run timestamps reproducible.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock tells the real time
+var SystemClock Clock = systemClock{}
+
+// systemClock is the real time
+type systemClock struct{}
+
+// Now returns time.Now()
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// fixedClock always tells the same time
+type fixedClock struct {
+	now time.Time
+}
+
+// FixedClock returns a clock that always tells t, however much time passes
+func FixedClock(t time.Time) Clock {
+	return fixedClock{now: t}
+}
+
+// Now returns the fixed time
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+// Random is a source of random numbers that is safe for concurrent use. The
+// fetchers use it to pick user agents and request delays; a seeded source
+// makes those choices, and run IDs, reproducible.
+type Random struct {
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+// NewRandom returns a Random drawing from src
+func NewRandom(src rand.Source) *Random {
+	return &Random{rand: rand.New(src)}
+}
+
+// Intn returns a random number in [0, n)
+func (r *Random) Intn(n int) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rand.Intn(n)
+}
+
+// Read fills p with random bytes
+func (r *Random) Read(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rand.Read(p)
+}
@@ -2,21 +2,24 @@ package services
 
 import (
 	"context"
-	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"fair-stock-value/models"
+	"github.com/lesnerd/fair-stock-value/go/finparse"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/symbols"
 	"github.com/PuerkitoBio/goquery"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // YahooChartResponse represents the response from Yahoo Finance Chart API
@@ -45,6 +48,9 @@ type YahooChartResponse struct {
 				ValidRanges             []string `json:"validRanges"`
 			} `json:"meta"`
 			Timestamp []int64 `json:"timestamp"`
+			Events struct {
+				Splits map[string]YahooSplitEvent `json:"splits"`
+			} `json:"events"`
 			Indicators struct {
 				Quote []struct {
 					Close  []float64 `json:"close"`
@@ -62,104 +68,493 @@ type YahooChartResponse struct {
 // DataFetcher handles fetching stock data from various sources
 type DataFetcher struct {
 	httpClient       *http.Client
+	growthFetcher    *GrowthRateFetcher // configured along with the data fetcher
+	growth           GrowthSource       // growthFetcher unless replaced
 	peRatioCache     map[string]float64
 	cacheMutex       sync.RWMutex
 	fallbackPERatios map[string]float64
 	lastRequestTime  time.Time
 	requestMutex     sync.Mutex
+	features         models.DataFeatures
+	logger           Logger
+	clock            Clock
+	random           *Random // shared with growthFetcher
+	splits           map[string]splitLookup
+	splitsMutex      sync.Mutex
+	memory           *MemoryCache // nil unless growth consensus is kept in memory
+	selectors        Selectors    // shared with growthFetcher
+	yahooSession     *YahooSession
 }
 
 // NewDataFetcher creates a new instance of DataFetcher
 func NewDataFetcher() *DataFetcher {
+	growthFetcher := NewGrowthRateFetcher()
+	httpClient := newTracingClient(&http.Client{
+		Timeout:   10 * time.Second,
+		Transport: sharedTransport,
+	})
 	return &DataFetcher{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		httpClient:       httpClient,
+		growthFetcher:    growthFetcher,
+		growth:           growthFetcher,
 		peRatioCache:     make(map[string]float64),
 		fallbackPERatios: getFallbackPERatios(),
+		splits:           make(map[string]splitLookup),
+		features: models.DataFeatures{
+			EnableYahooAPI:        true,
+			EnableScraping:        true,
+			EnableGrowthConsensus: true,
+			EnableFallbackData:    true,
+		},
+		logger:    NewWriterLogger(os.Stdout),
+		clock:     SystemClock,
+		random:    growthFetcher.random,
+		selectors: growthFetcher.selectors,
+		// The session shares the client, so its requests are observed
+		// and sent through the same transport
+		yahooSession: NewYahooSession(httpClient),
 	}
 }
 
+// SetFeatures restricts which data acquisition capabilities the fetcher may use
+func (df *DataFetcher) SetFeatures(features models.DataFeatures) {
+	df.features = features
+	df.growthFetcher.SetFallbackEnabled(features.EnableFallbackData)
+}
+
+// SetLogger sets where progress and diagnostic messages are written
+func (df *DataFetcher) SetLogger(logger Logger) {
+	df.logger = logger
+	df.growthFetcher.SetLogger(logger)
+}
+
+// SetRequestObserver reports every HTTP request the fetcher makes to
+// observer. A nil observer stops reporting.
+func (df *DataFetcher) SetRequestObserver(observer RequestObserver) {
+	observeClient(df.httpClient, observer)
+	df.growthFetcher.SetRequestObserver(observer)
+}
+
+// SetTransport sends the fetcher's requests, including those for growth
+// rates, through transport instead of the transport shared by all
+// fetchers. A nil transport restores the shared one.
+func (df *DataFetcher) SetTransport(transport http.RoundTripper) {
+	setClientTransport(df.httpClient, transport)
+	df.growthFetcher.SetTransport(transport)
+}
+
+// SetClock sets the clock that stamps fetched data, growth rates and
+// scraper checks included
+func (df *DataFetcher) SetClock(clock Clock) {
+	df.clock = clock
+	df.growthFetcher.SetClock(clock)
+}
+
+// SetRandom sets the source of the user agents and request delays the
+// fetcher, and its growth rate fetcher, pick
+func (df *DataFetcher) SetRandom(random *Random) {
+	df.random = random
+	df.growthFetcher.SetRandom(random)
+}
+
+// SetSelectors sets the pages and selectors the fetcher, and its growth
+// rate fetcher, scrape
+func (df *DataFetcher) SetSelectors(selectors Selectors) {
+	df.selectors = selectors
+	df.growthFetcher.SetSelectors(selectors)
+}
+
+// SetMemoryCache keeps the growth consensus of every ticker in memory,
+// so later fetches of the ticker reuse it while it is current. A nil
+// cache stops keeping it.
+func (df *DataFetcher) SetMemoryCache(memory *MemoryCache) {
+	df.memory = memory
+}
+
+// SetGrowthSource replaces the fetcher's own growth rate consensus with
+// growth, which is then shared by every ticker the fetcher fetches. The
+// fetcher's logger, features and transport do not apply to it. A nil
+// source restores the fetcher's own.
+func (df *DataFetcher) SetGrowthSource(growth GrowthSource) {
+	if growth == nil {
+		growth = df.growthFetcher
+	}
+	df.growth = growth
+}
+
+// GetFeatures returns the currently enabled data acquisition capabilities
+func (df *DataFetcher) GetFeatures() models.DataFeatures {
+	return df.features
+}
+
 // FetchStockData fetches comprehensive stock data for a given ticker
 func (df *DataFetcher) FetchStockData(ctx context.Context, ticker string) (*models.StockData, error) {
+	ctx, span := tracer.Start(ctx, "DataFetcher.FetchStockData",
+		trace.WithAttributes(attribute.String("ticker", ticker)))
+	stockData, err := df.fetchStockData(ctx, ticker)
+	endSpan(span, err)
+	return stockData, err
+}
+
+// fetchStockData fetches stock data from every enabled source
+func (df *DataFetcher) fetchStockData(ctx context.Context, ticker string) (*models.StockData, error) {
 	stockData := &models.StockData{
-		Ticker:    ticker,
-		FetchTime: time.Now(),
+		Ticker:         ticker,
+		InstrumentType: symbols.InstrumentType(ticker),
+		FetchTime:      df.clock.Now(),
 	}
 
+	// Each source stamps the fields it set with the time it answered
+	before := *stockData
+	stamp := func() {
+		stockData.StampChanged(&before, df.clock.Now())
+		before = *stockData
+	}
+	// Fallback figures are recorded so those no source replaced are known
+	fallback := make(fallbackFigures)
+
 	// Try to fetch from Yahoo Finance API first (for current price)
-	if err := df.fetchFromYahooFinance(ctx, ticker, stockData); err != nil {
-		fmt.Printf("Yahoo Finance API failed for %s: %v, trying web scraping\n", ticker, err)
+	var priceErr error
+	if df.features.EnableYahooAPI {
+		if priceErr = df.fetchFromYahooFinance(ctx, ticker, stockData); priceErr != nil {
+			// Neither scraping nor fallback data can value an unknown symbol
+			if errors.Is(priceErr, ErrSymbolNotFound) {
+				return nil, fmt.Errorf("%s: %w", ticker, priceErr)
+			}
+			df.logger.Printf("Yahoo Finance API failed for %s: %v, trying web scraping\n", ticker, priceErr)
+		} else if df.features.EnableFallbackData {
+			// Only the price came from the chart, the rest from fallback data
+			fallback.record(&before, stockData, "current_price")
+		}
+		stamp()
 	}
 
-	// Fetch fundamental data from Yahoo Finance web scraping
-	fmt.Printf("Fetching fundamental data for %s from Yahoo Finance web scraping...\n", ticker)
-	
-	// Fetch key statistics (P/E, EPS, Market Cap, Book Value)
-	if err := df.fetchFundamentalData(ctx, ticker, stockData); err != nil {
-		fmt.Printf("Failed to fetch fundamental data for %s: %v\n", ticker, err)
+	// Cryptocurrencies and commodities have no fundamentals to fetch, nor
+	// should they fall back on a company's
+	if stockData.PriceOnly() {
+		if stockData.CurrentPrice <= 0 {
+			if priceErr != nil {
+				return nil, fmt.Errorf("no price data available for %s: %w", ticker, priceErr)
+			}
+			return nil, fmt.Errorf("no price data available for %s, which only the Yahoo Finance API prices", ticker)
+		}
+		if loc, err := exchangeLocation(stockData.ExchangeTimezone); err == nil {
+			stockData.In(loc)
+		}
+		return stockData, nil
 	}
-	
-	// Add delay between requests to avoid rate limiting
-	df.addRequestDelay()
-	
-	// Fetch financial data (FCF)
-	if err := df.fetchFinancialsData(ctx, ticker, stockData); err != nil {
-		fmt.Printf("Failed to fetch financials data for %s: %v\n", ticker, err)
+
+	// The growth consensus and quarterly statements need nothing of the
+	// other sources, so they are fetched while those are; each source's
+	// figures are still applied in the order below. Fetches left over when
+	// this returns early are cancelled.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var growth func() (GrowthConsensus, error)
+	if df.features.EnableGrowthConsensus {
+		df.logger.Printf("Fetching consensus growth rate for %s...\n", ticker)
+		growth = async(func() (GrowthConsensus, error) {
+			return df.fetchGrowthConsensus(ctx, ticker)
+		})
 	}
-	
-	// Add delay between requests to avoid rate limiting
-	df.addRequestDelay()
-	
-	// Fetch profile data (Sector, Company Name)
-	if err := df.fetchProfileData(ctx, ticker, stockData); err != nil {
-		fmt.Printf("Failed to fetch profile data for %s: %v\n", ticker, err)
+	var statements func() (*yahooTimeseriesResponse, error)
+	if df.features.EnableYahooAPI && priceErr == nil {
+		statements = async(func() (*yahooTimeseriesResponse, error) {
+			return df.fetchQuarterlyStatements(ctx, ticker)
+		})
 	}
 
+	// The quoteSummary API holds what the scraped pages embed, over any
+	// fallback figures, so the pages need not be scraped once it answered
+	summarized := false
+	if df.features.EnableYahooAPI && priceErr == nil {
+		if err := df.fetchQuoteSummary(ctx, ticker, stockData); err != nil {
+			df.logger.Printf("Yahoo Finance quote summary failed for %s: %v\n", ticker, err)
+		} else {
+			summarized = true
+		}
+		stamp()
+	}
+
+	if df.features.EnableScraping && !summarized {
+		// Fetch fundamental data from Yahoo Finance web scraping
+		df.logger.Printf("Fetching fundamental data for %s from Yahoo Finance web scraping...\n", ticker)
+		df.scrapePages(ctx, ticker, stockData, stamp)
+	}
+
+	// Trailing twelve months summed from the latest quarters replace the
+	// EPS and free cash flow of annual statements and scraped pages, and
+	// the latest balance sheet the tangible book value
+	if statements != nil {
+		response, err := statements()
+		if err == nil {
+			err = df.applyQuarterlyStatements(ticker, response, stockData)
+		}
+		if err != nil {
+			df.logger.Printf("Quarterly statements failed for %s: %v\n", ticker, err)
+		}
+		stamp()
+	}
+
+	// Sources label sectors and industries differently; the industry may
+	// tell the sector where no source did, ahead of the fallback sector
+	stockData.Classify()
+
 	// Use fallback data for any missing fields
-	df.applyFallbackForMissingData(ticker, stockData)
+	if df.features.EnableFallbackData {
+		df.applyFallbackForMissingData(ticker, stockData)
+		fallback.record(&before, stockData)
+		stamp()
+	}
+
+	// Without fallback data there is nothing to value against
+	if stockData.CurrentPrice <= 0 {
+		if priceErr != nil {
+			return nil, fmt.Errorf("no price data available for %s from enabled sources: %w", ticker, priceErr)
+		}
+		return nil, fmt.Errorf("no price data available for %s from enabled sources", ticker)
+	}
 
-	// Fetch P/E ratio from multiple sources (as backup)
+	// Derive the share count from market cap when no source reported it
+	if stockData.SharesOutstanding == 0 && stockData.MarketCap > 0 {
+		stockData.SharesOutstanding = int64(float64(stockData.MarketCap) / stockData.CurrentPrice)
+		stamp()
+	}
+
+	// Fetch P/E ratio from multiple sources (as backup), standing in the
+	// industry average when none has it. Both are fallback data, so without
+	// it the P/E is left missing.
 	if stockData.PERatio == 0 {
-		peRatio, err := df.fetchPERatio(ctx, ticker)
-		if err != nil || peRatio == 0 {
-			peRatio = df.getIndustryPERatio(stockData.Sector)
+		if df.features.EnableFallbackData {
+			peRatio, _ := df.fetchPERatio(ctx, ticker)
+			if peRatio == 0 {
+				peRatio = df.getIndustryPERatio(stockData.Sector)
+			}
+			stockData.PERatio = peRatio
+			stockData.PERatioEstimated = true
+			stamp()
+		} else {
+			df.logger.Printf("No P/E ratio for %s from enabled sources, leaving it missing\n", ticker)
 		}
-		stockData.PERatio = peRatio
 	}
 
 	// Fetch growth rate from multiple sources using crowd wisdom
 	// Always fetch consensus growth rate to override fallback data
-	fmt.Printf("Fetching consensus growth rate for %s...\n", ticker)
-	growthFetcher := NewGrowthRateFetcher()
-	if consensusGrowth, err := growthFetcher.FetchGrowthRateConsensus(ctx, ticker); err == nil {
-		stockData.GrowthRate = consensusGrowth
-	} else {
-		fmt.Printf("Failed to fetch consensus growth rate for %s: %v, using fallback or default\n", ticker, err)
-		// Keep existing growth rate if we have one, otherwise use default
-		if stockData.GrowthRate == 0 {
-			stockData.GrowthRate = 0.06 // Default 6% growth
+	if growth != nil {
+		if consensus, err := growth(); err == nil {
+			stockData.GrowthRate = consensus.Rate
+			stockData.RejectedGrowth = consensus.Rejected
+			stockData.GrowthSources = consensus.Sources
+			stockData.GrowthStdDev = consensus.StdDev
+			// A rate no source answered with is fallback data
+			if consensus.Fallback {
+				fallback["growth_rate"] = consensus.Rate
+			}
+		} else {
+			df.logger.Printf("Failed to fetch consensus growth rate for %s: %v, using fallback or default\n", ticker, err)
 		}
 	}
 
+	// Keep existing growth rate if we have one, otherwise use default
+	if stockData.GrowthRate == 0 && df.features.EnableFallbackData {
+		stockData.GrowthRate = 0.06 // Default 6% growth
+		fallback["growth_rate"] = stockData.GrowthRate
+	}
+	stamp()
+	stockData.FallbackFields = fallback.fields(stockData)
+
+	// Report times as the exchange does
+	if loc, err := exchangeLocation(stockData.ExchangeTimezone); err == nil {
+		stockData.In(loc)
+	}
+
 	return stockData, nil
 }
 
+// async runs fetch in a goroutine, returning a function that waits for
+// and returns its result
+func async[T any](fetch func() (T, error)) func() (T, error) {
+	done := make(chan struct{})
+	var result T
+	var err error
+	go func() {
+		defer close(done)
+		result, err = fetch()
+	}()
+	return func() (T, error) {
+		<-done
+		return result, err
+	}
+}
+
+// exchangeLocations caches the exchange timezones loaded so far
+var exchangeLocations sync.Map
+
+// exchangeLocation returns the named exchange timezone
+func exchangeLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, fmt.Errorf("no exchange timezone")
+	}
+	if loc, ok := exchangeLocations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	exchangeLocations.Store(name, loc)
+	return loc, nil
+}
+
 // fetchFromYahooFinance fetches data from Yahoo Finance API
 func (df *DataFetcher) fetchFromYahooFinance(ctx context.Context, ticker string, stockData *models.StockData) error {
+	chartResp, err := df.fetchChart(ctx, ticker, chartHistory)
+	if err != nil {
+		return err
+	}
+	
+	result := chartResp.Chart.Result[0]
+	// The closes are adjusted for splits, so the averages are set after
+	// any older figures have been
+	defer func() {
+		if len(result.Indicators.Quote) > 0 {
+			closes := result.Indicators.Quote[0].Close
+			stockData.MovingAverage50 = movingAverage(closes, 50)
+			stockData.MovingAverage200 = movingAverage(closes, 200)
+			stockData.AverageVolume = averageVolume(closes, result.Indicators.Quote[0].Volume, averageVolumeDays)
+		}
+	}()
+	
+	// Extract stock data from chart API
+	stockData.CurrentPrice = result.Meta.RegularMarketPrice
+	stockData.CompanyName = result.Meta.Symbol
+	stockData.Currency = result.Meta.Currency
+	stockData.Exchange = result.Meta.ExchangeName
+	stockData.ExchangeTimezone = result.Meta.ExchangeTimezoneName
+	if result.Meta.InstrumentType != "" {
+		stockData.InstrumentType = result.Meta.InstrumentType
+	}
+	if result.Meta.RegularMarketTime > 0 {
+		stockData.RegularMarketTime = time.Unix(result.Meta.RegularMarketTime, 0)
+	}
+	
+	// The chart API doesn't provide all the data we need, so we'll use fallback values
+	// and get the rest from our fallback data sources
+	if stockData.CurrentPrice > 0 {
+		if !df.features.EnableFallbackData || stockData.PriceOnly() {
+			return nil
+		}
+
+		// Use fallback data for missing fields, but keep the real current price
+		df.setFallbackData(ticker, stockData)
+		// The real price reflects splits since the fallback figures were current
+		shareRatio := df.adjustFallbackForSplits(ctx, ticker, stockData)
+		// Override with the real current price from the API
+		stockData.CurrentPrice = result.Meta.RegularMarketPrice
+		
+		// Calculate market cap if we have shares outstanding estimate
+		// This is approximate - in a real implementation you'd get this from another API
+		if fallbackData, exists := df.getFallbackStockData()[ticker]; exists {
+			// Estimate shares outstanding from fallback market cap and current price
+			if stockData.CurrentPrice > 0 && fallbackData.MarketCap > 0 {
+				estimatedShares := float64(fallbackData.MarketCap) / fallbackData.Price * shareRatio
+				stockData.MarketCap = int64(estimatedShares * stockData.CurrentPrice)
+			}
+		}
+	} else {
+		return fmt.Errorf("no valid price data found for %s", ticker)
+	}
+	
+	
+	return nil
+}
+
+// chartHistory asks for a year of daily closes with the price, enough for
+// a 200-day moving average
+var chartHistory = url.Values{"range": {"1y"}, "interval": {"1d"}}
+
+// movingAverage returns the average of the last days closes, or 0 when the
+// chart has fewer. Days without a close, which the API reports as null,
+// are skipped.
+func movingAverage(closes []float64, days int) float64 {
+	sum, n := 0.0, 0
+	for i := len(closes) - 1; i >= 0 && n < days; i-- {
+		if closes[i] > 0 {
+			sum += closes[i]
+			n++
+		}
+	}
+	if n < days {
+		return 0
+	}
+	return sum / float64(days)
+}
+
+// averageVolumeDays is how many sessions the average volume is taken over
+const averageVolumeDays = 50
+
+// averageVolume returns the average of the volumes of the last days
+// sessions, or 0 when the chart has fewer. Sessions are the days with a
+// close, so days no share traded count towards the average.
+func averageVolume(closes []float64, volumes []int64, days int) int64 {
+	var sum, n int64
+	for i := min(len(closes), len(volumes)) - 1; i >= 0 && n < int64(days); i-- {
+		if closes[i] > 0 {
+			sum += volumes[i]
+			n++
+		}
+	}
+	if n < int64(days) {
+		return 0
+	}
+	return sum / n
+}
+
+// FetchPrice fetches only the current market price for a ticker, which is
+// much cheaper than a full FetchStockData call
+func (df *DataFetcher) FetchPrice(ctx context.Context, ticker string) (float64, error) {
+	if !df.features.EnableYahooAPI {
+		return 0, fmt.Errorf("price refresh requires the Yahoo Finance API to be enabled")
+	}
+
+	chartResp, err := df.fetchChart(ctx, ticker, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	price := chartResp.Chart.Result[0].Meta.RegularMarketPrice
+	if price <= 0 {
+		return 0, fmt.Errorf("no valid price data found for %s", ticker)
+	}
+	return price, nil
+}
+
+// fetchChart fetches and decodes the Yahoo Finance chart API response,
+// retrying when the API is rate limiting or slow to answer
+func (df *DataFetcher) fetchChart(ctx context.Context, ticker string, query url.Values) (*YahooChartResponse, error) {
+	return withRetry(ctx, func() (*YahooChartResponse, error) {
+		return df.fetchChartOnce(ctx, ticker, query)
+	})
+}
+
+// fetchChartOnce makes a single request to the Yahoo Finance chart API,
+// with query parameters selecting the range, interval and events; without
+// any it returns the current quote only
+func (df *DataFetcher) fetchChartOnce(ctx context.Context, ticker string, query url.Values) (*YahooChartResponse, error) {
 	// Use the chart API which doesn't require a crumb
 	baseURL := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s", ticker)
 	
 	// Build URL
 	u, err := url.Parse(baseURL)
 	if err != nil {
-		return fmt.Errorf("failed to parse URL: %w", err)
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
+	u.RawQuery = query.Encode()
 	
 	// Create request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(withSource(ctx, SourceYahooChart), "GET", u.String(), nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	
 	// Set headers to mimic browser request
@@ -169,60 +564,54 @@ func (df *DataFetcher) fetchFromYahooFinance(ctx context.Context, ticker string,
 	// Make request
 	resp, err := df.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to fetch data: %w", err)
+		return nil, requestError(req, err)
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Yahoo Finance API returned status %d", resp.StatusCode)
+		return nil, statusError(resp)
 	}
 	
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, requestError(req, fmt.Errorf("failed to read response body: %w", err))
 	}
 	
 	// Parse JSON response
 	var chartResp YahooChartResponse
 	if err := json.Unmarshal(body, &chartResp); err != nil {
-		return fmt.Errorf("failed to parse JSON response: %w", err)
+		return nil, parseError(u.Hostname(), fmt.Errorf("failed to parse JSON response: %w", err))
 	}
 	
 	// Check if we have results
 	if len(chartResp.Chart.Result) == 0 {
-		return fmt.Errorf("no data found for ticker %s", ticker)
+		return nil, &FetchError{Source: u.Hostname(), Cause: ErrSymbolNotFound, Err: fmt.Errorf("no data found for ticker %s", ticker)}
 	}
 	
-	result := chartResp.Chart.Result[0]
-	
-	// Extract stock data from chart API
-	stockData.CurrentPrice = result.Meta.RegularMarketPrice
-	stockData.CompanyName = result.Meta.Symbol
-	
-	// The chart API doesn't provide all the data we need, so we'll use fallback values
-	// and get the rest from our fallback data sources
-	if stockData.CurrentPrice > 0 {
-		// Use fallback data for missing fields, but keep the real current price
-		df.setFallbackData(ticker, stockData)
-		// Override with the real current price from the API
-		stockData.CurrentPrice = result.Meta.RegularMarketPrice
-		
-		// Calculate market cap if we have shares outstanding estimate
-		// This is approximate - in a real implementation you'd get this from another API
-		if fallbackData, exists := df.getFallbackStockData()[ticker]; exists {
-			// Estimate shares outstanding from fallback market cap and current price
-			if stockData.CurrentPrice > 0 && fallbackData.MarketCap > 0 {
-				estimatedShares := float64(fallbackData.MarketCap) / fallbackData.Price
-				stockData.MarketCap = int64(estimatedShares * stockData.CurrentPrice)
-			}
+	return &chartResp, nil
+}
+
+// fetchGrowthConsensus fetches the consensus growth rate of ticker, with
+// the rates left out of it when the growth source reports them
+func (df *DataFetcher) fetchGrowthConsensus(ctx context.Context, ticker string) (GrowthConsensus, error) {
+	if df.memory != nil {
+		if consensus, ok := df.memory.GrowthConsensus(ticker); ok {
+			return consensus, nil
 		}
+	}
+
+	var consensus GrowthConsensus
+	var err error
+	if detailed, ok := df.growth.(GrowthDetailSource); ok {
+		consensus, err = detailed.FetchGrowthConsensus(ctx, ticker)
 	} else {
-		return fmt.Errorf("no valid price data found for %s", ticker)
+		consensus.Rate, err = df.growth.FetchGrowthRateConsensus(ctx, ticker)
 	}
-	
-	
-	return nil
+	if err == nil && df.memory != nil {
+		df.memory.PutGrowthConsensus(ticker, consensus)
+	}
+	return consensus, err
 }
 
 // fetchPERatio fetches P/E ratio from multiple sources
@@ -234,7 +623,7 @@ func (df *DataFetcher) fetchPERatio(ctx context.Context, ticker string) (float64
 	}
 	df.cacheMutex.RUnlock()
 
-	fmt.Printf("Fetching P/E ratios for %s from multiple sources...\n", ticker)
+	df.logger.Printf("Fetching P/E ratios for %s from multiple sources...\n", ticker)
 
 	// Collect P/E ratios from multiple sources
 	var peRatios []float64
@@ -250,7 +639,7 @@ func (df *DataFetcher) fetchPERatio(ctx context.Context, ticker string) (float64
 	}
 
 	if len(peRatios) == 0 {
-		fmt.Printf("No P/E ratios found for %s\n", ticker)
+		df.logger.Printf("No P/E ratios found for %s\n", ticker)
 		return 0, fmt.Errorf("no P/E ratio found for %s", ticker)
 	}
 
@@ -280,68 +669,40 @@ func (df *DataFetcher) fetchPERatio(ctx context.Context, ticker string) (float64
 	df.peRatioCache[ticker] = conservativePE
 	df.cacheMutex.Unlock()
 
-	fmt.Printf("Final P/E for %s: %.2f -> Conservative: %.2f\n", ticker, aggregatedPE, conservativePE)
+	df.logger.Printf("Final P/E for %s: %.2f -> Conservative: %.2f\n", ticker, aggregatedPE, conservativePE)
 	return conservativePE, nil
 }
 
-// fetchFundamentalData fetches fundamental data from Yahoo Finance key-statistics page
-func (df *DataFetcher) fetchFundamentalData(ctx context.Context, ticker string, stockData *models.StockData) error {
-	// Build key-statistics URL
-	keyStatsURL := fmt.Sprintf("https://finance.yahoo.com/quote/%s/key-statistics/", ticker)
-	
-	// Create request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", keyStatsURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	
-	// Set headers to mimic browser request
-	df.setRequestHeaders(req)
-	
-	// Make request
-	resp, err := df.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to fetch key-statistics data: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Yahoo Finance key-statistics returned status %d", resp.StatusCode)
-	}
-	
-	// Parse HTML document
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to parse HTML: %w", err)
-	}
-	
-	// Extract fundamental data using various selectors
-	if err := df.extractKeyStatistics(doc, stockData); err != nil {
-		return fmt.Errorf("failed to extract key statistics: %w", err)
-	}
-	
-	return nil
-}
-
 // extractKeyStatistics extracts key statistics from the parsed HTML document
-func (df *DataFetcher) extractKeyStatistics(doc *goquery.Document, stockData *models.StockData) error {
+func (df *DataFetcher) extractKeyStatistics(doc *goquery.Document, page PageSelectors, stockData *models.StockData) error {
 	var extractedData struct {
 		peRatio     float64
 		eps         float64
 		marketCap   string
 		bookValue   float64
 		found       bool
+
+		shares    string
+		beta      float64
+		dividend  float64
+		yield     float64
+		payout    float64
+		roe       float64
+		totalDebt string
+		cash      string
+		ebitda    string
+		revenue   string
 	}
 	
 	// Look for data in tables - Yahoo Finance uses different table structures
-	doc.Find("table").Each(func(i int, table *goquery.Selection) {
-		table.Find("tr").Each(func(j int, row *goquery.Selection) {
-			label := strings.TrimSpace(row.Find("td").First().Text())
-			value := strings.TrimSpace(row.Find("td").Last().Text())
+	doc.Find(page.Selector("tables")).Each(func(i int, table *goquery.Selection) {
+		table.Find(page.Selector("rows")).Each(func(j int, row *goquery.Selection) {
+			label := strings.TrimSpace(row.Find(page.Selector("cells")).First().Text())
+			value := strings.TrimSpace(row.Find(page.Selector("cells")).Last().Text())
 			
 			// Extract P/E ratio
 			if strings.Contains(strings.ToLower(label), "trailing p/e") || strings.Contains(strings.ToLower(label), "pe ratio") {
-				if peRatio, err := df.parseFloatValue(value); err == nil && peRatio > 0 {
+				if peRatio, err := finparse.Number(value); err == nil && peRatio > 0 {
 					extractedData.peRatio = peRatio
 					extractedData.found = true
 				}
@@ -349,7 +710,7 @@ func (df *DataFetcher) extractKeyStatistics(doc *goquery.Document, stockData *mo
 			
 			// Extract EPS
 			if strings.Contains(strings.ToLower(label), "diluted eps") || strings.Contains(strings.ToLower(label), "eps (ttm)") {
-				if eps, err := df.parseFloatValue(value); err == nil {
+				if eps, err := finparse.Number(value); err == nil {
 					extractedData.eps = eps
 					extractedData.found = true
 				}
@@ -363,16 +724,54 @@ func (df *DataFetcher) extractKeyStatistics(doc *goquery.Document, stockData *mo
 			
 			// Extract Book Value
 			if strings.Contains(strings.ToLower(label), "book value per share") {
-				if bookValue, err := df.parseFloatValue(value); err == nil {
+				if bookValue, err := finparse.Number(value); err == nil {
 					extractedData.bookValue = bookValue
 					extractedData.found = true
 				}
 			}
+
+			// Extract balance sheet, income and risk figures
+			lower := strings.ToLower(label)
+			switch {
+			case strings.HasPrefix(lower, "shares outstanding"):
+				extractedData.shares = value
+			case strings.HasPrefix(lower, "beta"):
+				if beta, err := finparse.Number(value); err == nil {
+					extractedData.beta = beta
+				}
+			case strings.Contains(lower, "forward annual dividend rate"):
+				if dividend, err := finparse.Number(value); err == nil {
+					extractedData.dividend = dividend
+				}
+			case strings.Contains(lower, "forward annual dividend yield"):
+				if yield, err := finparse.Percent(value); err == nil {
+					extractedData.yield = yield
+				}
+			case strings.HasPrefix(lower, "payout ratio"):
+				if payout, err := finparse.Percent(value); err == nil {
+					extractedData.payout = payout
+				}
+			case strings.HasPrefix(lower, "return on equity"):
+				if roe, err := finparse.Percent(value); err == nil {
+					extractedData.roe = roe
+				}
+			case strings.HasPrefix(lower, "total debt"):
+				extractedData.totalDebt = value
+			case strings.HasPrefix(lower, "total cash") && !strings.Contains(lower, "per share"):
+				extractedData.cash = value
+			case strings.HasPrefix(lower, "ebitda"):
+				extractedData.ebitda = value
+			case strings.HasPrefix(lower, "revenue") && !strings.Contains(lower, "per share"):
+				extractedData.revenue = value
+			default:
+				return
+			}
+			extractedData.found = true
 		})
 	})
 	
 	// Look for data in JSON scripts embedded in the page
-	doc.Find("script").Each(func(i int, script *goquery.Selection) {
+	doc.Find(page.Selector("scripts")).Each(func(i int, script *goquery.Selection) {
 		content := script.Text()
 		if strings.Contains(content, "root.App.main") {
 			// Extract JSON data if found
@@ -392,75 +791,69 @@ func (df *DataFetcher) extractKeyStatistics(doc *goquery.Document, stockData *mo
 			stockData.EPS = extractedData.eps
 		}
 		if extractedData.marketCap != "" {
-			if marketCap, err := df.parseMarketCap(extractedData.marketCap); err == nil {
-				stockData.MarketCap = marketCap
+			if marketCap, err := finparse.Amount(extractedData.marketCap); err == nil && marketCap > 0 {
+				stockData.MarketCap = int64(marketCap)
 			}
 		}
 		if extractedData.bookValue > 0 {
 			stockData.BookValue = extractedData.bookValue
 		}
+		if shares, err := finparse.Amount(extractedData.shares); err == nil && shares > 0 {
+			stockData.SharesOutstanding = int64(shares)
+		}
+		if extractedData.beta != 0 {
+			stockData.Beta = extractedData.beta
+		}
+		if extractedData.dividend > 0 {
+			stockData.DividendPerShare = extractedData.dividend
+		} else if extractedData.yield > 0 && stockData.CurrentPrice > 0 {
+			// Some pages give the yield alone
+			stockData.DividendPerShare = extractedData.yield * stockData.CurrentPrice
+		}
+		if extractedData.payout > 0 {
+			stockData.PayoutRatio = extractedData.payout
+		}
+		if extractedData.roe != 0 {
+			stockData.ReturnOnEquity = extractedData.roe
+		}
+		if totalDebt, err := finparse.Amount(extractedData.totalDebt); err == nil {
+			stockData.TotalDebt = totalDebt
+		}
+		if cash, err := finparse.Amount(extractedData.cash); err == nil {
+			stockData.Cash = cash
+		}
+		if ebitda, err := finparse.Amount(extractedData.ebitda); err == nil {
+			stockData.EBITDA = ebitda
+		}
+		if revenue, err := finparse.Amount(extractedData.revenue); err == nil {
+			stockData.Revenue = revenue
+		}
 	}
 	
 	return nil
 }
 
-// parseFloatValue parses a string value to float64, handling common formats
-func (df *DataFetcher) parseFloatValue(value string) (float64, error) {
-	// Clean the value string
-	cleaned := strings.ReplaceAll(value, ",", "")
-	cleaned = strings.ReplaceAll(cleaned, "$", "")
-	cleaned = strings.ReplaceAll(cleaned, "%", "")
-	cleaned = strings.TrimSpace(cleaned)
-	
-	// Handle N/A or empty values
-	if cleaned == "" || cleaned == "N/A" || cleaned == "--" {
-		return 0, fmt.Errorf("no valid value")
-	}
-	
-	// Parse the float
-	return strconv.ParseFloat(cleaned, 64)
+// quoteSummaryRaw returns the raw number of a QuoteSummaryStore field,
+// which is encoded as {"raw": 1.5, "fmt": "1.50"}
+func quoteSummaryRaw(module map[string]interface{}, field string) (float64, bool) {
+	value, ok := module[field].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	raw, ok := value["raw"].(float64)
+	return raw, ok
 }
 
-// parseMarketCap parses market cap string (e.g., "2.5T", "150B", "1.2M")
-func (df *DataFetcher) parseMarketCap(value string) (int64, error) {
-	// Clean the value
-	cleaned := strings.ReplaceAll(value, ",", "")
-	cleaned = strings.ReplaceAll(cleaned, "$", "")
-	cleaned = strings.TrimSpace(cleaned)
-	
-	if cleaned == "" || cleaned == "N/A" || cleaned == "--" {
-		return 0, fmt.Errorf("no valid market cap")
-	}
-	
-	// Extract number and suffix
-	re := regexp.MustCompile(`^([0-9.]+)([KMBT]?)$`)
-	matches := re.FindStringSubmatch(strings.ToUpper(cleaned))
-	
-	if len(matches) < 2 {
-		return 0, fmt.Errorf("invalid market cap format: %s", value)
+// sharesOutstanding returns the share count of stockData, estimated from
+// market cap and price when it has not been fetched
+func sharesOutstanding(stockData *models.StockData) float64 {
+	if stockData.SharesOutstanding > 0 {
+		return float64(stockData.SharesOutstanding)
 	}
-	
-	baseValue, err := strconv.ParseFloat(matches[1], 64)
-	if err != nil {
-		return 0, fmt.Errorf("invalid market cap number: %s", matches[1])
+	if stockData.MarketCap > 0 && stockData.CurrentPrice > 0 {
+		return float64(stockData.MarketCap) / stockData.CurrentPrice
 	}
-	
-	// Apply multiplier
-	multiplier := int64(1)
-	if len(matches) > 2 {
-		switch matches[2] {
-		case "K":
-			multiplier = 1000
-		case "M":
-			multiplier = 1000000
-		case "B":
-			multiplier = 1000000000
-		case "T":
-			multiplier = 1000000000000
-		}
-	}
-	
-	return int64(baseValue * float64(multiplier)), nil
+	return 0
 }
 
 // extractJSONData extracts JSON data from script content
@@ -519,6 +912,35 @@ func (df *DataFetcher) parseQuoteSummaryData(quoteSummary map[string]interface{}
 				stockData.BookValue = raw
 			}
 		}
+
+		if shares, ok := quoteSummaryRaw(defaultKeyStats, "sharesOutstanding"); ok && shares > 0 {
+			stockData.SharesOutstanding = int64(shares)
+		}
+		if beta, ok := quoteSummaryRaw(defaultKeyStats, "beta"); ok {
+			stockData.Beta = beta
+		}
+	}
+
+	// Extract balance sheet and income figures
+	if financialData, ok := quoteSummary["financialData"].(map[string]interface{}); ok {
+		if totalDebt, ok := quoteSummaryRaw(financialData, "totalDebt"); ok {
+			stockData.TotalDebt = totalDebt
+		}
+		if cash, ok := quoteSummaryRaw(financialData, "totalCash"); ok {
+			stockData.Cash = cash
+		}
+		if ebitda, ok := quoteSummaryRaw(financialData, "ebitda"); ok {
+			stockData.EBITDA = ebitda
+		}
+		if revenue, ok := quoteSummaryRaw(financialData, "totalRevenue"); ok {
+			stockData.Revenue = revenue
+		}
+		if roe, ok := quoteSummaryRaw(financialData, "returnOnEquity"); ok {
+			stockData.ReturnOnEquity = roe
+		}
+		if currency, ok := financialData["financialCurrency"].(string); ok && currency != "" && stockData.Currency == "" {
+			stockData.Currency = currency
+		}
 	}
 	
 	// Extract summary detail for market cap
@@ -528,66 +950,57 @@ func (df *DataFetcher) parseQuoteSummaryData(quoteSummary map[string]interface{}
 				stockData.MarketCap = int64(raw)
 			}
 		}
+		if dividend, ok := quoteSummaryRaw(summaryDetail, "dividendRate"); ok {
+			stockData.DividendPerShare = dividend
+		} else if yield, ok := quoteSummaryRaw(summaryDetail, "dividendYield"); ok && stockData.CurrentPrice > 0 {
+			stockData.DividendPerShare = yield * stockData.CurrentPrice
+		}
+		if payout, ok := quoteSummaryRaw(summaryDetail, "payoutRatio"); ok && payout > 0 {
+			stockData.PayoutRatio = payout
+		}
+		if beta, ok := quoteSummaryRaw(summaryDetail, "beta"); ok && stockData.Beta == 0 {
+			stockData.Beta = beta
+		}
+		// Funds have no key statistics of their own, only the P/E of
+		// their holdings
+		if trailingPE, ok := quoteSummaryRaw(summaryDetail, "trailingPE"); ok && stockData.PERatio == 0 {
+			stockData.PERatio = trailingPE
+		}
 	}
-}
 
-// fetchFinancialsData fetches financial data from Yahoo Finance financials page
-func (df *DataFetcher) fetchFinancialsData(ctx context.Context, ticker string, stockData *models.StockData) error {
-	// Build financials URL
-	financialsURL := fmt.Sprintf("https://finance.yahoo.com/quote/%s/financials/", ticker)
-	
-	// Create request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", financialsURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	
-	// Set headers to mimic browser request
-	df.setRequestHeaders(req)
-	
-	// Make request
-	resp, err := df.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to fetch financials data: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Yahoo Finance financials returned status %d", resp.StatusCode)
-	}
-	
-	// Parse HTML document
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to parse HTML: %w", err)
-	}
-	
-	// Extract financial data
-	if err := df.extractFinancialsData(doc, stockData); err != nil {
-		return fmt.Errorf("failed to extract financials data: %w", err)
+	// Extract the next earnings date; until it is confirmed Yahoo gives the
+	// window it is expected in, whose first day is taken
+	if calendarEvents, ok := quoteSummary["calendarEvents"].(map[string]interface{}); ok {
+		if earnings, ok := calendarEvents["earnings"].(map[string]interface{}); ok {
+			if dates, ok := earnings["earningsDate"].([]interface{}); ok && len(dates) > 0 {
+				if date, ok := dates[0].(map[string]interface{}); ok {
+					if raw, ok := date["raw"].(float64); ok && raw > 0 {
+						stockData.EarningsDate = time.Unix(int64(raw), 0).UTC()
+					}
+				}
+			}
+		}
 	}
-	
-	return nil
 }
 
 // extractFinancialsData extracts financial data from the parsed HTML document
-func (df *DataFetcher) extractFinancialsData(doc *goquery.Document, stockData *models.StockData) error {
+func (df *DataFetcher) extractFinancialsData(doc *goquery.Document, page PageSelectors, stockData *models.StockData) error {
 	var extractedData struct {
 		fcfPerShare float64
 		found       bool
 	}
 	
 	// Look for Free Cash Flow in financial tables
-	doc.Find("div[data-test='fin-row']").Each(func(i int, row *goquery.Selection) {
-		label := strings.TrimSpace(row.Find("div[data-test='fin-col']").First().Text())
+	doc.Find(page.Selector("rows")).Each(func(i int, row *goquery.Selection) {
+		label := strings.TrimSpace(row.Find(page.Selector("cells")).First().Text())
 		
 		if strings.Contains(strings.ToLower(label), "free cash flow") || 
 		   strings.Contains(strings.ToLower(label), "operating cash flow") {
 			// Get the most recent value (usually the first data column)
-			row.Find("div[data-test='fin-col']").Each(func(j int, col *goquery.Selection) {
+			row.Find(page.Selector("cells")).Each(func(j int, col *goquery.Selection) {
 				if j > 0 { // Skip the label column
 					value := strings.TrimSpace(col.Text())
-					if fcf, err := df.parseFinancialValue(value); err == nil && fcf != 0 {
+					if fcf, err := finparse.Amount(value); err == nil && fcf != 0 {
 						// Convert to per-share basis (approximate)
 						if stockData.MarketCap > 0 && stockData.CurrentPrice > 0 {
 							shares := float64(stockData.MarketCap) / stockData.CurrentPrice
@@ -608,7 +1021,7 @@ func (df *DataFetcher) extractFinancialsData(doc *goquery.Document, stockData *m
 	})
 	
 	// Look for JSON data in scripts
-	doc.Find("script").Each(func(i int, script *goquery.Selection) {
+	doc.Find(page.Selector("scripts")).Each(func(i int, script *goquery.Selection) {
 		content := script.Text()
 		if strings.Contains(content, "root.App.main") {
 			if jsonData, err := df.extractJSONData(content); err == nil {
@@ -626,34 +1039,6 @@ func (df *DataFetcher) extractFinancialsData(doc *goquery.Document, stockData *m
 	return nil
 }
 
-// parseFinancialValue parses financial values (handles millions/billions)
-func (df *DataFetcher) parseFinancialValue(value string) (float64, error) {
-	// Clean the value
-	cleaned := strings.ReplaceAll(value, ",", "")
-	cleaned = strings.ReplaceAll(cleaned, "$", "")
-	cleaned = strings.ReplaceAll(cleaned, "(", "-")
-	cleaned = strings.ReplaceAll(cleaned, ")", "")
-	cleaned = strings.TrimSpace(cleaned)
-	
-	if cleaned == "" || cleaned == "N/A" || cleaned == "--" {
-		return 0, fmt.Errorf("no valid value")
-	}
-	
-	// Handle negative values
-	multiplier := 1.0
-	if strings.HasPrefix(cleaned, "-") {
-		multiplier = -1.0
-		cleaned = strings.TrimPrefix(cleaned, "-")
-	}
-	
-	// Parse the number
-	if val, err := strconv.ParseFloat(cleaned, 64); err == nil {
-		return val * multiplier, nil
-	}
-	
-	return 0, fmt.Errorf("invalid financial value: %s", value)
-}
-
 // parseJSONFinancials parses financial data from JSON
 func (df *DataFetcher) parseJSONFinancials(jsonData map[string]interface{}, stockData *models.StockData) {
 	// Navigate through the JSON structure to find financial data
@@ -679,11 +1064,8 @@ func (df *DataFetcher) parseQuoteSummaryFinancials(quoteSummary map[string]inter
 					if freeCashFlow, ok := mostRecent["freeCashFlow"].(map[string]interface{}); ok {
 						if raw, ok := freeCashFlow["raw"].(float64); ok {
 							// Convert to per-share basis
-							if stockData.MarketCap > 0 && stockData.CurrentPrice > 0 {
-								shares := float64(stockData.MarketCap) / stockData.CurrentPrice
-								if shares > 0 {
-									stockData.FCFPerShare = raw / shares
-								}
+							if shares := sharesOutstanding(stockData); shares > 0 {
+								stockData.FCFPerShare = raw / shares
 							}
 						}
 					}
@@ -691,49 +1073,23 @@ func (df *DataFetcher) parseQuoteSummaryFinancials(quoteSummary map[string]inter
 			}
 		}
 	}
-}
 
-// fetchProfileData fetches profile data from Yahoo Finance profile page
-func (df *DataFetcher) fetchProfileData(ctx context.Context, ticker string, stockData *models.StockData) error {
-	// Build profile URL
-	profileURL := fmt.Sprintf("https://finance.yahoo.com/quote/%s/profile/", ticker)
-	
-	// Create request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", profileURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	
-	// Set headers to mimic browser request
-	df.setRequestHeaders(req)
-	
-	// Make request
-	resp, err := df.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to fetch profile data: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Yahoo Finance profile returned status %d", resp.StatusCode)
-	}
-	
-	// Parse HTML document
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to parse HTML: %w", err)
-	}
-	
-	// Extract profile data
-	if err := df.extractProfileData(doc, stockData); err != nil {
-		return fmt.Errorf("failed to extract profile data: %w", err)
+	// Extract tangible book value from the most recent balance sheet
+	if balanceSheetHistory, ok := quoteSummary["balanceSheetHistory"].(map[string]interface{}); ok {
+		if statements, ok := balanceSheetHistory["balanceSheetStatements"].([]interface{}); ok && len(statements) > 0 {
+			if mostRecent, ok := statements[0].(map[string]interface{}); ok {
+				if tangible, ok := quoteSummaryRaw(mostRecent, "netTangibleAssets"); ok {
+					if shares := sharesOutstanding(stockData); shares > 0 {
+						stockData.TangibleBookValue = tangible / shares
+					}
+				}
+			}
+		}
 	}
-	
-	return nil
 }
 
 // extractProfileData extracts profile data from the parsed HTML document
-func (df *DataFetcher) extractProfileData(doc *goquery.Document, stockData *models.StockData) error {
+func (df *DataFetcher) extractProfileData(doc *goquery.Document, page PageSelectors, stockData *models.StockData) error {
 	var extractedData struct {
 		sector       string
 		industry     string
@@ -742,24 +1098,24 @@ func (df *DataFetcher) extractProfileData(doc *goquery.Document, stockData *mode
 	}
 	
 	// Look for sector and industry in various selectors
-	doc.Find("span[data-test='SECTOR']").Each(func(i int, elem *goquery.Selection) {
+	doc.Find(page.Selector("sector")).Each(func(i int, elem *goquery.Selection) {
 		extractedData.sector = strings.TrimSpace(elem.Text())
 		extractedData.found = true
 	})
 	
-	doc.Find("span[data-test='INDUSTRY']").Each(func(i int, elem *goquery.Selection) {
+	doc.Find(page.Selector("industry")).Each(func(i int, elem *goquery.Selection) {
 		extractedData.industry = strings.TrimSpace(elem.Text())
 		extractedData.found = true
 	})
 	
 	// Look for company name
-	doc.Find("h1[data-test='qsp-overview-entity-name']").Each(func(i int, elem *goquery.Selection) {
+	doc.Find(page.Selector("name")).Each(func(i int, elem *goquery.Selection) {
 		extractedData.companyName = strings.TrimSpace(elem.Text())
 		extractedData.found = true
 	})
 	
 	// Alternative selectors for sector/industry
-	doc.Find("p").Each(func(i int, p *goquery.Selection) {
+	doc.Find(page.Selector("description")).Each(func(i int, p *goquery.Selection) {
 		text := strings.TrimSpace(p.Text())
 		if strings.Contains(strings.ToLower(text), "sector:") {
 			parts := strings.Split(text, ":")
@@ -778,7 +1134,7 @@ func (df *DataFetcher) extractProfileData(doc *goquery.Document, stockData *mode
 	})
 	
 	// Look for JSON data in scripts
-	doc.Find("script").Each(func(i int, script *goquery.Selection) {
+	doc.Find(page.Selector("scripts")).Each(func(i int, script *goquery.Selection) {
 		content := script.Text()
 		if strings.Contains(content, "root.App.main") {
 			if jsonData, err := df.extractJSONData(content); err == nil {
@@ -793,6 +1149,9 @@ func (df *DataFetcher) extractProfileData(doc *goquery.Document, stockData *mode
 		if extractedData.sector != "" {
 			stockData.Sector = extractedData.sector
 		}
+		if extractedData.industry != "" {
+			stockData.Industry = extractedData.industry
+		}
 		if extractedData.companyName != "" {
 			stockData.CompanyName = extractedData.companyName
 		}
@@ -823,8 +1182,10 @@ func (df *DataFetcher) parseQuoteSummaryProfile(quoteSummary map[string]interfac
 			stockData.Sector = sector
 		}
 		if industry, ok := assetProfile["industry"].(string); ok {
-			// Store industry info if needed (not in current StockData struct)
-			_ = industry
+			stockData.Industry = industry
+		}
+		if country, ok := assetProfile["country"].(string); ok && country != "" {
+			stockData.Country = country
 		}
 	}
 	
@@ -833,12 +1194,20 @@ func (df *DataFetcher) parseQuoteSummaryProfile(quoteSummary map[string]interfac
 		if longName, ok := price["longName"].(string); ok {
 			stockData.CompanyName = longName
 		}
+		if currency, ok := price["currency"].(string); ok && currency != "" && stockData.Currency == "" {
+			stockData.Currency = currency
+		}
 	}
 }
 
 // applyFallbackForMissingData applies fallback data for any missing fields
 func (df *DataFetcher) applyFallbackForMissingData(ticker string, stockData *models.StockData) {
 	fallbackData := df.getFallbackStockData()
+
+	// Fallback figures are for US listings
+	if stockData.Currency == "" {
+		stockData.Currency = "USD"
+	}
 	
 	// Check if we have fallback data for this ticker
 	if data, exists := fallbackData[ticker]; exists {
@@ -924,7 +1293,7 @@ func (df *DataFetcher) setRequestHeaders(req *http.Request) {
 	}
 	
 	// Use a random user agent
-	userAgent := userAgents[int(time.Now().UnixNano())%len(userAgents)]
+	userAgent := userAgents[df.random.Intn(len(userAgents))]
 	req.Header.Set("User-Agent", userAgent)
 	
 	// Set other browser-like headers
@@ -1001,41 +1370,45 @@ func (df *DataFetcher) setFallbackData(ticker string, stockData *models.StockDat
 
 // LoadTickersFromCSV loads ticker symbols from CSV file
 func (df *DataFetcher) LoadTickersFromCSV(filename string) ([]string, error) {
-	var tickers []string
-	
-	file, err := os.Open(filename)
+	infos, err := df.LoadTickerInfosFromCSV(filename)
 	if err != nil {
-		// Return default tickers if file not found
-		return []string{
-			"AAPL", "MSFT", "GOOGL", "AMZN", "NVDA", "META", "TSLA", "BRK-B",
-			"UNH", "JNJ", "JPM", "V", "PG", "HD", "MA", "BAC", "ABBV", "PFE",
-			"KO", "AVGO", "PEP", "TMO", "COST", "WMT", "MRK", "DIS", "ACN",
-			"VZ", "ADBE", "NFLX", "NKE", "CRM", "DHR", "LIN", "TXN", "NEE",
-			"ABT", "ORCL", "PM", "RTX", "QCOM", "HON", "WFC", "UPS", "T",
-			"LOW", "SPGI", "ELV", "SCHW", "CAT",
-		}, nil
+		return nil, err
 	}
-	defer file.Close()
+	tickers := make([]string, len(infos))
+	for i, info := range infos {
+		tickers[i] = info.Ticker
+	}
+	return tickers, nil
+}
 
-	reader := csv.NewReader(file)
-	
-	// Skip header
-	if _, err := reader.Read(); err != nil {
+// LoadTickerInfosFromCSV loads ticker symbols from CSV file along with the
+// hints of its extra columns
+func (df *DataFetcher) LoadTickerInfosFromCSV(filename string) ([]models.TickerInfo, error) {
+	var tickers []models.TickerInfo
+	
+	file, err := OpenTickerFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		// Return the constituents of the default index if file not found
+		var defaults []models.TickerInfo
+		for _, ticker := range DefaultConstituents(DefaultIndex) {
+			defaults = append(defaults, models.TickerInfo{Ticker: ticker})
+		}
+		return defaults, nil
+	}
+	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
 	for {
-		record, err := reader.Read()
+		info, err := file.NextInfo()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
 			return nil, err
 		}
-		
-		if len(record) > 0 {
-			tickers = append(tickers, record[0])
-		}
+		tickers = append(tickers, info)
 	}
 
 	return tickers, nil
@@ -1043,25 +1416,7 @@ func (df *DataFetcher) LoadTickersFromCSV(filename string) ([]string, error) {
 
 // getIndustryPERatio returns conservative P/E ratio for industry
 func (df *DataFetcher) getIndustryPERatio(sector string) float64 {
-	industryPERatios := map[string]float64{
-		"Technology":             22.0,
-		"Healthcare":             18.0,
-		"Financial Services":     10.0,
-		"Consumer Cyclical":      16.0,
-		"Consumer Defensive":     20.0,
-		"Energy":                 12.0,
-		"Industrials":            13.0,
-		"Materials":              12.0,
-		"Real Estate":            14.0,
-		"Utilities":              16.0,
-		"Communication Services": 18.0,
-		"Default":                18.0,
-	}
-
-	if pe, exists := industryPERatios[sector]; exists {
-		return pe
-	}
-	return industryPERatios["Default"]
+	return models.SectorMedianPE(sector)
 }
 
 // getFallbackPERatios returns hardcoded P/E ratios for major stocks
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// offlineTransport fails every request, as when no source can be reached
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network is unreachable")
+}
+
+func TestFallbackGrowthRateIsNotRealData(t *testing.T) {
+	fetcher := NewDataFetcher()
+	fetcher.SetLogger(NewWriterLogger(io.Discard))
+	fetcher.SetTransport(offlineTransport{})
+	fetcher.growthFetcher.requestDelay = 0
+
+	// AAPL has a fallback growth rate of its own, MSFTX only the default
+	for _, ticker := range []string{"AAPL", "MSFTX"} {
+		stockData, err := fetcher.FetchStockData(context.Background(), ticker)
+		if err != nil {
+			t.Fatalf("%s: %v", ticker, err)
+		}
+		if stockData.GrowthRate <= 0 || len(stockData.GrowthSources) != 0 {
+			t.Fatalf("%s: growth rate %.4f from %d sources, want a fallback rate", ticker,
+				stockData.GrowthRate, len(stockData.GrowthSources))
+		}
+		if !slices.Contains(stockData.FallbackFields, "growth_rate") {
+			t.Errorf("%s: fallback fields %v, want growth_rate among them", ticker, stockData.FallbackFields)
+		}
+		if slices.Contains(stockData.RealInputs(), "growth_rate") {
+			t.Errorf("%s: real inputs %v, want the fallback growth rate left out", ticker, stockData.RealInputs())
+		}
+		// -strict-data 1 values no stock none of whose inputs were fetched
+		if err := stockData.CheckCompleteness(1); !errors.Is(err, models.ErrInsufficientData) {
+			t.Errorf("%s: strict data check %v, want insufficient data", ticker, err)
+		}
+	}
+}
+
+// chartResponse returns a Yahoo Finance chart response with days of daily
+// quotes
+func chartResponse(b *testing.B, days int) []byte {
+	timestamps := make([]int64, days)
+	closes := make([]float64, days)
+	volumes := make([]int64, days)
+	start := time.Date(2025, 1, 2, 14, 30, 0, 0, time.UTC)
+	for i := range days {
+		timestamps[i] = start.AddDate(0, 0, i).Unix()
+		closes[i] = 180 + float64(i%20)
+		volumes[i] = 50_000_000 + int64(i)*1000
+	}
+	quote := map[string]any{"close": closes, "high": closes, "low": closes, "open": closes, "volume": volumes}
+	data, err := json.Marshal(map[string]any{
+		"chart": map[string]any{
+			"result": []any{map[string]any{
+				"meta": map[string]any{
+					"currency":           "USD",
+					"symbol":             "AAPL",
+					"exchangeName":       "NMS",
+					"regularMarketPrice": 185.5,
+					"chartPreviousClose": 184.2,
+					"previousClose":      184.2,
+				},
+				"timestamp":  timestamps,
+				"indicators": map[string]any{"quote": []any{quote}},
+			}},
+			"error": nil,
+		},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+func BenchmarkParseChart(b *testing.B) {
+	chart := chartResponse(b, 252)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(chart)))
+	for b.Loop() {
+		var resp YahooChartResponse
+		if err := json.Unmarshal(chart, &resp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGrowthScrapeChecksOnFetcherClock(t *testing.T) {
+	now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	fetcher := NewDataFetcher()
+	fetcher.SetLogger(NewWriterLogger(io.Discard))
+	fetcher.SetTransport(offlineTransport{})
+	fetcher.SetClock(FixedClock(now))
+	fetcher.growthFetcher.requestDelay = 0
+
+	// Checked on the growth fetcher, which the data fetcher passes its clock
+	checks := fetcher.growthFetcher.CheckScrapers(context.Background(), "AAPL")
+	if len(checks) == 0 {
+		t.Fatal("no scrapers checked")
+	}
+	for _, check := range checks {
+		if !check.CheckedAt.Equal(now) {
+			t.Errorf("%s checked at %s, want the fetcher's clock %s", check.Source, check.CheckedAt, now)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Causes of failed fetches. Errors returned by the fetchers wrap one of
+// these when the cause is known, so callers can test for it with
+// errors.Is.
+var (
+	ErrRateLimited    = errors.New("rate limited")
+	ErrSymbolNotFound = errors.New("symbol not found")
+	ErrParse          = errors.New("unparseable response")
+	ErrTimeout        = errors.New("timed out")
+	ErrNotCached      = errors.New("not cached") // by cache-only analyzers
+)
+
+// causes lists the known causes in the order Cause checks them
+var causes = []error{ErrSymbolNotFound, ErrRateLimited, ErrTimeout, ErrParse, ErrNotCached}
+
+// FetchError is a failed request to a data source
+type FetchError struct {
+	Source     string        // host the request was sent to
+	Cause      error         // one of the Err* causes, nil when unknown
+	Err        error         // what went wrong
+	RetryAfter time.Duration // wait requested by a rate-limited source
+}
+
+// Error describes the failure
+func (e *FetchError) Error() string {
+	if e.Cause == nil {
+		return fmt.Sprintf("%s: %v", e.Source, e.Err)
+	}
+	return fmt.Sprintf("%s: %v: %v", e.Source, e.Cause, e.Err)
+}
+
+// Unwrap returns the cause and the underlying error
+func (e *FetchError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Cause, e.Err}
+}
+
+// Cause returns the message of the known cause of err, or "other" when it
+// has none, for grouping failures
+func Cause(err error) string {
+	for _, cause := range causes {
+		if errors.Is(err, cause) {
+			return cause.Error()
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout.Error()
+	}
+	return "other"
+}
+
+// Retryable reports whether repeating a failed fetch may succeed: rate
+// limiting and timeouts pass, unknown symbols and unparseable responses
+// do not
+func Retryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrTimeout)
+}
+
+// statusError classifies a response with an unsuccessful status
+func statusError(resp *http.Response) error {
+	fetchErr := &FetchError{
+		Source: resp.Request.URL.Hostname(),
+		Err:    fmt.Errorf("HTTP status %d", resp.StatusCode),
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		fetchErr.Cause = ErrRateLimited
+		fetchErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	case http.StatusNotFound:
+		fetchErr.Cause = ErrSymbolNotFound
+	}
+	return fetchErr
+}
+
+// requestError classifies a request that received no response
+func requestError(req *http.Request, err error) error {
+	fetchErr := &FetchError{Source: req.URL.Hostname(), Err: err}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		fetchErr.Cause = ErrTimeout
+	}
+	return fetchErr
+}
+
+// parseError reports a response from source that could not be parsed
+func parseError(source string, err error) error {
+	return &FetchError{Source: source, Cause: ErrParse, Err: err}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date, returning 0 when it is missing or invalid
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+// Retries of transiently failing requests
+const (
+	maxFetchAttempts = 3                      // attempts of a request, including the first
+	retryBackoff     = 500 * time.Millisecond // wait before the first retry, doubling after
+	maxRetryAfter    = 30 * time.Second       // cap on waits requested by a source
+)
+
+// withRetry calls fetch until it succeeds, fails permanently or has been
+// attempted maxFetchAttempts times. Rate-limited attempts wait as long as
+// the source asks, up to maxRetryAfter.
+func withRetry[T any](ctx context.Context, fetch func() (T, error)) (T, error) {
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		result, err := fetch()
+		if err == nil || attempt == maxFetchAttempts || !Retryable(err) || ctx.Err() != nil {
+			return result, err
+		}
+
+		wait := backoff
+		var fetchErr *FetchError
+		if errors.As(err, &fetchErr) && fetchErr.RetryAfter > wait {
+			wait = min(fetchErr.RetryAfter, maxRetryAfter)
+		}
+		backoff *= 2
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"slices"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// fallbackFigures are the valuation inputs fallback data set while a
+// ticker was fetched, by JSON name, with the figures it set them to.
+// Sources answering later may replace them, so an input holds fallback
+// data only while it still holds the figure recorded for it.
+type fallbackFigures map[string]float64
+
+// record remembers the valuation inputs of stockData that fallback data
+// changed from before, other than those in except
+func (f fallbackFigures) record(before, stockData *models.StockData, except ...string) {
+	for _, field := range models.ValuationInputs {
+		if value := stockData.Input(field); value != before.Input(field) && !slices.Contains(except, field) {
+			f[field] = value
+		}
+	}
+}
+
+// fields returns the valuation inputs of stockData that still hold
+// fallback figures, along with a P/E that stands in for the stock's own
+func (f fallbackFigures) fields(stockData *models.StockData) []string {
+	var fields []string
+	for _, field := range models.ValuationInputs {
+		value, ok := f[field]
+		if (ok && stockData.Input(field) == value) || (field == "pe_ratio" && stockData.PERatioEstimated) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// GrowthModelSource is the name the growth model goes by among the growth
+// sources
+const GrowthModelSource = "growth_model"
+
+// growthModelYears is how many fiscal years of financials the model's
+// features are computed from
+const growthModelYears = 5
+
+//go:embed growth_model.json
+var defaultGrowthModel []byte
+
+// FinancialYear holds the figures of one fiscal year the growth model
+// learns from
+type FinancialYear struct {
+	Date            time.Time `json:"date"`
+	Revenue         float64   `json:"revenue"`
+	OperatingIncome float64   `json:"operating_income"`
+	FreeCashFlow    float64   `json:"free_cash_flow"`
+}
+
+// FinancialHistorySource is implemented by providers that know the yearly
+// financials of a company
+type FinancialHistorySource interface {
+	// FinancialHistory returns the financials of the last fiscal years
+	// of ticker, oldest first
+	FinancialHistory(ctx context.Context, ticker string) ([]FinancialYear, error)
+}
+
+// GrowthModel predicts forward free cash flow growth from the trends of a
+// company's revenue and margins. It is trained offline and shipped as the
+// coefficients of a linear model over named features:
+//
+//   - revenue_cagr: compound annual revenue growth over the history
+//   - revenue_growth_last: revenue growth in the last fiscal year
+//   - operating_margin: operating income over revenue in the last year
+//   - operating_margin_change: yearly change of the operating margin
+//   - fcf_margin: free cash flow over revenue in the last year
+type GrowthModel struct {
+	Name         string             `json:"name"`
+	Version      string             `json:"version"`
+	Description  string             `json:"description,omitempty"`
+	Intercept    float64            `json:"intercept"`
+	Coefficients map[string]float64 `json:"coefficients"`
+	MinRate      float64            `json:"min_rate"` // predictions are clamped to MinRate..MaxRate
+	MaxRate      float64            `json:"max_rate"`
+	MinYears     int                `json:"min_years"`  // fewer fiscal years predict nothing
+	Confidence   float64            `json:"confidence"` // weight in the growth consensus, 0-1
+}
+
+// growthModelFeatures are the features a model's coefficients may use
+var growthModelFeatures = []string{
+	"revenue_cagr", "revenue_growth_last", "operating_margin", "operating_margin_change", "fcf_margin",
+}
+
+// DefaultGrowthModel returns the growth model built into the binary
+func DefaultGrowthModel() *GrowthModel {
+	model, err := parseGrowthModel(defaultGrowthModel)
+	if err != nil {
+		panic(fmt.Sprintf("built-in growth model: %v", err))
+	}
+	return model
+}
+
+// LoadGrowthModel reads a growth model from a JSON file shaped like the
+// built-in one, for models retrained since the binary was built
+func LoadGrowthModel(path string) (*GrowthModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read growth model: %w", err)
+	}
+	model, err := parseGrowthModel(data)
+	if err != nil {
+		return nil, fmt.Errorf("growth model %s: %w", path, err)
+	}
+	return model, nil
+}
+
+// parseGrowthModel decodes and checks a growth model
+func parseGrowthModel(data []byte) (*GrowthModel, error) {
+	var model GrowthModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, err
+	}
+	if len(model.Coefficients) == 0 {
+		return nil, fmt.Errorf("no coefficients")
+	}
+	for feature := range model.Coefficients {
+		if !slices.Contains(growthModelFeatures, feature) {
+			return nil, fmt.Errorf("unknown feature %q", feature)
+		}
+	}
+	if model.MinRate >= model.MaxRate {
+		return nil, fmt.Errorf("min_rate must be below max_rate")
+	}
+	if err := checkGrowthRange(model.MinRate); err != nil {
+		return nil, err
+	}
+	if err := checkGrowthRange(model.MaxRate); err != nil {
+		return nil, err
+	}
+	if model.Confidence <= 0 || model.Confidence > 1 {
+		return nil, fmt.Errorf("confidence must be above 0 and at most 1")
+	}
+	if model.MinYears < 2 {
+		return nil, fmt.Errorf("min_years must be at least 2, so trends can be measured")
+	}
+	return &model, nil
+}
+
+// Predict returns the forward growth the model predicts for a company
+// with the given financials, oldest first
+func (m *GrowthModel) Predict(history []FinancialYear) (float64, error) {
+	features, err := m.features(history)
+	if err != nil {
+		return 0, err
+	}
+	rate := m.Intercept
+	for feature, coefficient := range m.Coefficients {
+		rate += coefficient * features[feature]
+	}
+	return math.Max(m.MinRate, math.Min(m.MaxRate, rate)), nil
+}
+
+// features computes the model's features from history. Revenue must be
+// positive throughout, as growth and margins mean nothing without it.
+func (m *GrowthModel) features(history []FinancialYear) (map[string]float64, error) {
+	if len(history) < m.MinYears {
+		return nil, fmt.Errorf("%d fiscal years of financials, the model needs %d", len(history), m.MinYears)
+	}
+	for _, year := range history {
+		if year.Revenue <= 0 {
+			return nil, fmt.Errorf("no revenue in fiscal year %s", year.Date.Format(time.DateOnly))
+		}
+	}
+
+	first, last := history[0], history[len(history)-1]
+	previous := history[len(history)-2]
+	years := float64(len(history) - 1)
+	firstMargin := first.OperatingIncome / first.Revenue
+	lastMargin := last.OperatingIncome / last.Revenue
+	return map[string]float64{
+		"revenue_cagr":            math.Pow(last.Revenue/first.Revenue, 1/years) - 1,
+		"revenue_growth_last":     last.Revenue/previous.Revenue - 1,
+		"operating_margin":        lastMargin,
+		"operating_margin_change": (lastMargin - firstMargin) / years,
+		"fcf_margin":              last.FreeCashFlow / last.Revenue,
+	}, nil
+}
+
+// FinancialHistory returns the revenue, operating income and free cash
+// flow of the last fiscal years of ticker from the Yahoo Finance
+// fundamentals timeseries API
+func (df *DataFetcher) FinancialHistory(ctx context.Context, ticker string) ([]FinancialYear, error) {
+	if !df.features.EnableYahooAPI {
+		return nil, fmt.Errorf("financial history requires the Yahoo Finance API to be enabled")
+	}
+	now := df.clock.Now()
+	query := url.Values{
+		"symbol":  {ticker},
+		"type":    {"annualTotalRevenue,annualOperatingIncome,annualFreeCashFlow"},
+		"period1": {strconv.FormatInt(now.AddDate(-growthModelYears-1, 0, 0).Unix(), 10)},
+		"period2": {strconv.FormatInt(now.Unix(), 10)},
+	}
+	response, err := withRetry(ctx, func() (*yahooTimeseriesResponse, error) {
+		return df.fetchTimeseriesOnce(ctx, ticker, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Years are those with a revenue; the other figures may be missing
+	operatingIncome := response.series("annualOperatingIncome")
+	freeCashFlow := response.series("annualFreeCashFlow")
+	var history []FinancialYear
+	for date, revenue := range response.series("annualTotalRevenue") {
+		history = append(history, FinancialYear{
+			Date:            date,
+			Revenue:         revenue,
+			OperatingIncome: operatingIncome[date],
+			FreeCashFlow:    freeCashFlow[date],
+		})
+	}
+	if len(history) == 0 {
+		return nil, &FetchError{Source: "query1.finance.yahoo.com", Cause: ErrSymbolNotFound,
+			Err: fmt.Errorf("no financials found for %s", ticker)}
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+	if len(history) > growthModelYears {
+		history = history[len(history)-growthModelYears:]
+	}
+	return history, nil
+}
+
+// SetGrowthModel adds model to the sources of the growth consensus, with
+// the fetcher's financial history as its input. A nil model removes it.
+func (df *DataFetcher) SetGrowthModel(model *GrowthModel) {
+	df.growthFetcher.SetGrowthModel(model, df)
+}
+
+// SetGrowthModel adds model to the sources of the consensus, predicting
+// from the financials of history. A nil model removes it.
+func (grf *GrowthRateFetcher) SetGrowthModel(model *GrowthModel, history FinancialHistorySource) {
+	grf.model, grf.history = model, history
+	grf.sources = slices.DeleteFunc(grf.sources, func(name string) bool {
+		return name == GrowthModelSource
+	})
+	if model != nil {
+		grf.sources = append(grf.sources, GrowthModelSource)
+	}
+}
+
+// fetchFromGrowthModel predicts the growth rate of ticker from its
+// financial history
+func (grf *GrowthRateFetcher) fetchFromGrowthModel(ctx context.Context, ticker string) GrowthRateSource {
+	source := GrowthRateSource{
+		Name:      GrowthModelSource,
+		FetchTime: grf.clock.Now(),
+	}
+	if grf.model == nil {
+		source.Error = fmt.Errorf("no growth model is set")
+		return source
+	}
+	source.Confidence = grf.model.Confidence
+
+	history, err := grf.history.FinancialHistory(ctx, ticker)
+	if err != nil {
+		source.Error = err
+		return source
+	}
+	rate, err := grf.model.Predict(history)
+	if err != nil {
+		source.Error = err
+		return source
+	}
+	source.GrowthRate = rate
+	return source
+}
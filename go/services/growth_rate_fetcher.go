@@ -2,16 +2,25 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net/http"
+	"os"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lesnerd/fair-stock-value/go/finparse"
+	"github.com/lesnerd/fair-stock-value/go/models"
 )
 
 // GrowthRateSource represents a source of growth rate data
@@ -22,23 +31,82 @@ type GrowthRateSource struct {
 	Confidence  float64 // 0-1 scale for data quality
 	FetchTime   time.Time
 	Error       error
+	Rejected    []float64 // rates on the page left out of GrowthRate as outliers
+	Outlier     bool      // GrowthRate is left out of the consensus as an outlier
+	Fields      int       // growth rates found on the page, outliers included
+}
+
+// errNoGrowthRate is the error of a source whose page holds no growth rate
+var errNoGrowthRate = errors.New("no growth rate found")
+
+// setPageRates sets the growth rate of the source to the average of the
+// rates found on its page, leaving out the outliers among them
+func (s *GrowthRateSource) setPageRates(rates []float64) {
+	kept, rejected := rejectOutliers(rates)
+	s.GrowthRate = mean(kept)
+	s.Rejected = rejected
+	s.Fields = len(rates)
+}
+
+// GrowthConsensus is a consensus growth rate, the rates of the sources it
+// was weighted from with their standard deviation, and the rates left out
+// of it. A fallback or default rate has no sources.
+type GrowthConsensus struct {
+	Rate     float64
+	Sources  []models.GrowthEstimate
+	StdDev   float64
+	Rejected []models.RejectedGrowth
+
+	// Fallback is set when no source answered and Rate is the fallback
+	// rate of the ticker or the default
+	Fallback bool
+}
+
+// GrowthDetailSource is implemented by growth sources that report the
+// rates they left out of the consensus, for the audit trail of the data
+type GrowthDetailSource interface {
+	GrowthSource
+	FetchGrowthConsensus(ctx context.Context, ticker string) (GrowthConsensus, error)
 }
 
-// GrowthRateFetcher handles fetching growth rate predictions from multiple sources
+// GrowthSource provides the consensus growth rate of a ticker. It is used
+// by every worker at once, so implementations must be safe for
+// concurrent use.
+type GrowthSource interface {
+	FetchGrowthRateConsensus(ctx context.Context, ticker string) (float64, error)
+}
+
+// GrowthRateFetcher handles fetching growth rate predictions from multiple sources.
+// It is safe for concurrent use, so one fetcher serves every worker.
 type GrowthRateFetcher struct {
 	httpClient   *http.Client
 	requestDelay time.Duration
 	sources      []string
 	userAgents   []string
-	randSource   *rand.Rand
+	random       *Random
+	useFallback  bool
+	logger       Logger
+	clock        Clock
+	selectors    Selectors
+
+	// model predicts growth from the financials of history; it is among
+	// sources only when set
+	model   *GrowthModel
+	history FinancialHistorySource
+
+	// degraded are the sources whose pages held no growth rate for the
+	// known ticker of the last scraper check, left out of the consensus
+	degraded      map[string]bool
+	degradedMutex sync.RWMutex
 }
 
 // NewGrowthRateFetcher creates a new growth rate fetcher
 func NewGrowthRateFetcher() *GrowthRateFetcher {
 	return &GrowthRateFetcher{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		httpClient: newTracingClient(&http.Client{
+			Timeout:   30 * time.Second,
+			Transport: sharedTransport,
+		}),
 		requestDelay: 2 * time.Second,
 		sources: []string{
 			"yahoo_finance",
@@ -64,10 +132,55 @@ func NewGrowthRateFetcher() *GrowthRateFetcher {
 			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36",
 			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36",
 		},
-		randSource: rand.New(rand.NewSource(time.Now().UnixNano())),
+		random:      NewRandom(rand.NewSource(time.Now().UnixNano())),
+		useFallback: true,
+		logger:      NewWriterLogger(os.Stdout),
+		clock:       SystemClock,
+		selectors:   DefaultSelectors(),
 	}
 }
 
+// SetSelectors sets the pages and selectors the growth sources are scraped
+// with
+func (grf *GrowthRateFetcher) SetSelectors(selectors Selectors) {
+	grf.selectors = selectors
+}
+
+// SetLogger sets where progress and diagnostic messages are written
+func (grf *GrowthRateFetcher) SetLogger(logger Logger) {
+	grf.logger = logger
+}
+
+// SetClock sets the clock that stamps fetched rates and scraper checks
+func (grf *GrowthRateFetcher) SetClock(clock Clock) {
+	grf.clock = clock
+}
+
+// SetRequestObserver reports every HTTP request the fetcher makes to
+// observer. A nil observer stops reporting.
+func (grf *GrowthRateFetcher) SetRequestObserver(observer RequestObserver) {
+	observeClient(grf.httpClient, observer)
+}
+
+// SetFallbackEnabled controls whether hardcoded growth estimates may be used
+// when no source returns a usable value
+func (grf *GrowthRateFetcher) SetFallbackEnabled(enabled bool) {
+	grf.useFallback = enabled
+}
+
+// SetTransport sends the fetcher's requests through transport instead of
+// the transport shared by all fetchers. A nil transport restores the
+// shared one.
+func (grf *GrowthRateFetcher) SetTransport(transport http.RoundTripper) {
+	setClientTransport(grf.httpClient, transport)
+}
+
+// SetRandom sets the source of the user agents and request delays the
+// fetcher picks
+func (grf *GrowthRateFetcher) SetRandom(random *Random) {
+	grf.random = random
+}
+
 // createRealisticRequest creates an HTTP request with realistic headers and user agent
 func (grf *GrowthRateFetcher) createRealisticRequest(ctx context.Context, url string) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -76,7 +189,7 @@ func (grf *GrowthRateFetcher) createRealisticRequest(ctx context.Context, url st
 	}
 	
 	// Random user agent
-	userAgent := grf.userAgents[grf.randSource.Intn(len(grf.userAgents))]
+	userAgent := grf.userAgents[grf.random.Intn(len(grf.userAgents))]
 	req.Header.Set("User-Agent", userAgent)
 	
 	// Common browser headers
@@ -93,7 +206,7 @@ func (grf *GrowthRateFetcher) createRealisticRequest(ctx context.Context, url st
 	req.Header.Set("Cache-Control", "max-age=0")
 	
 	// Add random delay to avoid rate limiting
-	delay := time.Duration(grf.randSource.Intn(1000)+500) * time.Millisecond
+	delay := time.Duration(grf.random.Intn(1000)+500) * time.Millisecond
 	time.Sleep(delay)
 	
 	return req, nil
@@ -115,44 +228,59 @@ func (grf *GrowthRateFetcher) FetchGrowthRatesBatch(tickers []string) (map[strin
 
 // FetchGrowthRateConsensus fetches growth rate from multiple sources and calculates consensus
 func (grf *GrowthRateFetcher) FetchGrowthRateConsensus(ctx context.Context, ticker string) (float64, error) {
-	fmt.Printf("Fetching growth rate predictions for %s from multiple sources...\n", ticker)
+	consensus, err := grf.FetchGrowthConsensus(ctx, ticker)
+	return consensus.Rate, err
+}
+
+// FetchGrowthConsensus fetches growth rates from multiple sources and
+// combines them, leaving out outliers on each page and across sources
+func (grf *GrowthRateFetcher) FetchGrowthConsensus(ctx context.Context, ticker string) (GrowthConsensus, error) {
+	ctx, span := tracer.Start(ctx, "GrowthRateFetcher.FetchGrowthRateConsensus",
+		trace.WithAttributes(attribute.String("ticker", ticker)))
+	consensus, err := grf.fetchGrowthRateConsensus(ctx, ticker)
+	if err == nil {
+		span.SetAttributes(
+			attribute.Float64("growth_rate", consensus.Rate),
+			attribute.Int("rejected", len(consensus.Rejected)),
+		)
+	}
+	endSpan(span, err)
+	return consensus, err
+}
+
+// fetchGrowthRateConsensus queries every source concurrently and combines
+// their estimates
+func (grf *GrowthRateFetcher) fetchGrowthRateConsensus(ctx context.Context, ticker string) (GrowthConsensus, error) {
+	grf.logger.Printf("Fetching growth rate predictions for %s from multiple sources...\n", ticker)
 	
 	// Create channels for concurrent fetching
 	sourcesChan := make(chan GrowthRateSource, len(grf.sources))
 	var wg sync.WaitGroup
 	
-	// Fetch from all sources concurrently
+	// Fetch from all sources concurrently, except those whose layout no
+	// longer matches their extractors
 	for _, source := range grf.sources {
+		if grf.isDegraded(source) {
+			continue
+		}
 		wg.Add(1)
 		go func(sourceName string) {
 			defer wg.Done()
 			
 			var sourceData GrowthRateSource
 			sourceData.Name = sourceName
-			sourceData.FetchTime = time.Now()
+			sourceData.FetchTime = grf.clock.Now()
+
+			ctx, span := tracer.Start(ctx, "growth_source."+sourceName,
+				trace.WithAttributes(attribute.String("ticker", ticker), attribute.String("source", sourceName)))
+			defer func() {
+				if sourceData.Error == nil {
+					span.SetAttributes(attribute.Float64("growth_rate", sourceData.GrowthRate))
+				}
+				endSpan(span, sourceData.Error)
+			}()
 			
-			switch sourceName {
-			case "yahoo_finance":
-				sourceData = grf.fetchFromYahooFinance(ctx, ticker)
-			case "marketwatch":
-				sourceData = grf.fetchFromMarketWatch(ctx, ticker)
-			case "seeking_alpha":
-				sourceData = grf.fetchFromSeekingAlpha(ctx, ticker)
-			case "finviz":
-				sourceData = grf.fetchFromFinviz(ctx, ticker)
-			case "tipranks":
-				sourceData = grf.fetchFromTipRanks(ctx, ticker)
-			case "investing":
-				sourceData = grf.fetchFromInvesting(ctx, ticker)
-			case "zacks":
-				sourceData = grf.fetchFromZacks(ctx, ticker)
-			case "morningstar":
-				sourceData = grf.fetchFromMorningstar(ctx, ticker)
-			case "reuters":
-				sourceData = grf.fetchFromReuters(ctx, ticker)
-			case "bloomberg":
-				sourceData = grf.fetchFromBloomberg(ctx, ticker)
-			}
+			sourceData = grf.fetchSource(ctx, sourceName, ticker)
 			
 			sourcesChan <- sourceData
 		}(source)
@@ -169,28 +297,160 @@ func (grf *GrowthRateFetcher) FetchGrowthRateConsensus(ctx context.Context, tick
 	for sourceData := range sourcesChan {
 		sources = append(sources, sourceData)
 		if sourceData.Error != nil {
-			fmt.Printf("Error fetching from %s: %v\n", sourceData.Name, sourceData.Error)
+			grf.logger.Printf("Error fetching from %s: %v\n", sourceData.Name, sourceData.Error)
 		} else {
-			fmt.Printf("Growth rate from %s: %.2f%% (confidence: %.2f)\n", 
+			grf.logger.Printf("Growth rate from %s: %.2f%% (confidence: %.2f)\n", 
 				sourceData.Name, sourceData.GrowthRate*100, sourceData.Confidence)
 		}
 	}
 	
-	// Calculate weighted consensus
+	// Sources answer in any order; order them for a reproducible audit trail
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Name < sources[j].Name
+	})
+
+	// Leave out the sources far from the others, then weigh the rest
+	rejected := grf.rejectOutlierSources(sources)
+	for _, r := range rejected {
+		grf.logger.Printf("Rejected growth rate %.2f%% from %s for %s (%s)\n", r.Rate*100, r.Source, ticker, r.Reason)
+	}
 	consensus := grf.calculateWeightedConsensus(sources)
 	
 	if consensus == 0 {
+		if !grf.useFallback {
+			return GrowthConsensus{}, fmt.Errorf("no valid growth rate data found for %s", ticker)
+		}
+
 		// Try fallback growth estimates for major stocks
 		if fallbackGrowth := grf.getFallbackGrowthRate(ticker); fallbackGrowth > 0 {
-			fmt.Printf("Using fallback growth rate for %s: %.2f%%\n", ticker, fallbackGrowth*100)
-			return fallbackGrowth, nil
+			grf.logger.Printf("Using fallback growth rate for %s: %.2f%%\n", ticker, fallbackGrowth*100)
+			return GrowthConsensus{Rate: fallbackGrowth, Rejected: rejected, Fallback: true}, nil
 		}
-		fmt.Printf("No valid growth rate data found for %s, using default\n", ticker)
-		return 0.06, nil // Default 6% growth
+		grf.logger.Printf("No valid growth rate data found for %s, using default\n", ticker)
+		return GrowthConsensus{Rate: 0.06, Rejected: rejected, Fallback: true}, nil // Default 6% growth
 	}
 	
-	fmt.Printf("Consensus growth rate for %s: %.2f%%\n", ticker, consensus*100)
-	return consensus, nil
+	estimates, stdDev := consensusEstimates(sources)
+	grf.logger.Printf("Consensus growth rate for %s: %.2f%% (%d sources, std dev %.2f%%)\n",
+		ticker, consensus*100, len(estimates), stdDev*100)
+	return GrowthConsensus{Rate: consensus, Sources: estimates, StdDev: stdDev, Rejected: rejected}, nil
+}
+
+// consensusEstimates returns the rates of the sources the consensus is
+// weighted from and their standard deviation
+func consensusEstimates(sources []GrowthRateSource) ([]models.GrowthEstimate, float64) {
+	var estimates []models.GrowthEstimate
+	var rates []float64
+	for _, source := range sources {
+		if source.Error == nil && source.GrowthRate > 0 && !source.Outlier {
+			estimates = append(estimates, models.GrowthEstimate{
+				Source: source.Name, Rate: source.GrowthRate, Confidence: source.Confidence,
+			})
+			rates = append(rates, source.GrowthRate)
+		}
+	}
+	return estimates, stdDev(rates)
+}
+
+// fetchSource fetches the growth rate of ticker from the named source
+func (grf *GrowthRateFetcher) fetchSource(ctx context.Context, name, ticker string) GrowthRateSource {
+	ctx = withSource(ctx, name)
+	switch name {
+	case "yahoo_finance":
+		return grf.fetchFromYahooFinance(ctx, ticker)
+	case "marketwatch":
+		return grf.fetchFromMarketWatch(ctx, ticker)
+	case "seeking_alpha":
+		return grf.fetchFromSeekingAlpha(ctx, ticker)
+	case "finviz":
+		return grf.fetchFromFinviz(ctx, ticker)
+	case "tipranks":
+		return grf.fetchFromTipRanks(ctx, ticker)
+	case "investing":
+		return grf.fetchFromInvesting(ctx, ticker)
+	case "zacks":
+		return grf.fetchFromZacks(ctx, ticker)
+	case "morningstar":
+		return grf.fetchFromMorningstar(ctx, ticker)
+	case "reuters":
+		return grf.fetchFromReuters(ctx, ticker)
+	case "bloomberg":
+		return grf.fetchFromBloomberg(ctx, ticker)
+	case GrowthModelSource:
+		return grf.fetchFromGrowthModel(ctx, ticker)
+	}
+	return GrowthRateSource{Name: name, FetchTime: grf.clock.Now(), Error: fmt.Errorf("unknown growth source %q", name)}
+}
+
+// CheckScrapers runs the extractor of every growth source against the
+// page of ticker, a company every source covers, and reports how many
+// growth rates each found. The growth model scrapes nothing and is not
+// checked.
+func (grf *GrowthRateFetcher) CheckScrapers(ctx context.Context, ticker string) []ScrapeCheck {
+	scrapers := slices.DeleteFunc(slices.Clone(grf.sources), func(name string) bool {
+		return name == GrowthModelSource
+	})
+	checks := make([]ScrapeCheck, len(scrapers))
+	var wg sync.WaitGroup
+	for i, name := range scrapers {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			source := grf.fetchSource(ctx, name, ticker)
+			check := ScrapeCheck{Source: name, Ticker: ticker, Fields: source.Fields, CheckedAt: grf.clock.Now()}
+			if source.Error != nil && !errors.Is(source.Error, errNoGrowthRate) {
+				check.Error = source.Error.Error()
+			}
+			checks[i] = check
+		}(i, name)
+	}
+	wg.Wait()
+	return checks
+}
+
+// SetDegraded leaves the named sources out of the consensus until it is
+// called again without them
+func (grf *GrowthRateFetcher) SetDegraded(sources []string) {
+	degraded := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		degraded[source] = true
+	}
+	grf.degradedMutex.Lock()
+	defer grf.degradedMutex.Unlock()
+	grf.degraded = degraded
+}
+
+// isDegraded reports whether source is left out of the consensus
+func (grf *GrowthRateFetcher) isDegraded(source string) bool {
+	grf.degradedMutex.RLock()
+	defer grf.degradedMutex.RUnlock()
+	return grf.degraded[source]
+}
+
+// rejectOutlierSources marks the sources whose growth rate is an outlier
+// among those of the sources that found one, and returns the rejected
+// rates: those marked and those the sources left out on their pages
+func (grf *GrowthRateFetcher) rejectOutlierSources(sources []GrowthRateSource) []models.RejectedGrowth {
+	var rejected []models.RejectedGrowth
+	var found []int
+	var rates []float64
+	for i, source := range sources {
+		for _, rate := range source.Rejected {
+			rejected = append(rejected, models.RejectedGrowth{Source: source.Name, Rate: rate, Reason: models.OutlierOnPage})
+		}
+		if source.Error == nil && source.GrowthRate > 0 {
+			found = append(found, i)
+			rates = append(rates, source.GrowthRate)
+		}
+	}
+	for j, outlier := range outliers(rates) {
+		if outlier {
+			source := &sources[found[j]]
+			source.Outlier = true
+			rejected = append(rejected, models.RejectedGrowth{Source: source.Name, Rate: source.GrowthRate, Reason: models.OutlierAcrossSources})
+		}
+	}
+	return rejected
 }
 
 // fetchFromYahooFinance fetches growth rate from Yahoo Finance analyst estimates
@@ -198,11 +458,12 @@ func (grf *GrowthRateFetcher) fetchFromYahooFinance(ctx context.Context, ticker
 	source := GrowthRateSource{
 		Name:       "yahoo_finance",
 		Confidence: 0.85, // High confidence for Yahoo Finance
-		FetchTime:  time.Now(),
+		FetchTime:  grf.clock.Now(),
 	}
 	
 	// Try Yahoo Finance analysis page
-	analysisURL := fmt.Sprintf("https://finance.yahoo.com/quote/%s/analysis/", ticker)
+	page := grf.selectors.Page(source.Name)
+	analysisURL := page.PageURL(ticker)
 	source.URL = analysisURL
 	
 	req, err := http.NewRequestWithContext(ctx, "GET", analysisURL, nil)
@@ -215,36 +476,35 @@ func (grf *GrowthRateFetcher) fetchFromYahooFinance(ctx context.Context, ticker
 	
 	resp, err := grf.httpClient.Do(req)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to fetch data: %w", err)
+		source.Error = fmt.Errorf("failed to fetch data: %w", requestError(req, err))
 		return source
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		source.Error = fmt.Errorf("HTTP status %d", resp.StatusCode)
+		source.Error = statusError(resp)
 		return source
 	}
 	
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to parse HTML: %w", err)
+		source.Error = parseError(req.URL.Hostname(), fmt.Errorf("failed to parse HTML: %w", err))
 		return source
 	}
 	
 	// Look for growth rate estimates in various sections
-	growthRate := grf.extractYahooGrowthRate(doc)
-	source.GrowthRate = growthRate
+	source.setPageRates(grf.extractYahooGrowthRate(doc, page))
 	
 	return source
 }
 
-// extractYahooGrowthRate extracts growth rate from Yahoo Finance analysis page
-func (grf *GrowthRateFetcher) extractYahooGrowthRate(doc *goquery.Document) float64 {
+// extractYahooGrowthRate extracts the growth rates on the Yahoo Finance analysis page
+func (grf *GrowthRateFetcher) extractYahooGrowthRate(doc *goquery.Document, page PageSelectors) []float64 {
 	// Look for growth estimates table
 	var growthRates []float64
 	
 	// Search for "Growth Estimates" section with more patterns
-	doc.Find("table, div, span").Each(func(i int, elem *goquery.Selection) {
+	doc.Find(page.Selector("text")).Each(func(i int, elem *goquery.Selection) {
 		text := strings.TrimSpace(elem.Text())
 		lowerText := strings.ToLower(text)
 		
@@ -264,9 +524,9 @@ func (grf *GrowthRateFetcher) extractYahooGrowthRate(doc *goquery.Document) floa
 	})
 	
 	// More aggressive search in table cells
-	doc.Find("table").Each(func(i int, table *goquery.Selection) {
-		table.Find("tr").Each(func(j int, row *goquery.Selection) {
-			cells := row.Find("td")
+	doc.Find(page.Selector("tables")).Each(func(i int, table *goquery.Selection) {
+		table.Find(page.Selector("rows")).Each(func(j int, row *goquery.Selection) {
+			cells := row.Find(page.Selector("cells"))
 			if cells.Length() >= 2 {
 				label := strings.TrimSpace(cells.First().Text())
 				lowerLabel := strings.ToLower(label)
@@ -291,25 +551,14 @@ func (grf *GrowthRateFetcher) extractYahooGrowthRate(doc *goquery.Document) floa
 	})
 	
 	// Look for JSON data with growth estimates
-	doc.Find("script").Each(func(i int, script *goquery.Selection) {
+	doc.Find(page.Selector("scripts")).Each(func(i int, script *goquery.Selection) {
 		content := script.Text()
 		if strings.Contains(content, "growth") && strings.Contains(content, "estimate") {
-			if growth := grf.extractGrowthFromJSON(content); growth > 0 {
-				growthRates = append(growthRates, growth)
-			}
+			growthRates = append(growthRates, grf.extractGrowthFromJSON(content)...)
 		}
 	})
 	
-	// Return average of found growth rates
-	if len(growthRates) > 0 {
-		sum := 0.0
-		for _, rate := range growthRates {
-			sum += rate
-		}
-		return sum / float64(len(growthRates))
-	}
-	
-	return 0
+	return growthRates
 }
 
 // fetchFromMarketWatch fetches growth rate from MarketWatch
@@ -317,11 +566,12 @@ func (grf *GrowthRateFetcher) fetchFromMarketWatch(ctx context.Context, ticker s
 	source := GrowthRateSource{
 		Name:       "marketwatch",
 		Confidence: 0.7,
-		FetchTime:  time.Now(),
+		FetchTime:  grf.clock.Now(),
 	}
 	
 	// MarketWatch analyst estimates URL
-	analysisURL := fmt.Sprintf("https://www.marketwatch.com/investing/stock/%s/analystestimates", ticker)
+	page := grf.selectors.Page(source.Name)
+	analysisURL := page.PageURL(ticker)
 	source.URL = analysisURL
 	
 	req, err := http.NewRequestWithContext(ctx, "GET", analysisURL, nil)
@@ -334,41 +584,40 @@ func (grf *GrowthRateFetcher) fetchFromMarketWatch(ctx context.Context, ticker s
 	
 	resp, err := grf.httpClient.Do(req)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to fetch data: %w", err)
+		source.Error = fmt.Errorf("failed to fetch data: %w", requestError(req, err))
 		return source
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		source.Error = fmt.Errorf("HTTP status %d", resp.StatusCode)
+		source.Error = statusError(resp)
 		return source
 	}
 	
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to parse HTML: %w", err)
+		source.Error = parseError(req.URL.Hostname(), fmt.Errorf("failed to parse HTML: %w", err))
 		return source
 	}
 	
-	growthRate := grf.extractMarketWatchGrowthRate(doc)
-	source.GrowthRate = growthRate
+	source.setPageRates(grf.extractMarketWatchGrowthRate(doc, page))
 	
 	return source
 }
 
-// extractMarketWatchGrowthRate extracts growth rate from MarketWatch
-func (grf *GrowthRateFetcher) extractMarketWatchGrowthRate(doc *goquery.Document) float64 {
+// extractMarketWatchGrowthRate extracts the growth rates on MarketWatch
+func (grf *GrowthRateFetcher) extractMarketWatchGrowthRate(doc *goquery.Document, page PageSelectors) []float64 {
 	var growthRates []float64
 	
 	// Look for growth estimates in tables
-	doc.Find("table").Each(func(i int, table *goquery.Selection) {
-		table.Find("tr").Each(func(j int, row *goquery.Selection) {
-			label := strings.TrimSpace(row.Find("td").First().Text())
+	doc.Find(page.Selector("tables")).Each(func(i int, table *goquery.Selection) {
+		table.Find(page.Selector("rows")).Each(func(j int, row *goquery.Selection) {
+			label := strings.TrimSpace(row.Find(page.Selector("cells")).First().Text())
 			
 			if strings.Contains(strings.ToLower(label), "growth") ||
 			   strings.Contains(strings.ToLower(label), "estimate") {
 				
-				row.Find("td").Each(func(k int, cell *goquery.Selection) {
+				row.Find(page.Selector("cells")).Each(func(k int, cell *goquery.Selection) {
 					if k > 0 {
 						text := strings.TrimSpace(cell.Text())
 						if growth, err := grf.parseGrowthValue(text); err == nil && growth > 0 {
@@ -380,16 +629,7 @@ func (grf *GrowthRateFetcher) extractMarketWatchGrowthRate(doc *goquery.Document
 		})
 	})
 	
-	// Return average if we found any rates
-	if len(growthRates) > 0 {
-		sum := 0.0
-		for _, rate := range growthRates {
-			sum += rate
-		}
-		return sum / float64(len(growthRates))
-	}
-	
-	return 0
+	return growthRates
 }
 
 // fetchFromSeekingAlpha fetches growth rate from Seeking Alpha
@@ -397,11 +637,12 @@ func (grf *GrowthRateFetcher) fetchFromSeekingAlpha(ctx context.Context, ticker
 	source := GrowthRateSource{
 		Name:       "seeking_alpha",
 		Confidence: 0.6,
-		FetchTime:  time.Now(),
+		FetchTime:  grf.clock.Now(),
 	}
 	
 	// Seeking Alpha overview page
-	overviewURL := fmt.Sprintf("https://seekingalpha.com/symbol/%s", ticker)
+	page := grf.selectors.Page(source.Name)
+	overviewURL := page.PageURL(ticker)
 	source.URL = overviewURL
 	
 	req, err := http.NewRequestWithContext(ctx, "GET", overviewURL, nil)
@@ -414,34 +655,33 @@ func (grf *GrowthRateFetcher) fetchFromSeekingAlpha(ctx context.Context, ticker
 	
 	resp, err := grf.httpClient.Do(req)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to fetch data: %w", err)
+		source.Error = fmt.Errorf("failed to fetch data: %w", requestError(req, err))
 		return source
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		source.Error = fmt.Errorf("HTTP status %d", resp.StatusCode)
+		source.Error = statusError(resp)
 		return source
 	}
 	
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to parse HTML: %w", err)
+		source.Error = parseError(req.URL.Hostname(), fmt.Errorf("failed to parse HTML: %w", err))
 		return source
 	}
 	
-	growthRate := grf.extractSeekingAlphaGrowthRate(doc)
-	source.GrowthRate = growthRate
+	source.setPageRates(grf.extractSeekingAlphaGrowthRate(doc, page))
 	
 	return source
 }
 
-// extractSeekingAlphaGrowthRate extracts growth rate from Seeking Alpha
-func (grf *GrowthRateFetcher) extractSeekingAlphaGrowthRate(doc *goquery.Document) float64 {
+// extractSeekingAlphaGrowthRate extracts the growth rates on Seeking Alpha
+func (grf *GrowthRateFetcher) extractSeekingAlphaGrowthRate(doc *goquery.Document, page PageSelectors) []float64 {
 	var growthRates []float64
 	
 	// Look for growth metrics in various sections
-	doc.Find("div, span, td").Each(func(i int, elem *goquery.Selection) {
+	doc.Find(page.Selector("text")).Each(func(i int, elem *goquery.Selection) {
 		text := strings.TrimSpace(elem.Text())
 		
 		if strings.Contains(strings.ToLower(text), "growth") &&
@@ -453,16 +693,7 @@ func (grf *GrowthRateFetcher) extractSeekingAlphaGrowthRate(doc *goquery.Documen
 		}
 	})
 	
-	// Return average if we found any rates
-	if len(growthRates) > 0 {
-		sum := 0.0
-		for _, rate := range growthRates {
-			sum += rate
-		}
-		return sum / float64(len(growthRates))
-	}
-	
-	return 0
+	return growthRates
 }
 
 // fetchFromFinviz fetches growth rate from Finviz
@@ -470,11 +701,12 @@ func (grf *GrowthRateFetcher) fetchFromFinviz(ctx context.Context, ticker string
 	source := GrowthRateSource{
 		Name:       "finviz",
 		Confidence: 0.95, // Highest confidence for Finviz due to clean data format
-		FetchTime:  time.Now(),
+		FetchTime:  grf.clock.Now(),
 	}
 	
 	// Finviz stock overview page
-	overviewURL := fmt.Sprintf("https://finviz.com/quote.ashx?t=%s", ticker)
+	page := grf.selectors.Page(source.Name)
+	overviewURL := page.PageURL(ticker)
 	source.URL = overviewURL
 	
 	req, err := http.NewRequestWithContext(ctx, "GET", overviewURL, nil)
@@ -487,35 +719,34 @@ func (grf *GrowthRateFetcher) fetchFromFinviz(ctx context.Context, ticker string
 	
 	resp, err := grf.httpClient.Do(req)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to fetch data: %w", err)
+		source.Error = fmt.Errorf("failed to fetch data: %w", requestError(req, err))
 		return source
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		source.Error = fmt.Errorf("HTTP status %d", resp.StatusCode)
+		source.Error = statusError(resp)
 		return source
 	}
 	
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to parse HTML: %w", err)
+		source.Error = parseError(req.URL.Hostname(), fmt.Errorf("failed to parse HTML: %w", err))
 		return source
 	}
 	
-	growthRate := grf.extractFinvizGrowthRate(doc)
-	source.GrowthRate = growthRate
+	source.setPageRates(grf.extractFinvizGrowthRate(doc, page))
 	
 	return source
 }
 
-// extractFinvizGrowthRate extracts growth rate from Finviz
-func (grf *GrowthRateFetcher) extractFinvizGrowthRate(doc *goquery.Document) float64 {
+// extractFinvizGrowthRate extracts the growth rates on Finviz
+func (grf *GrowthRateFetcher) extractFinvizGrowthRate(doc *goquery.Document, page PageSelectors) []float64 {
 	var growthRates []float64
 	
 	// Finviz typically shows growth in a table format
-	doc.Find("table").Each(func(i int, table *goquery.Selection) {
-		table.Find("td").Each(func(j int, cell *goquery.Selection) {
+	doc.Find(page.Selector("tables")).Each(func(i int, table *goquery.Selection) {
+		table.Find(page.Selector("cells")).Each(func(j int, cell *goquery.Selection) {
 			text := strings.TrimSpace(cell.Text())
 			lowerText := strings.ToLower(text)
 			
@@ -540,9 +771,9 @@ func (grf *GrowthRateFetcher) extractFinvizGrowthRate(doc *goquery.Document) flo
 	})
 	
 	// Also look in the main data table (Finviz specific structure)
-	doc.Find("table.snapshot-table2").Each(func(i int, table *goquery.Selection) {
-		table.Find("tr").Each(func(j int, row *goquery.Selection) {
-			cells := row.Find("td")
+	doc.Find(page.Selector("snapshot")).Each(func(i int, table *goquery.Selection) {
+		table.Find(page.Selector("rows")).Each(func(j int, row *goquery.Selection) {
+			cells := row.Find(page.Selector("cells"))
 			cells.Each(func(k int, cell *goquery.Selection) {
 				text := strings.TrimSpace(cell.Text())
 				lowerText := strings.ToLower(text)
@@ -563,16 +794,7 @@ func (grf *GrowthRateFetcher) extractFinvizGrowthRate(doc *goquery.Document) flo
 		})
 	})
 	
-	// Return average if we found any rates
-	if len(growthRates) > 0 {
-		sum := 0.0
-		for _, rate := range growthRates {
-			sum += rate
-		}
-		return sum / float64(len(growthRates))
-	}
-	
-	return 0
+	return growthRates
 }
 
 // fetchFromTipRanks fetches growth rate from TipRanks
@@ -580,11 +802,12 @@ func (grf *GrowthRateFetcher) fetchFromTipRanks(ctx context.Context, ticker stri
 	source := GrowthRateSource{
 		Name:       "tipranks",
 		Confidence: 0.9, // TipRanks has high-quality analyst data
-		FetchTime:  time.Now(),
+		FetchTime:  grf.clock.Now(),
 	}
 	
 	// TipRanks stock analysis URL
-	analysisURL := fmt.Sprintf("https://www.tipranks.com/stocks/%s/forecast", ticker)
+	page := grf.selectors.Page(source.Name)
+	analysisURL := page.PageURL(ticker)
 	source.URL = analysisURL
 	
 	req, err := http.NewRequestWithContext(ctx, "GET", analysisURL, nil)
@@ -597,34 +820,33 @@ func (grf *GrowthRateFetcher) fetchFromTipRanks(ctx context.Context, ticker stri
 	
 	resp, err := grf.httpClient.Do(req)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to fetch data: %w", err)
+		source.Error = fmt.Errorf("failed to fetch data: %w", requestError(req, err))
 		return source
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		source.Error = fmt.Errorf("HTTP status %d", resp.StatusCode)
+		source.Error = statusError(resp)
 		return source
 	}
 	
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to parse HTML: %w", err)
+		source.Error = parseError(req.URL.Hostname(), fmt.Errorf("failed to parse HTML: %w", err))
 		return source
 	}
 	
-	growthRate := grf.extractTipRanksGrowthRate(doc)
-	source.GrowthRate = growthRate
+	source.setPageRates(grf.extractTipRanksGrowthRate(doc, page))
 	
 	return source
 }
 
-// extractTipRanksGrowthRate extracts growth rate from TipRanks
-func (grf *GrowthRateFetcher) extractTipRanksGrowthRate(doc *goquery.Document) float64 {
+// extractTipRanksGrowthRate extracts the growth rates on TipRanks
+func (grf *GrowthRateFetcher) extractTipRanksGrowthRate(doc *goquery.Document, page PageSelectors) []float64 {
 	var growthRates []float64
 	
 	// TipRanks typically shows analyst estimates in various sections
-	doc.Find("div, span, td").Each(func(i int, elem *goquery.Selection) {
+	doc.Find(page.Selector("text")).Each(func(i int, elem *goquery.Selection) {
 		text := strings.TrimSpace(elem.Text())
 		lowerText := strings.ToLower(text)
 		
@@ -645,9 +867,9 @@ func (grf *GrowthRateFetcher) extractTipRanksGrowthRate(doc *goquery.Document) f
 	})
 	
 	// Look for analyst consensus tables
-	doc.Find("table").Each(func(i int, table *goquery.Selection) {
-		table.Find("tr").Each(func(j int, row *goquery.Selection) {
-			cells := row.Find("td")
+	doc.Find(page.Selector("tables")).Each(func(i int, table *goquery.Selection) {
+		table.Find(page.Selector("rows")).Each(func(j int, row *goquery.Selection) {
+			cells := row.Find(page.Selector("cells"))
 			if cells.Length() >= 2 {
 				label := strings.TrimSpace(cells.First().Text())
 				lowerLabel := strings.ToLower(label)
@@ -670,26 +892,15 @@ func (grf *GrowthRateFetcher) extractTipRanksGrowthRate(doc *goquery.Document) f
 	})
 	
 	// Look for JSON data embedded in scripts
-	doc.Find("script").Each(func(i int, script *goquery.Selection) {
+	doc.Find(page.Selector("scripts")).Each(func(i int, script *goquery.Selection) {
 		content := script.Text()
 		if strings.Contains(content, "growth") && 
 		   (strings.Contains(content, "estimate") || strings.Contains(content, "consensus")) {
-			if growth := grf.extractGrowthFromJSON(content); growth > 0 {
-				growthRates = append(growthRates, growth)
-			}
+			growthRates = append(growthRates, grf.extractGrowthFromJSON(content)...)
 		}
 	})
 	
-	// Return average if we found any rates
-	if len(growthRates) > 0 {
-		sum := 0.0
-		for _, rate := range growthRates {
-			sum += rate
-		}
-		return sum / float64(len(growthRates))
-	}
-	
-	return 0
+	return growthRates
 }
 
 // fetchFromInvesting fetches growth rate from Investing.com
@@ -697,30 +908,12 @@ func (grf *GrowthRateFetcher) fetchFromInvesting(ctx context.Context, ticker str
 	source := GrowthRateSource{
 		Name:       "investing",
 		Confidence: 0.8, // Investing.com has good analyst data
-		FetchTime:  time.Now(),
-	}
-	
-	// Investing.com earnings estimates URL - try multiple formats
-	analysisURL := fmt.Sprintf("https://www.investing.com/equities/%s-earnings", strings.ToLower(ticker))
-	
-	// Alternative URL format for Investing.com
-	if strings.Contains(ticker, "-") {
-		analysisURL = fmt.Sprintf("https://www.investing.com/equities/%s-earnings", strings.ToLower(ticker))
-	} else {
-		// Try company name format for single-word tickers
-		companyNames := map[string]string{
-			"AAPL":  "apple-computer-inc",
-			"MSFT":  "microsoft-corp",
-			"GOOGL": "google-inc",
-			"AMZN":  "amazon-com-inc",
-			"NVDA":  "nvidia-corp",
-			"META":  "meta-platforms-inc",
-			"TSLA":  "tesla-motors",
-		}
-		if companyName, exists := companyNames[ticker]; exists {
-			analysisURL = fmt.Sprintf("https://www.investing.com/equities/%s-earnings", companyName)
-		}
+		FetchTime:  grf.clock.Now(),
 	}
+	
+	// Investing.com names pages after the company where it is known
+	page := grf.selectors.Page(source.Name)
+	analysisURL := page.PageURL(ticker)
 	source.URL = analysisURL
 	
 	req, err := http.NewRequestWithContext(ctx, "GET", analysisURL, nil)
@@ -733,36 +926,35 @@ func (grf *GrowthRateFetcher) fetchFromInvesting(ctx context.Context, ticker str
 	
 	resp, err := grf.httpClient.Do(req)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to fetch data: %w", err)
+		source.Error = fmt.Errorf("failed to fetch data: %w", requestError(req, err))
 		return source
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		source.Error = fmt.Errorf("HTTP status %d", resp.StatusCode)
+		source.Error = statusError(resp)
 		return source
 	}
 	
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		source.Error = fmt.Errorf("failed to parse HTML: %w", err)
+		source.Error = parseError(req.URL.Hostname(), fmt.Errorf("failed to parse HTML: %w", err))
 		return source
 	}
 	
-	growthRate := grf.extractInvestingGrowthRate(doc)
-	source.GrowthRate = growthRate
+	source.setPageRates(grf.extractInvestingGrowthRate(doc, page))
 	
 	return source
 }
 
-// extractInvestingGrowthRate extracts growth rate from Investing.com
-func (grf *GrowthRateFetcher) extractInvestingGrowthRate(doc *goquery.Document) float64 {
+// extractInvestingGrowthRate extracts the growth rates on Investing.com
+func (grf *GrowthRateFetcher) extractInvestingGrowthRate(doc *goquery.Document, page PageSelectors) []float64 {
 	var growthRates []float64
 	
 	// Investing.com typically shows estimates in structured tables
-	doc.Find("table").Each(func(i int, table *goquery.Selection) {
-		table.Find("tr").Each(func(j int, row *goquery.Selection) {
-			cells := row.Find("td, th")
+	doc.Find(page.Selector("tables")).Each(func(i int, table *goquery.Selection) {
+		table.Find(page.Selector("rows")).Each(func(j int, row *goquery.Selection) {
+			cells := row.Find(page.Selector("cells"))
 			if cells.Length() >= 2 {
 				label := strings.TrimSpace(cells.First().Text())
 				lowerLabel := strings.ToLower(label)
@@ -789,7 +981,7 @@ func (grf *GrowthRateFetcher) extractInvestingGrowthRate(doc *goquery.Document)
 	})
 	
 	// Look for estimates in div/span elements
-	doc.Find("div, span").Each(func(i int, elem *goquery.Selection) {
+	doc.Find(page.Selector("text")).Each(func(i int, elem *goquery.Selection) {
 		text := strings.TrimSpace(elem.Text())
 		lowerText := strings.ToLower(text)
 		
@@ -805,69 +997,45 @@ func (grf *GrowthRateFetcher) extractInvestingGrowthRate(doc *goquery.Document)
 		}
 	})
 	
-	// Return average if we found any rates
-	if len(growthRates) > 0 {
-		sum := 0.0
-		for _, rate := range growthRates {
-			sum += rate
-		}
-		return sum / float64(len(growthRates))
-	}
-	
-	return 0
+	return growthRates
 }
 
-// parseGrowthValue parses growth rate from text (handles percentages)
+// parseGrowthValue parses a growth rate from a table cell such as "12.5%"
+// or "-3.20", which is in percent, or from free text such as "Next year:
+// 12.5%", where only a number with a percent sign is taken for one. Rates
+// outside -50% to +100% fail with finparse.ErrRange rather than being
+// clamped into range, since they are more likely misread than real.
 func (grf *GrowthRateFetcher) parseGrowthValue(text string) (float64, error) {
-	// Clean the text
-	cleaned := strings.ReplaceAll(text, "%", "")
-	cleaned = strings.ReplaceAll(cleaned, ",", "")
-	cleaned = strings.ReplaceAll(cleaned, "$", "")
-	cleaned = strings.ReplaceAll(cleaned, "(", "")
-	cleaned = strings.ReplaceAll(cleaned, ")", "")
-	cleaned = strings.TrimSpace(cleaned)
-	
-	// Handle N/A or empty values
-	if cleaned == "" || cleaned == "N/A" || cleaned == "--" || cleaned == "NA" || 
-	   cleaned == "n/a" || cleaned == "null" || cleaned == "none" {
-		return 0, fmt.Errorf("no valid value")
-	}
-	
-	// Use regex to extract number (including negative numbers and decimals)
-	re := regexp.MustCompile(`-?\d+\.?\d*`)
-	match := re.FindString(cleaned)
-	if match == "" {
-		return 0, fmt.Errorf("no number found")
+	value, err := finparse.Percent(text)
+	if errors.Is(err, finparse.ErrSyntax) {
+		value, err = finparse.FindPercent(text)
 	}
-	
-	value, err := strconv.ParseFloat(match, 64)
 	if err != nil {
 		return 0, err
 	}
-	
-	// Convert percentage to decimal if it looks like a percentage
-	if strings.Contains(text, "%") || value > 1 {
-		value = value / 100
-	}
-	
-	// Apply reasonable bounds (growth rates typically between -50% to +100%)
-	if value < -0.5 {
-		value = -0.5
-	}
-	if value > 1.0 {
-		value = 1.0
+	if err := checkGrowthRange(value); err != nil {
+		return 0, err
 	}
-	
+
 	// Filter out very small values that are likely errors
 	if value > 0 && value < 0.001 {
-		return 0, fmt.Errorf("value too small")
+		return 0, fmt.Errorf("growth rate %g: %w", value, finparse.ErrRange)
 	}
-	
+
 	return value, nil
 }
 
-// extractGrowthFromJSON extracts growth rate from JSON content
-func (grf *GrowthRateFetcher) extractGrowthFromJSON(content string) float64 {
+// checkGrowthRange fails with finparse.ErrRange for growth rates outside
+// -50% to +100%, as fractions
+func checkGrowthRange(rate float64) error {
+	if rate < -0.5 || rate > 1.0 {
+		return fmt.Errorf("growth rate %g: %w", rate, finparse.ErrRange)
+	}
+	return nil
+}
+
+// extractGrowthFromJSON extracts the growth rates in JSON content
+func (grf *GrowthRateFetcher) extractGrowthFromJSON(content string) []float64 {
 	// Use regex to find growth-related values in JSON
 	re := regexp.MustCompile(`"growth"[^}]*?(\d+\.?\d*)`)
 	matches := re.FindAllStringSubmatch(content, -1)
@@ -875,25 +1043,15 @@ func (grf *GrowthRateFetcher) extractGrowthFromJSON(content string) float64 {
 	var growthRates []float64
 	for _, match := range matches {
 		if len(match) > 1 {
-			if value, err := strconv.ParseFloat(match[1], 64); err == nil {
-				if value > 1 {
-					value = value / 100
-				}
+			// Embedded JSON holds raw fractions, so anything out of range
+			// is some other number rather than a percentage
+			if value, err := strconv.ParseFloat(match[1], 64); err == nil && checkGrowthRange(value) == nil {
 				growthRates = append(growthRates, value)
 			}
 		}
 	}
 	
-	// Return average if we found any rates
-	if len(growthRates) > 0 {
-		sum := 0.0
-		for _, rate := range growthRates {
-			sum += rate
-		}
-		return sum / float64(len(growthRates))
-	}
-	
-	return 0
+	return growthRates
 }
 
 // calculateWeightedConsensus calculates weighted average of growth rates
@@ -902,7 +1060,7 @@ func (grf *GrowthRateFetcher) calculateWeightedConsensus(sources []GrowthRateSou
 	var weightedSum float64
 	
 	for _, source := range sources {
-		if source.Error == nil && source.GrowthRate > 0 {
+		if source.Error == nil && source.GrowthRate > 0 && !source.Outlier {
 			weight := source.Confidence
 			totalWeight += weight
 			weightedSum += source.GrowthRate * weight
@@ -932,7 +1090,7 @@ func (grf *GrowthRateFetcher) calculateWeightedConsensus(sources []GrowthRateSou
 // setRequestHeaders sets browser-like headers
 func (grf *GrowthRateFetcher) setRequestHeaders(req *http.Request) {
 	// Use the enhanced user agent from the struct
-	userAgent := grf.userAgents[grf.randSource.Intn(len(grf.userAgents))]
+	userAgent := grf.userAgents[grf.random.Intn(len(grf.userAgents))]
 	req.Header.Set("User-Agent", userAgent)
 	
 	// Enhanced browser headers to mimic real browsers
@@ -958,7 +1116,7 @@ func (grf *GrowthRateFetcher) setRequestHeaders(req *http.Request) {
 	}
 	
 	// Add random delay to avoid rate limiting
-	delay := time.Duration(grf.randSource.Intn(1000)+500) * time.Millisecond
+	delay := time.Duration(grf.random.Intn(1000)+500) * time.Millisecond
 	time.Sleep(delay)
 }
 
@@ -1028,9 +1186,10 @@ func (grf *GrowthRateFetcher) getFallbackGrowthRate(ticker string) float64 {
 
 // fetchFromZacks fetches growth rate from Zacks Investment Research
 func (grf *GrowthRateFetcher) fetchFromZacks(ctx context.Context, ticker string) GrowthRateSource {
+	page := grf.selectors.Page("zacks")
 	source := GrowthRateSource{
 		Name:       "zacks",
-		URL:        fmt.Sprintf("https://www.zacks.com/stock/quote/%s", ticker),
+		URL:        page.PageURL(ticker),
 		Confidence: 0.85,
 	}
 	
@@ -1044,45 +1203,42 @@ func (grf *GrowthRateFetcher) fetchFromZacks(ctx context.Context, ticker string)
 	
 	resp, err := grf.httpClient.Do(req)
 	if err != nil {
-		source.Error = err
+		source.Error = requestError(req, err)
 		return source
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		source.Error = fmt.Errorf("HTTP status %d", resp.StatusCode)
+		source.Error = statusError(resp)
 		return source
 	}
 	
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		source.Error = err
+		source.Error = parseError(req.URL.Hostname(), err)
 		return source
 	}
 	
 	// Look for growth rate patterns in Zacks format
-	growthTexts := []string{
-		doc.Find(".rank_view .zr_ranktext").Text(),
-		doc.Find(".composite_val").Text(),
-		doc.Find("[data-test='growth-rate']").Text(),
-	}
-	
-	for _, text := range growthTexts {
+	for _, selector := range page.Alternatives("growth") {
+		text := doc.Find(selector).Text()
 		if rate, err := grf.parseGrowthValue(text); err == nil && rate > 0 {
 			source.GrowthRate = rate
+			source.Fields = 1
 			return source
 		}
 	}
 	
-	source.Error = fmt.Errorf("no growth rate found")
+	source.Error = errNoGrowthRate
 	return source
 }
 
 // fetchFromMorningstar fetches growth rate from Morningstar
 func (grf *GrowthRateFetcher) fetchFromMorningstar(ctx context.Context, ticker string) GrowthRateSource {
+	page := grf.selectors.Page("morningstar")
 	source := GrowthRateSource{
 		Name:       "morningstar",
-		URL:        fmt.Sprintf("https://www.morningstar.com/stocks/xnas/%s/quote", ticker),
+		URL:        page.PageURL(ticker),
 		Confidence: 0.90,
 	}
 	
@@ -1096,45 +1252,42 @@ func (grf *GrowthRateFetcher) fetchFromMorningstar(ctx context.Context, ticker s
 	
 	resp, err := grf.httpClient.Do(req)
 	if err != nil {
-		source.Error = err
+		source.Error = requestError(req, err)
 		return source
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		source.Error = fmt.Errorf("HTTP status %d", resp.StatusCode)
+		source.Error = statusError(resp)
 		return source
 	}
 	
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		source.Error = err
+		source.Error = parseError(req.URL.Hostname(), err)
 		return source
 	}
 	
 	// Look for growth rate patterns in Morningstar format
-	growthTexts := []string{
-		doc.Find(".dp-value").Text(),
-		doc.Find("[data-test='growth-forecast']").Text(),
-		doc.Find(".sal-component-cta-link").Text(),
-	}
-	
-	for _, text := range growthTexts {
+	for _, selector := range page.Alternatives("growth") {
+		text := doc.Find(selector).Text()
 		if rate, err := grf.parseGrowthValue(text); err == nil && rate > 0 {
 			source.GrowthRate = rate
+			source.Fields = 1
 			return source
 		}
 	}
 	
-	source.Error = fmt.Errorf("no growth rate found")
+	source.Error = errNoGrowthRate
 	return source
 }
 
 // fetchFromReuters fetches growth rate from Reuters
 func (grf *GrowthRateFetcher) fetchFromReuters(ctx context.Context, ticker string) GrowthRateSource {
+	page := grf.selectors.Page("reuters")
 	source := GrowthRateSource{
 		Name:       "reuters",
-		URL:        fmt.Sprintf("https://www.reuters.com/markets/companies/%s.O", ticker),
+		URL:        page.PageURL(ticker),
 		Confidence: 0.85,
 	}
 	
@@ -1148,45 +1301,42 @@ func (grf *GrowthRateFetcher) fetchFromReuters(ctx context.Context, ticker strin
 	
 	resp, err := grf.httpClient.Do(req)
 	if err != nil {
-		source.Error = err
+		source.Error = requestError(req, err)
 		return source
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		source.Error = fmt.Errorf("HTTP status %d", resp.StatusCode)
+		source.Error = statusError(resp)
 		return source
 	}
 	
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		source.Error = err
+		source.Error = parseError(req.URL.Hostname(), err)
 		return source
 	}
 	
 	// Look for growth rate patterns in Reuters format
-	growthTexts := []string{
-		doc.Find(".forecast-data").Text(),
-		doc.Find(".analyst-estimates").Text(),
-		doc.Find("[data-testid='growth-rate']").Text(),
-	}
-	
-	for _, text := range growthTexts {
+	for _, selector := range page.Alternatives("growth") {
+		text := doc.Find(selector).Text()
 		if rate, err := grf.parseGrowthValue(text); err == nil && rate > 0 {
 			source.GrowthRate = rate
+			source.Fields = 1
 			return source
 		}
 	}
 	
-	source.Error = fmt.Errorf("no growth rate found")
+	source.Error = errNoGrowthRate
 	return source
 }
 
 // fetchFromBloomberg fetches growth rate from Bloomberg
 func (grf *GrowthRateFetcher) fetchFromBloomberg(ctx context.Context, ticker string) GrowthRateSource {
+	page := grf.selectors.Page("bloomberg")
 	source := GrowthRateSource{
 		Name:       "bloomberg",
-		URL:        fmt.Sprintf("https://www.bloomberg.com/quote/%s:US", ticker),
+		URL:        page.PageURL(ticker),
 		Confidence: 0.90,
 	}
 	
@@ -1200,36 +1350,32 @@ func (grf *GrowthRateFetcher) fetchFromBloomberg(ctx context.Context, ticker str
 	
 	resp, err := grf.httpClient.Do(req)
 	if err != nil {
-		source.Error = err
+		source.Error = requestError(req, err)
 		return source
 	}
 	defer resp.Body.Close()
 	
 	if resp.StatusCode != http.StatusOK {
-		source.Error = fmt.Errorf("HTTP status %d", resp.StatusCode)
+		source.Error = statusError(resp)
 		return source
 	}
 	
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		source.Error = err
+		source.Error = parseError(req.URL.Hostname(), err)
 		return source
 	}
 	
 	// Look for growth rate patterns in Bloomberg format
-	growthTexts := []string{
-		doc.Find(".data-table-row").Text(),
-		doc.Find("[data-module='Estimates']").Text(),
-		doc.Find(".analyst-forecast").Text(),
-	}
-	
-	for _, text := range growthTexts {
+	for _, selector := range page.Alternatives("growth") {
+		text := doc.Find(selector).Text()
 		if rate, err := grf.parseGrowthValue(text); err == nil && rate > 0 {
 			source.GrowthRate = rate
+			source.Fields = 1
 			return source
 		}
 	}
 	
-	source.Error = fmt.Errorf("no growth rate found")
+	source.Error = errNoGrowthRate
 	return source
 }
\ No newline at end of file
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Provider hosts probed by CheckProviders, by the capability that uses them
+const (
+	yahooAPIHost    = "query1.finance.yahoo.com"
+	yahooScrapeHost = "finance.yahoo.com"
+)
+
+// ProviderCheck is the outcome of probing one data provider
+type ProviderCheck struct {
+	Host    string
+	Latency time.Duration
+	Err     error // nil when the provider answered
+}
+
+// CheckProviders probes the hosts of the enabled data sources. Any HTTP
+// response counts as reachable, since the check is about connectivity
+// rather than whether a particular ticker can be fetched.
+func (df *DataFetcher) CheckProviders(ctx context.Context) []ProviderCheck {
+	var hosts []string
+	if df.features.EnableYahooAPI {
+		hosts = append(hosts, yahooAPIHost)
+	}
+	if df.features.EnableScraping {
+		hosts = append(hosts, yahooScrapeHost)
+	}
+
+	// Probes bypass the request observer and tracing so frequent readiness
+	// checks neither affect adaptive workers nor clutter traces
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	checks := make([]ProviderCheck, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			checks[i] = probeHost(ctx, client, host)
+		}(i, host)
+	}
+	wg.Wait()
+	return checks
+}
+
+// probeHost sends a HEAD request to the root of host
+func probeHost(ctx context.Context, client *http.Client, host string) ProviderCheck {
+	check := ProviderCheck{Host: host}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://"+host+"/", nil)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	check.Latency = time.Since(start)
+	if err != nil {
+		check.Err = fmt.Errorf("%s unreachable: %w", host, err)
+		return check
+	}
+	resp.Body.Close()
+	return check
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HostAuth holds what requests to a host are sent with on top of the
+// fetchers' own headers, so a subscription to a data site can be used
+// with the fetchers
+type HostAuth struct {
+	Headers     map[string]string `json:"headers,omitempty"`      // replace headers of the same name
+	Cookies     map[string]string `json:"cookies,omitempty"`      // sent after the fetchers' cookies
+	BearerToken string            `json:"bearer_token,omitempty"` // sent as "Authorization: Bearer TOKEN"
+}
+
+// Validate checks that auth can be sent to host
+func (auth HostAuth) Validate(host string) error {
+	if host == "" || host != strings.ToLower(host) || strings.ContainsAny(host, ":/ ") {
+		return fmt.Errorf("host %q must be a lower-case host name without scheme or port", host)
+	}
+	for name := range auth.Headers {
+		if name == "" || strings.ContainsAny(name, ": \r\n") {
+			return fmt.Errorf("host %s: invalid header name %q", host, name)
+		}
+	}
+	for name := range auth.Cookies {
+		if name == "" || strings.ContainsAny(name, "=; \r\n") {
+			return fmt.Errorf("host %s: invalid cookie name %q", host, name)
+		}
+	}
+	return nil
+}
+
+// authTransport adds the HostAuth of a request's host to the request
+type authTransport struct {
+	base  http.RoundTripper
+	hosts map[string]HostAuth
+}
+
+// NewAuthTransport returns a transport that sends requests through base,
+// or the transport shared by all fetchers when base is nil, with the
+// HostAuth of their host. A host's entry also covers its subdomains, and
+// the most specific entry applies. Credentials are only sent over HTTPS,
+// and since redirects are requests of their own, never to another host.
+func NewAuthTransport(base http.RoundTripper, hosts map[string]HostAuth) http.RoundTripper {
+	if base == nil {
+		base = sharedTransport
+	}
+	return authTransport{base: base, hosts: hosts}
+}
+
+// RoundTrip sends req with the HostAuth of its host
+func (t authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	auth, ok := t.lookup(req.URL.Hostname())
+	if !ok || req.URL.Scheme != "https" {
+		return t.base.RoundTrip(req)
+	}
+
+	// A transport must not modify the request it was given
+	req = req.Clone(req.Context())
+	for name, value := range auth.Headers {
+		req.Header.Set(name, value)
+	}
+	if auth.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+auth.BearerToken)
+	}
+	for name, value := range auth.Cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	return t.base.RoundTrip(req)
+}
+
+// lookup returns the HostAuth of host or of the closest parent domain
+// that has one
+func (t authTransport) lookup(host string) (HostAuth, bool) {
+	host = strings.ToLower(host)
+	for {
+		if auth, ok := t.hosts[host]; ok {
+			return auth, true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok || !strings.Contains(parent, ".") {
+			return HostAuth{}, false
+		}
+		host = parent
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Events of an HTTP trace
+const (
+	TraceRequest = "request" // an HTTP request a fetcher sent
+	TraceCache   = "cache"   // a lookup of a ticker's data in the caches
+)
+
+// Outcomes of the cache lookups in an HTTP trace
+const (
+	CacheHit       = "hit"        // found in the stock data cache
+	CacheMemoryHit = "memory_hit" // found in the in-memory cache
+	CacheMiss      = "miss"       // not cached, so it was fetched
+)
+
+// redactedParams are the query parameters an HTTP trace leaves out the
+// values of, as they carry session tokens
+var redactedParams = []string{"crumb"}
+
+// HTTPTraceEntry is a line of an HTTP trace
+type HTTPTraceEntry struct {
+	Time      time.Time `json:"time"`
+	Event     string    `json:"event"`            // TraceRequest or TraceCache
+	Ticker    string    `json:"ticker,omitempty"` // ticker the request or lookup was made for
+	Source    string    `json:"source,omitempty"` // as reported to a RequestObserver
+	Method    string    `json:"method,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Status    int       `json:"status,omitempty"`     // 0 when no response was received
+	LatencyMS int64     `json:"latency_ms,omitempty"` // until the response headers arrived
+	Bytes     int64     `json:"bytes,omitempty"`      // of the response body read
+	Cache     string    `json:"cache,omitempty"`      // CacheHit, CacheMemoryHit or CacheMiss
+	Error     string    `json:"error,omitempty"`
+}
+
+// HTTPTrace writes a JSON line to a file for every HTTP request the
+// fetchers send and every cache lookup of a ticker, so why a ticker got
+// the data it did can be followed without the output filling up with
+// requests. Entries are stamped with the system clock, also in
+// deterministic runs, and written as requests complete, so a trace of an
+// interrupted run is complete up to the interruption. A nil HTTPTrace
+// traces nothing.
+type HTTPTrace struct {
+	mu       sync.Mutex
+	encoder  *json.Encoder
+	requests int
+	err      error // first write error; later entries are dropped
+}
+
+// NewHTTPTrace returns a trace writing to w
+func NewHTTPTrace(w io.Writer) *HTTPTrace {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &HTTPTrace{encoder: encoder}
+}
+
+// Requests returns the number of requests traced so far
+func (t *HTTPTrace) Requests() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requests
+}
+
+// Err returns the error that stopped the trace from being written, if any
+func (t *HTTPTrace) Err() error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// Cache records the outcome of looking up the data of ticker in the caches
+func (t *HTTPTrace) Cache(ticker, outcome string) {
+	if t == nil {
+		return
+	}
+	t.write(HTTPTraceEntry{Time: time.Now(), Event: TraceCache, Ticker: ticker, Cache: outcome})
+}
+
+// Transport returns a transport sending requests through base and tracing
+// them. A nil base is the transport shared by all fetchers.
+func (t *HTTPTrace) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = sharedTransport
+	}
+	return tracingHTTPTransport{base: base, trace: t}
+}
+
+// write appends entry to the trace
+func (t *HTTPTrace) write(entry HTTPTraceEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if entry.Event == TraceRequest {
+		t.requests++
+	}
+	if t.err == nil {
+		t.err = t.encoder.Encode(entry)
+	}
+}
+
+// tracingHTTPTransport writes each round trip to an HTTPTrace
+type tracingHTTPTransport struct {
+	base  http.RoundTripper
+	trace *HTTPTrace
+}
+
+// RoundTrip performs the request and traces it once its body is closed,
+// when the number of bytes read from it is known
+func (t tracingHTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := HTTPTraceEntry{
+		Time:   time.Now(),
+		Event:  TraceRequest,
+		Ticker: requestTicker(req),
+		Source: requestSource(req),
+		Method: req.Method,
+		URL:    redactURL(req),
+		Cache:  CacheMiss,
+	}
+	resp, err := t.base.RoundTrip(req)
+	entry.LatencyMS = time.Since(entry.Time).Milliseconds()
+	if err != nil {
+		entry.Error = err.Error()
+		t.trace.write(entry)
+		return resp, err
+	}
+
+	entry.Status = resp.StatusCode
+	resp.Body = &countingBody{ReadCloser: resp.Body, done: func(n int64, err error) {
+		entry.Bytes = n
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		t.trace.write(entry)
+	}}
+	return resp, nil
+}
+
+// countingBody counts the bytes read from a response body and reports
+// them, with any read error other than EOF, when it is closed
+type countingBody struct {
+	io.ReadCloser
+	n      int64
+	err    error
+	done   func(n int64, err error)
+	closed sync.Once
+}
+
+// Read reads from the body, counting the bytes read
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err != nil && err != io.EOF && b.err == nil {
+		b.err = err
+	}
+	return n, err
+}
+
+// Close closes the body and reports what was read from it
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.closed.Do(func() { b.done(b.n, b.err) })
+	return err
+}
+
+// redactURL returns the URL of req without the values of redactedParams
+func redactURL(req *http.Request) string {
+	query := req.URL.Query()
+	redacted := false
+	for _, param := range redactedParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return req.URL.String()
+	}
+	u := *req.URL
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// tickerKey is the context key of the ticker requests are made for
+type tickerKey struct{}
+
+// WithTicker makes the requests made with ctx traced as made for ticker
+func WithTicker(ctx context.Context, ticker string) context.Context {
+	return context.WithValue(ctx, tickerKey{}, ticker)
+}
+
+// requestTicker returns the ticker req was made for, if it is known
+func requestTicker(req *http.Request) string {
+	ticker, _ := req.Context().Value(tickerKey{}).(string)
+	return ticker
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Indices whose constituents can be valued as the universe
+const (
+	IndexDow30     = "dow30"
+	IndexNasdaq100 = "nasdaq100"
+	IndexSP500     = "sp500"
+)
+
+// DefaultIndex is the index whose constituents are valued when no ticker
+// file can be found
+const DefaultIndex = IndexDow30
+
+// SourceIndexConstituents is the source of index constituent requests, as
+// reported to a RequestObserver
+const SourceIndexConstituents = "index_constituents"
+
+//go:embed indices/*.txt
+var embeddedIndices embed.FS
+
+// indexPages are the Wikipedia pages listing the constituents of each
+// index in a table with the "constituents" ID
+var indexPages = map[string]string{
+	IndexDow30:     "https://en.wikipedia.org/wiki/Dow_Jones_Industrial_Average",
+	IndexNasdaq100: "https://en.wikipedia.org/wiki/Nasdaq-100",
+	IndexSP500:     "https://en.wikipedia.org/wiki/List_of_S%26P_500_companies",
+}
+
+// minConstituentShare is the share of the built-in constituents a fetched
+// list must have at least. Indices change a few members at a time, so a
+// shorter list means the page changed rather than the index.
+const minConstituentShare = 0.9
+
+// Indices returns the names of the indices with known constituents
+func Indices() []string {
+	names := make([]string, 0, len(indexPages))
+	for name := range indexPages {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// IsIndex reports whether name is an index with known constituents
+func IsIndex(name string) bool {
+	_, ok := indexPages[name]
+	return ok
+}
+
+// DefaultConstituents returns the constituents of index built into the
+// binary, as of its release, or nil for an unknown index
+func DefaultConstituents(index string) []string {
+	if !IsIndex(index) {
+		return nil
+	}
+	data, err := embeddedIndices.ReadFile("indices/" + index + ".txt")
+	if err != nil {
+		panic(fmt.Sprintf("built-in constituents of %s: %v", index, err))
+	}
+	var tickers []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			tickers = append(tickers, line)
+		}
+	}
+	return tickers
+}
+
+// FetchIndexConstituents returns the current constituents of index from
+// its Wikipedia page, in the page's notation, which may write share
+// classes with a dot
+func (df *DataFetcher) FetchIndexConstituents(ctx context.Context, index string) ([]string, error) {
+	pageURL, ok := indexPages[index]
+	if !ok {
+		return nil, fmt.Errorf("unknown index %q, expected one of %s", index, strings.Join(Indices(), ", "))
+	}
+	if !df.features.EnableScraping {
+		return nil, fmt.Errorf("fetching index constituents requires scraping to be enabled")
+	}
+
+	req, err := http.NewRequestWithContext(withSource(ctx, SourceIndexConstituents), http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	df.setRequestHeaders(req)
+
+	resp, err := df.httpClient.Do(req)
+	if err != nil {
+		return nil, requestError(req, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, parseError(req.URL.Hostname(), fmt.Errorf("failed to parse HTML: %w", err))
+	}
+	tickers := extractConstituents(doc)
+	if minimum := int(float64(len(DefaultConstituents(index))) * minConstituentShare); len(tickers) < minimum {
+		return nil, parseError(req.URL.Hostname(),
+			fmt.Errorf("found %d constituents of %s, expected at least %d", len(tickers), index, minimum))
+	}
+	return tickers, nil
+}
+
+// extractConstituents reads the tickers from the column headed "Symbol"
+// or "Ticker" of the constituents table. Rows may start with a header
+// cell naming the company, so header and data cells are counted alike.
+func extractConstituents(doc *goquery.Document) []string {
+	var tickers []string
+	column := -1
+	doc.Find("table#constituents tr").Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("th, td")
+		if column < 0 {
+			cells.EachWithBreak(func(i int, cell *goquery.Selection) bool {
+				switch strings.TrimSpace(cell.Text()) {
+				case "Symbol", "Ticker":
+					column = i
+					return false
+				}
+				return true
+			})
+			return
+		}
+		if column < cells.Length() {
+			ticker := strings.TrimSpace(cells.Eq(column).Text())
+			if ticker != "" && !slices.Contains(tickers, ticker) {
+				tickers = append(tickers, ticker)
+			}
+		}
+	})
+	return tickers
+}
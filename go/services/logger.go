@@ -0,0 +1,36 @@
+package services
+
+import (
+	"fmt"
+	"io"
+)
+
+// Logger receives the progress and diagnostic messages of the fetchers.
+// Messages are formatted like fmt.Printf and end with a newline.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// writerLogger writes messages to an io.Writer
+type writerLogger struct {
+	w io.Writer
+}
+
+// NewWriterLogger returns a Logger writing to w
+func NewWriterLogger(w io.Writer) Logger {
+	return writerLogger{w: w}
+}
+
+// Printf writes a formatted message
+func (l writerLogger) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(l.w, format, args...)
+}
+
+// NopLogger discards every message
+var NopLogger Logger = nopLogger{}
+
+// nopLogger is a Logger that discards messages
+type nopLogger struct{}
+
+// Printf discards the message
+func (nopLogger) Printf(format string, args ...interface{}) {}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// MemoryCache keeps the stock data and growth consensus of recently used
+// tickers in memory for long-running modes that value the same tickers
+// again and again. It holds at most a fixed number of tickers of each,
+// evicting the least recently used. Prices and fundamentals expire
+// separately: data whose price is stale but whose fundamentals are current
+// only needs a fresh price.
+type MemoryCache struct {
+	priceTTL        time.Duration
+	fundamentalsTTL time.Duration
+	clock           Clock
+
+	mutex  sync.Mutex
+	stocks *lru[*models.StockData]
+	growth *lru[GrowthConsensus]
+}
+
+// NewMemoryCache creates a memory cache of up to capacity tickers whose
+// prices expire after priceTTL and fundamentals after fundamentalsTTL
+func NewMemoryCache(capacity int, priceTTL, fundamentalsTTL time.Duration) *MemoryCache {
+	return &MemoryCache{
+		priceTTL:        priceTTL,
+		fundamentalsTTL: fundamentalsTTL,
+		clock:           SystemClock,
+		stocks:          newLRU[*models.StockData](capacity),
+		growth:          newLRU[GrowthConsensus](capacity),
+	}
+}
+
+// SetClock sets the clock entries expire by
+func (m *MemoryCache) SetClock(clock Clock) {
+	m.clock = clock
+}
+
+// StockData returns a copy of the data kept for ticker when its
+// fundamentals are current, and whether its price is current too
+func (m *MemoryCache) StockData(ticker string) (stockData *models.StockData, priceCurrent, ok bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry, fetched, ok := m.stocks.get(ticker)
+	if !ok {
+		return nil, false, false
+	}
+	now := m.clock.Now()
+	if now.Sub(fetched) > m.fundamentalsTTL {
+		m.stocks.remove(ticker)
+		return nil, false, false
+	}
+
+	priced := fetched
+	if at, ok := entry.FieldTimes["current_price"]; ok && at.After(priced) {
+		priced = at
+	}
+	return entry.Snapshot(), now.Sub(priced) <= m.priceTTL, true
+}
+
+// PutStockData keeps a copy of stockData, fetched at its fetch time
+func (m *MemoryCache) PutStockData(stockData *models.StockData) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.stocks.put(stockData.Ticker, stockData.Snapshot(), stockData.FetchTime)
+}
+
+// GrowthConsensus returns the growth consensus kept for ticker while it is
+// current
+func (m *MemoryCache) GrowthConsensus(ticker string) (GrowthConsensus, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	consensus, fetched, ok := m.growth.get(ticker)
+	if !ok {
+		return GrowthConsensus{}, false
+	}
+	if m.clock.Now().Sub(fetched) > m.fundamentalsTTL {
+		m.growth.remove(ticker)
+		return GrowthConsensus{}, false
+	}
+	consensus.Rejected = slices.Clone(consensus.Rejected)
+	return consensus, true
+}
+
+// PutGrowthConsensus keeps the growth consensus of ticker, fetched now
+func (m *MemoryCache) PutGrowthConsensus(ticker string, consensus GrowthConsensus) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	consensus.Rejected = slices.Clone(consensus.Rejected)
+	m.growth.put(ticker, consensus, m.clock.Now())
+}
+
+// Len returns the number of tickers with stock data and with growth
+// consensus kept, including expired ones not yet evicted
+func (m *MemoryCache) Len() (stocks, growth int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.stocks.len(), m.growth.len()
+}
+
+// lru is a least recently used map of values stamped with a time. It is
+// not safe for concurrent use.
+type lru[V any] struct {
+	capacity int
+	order    *list.List // most recently used first
+	entries  map[string]*list.Element
+}
+
+// lruEntry is an element of lru.order
+type lruEntry[V any] struct {
+	key   string
+	value V
+	at    time.Time
+}
+
+// newLRU creates an lru holding up to capacity values
+func newLRU[V any](capacity int) *lru[V] {
+	return &lru[V]{capacity: capacity, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get returns the value of key and when it was stamped, marking it as
+// recently used
+func (c *lru[V]) get(key string) (V, time.Time, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, time.Time{}, false
+	}
+	c.order.MoveToFront(element)
+	entry := element.Value.(*lruEntry[V])
+	return entry.value, entry.at, true
+}
+
+// put stores the value of key stamped with at, evicting the least
+// recently used value when the lru is full
+func (c *lru[V]) put(key string, value V, at time.Time) {
+	if element, ok := c.entries[key]; ok {
+		element.Value = &lruEntry[V]{key: key, value: value, at: at}
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value, at: at})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+// remove deletes the value of key
+func (c *lru[V]) remove(key string) {
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+// len returns the number of values held
+func (c *lru[V]) len() int {
+	return c.order.Len()
+}
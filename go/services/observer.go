@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Sources of the Yahoo Finance API requests, as reported to a
+// RequestObserver. The scraped pages are reported by their scraper names
+// and the growth sites by their growth source names.
+const (
+	SourceYahooChart        = "yahoo_chart"
+	SourceYahooQuoteSummary = "yahoo_quote_summary"
+	SourceYahooTimeseries   = "yahoo_timeseries"
+	SourceYahooSession      = "yahoo_session"
+)
+
+// RequestObserver is told about every HTTP request a fetcher makes. Source
+// names what the request fetched, such as SourceYahooChart or a growth
+// site, or is the host it was sent to when the fetcher did not name it;
+// status is 0 when no response was received.
+type RequestObserver interface {
+	ObserveRequest(source string, status int, latency time.Duration, err error)
+}
+
+// observingTransport reports each round trip to a RequestObserver
+type observingTransport struct {
+	base     http.RoundTripper
+	observer RequestObserver
+}
+
+// RoundTrip performs the request and reports its outcome
+func (t observingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	// A cancelled request says nothing about the source
+	if req.Context().Err() == nil {
+		t.observer.ObserveRequest(requestSource(req), status, time.Since(start), err)
+	}
+	return resp, err
+}
+
+// sourceKey is the context key of the source a request is reported as
+type sourceKey struct{}
+
+// withSource makes the requests made with ctx report as coming from source
+func withSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// requestSource returns the source req reports as coming from, which is
+// its host unless the fetcher named it
+func requestSource(req *http.Request) string {
+	if source, ok := req.Context().Value(sourceKey{}).(string); ok {
+		return source
+	}
+	return req.URL.Hostname()
+}
+
+// observeClient makes client report its requests to observer
+func observeClient(client *http.Client, observer RequestObserver) {
+	base := client.Transport
+	if t, ok := base.(observingTransport); ok {
+		base = t.base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if observer == nil {
+		client.Transport = base
+		return
+	}
+	client.Transport = observingTransport{base: base, observer: observer}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/symbols"
+)
+
+// IdentifierResolver resolves security identifiers, such as the ISINs and
+// CUSIPs of custodian exports, to exchange tickers
+type IdentifierResolver interface {
+	// ResolveIdentifiers returns the ticker of each identifier it knows,
+	// keyed by the identifier. Identifiers it does not know are left out.
+	ResolveIdentifiers(ctx context.Context, ids []string) (map[string]string, error)
+}
+
+// OpenFIGI mapping API limits: jobs per request and the time between
+// requests, without and with an API key
+const (
+	openFIGIURL               = "https://api.openfigi.com/v3/mapping"
+	openFIGIBatch             = 10
+	openFIGIKeyedBatch        = 100
+	openFIGIInterval          = 2500 * time.Millisecond
+	openFIGIKeyedInterval     = 250 * time.Millisecond
+	defaultIdentifierExchange = "US"
+)
+
+// OpenFIGI resolves ISINs and CUSIPs to tickers with the OpenFIGI mapping
+// API, remembering every answer. Requests are spaced to stay within the
+// API's rate limit, which an API key raises.
+type OpenFIGI struct {
+	httpClient *http.Client
+	apiKey     string
+	exchange   string
+	logger     Logger
+
+	mutex    sync.Mutex // held while resolving, so requests are spaced
+	resolved map[string]string
+	unknown  map[string]bool
+	last     time.Time
+}
+
+// NewOpenFIGI creates a resolver for listings on the exchange with the
+// OpenFIGI exchange code exchange, such as "US" for US composite tickers
+// (the default when empty). apiKey may be empty.
+func NewOpenFIGI(apiKey, exchange string) *OpenFIGI {
+	if exchange == "" {
+		exchange = defaultIdentifierExchange
+	}
+	return &OpenFIGI{
+		httpClient: newTracingClient(&http.Client{
+			Timeout:   30 * time.Second,
+			Transport: sharedTransport,
+		}),
+		apiKey:   apiKey,
+		exchange: exchange,
+		logger:   NopLogger,
+		resolved: make(map[string]string),
+		unknown:  make(map[string]bool),
+	}
+}
+
+// SetLogger sets where progress messages are written
+func (o *OpenFIGI) SetLogger(logger Logger) {
+	o.logger = logger
+}
+
+// openFIGIJob is a mapping request for one identifier
+type openFIGIJob struct {
+	IDType   string `json:"idType"`
+	IDValue  string `json:"idValue"`
+	ExchCode string `json:"exchCode,omitempty"`
+}
+
+// openFIGIResult is the answer to one job: matching listings, or a warning
+// or error
+type openFIGIResult struct {
+	Data []struct {
+		Ticker   string `json:"ticker"`
+		ExchCode string `json:"exchCode"`
+	} `json:"data"`
+	Warning string `json:"warning"`
+	Error   string `json:"error"`
+}
+
+// ResolveIdentifiers returns the ticker of each ISIN or CUSIP in ids that
+// OpenFIGI lists on the configured exchange. Anything else in ids is left
+// out, as are identifiers without such a listing.
+func (o *OpenFIGI) ResolveIdentifiers(ctx context.Context, ids []string) (map[string]string, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	tickers := make(map[string]string)
+	var jobs []openFIGIJob
+	queued := make(map[string]bool)
+	for _, id := range ids {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		kind := symbols.IdentifierKind(id)
+		switch {
+		case kind == "" || o.unknown[id]:
+		case o.resolved[id] != "":
+			tickers[id] = o.resolved[id]
+		case !queued[id]:
+			queued[id] = true
+			jobs = append(jobs, openFIGIJob{IDType: kind, IDValue: id, ExchCode: o.exchange})
+		}
+	}
+	if len(jobs) == 0 {
+		return tickers, nil
+	}
+
+	batch, interval := openFIGIBatch, openFIGIInterval
+	if o.apiKey != "" {
+		batch, interval = openFIGIKeyedBatch, openFIGIKeyedInterval
+	}
+	o.logger.Printf("Resolving %d identifiers with OpenFIGI...\n", len(jobs))
+	for start := 0; start < len(jobs); start += batch {
+		if err := o.wait(ctx, interval); err != nil {
+			return tickers, err
+		}
+		chunk := jobs[start:min(start+batch, len(jobs))]
+		results, err := withRetry(ctx, func() ([]openFIGIResult, error) {
+			return o.request(ctx, chunk)
+		})
+		if err != nil {
+			return tickers, fmt.Errorf("failed to resolve identifiers: %w", err)
+		}
+		for i, result := range results {
+			if i >= len(chunk) {
+				break
+			}
+			id := chunk[i].IDValue
+			if len(result.Data) == 0 || result.Data[0].Ticker == "" {
+				o.unknown[id] = true
+				continue
+			}
+			o.resolved[id] = result.Data[0].Ticker
+			tickers[id] = result.Data[0].Ticker
+		}
+	}
+	return tickers, nil
+}
+
+// wait sleeps until interval has passed since the last request
+func (o *OpenFIGI) wait(ctx context.Context, interval time.Duration) error {
+	delay := time.Until(o.last.Add(interval))
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	o.last = time.Now()
+	return nil
+}
+
+// request sends one mapping request, returning a result per job
+func (o *OpenFIGI) request(ctx context.Context, jobs []openFIGIJob) ([]openFIGIResult, error) {
+	body, err := json.Marshal(jobs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, openFIGIURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("X-OPENFIGI-APIKEY", o.apiKey)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, requestError(req, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, requestError(req, fmt.Errorf("failed to read response body: %w", err))
+	}
+	var results []openFIGIResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, parseError(req.URL.Hostname(), fmt.Errorf("failed to parse JSON response: %w", err))
+	}
+	return results, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"math"
+	"slices"
+)
+
+// Outlier rejection by the modified z-score of Iglewicz and Hoaglin: a
+// value whose distance from the median is more than outlierThreshold
+// times the median absolute deviation (scaled to a standard deviation) is
+// rejected. The median and its deviation are not pulled towards outliers
+// the way the mean and standard deviation are.
+const (
+	outlierThreshold = 3.5
+	// madScale scales the median absolute deviation to a standard
+	// deviation under normality
+	madScale = 0.6745
+	// minOutlierSamples is the fewest values outliers are rejected from,
+	// since two disagreeing values give no majority to trust
+	minOutlierSamples = 3
+	// minDeviation floors the median absolute deviation, so values that
+	// mostly agree exactly do not make every small difference an outlier.
+	// It is in the units of growth rates: half a percentage point.
+	minDeviation = 0.005
+)
+
+// outliers reports which of values are outliers, by index
+func outliers(values []float64) []bool {
+	rejected := make([]bool, len(values))
+	if len(values) < minOutlierSamples {
+		return rejected
+	}
+	center := median(values)
+	deviations := make([]float64, len(values))
+	for i, value := range values {
+		deviations[i] = math.Abs(value - center)
+	}
+	mad := max(median(deviations), minDeviation)
+	for i, deviation := range deviations {
+		rejected[i] = madScale*deviation/mad > outlierThreshold
+	}
+	return rejected
+}
+
+// rejectOutliers splits values into those kept and the outliers among them
+func rejectOutliers(values []float64) (kept, rejected []float64) {
+	for i, outlier := range outliers(values) {
+		if outlier {
+			rejected = append(rejected, values[i])
+		} else {
+			kept = append(kept, values[i])
+		}
+	}
+	return kept, rejected
+}
+
+// median returns the median of values, which must not be empty
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// mean returns the average of values, or 0 when there are none
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev returns the population standard deviation of values, or 0 when
+// there are none
+func stdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	avg := mean(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += (value - avg) * (value - avg)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// yahooPage is a Yahoo Finance page scraped for fundamentals
+type yahooPage struct {
+	scraper string // as named in the selectors
+	name    string // in messages, as in its URL
+	extract func(df *DataFetcher, doc *goquery.Document, page PageSelectors, stockData *models.StockData) error
+}
+
+// The Yahoo Finance pages scraped for fundamentals
+var (
+	keyStatisticsPage = yahooPage{ScraperYahooKeyStatistics, "key-statistics", (*DataFetcher).extractKeyStatistics} // P/E, EPS, market cap, book value
+	financialsPage    = yahooPage{ScraperYahooFinancials, "financials", (*DataFetcher).extractFinancialsData}       // free cash flow
+	profilePage       = yahooPage{ScraperYahooProfile, "profile", (*DataFetcher).extractProfileData}                // sector, company name
+)
+
+// yahooPages are the pages scraped when the quote summary API does not
+// answer, in the order their figures are applied
+var yahooPages = []yahooPage{keyStatisticsPage, financialsPage, profilePage}
+
+// fetchPage requests page for ticker and parses it
+func (df *DataFetcher) fetchPage(ctx context.Context, page yahooPage, ticker string) (*goquery.Document, error) {
+	req, err := http.NewRequestWithContext(withSource(ctx, page.scraper), "GET", df.selectors.Page(page.scraper).PageURL(ticker), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers to mimic browser request
+	df.setRequestHeaders(req)
+
+	resp, err := df.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s data: %w", page.name, requestError(req, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Yahoo Finance %s: %w", page.name, statusError(resp))
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, parseError(req.URL.Hostname(), fmt.Errorf("failed to parse HTML: %w", err))
+	}
+	return doc, nil
+}
+
+// extractPage sets the figures found in doc, the parsed page, on stockData
+func (df *DataFetcher) extractPage(page yahooPage, doc *goquery.Document, stockData *models.StockData) error {
+	if err := page.extract(df, doc, df.selectors.Page(page.scraper), stockData); err != nil {
+		return fmt.Errorf("failed to extract %s data: %w", page.name, err)
+	}
+	return nil
+}
+
+// scrapePage fetches page for ticker and sets the figures found on it on
+// stockData
+func (df *DataFetcher) scrapePage(ctx context.Context, page yahooPage, ticker string, stockData *models.StockData) error {
+	doc, err := df.fetchPage(ctx, page, ticker)
+	if err != nil {
+		return err
+	}
+	return df.extractPage(page, doc, stockData)
+}
+
+// scrapePages fetches the yahooPages of ticker concurrently and sets the
+// figures found on them on stockData in their order, calling applied after
+// each. Every page but the first waits its turn of the request spacing
+// shared by all tickers, so the pages of a ticker take about as long as
+// the slowest of them rather than all of them together.
+func (df *DataFetcher) scrapePages(ctx context.Context, ticker string, stockData *models.StockData, applied func()) {
+	docs := make([]*goquery.Document, len(yahooPages))
+	errs := make([]error, len(yahooPages))
+	var wg sync.WaitGroup
+	for i, page := range yahooPages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i > 0 {
+				df.addRequestDelay()
+			}
+			docs[i], errs[i] = df.fetchPage(ctx, page, ticker)
+		}()
+	}
+	wg.Wait()
+
+	for i, page := range yahooPages {
+		err := errs[i]
+		if err == nil {
+			err = df.extractPage(page, docs[i], stockData)
+		}
+		if err != nil {
+			df.logger.Printf("Failed to fetch %s data for %s: %v\n", page.name, ticker, err)
+		}
+		applied()
+	}
+}
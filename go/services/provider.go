@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// StockDataProvider supplies the data stocks are valued on. DataFetcher
+// fetches it from the enabled web sources; other providers, such as the
+// in-memory one of package servicestest, can stand in for it. A provider
+// is used by every worker at once, so it must be safe for concurrent use.
+type StockDataProvider interface {
+	// FetchStockData returns the price and fundamentals of ticker
+	FetchStockData(ctx context.Context, ticker string) (*models.StockData, error)
+
+	// FetchPrice returns the current price of ticker, for price-only
+	// refreshes
+	FetchPrice(ctx context.Context, ticker string) (float64, error)
+}
+
+var (
+	_ StockDataProvider  = (*DataFetcher)(nil)
+	_ GrowthSource       = (*GrowthRateFetcher)(nil)
+	_ GrowthDetailSource = (*GrowthRateFetcher)(nil)
+	_ SplitSource        = (*DataFetcher)(nil)
+	_ BenchmarkSource    = (*DataFetcher)(nil)
+	_ SentimentSource    = (*Finnhub)(nil)
+	_ ShareCountSource   = (*DataFetcher)(nil)
+)
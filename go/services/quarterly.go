@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ttmQuarters is the number of quarterly statements trailing twelve month
+// figures are summed from
+const ttmQuarters = 4
+
+// maxQuarterGap is how far apart the ends of two quarters may be for them
+// to follow each other; a longer gap means a statement is missing
+const maxQuarterGap = 120 * 24 * time.Hour
+
+// quarterlyTypes are the series of the quarterly statements fetched with
+// every stock's data
+var quarterlyTypes = []string{
+	"quarterlyDilutedEPS", "quarterlyFreeCashFlow",
+	"quarterlyStockholdersEquity", "quarterlyGoodwillAndOtherIntangibleAssets",
+	"quarterlyGoodwill", "quarterlyOtherIntangibleAssets",
+}
+
+// ffoTypes are the series funds from operations are calculated from,
+// fetched along with the quarterly statements of REITs
+var ffoTypes = []string{
+	"quarterlyNetIncome", "quarterlyDepreciationAndAmortization", "quarterlyGainOnSaleOfPPE",
+}
+
+// bankTypes are the series the return on equity and net interest margin
+// of financials are calculated from, fetched along with their quarterly
+// statements
+var bankTypes = []string{
+	"quarterlyNetIncome", "quarterlyNetInterestIncome", "quarterlyTotalAssets",
+}
+
+// statementTypes are the series of every statement fetched with a stock's
+// data. The series of REITs and financials are fetched for every stock, as
+// its sector is not known until the other sources it is fetched alongside
+// answered.
+var statementTypes = func() []string {
+	var types []string
+	for _, series := range slices.Concat(quarterlyTypes, ffoTypes, bankTypes) {
+		if !slices.Contains(types, series) {
+			types = append(types, series)
+		}
+	}
+	return types
+}()
+
+// fetchQuarterlyStatements fetches the latest quarterly statements of
+// ticker from the Yahoo Finance fundamentals timeseries API
+func (df *DataFetcher) fetchQuarterlyStatements(ctx context.Context, ticker string) (*yahooTimeseriesResponse, error) {
+	now := df.clock.Now()
+	query := url.Values{
+		"symbol":  {ticker},
+		"type":    {strings.Join(statementTypes, ",")},
+		"period1": {strconv.FormatInt(now.AddDate(-2, 0, 0).Unix(), 10)},
+		"period2": {strconv.FormatInt(now.Unix(), 10)},
+	}
+	return withRetry(ctx, func() (*yahooTimeseriesResponse, error) {
+		return df.fetchTimeseriesOnce(ctx, ticker, query)
+	})
+}
+
+// applyQuarterlyStatements sets the trailing twelve month figures and the
+// tangible book value of stockData from the quarterly statements in
+// response, along with the funds from operations of REITs and the bank
+// metrics of financials. Figures the statements do not cover are left as
+// they were.
+func (df *DataFetcher) applyQuarterlyStatements(ticker string, response *yahooTimeseriesResponse, stockData *models.StockData) error {
+	ttmErr := applyTTM(response, stockData)
+	bookErr := applyTangibleBook(response, stockData)
+	if ttmErr != nil && bookErr != nil {
+		return fmt.Errorf("no quarterly figures for %s: %v; %v", ticker, ttmErr, bookErr)
+	}
+	if stockData.IsREIT() {
+		if err := applyFFO(response, stockData); err != nil {
+			df.logger.Printf("No funds from operations for %s, valuing it on EPS: %v\n", ticker, err)
+		}
+	}
+	if stockData.IsFinancial() {
+		if err := applyBankMetrics(response, stockData); err != nil {
+			df.logger.Printf("Incomplete bank metrics for %s: %v\n", ticker, err)
+		}
+	}
+	return nil
+}
+
+// applyBankMetrics sets the net interest margin of stockData to the
+// trailing twelve months of net interest income over the total assets of
+// the latest quarter, which stand in for the earning assets statements do
+// not report, and the return on equity to the trailing net income over
+// the latest equity when no source reported it
+func applyBankMetrics(response *yahooTimeseriesResponse, stockData *models.StockData) error {
+	var errs []string
+	if stockData.ReturnOnEquity == 0 {
+		netIncome, _, err := trailingSum(response.series("quarterlyNetIncome"))
+		equity, ok := latest(response.series("quarterlyStockholdersEquity"))
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Sprintf("trailing net income: %v", err))
+		case !ok || equity <= 0:
+			errs = append(errs, "no positive stockholders' equity")
+		default:
+			stockData.ReturnOnEquity = netIncome / equity
+		}
+	}
+
+	interest, _, err := trailingSum(response.series("quarterlyNetInterestIncome"))
+	assets, ok := latest(response.series("quarterlyTotalAssets"))
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Sprintf("trailing net interest income: %v", err))
+	case !ok || assets <= 0:
+		errs = append(errs, "no total assets")
+	default:
+		stockData.NetInterestMargin = interest / assets
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// latest returns the figure of the latest quarter of a quarterly series
+func latest(series map[time.Time]float64) (float64, bool) {
+	var last time.Time
+	for date := range series {
+		if date.After(last) {
+			last = date
+		}
+	}
+	value, ok := series[last]
+	return value, ok
+}
+
+// applyFFO sets the funds from operations per share of stockData to the
+// trailing twelve months of net income plus depreciation and amortization,
+// less gains on the sale of property, which REITs report as NAREIT FFO.
+// Quarters without property sales report no gains, so a missing gains
+// series counts as zero.
+func applyFFO(response *yahooTimeseriesResponse, stockData *models.StockData) error {
+	netIncome, _, err := trailingSum(response.series("quarterlyNetIncome"))
+	if err != nil {
+		return fmt.Errorf("trailing net income: %v", err)
+	}
+	depreciation, _, err := trailingSum(response.series("quarterlyDepreciationAndAmortization"))
+	if err != nil {
+		return fmt.Errorf("trailing depreciation and amortization: %v", err)
+	}
+	gains, _, _ := trailingSum(response.series("quarterlyGainOnSaleOfPPE"))
+	shares := sharesOutstanding(stockData)
+	if shares <= 0 {
+		return fmt.Errorf("no share count to divide funds from operations by")
+	}
+	stockData.FFOPerShare = (netIncome + depreciation - gains) / shares
+	return nil
+}
+
+// applyTTM sets the EPS and free cash flow per share of stockData to the
+// sums of the four latest quarters, and TTMAsOf to the end of the latest
+// quarter. Annual statements lag by up to a year and scraped "ttm" labels
+// by however long the page went without an update, so the quarters replace
+// them. A figure missing one of the four quarters is left as it was.
+func applyTTM(response *yahooTimeseriesResponse, stockData *models.StockData) error {
+	eps, epsAsOf, epsErr := trailingSum(response.series("quarterlyDilutedEPS"))
+	fcf, fcfAsOf, fcfErr := trailingSum(response.series("quarterlyFreeCashFlow"))
+	shares := sharesOutstanding(stockData)
+	if fcfErr == nil && shares <= 0 {
+		fcfErr = fmt.Errorf("no share count to divide free cash flow by")
+	}
+	if epsErr != nil && fcfErr != nil {
+		return fmt.Errorf("trailing EPS: %v; trailing free cash flow: %v", epsErr, fcfErr)
+	}
+
+	// Figures from statements ending on different dates are as of the
+	// earlier one
+	var asOf time.Time
+	if epsErr == nil {
+		stockData.EPS = eps
+		asOf = epsAsOf
+	}
+	if fcfErr == nil {
+		stockData.FCFPerShare = fcf / shares
+		if asOf.IsZero() || fcfAsOf.Before(asOf) {
+			asOf = fcfAsOf
+		}
+	}
+	stockData.TTMAsOf = asOf
+	return nil
+}
+
+// applyTangibleBook sets the tangible book value per share of stockData to
+// the stockholders' equity of the latest quarter less its goodwill and
+// other intangible assets. Companies without intangibles report none, so
+// a missing series counts as zero. The result is negative when acquired
+// goodwill exceeds equity.
+func applyTangibleBook(response *yahooTimeseriesResponse, stockData *models.StockData) error {
+	equity := response.series("quarterlyStockholdersEquity")
+	if len(equity) == 0 {
+		return fmt.Errorf("tangible book value: no stockholders' equity")
+	}
+	var latest time.Time
+	for date := range equity {
+		if date.After(latest) {
+			latest = date
+		}
+	}
+	shares := sharesOutstanding(stockData)
+	if shares <= 0 {
+		return fmt.Errorf("tangible book value: no share count to divide equity by")
+	}
+
+	intangibles, ok := response.series("quarterlyGoodwillAndOtherIntangibleAssets")[latest]
+	if !ok {
+		intangibles = response.series("quarterlyGoodwill")[latest] + response.series("quarterlyOtherIntangibleAssets")[latest]
+	}
+	stockData.TangibleBookValue = (equity[latest] - intangibles) / shares
+	return nil
+}
+
+// trailingSum returns the sum of the four latest quarters of a quarterly
+// series and the end of the latest one. The quarters must follow each
+// other without gaps.
+func trailingSum(series map[time.Time]float64) (float64, time.Time, error) {
+	if len(series) < ttmQuarters {
+		return 0, time.Time{}, fmt.Errorf("%d quarterly statements, %d are needed", len(series), ttmQuarters)
+	}
+	dates := make([]time.Time, 0, len(series))
+	for date := range series {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	dates = dates[len(dates)-ttmQuarters:]
+
+	var sum float64
+	for i, date := range dates {
+		if i > 0 && date.Sub(dates[i-1]) > maxQuarterGap {
+			return 0, time.Time{}, fmt.Errorf("no statement between %s and %s",
+				dates[i-1].Format(time.DateOnly), date.Format(time.DateOnly))
+		}
+		sum += series[date]
+	}
+	return sum, dates[len(dates)-1], nil
+}
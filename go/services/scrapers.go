@@ -0,0 +1,137 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// Names of the Yahoo Finance pages scraped for fundamentals, as reported
+// by CheckScrapers
+const (
+	ScraperYahooKeyStatistics = "yahoo_key_statistics"
+	ScraperYahooFinancials    = "yahoo_financials"
+	ScraperYahooProfile       = "yahoo_profile"
+)
+
+// ScrapeCheck is the outcome of running one scraping extractor against the
+// page of a known ticker
+type ScrapeCheck struct {
+	Source    string    `json:"source"`
+	Ticker    string    `json:"ticker"`
+	Fields    int       `json:"fields"`          // values the extractor found on the page
+	Error     string    `json:"error,omitempty"` // why the page could not be fetched
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Degraded reports whether the page was fetched but its extractor found
+// nothing on it, which is what a change in the site's layout looks like.
+// A page that could not be fetched says nothing about its extractor.
+func (c ScrapeCheck) Degraded() bool {
+	return c.Error == "" && c.Fields == 0
+}
+
+// DegradedScrapers returns the sources of the degraded checks
+func DegradedScrapers(checks []ScrapeCheck) []string {
+	var sources []string
+	for _, check := range checks {
+		if check.Degraded() {
+			sources = append(sources, check.Source)
+		}
+	}
+	return sources
+}
+
+// CheckScrapers runs the extractor of every enabled scraped page against
+// the pages of ticker, which should be a company every source covers, and
+// reports how many values each found. Growth sources are checked only
+// while the fetcher's own growth consensus is in use.
+func (df *DataFetcher) CheckScrapers(ctx context.Context, ticker string) []ScrapeCheck {
+	var checks []ScrapeCheck
+	if df.features.EnableScraping {
+		for _, page := range yahooPages {
+			var empty, stockData models.StockData
+			check := ScrapeCheck{Source: page.scraper, Ticker: ticker}
+			if err := df.scrapePage(ctx, page, ticker, &stockData); err != nil {
+				check.Error = err.Error()
+			}
+			stockData.StampChanged(&empty, df.clock.Now())
+			check.Fields = len(stockData.FieldTimes)
+			checks = append(checks, check)
+		}
+	}
+	if df.features.EnableGrowthConsensus && df.growth == GrowthSource(df.growthFetcher) {
+		checks = append(checks, df.growthFetcher.CheckScrapers(ctx, ticker)...)
+	}
+
+	// Checks are stamped on the fetcher's clock, which their reuse is
+	// measured against
+	now := df.clock.Now()
+	for i := range checks {
+		checks[i].CheckedAt = now
+	}
+	return checks
+}
+
+// SetDegradedScrapers leaves the growth sources among the named degraded
+// scrapers out of the growth consensus, so they no longer cost a request
+// per ticker for nothing. The Yahoo pages are still fetched, as they hold
+// data no other source has.
+func (df *DataFetcher) SetDegradedScrapers(sources []string) {
+	df.growthFetcher.SetDegraded(sources)
+}
+
+// LoadScrapeChecks reads the checks saved by SaveScrapeChecks. A missing
+// file holds no checks.
+func LoadScrapeChecks(path string) ([]ScrapeCheck, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scraper checks: %w", err)
+	}
+	defer file.Close()
+
+	var checks []ScrapeCheck
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var check ScrapeCheck
+		if err := json.Unmarshal(scanner.Bytes(), &check); err != nil {
+			return nil, fmt.Errorf("failed to read scraper checks %s: %w", path, err)
+		}
+		checks = append(checks, check)
+	}
+	return checks, scanner.Err()
+}
+
+// SaveScrapeChecks writes checks to path, one per line, replacing those
+// saved before
+func SaveScrapeChecks(path string, checks []ScrapeCheck) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for scraper checks: %w", err)
+	}
+
+	var data []byte
+	for _, check := range checks {
+		line, err := json.Marshal(check)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	// Write to a temporary file first so readers never see partial checks
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write scraper checks: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
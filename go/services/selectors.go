@@ -0,0 +1,196 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/symbols"
+)
+
+// PageSelectors locate a scraped page and the elements its extractor
+// reads values from
+type PageSelectors struct {
+	// URL is the page's address, in which {ticker} stands for the ticker
+	// in Yahoo notation (BRK-B), {ticker_dot} and {ticker_slash} for it in
+	// dot (BRK.B) and slash (BRK/B) notation, {ticker_lower} for it in
+	// lower case and {slug} for the company's Investing.com name where it
+	// is known, or the lower-case ticker
+	URL string `json:"url"`
+	// Selectors are CSS selectors by the name the extractor knows them by
+	Selectors map[string]string `json:"selectors,omitempty"`
+}
+
+// Selectors hold the pages of every scraper, by the scraper names
+// CheckScrapers reports
+type Selectors map[string]PageSelectors
+
+// investingSlugs are the Investing.com names of companies whose page is
+// not named after their ticker
+var investingSlugs = map[string]string{
+	"AAPL":  "apple-computer-inc",
+	"MSFT":  "microsoft-corp",
+	"GOOGL": "google-inc",
+	"AMZN":  "amazon-com-inc",
+	"NVDA":  "nvidia-corp",
+	"META":  "meta-platforms-inc",
+	"TSLA":  "tesla-motors",
+}
+
+// DefaultSelectors returns the pages and selectors the scrapers are built
+// with
+func DefaultSelectors() Selectors {
+	return Selectors{
+		ScraperYahooKeyStatistics: {
+			URL: "https://finance.yahoo.com/quote/{ticker}/key-statistics/",
+			Selectors: map[string]string{
+				"tables": "table", "rows": "tr", "cells": "td", "scripts": "script",
+			},
+		},
+		ScraperYahooFinancials: {
+			URL: "https://finance.yahoo.com/quote/{ticker}/financials/",
+			Selectors: map[string]string{
+				"rows": "div[data-test='fin-row']", "cells": "div[data-test='fin-col']", "scripts": "script",
+			},
+		},
+		ScraperYahooProfile: {
+			URL: "https://finance.yahoo.com/quote/{ticker}/profile/",
+			Selectors: map[string]string{
+				"sector":      "span[data-test='SECTOR']",
+				"industry":    "span[data-test='INDUSTRY']",
+				"name":        "h1[data-test='qsp-overview-entity-name']",
+				"description": "p",
+				"scripts":     "script",
+			},
+		},
+		"yahoo_finance": {
+			URL: "https://finance.yahoo.com/quote/{ticker}/analysis/",
+			Selectors: map[string]string{
+				"text": "table, div, span", "tables": "table", "rows": "tr", "cells": "td", "scripts": "script",
+			},
+		},
+		"marketwatch": {
+			URL: "https://www.marketwatch.com/investing/stock/{ticker_dot}/analystestimates",
+			Selectors: map[string]string{
+				"tables": "table", "rows": "tr", "cells": "td",
+			},
+		},
+		"seeking_alpha": {
+			URL:       "https://seekingalpha.com/symbol/{ticker_dot}",
+			Selectors: map[string]string{"text": "div, span, td"},
+		},
+		"finviz": {
+			URL: "https://finviz.com/quote.ashx?t={ticker}",
+			Selectors: map[string]string{
+				"tables": "table", "cells": "td", "snapshot": "table.snapshot-table2", "rows": "tr",
+			},
+		},
+		"tipranks": {
+			URL: "https://www.tipranks.com/stocks/{ticker_dot}/forecast",
+			Selectors: map[string]string{
+				"text": "div, span, td", "tables": "table", "rows": "tr", "cells": "td", "scripts": "script",
+			},
+		},
+		"investing": {
+			URL: "https://www.investing.com/equities/{slug}-earnings",
+			Selectors: map[string]string{
+				"tables": "table", "rows": "tr", "cells": "td, th", "text": "div, span",
+			},
+		},
+		"zacks": {
+			URL:       "https://www.zacks.com/stock/quote/{ticker_dot}",
+			Selectors: map[string]string{"growth": ".rank_view .zr_ranktext, .composite_val, [data-test='growth-rate']"},
+		},
+		"morningstar": {
+			URL:       "https://www.morningstar.com/stocks/xnas/{ticker_dot}/quote",
+			Selectors: map[string]string{"growth": ".dp-value, [data-test='growth-forecast'], .sal-component-cta-link"},
+		},
+		"reuters": {
+			URL:       "https://www.reuters.com/markets/companies/{ticker_dot}.O",
+			Selectors: map[string]string{"growth": ".forecast-data, .analyst-estimates, [data-testid='growth-rate']"},
+		},
+		"bloomberg": {
+			URL:       "https://www.bloomberg.com/quote/{ticker_slash}:US",
+			Selectors: map[string]string{"growth": ".data-table-row, [data-module='Estimates'], .analyst-forecast"},
+		},
+	}
+}
+
+// LoadSelectors reads pages and selectors from a JSON file shaped like
+// DefaultSelectors, over the defaults: a page's URL replaces the default
+// when set, and its selectors replace the defaults of the same name, so
+// the file only needs what changed. Unknown scrapers and selector names
+// are rejected, as they would never be used.
+func LoadSelectors(path string) (Selectors, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selectors: %w", err)
+	}
+	var overrides Selectors
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse selectors %s: %w", path, err)
+	}
+
+	selectors := DefaultSelectors()
+	for name, override := range overrides {
+		page, ok := selectors[name]
+		if !ok {
+			return nil, fmt.Errorf("selectors %s: unknown scraper %q", path, name)
+		}
+		if override.URL != "" {
+			if !strings.HasPrefix(override.URL, "https://") && !strings.HasPrefix(override.URL, "http://") {
+				return nil, fmt.Errorf("selectors %s: URL of %s is not absolute", path, name)
+			}
+			page.URL = override.URL
+		}
+		for key, selector := range override.Selectors {
+			if _, ok := page.Selectors[key]; !ok {
+				return nil, fmt.Errorf("selectors %s: %s has no selector %q", path, name, key)
+			}
+			if strings.TrimSpace(selector) == "" {
+				return nil, fmt.Errorf("selectors %s: selector %s of %s is empty", path, key, name)
+			}
+			page.Selectors[key] = selector
+		}
+		selectors[name] = page
+	}
+	return selectors, nil
+}
+
+// Page returns the page of the named scraper
+func (s Selectors) Page(name string) PageSelectors {
+	return s[name]
+}
+
+// PageURL returns the page's address for ticker
+func (p PageSelectors) PageURL(ticker string) string {
+	slug, ok := investingSlugs[ticker]
+	if !ok {
+		slug = strings.ToLower(ticker)
+	}
+	return strings.NewReplacer(
+		"{ticker}", ticker,
+		"{ticker_dot}", symbols.Format(ticker, symbols.Dot),
+		"{ticker_slash}", symbols.Format(ticker, symbols.Slash),
+		"{ticker_lower}", strings.ToLower(ticker),
+		"{slug}", slug,
+	).Replace(p.URL)
+}
+
+// Selector returns the named selector
+func (p PageSelectors) Selector(name string) string {
+	return p.Selectors[name]
+}
+
+// Alternatives returns the parts of the named selector group, which are
+// tried in order
+func (p PageSelectors) Alternatives(name string) []string {
+	var parts []string
+	for _, part := range strings.Split(p.Selectors[name], ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
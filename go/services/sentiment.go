@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// SentimentSource scores the tone of recent news about companies
+type SentimentSource interface {
+	// FetchSentiment returns the news sentiment of ticker, wrapping
+	// ErrSymbolNotFound when there is no recent news to score
+	FetchSentiment(ctx context.Context, ticker string) (*models.Sentiment, error)
+}
+
+const finnhubSentimentURL = "https://finnhub.io/api/v1/news-sentiment"
+
+// Finnhub scores news sentiment with the Finnhub news sentiment API, which
+// requires an API key
+type Finnhub struct {
+	httpClient *http.Client
+	apiKey     string
+	clock      Clock
+}
+
+// NewFinnhub creates a sentiment source authenticated with apiKey
+func NewFinnhub(apiKey string) *Finnhub {
+	return &Finnhub{
+		httpClient: newTracingClient(&http.Client{
+			Timeout:   30 * time.Second,
+			Transport: sharedTransport,
+		}),
+		apiKey: apiKey,
+		clock:  SystemClock,
+	}
+}
+
+// SetClock sets the clock scores are stamped with
+func (f *Finnhub) SetClock(clock Clock) {
+	f.clock = clock
+}
+
+// finnhubSentiment is the part of a news sentiment response that is used
+type finnhubSentiment struct {
+	Buzz struct {
+		ArticlesInLastWeek int `json:"articlesInLastWeek"`
+	} `json:"buzz"`
+	Sentiment struct {
+		BearishPercent float64 `json:"bearishPercent"`
+		BullishPercent float64 `json:"bullishPercent"`
+	} `json:"sentiment"`
+}
+
+// FetchSentiment returns the share of last week's articles about ticker
+// that Finnhub rates bullish less the share it rates bearish
+func (f *Finnhub) FetchSentiment(ctx context.Context, ticker string) (*models.Sentiment, error) {
+	response, err := withRetry(ctx, func() (*finnhubSentiment, error) {
+		return f.request(ctx, ticker)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the news sentiment of %s: %w", ticker, err)
+	}
+	if response.Buzz.ArticlesInLastWeek == 0 && response.Sentiment.BullishPercent == 0 && response.Sentiment.BearishPercent == 0 {
+		return nil, fmt.Errorf("no recent news about %s: %w", ticker, ErrSymbolNotFound)
+	}
+	return &models.Sentiment{
+		Source:    "finnhub",
+		Score:     response.Sentiment.BullishPercent - response.Sentiment.BearishPercent,
+		Articles:  response.Buzz.ArticlesInLastWeek,
+		FetchTime: f.clock.Now(),
+	}, nil
+}
+
+// request sends one news sentiment request. The API key goes in a header
+// rather than the URL, so it cannot leak into error messages.
+func (f *Finnhub) request(ctx context.Context, ticker string) (*finnhubSentiment, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		finnhubSentimentURL+"?symbol="+url.QueryEscape(ticker), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Finnhub-Token", f.apiKey)
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, requestError(req, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, requestError(req, fmt.Errorf("failed to read response body: %w", err))
+	}
+	var response finnhubSentiment
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, parseError(req.URL.Hostname(), fmt.Errorf("failed to parse JSON response: %w", err))
+	}
+	return &response, nil
+}
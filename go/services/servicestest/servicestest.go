@@ -0,0 +1,317 @@
+// Package servicestest provides in-memory stand-ins for the data sources of
+// package services, so programs embedding the valuation engine can run the
+// whole pipeline in tests without network access:
+//
+//	cfg := config.NewDefaultConfig()
+//	cfg.Processing.EnableCaching = false
+//	analyzer, err := fairvalue.New(cfg,
+//		fairvalue.WithStockDataProvider(servicestest.NewProvider(servicestest.Stocks()...)))
+//	if err != nil {
+//		return err
+//	}
+//	run, err := analyzer.Analyze(ctx, servicestest.Tickers())
+package servicestest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/services"
+)
+
+// Provider is a services.StockDataProvider serving canned stock data from
+// memory. It is safe for concurrent use, and may be changed while in use.
+type Provider struct {
+	mu       sync.Mutex
+	stocks   map[string]*models.StockData
+	errs     map[string]error
+	delay    time.Duration
+	requests map[string]int
+	splits   map[string][]models.Split
+	market   map[string]float64 // benchmark P/E by symbol
+	shares   map[string][]models.ShareCount
+}
+
+// NewProvider returns a provider serving stocks
+func NewProvider(stocks ...*models.StockData) *Provider {
+	p := &Provider{
+		stocks:   make(map[string]*models.StockData),
+		errs:     make(map[string]error),
+		requests: make(map[string]int),
+		splits:   make(map[string][]models.Split),
+		market:   make(map[string]float64),
+		shares:   make(map[string][]models.ShareCount),
+	}
+	for _, stock := range stocks {
+		p.Set(stock)
+	}
+	return p
+}
+
+// Set serves a copy of stock for its ticker, replacing any earlier data or
+// error
+func (p *Provider) Set(stock *models.StockData) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stocks[stock.Ticker] = stock.Snapshot()
+	delete(p.errs, stock.Ticker)
+}
+
+// SetPrice changes the price served for ticker, as a price refresh would
+// see it. It has no effect on tickers without data.
+func (p *Provider) SetPrice(ticker string, price float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if stock, ok := p.stocks[ticker]; ok {
+		stock.CurrentPrice = price
+	}
+}
+
+// SetSplits makes ticker report splits, oldest first, as a stock that
+// split after its data was fetched would
+func (p *Provider) SetSplits(ticker string, splits ...models.Split) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.splits[ticker] = splits
+}
+
+// SetBenchmark makes the benchmark symbol trade at a trailing P/E of
+// peRatio
+func (p *Provider) SetBenchmark(symbol string, peRatio float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.market[symbol] = peRatio
+}
+
+// SetShareCounts makes ticker report the yearly share counts, oldest first
+func (p *Provider) SetShareCounts(ticker string, counts ...models.ShareCount) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.shares[ticker] = counts
+}
+
+// SetError makes requests for ticker fail with err. Wrap one of the
+// services.Err* causes to test how failures are classified.
+func (p *Provider) SetError(ticker string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errs[ticker] = err
+}
+
+// SetDelay makes every request take d, or until its context is done, to
+// test timeouts and cancellation
+func (p *Provider) SetDelay(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.delay = d
+}
+
+// Requests returns how many times data or a price was requested for ticker
+func (p *Provider) Requests(ticker string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.requests[ticker]
+}
+
+// FetchStockData returns a copy of the data served for ticker. Tickers
+// without data fail with services.ErrSymbolNotFound.
+func (p *Provider) FetchStockData(ctx context.Context, ticker string) (*models.StockData, error) {
+	return p.lookup(ctx, ticker)
+}
+
+// FetchPrice returns the price served for ticker
+func (p *Provider) FetchPrice(ctx context.Context, ticker string) (float64, error) {
+	stock, err := p.lookup(ctx, ticker)
+	if err != nil {
+		return 0, err
+	}
+	return stock.CurrentPrice, nil
+}
+
+// RecentSplits returns the splits set for ticker after since
+func (p *Provider) RecentSplits(ctx context.Context, ticker string, since time.Time) ([]models.Split, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var splits []models.Split
+	for _, split := range p.splits[ticker] {
+		if split.Date.After(since) {
+			splits = append(splits, split)
+		}
+	}
+	return splits, nil
+}
+
+// FetchBenchmark returns the P/E set for the benchmark symbol. Symbols
+// without one fail with services.ErrSymbolNotFound.
+func (p *Provider) FetchBenchmark(ctx context.Context, symbol string) (*models.Benchmark, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	peRatio, ok := p.market[symbol]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", symbol, services.ErrSymbolNotFound)
+	}
+	return models.NewBenchmark(symbol, peRatio, time.Now()), nil
+}
+
+// ShareCounts returns the share counts set for ticker. Tickers without
+// any fail with services.ErrSymbolNotFound.
+func (p *Provider) ShareCounts(ctx context.Context, ticker string) ([]models.ShareCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	counts, ok := p.shares[ticker]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", ticker, services.ErrSymbolNotFound)
+	}
+	return slices.Clone(counts), nil
+}
+
+// lookup counts a request for ticker and returns a copy of its data once
+// the delay has passed
+func (p *Provider) lookup(ctx context.Context, ticker string) (*models.StockData, error) {
+	p.mu.Lock()
+	p.requests[ticker]++
+	delay := p.delay
+	p.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s: %w", ticker, ctx.Err())
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ticker, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err, ok := p.errs[ticker]; ok {
+		return nil, err
+	}
+	stock, ok := p.stocks[ticker]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", ticker, services.ErrSymbolNotFound)
+	}
+	return stock.Snapshot(), nil
+}
+
+// GrowthSource is a services.GrowthSource serving canned growth rates. It
+// is safe for concurrent use.
+type GrowthSource struct {
+	mu    sync.Mutex
+	rates map[string]float64
+	errs  map[string]error
+}
+
+// NewGrowthSource returns a source serving rates, keyed by ticker
+func NewGrowthSource(rates map[string]float64) *GrowthSource {
+	g := &GrowthSource{rates: make(map[string]float64), errs: make(map[string]error)}
+	for ticker, rate := range rates {
+		g.rates[ticker] = rate
+	}
+	return g
+}
+
+// Set serves rate for ticker
+func (g *GrowthSource) Set(ticker string, rate float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.rates[ticker] = rate
+	delete(g.errs, ticker)
+}
+
+// SetError makes requests for ticker fail with err
+func (g *GrowthSource) SetError(ticker string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.errs[ticker] = err
+}
+
+// FetchGrowthRateConsensus returns the rate served for ticker. Tickers
+// without a rate fail with services.ErrSymbolNotFound, which leaves the
+// data fetcher's default growth rate in place.
+func (g *GrowthSource) FetchGrowthRateConsensus(ctx context.Context, ticker string) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err, ok := g.errs[ticker]; ok {
+		return 0, err
+	}
+	rate, ok := g.rates[ticker]
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", ticker, services.ErrSymbolNotFound)
+	}
+	return rate, nil
+}
+
+// SentimentSource is a services.SentimentSource serving canned news
+// sentiment scores. It is safe for concurrent use.
+type SentimentSource struct {
+	mu     sync.Mutex
+	scores map[string]float64
+}
+
+// NewSentimentSource returns a source serving scores, from -1 to 1, keyed
+// by ticker
+func NewSentimentSource(scores map[string]float64) *SentimentSource {
+	s := &SentimentSource{scores: make(map[string]float64)}
+	for ticker, score := range scores {
+		s.scores[ticker] = score
+	}
+	return s
+}
+
+// Set serves score for ticker
+func (s *SentimentSource) Set(ticker string, score float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scores[ticker] = score
+}
+
+// FetchSentiment returns the score served for ticker. Tickers without a
+// score fail with services.ErrSymbolNotFound, as stocks without recent
+// news do.
+func (s *SentimentSource) FetchSentiment(ctx context.Context, ticker string) (*models.Sentiment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	score, ok := s.scores[ticker]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", ticker, services.ErrSymbolNotFound)
+	}
+	return &models.Sentiment{Source: "servicestest", Score: score}, nil
+}
+
+var (
+	_ services.StockDataProvider = (*Provider)(nil)
+	_ services.GrowthSource      = (*GrowthSource)(nil)
+	_ services.SplitSource       = (*Provider)(nil)
+	_ services.BenchmarkSource   = (*Provider)(nil)
+	_ services.ShareCountSource  = (*Provider)(nil)
+	_ services.SentimentSource   = (*SentimentSource)(nil)
+)
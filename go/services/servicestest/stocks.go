@@ -0,0 +1,46 @@
+package servicestest
+
+import (
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// FetchTime is the time the canned stocks were fetched at
+var FetchTime = time.Date(2025, time.January, 2, 21, 0, 0, 0, time.UTC)
+
+// cannedStocks are plausible, complete fundamentals of well-known stocks,
+// one per sector the valuation treats differently
+var cannedStocks = []models.StockData{
+	{Ticker: "AAPL", CompanyName: "Apple Inc.", CurrentPrice: 243.85, FCFPerShare: 7.12, EPS: 6.08, BookValue: 3.77, Sector: "Technology", GrowthRate: 0.08, PERatio: 40.1, MarketCap: 3_686_000_000_000, SharesOutstanding: 15_116_000_000, DividendPerShare: 1.00, Beta: 1.24, TotalDebt: 106_630_000_000, Cash: 65_170_000_000, EBITDA: 134_660_000_000, Revenue: 391_040_000_000, TangibleBookValue: 3.77, MovingAverage50: 233.10, MovingAverage200: 213.40, Currency: "USD"},
+	{Ticker: "MSFT", CompanyName: "Microsoft Corporation", CurrentPrice: 418.58, FCFPerShare: 9.51, EPS: 12.41, BookValue: 38.69, Sector: "Technology", GrowthRate: 0.10, PERatio: 33.7, MarketCap: 3_112_000_000_000, SharesOutstanding: 7_434_000_000, DividendPerShare: 3.32, Beta: 0.90, TotalDebt: 97_850_000_000, Cash: 78_430_000_000, EBITDA: 144_550_000_000, Revenue: 261_800_000_000, TangibleBookValue: 23.19, MovingAverage50: 425.90, MovingAverage200: 424.70, Currency: "USD"},
+	{Ticker: "JPM", CompanyName: "JPMorgan Chase & Co.", CurrentPrice: 239.71, FCFPerShare: 0, EPS: 19.75, BookValue: 116.07, Sector: "Financial Services", GrowthRate: 0.05, PERatio: 12.1, MarketCap: 674_900_000_000, SharesOutstanding: 2_815_000_000, DividendPerShare: 5.00, Beta: 1.09, TotalDebt: 0, Cash: 0, EBITDA: 0, Revenue: 166_770_000_000, TangibleBookValue: 97.30, MovingAverage50: 236.20, MovingAverage200: 207.90, Currency: "USD"},
+	{Ticker: "JNJ", CompanyName: "Johnson & Johnson", CurrentPrice: 144.62, FCFPerShare: 8.05, EPS: 5.79, BookValue: 28.55, Sector: "Healthcare", GrowthRate: 0.04, PERatio: 25.0, MarketCap: 348_200_000_000, SharesOutstanding: 2_408_000_000, DividendPerShare: 4.96, Beta: 0.51, TotalDebt: 36_590_000_000, Cash: 20_220_000_000, EBITDA: 28_470_000_000, Revenue: 88_820_000_000, TangibleBookValue: 0, MovingAverage50: 150.30, MovingAverage200: 155.20, Currency: "USD"},
+	{Ticker: "XOM", CompanyName: "Exxon Mobil Corporation", CurrentPrice: 107.57, FCFPerShare: 7.28, EPS: 7.84, BookValue: 61.28, Sector: "Energy", GrowthRate: 0.03, PERatio: 13.7, MarketCap: 472_800_000_000, SharesOutstanding: 4_395_000_000, DividendPerShare: 3.96, Beta: 0.88, TotalDebt: 41_710_000_000, Cash: 27_450_000_000, EBITDA: 72_300_000_000, Revenue: 343_820_000_000, TangibleBookValue: 61.28, MovingAverage50: 111.40, MovingAverage200: 113.90, Currency: "USD"},
+	{Ticker: "PG", CompanyName: "The Procter & Gamble Company", CurrentPrice: 167.71, FCFPerShare: 6.43, EPS: 6.55, BookValue: 21.05, Sector: "Consumer Defensive", GrowthRate: 0.05, PERatio: 25.6, MarketCap: 394_900_000_000, SharesOutstanding: 2_355_000_000, DividendPerShare: 4.03, Beta: 0.42, TotalDebt: 34_110_000_000, Cash: 9_480_000_000, EBITDA: 22_280_000_000, Revenue: 84_350_000_000, TangibleBookValue: 0, MovingAverage50: 170.60, MovingAverage200: 166.20, Currency: "USD"},
+	{Ticker: "TSLA", CompanyName: "Tesla Inc.", CurrentPrice: 379.28, FCFPerShare: 1.12, EPS: 2.23, BookValue: 22.68, Sector: "Consumer Cyclical", GrowthRate: 0.20, PERatio: 170.1, MarketCap: 1_217_000_000_000, SharesOutstanding: 3_208_000_000, DividendPerShare: 0, Beta: 2.30, TotalDebt: 13_620_000_000, Cash: 33_650_000_000, EBITDA: 13_240_000_000, Revenue: 97_150_000_000, TangibleBookValue: 22.49, MovingAverage50: 349.50, MovingAverage200: 246.30, Currency: "USD"},
+	{Ticker: "NEE", CompanyName: "NextEra Energy, Inc.", CurrentPrice: 71.69, FCFPerShare: 0, EPS: 3.37, BookValue: 24.40, Sector: "Utilities", GrowthRate: 0.06, PERatio: 21.3, MarketCap: 147_400_000_000, SharesOutstanding: 2_056_000_000, DividendPerShare: 2.06, Beta: 0.57, TotalDebt: 80_420_000_000, Cash: 1_800_000_000, EBITDA: 15_930_000_000, Revenue: 24_750_000_000, TangibleBookValue: 22.30, MovingAverage50: 74.10, MovingAverage200: 74.90, Currency: "USD"},
+}
+
+// Stocks returns fresh copies of the canned stocks, fetched at FetchTime.
+// They cover technology, financials, healthcare, energy, consumer and
+// utility stocks, including ones without free cash flow or tangible book
+// value.
+func Stocks() []*models.StockData {
+	stocks := make([]*models.StockData, len(cannedStocks))
+	for i := range cannedStocks {
+		stock := cannedStocks[i]
+		stock.FetchTime = FetchTime
+		stocks[i] = &stock
+	}
+	return stocks
+}
+
+// Tickers returns the tickers of the canned stocks, in the order of Stocks
+func Tickers() []string {
+	tickers := make([]string, len(cannedStocks))
+	for i, stock := range cannedStocks {
+		tickers[i] = stock.Ticker
+	}
+	return tickers
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// SplitSource is implemented by providers that know the stock splits of a
+// ticker, so per-share figures fetched before a split can be brought in
+// line with the price after it
+type SplitSource interface {
+	// RecentSplits returns the splits of ticker after since, oldest first
+	RecentSplits(ctx context.Context, ticker string, since time.Time) ([]models.Split, error)
+}
+
+// YahooSplitEvent is a split in the events of a chart response, such as
+// 10 for 1 with a numerator of 10 and a denominator of 1
+type YahooSplitEvent struct {
+	Date        int64   `json:"date"`
+	Numerator   float64 `json:"numerator"`
+	Denominator float64 `json:"denominator"`
+}
+
+// fallbackDataAsOf is when the built-in fallback fundamentals were current.
+// Their per-share figures predate any split since.
+var fallbackDataAsOf = time.Date(2023, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+// splitMemoTTL is how long the splits of a ticker are remembered, since a
+// price refresh asks for them every time
+const splitMemoTTL = time.Hour
+
+// splitLookup is a remembered answer to RecentSplits
+type splitLookup struct {
+	since   time.Time
+	fetched time.Time
+	splits  []models.Split
+}
+
+// RecentSplits returns the splits of ticker after since from the events of
+// the chart API, oldest first. Answers are remembered for an hour.
+func (df *DataFetcher) RecentSplits(ctx context.Context, ticker string, since time.Time) ([]models.Split, error) {
+	now := df.clock.Now()
+
+	df.splitsMutex.Lock()
+	lookup, ok := df.splits[ticker]
+	df.splitsMutex.Unlock()
+
+	if !ok || now.Sub(lookup.fetched) > splitMemoTTL || since.Before(lookup.since) {
+		query := url.Values{
+			"period1":  {strconv.FormatInt(since.Unix(), 10)},
+			"period2":  {strconv.FormatInt(now.Unix(), 10)},
+			"interval": {"1mo"},
+			"events":   {"split"},
+		}
+		chartResp, err := df.fetchChart(ctx, ticker, query)
+		if err != nil {
+			return nil, err
+		}
+		lookup = splitLookup{since: since, fetched: now, splits: chartSplits(chartResp)}
+
+		df.splitsMutex.Lock()
+		df.splits[ticker] = lookup
+		df.splitsMutex.Unlock()
+	}
+
+	var splits []models.Split
+	for _, split := range lookup.splits {
+		if split.Date.After(since) {
+			splits = append(splits, split)
+		}
+	}
+	return splits, nil
+}
+
+// chartSplits returns the splits in the events of a chart response, oldest
+// first
+func chartSplits(chartResp *YahooChartResponse) []models.Split {
+	var splits []models.Split
+	for _, event := range chartResp.Chart.Result[0].Events.Splits {
+		if event.Numerator <= 0 || event.Denominator <= 0 {
+			continue
+		}
+		splits = append(splits, models.Split{
+			Date:  time.Unix(event.Date, 0).UTC(),
+			Ratio: event.Numerator / event.Denominator,
+		})
+	}
+	sort.Slice(splits, func(i, j int) bool {
+		return splits[i].Date.Before(splits[j].Date)
+	})
+	return splits
+}
+
+// adjustFallbackForSplits brings the fallback figures of ticker in line
+// with its current price by applying the splits since they were current.
+// It returns the factor the share count grew by, 1 without splits.
+func (df *DataFetcher) adjustFallbackForSplits(ctx context.Context, ticker string, stockData *models.StockData) float64 {
+	if _, ok := df.getFallbackStockData()[ticker]; !ok {
+		return 1
+	}
+	splits, err := df.RecentSplits(ctx, ticker, fallbackDataAsOf)
+	if err != nil {
+		df.logger.Printf("Warning: could not check %s for splits, fallback per-share figures may predate one: %v\n", ticker, err)
+		return 1
+	}
+	ratio := 1.0
+	for _, split := range splits {
+		if stockData.AdjustForSplit(split, df.clock.Now()) {
+			ratio *= split.Ratio
+			df.logger.Printf("Adjusted fallback data for %s for its %s split\n", ticker, split)
+		}
+	}
+	return ratio
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"encoding/csv"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// tickerColumnNames maps the header names of ticker file columns to the
+// hints they hold. Other columns, such as a company name, are ignored.
+var tickerColumnNames = map[string]string{
+	"ticker":      "ticker",
+	"symbol":      "ticker",
+	"isin":        "identifier",
+	"cusip":       "identifier",
+	"exchange":    "exchange",
+	"country":     "country",
+	"sector":      "sector",
+	"sector_hint": "sector",
+	"tag":         "tag",
+}
+
+// tickerColumns are the positions of the columns of a ticker file by the
+// hint they hold
+type tickerColumns map[string]int
+
+// headerColumns returns the columns named by record, and whether it is a
+// header at all: a header names the ticker or an identifier column
+func headerColumns(record []string) (tickerColumns, bool) {
+	columns := make(tickerColumns)
+	for i, name := range record {
+		hint, ok := tickerColumnNames[strings.ToLower(strings.TrimSpace(name))]
+		if _, seen := columns[hint]; ok && !seen {
+			columns[hint] = i
+		}
+	}
+	_, ticker := columns["ticker"]
+	_, identifier := columns["identifier"]
+	return columns, ticker || identifier
+}
+
+// info returns the ticker and hints of record. Rows without a ticker take
+// their ISIN or CUSIP in its place, for the analyzer to resolve.
+func (c tickerColumns) info(record []string) models.TickerInfo {
+	cell := func(hint string) string {
+		i, ok := c[hint]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	ticker := cell("ticker")
+	if ticker == "" {
+		ticker = cell("identifier")
+	}
+	return models.TickerInfo{
+		Ticker:   ticker,
+		Exchange: cell("exchange"),
+		Country:  cell("country"),
+		Sector:   cell("sector"),
+		Tag:      cell("tag"),
+	}
+}
+
+// TickerReader reads ticker symbols one at a time from a CSV file, so a
+// universe of any size can be valued without loading it first. A header
+// row naming a ticker (or symbol) column places the columns in any order,
+// with optional exchange, country, sector and tag columns alongside. An
+// isin or cusip column may stand in for the ticker column.
+// Without a header, the first column holds the symbol.
+type TickerReader struct {
+	file    *os.File
+	reader  *csv.Reader
+	columns tickerColumns
+	pending []string // first record of a file without a header
+	err     error
+}
+
+// OpenTickerFile opens filename and reads its header row, if it has one
+func OpenTickerFile(filename string) (*TickerReader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	first, err := reader.Read()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r := &TickerReader{file: file, reader: reader}
+	var header bool
+	if r.columns, header = headerColumns(first); !header {
+		r.columns = tickerColumns{"ticker": 0}
+		r.pending = slices.Clone(first)
+	}
+	return r, nil
+}
+
+// Next returns the next ticker, or io.EOF after the last one
+func (r *TickerReader) Next() (string, error) {
+	info, err := r.NextInfo()
+	return info.Ticker, err
+}
+
+// NextInfo returns the next ticker with its hints, or io.EOF after the
+// last one
+func (r *TickerReader) NextInfo() (models.TickerInfo, error) {
+	for {
+		record := r.pending
+		r.pending = nil
+		if record == nil {
+			var err error
+			if record, err = r.reader.Read(); err != nil {
+				return models.TickerInfo{}, err
+			}
+		}
+		if info := r.columns.info(record); info.Ticker != "" {
+			return info, nil
+		}
+	}
+}
+
+// All returns the remaining tickers as a sequence. It stops at the first
+// read error, which Err then returns.
+func (r *TickerReader) All() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for info := range r.AllInfo() {
+			if !yield(info.Ticker) {
+				return
+			}
+		}
+	}
+}
+
+// AllInfo returns the remaining tickers with their hints as a sequence,
+// stopping like All
+func (r *TickerReader) AllInfo() iter.Seq[models.TickerInfo] {
+	return func(yield func(models.TickerInfo) bool) {
+		for {
+			info, err := r.NextInfo()
+			if err != nil {
+				if err != io.EOF {
+					r.err = err
+				}
+				return
+			}
+			if !yield(info) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the error that stopped All, if any
+func (r *TickerReader) Err() error {
+	return r.err
+}
+
+// Close closes the file
+func (r *TickerReader) Close() error {
+	return r.file.Close()
+}
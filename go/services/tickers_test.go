@@ -0,0 +1,37 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func BenchmarkReadTickers(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "tickers.csv")
+	file, err := os.Create(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	fmt.Fprintln(file, "Ticker,Company")
+	for i := range 5000 {
+		fmt.Fprintf(file, "T%04d,Company %d\n", i, i)
+	}
+	if err := file.Close(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		reader, err := OpenTickerFile(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for range reader.All() {
+		}
+		reader.Close()
+		if err := reader.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// sharedTransport carries the requests of every fetcher that is not given
+// a transport of its own, so workers reuse each other's connections
+var sharedTransport = NewTransport()
+
+// NewTransport returns an HTTP transport tuned for many workers sending
+// requests to the same few hosts: it keeps enough idle connections per
+// host for every worker and negotiates HTTP/2 where the host supports it
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          256,
+		MaxIdleConnsPerHost:   32,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// setClientTransport replaces the transport under client's tracing and
+// request observer with base
+func setClientTransport(client *http.Client, base http.RoundTripper) {
+	if base == nil {
+		base = sharedTransport
+	}
+	if t, ok := client.Transport.(observingTransport); ok {
+		client.Transport = observingTransport{base: tracingTransport{base: base}, observer: t.observer}
+		return
+	}
+	client.Transport = tracingTransport{base: base}
+}
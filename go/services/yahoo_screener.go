@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// SourceYahooScreener is the source of Yahoo Finance screener requests, as
+// reported to a RequestObserver
+const SourceYahooScreener = "yahoo_screener"
+
+// screenerPageSize is the number of matches requested at a time, the most
+// the screener API answers with
+const screenerPageSize = 250
+
+// maxScreenerMatches bounds the matches read from a screener without a
+// limit, so a screener matching the whole market does not page forever
+const maxScreenerMatches = 10000
+
+// yahooScreenerResponse is the response of the saved screener API
+type yahooScreenerResponse struct {
+	Finance struct {
+		Result []struct {
+			Total  int `json:"total"`
+			Quotes []struct {
+				Symbol           string `json:"symbol"`
+				FullExchangeName string `json:"fullExchangeName"`
+			} `json:"quotes"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"finance"`
+}
+
+// ScreenerID returns the ID of the screener given by its ID or by the
+// address of its Yahoo Finance page, whose last path segment is the ID
+func ScreenerID(screener string) (string, error) {
+	screener = strings.TrimSpace(screener)
+	if strings.HasPrefix(screener, "https://") || strings.HasPrefix(screener, "http://") {
+		u, err := url.Parse(screener)
+		if err != nil {
+			return "", fmt.Errorf("invalid screener URL: %w", err)
+		}
+		if id := u.Query().Get("scrIds"); id != "" {
+			return id, nil
+		}
+		screener = path.Base(strings.TrimSuffix(u.Path, "/"))
+	}
+	if screener == "" || screener == "." || screener == "/" || strings.ContainsAny(screener, " /?&") {
+		return "", fmt.Errorf("invalid screener %q", screener)
+	}
+	return screener, nil
+}
+
+// ScreenerTickers runs a saved Yahoo Finance screener, given by its ID or
+// the address of its page, and returns the tickers it matches in its own
+// order, at most limit of them when limit is positive. Predefined
+// screeners such as "undervalued_large_caps" need no account; screeners
+// saved by a user need the cookies of a signed-in session, which the hosts
+// configuration sends along.
+func (df *DataFetcher) ScreenerTickers(ctx context.Context, screener string, limit int) ([]models.TickerInfo, error) {
+	if !df.features.EnableYahooAPI {
+		return nil, fmt.Errorf("screeners require the Yahoo Finance API to be enabled")
+	}
+	id, err := ScreenerID(screener)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxScreenerMatches {
+		limit = maxScreenerMatches
+	}
+
+	var tickers []models.TickerInfo
+	for start := 0; len(tickers) < limit; {
+		count := min(screenerPageSize, limit-len(tickers))
+		response, err := withRetry(ctx, func() (*yahooScreenerResponse, error) {
+			return df.fetchScreenerOnce(ctx, id, start, count)
+		})
+		if err != nil {
+			return nil, err
+		}
+		result := response.Finance.Result[0]
+		for _, quote := range result.Quotes {
+			if quote.Symbol != "" {
+				tickers = append(tickers, models.TickerInfo{Ticker: quote.Symbol, Exchange: quote.FullExchangeName})
+			}
+		}
+		start += len(result.Quotes)
+		if len(result.Quotes) == 0 || start >= result.Total {
+			break
+		}
+	}
+	if len(tickers) > limit {
+		tickers = tickers[:limit]
+	}
+	return tickers, nil
+}
+
+// fetchScreenerOnce makes a single request for count matches of the
+// screener from start on
+func (df *DataFetcher) fetchScreenerOnce(ctx context.Context, id string, start, count int) (*yahooScreenerResponse, error) {
+	u := &url.URL{
+		Scheme: "https",
+		Host:   "query1.finance.yahoo.com",
+		Path:   "/v1/finance/screener/predefined/saved",
+		RawQuery: url.Values{
+			"scrIds": {id},
+			"start":  {strconv.Itoa(start)},
+			"count":  {strconv.Itoa(count)},
+		}.Encode(),
+	}
+	req, err := http.NewRequestWithContext(withSource(ctx, SourceYahooScreener), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := df.yahooSession.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, requestError(req, fmt.Errorf("failed to read response body: %w", err))
+	}
+	var response yahooScreenerResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, parseError(u.Hostname(), fmt.Errorf("failed to parse JSON response: %w", err))
+	}
+	if len(response.Finance.Result) == 0 {
+		err := fmt.Errorf("no screener %s found", id)
+		if response.Finance.Error != nil {
+			err = errors.New(response.Finance.Error.Description)
+		}
+		return nil, &FetchError{Source: u.Hostname(), Err: err}
+	}
+	return &response, nil
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// Endpoints a Yahoo Finance session is obtained from: the first sets the
+// session cookie, the second answers the crumb that goes with it
+const (
+	yahooCookieURL = "https://fc.yahoo.com/"
+	yahooCrumbURL  = "https://query1.finance.yahoo.com/v1/test/getcrumb"
+)
+
+// yahooSessionLifetime is how long a session is used before a new one is
+// obtained, well within the lifetime of Yahoo's cookie
+const yahooSessionLifetime = 12 * time.Hour
+
+// yahooUserAgent is sent with the requests of a session, which Yahoo ties
+// the cookie to
+const yahooUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+
+// YahooSession holds the cookie and crumb pair the Yahoo Finance
+// endpoints beyond the chart API, such as quoteSummary, require. The pair
+// is obtained on first use and shared by every request until it expires
+// or Yahoo rejects it. It is safe for concurrent use.
+type YahooSession struct {
+	client *http.Client
+
+	mu         sync.Mutex
+	cookies    []*http.Cookie
+	crumb      string
+	obtainedAt time.Time
+}
+
+// NewYahooSession creates a session that obtains its cookie and crumb
+// with client
+func NewYahooSession(client *http.Client) *YahooSession {
+	return &YahooSession{client: client}
+}
+
+// Do sends req with the session's cookie and crumb. A request Yahoo
+// answers with 401 Unauthorized is sent again once with a newly obtained
+// session. The caller closes the response body.
+func (s *YahooSession) Do(req *http.Request) (*http.Response, error) {
+	cookies, crumb, err := s.credentials(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(authorize(req, cookies, crumb))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	s.invalidate(crumb)
+	cookies, crumb, err = s.credentials(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	return s.client.Do(authorize(req, cookies, crumb))
+}
+
+// credentials returns the session's cookie and crumb, obtaining new ones
+// when there are none or they expired. Concurrent callers wait for one
+// to be obtained rather than each obtaining their own.
+func (s *YahooSession) credentials(ctx context.Context) ([]*http.Cookie, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.crumb != "" && time.Since(s.obtainedAt) < yahooSessionLifetime {
+		return s.cookies, s.crumb, nil
+	}
+
+	cookies, err := s.fetchCookies(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	crumb, err := s.fetchCrumb(ctx, cookies)
+	if err != nil {
+		return nil, "", err
+	}
+	s.cookies, s.crumb, s.obtainedAt = cookies, crumb, time.Now()
+	return cookies, crumb, nil
+}
+
+// invalidate drops the session if its crumb is still crumb, so requests
+// rejected together obtain a single new session
+func (s *YahooSession) invalidate(crumb string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.crumb == crumb {
+		s.cookies, s.crumb = nil, ""
+	}
+}
+
+// fetchCookies obtains a session cookie. Yahoo sets it along with a 404
+// status, so the status is not checked.
+func (s *YahooSession) fetchCookies(ctx context.Context) ([]*http.Cookie, error) {
+	req, err := http.NewRequestWithContext(withSource(ctx, SourceYahooSession), http.MethodGet, yahooCookieURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", yahooUserAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, requestError(req, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		return nil, &FetchError{Source: req.URL.Hostname(), Err: errors.New("no session cookie set")}
+	}
+	return cookies, nil
+}
+
+// fetchCrumb obtains the crumb that goes with cookies
+func (s *YahooSession) fetchCrumb(ctx context.Context, cookies []*http.Cookie) (string, error) {
+	req, err := http.NewRequestWithContext(withSource(ctx, SourceYahooSession), http.MethodGet, yahooCrumbURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", yahooUserAgent)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", requestError(req, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", requestError(req, fmt.Errorf("failed to read crumb: %w", err))
+	}
+	// A consent or error page in place of the crumb is no crumb
+	crumb := strings.TrimSpace(string(body))
+	if crumb == "" || strings.ContainsAny(crumb, "<> \n") {
+		return "", parseError(req.URL.Hostname(), errors.New("no crumb in response"))
+	}
+	return crumb, nil
+}
+
+// authorize returns a copy of req carrying cookies and crumb
+func authorize(req *http.Request, cookies []*http.Cookie, crumb string) *http.Request {
+	authorized := req.Clone(req.Context())
+	query := authorized.URL.Query()
+	query.Set("crumb", crumb)
+	authorized.URL.RawQuery = query.Encode()
+	authorized.Header.Set("User-Agent", yahooUserAgent)
+	authorized.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		authorized.AddCookie(cookie)
+	}
+	return authorized
+}
+
+// quoteSummaryModules are the quoteSummary modules the fetcher reads,
+// the same the Yahoo Finance pages embed in their QuoteSummaryStore
+var quoteSummaryModules = []string{
+	"defaultKeyStatistics", "financialData", "summaryDetail", "calendarEvents",
+	"cashflowStatementHistory", "balanceSheetHistory", "assetProfile", "price",
+}
+
+// yahooQuoteSummaryResponse is the response of the quoteSummary API, whose
+// result holds the requested modules by name
+type yahooQuoteSummaryResponse struct {
+	QuoteSummary struct {
+		Result []map[string]interface{} `json:"result"`
+		Error  *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"quoteSummary"`
+}
+
+// fetchQuoteSummary fetches the fundamentals and profile of ticker from
+// the quoteSummary API, which needs a session, retrying when the API is
+// rate limiting or slow to answer
+func (df *DataFetcher) fetchQuoteSummary(ctx context.Context, ticker string, stockData *models.StockData) error {
+	modules, err := withRetry(ctx, func() (map[string]interface{}, error) {
+		return df.fetchQuoteSummaryOnce(ctx, ticker)
+	})
+	if err != nil {
+		return err
+	}
+	df.parseQuoteSummaryData(modules, stockData)
+	df.parseQuoteSummaryProfile(modules, stockData)
+	// Per-share figures need the share count set above
+	df.parseQuoteSummaryFinancials(modules, stockData)
+	return nil
+}
+
+// fetchQuoteSummaryOnce makes a single request to the quoteSummary API
+func (df *DataFetcher) fetchQuoteSummaryOnce(ctx context.Context, ticker string) (map[string]interface{}, error) {
+	u := &url.URL{
+		Scheme:   "https",
+		Host:     "query2.finance.yahoo.com",
+		Path:     "/v10/finance/quoteSummary/" + ticker,
+		RawQuery: url.Values{"modules": {strings.Join(quoteSummaryModules, ",")}}.Encode(),
+	}
+	req, err := http.NewRequestWithContext(withSource(ctx, SourceYahooQuoteSummary), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := df.yahooSession.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, requestError(req, fmt.Errorf("failed to read response body: %w", err))
+	}
+	var response yahooQuoteSummaryResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, parseError(u.Hostname(), fmt.Errorf("failed to parse JSON response: %w", err))
+	}
+	if len(response.QuoteSummary.Result) == 0 {
+		err := fmt.Errorf("no quote summary found for %s", ticker)
+		if response.QuoteSummary.Error != nil {
+			err = errors.New(response.QuoteSummary.Error.Description)
+		}
+		return nil, &FetchError{Source: u.Hostname(), Cause: ErrSymbolNotFound, Err: err}
+	}
+	return response.QuoteSummary.Result[0], nil
+}
@@ -0,0 +1,91 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+)
+
+// archiveClient is shared by the archive sinks writing to object stores
+var archiveClient = &http.Client{Timeout: 2 * time.Minute}
+
+// ArchiveSink archives each run as gzip-compressed JSON, in a directory
+// or as an object uploaded to an object store, building a record of every
+// call the model made that the trends report charts
+type ArchiveSink struct {
+	store    *storage.ArchiveStore // nil when uploading
+	endpoint *url.URL              // prefix objects are uploaded under
+	token    string
+}
+
+// NewArchiveSink creates a sink archiving into the directory cfg.Path, or
+// uploading to cfg.URL with an HTTP PUT per run, authorized with the
+// bearer token cfg.Token when set
+func NewArchiveSink(cfg config.SinkConfig) (*ArchiveSink, error) {
+	switch {
+	case cfg.Path != "" && cfg.URL != "":
+		return nil, fmt.Errorf("archive sink takes a path or a url, not both")
+	case cfg.Path != "":
+		store, err := storage.NewArchiveStore(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		return &ArchiveSink{store: store}, nil
+	case cfg.URL != "":
+		endpoint, err := url.Parse(strings.TrimSpace(cfg.URL))
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return nil, fmt.Errorf("archive sink requires an http(s) url, got %q", cfg.URL)
+		}
+		return &ArchiveSink{endpoint: endpoint, token: cfg.Token}, nil
+	}
+	return nil, fmt.Errorf("archive sink requires a path or a url")
+}
+
+// Name returns the sink name used in error messages
+func (s *ArchiveSink) Name() string {
+	if s.store != nil {
+		return "archive:" + s.store.Dir()
+	}
+	return "archive:" + s.endpoint.Host
+}
+
+// Publish archives run
+func (s *ArchiveSink) Publish(ctx context.Context, run *models.Run) error {
+	if s.store != nil {
+		return s.store.Save(run)
+	}
+
+	var body bytes.Buffer
+	if err := storage.WriteArchivedRun(&body, run); err != nil {
+		return err
+	}
+	object := s.endpoint.JoinPath(run.ID + storage.ArchiveExt)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, object.String(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := archiveClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("upload of %s failed: %s: %s", run.ID, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/notify"
+)
+
+// EmailSink emails each run as an HTML report, optionally with the
+// results attached as CSV
+type EmailSink struct {
+	email     *notify.Email
+	opts      SummaryOptions
+	attachCSV bool
+	statuses  statusTracker
+}
+
+// NewEmailSink creates a sink sending email with the SMTP settings of cfg.
+// The report includes every result unless cfg selects its sections.
+func NewEmailSink(cfg config.SinkConfig) (*EmailSink, error) {
+	email, err := notify.NewEmail(config.NotifierConfig{
+		Type:     "email",
+		SMTPHost: cfg.SMTPHost,
+		SMTPPort: cfg.SMTPPort,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		From:     cfg.From,
+		To:       cfg.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	opts := SummaryOptions{Sections: cfg.Include, TopN: cfg.TopN}
+	if len(opts.Sections) == 0 {
+		opts.Sections = append(append([]string{}, DefaultSections...), SectionResults)
+	}
+	return &EmailSink{email: email, opts: opts, attachCSV: cfg.AttachCSV}, nil
+}
+
+// Name returns the sink name used in error messages
+func (s *EmailSink) Name() string {
+	return s.email.Name()
+}
+
+// Publish emails the report of run
+func (s *EmailSink) Publish(ctx context.Context, run *models.Run) error {
+	summary := Summarize(run, s.opts)
+	changes := s.statuses.changes(run)
+	if s.opts.includes(SectionStatusChanges) {
+		summary.StatusChanges = changes
+	}
+
+	html, err := renderHTML(summary)
+	if err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	msg := notify.Message{
+		Subject: "Fair value report: " + summary.Subject(),
+		Text:    summary.Text(),
+		HTML:    html,
+	}
+
+	if s.attachCSV {
+		var csv bytes.Buffer
+		if err := WriteCSV(&csv, run); err != nil {
+			return fmt.Errorf("failed to export CSV: %w", err)
+		}
+		msg.Attachments = append(msg.Attachments, notify.Attachment{
+			Filename:    "fair-value-" + run.ID + ".csv",
+			ContentType: "text/csv; charset=UTF-8",
+			Data:        csv.Bytes(),
+		})
+	}
+	return s.email.Notify(ctx, msg)
+}
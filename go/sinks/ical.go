@@ -0,0 +1,164 @@
+package sinks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// DefaultCalendarSize is the number of undervalued stocks whose earnings
+// a calendar lists unless told otherwise
+const DefaultCalendarSize = 10
+
+// EarningsEvents returns the results of undervalued stocks reporting
+// earnings today or later, the topN with the most upside first
+func EarningsEvents(results []*models.ValuationResult, topN int, now time.Time) []*models.ValuationResult {
+	if topN <= 0 {
+		topN = DefaultCalendarSize
+	}
+	var events []*models.ValuationResult
+	for _, result := range results {
+		if result.Status != models.StatusUnderpriced || result.Inputs == nil || result.Inputs.EarningsDate.IsZero() {
+			continue
+		}
+		// The event lasts the whole day of the report, where it is reported
+		date := result.Inputs.EarningsDate
+		if end := time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, date.Location()); end.After(now) {
+			events = append(events, result)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].UpsidePercentage > events[j].UpsidePercentage
+	})
+	if len(events) > topN {
+		events = events[:topN]
+	}
+	return events
+}
+
+// WriteEarningsCalendar writes the earnings dates of results, as returned
+// by EarningsEvents, as an iCalendar file of all-day events. Each event's
+// UID depends only on the ticker and date, so calendars that import the
+// file again update their events rather than duplicating them.
+func WriteEarningsCalendar(w io.Writer, results []*models.ValuationResult, now time.Time) error {
+	out := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(out, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//fair-stock-value//Earnings of undervalued stocks//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", "Earnings of undervalued stocks")
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, result := range results {
+		date := result.Inputs.EarningsDate
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+		name := result.Ticker
+		if result.CompanyName != "" {
+			name = fmt.Sprintf("%s (%s)", result.CompanyName, result.Ticker)
+		}
+		description := fmt.Sprintf("%s is expected to report earnings. Valued %s: price $%.2f, fair value $%.2f (DCF $%.2f, Comps $%.2f), upside %.1f%%.",
+			name, result.Status, result.CurrentPrice, result.FairValue, result.DCFValue, result.CompsValue, result.UpsidePercentage)
+
+		line("BEGIN", "VEVENT")
+		line("UID", "earnings-"+strings.ToLower(result.Ticker)+"-"+day.Format("20060102")+"@fair-stock-value")
+		line("DTSTAMP", stamp)
+		line("DTSTART;VALUE=DATE", day.Format("20060102"))
+		line("DTEND;VALUE=DATE", day.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", escapeICS(fmt.Sprintf("%s earnings (%.1f%% upside)", result.Ticker, result.UpsidePercentage)))
+		line("DESCRIPTION", escapeICS(description))
+		line("CATEGORIES", "Earnings")
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return out.Flush()
+}
+
+// escapeICS escapes the characters of a text value that iCalendar reserves
+func escapeICS(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
+
+// writeFolded writes a content line ended by CRLF, folding it into lines of
+// at most 75 octets without splitting characters
+func writeFolded(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space
+		limit = 74
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// CalendarSink rewrites an iCalendar file with the upcoming earnings dates
+// of the top undervalued stocks after each run, for calendar applications
+// that subscribe to the file
+type CalendarSink struct {
+	path  string
+	topN  int
+	mutex sync.Mutex
+}
+
+// NewCalendarSink creates a sink writing the earnings of the topN
+// undervalued stocks to path
+func NewCalendarSink(path string, topN int) (*CalendarSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("ics sink requires a path")
+	}
+	return &CalendarSink{path: path, topN: topN}, nil
+}
+
+// Name returns the sink name used in error messages
+func (s *CalendarSink) Name() string {
+	return "ics:" + s.path
+}
+
+// Publish replaces the file with the earnings calendar of run
+func (s *CalendarSink) Publish(ctx context.Context, run *models.Run) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Write to a temporary file first, so a subscribed calendar never
+	// reads a half-written one
+	file, err := os.CreateTemp(filepath.Dir(s.path), ".ics-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", s.path, err)
+	}
+	defer os.Remove(file.Name())
+
+	// The run's time, on the analyzer's clock, renders the same run the
+	// same way each time
+	now := run.FinishedAt
+	err = WriteEarningsCalendar(file, EarningsEvents(run.Results, s.topN, now), now)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	if err := os.Chmod(file.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), s.path)
+}
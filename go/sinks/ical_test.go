@@ -0,0 +1,61 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+func TestCalendarSinkRendersRunTime(t *testing.T) {
+	finishedAt := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	result := func(ticker string, earnings time.Time) *models.ValuationResult {
+		return &models.ValuationResult{
+			Ticker:           ticker,
+			Status:           models.StatusUnderpriced,
+			UpsidePercentage: 20,
+			Inputs:           &models.StockData{Ticker: ticker, EarningsDate: earnings},
+		}
+	}
+	run := &models.Run{
+		StartedAt:  finishedAt.Add(-time.Minute),
+		FinishedAt: finishedAt,
+		Results: []*models.ValuationResult{
+			result("NEXT", finishedAt.AddDate(0, 0, 19)),
+			result("PAST", finishedAt.AddDate(0, 0, -12)),
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "earnings.ics")
+	sink, err := NewCalendarSink(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var published [][]byte
+	for range 2 {
+		if err := sink.Publish(context.Background(), run); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		published = append(published, data)
+	}
+
+	if !bytes.Equal(published[0], published[1]) {
+		t.Errorf("the same run rendered differently:\n%s\n%s", published[0], published[1])
+	}
+	for _, want := range []string{"DTSTAMP:20000101T000000Z", "UID:earnings-next-20000120@fair-stock-value"} {
+		if !bytes.Contains(published[0], []byte(want)) {
+			t.Errorf("calendar lacks %s:\n%s", want, published[0])
+		}
+	}
+	if bytes.Contains(published[0], []byte("earnings-past-")) {
+		t.Errorf("calendar lists earnings reported before the run:\n%s", published[0])
+	}
+}
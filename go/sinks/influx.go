@@ -0,0 +1,183 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// DefaultMeasurement is the measurement the influxdb sink writes to
+const DefaultMeasurement = "fair_value"
+
+// influxBatch is the number of points written per request
+const influxBatch = 5000
+
+// influxClient is shared by the influxdb sinks
+var influxClient = &http.Client{Timeout: 30 * time.Second}
+
+// InfluxSink writes the fair value, price and upside of every valued ticker
+// of each run as points in InfluxDB line protocol, for time-series
+// dashboards such as Grafana. With an organization it uses the InfluxDB 2
+// write API, and otherwise the InfluxDB 1 one, which VictoriaMetrics also
+// accepts.
+type InfluxSink struct {
+	endpoint    string
+	token       string
+	measurement string
+}
+
+// NewInfluxSink creates a sink writing to the server at cfg.URL, into the
+// bucket (or database) cfg.Bucket
+func NewInfluxSink(cfg config.SinkConfig) (*InfluxSink, error) {
+	base, err := url.Parse(strings.TrimSpace(cfg.URL))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("influxdb sink requires an http(s) url, got %q", cfg.URL)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("influxdb sink requires a bucket")
+	}
+
+	query := url.Values{"precision": {"s"}}
+	if cfg.Org != "" {
+		base = base.JoinPath("api", "v2", "write")
+		query.Set("org", cfg.Org)
+		query.Set("bucket", cfg.Bucket)
+	} else {
+		base = base.JoinPath("write")
+		query.Set("db", cfg.Bucket)
+	}
+	base.RawQuery = query.Encode()
+
+	measurement := cfg.Measurement
+	if measurement == "" {
+		measurement = DefaultMeasurement
+	}
+	return &InfluxSink{endpoint: base.String(), token: cfg.Token, measurement: measurement}, nil
+}
+
+// Name returns the sink name used in error messages
+func (s *InfluxSink) Name() string {
+	endpoint, _ := url.Parse(s.endpoint)
+	return "influxdb:" + endpoint.Host
+}
+
+// Publish writes a point per valued ticker of run, stamped with the time
+// the run finished
+func (s *InfluxSink) Publish(ctx context.Context, run *models.Run) error {
+	lines := s.lines(run)
+	for start := 0; start < len(lines); start += influxBatch {
+		body := strings.Join(lines[start:min(start+influxBatch, len(lines))], "\n")
+		if err := s.write(ctx, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lines renders the points of run in line protocol
+func (s *InfluxSink) lines(run *models.Run) []string {
+	at := run.FinishedAt
+	if at.IsZero() {
+		at = run.StartedAt
+	}
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	lines := make([]string, 0, len(run.Results))
+	for _, result := range run.Results {
+		if result.Failed() {
+			continue
+		}
+
+		var line strings.Builder
+		line.WriteString(escapeInflux(s.measurement, ", "))
+		writeTag(&line, "ticker", result.Ticker)
+		writeTag(&line, "sector", result.Sector)
+		writeTag(&line, "status", result.Status)
+		writeTag(&line, "tag", result.Tag)
+		writeTag(&line, "job", run.Job)
+		writeTag(&line, "run_tags", strings.Join(run.Tags, ","))
+
+		// Line protocol has no NaN or infinity, so such fields are left out
+		separator := " "
+		for _, field := range []struct {
+			key   string
+			value float64
+		}{
+			{"fair_value", result.FairValue},
+			{"price", result.CurrentPrice},
+			{"upside_pct", result.UpsidePercentage},
+			{"dcf_value", result.DCFValue},
+			{"comps_value", result.CompsValue},
+		} {
+			if math.IsNaN(field.value) || math.IsInf(field.value, 0) {
+				continue
+			}
+			line.WriteString(separator + field.key + "=" + strconv.FormatFloat(field.value, 'g', -1, 64))
+			separator = ","
+		}
+		fmt.Fprintf(&line, "%sincomplete=%t,prices_only=%t,run_id=\"%s\"",
+			separator, result.Incomplete, run.PricesOnly, escapeInflux(run.ID, `"`))
+		line.WriteString(" " + timestamp)
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// write posts a body of points
+func (s *InfluxSink) write(ctx context.Context, body string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+
+	resp, err := influxClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if detail = bytes.TrimSpace(detail); len(detail) > 0 {
+			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, detail)
+		}
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// writeTag appends a tag to a point, leaving out empty values, which line
+// protocol does not allow
+func writeTag(line *strings.Builder, key, value string) {
+	if value == "" {
+		return
+	}
+	line.WriteString("," + key + "=" + escapeInflux(value, ",= "))
+}
+
+// escapeInflux escapes the characters of special, and backslashes, in a
+// measurement, tag or string field
+func escapeInflux(value, special string) string {
+	var escaped strings.Builder
+	for _, r := range value {
+		if r == '\\' || strings.ContainsRune(special, r) {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
@@ -0,0 +1,52 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// JSONLinesSink appends each run as a single JSON line to a file
+type JSONLinesSink struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewJSONLinesSink creates a sink appending to path
+func NewJSONLinesSink(path string) (*JSONLinesSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("jsonl sink requires a path")
+	}
+	return &JSONLinesSink{path: path}, nil
+}
+
+// Name returns the sink name used in error messages
+func (s *JSONLinesSink) Name() string {
+	return "jsonl:" + s.path
+}
+
+// Publish appends run to the file
+func (s *JSONLinesSink) Publish(ctx context.Context, run *models.Run) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", s.path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package sinks
+
+import (
+	"context"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/notify"
+)
+
+// NotifierSink posts a summary of each run through a notifier: the full
+// summary as JSON for webhooks, a formatted message for chat services
+type NotifierSink struct {
+	notifier notify.Notifier
+	opts     SummaryOptions
+	markdown bool // render the text for chat services
+	statuses statusTracker
+}
+
+// NewNotifierSink creates a sink posting run summaries through notifier
+func NewNotifierSink(notifier notify.Notifier, opts SummaryOptions, markdown bool) *NotifierSink {
+	return &NotifierSink{notifier: notifier, opts: opts, markdown: markdown}
+}
+
+// Name returns the sink name used in error messages
+func (s *NotifierSink) Name() string {
+	return s.notifier.Name()
+}
+
+// Publish posts the run summary
+func (s *NotifierSink) Publish(ctx context.Context, run *models.Run) error {
+	summary := Summarize(run, s.opts)
+	changes := s.statuses.changes(run)
+	if s.opts.includes(SectionStatusChanges) {
+		summary.StatusChanges = changes
+	}
+
+	text := summary.Text()
+	if s.markdown {
+		text = summary.Markdown()
+	}
+	return s.notifier.Notify(ctx, notify.Message{
+		Subject: summary.Subject(),
+		Text:    text,
+		Data:    summary,
+	})
+}
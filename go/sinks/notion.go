@@ -0,0 +1,330 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// notionAPI is the Notion API endpoint and notionVersion the API version
+// the requests are written against
+const (
+	notionAPI     = "https://api.notion.com/v1"
+	notionVersion = "2022-06-28"
+)
+
+// notionInterval spaces requests to stay within Notion's limit of about
+// three requests a second
+const notionInterval = 350 * time.Millisecond
+
+// notionRetries is the number of times a rate limited request is retried
+const notionRetries = 3
+
+// notionClient is shared by the notion sinks
+var notionClient = &http.Client{Timeout: 30 * time.Second}
+
+// Columns of the Notion database the sink fills when they exist with
+// their type. The title column, whatever its name, holds the ticker.
+var notionColumns = []struct {
+	name, kind string
+}{
+	{"Fair Value", "number"},
+	{"Price", "number"},
+	{"Upside", "number"},
+	{"Status", "select"},
+	{"Run Date", "date"},
+	{"Company", "rich_text"},
+	{"Sector", "select"},
+	{"DCF Value", "number"},
+	{"Comps Value", "number"},
+	{"Run ID", "rich_text"},
+}
+
+// NotionSink upserts the results of each run into a Notion database, one
+// row per ticker: the row of a ticker is updated by later runs, so the
+// database always shows the latest valuation of each stock.
+//
+// Notion accepts about three requests a second, a request per row, so
+// a run of hundreds of tickers takes minutes to write. The sink therefore
+// allows itself more than the time other sinks are given, in proportion
+// to the rows it writes.
+type NotionSink struct {
+	token      string
+	databaseID string
+	baseURL    string
+
+	// mutex is held while a run is written, so runs published at the same
+	// time do not both create the row of a new ticker
+	mutex sync.Mutex
+	last  time.Time // when the last request was sent
+}
+
+// NewNotionSink creates a sink writing to the database cfg.DatabaseID with
+// the integration token cfg.Token
+func NewNotionSink(cfg config.SinkConfig) (*NotionSink, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("notion sink requires an integration token")
+	}
+	databaseID := strings.ReplaceAll(strings.TrimSpace(cfg.DatabaseID), "-", "")
+	if databaseID == "" {
+		return nil, fmt.Errorf("notion sink requires a database_id")
+	}
+	return &NotionSink{token: cfg.Token, databaseID: databaseID, baseURL: notionAPI}, nil
+}
+
+// Name returns the sink name used in error messages
+func (s *NotionSink) Name() string {
+	return "notion:" + s.databaseID
+}
+
+// Publish upserts a row per valued ticker of run. Watch mode's price-only
+// passes are skipped, since the rate limit would not let them keep up.
+func (s *NotionSink) Publish(ctx context.Context, run *models.Run) error {
+	if run.PricesOnly {
+		return nil
+	}
+	var results []*models.ValuationResult
+	for _, result := range run.Results {
+		if !result.Failed() {
+			results = append(results, result)
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	budget := time.Duration(len(results)+20) * notionInterval * 2
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), budget)
+	defer cancel()
+
+	columns, title, err := s.columns(ctx)
+	if err != nil {
+		return err
+	}
+	pages, err := s.pages(ctx, title)
+	if err != nil {
+		return err
+	}
+
+	for i, result := range results {
+		properties := notionProperties(run, result, columns, title)
+		if id, ok := pages[result.Ticker]; ok {
+			err = s.call(ctx, http.MethodPatch, "/pages/"+id, map[string]any{"properties": properties}, nil)
+		} else {
+			err = s.call(ctx, http.MethodPost, "/pages", map[string]any{
+				"parent":     map[string]string{"database_id": s.databaseID},
+				"properties": properties,
+			}, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("wrote %d of %d rows: %s: %w", i, len(results), result.Ticker, err)
+		}
+	}
+	return nil
+}
+
+// columns returns the known columns the database has with the expected
+// type, and the name of its title column
+func (s *NotionSink) columns(ctx context.Context) (map[string]bool, string, error) {
+	var database struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	if err := s.call(ctx, http.MethodGet, "/databases/"+s.databaseID, nil, &database); err != nil {
+		return nil, "", err
+	}
+
+	var title string
+	columns := make(map[string]bool)
+	for name, property := range database.Properties {
+		if property.Type == "title" {
+			title = name
+		}
+	}
+	for _, column := range notionColumns {
+		if property, ok := database.Properties[column.name]; ok && property.Type == column.kind {
+			columns[column.name] = true
+		}
+	}
+	if title == "" {
+		return nil, "", fmt.Errorf("database has no title column")
+	}
+	return columns, title, nil
+}
+
+// pages returns the IDs of the rows of the database by ticker
+func (s *NotionSink) pages(ctx context.Context, title string) (map[string]string, error) {
+	pages := make(map[string]string)
+	query := map[string]any{"page_size": 100}
+	for {
+		var page struct {
+			Results []struct {
+				ID         string `json:"id"`
+				Properties map[string]struct {
+					Title []struct {
+						PlainText string `json:"plain_text"`
+					} `json:"title"`
+				} `json:"properties"`
+			} `json:"results"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := s.call(ctx, http.MethodPost, "/databases/"+s.databaseID+"/query", query, &page); err != nil {
+			return nil, err
+		}
+		for _, row := range page.Results {
+			var ticker strings.Builder
+			for _, text := range row.Properties[title].Title {
+				ticker.WriteString(text.PlainText)
+			}
+			if t := strings.ToUpper(strings.TrimSpace(ticker.String())); t != "" {
+				pages[t] = row.ID
+			}
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			return pages, nil
+		}
+		query["start_cursor"] = page.NextCursor
+	}
+}
+
+// notionProperties returns the property values of the row of result
+func notionProperties(run *models.Run, result *models.ValuationResult, columns map[string]bool, title string) map[string]any {
+	properties := map[string]any{
+		title: map[string]any{"title": notionText(result.Ticker)},
+	}
+	set := func(name string, value any) {
+		if columns[name] {
+			properties[name] = value
+		}
+	}
+	number := func(name string, value float64) {
+		set(name, map[string]any{"number": models.RoundMoney(value)})
+	}
+	choice := func(name, value string) {
+		if value == "" {
+			set(name, map[string]any{"select": nil})
+			return
+		}
+		// Select options may not contain commas
+		set(name, map[string]any{"select": map[string]string{"name": strings.ReplaceAll(value, ",", "")}})
+	}
+
+	number("Fair Value", result.FairValue)
+	number("Price", result.CurrentPrice)
+	number("Upside", result.UpsidePercentage)
+	number("DCF Value", result.DCFValue)
+	number("Comps Value", result.CompsValue)
+	choice("Status", result.Status)
+	choice("Sector", result.Sector)
+	set("Company", map[string]any{"rich_text": notionText(result.CompanyName)})
+	set("Run ID", map[string]any{"rich_text": notionText(run.ID)})
+
+	at := run.FinishedAt
+	if at.IsZero() {
+		at = run.StartedAt
+	}
+	set("Run Date", map[string]any{"date": map[string]string{"start": at.UTC().Format(time.RFC3339)}})
+	return properties
+}
+
+// notionText returns the rich text value holding text
+func notionText(text string) []map[string]any {
+	if text == "" {
+		return []map[string]any{}
+	}
+	return []map[string]any{{"type": "text", "text": map[string]string{"content": text}}}
+}
+
+// call sends a request to the Notion API, pacing requests and retrying
+// those that are rate limited, and decodes the response into result
+// unless it is nil
+func (s *NotionSink) call(ctx context.Context, method, path string, body any, result any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		wait := notionInterval - time.Since(s.last)
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+		s.last = time.Now()
+
+		req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+s.token)
+		req.Header.Set("Notion-Version", notionVersion)
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := notionClient.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < notionRetries {
+			seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			if err := sleepContext(ctx, time.Duration(max(seconds, 1))*time.Second); err != nil {
+				return err
+			}
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			var failure struct {
+				Message string `json:"message"`
+			}
+			if json.Unmarshal(data, &failure) == nil && failure.Message != "" {
+				return fmt.Errorf("HTTP %d: %s", resp.StatusCode, failure.Message)
+			}
+			return fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+		return nil
+	}
+}
+
+// sleepContext waits for d, or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,179 @@
+package sinks
+
+import (
+	"io"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/parquet"
+)
+
+// parquetResultColumns are the columns written by WriteParquet for every
+// result, in the order of resultRow
+var parquetResultColumns = []parquet.Column{
+	{Name: "run_id", Type: parquet.String},
+	{Name: "run_started_at", Type: parquet.Timestamp},
+	{Name: "ticker", Type: parquet.String},
+	{Name: "company", Type: parquet.String},
+	{Name: "sector", Type: parquet.String},
+	{Name: "tag", Type: parquet.String},
+	{Name: "status", Type: parquet.String},
+	{Name: "current_price", Type: parquet.Double},
+	{Name: "fair_value", Type: parquet.Double},
+	{Name: "price_difference", Type: parquet.Double},
+	{Name: "upside_pct", Type: parquet.Double},
+	{Name: "dcf_value", Type: parquet.Double},
+	{Name: "comps_value", Type: parquet.Double},
+	{Name: "book_value", Type: parquet.Double},
+	{Name: "pe_ratio", Type: parquet.Double},
+	{Name: "eps", Type: parquet.Double},
+	{Name: "fcf_per_share", Type: parquet.Double},
+	{Name: "growth_rate", Type: parquet.Double},
+	{Name: "market_cap", Type: parquet.Int64},
+	{Name: "incomplete", Type: parquet.Bool},
+	{Name: "error", Type: parquet.String},
+	{Name: "rating", Type: parquet.Int64, Optional: true}, // null without a rated note
+	{Name: "note", Type: parquet.String, Optional: true},  // null without a note text
+}
+
+// parquetInputColumns are the fundamentals added by WriteParquet with
+// withInputs, in the order of inputRow. They are null for results that
+// did not record their inputs.
+var parquetInputColumns = []parquet.Column{
+	{Name: "input_current_price", Type: parquet.Double, Optional: true},
+	{Name: "input_fcf_per_share", Type: parquet.Double, Optional: true},
+	{Name: "input_eps", Type: parquet.Double, Optional: true},
+	{Name: "input_book_value", Type: parquet.Double, Optional: true},
+	{Name: "input_growth_rate", Type: parquet.Double, Optional: true},
+	{Name: "input_pe_ratio", Type: parquet.Double, Optional: true},
+	{Name: "input_market_cap", Type: parquet.Int64, Optional: true},
+	{Name: "input_shares_outstanding", Type: parquet.Int64, Optional: true},
+	{Name: "input_dividend_per_share", Type: parquet.Double, Optional: true},
+	{Name: "input_beta", Type: parquet.Double, Optional: true},
+	{Name: "input_total_debt", Type: parquet.Double, Optional: true},
+	{Name: "input_cash", Type: parquet.Double, Optional: true},
+	{Name: "input_ebitda", Type: parquet.Double, Optional: true},
+	{Name: "input_revenue", Type: parquet.Double, Optional: true},
+	{Name: "input_tangible_book_value", Type: parquet.Double, Optional: true},
+	{Name: "input_moving_average_50", Type: parquet.Double, Optional: true},
+	{Name: "input_moving_average_200", Type: parquet.Double, Optional: true},
+	{Name: "input_average_volume", Type: parquet.Int64, Optional: true},
+	{Name: "input_currency", Type: parquet.String, Optional: true},
+	{Name: "input_incomplete", Type: parquet.Bool, Optional: true},
+	{Name: "input_fetch_time", Type: parquet.Timestamp, Optional: true},
+}
+
+// parquetGroupRows is the number of rows per row group written by
+// NewParquetWriter
+const parquetGroupRows = 1000
+
+// WriteParquet writes the results of run as a Parquet file, one row per
+// ticker. With withInputs, the fundamentals each ticker was valued on are
+// added as input_* columns. The run ID, version, configuration hash and
+// model parameters are stored in the file metadata.
+func WriteParquet(w io.Writer, run *models.Run, withInputs bool) error {
+	pw := newParquetWriter(parquet.NewWriter(parquetColumns(withInputs)...), run, withInputs)
+	for _, r := range run.Results {
+		if err := pw.Write(r); err != nil {
+			return err
+		}
+	}
+	_, err := pw.writer.WriteTo(w)
+	return err
+}
+
+// NewParquetWriter starts the Parquet file WriteParquet would write for
+// run, writing it to w a row group of parquetGroupRows results at a time
+func NewParquetWriter(w io.Writer, run *models.Run, withInputs bool) ResultWriter {
+	columns := parquetColumns(withInputs)
+	return newParquetWriter(parquet.NewStreamWriter(w, parquetGroupRows, columns...), run, withInputs)
+}
+
+// parquetColumns returns the columns of the file, with the input columns
+// when withInputs
+func parquetColumns(withInputs bool) []parquet.Column {
+	if !withInputs {
+		return parquetResultColumns
+	}
+	return append(append([]parquet.Column{}, parquetResultColumns...), parquetInputColumns...)
+}
+
+// parquetWriter appends the rows of results to a Parquet writer
+type parquetWriter struct {
+	writer     *parquet.Writer
+	run        *models.Run
+	withInputs bool
+}
+
+// newParquetWriter records the metadata of run with writer
+func newParquetWriter(writer *parquet.Writer, run *models.Run, withInputs bool) *parquetWriter {
+	writer.SetMetadata("fair_stock_value.run_id", run.ID)
+	if run.Version != "" {
+		writer.SetMetadata("fair_stock_value.version", run.Version)
+	}
+	if run.ConfigHash != "" {
+		writer.SetMetadata("fair_stock_value.config_hash", run.ConfigHash)
+	}
+	if len(run.Config) > 0 {
+		writer.SetMetadata("fair_stock_value.config", string(run.Config))
+	}
+	return &parquetWriter{writer: writer, run: run, withInputs: withInputs}
+}
+
+// Write appends the row of r
+func (pw *parquetWriter) Write(r *models.ValuationResult) error {
+	row := resultRow(pw.run, r)
+	if pw.withInputs {
+		row = append(row, inputRow(r.Inputs)...)
+	}
+	return pw.writer.Append(row...)
+}
+
+// Close writes the remaining rows and the footer
+func (pw *parquetWriter) Close(*models.Run) error {
+	return pw.writer.Close()
+}
+
+// resultRow returns the values of parquetResultColumns for r
+func resultRow(run *models.Run, r *models.ValuationResult) []interface{} {
+	return []interface{}{
+		run.ID, run.StartedAt,
+		r.Ticker, r.CompanyName, r.Sector, r.Tag, r.Status,
+		r.CurrentPrice, r.FairValue, r.PriceDifference, r.UpsidePercentage,
+		r.DCFValue, r.CompsValue, r.BookValue,
+		r.PERatio, r.EPS, r.FCFPerShare, r.GrowthRate,
+		r.MarketCap, r.Incomplete, r.Error,
+		noteRating(r.Note), noteText(r.Note),
+	}
+}
+
+// noteRating returns the rating of note, or nil when it has none
+func noteRating(note *models.Note) interface{} {
+	if note == nil || note.Rating == 0 {
+		return nil
+	}
+	return int64(note.Rating)
+}
+
+// noteText returns the text of note, or nil when it has none
+func noteText(note *models.Note) interface{} {
+	if note == nil || note.Text == "" {
+		return nil
+	}
+	return note.Text
+}
+
+// inputRow returns the values of parquetInputColumns for data, all null
+// when there is none
+func inputRow(data *models.StockData) []interface{} {
+	if data == nil {
+		return make([]interface{}, len(parquetInputColumns))
+	}
+	return []interface{}{
+		data.CurrentPrice, data.FCFPerShare, data.EPS, data.BookValue,
+		data.GrowthRate, data.PERatio, data.MarketCap, data.SharesOutstanding,
+		data.DividendPerShare, data.Beta, data.TotalDebt, data.Cash,
+		data.EBITDA, data.Revenue, data.TangibleBookValue,
+		data.MovingAverage50, data.MovingAverage200, data.AverageVolume, data.Currency,
+		data.Incomplete, data.FetchTime,
+	}
+}
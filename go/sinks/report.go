@@ -0,0 +1,155 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// csvHeader lists the columns written by WriteCSV
+var csvHeader = []string{
+	"ticker", "company", "sector", "tag", "status", "current_price", "fair_value",
+	"upside_pct", "dcf_value", "comps_value", "book_value", "pe_ratio", "eps",
+	"fcf_per_share", "growth_rate", "market_cap", "incomplete", "error",
+	"run_id", "version", "config_hash", "rating", "note",
+}
+
+// WriteCSV writes the results of run as CSV with a header row. Every row
+// carries the run ID, version and configuration hash of the run.
+func WriteCSV(w io.Writer, run *models.Run) error {
+	writer, err := NewCSVWriter(w, run)
+	if err != nil {
+		return err
+	}
+	for _, r := range run.Results {
+		if err := writer.Write(r); err != nil {
+			return err
+		}
+	}
+	return writer.Close(run)
+}
+
+// csvWriter streams the rows written by WriteCSV
+type csvWriter struct {
+	writer *csv.Writer
+	run    *models.Run
+}
+
+// NewCSVWriter writes the header row of the CSV file WriteCSV would write
+// for run, returning a writer for its rows
+func NewCSVWriter(w io.Writer, run *models.Run) (ResultWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer, run: run}, nil
+}
+
+// Write adds the row of r
+func (cw *csvWriter) Write(r *models.ValuationResult) error {
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	var rating, note string
+	if r.Note != nil {
+		if r.Note.Rating > 0 {
+			rating = strconv.Itoa(r.Note.Rating)
+		}
+		note = r.Note.Text
+	}
+	return cw.writer.Write([]string{
+		r.Ticker, r.CompanyName, r.Sector, r.Tag, r.Status,
+		money(r.CurrentPrice), money(r.FairValue),
+		strconv.FormatFloat(r.UpsidePercentage, 'f', 2, 64),
+		money(r.DCFValue), money(r.CompsValue), money(r.BookValue),
+		strconv.FormatFloat(r.PERatio, 'f', 2, 64), money(r.EPS), money(r.FCFPerShare),
+		strconv.FormatFloat(r.GrowthRate, 'f', 4, 64),
+		strconv.FormatInt(r.MarketCap, 10),
+		strconv.FormatBool(r.Incomplete), r.Error,
+		cw.run.ID, cw.run.Version, cw.run.ConfigHash,
+		rating, note,
+	})
+}
+
+// Close flushes the rows written so far
+func (cw *csvWriter) Close(*models.Run) error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+// reportTemplate renders a run summary and its results as an HTML email
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"price": func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"pct":   func(v float64) string { return fmt.Sprintf("%+.1f%%", v) },
+	"underpriced": func(status string) bool {
+		return status == models.StatusUnderpriced
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Summary.Subject}}</title>
+</head>
+<body style="font-family: Helvetica, Arial, sans-serif; font-size: 14px; color: #222;">
+<h2 style="margin-bottom: 4px;">Stock Fair Value Analysis</h2>
+<p style="margin-top: 0; color: #666;">{{.Summary.Subject}} at {{.Summary.FinishedAt.Format "2006-01-02 15:04 MST"}}</p>
+{{with .Summary.Stats}}
+<p>{{.Total}} stocks valued: <span style="color: #1a7f37;">{{.Underpriced}} underpriced</span>,
+<span style="color: #cf222e;">{{.Overpriced}} overpriced</span>{{if .Failed}}, {{.Failed}} failed{{end}}.
+{{if .Underpriced}}Average upside of underpriced stocks: {{pct .AverageUpsidePct}}.{{end}}</p>
+{{end}}
+{{with .Summary.TopUndervalued}}
+<h3>Top undervalued</h3>
+<table cellpadding="4" cellspacing="0" style="border-collapse: collapse;">
+<tr style="background: #f0f0f0;"><th align="left">Ticker</th><th align="left">Company</th><th align="right">Price</th><th align="right">Fair value</th><th align="right">Upside</th></tr>
+{{range .}}<tr><td>{{.Ticker}}</td><td>{{.CompanyName}}</td><td align="right">{{price .Price}}</td><td align="right">{{price .FairValue}}</td><td align="right" style="color: #1a7f37;">{{pct .UpsidePct}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with .Summary.StatusChanges}}
+<h3>Status changes</h3>
+<ul>
+{{range .}}<li><b>{{.Ticker}}</b>: {{.From}} &rarr; {{.To}} ({{pct .UpsidePct}})</li>
+{{end}}</ul>
+{{end}}
+{{with .Results}}
+<h3>All results</h3>
+<table cellpadding="4" cellspacing="0" style="border-collapse: collapse;">
+<tr style="background: #f0f0f0;"><th align="left">Ticker</th><th align="left">Sector</th><th align="right">Price</th><th align="right">Fair value</th><th align="right">Upside</th><th align="left">Status</th></tr>
+{{range .}}{{if .Failed}}<tr style="color: #999;"><td>{{.Ticker}}</td><td>{{.Sector}}</td><td align="right">-</td><td align="right">-</td><td align="right">-</td><td>{{.Status}}: {{.Error}}</td></tr>
+{{else}}<tr style="color: {{if underpriced .Status}}#1a7f37{{else}}#cf222e{{end}};"><td>{{.Ticker}}</td><td>{{.Sector}}</td><td align="right">{{price .CurrentPrice}}</td><td align="right">{{price .FairValue}}</td><td align="right">{{pct .UpsidePercentage}}</td><td>{{.Status}}{{if .Incomplete}}*{{end}}</td></tr>
+{{end}}{{end}}</table>
+{{end}}
+{{with .Summary.Failures}}
+<h3>Failures</h3>
+<ul>
+{{range $ticker, $reason := .}}<li><b>{{$ticker}}</b>: {{$reason}}</li>
+{{end}}</ul>
+{{end}}
+{{if .Summary.Version}}<p style="color: #999; font-size: 12px;">Run {{.Summary.RunID}}, version {{.Summary.Version}}, configuration {{.Summary.ConfigHash}}</p>{{end}}
+</body>
+</html>
+`))
+
+// renderHTML renders summary as an HTML report, with its results sorted
+// by upside and failed tickers last
+func renderHTML(summary RunSummary) (string, error) {
+	results := make([]*models.ValuationResult, len(summary.Results))
+	copy(results, summary.Results)
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Failed() != results[j].Failed() {
+			return results[j].Failed()
+		}
+		return results[i].UpsidePercentage > results[j].UpsidePercentage
+	})
+
+	var b bytes.Buffer
+	err := reportTemplate.Execute(&b, struct {
+		Summary RunSummary
+		Results []*models.ValuationResult
+	}{summary, results})
+	return b.String(), err
+}
@@ -0,0 +1,81 @@
+package sinks
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// routedSink passes a sink only the runs and results routed to it
+type routedSink struct {
+	Sink
+	jobs    map[string]bool
+	tickers map[string]bool
+	sectors map[string]bool // normalized, in lower case
+}
+
+// route wraps sink with the routing of cfg, or returns it unchanged when
+// cfg routes everything to it
+func route(sink Sink, cfg config.SinkConfig) Sink {
+	if len(cfg.Jobs) == 0 && len(cfg.Tickers) == 0 && len(cfg.Sectors) == 0 {
+		return sink
+	}
+
+	r := &routedSink{Sink: sink}
+	r.jobs = toSet(cfg.Jobs, strings.TrimSpace)
+	r.tickers = toSet(cfg.Tickers, func(s string) string {
+		return strings.ToUpper(strings.TrimSpace(s))
+	})
+	r.sectors = toSet(cfg.Sectors, func(s string) string {
+		return strings.ToLower(models.NormalizeSector(s))
+	})
+	return r
+}
+
+// Publish forwards the routed part of run, skipping runs of other jobs
+func (r *routedSink) Publish(ctx context.Context, run *models.Run) error {
+	if r.jobs != nil && !r.jobs[run.Job] {
+		return nil
+	}
+	if r.tickers == nil && r.sectors == nil {
+		return r.Sink.Publish(ctx, run)
+	}
+
+	routed := *run
+	routed.Results = nil
+	for _, result := range run.Results {
+		if r.matches(result.Ticker, result.Sector) {
+			routed.Results = append(routed.Results, result)
+		}
+	}
+	routed.Errors = nil
+	for ticker, reason := range run.Errors {
+		// The sector of a failed ticker is unknown
+		if r.tickers[ticker] {
+			if routed.Errors == nil {
+				routed.Errors = make(map[string]string)
+			}
+			routed.Errors[ticker] = reason
+		}
+	}
+	return r.Sink.Publish(ctx, &routed)
+}
+
+// matches reports whether a result is routed to the sink
+func (r *routedSink) matches(ticker, sector string) bool {
+	return r.tickers[ticker] || r.sectors[strings.ToLower(models.NormalizeSector(sector))]
+}
+
+// toSet returns the normalized values as a set, or nil when there are none
+func toSet(values []string, normalize func(string) string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[normalize(value)] = true
+	}
+	return set
+}
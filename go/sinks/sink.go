@@ -0,0 +1,94 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/notify"
+)
+
+// Sink is a destination that receives every completed run
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, run *models.Run) error
+}
+
+// New creates a sink from its configuration
+func New(cfg config.SinkConfig) (Sink, error) {
+	sink, err := newSink(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return route(sink, cfg), nil
+}
+
+// newSink creates the sink named by cfg.Type
+func newSink(cfg config.SinkConfig) (Sink, error) {
+	opts := SummaryOptions{Sections: cfg.Include, TopN: cfg.TopN}
+	switch cfg.Type {
+	case "jsonl":
+		return NewJSONLinesSink(cfg.Path)
+	case "webhook":
+		webhook, err := notify.NewWebhook(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+		return NewNotifierSink(webhook, opts, false), nil
+	case "slack":
+		slack, err := notify.NewSlack(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+		return NewNotifierSink(slack, opts, true), nil
+	case "email":
+		return NewEmailSink(cfg)
+	case "telegram":
+		telegram, err := notify.NewTelegram(cfg.Token, cfg.ChatID)
+		if err != nil {
+			return nil, err
+		}
+		return NewNotifierSink(telegram, opts, false), nil
+	case "influxdb":
+		return NewInfluxSink(cfg)
+	case "notion":
+		return NewNotionSink(cfg)
+	case "ics":
+		return NewCalendarSink(cfg.Path, cfg.TopN)
+	case "archive":
+		return NewArchiveSink(cfg)
+	case "discord":
+		discord, err := notify.NewDiscord(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+		return NewNotifierSink(discord, opts, true), nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", cfg.Type)
+	}
+}
+
+// NewAll creates every configured sink
+func NewAll(cfgs []config.SinkConfig) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		sink, err := New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("sink %d: %w", i, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// PublishAll sends run to every sink, collecting rather than stopping on errors
+func PublishAll(ctx context.Context, sinks []Sink, run *models.Run) []error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Publish(ctx, run); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errs
+}
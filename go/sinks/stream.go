@@ -0,0 +1,117 @@
+package sinks
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ResultWriter writes the results of a run to a file as they complete, so
+// a run over a large universe never holds all of its results at once
+type ResultWriter interface {
+	// Write adds a result to the file
+	Write(result *models.ValuationResult) error
+
+	// Close finishes the file with the final state of run, such as its
+	// finish time; run's results are not written again
+	Close(run *models.Run) error
+}
+
+// jsonWriter streams a run as the JSON document json.Marshal would produce,
+// with the results array written element by element
+type jsonWriter struct {
+	out     *bufio.Writer
+	results int
+	err     error
+}
+
+// NewJSONWriter starts the JSON document of run on w. The fields known
+// when the run starts come before the results and the rest after them.
+func NewJSONWriter(w io.Writer, run *models.Run) (ResultWriter, error) {
+	jw := &jsonWriter{out: bufio.NewWriter(w)}
+	jw.out.WriteString("{")
+	jw.field("id", run.ID, true)
+	jw.field("started_at", run.StartedAt, false)
+	if run.Job != "" {
+		jw.field("job", run.Job, false)
+	}
+	if len(run.Tags) > 0 {
+		jw.field("tags", run.Tags, false)
+	}
+	if run.Version != "" {
+		jw.field("version", run.Version, false)
+	}
+	if run.ConfigHash != "" {
+		jw.field("config_hash", run.ConfigHash, false)
+	}
+	if len(run.Config) > 0 {
+		jw.field("config", run.Config, false)
+	}
+	jw.out.WriteString(",\n  \"results\": [")
+	return jw, jw.err
+}
+
+// field writes a top-level key and value
+func (jw *jsonWriter) field(key string, value interface{}, first bool) {
+	if jw.err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(value, "  ", "  ")
+	if err != nil {
+		jw.err = err
+		return
+	}
+	if !first {
+		jw.out.WriteString(",")
+	}
+	jw.out.WriteString("\n  \"" + key + "\": ")
+	jw.out.Write(data)
+}
+
+// Write adds result to the results array
+func (jw *jsonWriter) Write(result *models.ValuationResult) error {
+	if jw.err != nil {
+		return jw.err
+	}
+	data, err := json.MarshalIndent(result, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	if jw.results > 0 {
+		jw.out.WriteString(",")
+	}
+	jw.out.WriteString("\n    ")
+	_, jw.err = jw.out.Write(data)
+	jw.results++
+	return jw.err
+}
+
+// Close ends the results array and writes the fields known at the end
+func (jw *jsonWriter) Close(run *models.Run) error {
+	if jw.results > 0 {
+		jw.out.WriteString("\n  ")
+	}
+	jw.out.WriteString("]")
+	finishedAt := run.FinishedAt
+	if finishedAt.IsZero() {
+		finishedAt = time.Now()
+	}
+	jw.field("finished_at", finishedAt, false)
+	if run.PricesOnly {
+		jw.field("prices_only", true, false)
+	}
+	if run.Partial {
+		jw.field("partial", true, false)
+	}
+	if len(run.Errors) > 0 {
+		jw.field("errors", run.Errors, false)
+	}
+	if jw.err != nil {
+		return jw.err
+	}
+	jw.out.WriteString("\n}\n")
+	return jw.out.Flush()
+}
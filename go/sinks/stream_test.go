@@ -0,0 +1,74 @@
+package sinks
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/valuation"
+)
+
+// benchRun returns a run of n results, each with its inputs
+func benchRun(n int) *models.Run {
+	calculator := valuation.NewCalculator()
+	run := &models.Run{ID: models.NewRunID(time.Now()), StartedAt: time.Now()}
+	for i := range n {
+		price := 20 + float64(i%400)
+		data := &models.StockData{
+			Ticker:            fmt.Sprintf("T%04d", i),
+			CompanyName:       fmt.Sprintf("Company %d", i),
+			CurrentPrice:      price,
+			FCFPerShare:       price / 25,
+			EPS:               price / 20,
+			BookValue:         price / 8,
+			TangibleBookValue: price / 10,
+			Sector:            models.SectorTechnology,
+			GrowthRate:        0.08,
+			PERatio:           20,
+			MarketCap:         int64(price * 1e9),
+			SharesOutstanding: 1_000_000_000,
+			Currency:          "USD",
+		}
+		result := calculator.CalculateFairValue(data)
+		result.Inputs = data
+		run.Results = append(run.Results, result)
+	}
+	return run
+}
+
+// benchmarkWriter writes the results of a run of 1000 with a writer from
+// newWriter
+func benchmarkWriter(b *testing.B, newWriter func(io.Writer, *models.Run) (ResultWriter, error)) {
+	run := benchRun(1000)
+	b.ReportAllocs()
+	for b.Loop() {
+		writer, err := newWriter(io.Discard, run)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, result := range run.Results {
+			if err := writer.Write(result); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := writer.Close(run); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteCSV(b *testing.B) {
+	benchmarkWriter(b, NewCSVWriter)
+}
+
+func BenchmarkWriteJSON(b *testing.B) {
+	benchmarkWriter(b, NewJSONWriter)
+}
+
+func BenchmarkWriteParquet(b *testing.B) {
+	benchmarkWriter(b, func(w io.Writer, run *models.Run) (ResultWriter, error) {
+		return NewParquetWriter(w, run, true), nil
+	})
+}
@@ -0,0 +1,307 @@
+package sinks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// Sections of a run summary that can be selected with a sink's include list
+const (
+	SectionSummary        = "summary"
+	SectionTopUndervalued = "top_undervalued"
+	SectionStatusChanges  = "status_changes"
+	SectionFailures       = "failures"
+	SectionResults        = "results"
+)
+
+// DefaultSections are included when a sink does not list any
+var DefaultSections = []string{SectionSummary, SectionTopUndervalued, SectionStatusChanges, SectionFailures}
+
+// DefaultTopN is the length of the top undervalued list when a sink does
+// not set one
+const DefaultTopN = 10
+
+// RunStats counts the outcomes of a run
+type RunStats struct {
+	Total            int     `json:"total"`
+	Underpriced      int     `json:"underpriced"`
+	Overpriced       int     `json:"overpriced"`
+	Failed           int     `json:"failed"`
+	AverageUpsidePct float64 `json:"average_upside_pct"` // over underpriced stocks
+}
+
+// Pick is a stock in the top undervalued list
+type Pick struct {
+	Ticker      string  `json:"ticker"`
+	CompanyName string  `json:"company_name,omitempty"`
+	Sector      string  `json:"sector,omitempty"`
+	FairValue   float64 `json:"fair_value"`
+	Price       float64 `json:"current_price"`
+	UpsidePct   float64 `json:"upside_percentage"`
+}
+
+// StatusChange is a stock whose status differs from the previous run
+type StatusChange struct {
+	Ticker    string  `json:"ticker"`
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	UpsidePct float64 `json:"upside_percentage"`
+}
+
+// RunSummary is the payload posted when a run completes. Sections that
+// were not selected are omitted.
+type RunSummary struct {
+	Event          string                    `json:"event"`
+	RunID          string                    `json:"run_id"`
+	Version        string                    `json:"version,omitempty"`
+	ConfigHash     string                    `json:"config_hash,omitempty"`
+	Job            string                    `json:"job,omitempty"`
+	Tags           []string                  `json:"tags,omitempty"`
+	StartedAt      time.Time                 `json:"started_at"`
+	FinishedAt     time.Time                 `json:"finished_at"`
+	PricesOnly     bool                      `json:"prices_only,omitempty"`
+	Partial        bool                      `json:"partial,omitempty"`
+	Stats          *RunStats                 `json:"summary,omitempty"`
+	TopUndervalued []Pick                    `json:"top_undervalued,omitempty"`
+	StatusChanges  []StatusChange            `json:"status_changes,omitempty"`
+	Failures       map[string]string         `json:"failures,omitempty"`
+	Degraded       []string                  `json:"degraded_scrapers,omitempty"` // with the failures
+	Results        []*models.ValuationResult `json:"results,omitempty"`
+}
+
+// SummaryOptions selects what a run summary contains
+type SummaryOptions struct {
+	Sections []string // defaults to DefaultSections
+	TopN     int      // defaults to DefaultTopN
+}
+
+// includes reports whether section is selected
+func (o SummaryOptions) includes(section string) bool {
+	sections := o.Sections
+	if len(sections) == 0 {
+		sections = DefaultSections
+	}
+	for _, s := range sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// Summarize builds the summary of run selected by opts. Status changes are
+// not known from a single run; see statusTracker.
+func Summarize(run *models.Run, opts SummaryOptions) RunSummary {
+	summary := RunSummary{
+		Event:      "run.completed",
+		RunID:      run.ID,
+		Version:    run.Version,
+		ConfigHash: run.ConfigHash,
+		Job:        run.Job,
+		Tags:       run.Tags,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		PricesOnly: run.PricesOnly,
+		Partial:    run.Partial,
+	}
+
+	if opts.includes(SectionSummary) {
+		stats := &RunStats{Failed: len(run.Errors)}
+		totalUpside := 0.0
+		for _, result := range run.Results {
+			if result.Failed() {
+				continue
+			}
+			stats.Total++
+			if result.Status == models.StatusUnderpriced {
+				stats.Underpriced++
+				totalUpside += result.UpsidePercentage
+			} else {
+				stats.Overpriced++
+			}
+		}
+		if stats.Underpriced > 0 {
+			stats.AverageUpsidePct = totalUpside / float64(stats.Underpriced)
+		}
+		summary.Stats = stats
+	}
+
+	if opts.includes(SectionTopUndervalued) {
+		summary.TopUndervalued = topUndervalued(run.Results, opts.TopN)
+	}
+	if opts.includes(SectionFailures) {
+		summary.Failures = run.Errors
+		summary.Degraded = run.DegradedScrapers
+	}
+	if opts.includes(SectionResults) {
+		summary.Results = run.Results
+	}
+	return summary
+}
+
+// topUndervalued returns the n underpriced results with the highest upside
+func topUndervalued(results []*models.ValuationResult, n int) []Pick {
+	if n <= 0 {
+		n = DefaultTopN
+	}
+
+	var picks []Pick
+	for _, result := range results {
+		if result.Status != models.StatusUnderpriced {
+			continue
+		}
+		picks = append(picks, Pick{
+			Ticker:      result.Ticker,
+			CompanyName: result.CompanyName,
+			Sector:      result.Sector,
+			FairValue:   result.FairValue,
+			Price:       result.CurrentPrice,
+			UpsidePct:   result.UpsidePercentage,
+		})
+	}
+	sort.SliceStable(picks, func(i, j int) bool {
+		return picks[i].UpsidePct > picks[j].UpsidePct
+	})
+	if len(picks) > n {
+		picks = picks[:n]
+	}
+	return picks
+}
+
+// Subject returns a one-line description of the run
+func (s RunSummary) Subject() string {
+	kind := "Analysis"
+	if s.PricesOnly {
+		kind = "Price refresh"
+	}
+	if s.Partial {
+		kind = "Partial " + strings.ToLower(kind)
+	}
+	if s.Job != "" {
+		return fmt.Sprintf("%s %s (%s) finished", kind, s.RunID, s.Job)
+	}
+	return fmt.Sprintf("%s %s finished", kind, s.RunID)
+}
+
+// statusTracker remembers the status of each stock across the runs a sink
+// receives. The first run after start has no changes.
+type statusTracker struct {
+	mutex    sync.Mutex
+	previous map[string]string
+}
+
+// changes returns the stocks of run whose status differs from the last
+// run they appeared in, and records their current status
+func (t *statusTracker) changes(run *models.Run) []StatusChange {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	first := t.previous == nil
+	if first {
+		t.previous = make(map[string]string)
+	}
+
+	var changes []StatusChange
+	for _, result := range run.Results {
+		// A failed valuation says nothing about the stock's status
+		if result.Failed() {
+			continue
+		}
+		from, seen := t.previous[result.Ticker]
+		t.previous[result.Ticker] = result.Status
+		if first || !seen || from == result.Status {
+			continue
+		}
+		changes = append(changes, StatusChange{
+			Ticker:    result.Ticker,
+			From:      from,
+			To:        result.Status,
+			UpsidePct: result.UpsidePercentage,
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Ticker < changes[j].Ticker
+	})
+	return changes
+}
+
+// Text renders the summary as plain text
+func (s RunSummary) Text() string {
+	return s.render(false)
+}
+
+// Markdown renders the summary for chat services, with lists in code
+// blocks so their columns line up
+func (s RunSummary) Markdown() string {
+	return s.render(true)
+}
+
+// render renders the summary below the subject, optionally wrapping lists
+// in code blocks
+func (s RunSummary) render(codeBlocks bool) string {
+	var b strings.Builder
+	if s.Stats != nil {
+		fmt.Fprintf(&b, "%d stocks valued: %d underpriced, %d overpriced",
+			s.Stats.Total, s.Stats.Underpriced, s.Stats.Overpriced)
+		if s.Stats.Failed > 0 {
+			fmt.Fprintf(&b, ", %d failed", s.Stats.Failed)
+		}
+		b.WriteString("\n")
+		if s.Stats.Underpriced > 0 {
+			fmt.Fprintf(&b, "Average upside of underpriced stocks: %.1f%%\n", s.Stats.AverageUpsidePct)
+		}
+	}
+
+	list := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s:\n", title)
+		if codeBlocks {
+			b.WriteString("```\n")
+		}
+		for _, line := range lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+		if codeBlocks {
+			b.WriteString("```\n")
+		}
+	}
+
+	var lines []string
+	for i, pick := range s.TopUndervalued {
+		lines = append(lines, fmt.Sprintf("%2d. %-6s $%.2f -> $%.2f (%+.1f%%)",
+			i+1, pick.Ticker, pick.Price, pick.FairValue, pick.UpsidePct))
+	}
+	list("Top undervalued", lines)
+
+	lines = nil
+	for _, change := range s.StatusChanges {
+		lines = append(lines, fmt.Sprintf("%-6s %s -> %s (%+.1f%%)",
+			change.Ticker, change.From, change.To, change.UpsidePct))
+	}
+	list("Status changes", lines)
+
+	lines = nil
+	tickers := make([]string, 0, len(s.Failures))
+	for ticker := range s.Failures {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+	for _, ticker := range tickers {
+		lines = append(lines, fmt.Sprintf("%s: %s", ticker, s.Failures[ticker]))
+	}
+	list("Failures", lines)
+	list("Degraded scrapers (no values found; layout may have changed)", s.Degraded)
+
+	return b.String()
+}
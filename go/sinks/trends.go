@@ -0,0 +1,188 @@
+package sinks
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// Size of the chart of each ticker in the trends report, in pixels
+const (
+	trendWidth   = 640
+	trendHeight  = 200
+	trendPadding = 8
+)
+
+// trendPoint is the valuation of a ticker by one run
+type trendPoint struct {
+	At        time.Time
+	Price     float64
+	FairValue float64
+}
+
+// Trend is the fair value and price of a ticker over the runs that valued
+// it, as charted by the trends report
+type Trend struct {
+	Ticker      string
+	CompanyName string
+	points      []trendPoint
+}
+
+// Runs returns the number of runs that valued the ticker
+func (t *Trend) Runs() int {
+	return len(t.points)
+}
+
+// Underpriced returns the share of the runs that found the ticker
+// underpriced, in percent
+func (t *Trend) Underpriced() float64 {
+	underpriced := 0
+	for _, point := range t.points {
+		if point.FairValue > point.Price {
+			underpriced++
+		}
+	}
+	return float64(underpriced) / float64(len(t.points)) * 100
+}
+
+// Latest returns the valuation of the ticker by the most recent run
+func (t *Trend) Latest() trendPoint {
+	return t.points[len(t.points)-1]
+}
+
+// First returns the valuation of the ticker by the earliest run
+func (t *Trend) First() trendPoint {
+	return t.points[0]
+}
+
+// Trends returns the trend of each of tickers over runs, oldest run first,
+// in the order of tickers. Tickers no run valued are left out.
+func Trends(runs []*models.Run, tickers []string) []*Trend {
+	byTicker := make(map[string]*Trend, len(tickers))
+	trends := make([]*Trend, 0, len(tickers))
+	for _, ticker := range tickers {
+		if _, ok := byTicker[ticker]; !ok {
+			trend := &Trend{Ticker: ticker}
+			byTicker[ticker] = trend
+			trends = append(trends, trend)
+		}
+	}
+	for _, run := range runs {
+		for _, result := range run.Results {
+			trend, ok := byTicker[result.Ticker]
+			if !ok || result.Failed() || result.CurrentPrice <= 0 {
+				continue
+			}
+			if result.CompanyName != "" {
+				trend.CompanyName = result.CompanyName
+			}
+			trend.points = append(trend.points, trendPoint{At: run.StartedAt, Price: result.CurrentPrice, FairValue: result.FairValue})
+		}
+	}
+
+	valued := trends[:0]
+	for _, trend := range trends {
+		if len(trend.points) > 0 {
+			valued = append(valued, trend)
+		}
+	}
+	return valued
+}
+
+// chart returns the SVG polylines of the price and fair value of t, and
+// the low and high of the scale they are drawn on
+func (t *Trend) chart() (price, fairValue string, low, high float64) {
+	low, high = math.Inf(1), math.Inf(-1)
+	for _, point := range t.points {
+		low = math.Min(low, math.Min(point.Price, point.FairValue))
+		high = math.Max(high, math.Max(point.Price, point.FairValue))
+	}
+	if high == low {
+		low, high = low*0.9, high*1.1
+	}
+	first, last := t.points[0].At, t.points[len(t.points)-1].At
+
+	x := func(at time.Time) float64 {
+		if !last.After(first) {
+			return trendWidth / 2
+		}
+		return trendPadding + float64(at.Sub(first))/float64(last.Sub(first))*(trendWidth-2*trendPadding)
+	}
+	y := func(value float64) float64 {
+		return trendHeight - trendPadding - (value-low)/(high-low)*(trendHeight-2*trendPadding)
+	}
+	line := func(value func(trendPoint) float64) string {
+		coords := make([]string, len(t.points))
+		for i, point := range t.points {
+			coords[i] = strconv.FormatFloat(x(point.At), 'f', 1, 64) + "," + strconv.FormatFloat(y(value(point)), 'f', 1, 64)
+		}
+		return strings.Join(coords, " ")
+	}
+	return line(func(p trendPoint) float64 { return p.Price }), line(func(p trendPoint) float64 { return p.FairValue }), low, high
+}
+
+// trendChart is a trend as the template draws it
+type trendChart struct {
+	*Trend
+	Price, FairValue string
+	Low, High        float64
+	Upside           float64 // of the latest run
+}
+
+// trendsTemplate renders the trends report
+var trendsTemplate = template.Must(template.New("trends").Funcs(template.FuncMap{
+	"price": func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"pct":   func(v float64) string { return fmt.Sprintf("%+.1f%%", v) },
+	"date":  func(t time.Time) string { return t.Format(time.DateOnly) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Fair value trends</title>
+</head>
+<body style="font-family: Helvetica, Arial, sans-serif; font-size: 14px; color: #222;">
+<h2 style="margin-bottom: 4px;">Fair Value Trends</h2>
+<p style="margin-top: 0; color: #666;">{{.Runs}} runs{{with .Charts}} from {{date (index . 0).First.At}}{{end}}, generated {{date .Generated}}.
+<span style="color: #1a7f37;">&#9644; fair value</span> <span style="color: #555;">&#9644; price</span></p>
+{{range .Charts}}
+<h3 style="margin-bottom: 2px;">{{.Ticker}}{{if .CompanyName}} <span style="font-weight: normal; color: #666;">{{.CompanyName}}</span>{{end}}</h3>
+<p style="margin-top: 0; color: #666;">{{.Runs}} runs from {{date .First.At}} to {{date .Latest.At}}, underpriced in {{printf "%.0f" .Underpriced}}%.
+Latest: fair value {{price .Latest.FairValue}} vs price {{price .Latest.Price}}
+(<span style="color: {{if gt .Upside 0.0}}#1a7f37{{else}}#cf222e{{end}};">{{pct .Upside}}</span>).</p>
+<svg width="` + strconv.Itoa(trendWidth) + `" height="` + strconv.Itoa(trendHeight) + `" style="background: #fafafa; border: 1px solid #eee;">
+<polyline points="{{.Price}}" fill="none" stroke="#555" stroke-width="1.5"/>
+<polyline points="{{.FairValue}}" fill="none" stroke="#1a7f37" stroke-width="2"/>
+<text x="4" y="14" font-size="11" fill="#999">{{price .High}}</text>
+<text x="4" y="` + strconv.Itoa(trendHeight-4) + `" font-size="11" fill="#999">{{price .Low}}</text>
+</svg>
+{{else}}
+<p>No recorded run valued the requested tickers.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteTrendReport writes an HTML report charting the fair value of each
+// trend against the price over the runs that valued it
+func WriteTrendReport(w io.Writer, trends []*Trend, runs int, generated time.Time) error {
+	charts := make([]trendChart, len(trends))
+	for i, trend := range trends {
+		price, fairValue, low, high := trend.chart()
+		latest := trend.Latest()
+		charts[i] = trendChart{
+			Trend: trend, Price: price, FairValue: fairValue, Low: low, High: high,
+			Upside: (latest.FairValue - latest.Price) / latest.Price * 100,
+		}
+	}
+	return trendsTemplate.Execute(w, struct {
+		Runs      int
+		Generated time.Time
+		Charts    []trendChart
+	}{runs, generated, charts})
+}
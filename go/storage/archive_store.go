@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ArchiveExt is the extension of archived runs
+const ArchiveExt = ".json.gz"
+
+// ArchiveStore keeps each run as a gzip-compressed JSON file in a
+// directory, a compact archive of every run the model valued
+type ArchiveStore struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewArchiveStore creates an archive in dir
+func NewArchiveStore(dir string) (*ArchiveStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("archive directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &ArchiveStore{dir: dir}, nil
+}
+
+// Dir returns the directory backing the archive
+func (s *ArchiveStore) Dir() string {
+	return s.dir
+}
+
+// Save stores run as <id>.json.gz
+func (s *ArchiveStore) Save(run *models.Run) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path, err := s.path(run.ID)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see partial runs
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to write run: %w", err)
+	}
+	if err := WriteArchivedRun(file, run); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write run: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get loads the run with the given ID
+func (s *ArchiveStore) Get(id string) (*models.Run, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path, err := s.path(id)
+	if err != nil {
+		return nil, ErrRunNotFound
+	}
+	return readArchivedRun(path)
+}
+
+// List loads every archived run, oldest first
+func (s *ArchiveStore) List() ([]*models.Run, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+ArchiveExt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	runs := make([]*models.Run, 0, len(files))
+	for _, file := range files {
+		run, err := readArchivedRun(file)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// path returns the file path for a run, rejecting IDs that could escape the directory
+func (s *ArchiveStore) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return "", fmt.Errorf("invalid run ID %q", id)
+	}
+	return filepath.Join(s.dir, id+ArchiveExt), nil
+}
+
+// WriteArchivedRun writes run as gzip-compressed JSON, as the archive
+// stores it
+func WriteArchivedRun(w io.Writer, run *models.Run) error {
+	gz := gzip.NewWriter(w)
+	gz.Name = run.ID + ".json"
+	gz.ModTime = run.FinishedAt
+	if err := json.NewEncoder(gz).Encode(run); err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress run: %w", err)
+	}
+	return nil
+}
+
+// readArchivedRun decodes an archived run file
+func readArchivedRun(path string) (*models.Run, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run %s: %w", filepath.Base(path), err)
+	}
+	defer gz.Close()
+
+	var run models.Run
+	if err := json.NewDecoder(gz).Decode(&run); err != nil {
+		return nil, fmt.Errorf("failed to parse run %s: %w", filepath.Base(path), err)
+	}
+	return &run, nil
+}
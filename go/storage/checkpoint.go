@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// CheckpointEntry records one ticker valued during a run
+type CheckpointEntry struct {
+	Ticker   string                  `json:"ticker"`
+	Result   *models.ValuationResult `json:"result"`
+	ValuedAt time.Time               `json:"valued_at"`
+}
+
+// Checkpoint appends each valued ticker to a JSON-lines file as a run
+// progresses, so an interrupted run can be resumed
+type Checkpoint struct {
+	path  string
+	file  *os.File
+	mutex sync.Mutex
+}
+
+// OpenCheckpoint opens the checkpoint file at path. Existing entries are
+// kept when resuming and discarded otherwise.
+func OpenCheckpoint(path string, resume bool) (*Checkpoint, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+
+	return &Checkpoint{path: path, file: file}, nil
+}
+
+// LoadCheckpoint reads the entries of the checkpoint file at path, keeping
+// only those valued within maxAge of now (all entries when maxAge is zero).
+// A missing file yields no entries.
+func LoadCheckpoint(path string, maxAge time.Duration, now time.Time) (map[string]CheckpointEntry, error) {
+	entries := make(map[string]CheckpointEntry)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry CheckpointEntry
+		// A line cut short by a crash is simply skipped
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Result == nil {
+			continue
+		}
+		if maxAge > 0 && now.Sub(entry.ValuedAt) > maxAge {
+			continue
+		}
+		entries[entry.Ticker] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Path returns the location of the checkpoint file
+func (c *Checkpoint) Path() string {
+	return c.path
+}
+
+// Record appends a ticker valued at now to the checkpoint
+func (c *Checkpoint) Record(result *models.ValuationResult, now time.Time) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	data, err := json.Marshal(CheckpointEntry{
+		Ticker:   result.Ticker,
+		Result:   result,
+		ValuedAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint entry: %w", err)
+	}
+
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the checkpoint, keeping the file for a later resume
+func (c *Checkpoint) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.file.Close()
+}
+
+// Remove closes the checkpoint and deletes the file once a run completes
+func (c *Checkpoint) Remove() error {
+	if err := c.Close(); err != nil {
+		return err
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+func TestCheckpointAgesOnGivenClock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+	checkpoint, err := OpenCheckpoint(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valuedAt := time.Date(2020, time.March, 2, 21, 0, 0, 0, time.UTC)
+	for i, ticker := range []string{"AAPL", "MSFT"} {
+		result := &models.ValuationResult{Ticker: ticker, Status: models.StatusUnderpriced}
+		if err := checkpoint.Record(result, valuedAt.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := checkpoint.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := LoadCheckpoint(path, 90*time.Minute, valuedAt.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := entries["AAPL"]; ok || len(entries) != 1 {
+		t.Errorf("entries %v two hours after AAPL was valued, want only MSFT", entries)
+	}
+	if entry := entries["MSFT"]; !entry.ValuedAt.Equal(valuedAt.Add(time.Hour)) {
+		t.Errorf("MSFT valued at %s, want the time it was recorded with", entry.ValuedAt)
+	}
+
+	entries, err = LoadCheckpoint(path, 0, valuedAt.AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("%d entries without a maximum age, want 2", len(entries))
+	}
+}
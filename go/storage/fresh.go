@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// SinceStore is a RunStore that can load only the runs started after a
+// time, without reading older ones
+type SinceStore interface {
+	RunStore
+
+	// ListSince returns the runs started at or after since, oldest first
+	ListSince(since time.Time) ([]*models.Run, error)
+}
+
+// FreshInputs returns, by ticker, the latest data the recorded runs valued
+// each ticker on, for the tickers whose data was fetched at or after
+// since. Failed results have no data and are skipped.
+func FreshInputs(store RunStore, since time.Time) (map[string]*models.StockData, error) {
+	return inputsBetween(store, since, time.Time{})
+}
+
+// inputsBetween returns, by ticker, the latest data the recorded runs
+// valued each ticker on among the data fetched at or after from and, when
+// to is set, at or before to
+func inputsBetween(store RunStore, from, to time.Time) (map[string]*models.StockData, error) {
+	var runs []*models.Run
+	var err error
+	if s, ok := store.(SinceStore); ok {
+		runs, err = s.ListSince(from)
+	} else {
+		runs, err = store.List()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make(map[string]*models.StockData)
+	for _, run := range runs {
+		for _, result := range run.Results {
+			data := result.Inputs
+			if result.Failed() || data == nil || data.FetchTime.Before(from) || (!to.IsZero() && data.FetchTime.After(to)) {
+				continue
+			}
+			if latest, ok := inputs[data.Ticker]; !ok || !data.FetchTime.Before(latest.FetchTime) {
+				inputs[data.Ticker] = data
+			}
+		}
+	}
+	return inputs, nil
+}
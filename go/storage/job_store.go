@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ErrJobNotFound is returned when no job exists with the requested ID
+var ErrJobNotFound = errors.New("job not found")
+
+// JobStore keeps each analysis job as a JSON file in a directory, next to
+// the checkpoint of its valued tickers
+type JobStore struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewJobStore creates a job store in dir, using DefaultJobsDir when empty
+func NewJobStore(dir string) (*JobStore, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultJobsDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+
+	return &JobStore{dir: dir}, nil
+}
+
+// DefaultJobsDir returns the per-user directory where jobs are stored
+func DefaultJobsDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(base, "fair-stock-value", "jobs"), nil
+}
+
+// Save stores job as <id>.json
+func (s *JobStore) Save(job *models.Job) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path, err := s.path(job.ID, ".json")
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial job
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get loads the job with the given ID
+func (s *JobStore) Get(id string) (*models.Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path, err := s.path(id, ".json")
+	if err != nil {
+		return nil, ErrJobNotFound
+	}
+	return readJob(path)
+}
+
+// List loads every stored job, oldest first
+func (s *JobStore) List() ([]*models.Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	jobs := make([]*models.Job, 0, len(files))
+	for _, file := range files {
+		job, err := readJob(file)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+// CheckpointPath returns the checkpoint file recording the tickers a job
+// has valued, so a job interrupted by a restart resumes where it stopped
+func (s *JobStore) CheckpointPath(id string) (string, error) {
+	return s.path(id, ".checkpoint.jsonl")
+}
+
+// path returns the file path for a job, rejecting IDs that could escape the directory
+func (s *JobStore) path(id, ext string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return "", fmt.Errorf("invalid job ID %q", id)
+	}
+	return filepath.Join(s.dir, id+ext), nil
+}
+
+// readJob decodes a job file
+func readJob(path string) (*models.Job, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job: %w", err)
+	}
+
+	var job models.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job %s: %w", filepath.Base(path), err)
+	}
+	return &job, nil
+}
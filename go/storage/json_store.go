@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// JSONStore keeps each run as a JSON file in a directory
+type JSONStore struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewJSONStore creates a run store in dir, using DefaultRunsDir when empty
+func NewJSONStore(dir string) (*JSONStore, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultRunsDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create runs directory: %w", err)
+	}
+
+	return &JSONStore{dir: dir}, nil
+}
+
+// DefaultRunsDir returns the per-user directory where runs are stored
+func DefaultRunsDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(base, "fair-stock-value", "runs"), nil
+}
+
+// Dir returns the directory backing the store
+func (s *JSONStore) Dir() string {
+	return s.dir
+}
+
+// Save stores run as <id>.json
+func (s *JSONStore) Save(run *models.Run) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path, err := s.path(run.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see partial runs
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write run: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get loads the run with the given ID
+func (s *JSONStore) Get(id string) (*models.Run, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path, err := s.path(id)
+	if err != nil {
+		// An ID that cannot name a file cannot name a stored run either
+		return nil, ErrRunNotFound
+	}
+
+	return readRun(path)
+}
+
+// List loads every stored run, oldest first
+func (s *JSONStore) List() ([]*models.Run, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	runs := make([]*models.Run, 0, len(files))
+	for _, file := range files {
+		run, err := readRun(file)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// path returns the file path for a run, rejecting IDs that could escape the directory
+func (s *JSONStore) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return "", fmt.Errorf("invalid run ID %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// readRun decodes a run file
+func readRun(path string) (*models.Run, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run: %w", err)
+	}
+
+	var run models.Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse run %s: %w", filepath.Base(path), err)
+	}
+	return &run, nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// JSONLinesStore keeps runs as lines of a single file, the format written
+// by the jsonl sink
+type JSONLinesStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewJSONLinesStore creates a run store backed by the file at path
+func NewJSONLinesStore(path string) *JSONLinesStore {
+	return &JSONLinesStore{path: path}
+}
+
+// Save appends run to the file. A later line with the same ID supersedes
+// earlier ones.
+func (s *JSONLinesStore) Save(run *models.Run) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", s.path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Get returns the last run in the file with the given ID
+func (s *JSONLinesStore) Get(id string) (*models.Run, error) {
+	runs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if run.ID == id {
+			return run, nil
+		}
+	}
+	return nil, ErrRunNotFound
+}
+
+// List loads every run in the file, oldest first. A missing file holds no
+// runs.
+func (s *JSONLinesStore) List() ([]*models.Run, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", s.path, err)
+	}
+	defer file.Close()
+
+	byID := make(map[string]*models.Run)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var run models.Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("failed to parse %s line %d: %w", s.path, line, err)
+		}
+		byID[run.ID] = &run
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+
+	runs := make([]*models.Run, 0, len(byID))
+	for _, run := range byID {
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+	return runs, nil
+}
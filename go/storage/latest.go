@@ -0,0 +1,32 @@
+package storage
+
+import "github.com/lesnerd/fair-stock-value/go/models"
+
+// LatestStore is a RunStore that can load its latest full run without
+// reading the others
+type LatestStore interface {
+	RunStore
+
+	// Latest returns the latest stored run that is not a prices-only
+	// refresh, or ErrRunNotFound when there is none
+	Latest() (*models.Run, error)
+}
+
+// LatestRun returns the latest run in store that valued fetched
+// fundamentals rather than only refreshing prices, or ErrRunNotFound when
+// there is none
+func LatestRun(store RunStore) (*models.Run, error) {
+	if s, ok := store.(LatestStore); ok {
+		return s.Latest()
+	}
+	runs, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if !runs[i].PricesOnly {
+			return runs[i], nil
+		}
+	}
+	return nil, ErrRunNotFound
+}
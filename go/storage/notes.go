@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ErrNoteNotFound is returned when a note ID is not in the store
+var ErrNoteNotFound = errors.New("note not found")
+
+// NoteStore keeps the notes on tickers of the research journal
+type NoteStore interface {
+	// AddNote stores note, setting its ID
+	AddNote(note *models.Note) error
+	// Notes returns the notes on ticker, or on every ticker when it is
+	// empty, oldest first
+	Notes(ticker string) ([]models.Note, error)
+	// DeleteNote removes a note, or returns ErrNoteNotFound
+	DeleteNote(id int64) error
+}
+
+// AnnotateResults sets the note of each result to the latest of the notes
+// kept on its ticker. Results of tickers without notes are left as they are.
+func AnnotateResults(store NoteStore, results []*models.ValuationResult) error {
+	notes, err := store.Notes("")
+	if err != nil {
+		return err
+	}
+	byTicker := make(map[string][]models.Note)
+	for _, note := range notes {
+		byTicker[note.Ticker] = append(byTicker[note.Ticker], note)
+	}
+	for _, result := range results {
+		if note := models.LatestNote(byTicker[result.Ticker]); note != nil {
+			result.Note = note
+		}
+	}
+	return nil
+}
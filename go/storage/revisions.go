@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ReviseEstimates sets the estimate revision of each result of run: how
+// its growth estimate moved since the recorded runs valued the ticker on
+// data fetched between one and two windows before the run started. Results
+// without such data, such as those of tickers new to the history, are left
+// without a revision.
+func ReviseEstimates(store RunStore, run *models.Run, window time.Duration) error {
+	to := run.StartedAt.Add(-window)
+	previous, err := inputsBetween(store, to.Add(-window), to)
+	if err != nil {
+		return err
+	}
+	for _, result := range run.Results {
+		if data, ok := previous[result.Ticker]; ok && !result.Failed() {
+			result.Revision = models.NewEstimateRevision(result, data)
+		}
+	}
+	return nil
+}
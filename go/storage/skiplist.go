@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// SkipEntry records the failures of a ticker in consecutive runs
+type SkipEntry struct {
+	Ticker      string    `json:"ticker"`
+	Failures    int       `json:"failures"` // runs in a row the ticker failed in
+	Cause       string    `json:"cause"`    // of the latest failure, such as "symbol not found"
+	Error       string    `json:"error"`
+	FirstFailed time.Time `json:"first_failed"`
+	LastFailed  time.Time `json:"last_failed"`
+	Until       time.Time `json:"until,omitempty"` // skipped until, zero while it is not
+}
+
+// SkipList keeps the tickers that failed the last few runs, such as
+// delisted or renamed ones, in a JSON file, so runs can leave them out for
+// a while instead of fetching them every time. A ticker that fails again
+// once its time is up is skipped again straight away; one that is valued
+// is dropped from the list.
+type SkipList struct {
+	path      string
+	threshold int           // failures in a row before a ticker is skipped
+	duration  time.Duration // how long it is skipped for
+	entries   map[string]*SkipEntry
+}
+
+// LoadSkipList reads the skip list at path; a missing file is an empty
+// list. Tickers are skipped for duration after failing threshold runs in
+// a row.
+func LoadSkipList(path string, threshold int, duration time.Duration) (*SkipList, error) {
+	list := &SkipList{path: path, threshold: threshold, duration: duration, entries: make(map[string]*SkipEntry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skip list: %w", err)
+	}
+	var entries []*SkipEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse skip list %s: %w", path, err)
+	}
+	for _, entry := range entries {
+		list.entries[entry.Ticker] = entry
+	}
+	return list, nil
+}
+
+// Path returns the file the list is kept in
+func (l *SkipList) Path() string {
+	return l.path
+}
+
+// Skipped reports whether ticker is to be left out of runs at now
+func (l *SkipList) Skipped(ticker string, now time.Time) bool {
+	entry, ok := l.entries[ticker]
+	return ok && now.Before(entry.Until)
+}
+
+// Failed records that ticker failed a run at now for a reason that
+// retrying will not fix, and reports whether that put it on the list
+func (l *SkipList) Failed(ticker, cause, message string, now time.Time) bool {
+	entry, ok := l.entries[ticker]
+	if !ok {
+		entry = &SkipEntry{Ticker: ticker, FirstFailed: now}
+		l.entries[ticker] = entry
+	}
+	entry.Failures++
+	entry.Cause, entry.Error, entry.LastFailed = cause, message, now
+	if entry.Failures < l.threshold {
+		return false
+	}
+	entry.Until = now.Add(l.duration)
+	return true
+}
+
+// Succeeded records that ticker was valued, dropping it from the list
+func (l *SkipList) Succeeded(ticker string) {
+	delete(l.entries, ticker)
+}
+
+// Remove drops tickers from the list, or every ticker when none are given,
+// returning how many were dropped
+func (l *SkipList) Remove(tickers ...string) int {
+	if len(tickers) == 0 {
+		n := len(l.entries)
+		clear(l.entries)
+		return n
+	}
+	n := 0
+	for _, ticker := range tickers {
+		if _, ok := l.entries[ticker]; ok {
+			delete(l.entries, ticker)
+			n++
+		}
+	}
+	return n
+}
+
+// Entries returns the tickers that failed at least one run in a row,
+// skipped or not, sorted by ticker
+func (l *SkipList) Entries() []SkipEntry {
+	entries := make([]SkipEntry, 0, len(l.entries))
+	for _, entry := range l.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Ticker < entries[j].Ticker })
+	return entries
+}
+
+// Save writes the list to its file
+func (l *SkipList) Save() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create skip list directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(l.Entries(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode skip list: %w", err)
+	}
+
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write skip list: %w", err)
+	}
+	return os.Rename(tmp, l.path)
+}
@@ -0,0 +1,517 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // registers the "sqlite3" driver
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// sqliteTimeFormat stores times in UTC at a fixed width, so they sort
+// correctly as text
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// sqliteSchemaVersion is recorded in PRAGMA user_version for migrations
+const sqliteSchemaVersion = 4
+
+// sqliteSchema creates the tables of a history database. Key result
+// fields have their own columns for ad-hoc queries; the full result is
+// kept as JSON so runs load back unchanged.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          TEXT PRIMARY KEY,
+	started_at  TEXT NOT NULL,
+	finished_at TEXT NOT NULL,
+	prices_only INTEGER NOT NULL DEFAULT 0,
+	partial     INTEGER NOT NULL DEFAULT 0,
+	job         TEXT NOT NULL DEFAULT '',
+	version     TEXT NOT NULL DEFAULT '',
+	config_hash TEXT NOT NULL DEFAULT '',
+	config      TEXT,
+	errors      TEXT
+);
+CREATE INDEX IF NOT EXISTS runs_started_at ON runs (started_at);
+
+CREATE TABLE IF NOT EXISTS results (
+	run_id        TEXT NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+	position      INTEGER NOT NULL,
+	ticker        TEXT NOT NULL,
+	status        TEXT NOT NULL,
+	current_price REAL NOT NULL,
+	fair_value    REAL NOT NULL,
+	upside_pct    REAL NOT NULL,
+	result        TEXT NOT NULL,
+	PRIMARY KEY (run_id, position)
+);
+CREATE INDEX IF NOT EXISTS results_ticker ON results (ticker, run_id);
+` + sqliteRunTags + sqliteNotes
+
+// sqliteRunTags creates the table of run tags, one row per tag of a run,
+// in the order the run lists them
+const sqliteRunTags = `
+CREATE TABLE IF NOT EXISTS run_tags (
+	run_id TEXT NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+	tag    TEXT NOT NULL,
+	PRIMARY KEY (run_id, tag)
+);
+CREATE INDEX IF NOT EXISTS run_tags_tag ON run_tags (tag, run_id);
+`
+
+// sqliteNotes creates the table of the notes kept on tickers, which are
+// not part of any run
+const sqliteNotes = `
+CREATE TABLE IF NOT EXISTS notes (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	ticker     TEXT NOT NULL,
+	text       TEXT NOT NULL DEFAULT '',
+	rating     INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS notes_ticker ON notes (ticker, id);
+`
+
+// sqliteMigrations upgrade databases of earlier schema versions; entry i
+// upgrades version i+1 to i+2
+var sqliteMigrations = []string{
+	`ALTER TABLE runs ADD COLUMN version TEXT NOT NULL DEFAULT '';
+	ALTER TABLE runs ADD COLUMN config_hash TEXT NOT NULL DEFAULT '';`,
+	sqliteRunTags,
+	sqliteNotes,
+}
+
+// SQLiteStore keeps runs in a SQLite database, one row per run and one
+// per ticker. Several processes may share the database.
+type SQLiteStore struct {
+	db   *sql.DB
+	path string
+}
+
+// NewSQLiteStore opens the database at path, creating it and its schema
+// when missing
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	// WAL and a busy timeout let an API server and CLI runs write at once
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL"
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read history schema version: %w", err)
+	}
+	if version > sqliteSchemaVersion {
+		db.Close()
+		return nil, fmt.Errorf("history database %s has schema version %d, newer than this build supports", path, version)
+	}
+	for ; version > 0 && version < sqliteSchemaVersion; version++ {
+		if _, err := db.Exec(sqliteMigrations[version-1]); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to upgrade history schema in %s: %w", path, err)
+		}
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create history schema in %s: %w", path, err)
+	}
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to set history schema version: %w", err)
+	}
+
+	return &SQLiteStore{db: db, path: path}, nil
+}
+
+// Path returns the location of the database
+func (s *SQLiteStore) Path() string {
+	return s.path
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Empty reports whether the database holds no runs
+func (s *SQLiteStore) Empty() (bool, error) {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM runs").Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to count runs: %w", err)
+	}
+	return count == 0, nil
+}
+
+// Save stores run and its results in one transaction, replacing any
+// previous run with the same ID
+func (s *SQLiteStore) Save(run *models.Run) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM results WHERE run_id = ?", run.ID); err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+	if err := saveRunRow(tx, run); err != nil {
+		return err
+	}
+	if err := insertResults(tx, run.ID, 0, run.Results); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+	return nil
+}
+
+// saveRunRow inserts or updates the runs row of run
+func saveRunRow(tx *sql.Tx, run *models.Run) error {
+	var errorsJSON []byte
+	if len(run.Errors) > 0 {
+		var err error
+		if errorsJSON, err = json.Marshal(run.Errors); err != nil {
+			return fmt.Errorf("failed to encode run errors: %w", err)
+		}
+	}
+
+	// An upsert rather than INSERT OR REPLACE, which would delete the
+	// results recorded so far along with the old row
+	_, err := tx.Exec(`INSERT INTO runs
+		(id, started_at, finished_at, prices_only, partial, job, version, config_hash, config, errors)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			started_at = excluded.started_at, finished_at = excluded.finished_at,
+			prices_only = excluded.prices_only, partial = excluded.partial, job = excluded.job,
+			version = excluded.version, config_hash = excluded.config_hash,
+			config = excluded.config, errors = excluded.errors`,
+		run.ID, formatTime(run.StartedAt), formatTime(run.FinishedAt),
+		run.PricesOnly, run.Partial, run.Job, run.Version, run.ConfigHash,
+		nullableText(run.Config), nullableText(errorsJSON))
+	if err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM run_tags WHERE run_id = ?", run.ID); err != nil {
+		return fmt.Errorf("failed to save run tags: %w", err)
+	}
+	for _, tag := range run.Tags {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO run_tags (run_id, tag) VALUES (?, ?)", run.ID, tag); err != nil {
+			return fmt.Errorf("failed to save run tags: %w", err)
+		}
+	}
+	return nil
+}
+
+// insertResults adds results to the run with the given ID, numbering them
+// from position first
+func insertResults(tx *sql.Tx, runID string, first int, results []*models.ValuationResult) error {
+	insert, err := tx.Prepare(`INSERT INTO results
+		(run_id, position, ticker, status, current_price, fair_value, upside_pct, result)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+	defer insert.Close()
+
+	for i, result := range results {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to encode result for %s: %w", result.Ticker, err)
+		}
+		_, err = insert.Exec(runID, first+i, result.Ticker, result.Status,
+			result.CurrentPrice, result.FairValue, result.UpsidePercentage, string(data))
+		if err != nil {
+			return fmt.Errorf("failed to save result for %s: %w", result.Ticker, err)
+		}
+	}
+	return nil
+}
+
+// sqliteRecordBatch is the number of results a recorder commits at once
+const sqliteRecordBatch = 100
+
+// sqliteRecorder records a run's results in batches as they complete
+type sqliteRecorder struct {
+	store   *SQLiteStore
+	runID   string
+	next    int // position of the first pending result
+	pending []*models.ValuationResult
+}
+
+// Start stores run, with its results so far, as partial and returns a
+// recorder committing the rest of its results in batches. A run that is
+// never finished stays recorded as partial with the results committed.
+func (s *SQLiteStore) Start(run *models.Run) (RunRecorder, error) {
+	started := *run
+	started.Partial = true
+	started.FinishedAt = run.StartedAt
+	if err := s.Save(&started); err != nil {
+		return nil, err
+	}
+	return &sqliteRecorder{store: s, runID: run.ID, next: len(run.Results)}, nil
+}
+
+// Record adds result, committing once a batch is complete
+func (r *sqliteRecorder) Record(result *models.ValuationResult) error {
+	r.pending = append(r.pending, result)
+	if len(r.pending) < sqliteRecordBatch {
+		return nil
+	}
+	return r.flush(nil)
+}
+
+// Finish commits the remaining results together with the final state of run
+func (r *sqliteRecorder) Finish(run *models.Run) error {
+	return r.flush(run)
+}
+
+// flush commits the pending results and, when run is set, its runs row
+func (r *sqliteRecorder) flush(run *models.Run) error {
+	tx, err := r.store.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertResults(tx, r.runID, r.next, r.pending); err != nil {
+		return err
+	}
+	if run != nil {
+		if err := saveRunRow(tx, run); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+
+	r.next += len(r.pending)
+	r.pending = r.pending[:0]
+	return nil
+}
+
+// Get loads the run with the given ID
+func (s *SQLiteStore) Get(id string) (*models.Run, error) {
+	runs, err := s.query("WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, ErrRunNotFound
+	}
+	return runs[0], nil
+}
+
+// List loads every stored run, oldest first
+func (s *SQLiteStore) List() ([]*models.Run, error) {
+	return s.query("")
+}
+
+// ListSince loads the runs started at or after since, oldest first
+func (s *SQLiteStore) ListSince(since time.Time) ([]*models.Run, error) {
+	return s.query("WHERE started_at >= ?", formatTime(since))
+}
+
+// ListTagged loads the runs tagged with tag, oldest first
+func (s *SQLiteStore) ListTagged(tag string) ([]*models.Run, error) {
+	return s.query("WHERE id IN (SELECT run_id FROM run_tags WHERE tag = ?)", tag)
+}
+
+// Latest loads the latest run that is not a prices-only refresh
+func (s *SQLiteStore) Latest() (*models.Run, error) {
+	runs, err := s.query(`WHERE id = (SELECT id FROM runs WHERE NOT prices_only
+		ORDER BY started_at DESC LIMIT 1)`)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, ErrRunNotFound
+	}
+	return runs[0], nil
+}
+
+// query loads the runs matching where, oldest first, with their results
+func (s *SQLiteStore) query(where string, args ...interface{}) ([]*models.Run, error) {
+	rows, err := s.db.Query(`SELECT id, started_at, finished_at, prices_only, partial, job, version, config_hash, config, errors
+		FROM runs `+where+` ORDER BY started_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*models.Run, 0)
+	byID := make(map[string]*models.Run)
+	for rows.Next() {
+		var run models.Run
+		var startedAt, finishedAt string
+		var config, errorsJSON sql.NullString
+		if err := rows.Scan(&run.ID, &startedAt, &finishedAt, &run.PricesOnly, &run.Partial, &run.Job, &run.Version, &run.ConfigHash, &config, &errorsJSON); err != nil {
+			return nil, fmt.Errorf("failed to read run: %w", err)
+		}
+		if run.StartedAt, err = time.Parse(time.RFC3339Nano, startedAt); err != nil {
+			return nil, fmt.Errorf("failed to read run %s: %w", run.ID, err)
+		}
+		if run.FinishedAt, err = time.Parse(time.RFC3339Nano, finishedAt); err != nil {
+			return nil, fmt.Errorf("failed to read run %s: %w", run.ID, err)
+		}
+		if config.Valid {
+			run.Config = json.RawMessage(config.String)
+		}
+		if errorsJSON.Valid {
+			if err := json.Unmarshal([]byte(errorsJSON.String), &run.Errors); err != nil {
+				return nil, fmt.Errorf("failed to parse errors of run %s: %w", run.ID, err)
+			}
+		}
+		run.Results = []*models.ValuationResult{}
+		runs = append(runs, &run)
+		byID[run.ID] = &run
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	if len(runs) == 0 {
+		return runs, nil
+	}
+
+	tags, err := s.db.Query(`SELECT run_id, tag FROM run_tags
+		WHERE run_id IN (SELECT id FROM runs `+where+`) ORDER BY run_id, rowid`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load run tags: %w", err)
+	}
+	defer tags.Close()
+	for tags.Next() {
+		var runID, tag string
+		if err := tags.Scan(&runID, &tag); err != nil {
+			return nil, fmt.Errorf("failed to read run tag: %w", err)
+		}
+		if run, ok := byID[runID]; ok {
+			run.Tags = append(run.Tags, tag)
+		}
+	}
+	if err := tags.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load run tags: %w", err)
+	}
+
+	results, err := s.db.Query(`SELECT run_id, result FROM results
+		WHERE run_id IN (SELECT id FROM runs `+where+`) ORDER BY run_id, position`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load results: %w", err)
+	}
+	defer results.Close()
+
+	for results.Next() {
+		var runID, data string
+		if err := results.Scan(&runID, &data); err != nil {
+			return nil, fmt.Errorf("failed to read result: %w", err)
+		}
+		var result models.ValuationResult
+		if err := json.Unmarshal([]byte(data), &result); err != nil {
+			return nil, fmt.Errorf("failed to parse result of run %s: %w", runID, err)
+		}
+		if run, ok := byID[runID]; ok {
+			run.Results = append(run.Results, &result)
+		}
+	}
+	if err := results.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load results: %w", err)
+	}
+	return runs, nil
+}
+
+// AddNote stores note, setting its ID
+func (s *SQLiteStore) AddNote(note *models.Note) error {
+	res, err := s.db.Exec("INSERT INTO notes (ticker, text, rating, created_at) VALUES (?, ?, ?, ?)",
+		note.Ticker, note.Text, note.Rating, formatTime(note.At))
+	if err != nil {
+		return fmt.Errorf("failed to save note: %w", err)
+	}
+	if note.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to save note: %w", err)
+	}
+	return nil
+}
+
+// Notes loads the notes on ticker, or on every ticker when it is empty,
+// oldest first
+func (s *SQLiteStore) Notes(ticker string) ([]models.Note, error) {
+	rows, err := s.db.Query(`SELECT id, ticker, text, rating, created_at FROM notes
+		WHERE ? = '' OR ticker = ? ORDER BY id`, ticker, ticker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []models.Note
+	for rows.Next() {
+		var note models.Note
+		var at string
+		if err := rows.Scan(&note.ID, &note.Ticker, &note.Text, &note.Rating, &at); err != nil {
+			return nil, fmt.Errorf("failed to read note: %w", err)
+		}
+		if note.At, err = time.Parse(time.RFC3339Nano, at); err != nil {
+			return nil, fmt.Errorf("failed to read note %d: %w", note.ID, err)
+		}
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+	return notes, nil
+}
+
+// DeleteNote removes the note with the given ID, or returns
+// ErrNoteNotFound
+func (s *SQLiteStore) DeleteNote(id int64) error {
+	res, err := s.db.Exec("DELETE FROM notes WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("%w: %d", ErrNoteNotFound, id)
+	}
+	return nil
+}
+
+// formatTime formats t for storage
+func formatTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+// nullableText stores empty data as NULL
+func nullableText(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+// ImportRuns copies every run of src into dst, returning how many were
+// copied
+func ImportRuns(dst, src RunStore) (int, error) {
+	runs, err := src.List()
+	if err != nil {
+		return 0, err
+	}
+	for i, run := range runs {
+		if err := dst.Save(run); err != nil {
+			return i, err
+		}
+	}
+	return len(runs), nil
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ErrRunNotFound is returned when no run exists with the requested ID
+var ErrRunNotFound = errors.New("run not found")
+
+// RunStore persists completed analysis runs
+type RunStore interface {
+	// Save stores run, replacing any previous run with the same ID
+	Save(run *models.Run) error
+
+	// Get returns the run with the given ID or ErrRunNotFound
+	Get(id string) (*models.Run, error)
+
+	// List returns all stored runs, oldest first
+	List() ([]*models.Run, error)
+}
+
+// StreamingStore is a RunStore that can record a run while it is in
+// progress, so a run over a large universe is stored without holding all
+// of its results
+type StreamingStore interface {
+	RunStore
+
+	// Start stores run, with its results so far, as partial and returns a
+	// recorder for the rest of its results
+	Start(run *models.Run) (RunRecorder, error)
+}
+
+// RunRecorder records the results of a run started with
+// StreamingStore.Start as they complete
+type RunRecorder interface {
+	// Record adds a result to the run
+	Record(result *models.ValuationResult) error
+
+	// Finish records the final state of run, such as its finish time and
+	// errors; run's results are not recorded again
+	Finish(run *models.Run) error
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// TaggedStore is a RunStore that can load only the runs with a tag,
+// without reading the others
+type TaggedStore interface {
+	RunStore
+
+	// ListTagged returns the runs tagged with tag, oldest first
+	ListTagged(tag string) ([]*models.Run, error)
+}
+
+// ListTagged returns the runs of store tagged with every one of tags,
+// oldest first, or every run when tags is empty
+func ListTagged(store RunStore, tags []string) ([]*models.Run, error) {
+	var runs []*models.Run
+	var err error
+	if s, ok := store.(TaggedStore); ok && len(tags) > 0 {
+		runs, err = s.ListTagged(tags[0])
+	} else {
+		runs, err = store.List()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tagged := runs[:0]
+	for _, run := range runs {
+		if run.HasTags(tags) {
+			tagged = append(tagged, run)
+		}
+	}
+	return tagged, nil
+}
+
+// TagSummary sums up the recorded runs of a tag
+type TagSummary struct {
+	Tag        string    `json:"tag"` // empty for runs without tags
+	Runs       int       `json:"runs"`
+	PricesOnly int       `json:"prices_only"` // of the runs, price refreshes
+	Valued     int       `json:"valued"`      // tickers valued over all of the runs
+	First      time.Time `json:"first"`       // start of the earliest run
+	Last       time.Time `json:"last"`        // start of the latest run
+}
+
+// SummarizeTags sums up runs by tag, counting a run under each of its
+// tags, or under the empty tag when it has none. Tags are sorted by name,
+// the untagged runs last.
+func SummarizeTags(runs []*models.Run) []TagSummary {
+	byTag := make(map[string]*TagSummary)
+	add := func(tag string, run *models.Run) {
+		summary, ok := byTag[tag]
+		if !ok {
+			summary = &TagSummary{Tag: tag, First: run.StartedAt, Last: run.StartedAt}
+			byTag[tag] = summary
+		}
+		summary.Runs++
+		if run.PricesOnly {
+			summary.PricesOnly++
+		}
+		summary.Valued += run.Valued()
+		if run.StartedAt.Before(summary.First) {
+			summary.First = run.StartedAt
+		}
+		if run.StartedAt.After(summary.Last) {
+			summary.Last = run.StartedAt
+		}
+	}
+	for _, run := range runs {
+		if len(run.Tags) == 0 {
+			add("", run)
+		}
+		for _, tag := range run.Tags {
+			add(tag, run)
+		}
+	}
+
+	summaries := make([]TagSummary, 0, len(byTag))
+	for _, summary := range byTag {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if (summaries[i].Tag == "") != (summaries[j].Tag == "") {
+			return summaries[j].Tag == ""
+		}
+		return summaries[i].Tag < summaries[j].Tag
+	})
+	return summaries
+}
@@ -0,0 +1,100 @@
+package symbols
+
+import "strings"
+
+// Kinds of security identifiers, named as the OpenFIGI API names them
+const (
+	ISIN  = "ID_ISIN"
+	CUSIP = "ID_CUSIP"
+)
+
+// IdentifierKind returns ISIN or CUSIP when id is a valid identifier of
+// that kind, check digit included, or "" for anything else, such as a
+// ticker
+func IdentifierKind(id string) string {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	switch {
+	case isISIN(id):
+		return ISIN
+	case isCUSIP(id):
+		return CUSIP
+	}
+	return ""
+}
+
+// IsIdentifier reports whether id is an ISIN or CUSIP rather than a ticker
+func IsIdentifier(id string) bool {
+	return IdentifierKind(id) != ""
+}
+
+// isISIN reports whether id is an ISIN: a country code, nine characters
+// and a Luhn check digit over the digits the letters stand for
+func isISIN(id string) bool {
+	if len(id) != 12 || !isLetter(id[0]) || !isLetter(id[1]) || !isDigit(id[11]) {
+		return false
+	}
+	var digits []int
+	for i := 0; i < len(id); i++ {
+		value, ok := charValue(id[i])
+		if !ok || value > 35 {
+			return false
+		}
+		if value >= 10 {
+			digits = append(digits, value/10)
+		}
+		digits = append(digits, value%10)
+	}
+
+	sum := 0
+	for i := range digits {
+		digit := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+// isCUSIP reports whether id is a CUSIP: eight characters and a check
+// digit over them
+func isCUSIP(id string) bool {
+	if len(id) != 9 || !isDigit(id[8]) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 8; i++ {
+		value, ok := charValue(id[i])
+		if !ok {
+			return false
+		}
+		if i%2 == 1 {
+			value *= 2
+		}
+		sum += value/10 + value%10
+	}
+	return int(id[8]-'0') == (10-sum%10)%10
+}
+
+// charValue returns the value of a character of an identifier: digits are
+// themselves, letters 10 to 35 and the CUSIP characters *, @ and # 36 to 38
+func charValue(c byte) (int, bool) {
+	switch {
+	case isDigit(c):
+		return int(c - '0'), true
+	case isLetter(c):
+		return int(c-'A') + 10, true
+	case c == '*':
+		return 36, true
+	case c == '@':
+		return 37, true
+	case c == '#':
+		return 38, true
+	}
+	return 0, false
+}
+
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isLetter(c byte) bool { return c >= 'A' && c <= 'Z' }
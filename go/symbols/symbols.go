@@ -0,0 +1,183 @@
+// Package symbols translates tickers between the notations of the data
+// sources. The same share class is BRK-B on Yahoo Finance, Finviz and
+// EDGAR, BRK.B on most exchange feeds, paid APIs and sites such as
+// MarketWatch and Zacks, and BRK/B on Bloomberg. Tickers are kept in one
+// canonical notation, Yahoo's, and translated for each source they are
+// fetched from.
+package symbols
+
+import (
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// Notation is a way of writing share classes, units and warrants
+type Notation int
+
+const (
+	// Dash writes BRK-B, units as XYZ-UN and warrants as XYZ-WT, as Yahoo
+	// Finance, Finviz and EDGAR do. It is the canonical notation.
+	Dash Notation = iota
+	// Dot writes BRK.B, units as XYZ.U and warrants as XYZ.WS
+	Dot
+	// Slash writes BRK/B, units as XYZ/U and warrants as XYZ/WS
+	Slash
+)
+
+// separators are the separators of the notations
+var separators = map[Notation]string{Dash: "-", Dot: ".", Slash: "/"}
+
+// Kind is the kind of security a symbol names
+type Kind int
+
+const (
+	// Share is a common or class share
+	Share Kind = iota
+	// Unit is a unit, such as that of a SPAC, of a share and warrants
+	Unit
+	// Warrant is a warrant to buy shares
+	Warrant
+)
+
+// kindSuffixes are the suffixes of units and warrants in each notation
+var kindSuffixes = map[Kind]map[Notation]string{
+	Unit:    {Dash: "UN", Dot: "U", Slash: "U"},
+	Warrant: {Dash: "WT", Dot: "WS", Slash: "WS"},
+}
+
+// suffixKinds maps the suffixes of every notation to their kind
+var suffixKinds = map[string]Kind{
+	"U": Unit, "UN": Unit,
+	"W": Warrant, "WS": Warrant, "WT": Warrant,
+}
+
+// exchangeSuffixes are the suffixes Yahoo Finance gives tickers listed
+// outside the US, such as SHOP.TO, which are not share classes
+var exchangeSuffixes = map[string]bool{
+	"AS": true, "AX": true, "BO": true, "BR": true, "CO": true, "DE": true,
+	"F": true, "HE": true, "HK": true, "IR": true, "JK": true, "KS": true,
+	"L": true, "LS": true, "MC": true, "MI": true, "MX": true, "NE": true,
+	"NS": true, "NZ": true, "OL": true, "PA": true, "SA": true, "SI": true,
+	"SS": true, "ST": true, "SW": true, "SZ": true, "T": true, "TO": true,
+	"TW": true, "V": true, "VI": true,
+}
+
+// Symbol is a ticker taken apart into the parts notations write differently
+type Symbol struct {
+	Root     string // such as "BRK"
+	Class    string // share class, such as "B", or empty
+	Kind     Kind
+	Exchange string // Yahoo suffix of a non-US listing, such as "TO", or empty
+}
+
+// Parse takes ticker apart in any notation. Suffixes it does not know,
+// such as those of preferred shares or currency pairs like BTC-USD, are
+// left in the root, so the ticker is written the same in every notation.
+func Parse(ticker string) Symbol {
+	ticker = strings.ToUpper(strings.TrimSpace(ticker))
+
+	var symbol Symbol
+	if i := strings.LastIndex(ticker, "."); i > 0 && exchangeSuffixes[ticker[i+1:]] {
+		symbol.Exchange = ticker[i+1:]
+		ticker = ticker[:i]
+	}
+
+	i := strings.IndexAny(ticker, "-./ ")
+	if i <= 0 {
+		symbol.Root = ticker
+		return symbol
+	}
+	root, suffix := ticker[:i], ticker[i+1:]
+	if kind, ok := suffixKinds[suffix]; ok {
+		symbol.Root, symbol.Kind = root, kind
+	} else if len(suffix) == 1 && suffix[0] >= 'A' && suffix[0] <= 'Z' {
+		symbol.Root, symbol.Class = root, suffix
+	} else {
+		symbol.Root = ticker
+	}
+	return symbol
+}
+
+// Format writes s in notation
+func (s Symbol) Format(notation Notation) string {
+	ticker := s.Root
+	switch {
+	case s.Kind != Share:
+		ticker += separators[notation] + kindSuffixes[s.Kind][notation]
+	case s.Class != "":
+		ticker += separators[notation] + s.Class
+	}
+	if s.Exchange != "" {
+		ticker += "." + s.Exchange
+	}
+	return ticker
+}
+
+// String writes s in the canonical notation
+func (s Symbol) String() string {
+	return s.Format(Dash)
+}
+
+// Format writes ticker, given in any notation, in notation
+func Format(ticker string, notation Notation) string {
+	return Parse(ticker).Format(notation)
+}
+
+// DefaultAliases map well-known tickers written without a separator to
+// their canonical notation. Such tickers cannot be told apart from plain
+// ones by their form.
+var DefaultAliases = map[string]string{
+	"BRKA": "BRK-A",
+	"BRKB": "BRK-B",
+}
+
+// Mapper translates tickers to the canonical notation, resolving aliases
+// first
+type Mapper struct {
+	aliases map[string]string
+}
+
+// NewMapper creates a mapper resolving DefaultAliases and aliases, which
+// take precedence. Aliases are matched case-insensitively.
+func NewMapper(aliases map[string]string) *Mapper {
+	m := &Mapper{aliases: make(map[string]string, len(DefaultAliases)+len(aliases))}
+	for _, table := range []map[string]string{DefaultAliases, aliases} {
+		for alias, ticker := range table {
+			m.aliases[strings.ToUpper(strings.TrimSpace(alias))] = ticker
+		}
+	}
+	return m
+}
+
+// Canonical returns ticker in the canonical notation
+func (m *Mapper) Canonical(ticker string) string {
+	if target, ok := m.aliases[strings.ToUpper(strings.TrimSpace(ticker))]; ok {
+		ticker = target
+	}
+	return Parse(ticker).String()
+}
+
+// pairCurrencies are the quote currencies of the cryptocurrency pairs
+// Yahoo Finance lists, such as BTC-USD
+var pairCurrencies = map[string]bool{
+	"USD": true, "EUR": true, "GBP": true, "JPY": true, "CAD": true, "AUD": true, "USDT": true,
+}
+
+// InstrumentType guesses the Yahoo Finance instrument type of ticker from
+// its notation, for when no source reports it: GC=F is a future,
+// EURUSD=X a currency pair and BTC-USD a cryptocurrency. It returns an
+// empty string for the tickers of shares and funds.
+func InstrumentType(ticker string) string {
+	ticker = strings.ToUpper(strings.TrimSpace(ticker))
+	switch {
+	case strings.HasSuffix(ticker, "=F"):
+		return models.InstrumentFuture
+	case strings.HasSuffix(ticker, "=X"):
+		return models.InstrumentCurrency
+	}
+	if i := strings.LastIndex(ticker, "-"); i > 0 && pairCurrencies[ticker[i+1:]] {
+		return models.InstrumentCrypto
+	}
+	return ""
+}
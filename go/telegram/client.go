@@ -0,0 +1,158 @@
+// Package telegram is a minimal client of the Telegram Bot API: enough to
+// receive the messages sent to a bot and to send messages to chats.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// apiURL is the Bot API endpoint; the token and method are appended
+const apiURL = "https://api.telegram.org/bot"
+
+// MaxMessageLength is the longest text a single message may hold
+const MaxMessageLength = 4096
+
+// Update is an incoming update. Only messages are requested.
+type Update struct {
+	ID      int64    `json:"update_id"`
+	Message *Message `json:"message,omitempty"`
+}
+
+// Message is a message sent to the bot
+type Message struct {
+	ID   int64  `json:"message_id"`
+	Chat Chat   `json:"chat"`
+	From *User  `json:"from,omitempty"`
+	Text string `json:"text"`
+}
+
+// Chat is a private chat, group or channel
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// User is the sender of a message
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username,omitempty"`
+}
+
+// Client calls the Bot API with a bot token
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the bot with token
+func NewClient(token string) (*Client, error) {
+	if token == "" {
+		return nil, fmt.Errorf("telegram bot token is required")
+	}
+	return &Client{
+		token:      token,
+		baseURL:    apiURL,
+		httpClient: &http.Client{Timeout: 90 * time.Second},
+	}, nil
+}
+
+// Updates waits up to timeout for updates after offset, the ID of the
+// last update handled plus one, and returns them
+func (c *Client) Updates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout / time.Second),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// SendMessage sends text as plain text to chatID, a numeric chat ID or
+// the @username of a channel. Text longer than a message allows is split
+// at line breaks into several messages.
+func (c *Client) SendMessage(ctx context.Context, chatID string, text string) error {
+	for _, part := range split(text, MaxMessageLength) {
+		err := c.call(ctx, "sendMessage", map[string]any{
+			"chat_id":                  chatID,
+			"text":                     part,
+			"disable_web_page_preview": true,
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// response is the envelope of every Bot API response
+type response struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+	ErrorCode   int             `json:"error_code"`
+}
+
+// call invokes method with params, decoding its result into result unless
+// it is nil
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+c.token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// The URL holds the token, which must not end up in logs
+		return fmt.Errorf("telegram %s failed: %w", method, redact(err, c.token))
+	}
+	defer resp.Body.Close()
+
+	var envelope response
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("telegram %s: HTTP %d: invalid response: %w", method, resp.StatusCode, err)
+	}
+	if !envelope.OK {
+		return fmt.Errorf("telegram %s: error %d: %s", method, envelope.ErrorCode, envelope.Description)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return fmt.Errorf("telegram %s: invalid result: %w", method, err)
+	}
+	return nil
+}
+
+// redact removes token from the message of err
+func redact(err error, token string) error {
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), token, "<token>"))
+}
+
+// split breaks text into parts of at most limit bytes, at line breaks
+// where possible
+func split(text string, limit int) []string {
+	var parts []string
+	for len(text) > limit {
+		cut := strings.LastIndex(text[:limit], "\n")
+		if cut <= 0 {
+			for cut = limit; cut > 0 && !utf8.RuneStart(text[cut]); cut-- {
+			}
+		}
+		parts = append(parts, text[:cut])
+		text = strings.TrimPrefix(text[cut:], "\n")
+	}
+	return append(parts, text)
+}
@@ -0,0 +1,62 @@
+// Package telemetry sets up OpenTelemetry tracing. Instrumented packages
+// create spans through the global tracer provider, which records nothing
+// until Setup installs an exporting one.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/lesnerd/fair-stock-value/go/config"
+)
+
+// DefaultServiceName identifies traces when no service name is configured
+const DefaultServiceName = "fair-stock-value"
+
+// Setup exports spans over OTLP/gRPC as configured by cfg and returns a
+// function flushing and stopping the export. When tracing is disabled it
+// installs nothing and the returned function does nothing.
+func Setup(ctx context.Context, cfg config.TelemetryConfig) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// Without an endpoint the exporter honours OTEL_EXPORTER_OTLP_ENDPOINT
+	// and defaults to localhost:4317
+	var opts []otlptracegrpc.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	ratio := cfg.SampleRatio
+	if ratio == 0 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/backtest"
+)
+
+// DisplayBacktest displays the holding periods and totals of a backtest
+func DisplayBacktest(report *backtest.Report, showColors bool) {
+	header := fmt.Sprintf("%-12s %-12s %6s %10s %10s %10s  %s",
+		"Start", "End", "Picks", "Return", "Benchmark", "Excess", "Top picks")
+	width := 100
+
+	displayHeader(showColors, width)
+	if showColors {
+		fmt.Printf("%s%s%s\n", ColorBold, header, ColorReset)
+	} else {
+		fmt.Println(header)
+	}
+	fmt.Println(strings.Repeat("-", width))
+
+	for _, period := range report.Periods {
+		var top []string
+		for i, pick := range period.Picks {
+			if i == 5 {
+				top = append(top, "...")
+				break
+			}
+			top = append(top, fmt.Sprintf("%s %+.1f%%", pick.Ticker, pick.Return))
+		}
+
+		row := fmt.Sprintf("%-12s %-12s %6d %9.1f%% %9.1f%% %9.1f%%  %s",
+			period.Start.Format("2006-01-02"),
+			period.End.Format("2006-01-02"),
+			len(period.Picks),
+			period.Return,
+			period.BenchmarkReturn,
+			period.Excess(),
+			strings.Join(top, ", "))
+		fmt.Println(colorBySign(row, period.Excess(), showColors))
+	}
+
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", width))
+	fmt.Println("Backtest Summary:")
+	fmt.Printf("Runs replayed:        %d\n", report.Runs)
+	fmt.Printf("Holding periods:      %d\n", len(report.Periods))
+	fmt.Printf("Benchmark:            %s\n", report.Benchmark)
+	fmt.Printf("Picks return:         %.1f%%\n", report.TotalReturn)
+	fmt.Printf("Benchmark return:     %.1f%%\n", report.TotalBenchmarkReturn)
+	excess := report.TotalReturn - report.TotalBenchmarkReturn
+	fmt.Println(colorBySign(fmt.Sprintf("Excess return:        %.1f%%", excess), excess, showColors))
+	fmt.Printf("Picks beating bench:  %.0f%%\n", report.HitRate*100)
+	fmt.Println(strings.Repeat("=", width))
+}
+
+// colorBySign colors text green for positive and red for negative values
+func colorBySign(text string, value float64, showColors bool) string {
+	if !showColors || value == 0 {
+		return text
+	}
+	if value > 0 {
+		return ColorGreen + text + ColorReset
+	}
+	return ColorRed + text + ColorReset
+}
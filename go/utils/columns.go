@@ -0,0 +1,256 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// Column describes a single column of the results table
+type Column struct {
+	Key    string
+	Header string
+	Width  int
+	Format func(result *models.ValuationResult) string
+}
+
+// DefaultColumns are the columns shown when no column set is configured
+var DefaultColumns = []string{
+	"ticker", "fair_value", "current_price", "difference", "upside_pct", "book_value", "status", "growth",
+}
+
+// availableColumns holds every column that can be selected for output
+var availableColumns = map[string]Column{
+	"ticker": {"ticker", "Ticker", 8, func(r *models.ValuationResult) string {
+		return r.Ticker
+	}},
+	"fair_value": {"fair_value", "Fair Value", 12, func(r *models.ValuationResult) string {
+		return formatPrice(r.FairValue)
+	}},
+	"current_price": {"current_price", "Current Price", 13, func(r *models.ValuationResult) string {
+		return formatPrice(r.CurrentPrice)
+	}},
+	"difference": {"difference", "Difference", 12, func(r *models.ValuationResult) string {
+		return formatPrice(r.PriceDifference)
+	}},
+	"upside_pct": {"upside_pct", "Pct", 8, func(r *models.ValuationResult) string {
+		return fmt.Sprintf("%6.1f%%", r.UpsidePercentage)
+	}},
+	"book_value": {"book_value", "Book Value", 12, func(r *models.ValuationResult) string {
+		return formatPrice(r.BookValue)
+	}},
+	"status": {"status", "Status", 12, func(r *models.ValuationResult) string {
+		status := r.Status
+		if status == models.StatusInsufficientData {
+			status = "Insufficient" // fits the column
+		}
+		if r.Incomplete {
+			status += "*"
+		}
+		if len(r.Violations) > 0 {
+			status += "!"
+		}
+		return status
+	}},
+	"growth": {"growth", "Growth", 8, func(r *models.ValuationResult) string {
+		return fmt.Sprintf("%5.1f%%", r.GrowthRate*100)
+	}},
+	"pe": {"pe", "P/E", 6, func(r *models.ValuationResult) string {
+		return fmt.Sprintf("%5.1f", r.PERatio)
+	}},
+	"eps": {"eps", "EPS", 8, func(r *models.ValuationResult) string {
+		return formatPrice(r.EPS)
+	}},
+	"fcf_per_share": {"fcf_per_share", "FCF/Share", 12, func(r *models.ValuationResult) string {
+		return formatPrice(r.FCFPerShare)
+	}},
+	"dcf_value": {"dcf_value", "DCF Value", 12, func(r *models.ValuationResult) string {
+		return formatPrice(r.DCFValue)
+	}},
+	"comps_value": {"comps_value", "Comps Value", 12, func(r *models.ValuationResult) string {
+		return formatPrice(r.CompsValue)
+	}},
+	"market_cap": {"market_cap", "Market Cap", 10, func(r *models.ValuationResult) string {
+		return formatMarketCap(r.MarketCap)
+	}},
+	"sector": {"sector", "Sector", 20, func(r *models.ValuationResult) string {
+		return truncate(r.Sector, 18)
+	}},
+	"company": {"company", "Company", 20, func(r *models.ValuationResult) string {
+		return truncate(r.CompanyName, 20)
+	}},
+	"tag": {"tag", "Tag", 12, func(r *models.ValuationResult) string {
+		return truncate(r.Tag, 12)
+	}},
+	"ma_50": {"ma_50", "50D MA", 12, func(r *models.ValuationResult) string {
+		return formatMovingAverage(r, 50)
+	}},
+	"ma_200": {"ma_200", "200D MA", 12, func(r *models.ValuationResult) string {
+		return formatMovingAverage(r, 200)
+	}},
+	"vs_50dma": {"vs_50dma", "vs 50D", 8, func(r *models.ValuationResult) string {
+		return formatPriceVsMovingAverage(r, 50)
+	}},
+	"vs_200dma": {"vs_200dma", "vs 200D", 8, func(r *models.ValuationResult) string {
+		return formatPriceVsMovingAverage(r, 200)
+	}},
+	"rel_pe": {"rel_pe", "Rel P/E", 8, formatRelativePE},
+	"ey_spread": {"ey_spread", "EY Spread", 10, func(r *models.ValuationResult) string {
+		if r.Relative == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%+6.2f", r.Relative.EarningsYieldSpread)
+	}},
+	"rel_upside": {"rel_upside", "Rel Upside", 11, func(r *models.ValuationResult) string {
+		if r.Relative == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%+7.1f%%", r.Relative.Upside)
+	}},
+	"fcf_yield": {"fcf_yield", "FCF Yield", 10, func(r *models.ValuationResult) string {
+		return formatYield(r.FCFYield())
+	}},
+	"earnings_yield": {"earnings_yield", "Earn Yield", 10, func(r *models.ValuationResult) string {
+		return formatYield(r.EarningsYield())
+	}},
+	"dividend_yield": {"dividend_yield", "Div Yield", 10, func(r *models.ValuationResult) string {
+		return formatYield(r.DividendYield())
+	}},
+	"net_yield": {"net_yield", "Net Yield", 10, func(r *models.ValuationResult) string {
+		return formatYield(r.NetDividendYield())
+	}},
+	"payout_ratio": {"payout_ratio", "Payout", 8, func(r *models.ValuationResult) string {
+		return formatYield(r.PayoutRatio())
+	}},
+	"sentiment": {"sentiment", "Sentiment", 12, formatSentiment},
+	"revision": {"revision", "Est. Rev", 9, func(r *models.ValuationResult) string {
+		if r.Revision == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%+.2fpt", r.Revision.Change*100)
+	}},
+	"rating": {"rating", "Rating", 6, func(r *models.ValuationResult) string {
+		if r.Note == nil || r.Note.Rating == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%d/%d", r.Note.Rating, models.MaxRating)
+	}},
+	"note": {"note", "Note", 30, func(r *models.ValuationResult) string {
+		if r.Note == nil || r.Note.Text == "" {
+			return "-"
+		}
+		return truncate(r.Note.Text, 30)
+	}},
+}
+
+// IsValidColumn reports whether key names a known output column
+func IsValidColumn(key string) bool {
+	_, exists := availableColumns[key]
+	return exists
+}
+
+// ColumnKeys returns the keys of all known output columns in sorted order
+func ColumnKeys() []string {
+	keys := make([]string, 0, len(availableColumns))
+	for key := range availableColumns {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookupColumns resolves column keys to columns, skipping unknown keys
+func lookupColumns(keys []string) []Column {
+	if len(keys) == 0 {
+		keys = DefaultColumns
+	}
+
+	var columns []Column
+	for _, key := range keys {
+		if column, exists := availableColumns[key]; exists {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// tableWidth returns the printed width of a table with the given columns
+func tableWidth(columns []Column) int {
+	width := 0
+	for _, column := range columns {
+		width += column.Width + 1
+	}
+	if width > 0 {
+		width--
+	}
+	return width
+}
+
+// formatPrice formats a per-share dollar amount
+func formatPrice(value float64) string {
+	if value < 0 {
+		return fmt.Sprintf("-$%.2f", -value)
+	}
+	return fmt.Sprintf("$%.2f", value)
+}
+
+// formatMovingAverage formats the days-day moving average of a result's
+// price, or "-" when it is unknown
+func formatMovingAverage(r *models.ValuationResult, days int) string {
+	average, ok := r.MovingAverage(days)
+	if !ok {
+		return "-"
+	}
+	return formatPrice(average)
+}
+
+// formatPriceVsMovingAverage formats how far a result's price is above or
+// below its days-day moving average, or "-" when it is unknown
+func formatPriceVsMovingAverage(r *models.ValuationResult, days int) string {
+	percent, ok := r.PriceVsMovingAverage(days)
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%+6.1f%%", percent)
+}
+
+// formatRelativePE formats a result's P/E as a multiple of the benchmark's,
+// or "-" when either is unknown
+func formatRelativePE(r *models.ValuationResult) string {
+	if r.Relative == nil || r.Relative.PERatio == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2fx", r.Relative.PERatio)
+}
+
+// formatYield formats a yield in percent, or "-" when it is unknown
+func formatYield(yield float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", yield)
+}
+
+// formatSentiment formats a result's news sentiment score, followed by
+// the adjustment of its fair value when sentiment was extreme, or "-" when
+// it is unknown
+func formatSentiment(r *models.ValuationResult) string {
+	score, ok := r.SentimentScore()
+	if !ok {
+		return "-"
+	}
+	if r.SentimentAdjustment != 0 {
+		return fmt.Sprintf("%+.2f (%+.0f%%)", score, r.SentimentAdjustment*100)
+	}
+	return fmt.Sprintf("%+.2f", score)
+}
+
+// truncate shortens text to at most maxLen characters, marking the cut
+func truncate(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/valuation"
+)
+
+// Comparison is one stock in a side-by-side comparison
+type Comparison struct {
+	StockData *models.StockData
+	Result    *models.ValuationResult
+	Breakdown *valuation.Breakdown
+}
+
+// comparisonRow is a labelled line of a comparison
+type comparisonRow struct {
+	label  string
+	format func(c Comparison) string
+}
+
+// comparisonSections lists every row of a comparison by section
+var comparisonSections = []struct {
+	title string
+	rows  []comparisonRow
+}{
+	{"Inputs", []comparisonRow{
+		{"Company", func(c Comparison) string { return truncate(c.StockData.CompanyName, 14) }},
+		{"Sector", func(c Comparison) string { return truncate(c.StockData.Sector, 14) }},
+		{"Current price", func(c Comparison) string { return formatPrice(c.StockData.CurrentPrice) }},
+		{"FCF per share", func(c Comparison) string { return formatPrice(c.StockData.FCFPerShare) }},
+		{"EPS", func(c Comparison) string { return formatPrice(c.StockData.EPS) }},
+		{"Book value per share", func(c Comparison) string { return formatPrice(c.StockData.BookValue) }},
+		{"P/E ratio", func(c Comparison) string { return fmt.Sprintf("%.2f", c.StockData.PERatio) }},
+		{"Growth rate", func(c Comparison) string { return fmt.Sprintf("%.2f%%", c.StockData.GrowthRate*100) }},
+		{"Market cap", func(c Comparison) string { return formatMarketCap(c.StockData.MarketCap) }},
+	}},
+	{"Discounted Cash Flow", []comparisonRow{
+		{"Starting FCF per share", func(c Comparison) string {
+			if c.Breakdown.DCF.PBROE != nil {
+				return "n/a (P/B-ROE)"
+			}
+			if scaling := c.Breakdown.DCF.Commodity; scaling != nil {
+				return fmt.Sprintf("%s %.2fx oil", formatPrice(c.Breakdown.DCF.FCFPerShare), scaling.Scale) + fallbackMark(c.Breakdown.DCF.UsedFallbackFCF)
+			}
+			return formatPrice(c.Breakdown.DCF.FCFPerShare) + fallbackMark(c.Breakdown.DCF.UsedFallbackFCF)
+		}},
+		{"Growth rate used", func(c Comparison) string { return fmt.Sprintf("%.2f%%", c.Breakdown.DCF.GrowthRate*100) }},
+		{"PV of projected FCF", func(c Comparison) string { return formatPrice(c.Breakdown.DCF.PVProjectedFCF) }},
+		{"PV of terminal value", func(c Comparison) string { return formatPrice(c.Breakdown.DCF.PVTerminalValue) }},
+		{"DCF value", func(c Comparison) string {
+			if pbroe := c.Breakdown.DCF.PBROE; pbroe != nil {
+				return fmt.Sprintf("%s %.2fx", formatPrice(c.Breakdown.DCF.Value), pbroe.JustifiedPB) + floorMark(c.Breakdown.DCF.FlooredAtBook)
+			}
+			return formatPrice(c.Breakdown.DCF.Value) + floorMark(c.Breakdown.DCF.FlooredAtBook)
+		}},
+	}},
+	{"Comparable Company Analysis", []comparisonRow{
+		{"EPS used", func(c Comparison) string {
+			if c.Breakdown.Comps.FFOPerShare > 0 {
+				return formatPrice(c.Breakdown.Comps.FFOPerShare) + " FFO"
+			}
+			return formatPrice(c.Breakdown.Comps.EPS) + fallbackMark(c.Breakdown.Comps.UsedFallbackEPS)
+		}},
+		{"Conservative P/E", func(c Comparison) string { return fmt.Sprintf("%.2f", c.Breakdown.Comps.ConservativePE) }},
+		{"Comps value", func(c Comparison) string {
+			return formatPrice(c.Breakdown.Comps.Value) + floorMark(c.Breakdown.Comps.FlooredAtBook)
+		}},
+	}},
+	{"Fair Value", []comparisonRow{
+		{"Weighted value", func(c Comparison) string { return formatPrice(c.Breakdown.WeightedValue) }},
+		{"Fair value", func(c Comparison) string { return formatPrice(c.Result.FairValue) }},
+		{"Difference", func(c Comparison) string { return formatPrice(c.Result.PriceDifference) }},
+		{"Upside", func(c Comparison) string { return fmt.Sprintf("%+.1f%%", c.Result.UpsidePercentage) }},
+		{"Status", func(c Comparison) string { return c.Result.Status }},
+	}},
+}
+
+// DisplayComparison displays the valuation inputs, intermediate values and
+// outputs of several stocks in aligned columns, followed by their ranking
+// by upside and the peers of those valued on peers
+func DisplayComparison(stocks []Comparison, showColors bool) {
+	const labelWidth = 24
+	const columnWidth = 15
+	width := labelWidth + len(stocks)*(columnWidth+1)
+
+	displayHeader(showColors, width)
+	header := fmt.Sprintf("%-*s", labelWidth, "")
+	for _, c := range stocks {
+		header += fmt.Sprintf(" %*s", columnWidth, c.Result.Ticker)
+	}
+	if showColors {
+		fmt.Printf("%s%s%s\n", ColorBold, header, ColorReset)
+	} else {
+		fmt.Println(header)
+	}
+
+	for _, section := range comparisonSections {
+		if showColors {
+			fmt.Printf("\n%s%s%s%s\n", ColorBold, ColorCyan, section.title, ColorReset)
+		} else {
+			fmt.Printf("\n%s\n", section.title)
+		}
+		fmt.Println(strings.Repeat("-", width))
+
+		for _, row := range section.rows {
+			line := fmt.Sprintf("%-*s", labelWidth, row.label)
+			for _, c := range stocks {
+				cell := fmt.Sprintf(" %*s", columnWidth, row.format(c))
+				if showColors && (row.label == "Upside" || row.label == "Status") {
+					color := ColorRed
+					if c.Result.Status == models.StatusUnderpriced {
+						color = ColorGreen
+					}
+					cell = color + cell + ColorReset
+				}
+				line += cell
+			}
+			fmt.Println(line)
+		}
+	}
+
+	ranked := append([]Comparison(nil), stocks...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Result.UpsidePercentage > ranked[j].Result.UpsidePercentage
+	})
+	tickers := make([]string, len(ranked))
+	for i, c := range ranked {
+		tickers[i] = fmt.Sprintf("%d. %s (%+.1f%%)", i+1, c.Result.Ticker, c.Result.UpsidePercentage)
+	}
+
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", width))
+	fmt.Printf("Ranked by upside: %s\n", strings.Join(tickers, "  "))
+	fmt.Println("* fallback input used   ^ raised to book value floor")
+	fmt.Println(strings.Repeat("=", width))
+
+	displayPeerTables(stocks, showColors)
+}
+
+// fallbackMark flags values where the model substituted a fallback input
+func fallbackMark(used bool) string {
+	if used {
+		return "*"
+	}
+	return ""
+}
+
+// floorMark flags model values raised to the book value floor
+func floorMark(floored bool) string {
+	if floored {
+		return "^"
+	}
+	return ""
+}
@@ -0,0 +1,256 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/valuation"
+)
+
+// DisplayQuotes displays fetched stock data without valuation
+func DisplayQuotes(quotes []*models.StockData, showColors bool) {
+	if len(quotes) == 0 {
+		fmt.Println("No quotes to display!")
+		return
+	}
+
+	header := fmt.Sprintf("%-8s %-12s %-10s %-10s %-10s %-6s %-8s %-10s %-20s %-20s",
+		"Ticker", "Price", "EPS", "FCF/Share", "Book Value", "P/E", "Growth", "Market Cap", "Sector", "Company")
+	if showColors {
+		fmt.Printf("%s%s%s\n", ColorBold, header, ColorReset)
+	} else {
+		fmt.Println(header)
+	}
+	fmt.Println(strings.Repeat("-", len(header)))
+
+	for _, quote := range quotes {
+		fmt.Printf("%-8s %-12s %-10s %-10s %-10s %-6.1f %-8s %-10s %-20s %-20s\n",
+			quote.Ticker,
+			formatPrice(quote.CurrentPrice),
+			formatPrice(quote.EPS),
+			formatPrice(quote.FCFPerShare),
+			formatPrice(quote.BookValue),
+			quote.PERatio,
+			fmt.Sprintf("%.1f%%", quote.GrowthRate*100),
+			formatMarketCap(quote.MarketCap),
+			truncate(quote.Sector, 20),
+			truncate(quote.CompanyName, 20))
+	}
+}
+
+// DisplayExplanation displays every step of a single stock's valuation
+func DisplayExplanation(stockData *models.StockData, result *models.ValuationResult, breakdown *valuation.Breakdown,
+	dcfParams models.DCFParameters, compsParams models.CompsParameters, showColors bool) {
+	section := func(title string) {
+		if showColors {
+			fmt.Printf("\n%s%s%s%s\n", ColorBold, ColorCyan, title, ColorReset)
+		} else {
+			fmt.Printf("\n%s\n", title)
+		}
+		fmt.Println(strings.Repeat("-", 60))
+	}
+
+	fmt.Printf("%s (%s) - %s\n", stockData.CompanyName, stockData.Ticker, stockData.Sector)
+	if stockData.Industry != "" {
+		fmt.Printf("Industry:     %s\n", stockData.Industry)
+	}
+	if stockData.Tag != "" {
+		fmt.Printf("Tag:          %s\n", stockData.Tag)
+	}
+	if result.Note != nil {
+		fmt.Printf("Note:         %s (%s)\n", result.Note.Summary(), result.Note.At.Local().Format("2006-01-02"))
+	}
+	fmt.Printf("Data fetched: %s\n", stockData.FetchTime.Format("2006-01-02 15:04:05 MST"))
+	if !stockData.RegularMarketTime.IsZero() {
+		fmt.Printf("Last trade:   %s\n", stockData.RegularMarketTime.Format("2006-01-02 15:04:05 MST"))
+	}
+	if !stockData.EarningsDate.IsZero() {
+		fmt.Printf("Earnings:     %s\n", stockData.EarningsDate.Format("2006-01-02"))
+	}
+	if !stockData.TTMAsOf.IsZero() {
+		fmt.Printf("TTM as of:    %s\n", stockData.TTMAsOf.Format("2006-01-02"))
+	}
+	for _, split := range stockData.Splits {
+		fmt.Printf("Adjusted for: %s split\n", split)
+	}
+	if stockData.Incomplete {
+		fmt.Println("Warning: incomplete data - the ticker timeout passed before every source answered")
+	}
+	for _, violation := range result.Violations {
+		fmt.Printf("Warning: failed sanity check %s\n", violation)
+	}
+
+	section("Inputs")
+	fmt.Printf("%-28s %s\n", "Current price", formatPrice(stockData.CurrentPrice))
+	fmt.Printf("%-28s %s\n", "FCF per share", formatPrice(stockData.FCFPerShare))
+	fmt.Printf("%-28s %s\n", "EPS", formatPrice(stockData.EPS))
+	fmt.Printf("%-28s %s\n", "Book value per share", formatPrice(stockData.BookValue))
+	fmt.Printf("%-28s %s\n", "Tangible book per share", formatPrice(stockData.TangibleBookValue))
+	fmt.Printf("%-28s %.2f\n", "P/E ratio", stockData.PERatio)
+	if stockData.FFOPerShare != 0 {
+		fmt.Printf("%-28s %s\n", "FFO per share", formatPrice(stockData.FFOPerShare))
+	}
+	if len(stockData.GrowthSources) > 0 {
+		fmt.Printf("%-28s %.2f%% (std dev %.2f%% across %d sources)\n", "Growth rate", stockData.GrowthRate*100,
+			stockData.GrowthStdDev*100, len(stockData.GrowthSources))
+	} else {
+		fmt.Printf("%-28s %.2f%%\n", "Growth rate", stockData.GrowthRate*100)
+	}
+	for _, estimate := range stockData.GrowthSources {
+		fmt.Printf("  %-26s %.2f%% (confidence %.2f)\n", estimate.Source, estimate.Rate*100, estimate.Confidence)
+	}
+	for _, rejected := range stockData.RejectedGrowth {
+		fmt.Printf("  %-26s %.2f%% from %s (%s)\n", "Rejected growth rate", rejected.Rate*100,
+			rejected.Source, strings.ReplaceAll(rejected.Reason, "_", " "))
+	}
+	fmt.Printf("%-28s %s\n", "Market cap", formatMarketCap(stockData.MarketCap))
+	if stockData.ReturnOnEquity != 0 {
+		fmt.Printf("%-28s %.2f%%\n", "Return on equity", stockData.ReturnOnEquity*100)
+	}
+	if stockData.NetInterestMargin != 0 {
+		fmt.Printf("%-28s %.2f%%\n", "Net interest margin", stockData.NetInterestMargin*100)
+	}
+
+	dcf := breakdown.DCF
+	if pbroe := dcf.PBROE; pbroe != nil {
+		section("P/B-ROE (in place of DCF)")
+		fmt.Printf("%-28s %.2f%%\n", "Return on equity", pbroe.ReturnOnEquity*100)
+		fmt.Printf("%-28s %.2f%% (capped at %.2f%%)\n", "Growth rate used", pbroe.GrowthRate*100, dcfParams.MaxGrowthRate*100)
+		fmt.Printf("%-28s %.2f%%\n", "Discount rate", dcfParams.DiscountRate*100)
+		fmt.Printf("%-28s %.2fx (ROE - g) / (r - g)\n", "Justified P/B", pbroe.JustifiedPB)
+		fmt.Printf("%-28s %s\n", "Book value per share used", formatPrice(pbroe.BookValue))
+		fmt.Printf("%-28s %s%s\n", "P/B-ROE value", formatPrice(dcf.Value), floorNote(dcf.FlooredAtBook, breakdown.Floor))
+	} else {
+		section("Discounted Cash Flow")
+		fcfNote := ""
+		if dcf.UsedFallbackFCF {
+			fcfNote = fmt.Sprintf(" (%s fallback: reported FCF not positive)", dcf.FallbackPolicy)
+		}
+		if scaling := dcf.Commodity; scaling != nil {
+			fmt.Printf("%-28s %s\n", "Reported FCF per share", formatPrice(scaling.ReportedFCF))
+			fmt.Printf("%-28s %.2fx (oil at $%.2f, reported FCF earned at $%.2f)\n", "Oil scenario scale",
+				scaling.Scale, scaling.Scenario, scaling.ReferencePrice)
+		}
+		fmt.Printf("%-28s %s%s\n", "Starting FCF per share", formatPrice(dcf.FCFPerShare), fcfNote)
+		if dcf.BuybackYield != 0 {
+			fmt.Printf("%-28s %.2f%% (estimate capped at %.2f%%, %+.2f%% buyback yield)\n", "Growth rate used",
+				dcf.GrowthRate*100, dcfParams.MaxGrowthRate*100, dcf.BuybackYield*100)
+			fmt.Printf("  %-26s %s (yield bounded at %.2f%%)\n", "Diluted shares",
+				formatShareCounts(stockData.ShareCounts), dcfParams.MaxBuybackYield*100)
+		} else {
+			fmt.Printf("%-28s %.2f%% (capped at %.2f%%)\n", "Growth rate used", dcf.GrowthRate*100, dcfParams.MaxGrowthRate*100)
+		}
+		fmt.Printf("%-28s %.2f%%\n", "Discount rate", dcfParams.DiscountRate*100)
+		fmt.Printf("%-28s %.2f%%\n", "Terminal growth rate", dcfParams.TerminalGrowthRate*100)
+		for i, fcf := range dcf.ProjectedFCF {
+			fmt.Printf("  Year %-21d %s\n", i+1, formatPrice(fcf))
+		}
+		fmt.Printf("%-28s %s\n", "PV of projected FCF", formatPrice(dcf.PVProjectedFCF))
+		fmt.Printf("%-28s %s\n", "Terminal value", formatPrice(dcf.TerminalValue))
+		fmt.Printf("%-28s %s\n", "PV of terminal value", formatPrice(dcf.PVTerminalValue))
+		fmt.Printf("%-28s %s%s\n", "DCF value", formatPrice(dcf.Value), floorNote(dcf.FlooredAtBook, breakdown.Floor))
+	}
+
+	section("Comparable Company Analysis")
+	comps := breakdown.Comps
+	epsNote := ""
+	if comps.UsedFallbackEPS {
+		epsNote = fmt.Sprintf(" (%s fallback: reported EPS not positive)", comps.FallbackPolicy)
+	}
+	multiple := "P/E"
+	if comps.FFOPerShare > 0 {
+		multiple = "P/FFO"
+		fmt.Printf("%-28s %s (REIT, in place of EPS)\n", "FFO per share used", formatPrice(comps.FFOPerShare))
+	} else {
+		fmt.Printf("%-28s %s%s\n", "EPS used", formatPrice(comps.EPS), epsNote)
+	}
+	if peers := comps.Peers; peers != nil && (comps.FFOPerShare == 0 || peers.MedianPFFO > 0) {
+		fmt.Printf("%-28s %.2f (median of %d %s peers: %s)\n", "Peer "+multiple+" ratio", comps.PERatio,
+			len(peers.Peers), peers.Basis, strings.Join(peers.Tickers(), ", "))
+	} else if comps.FFOPerShare > 0 {
+		fmt.Printf("%-28s %.2f (sector median)\n", multiple+" ratio", comps.PERatio)
+	} else {
+		fmt.Printf("%-28s %.2f\n", multiple+" ratio", comps.PERatio)
+	}
+	fmt.Printf("%-28s %.2f (x%.2f, bounded %.0f-%.0f)\n", "Conservative "+multiple, comps.ConservativePE,
+		compsParams.PEConservativeFactor, compsParams.MinPERatio, compsParams.MaxPERatio)
+	fmt.Printf("%-28s %s%s\n", "Comps value", formatPrice(comps.Value), floorNote(comps.FlooredAtBook, breakdown.Floor))
+	if comps.Peers != nil {
+		fmt.Println()
+		displayPeerTable(stockData, result, comps.Peers, showColors)
+	}
+
+	section("Fair Value")
+	fmt.Printf("%-28s %.0f%% DCF + %.0f%% Comps = %s\n", "Weighted value",
+		breakdown.Weights.DCFWeight*100, breakdown.Weights.CompsWeight*100, formatPrice(breakdown.WeightedValue))
+	// Each adjustment is shown with the value it leads to
+	adjusted := breakdown.WeightedValue
+	if sentiment := breakdown.Sentiment; sentiment != nil {
+		fmt.Printf("%-28s %+.2f from %d articles (%s)\n", "News sentiment", sentiment.Score, sentiment.Articles, sentiment.Source)
+		if breakdown.SentimentAdjustment != 0 {
+			adjusted *= 1 + breakdown.SentimentAdjustment
+			fmt.Printf("%-28s %+.0f%% for extreme sentiment = %s\n", "Sentiment adjustment",
+				breakdown.SentimentAdjustment*100, formatPrice(adjusted))
+		}
+	}
+	if breakdown.SizePremium != 0 {
+		adjusted *= 1 - breakdown.SizePremium
+		fmt.Printf("%-28s -%.0f%% for a $%s market cap = %s\n", "Size premium",
+			breakdown.SizePremium*100, formatMarketCap(stockData.MarketCap), formatPrice(adjusted))
+	}
+	if breakdown.IlliquidityHaircut != 0 {
+		adjusted *= 1 - breakdown.IlliquidityHaircut
+		fmt.Printf("%-28s -%.0f%% for $%s traded a day = %s\n", "Illiquidity haircut",
+			breakdown.IlliquidityHaircut*100, formatMarketCap(int64(breakdown.AverageDollarVolume)), formatPrice(adjusted))
+	}
+	if breakdown.Floor != models.FloorNone {
+		fmt.Printf("%-28s %s (%s)\n", "Floor", formatPrice(breakdown.BookValue), breakdown.Floor)
+	}
+
+	color, reset := "", ""
+	if showColors {
+		color, reset = ColorRed, ColorReset
+		if result.Status == models.StatusUnderpriced {
+			color = ColorGreen
+		}
+	}
+	fmt.Printf("%s%-28s %s vs price %s (%+.1f%%) - %s%s\n", color, "Fair value",
+		formatPrice(result.FairValue), formatPrice(result.CurrentPrice), result.UpsidePercentage, result.Status, reset)
+
+	if relative := result.Relative; relative != nil {
+		section("Relative to " + relative.Benchmark)
+		fmt.Printf("%-28s %s\n", "P/E vs benchmark", formatRelativePE(result))
+		fmt.Printf("%-28s %+.2f points\n", "Earnings yield spread", relative.EarningsYieldSpread)
+		fmt.Printf("%-28s %+.1f points\n", "Upside vs benchmark", relative.Upside)
+	}
+
+	if costs := result.ADRCosts; costs != nil && stockData.DividendPerShare > 0 {
+		section("ADR Dividend Costs (" + costs.Country + ")")
+		fmt.Printf("%-28s %.2f%%\n", "Withholding tax", costs.Withholding*100)
+		fmt.Printf("%-28s %s\n", "Depositary fee per ADR", formatPrice(costs.FeePerShare))
+		fmt.Printf("%-28s %s of %s\n", "Net dividend per share",
+			formatPrice(costs.NetDividend(stockData.DividendPerShare)), formatPrice(stockData.DividendPerShare))
+		fmt.Printf("%-28s %s of %s\n", "Net dividend yield",
+			formatYield(result.NetDividendYield()), formatYield(result.DividendYield()))
+	}
+}
+
+// formatShareCounts formats the share count history a buyback yield was
+// measured over, such as "16.9B (2021) to 15.4B (2024)"
+func formatShareCounts(counts []models.ShareCount) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+	first, last := counts[0], counts[len(counts)-1]
+	return fmt.Sprintf("%s (%d) to %s (%d)", formatMarketCap(first.Shares), first.Date.Year(),
+		formatMarketCap(last.Shares), last.Date.Year())
+}
+
+// floorNote describes whether a model value was raised to the floor
+func floorNote(floored bool, floor models.BookFloor) string {
+	if floored {
+		return fmt.Sprintf(" (raised to %s floor)", floor)
+	}
+	return ""
+}
@@ -3,11 +3,12 @@ package utils
 import (
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
-	"fair-stock-value/models"
+	"github.com/lesnerd/fair-stock-value/go/models"
 )
 
 // Colors for terminal output
@@ -23,8 +24,20 @@ const (
 	ColorBold   = "\033[1m"
 )
 
+// DisplayOptions controls how valuation results are rendered
+type DisplayOptions struct {
+	ShowColors          bool
+	SortBy              string
+	ShowOnlyUnderpriced bool
+	MaxResults          int
+	Columns             []string
+	Trend               string // "above" or "below" the 200-day moving average; empty shows all
+	Benchmark           *models.Benchmark // market the results were related to, shown in the summary
+	PageSize            int               // rows per page, with the header repeated; zero shows every row at once
+}
+
 // DisplayResults displays the valuation results in a formatted table
-func DisplayResults(results []*models.ValuationResult, showColors bool, sortBy string, showOnlyUnderpriced bool, maxResults int, showExtra bool) {
+func DisplayResults(results []*models.ValuationResult, opts DisplayOptions) {
 	if len(results) == 0 {
 		fmt.Println("No results to display!")
 		return
@@ -32,26 +45,52 @@ func DisplayResults(results []*models.ValuationResult, showColors bool, sortBy s
 
 	// Filter results if needed
 	filteredResults := results
-	if showOnlyUnderpriced {
+	if opts.ShowOnlyUnderpriced {
 		filteredResults = filterUnderpriced(results)
 	}
+	if opts.Trend != "" {
+		filteredResults = filterTrend(filteredResults, opts.Trend)
+	}
 
 	// Sort results
-	sortResults(filteredResults, sortBy)
+	sortResults(filteredResults, opts.SortBy)
 
 	// Limit results if specified
-	if maxResults > 0 && len(filteredResults) > maxResults {
-		filteredResults = filteredResults[:maxResults]
+	if opts.MaxResults > 0 && len(filteredResults) > opts.MaxResults {
+		filteredResults = filteredResults[:opts.MaxResults]
 	}
 
+	columns := lookupColumns(opts.Columns)
+
 	// Display header
-	displayHeader(showColors)
+	displayHeader(opts.ShowColors, tableWidth(columns))
 
 	// Display table
-	displayTable(filteredResults, showColors, showExtra)
+	displayTable(filteredResults, opts.ShowColors, columns, opts.PageSize)
+	if !slices.Contains(opts.Columns, "note") {
+		displayNotes(filteredResults)
+	}
 
 	// Display summary
-	displaySummary(results, showColors)
+	displaySummary(results, opts.Benchmark, opts.ShowColors, tableWidth(columns))
+}
+
+// displayNotes lists the notes on the tickers shown, which the table
+// leaves out unless its note column is selected
+func displayNotes(results []*models.ValuationResult) {
+	var noted []*models.ValuationResult
+	for _, result := range results {
+		if result.Note != nil {
+			noted = append(noted, result)
+		}
+	}
+	if len(noted) == 0 {
+		return
+	}
+	fmt.Println("\nNotes:")
+	for _, result := range noted {
+		fmt.Printf("  %-8s %s (%s)\n", result.Ticker, result.Note.Summary(), result.Note.At.Local().Format("2006-01-02"))
+	}
 }
 
 // filterUnderpriced filters results to show only underpriced stocks
@@ -65,8 +104,47 @@ func filterUnderpriced(results []*models.ValuationResult) []*models.ValuationRes
 	return filtered
 }
 
-// sortResults sorts results based on the specified criteria
+// Trends are the accepted values of the trend option
+var Trends = []string{"above", "below"}
+
+// MatchesTrend reports whether result trades above or below its 200-day
+// moving average, as trend names. An empty trend matches every result;
+// results without a moving average match no other.
+func MatchesTrend(result *models.ValuationResult, trend string) bool {
+	if trend == "" {
+		return true
+	}
+	percent, ok := result.PriceVsMovingAverage(200)
+	if !ok {
+		return false
+	}
+	if trend == "above" {
+		return percent > 0
+	}
+	return percent < 0
+}
+
+// filterTrend filters results to the stocks matching trend
+func filterTrend(results []*models.ValuationResult, trend string) []*models.ValuationResult {
+	var filtered []*models.ValuationResult
+	for _, result := range results {
+		if MatchesTrend(result, trend) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// SortKeys are the accepted values of the sort option
+var SortKeys = []string{"upside", "ticker", "fair_value"}
+
+// sortResults sorts results based on the specified criteria, placing
+// tickers that could not be valued last
 func sortResults(results []*models.ValuationResult, sortBy string) {
+	defer sort.SliceStable(results, func(i, j int) bool {
+		return !results[i].Failed() && results[j].Failed()
+	})
+
 	switch sortBy {
 	case "upside":
 		sort.Slice(results, func(i, j int) bool {
@@ -98,11 +176,15 @@ func sortResults(results []*models.ValuationResult, sortBy string) {
 	}
 }
 
+// Now tells the time shown in table headers. Deterministic runs replace it
+// with the analyzer's clock.
+var Now = time.Now
+
 // displayHeader displays the table header
-func displayHeader(showColors bool) {
-	currentTime := time.Now()
+func displayHeader(showColors bool, width int) {
+	currentTime := Now()
 	
-	separator := strings.Repeat("=", 98)
+	separator := strings.Repeat("=", width)
 	title := fmt.Sprintf("Stock Fair Value Analysis - %s", currentTime.Format("2006-01-02 15:04:05"))
 	
 	if showColors {
@@ -116,92 +198,72 @@ func displayHeader(showColors bool) {
 	}
 }
 
-// displayTable displays the results in a formatted table
-func displayTable(results []*models.ValuationResult, showColors bool, showExtra bool) {
-	// Table header
-	if showExtra {
-		if showColors {
-			fmt.Printf("%s%-8s %-12s %-12s %-12s %-8s %-12s %-12s %-8s %-6s %-8s %-12s %-20s %-12s%s\n", 
-				ColorBold, "Ticker", "Fair Value", "Current Price", "Difference", "Pct", "Book Value", "Status", "Growth", "P/E", "EPS", "FCF/Share", "Sector", "Company", ColorReset)
-		} else {
-			fmt.Printf("%-8s %-12s %-12s %-12s %-8s %-12s %-12s %-8s %-6s %-8s %-12s %-20s %-12s\n", 
-				"Ticker", "Fair Value", "Current Price", "Difference", "Pct", "Book Value", "Status", "Growth", "P/E", "EPS", "FCF/Share", "Sector", "Company")
+// displayTable displays the results in a formatted table. With a page
+// size, it waits for a key after every page and repeats the header on the
+// next one, so the header stays in view however many rows there are.
+func displayTable(results []*models.ValuationResult, showColors bool, columns []Column, pageSize int) {
+	displayTableHeader(showColors, columns)
+	for i, result := range results {
+		if pageSize > 0 && i > 0 && i%pageSize == 0 {
+			if !waitForMore(i, len(results), showColors) {
+				fmt.Printf("(%d more rows not shown)\n", len(results)-i)
+				return
+			}
+			displayTableHeader(showColors, columns)
 		}
+		displayRow(result, showColors, columns)
+	}
+}
+
+// displayTableHeader displays the column headers of the results table
+func displayTableHeader(showColors bool, columns []Column) {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = fmt.Sprintf("%-*s", column.Width, column.Header)
+	}
+	if showColors {
+		fmt.Printf("%s%s%s\n", ColorBold, strings.Join(headers, " "), ColorReset)
 	} else {
-		if showColors {
-			fmt.Printf("%s%-8s %-12s %-12s %-12s %-8s %-12s %-12s %-8s%s\n", 
-				ColorBold, "Ticker", "Fair Value", "Current Price", "Difference", "Pct", "Book Value", "Status", "Growth", ColorReset)
-		} else {
-			fmt.Printf("%-8s %-12s %-12s %-12s %-8s %-12s %-12s %-8s\n", 
-				"Ticker", "Fair Value", "Current Price", "Difference", "Pct", "Book Value", "Status", "Growth")
-		}
+		fmt.Println(strings.Join(headers, " "))
 	}
 	
 	// Separator line
-	separatorLength := 98
-	if showExtra {
-		separatorLength = 168
-	}
-	fmt.Println(strings.Repeat("-", separatorLength))
-	
-	// Table rows
-	for _, result := range results {
-		displayRow(result, showColors, showExtra)
-	}
+	fmt.Println(strings.Repeat("-", tableWidth(columns)))
 }
 
 // displayRow displays a single result row
-func displayRow(result *models.ValuationResult, showColors bool, showExtra bool) {
+func displayRow(result *models.ValuationResult, showColors bool, columns []Column) {
 	var color string
 	if showColors {
-		if result.Status == models.StatusUnderpriced {
+		if result.Failed() {
+			color = ColorYellow
+		} else if result.Status == models.StatusUnderpriced {
 			color = ColorGreen
 		} else {
 			color = ColorRed
 		}
 	}
 	
-	if showExtra {
-		// Truncate company name if too long
-		companyName := result.CompanyName
-		if len(companyName) > 20 {
-			companyName = companyName[:17] + "..."
+	cells := make([]string, len(columns))
+	for i, column := range columns {
+		value := column.Format(result)
+		// A failed ticker has no figures, only its status and the reason,
+		// and the price of an instrument that cannot be valued
+		if result.Failed() && column.Key != "ticker" && column.Key != "status" &&
+			!(column.Key == "current_price" && result.CurrentPrice > 0) {
+			value = "-"
 		}
-		
-		// Truncate sector if too long
-		sector := result.Sector
-		if len(sector) > 18 {
-			sector = sector[:15] + "..."
-		}
-		
-		fmt.Printf("%s%-8s $%-11.2f $%-11.2f $%-11.2f %6.1f%% $%-11.2f %-12s %5.1f%% %5.1f $%-7.2f $%-11.2f %-20s %-12s%s\n",
-			color,
-			result.Ticker,
-			result.FairValue,
-			result.CurrentPrice,
-			result.PriceDifference,
-			result.UpsidePercentage,
-			result.BookValue,
-			result.Status,
-			result.GrowthRate*100,
-			result.PERatio,
-			result.EPS,
-			result.FCFPerShare,
-			sector,
-			companyName,
-			ColorReset)
+		cells[i] = fmt.Sprintf("%-*s", column.Width, value)
+	}
+	line := strings.Join(cells, " ")
+	if result.Failed() {
+		line += "  " + result.Error
+	}
+	
+	if showColors {
+		fmt.Printf("%s%s%s\n", color, line, ColorReset)
 	} else {
-		fmt.Printf("%s%-8s $%-11.2f $%-11.2f $%-11.2f %6.1f%% $%-11.2f %-12s %5.1f%%%s\n",
-			color,
-			result.Ticker,
-			result.FairValue,
-			result.CurrentPrice,
-			result.PriceDifference,
-			result.UpsidePercentage,
-			result.BookValue,
-			result.Status,
-			result.GrowthRate*100,
-			ColorReset)
+		fmt.Println(line)
 	}
 }
 
@@ -223,13 +285,30 @@ func formatMarketCap(marketCap int64) string {
 }
 
 // displaySummary displays summary statistics
-func displaySummary(results []*models.ValuationResult, showColors bool) {
+func displaySummary(results []*models.ValuationResult, benchmark *models.Benchmark, showColors bool, width int) {
 	underpriced := 0
 	overpriced := 0
+	incomplete := 0
+	inconsistent := 0
+	failed := 0
+	insufficient := 0
+	notValuable := 0
 	totalUpside := 0.0
 	
 	for _, result := range results {
-		if result.Status == models.StatusUnderpriced {
+		if result.Incomplete {
+			incomplete++
+		}
+		if len(result.Violations) > 0 {
+			inconsistent++
+		}
+		if result.Status == models.StatusInsufficientData {
+			insufficient++
+		} else if result.Status == models.StatusNotValuable {
+			notValuable++
+		} else if result.Failed() {
+			failed++
+		} else if result.Status == models.StatusUnderpriced {
 			underpriced++
 			totalUpside += result.PriceDifference
 		} else {
@@ -242,7 +321,7 @@ func displaySummary(results []*models.ValuationResult, showColors bool) {
 		avgUpside = totalUpside / float64(underpriced)
 	}
 	
-	separator := strings.Repeat("=", 98)
+	separator := strings.Repeat("=", width)
 	
 	if showColors {
 		fmt.Printf("\n%s%s%s%s\n", ColorBold, ColorCyan, separator, ColorReset)
@@ -250,9 +329,27 @@ func displaySummary(results []*models.ValuationResult, showColors bool) {
 		fmt.Printf("Total stocks analyzed: %d\n", len(results))
 		fmt.Printf("%sUnderpriced: %d%s\n", ColorGreen, underpriced, ColorReset)
 		fmt.Printf("%sOverpriced: %d%s\n", ColorRed, overpriced, ColorReset)
+		if failed > 0 {
+			fmt.Printf("%sErrors: %d%s\n", ColorYellow, failed, ColorReset)
+		}
+		if insufficient > 0 {
+			fmt.Printf("%sInsufficient data (-strict-data): %d%s\n", ColorYellow, insufficient, ColorReset)
+		}
+		if notValuable > 0 {
+			fmt.Printf("%sPrice only (crypto, commodities): %d%s\n", ColorYellow, notValuable, ColorReset)
+		}
 		if underpriced > 0 {
 			fmt.Printf("%sAverage upside for underpriced stocks: $%.2f%s\n", ColorGreen, avgUpside, ColorReset)
 		}
+		if incomplete > 0 {
+			fmt.Printf("%s* Incomplete data (ticker timeout): %d%s\n", ColorYellow, incomplete, ColorReset)
+		}
+		if inconsistent > 0 {
+			fmt.Printf("%s! Failed sanity checks (see explain): %d%s\n", ColorYellow, inconsistent, ColorReset)
+		}
+		if benchmark != nil {
+			fmt.Printf("%s%s%s\n", ColorBlue, formatBenchmark(benchmark), ColorReset)
+		}
 		fmt.Printf("%s%s%s%s\n", ColorBold, ColorCyan, separator, ColorReset)
 	} else {
 		fmt.Printf("\n%s\n", separator)
@@ -260,13 +357,41 @@ func displaySummary(results []*models.ValuationResult, showColors bool) {
 		fmt.Printf("Total stocks analyzed: %d\n", len(results))
 		fmt.Printf("Underpriced: %d\n", underpriced)
 		fmt.Printf("Overpriced: %d\n", overpriced)
+		if failed > 0 {
+			fmt.Printf("Errors: %d\n", failed)
+		}
+		if insufficient > 0 {
+			fmt.Printf("Insufficient data (-strict-data): %d\n", insufficient)
+		}
+		if notValuable > 0 {
+			fmt.Printf("Price only (crypto, commodities): %d\n", notValuable)
+		}
 		if underpriced > 0 {
 			fmt.Printf("Average upside for underpriced stocks: $%.2f\n", avgUpside)
 		}
+		if incomplete > 0 {
+			fmt.Printf("* Incomplete data (ticker timeout): %d\n", incomplete)
+		}
+		if inconsistent > 0 {
+			fmt.Printf("! Failed sanity checks (see explain): %d\n", inconsistent)
+		}
+		if benchmark != nil {
+			fmt.Println(formatBenchmark(benchmark))
+		}
 		fmt.Printf("%s\n", separator)
 	}
 }
 
+// formatBenchmark describes the market benchmark results were related to
+func formatBenchmark(benchmark *models.Benchmark) string {
+	text := fmt.Sprintf("Market (%s): P/E %.1f, earnings yield %.2f%%, %+.1f%% to its long-run P/E of %.0f",
+		benchmark.Symbol, benchmark.PERatio, benchmark.EarningsYield, benchmark.Upside, benchmark.LongRunPE)
+	if benchmark.Fallback {
+		text += " (fallback data)"
+	}
+	return text
+}
+
 // ShowProgress displays a progress indicator
 func ShowProgress(current, total int, ticker string) {
 	percentage := float64(current) / float64(total) * 100
@@ -282,6 +407,11 @@ func ClearLine() {
 	fmt.Print("\r" + strings.Repeat(" ", 80) + "\r")
 }
 
+// ClearScreen clears the terminal and moves the cursor to the top left
+func ClearScreen() {
+	fmt.Print("\033[H\033[2J")
+}
+
 // IsTerminal checks if stdout is a terminal
 func IsTerminal() bool {
 	fileInfo, _ := os.Stdout.Stat()
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// benchResults returns n results of varied status and upside
+func benchResults(n int) []*models.ValuationResult {
+	results := make([]*models.ValuationResult, n)
+	for i := range results {
+		price := 20 + float64(i%400)
+		fairValue := price * (0.5 + float64(i%7)/6)
+		status := models.StatusOverpriced
+		if fairValue > price {
+			status = models.StatusUnderpriced
+		}
+		results[i] = &models.ValuationResult{
+			Ticker:           fmt.Sprintf("T%04d", i),
+			FairValue:        fairValue,
+			CurrentPrice:     price,
+			PriceDifference:  fairValue - price,
+			UpsidePercentage: (fairValue - price) / price * 100,
+			BookValue:        price / 8,
+			Status:           status,
+			PERatio:          20,
+			GrowthRate:       0.08,
+			MarketCap:        int64(price * 1e9),
+			Sector:           models.SectorTechnology,
+		}
+	}
+	return results
+}
+
+func BenchmarkSortResults(b *testing.B) {
+	results := benchResults(5000)
+	b.ReportAllocs()
+	for b.Loop() {
+		sorted := slices.Clone(results)
+		sortResults(sorted, "upside")
+	}
+}
+
+func BenchmarkFormatRows(b *testing.B) {
+	results := benchResults(1000)
+	columns := lookupColumns(slices.Sorted(maps.Keys(availableColumns)))
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, result := range results {
+			for _, column := range columns {
+				column.Format(result)
+			}
+		}
+	}
+}
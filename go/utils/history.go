@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/storage"
+)
+
+// HistoryPoint is the valuation of a stock in one recorded run
+type HistoryPoint struct {
+	At         time.Time
+	Result     *models.ValuationResult
+	PricesOnly bool // fundamentals were carried over from an earlier run
+}
+
+// chartHeight is the number of rows of a history chart
+const chartHeight = 12
+
+// chartWidth is the maximum number of columns of a history chart
+const chartWidth = 72
+
+// DisplayHistory displays the past valuations of a stock as a table
+func DisplayHistory(ticker string, points []HistoryPoint, showColors bool) {
+	header := fmt.Sprintf("%-17s %-12s %-12s %-9s %-12s %-12s %-12s",
+		"Date", "Price", "Fair Value", "Upside", "Status", "DCF Value", "Comps Value")
+	width := len(header)
+
+	displayHeader(showColors, width)
+	if showColors {
+		fmt.Printf("%s%s%s\n", ColorBold, header, ColorReset)
+	} else {
+		fmt.Println(header)
+	}
+	fmt.Println(strings.Repeat("-", width))
+
+	for _, point := range points {
+		r := point.Result
+		date := point.At.Local().Format("2006-01-02 15:04")
+		if point.PricesOnly {
+			date += "*"
+		}
+		row := fmt.Sprintf("%-17s %-12s %-12s %8.1f%% %-12s %-12s %-12s",
+			date, formatPrice(r.CurrentPrice), formatPrice(r.FairValue), r.UpsidePercentage,
+			r.Status, formatPrice(r.DCFValue), formatPrice(r.CompsValue))
+		if showColors {
+			color := ColorRed
+			if r.Status == models.StatusUnderpriced {
+				color = ColorGreen
+			}
+			row = color + row + ColorReset
+		}
+		fmt.Println(row)
+	}
+
+	displayHistorySummary(ticker, points, width)
+}
+
+// DisplayRunTags displays the recorded runs of each run tag, so the runs
+// of separate workflows can be told apart
+func DisplayRunTags(summaries []storage.TagSummary, showColors bool) {
+	header := fmt.Sprintf("%-24s %6s %8s %8s %-17s %-17s",
+		"Tag", "Runs", "Refresh", "Valued", "First", "Last")
+	if showColors {
+		fmt.Printf("%s%s%s\n", ColorBold, header, ColorReset)
+	} else {
+		fmt.Println(header)
+	}
+	fmt.Println(strings.Repeat("-", len(header)))
+
+	for _, summary := range summaries {
+		tag := summary.Tag
+		if tag == "" {
+			tag = "(untagged)"
+		}
+		fmt.Printf("%-24s %6d %8d %8d %-17s %-17s\n", truncate(tag, 24), summary.Runs, summary.PricesOnly,
+			summary.Valued, summary.First.Local().Format("2006-01-02 15:04"), summary.Last.Local().Format("2006-01-02 15:04"))
+	}
+}
+
+// DisplayHistoryChart plots the price and fair value of a stock over time
+func DisplayHistoryChart(ticker string, points []HistoryPoint, showColors bool) {
+	sampled := samplePoints(points, chartWidth)
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, point := range sampled {
+		for _, value := range []float64{point.Result.CurrentPrice, point.Result.FairValue} {
+			low, high = math.Min(low, value), math.Max(high, value)
+		}
+	}
+	if high == low {
+		low, high = low*0.95, high*1.05
+	}
+
+	// rowOf maps a value to a chart row, 0 being the top
+	rowOf := func(value float64) int {
+		return int(math.Round((high - value) / (high - low) * (chartHeight - 1)))
+	}
+
+	grid := make([][]string, chartHeight)
+	for i := range grid {
+		grid[i] = make([]string, len(sampled))
+		for j := range grid[i] {
+			grid[i][j] = " "
+		}
+	}
+	mark := func(symbol, color string) string {
+		if showColors {
+			return color + symbol + ColorReset
+		}
+		return symbol
+	}
+	for col, point := range sampled {
+		priceRow, fairRow := rowOf(point.Result.CurrentPrice), rowOf(point.Result.FairValue)
+		grid[priceRow][col] = mark("P", ColorYellow)
+		grid[fairRow][col] = mark("F", ColorCyan)
+		if priceRow == fairRow {
+			grid[priceRow][col] = mark("*", ColorBold)
+		}
+	}
+
+	width := len(sampled) + 12
+	displayHeader(showColors, max(width, 60))
+	fmt.Printf("%s: P = price, F = fair value, * = both\n\n", ticker)
+	for i, row := range grid {
+		label := ""
+		if i%3 == 0 || i == chartHeight-1 {
+			label = formatPrice(high - (high-low)*float64(i)/(chartHeight-1))
+		}
+		fmt.Printf("%10s |%s\n", label, strings.Join(row, ""))
+	}
+	fmt.Printf("%10s +%s\n", "", strings.Repeat("-", len(sampled)))
+
+	first := sampled[0].At.Local().Format("2006-01-02")
+	last := sampled[len(sampled)-1].At.Local().Format("2006-01-02")
+	gap := max(len(sampled)-len(first)-len(last), 1)
+	fmt.Printf("%10s  %s%s%s\n", "", first, strings.Repeat(" ", gap), last)
+
+	displayHistorySummary(ticker, points, max(width, 60))
+}
+
+// displayHistorySummary shows how the model's view has drifted
+func displayHistorySummary(ticker string, points []HistoryPoint, width int) {
+	first, last := points[0].Result, points[len(points)-1].Result
+
+	minUpside, maxUpside := math.Inf(1), math.Inf(-1)
+	changes := 0
+	for i, point := range points {
+		minUpside = math.Min(minUpside, point.Result.UpsidePercentage)
+		maxUpside = math.Max(maxUpside, point.Result.UpsidePercentage)
+		if i > 0 && point.Result.Status != points[i-1].Result.Status {
+			changes++
+		}
+	}
+
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", width))
+	fmt.Printf("%s history: %d runs from %s to %s\n", ticker, len(points),
+		points[0].At.Local().Format("2006-01-02"), points[len(points)-1].At.Local().Format("2006-01-02"))
+	fmt.Printf("Fair value:     %s -> %s (%s)\n", formatPrice(first.FairValue), formatPrice(last.FairValue),
+		percentChange(first.FairValue, last.FairValue))
+	fmt.Printf("Price:          %s -> %s (%s)\n", formatPrice(first.CurrentPrice), formatPrice(last.CurrentPrice),
+		percentChange(first.CurrentPrice, last.CurrentPrice))
+	fmt.Printf("Upside range:   %.1f%% to %.1f%%\n", minUpside, maxUpside)
+	fmt.Printf("Status changes: %d\n", changes)
+	fmt.Println(strings.Repeat("=", width))
+}
+
+// samplePoints reduces points to at most n, evenly spread and always
+// keeping the latest
+func samplePoints(points []HistoryPoint, n int) []HistoryPoint {
+	if len(points) <= n {
+		return points
+	}
+	sampled := make([]HistoryPoint, n)
+	for i := range sampled {
+		sampled[i] = points[(i+1)*len(points)/n-1]
+	}
+	return sampled
+}
+
+// percentChange formats the relative change from before to after
+func percentChange(before, after float64) string {
+	if before == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.1f%%", (after-before)/before*100)
+}
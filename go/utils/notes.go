@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// DisplayNotes displays notes kept on tickers, oldest first
+func DisplayNotes(notes []models.Note, showColors bool) {
+	header := fmt.Sprintf("%6s %-8s %-17s %6s  %s", "ID", "Ticker", "Date", "Rating", "Note")
+	if showColors {
+		fmt.Printf("%s%s%s\n", ColorBold, header, ColorReset)
+	} else {
+		fmt.Println(header)
+	}
+	fmt.Println(strings.Repeat("-", len(header)+40))
+
+	for _, note := range notes {
+		rating := "-"
+		if note.Rating > 0 {
+			rating = fmt.Sprintf("%d/%d", note.Rating, models.MaxRating)
+		}
+		fmt.Printf("%6d %-8s %-17s %6s  %s\n", note.ID, note.Ticker,
+			note.At.Local().Format("2006-01-02 15:04"), rating, note.Text)
+	}
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// pageOverhead is the number of terminal lines a page of the results
+// table leaves for its header, separator and the prompt
+const pageOverhead = 3
+
+// PageSize returns the number of table rows shown per page: size when it
+// is positive, or as many as fit the height of the terminal otherwise.
+// Output is only paged when both stdout and stdin are terminals, so it
+// returns zero, for no paging, when the output is piped or redirected.
+func PageSize(size int) int {
+	stdout, stdin := int(os.Stdout.Fd()), int(os.Stdin.Fd())
+	if !term.IsTerminal(stdout) || !term.IsTerminal(stdin) {
+		return 0
+	}
+	if size > 0 {
+		return size
+	}
+	_, height, err := term.GetSize(stdout)
+	if err != nil || height <= pageOverhead {
+		return 0
+	}
+	return height - pageOverhead
+}
+
+// waitForMore shows a "more" prompt after shown of total rows and waits
+// for a key: space or Enter continues with the next page, q, Escape or
+// Ctrl+C skip the remaining rows. It returns whether to continue. When
+// keys cannot be read one at a time the rest is shown without asking.
+func waitForMore(shown, total int, showColors bool) bool {
+	prompt := fmt.Sprintf("-- More (%d of %d) -- space: next page, q: skip the rest", shown, total)
+	if showColors {
+		prompt = ColorBold + prompt + ColorReset
+	}
+	fmt.Print(prompt)
+	defer ClearLine()
+
+	stdin := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(stdin)
+	if err != nil {
+		return true
+	}
+	defer term.Restore(stdin, state)
+
+	key := make([]byte, 1)
+	for {
+		if _, err := os.Stdin.Read(key); err != nil {
+			return true
+		}
+		switch key[0] {
+		case ' ', '\r', '\n':
+			return true
+		case 'q', 'Q', 0x1b, 0x03:
+			return false
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// displayPeerTable displays the peers a stock's Comps value is anchored on
+// next to the stock itself, with their P/E, or P/FFO when the group has a
+// median one, growth and upside, so whether the anchor makes sense can be
+// judged at a glance
+func displayPeerTable(stockData *models.StockData, result *models.ValuationResult, group *models.PeerGroup, showColors bool) {
+	multiple, ownMultiple, median := "P/E", stockData.PERatio, group.MedianPE
+	if group.MedianPFFO > 0 {
+		multiple, ownMultiple, median = "P/FFO", 0, group.MedianPFFO
+		if stockData.FFOPerShare > 0 {
+			ownMultiple = stockData.CurrentPrice / stockData.FFOPerShare
+		}
+	}
+	header := fmt.Sprintf("  %-8s %-20s %8s %8s %9s %10s", "Ticker", "Company", multiple, "Growth", "Upside", "Market Cap")
+	if showColors {
+		fmt.Printf("%s%s%s\n", ColorBold, header, ColorReset)
+	} else {
+		fmt.Println(header)
+	}
+
+	row := func(mark, ticker, company string, pe, growth, upside float64, marketCap int64) {
+		cell := fmt.Sprintf("%+8.1f%%", upside)
+		if showColors {
+			color := ColorRed
+			if upside > 0 {
+				color = ColorGreen
+			}
+			cell = color + cell + ColorReset
+		}
+		fmt.Printf("%s %-8s %-20s %8.2f %7.1f%% %s %10s\n", mark, ticker, truncate(company, 20),
+			pe, growth*100, cell, formatMarketCap(marketCap))
+	}
+	row("*", stockData.Ticker, stockData.CompanyName, ownMultiple, stockData.GrowthRate,
+		result.UpsidePercentage, stockData.MarketCap)
+	for _, peer := range group.Peers {
+		peerMultiple := peer.PERatio
+		if group.MedianPFFO > 0 {
+			peerMultiple = peer.PFFO
+		}
+		row(" ", peer.Ticker, peer.CompanyName, peerMultiple, peer.GrowthRate, peer.Upside, peer.MarketCap)
+	}
+	fmt.Printf("  %-8s %-20s %8.2f\n", "Median", fmt.Sprintf("%d %s peers", len(group.Peers), group.Basis), median)
+}
+
+// displayPeerTables displays the peer table of every compared stock valued
+// on peers
+func displayPeerTables(stocks []Comparison, showColors bool) {
+	for _, c := range stocks {
+		group := c.Breakdown.Comps.Peers
+		if group == nil {
+			continue
+		}
+		title := fmt.Sprintf("Peers of %s", c.Result.Ticker)
+		if showColors {
+			fmt.Printf("\n%s%s%s%s\n", ColorBold, ColorCyan, title, ColorReset)
+		} else {
+			fmt.Printf("\n%s\n", title)
+		}
+		fmt.Println(strings.Repeat("-", 72))
+		displayPeerTable(c.StockData, c.Result, group, showColors)
+	}
+}
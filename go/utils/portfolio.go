@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+	"github.com/lesnerd/fair-stock-value/go/portfolio"
+)
+
+// DisplayPortfolio displays a valued portfolio with per-position and
+// portfolio-level figures and rebalancing suggestions
+func DisplayPortfolio(report *portfolio.Report, showColors bool) {
+	header := fmt.Sprintf("%-8s %10s %12s %14s %14s %8s %9s %10s %14s",
+		"Ticker", "Shares", "Price", "Market Value", "Fair Value", "Weight", "Upside", "Weighted", "Gain/Loss")
+	width := len(header)
+
+	displayHeader(showColors, width)
+	if showColors {
+		fmt.Printf("%s%s%s\n", ColorBold, header, ColorReset)
+	} else {
+		fmt.Println(header)
+	}
+	fmt.Println(strings.Repeat("-", width))
+
+	for _, position := range report.Positions {
+		gainLoss := "N/A"
+		if position.CostValue > 0 {
+			gainLoss = formatSignedAmount(position.GainLoss())
+		}
+		row := fmt.Sprintf("%-8s %10s %12s %14s %14s %7.1f%% %8.1f%% %9.2fpp %14s",
+			position.Ticker,
+			strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.4f", position.Shares), "0"), "."),
+			formatPrice(position.Result.CurrentPrice),
+			formatAmount(position.MarketValue),
+			formatAmount(position.FairValue),
+			position.Weight*100,
+			position.UpsidePct,
+			position.Contribution,
+			gainLoss)
+		if showColors {
+			color := ColorRed
+			if position.Result.Status == models.StatusUnderpriced {
+				color = ColorGreen
+			}
+			row = color + row + ColorReset
+		}
+		fmt.Println(row)
+	}
+
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", width))
+	fmt.Println("Portfolio:")
+	fmt.Printf("Positions valued:  %d\n", len(report.Positions))
+	fmt.Printf("Market value:      %s\n", formatAmount(report.MarketValue))
+	fmt.Printf("Fair value:        %s\n", formatAmount(report.FairValue))
+	fmt.Printf("Upside:            %s (%.1f%%)\n", formatSignedAmount(report.FairValue-report.MarketValue), report.UpsidePct)
+	if report.CostValue > 0 {
+		fmt.Printf("Unrealized gain:   %s\n", formatSignedAmount(report.GainLoss()))
+	}
+
+	if len(report.Failed) > 0 {
+		tickers := make([]string, 0, len(report.Failed))
+		for ticker := range report.Failed {
+			tickers = append(tickers, ticker)
+		}
+		sort.Strings(tickers)
+
+		fmt.Printf("\nNot valued (excluded from totals):\n")
+		for _, ticker := range tickers {
+			fmt.Printf("  %-8s %s\n", ticker, report.Failed[ticker])
+		}
+	}
+
+	if len(report.Warnings) > 0 {
+		fmt.Printf("\nWarnings:\n")
+		for _, warning := range report.Warnings {
+			fmt.Printf("  %s\n", warning)
+		}
+	}
+
+	fmt.Println()
+	if len(report.Suggestions) == 0 {
+		fmt.Println("No rebalancing candidates")
+	} else {
+		fmt.Println("Rebalancing candidates:")
+		for _, suggestion := range report.Suggestions {
+			action := strings.ToUpper(suggestion.Action)
+			if showColors {
+				color := ColorRed
+				if suggestion.Action == "add" {
+					color = ColorGreen
+				}
+				action = color + action + ColorReset
+			}
+			fmt.Printf("  %-8s %s %s\n", suggestion.Ticker, action, suggestion.Reason)
+		}
+	}
+	fmt.Println(strings.Repeat("=", width))
+}
+
+// DisplaySimulation displays the distribution of the total fair value of
+// a portfolio over Monte Carlo trials
+func DisplaySimulation(simulation *portfolio.Simulation, showColors bool) {
+	title := fmt.Sprintf("Monte Carlo fair value (%d trials, correlation %.2f):", simulation.Trials, simulation.Correlation)
+	if showColors {
+		title = ColorBold + title + ColorReset
+	}
+	fmt.Println()
+	fmt.Println(title)
+	fmt.Printf("Market value:      %s\n", formatAmount(simulation.MarketValue))
+	fmt.Printf("Mean:              %s (std dev %s)\n", formatAmount(simulation.Mean), formatAmount(simulation.StdDev))
+	for _, row := range []struct {
+		label string
+		value float64
+	}{
+		{"5th percentile", simulation.P5},
+		{"25th percentile", simulation.P25},
+		{"Median", simulation.Median},
+		{"75th percentile", simulation.P75},
+		{"95th percentile", simulation.P95},
+	} {
+		fmt.Printf("%-18s %s (%+.1f%%)\n", row.label+":", formatAmount(row.value), simulation.UpsidePct(row.value))
+	}
+
+	undervalued := fmt.Sprintf("%.1f%% of trials", simulation.Undervalued*100)
+	if showColors {
+		color := ColorRed
+		if simulation.Undervalued >= 0.5 {
+			color = ColorGreen
+		}
+		undervalued = color + undervalued + ColorReset
+	}
+	fmt.Printf("Undervalued in:    %s\n", undervalued)
+	if simulation.Fixed > 0 {
+		fmt.Printf("Held fixed:        %s of fair value without recorded inputs\n", formatAmount(simulation.Fixed))
+	}
+}
+
+// formatAmount formats a dollar amount with thousands separators
+func formatAmount(value float64) string {
+	if value < 0 {
+		return "-" + formatAmount(-value)
+	}
+
+	whole := fmt.Sprintf("%.2f", value)
+	digits, cents := whole[:len(whole)-3], whole[len(whole)-3:]
+	var grouped []string
+	for len(digits) > 3 {
+		grouped = append([]string{digits[len(digits)-3:]}, grouped...)
+		digits = digits[:len(digits)-3]
+	}
+	grouped = append([]string{digits}, grouped...)
+	return "$" + strings.Join(grouped, ",") + cents
+}
+
+// formatSignedAmount formats a dollar amount with an explicit sign
+func formatSignedAmount(value float64) string {
+	if value >= 0 {
+		return "+" + formatAmount(value)
+	}
+	return formatAmount(value)
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// ProfileComparison is the valuation of one stock under each of the
+// compared profiles
+type ProfileComparison struct {
+	Ticker  string
+	Price   float64
+	Results []*models.ValuationResult // in the order of the profiles
+}
+
+// Flipped reports whether the stock is underpriced under some of the
+// profiles and overpriced under others
+func (p ProfileComparison) Flipped() bool {
+	for _, result := range p.Results[1:] {
+		if result.Status != p.Results[0].Status {
+			return true
+		}
+	}
+	return false
+}
+
+// DisplayProfileComparison displays the fair value and upside of each
+// stock under every profile in side-by-side columns, marking the stocks
+// whose status flips between profiles, followed by the number of
+// underpriced stocks under each profile. With onlyFlips, stocks whose
+// status is the same under every profile are counted but not listed.
+func DisplayProfileComparison(profiles []string, stocks []ProfileComparison, onlyFlips, showColors bool) {
+	const tickerWidth = 8
+	const priceWidth = 11
+	const columnWidth = 20
+	width := tickerWidth + priceWidth + len(profiles)*(columnWidth+1) + 6
+
+	displayHeader(showColors, width)
+	header := fmt.Sprintf("%-*s%*s", tickerWidth, "Ticker", priceWidth, "Price")
+	for _, profile := range profiles {
+		header += fmt.Sprintf(" %*s", columnWidth, truncate(profile, columnWidth))
+	}
+	header += "  Flip"
+	if showColors {
+		fmt.Printf("%s%s%s\n", ColorBold, header, ColorReset)
+	} else {
+		fmt.Println(header)
+	}
+	fmt.Println(strings.Repeat("-", width))
+
+	underpriced := make([]int, len(profiles))
+	flips := 0
+	for _, stock := range stocks {
+		flipped := stock.Flipped()
+		if flipped {
+			flips++
+		}
+		line := fmt.Sprintf("%-*s%*s", tickerWidth, stock.Ticker, priceWidth, formatPrice(stock.Price))
+		for i, result := range stock.Results {
+			cell := fmt.Sprintf(" %*s", columnWidth,
+				fmt.Sprintf("%s %+7.1f%%", formatPrice(result.FairValue), result.UpsidePercentage))
+			if showColors {
+				color := ColorRed
+				if result.Status == models.StatusUnderpriced {
+					color = ColorGreen
+				}
+				cell = color + cell + ColorReset
+			}
+			if result.Status == models.StatusUnderpriced {
+				underpriced[i]++
+			}
+			line += cell
+		}
+		if flipped {
+			line += "  *"
+		}
+		if flipped || !onlyFlips {
+			fmt.Println(line)
+		}
+	}
+
+	fmt.Println(strings.Repeat("=", width))
+	counts := make([]string, len(profiles))
+	for i, profile := range profiles {
+		counts[i] = fmt.Sprintf("%s %d", profile, underpriced[i])
+	}
+	fmt.Printf("Underpriced of %d stocks: %s\n", len(stocks), strings.Join(counts, ", "))
+	fmt.Printf("Status flips between profiles: %d\n", flips)
+	fmt.Println("Each column shows the fair value and upside under the profile; * marks a status flip")
+	fmt.Println(strings.Repeat("=", width))
+}
@@ -3,7 +3,7 @@ package valuation
 import (
 	"math"
 
-	"fair-stock-value/models"
+	"github.com/lesnerd/fair-stock-value/go/models"
 )
 
 // Calculator handles stock valuation calculations
@@ -11,6 +11,13 @@ type Calculator struct {
 	dcfParams     models.DCFParameters
 	compsParams   models.CompsParameters
 	weights       models.ValuationWeights
+	sentiment     models.SentimentParameters
+	size          models.SizeParameters
+	commodity     models.CommodityParameters
+	adr           models.ADRParameters
+	floor         models.BookFloor
+	fallbacks     models.FallbackParameters
+	decimal       bool // per-share arithmetic in models.Money
 }
 
 // NewCalculator creates a new valuation calculator with default parameters
@@ -20,6 +27,7 @@ func NewCalculator() *Calculator {
 			DiscountRate:       0.12, // 12% discount rate
 			TerminalGrowthRate: 0.08, // 8% terminal growth rate
 			MaxGrowthRate:      0.08, // 8% max growth rate cap
+			MaxBuybackYield:    0.05, // 5% bound on the buyback yield, when included
 			ProjectionYears:    5,    // 5 year projection
 		},
 		compsParams: models.CompsParameters{
@@ -36,19 +44,97 @@ func NewCalculator() *Calculator {
 
 // CalculateFairValue calculates the hybrid fair value using DCF and Comps
 func (c *Calculator) CalculateFairValue(stockData *models.StockData) *models.ValuationResult {
+	if c.decimal {
+		return c.calculateDecimal(stockData)
+	}
 	dcfValue := c.calculateDCFValue(stockData)
 	compsValue := c.calculateCompsValue(stockData)
 	
 	// Weighted average: 60% DCF + 40% Comps
 	fairValue := (dcfValue * c.weights.DCFWeight) + (compsValue * c.weights.CompsWeight)
 	
-	// Ensure fair value is not below book value (conservative floor)
-	fairValue = math.Max(fairValue, stockData.BookValue)
+	// Lowered or raised when news sentiment is extreme, and lowered for
+	// small and thinly traded companies
+	adj := c.adjust(stockData)
+	fairValue *= adj.factor()
+	
+	// Ensure fair value is not below the floor (tangible book by default)
+	fairValue = math.Max(fairValue, c.floor.Value(stockData))
 	
 	// Calculate metrics
 	priceDifference := fairValue - stockData.CurrentPrice
 	upsidePercentage := (priceDifference / stockData.CurrentPrice) * 100
 	
+	result := newResult(stockData, fairValue, dcfValue, compsValue, priceDifference, upsidePercentage)
+	adj.record(result)
+	result.ADRCosts = c.adr.For(stockData)
+	return result
+}
+
+// calculateDecimal calculates the hybrid fair value like
+// CalculateFairValue, with prices and per-share values as exact decimal
+// amounts. Only the DCF projection runs in floating point, its value
+// converted once it is known.
+func (c *Calculator) calculateDecimal(stockData *models.StockData) *models.ValuationResult {
+	price := models.MoneyFromFloat(stockData.CurrentPrice)
+	dcfValue := models.MoneyFromFloat(c.calculateDCFValue(stockData))
+	compsValue := models.MoneyFromFloat(c.calculateCompsValue(stockData))
+	adj := c.adjust(stockData)
+	fairValue := c.blendDecimal(dcfValue, compsValue).Mul(models.MoneyFromFloat(adj.factor())).
+		Max(models.MoneyFromFloat(c.floor.Value(stockData)))
+	
+	priceDifference := fairValue.Sub(price)
+	upsidePercentage := priceDifference.Mul(models.MoneyFromFloat(100)).Div(price)
+	
+	result := newResult(stockData, fairValue.Float(), dcfValue.Float(), compsValue.Float(),
+		priceDifference.Float(), upsidePercentage.Float())
+	result.CurrentPrice = price.Float()
+	result.BookValue = models.RoundMoney(result.BookValue)
+	result.EPS = models.RoundMoney(result.EPS)
+	result.FCFPerShare = models.RoundMoney(result.FCFPerShare)
+	adj.record(result)
+	result.ADRCosts = c.adr.For(stockData)
+	return result
+}
+
+// adjustments are the fractions a weighted value is moved by before the
+// floor
+type adjustments struct {
+	sentiment float64 // raised or lowered for extreme news sentiment
+	premium   float64 // lowered for a small market cap
+	haircut   float64 // lowered for a thin trading volume
+}
+
+// adjust returns the adjustments of the weighted value of stockData
+func (c *Calculator) adjust(stockData *models.StockData) adjustments {
+	premium, haircut := c.size.Discount(stockData)
+	return adjustments{
+		sentiment: c.sentiment.Adjust(stockData.Sentiment),
+		premium:   premium,
+		haircut:   haircut,
+	}
+}
+
+// factor returns what the weighted value is multiplied by
+func (a adjustments) factor() float64 {
+	return (1 + a.sentiment) * (1 - a.premium) * (1 - a.haircut)
+}
+
+// record sets the adjustments on result
+func (a adjustments) record(result *models.ValuationResult) {
+	result.SentimentAdjustment = a.sentiment
+	result.SizePremium = a.premium
+	result.IlliquidityHaircut = a.haircut
+}
+
+// blendDecimal weighs the DCF and Comps values in decimal arithmetic
+func (c *Calculator) blendDecimal(dcfValue, compsValue models.Money) models.Money {
+	return dcfValue.Mul(models.MoneyFromFloat(c.weights.DCFWeight)).
+		Add(compsValue.Mul(models.MoneyFromFloat(c.weights.CompsWeight)))
+}
+
+// newResult assembles the valuation result of stockData
+func newResult(stockData *models.StockData, fairValue, dcfValue, compsValue, priceDifference, upsidePercentage float64) *models.ValuationResult {
 	status := models.StatusOverpriced
 	if stockData.CurrentPrice < fairValue {
 		status = models.StatusUnderpriced
@@ -73,18 +159,193 @@ func (c *Calculator) CalculateFairValue(stockData *models.StockData) *models.Val
 		Sector:           stockData.Sector,
 		GrowthRate:       stockData.GrowthRate,
 		CompanyName:      stockData.CompanyName,
+		Tag:              stockData.Tag,
+		Incomplete:       stockData.Incomplete,
+		Inputs:           stockData.Snapshot(),
+
+		GrowthSourceCount: len(stockData.GrowthSources),
+		GrowthStdDev:      stockData.GrowthStdDev,
+	}
+}
+
+// DCFBreakdown holds the intermediate values of a DCF calculation
+type DCFBreakdown struct {
+	FCFPerShare     float64               `json:"fcf_per_share"`
+	UsedFallbackFCF bool                  `json:"used_fallback_fcf"`
+	FallbackPolicy  models.FallbackPolicy `json:"fallback_policy,omitempty"` // that replaced the FCF, when UsedFallbackFCF is set
+	GrowthRate      float64               `json:"growth_rate"`               // including BuybackYield
+	BuybackYield    float64               `json:"buyback_yield,omitempty"`   // net, bounded by the DCF parameters
+	ProjectedFCF    []float64             `json:"projected_fcf"`
+	PVProjectedFCF  float64               `json:"pv_projected_fcf"`
+	TerminalValue   float64               `json:"terminal_value"`
+	PVTerminalValue float64               `json:"pv_terminal_value"`
+	Value           float64               `json:"value"`
+	FlooredAtBook   bool                  `json:"floored_at_book"`
+
+	// Commodity records how the FCF of an energy company was scaled to
+	// the commodity price scenario, when one is set
+	Commodity *CommodityScaling `json:"commodity,omitempty"`
+
+	// PBROE is the valuation of financials on their return on equity, in
+	// place of discounted free cash flow, whose value Value then holds
+	PBROE *PBROEBreakdown `json:"pb_roe,omitempty"`
+
+	// fallbackErr is why the fallback policy left the FCF unreplaced
+	fallbackErr error
+}
+
+// CommodityScaling records the FCF per share of an energy company before
+// it was scaled to the oil price of the scenario
+type CommodityScaling struct {
+	ReportedFCF    float64 `json:"reported_fcf"`
+	Scenario       float64 `json:"scenario"`        // oil price valued at, in dollars a barrel
+	ReferencePrice float64 `json:"reference_price"` // oil price the reported FCF was earned at
+	Scale          float64 `json:"scale"`           // what the reported FCF was multiplied by
+}
+
+// PBROEBreakdown holds the intermediate values of a P/B-ROE valuation: a
+// company earning ReturnOnEquity on its book and growing at GrowthRate
+// deserves a P/B of (ROE - g) / (r - g) at the discount rate r
+type PBROEBreakdown struct {
+	ReturnOnEquity float64 `json:"return_on_equity"`
+	GrowthRate     float64 `json:"growth_rate"` // capped at the maximum growth rate
+	JustifiedPB    float64 `json:"justified_pb"`
+	BookValue      float64 `json:"book_value"` // tangible book per share, or book value when unknown
+	Value          float64 `json:"value"`
+}
+
+// CompsBreakdown holds the intermediate values of a Comps calculation
+type CompsBreakdown struct {
+	EPS             float64               `json:"eps"` // FFO per share when FFOPerShare is set
+	UsedFallbackEPS bool                  `json:"used_fallback_eps"`
+	FallbackPolicy  models.FallbackPolicy `json:"fallback_policy,omitempty"` // that replaced the EPS, when UsedFallbackEPS is set
+	PERatio         float64               `json:"pe_ratio"`                  // the median of the peers' when Peers is set; when FFOPerShare is, P/FFO, the peers' median or the sector's
+	FFOPerShare     float64               `json:"ffo_per_share,omitempty"`   // REIT funds from operations the value is based on in place of EPS
+	Peers           *models.PeerGroup     `json:"peers,omitempty"`
+	ConservativePE  float64               `json:"conservative_pe"`
+	Value           float64               `json:"value"`
+	FlooredAtBook   bool                  `json:"floored_at_book"`
+
+	// fallbackErr is why the fallback policy left the EPS unreplaced
+	fallbackErr error
+}
+
+// Breakdown explains how a fair value was derived
+type Breakdown struct {
+	DCF           DCFBreakdown            `json:"dcf"`
+	Comps         CompsBreakdown          `json:"comps"`
+	Weights       models.ValuationWeights `json:"weights"`
+	WeightedValue float64                 `json:"weighted_value"`
+	Floor         models.BookFloor        `json:"floor"`
+	BookValue     float64                 `json:"book_value"` // value of the floor
+	FairValue     float64                 `json:"fair_value"`
+
+	// Sentiment is the news sentiment the weighted value was lowered or
+	// raised by SentimentAdjustment for, when known
+	Sentiment           *models.Sentiment `json:"sentiment,omitempty"`
+	SentimentAdjustment float64           `json:"sentiment_adjustment,omitempty"`
+
+	// SizePremium and IlliquidityHaircut are the fractions the weighted
+	// value was lowered by for the market cap and for AverageDollarVolume
+	SizePremium         float64 `json:"size_premium,omitempty"`
+	IlliquidityHaircut  float64 `json:"illiquidity_haircut,omitempty"`
+	AverageDollarVolume float64 `json:"average_dollar_volume,omitempty"`
+
+	// AdjustedValue is the weighted value after every adjustment
+	AdjustedValue float64 `json:"adjusted_value"`
+}
+
+// Explain calculates the fair value and returns every intermediate step
+func (c *Calculator) Explain(stockData *models.StockData) *Breakdown {
+	dcf := c.dcfBreakdown(stockData)
+	comps := c.compsBreakdown(stockData)
+	weighted := (dcf.Value * c.weights.DCFWeight) + (comps.Value * c.weights.CompsWeight)
+	adj := c.adjust(stockData)
+	adjusted := weighted * adj.factor()
+	bookValue := c.floor.Value(stockData)
+	fairValue := math.Max(adjusted, bookValue)
+	if c.decimal {
+		dcf.Value = models.RoundMoney(dcf.Value)
+		comps.Value = models.RoundMoney(comps.Value)
+		blended := c.blendDecimal(models.MoneyFromFloat(dcf.Value), models.MoneyFromFloat(comps.Value))
+		moved := blended.Mul(models.MoneyFromFloat(adj.factor()))
+		book := models.MoneyFromFloat(bookValue)
+		weighted, adjusted, bookValue, fairValue = blended.Float(), moved.Float(), book.Float(), moved.Max(book).Float()
+	}
+
+	return &Breakdown{
+		DCF:           dcf,
+		Comps:         comps,
+		Weights:       c.weights,
+		WeightedValue: weighted,
+		Floor:         c.floor,
+		BookValue:     bookValue,
+		FairValue:     fairValue,
+
+		Sentiment:           stockData.Sentiment,
+		SentimentAdjustment: adj.sentiment,
+
+		SizePremium:         adj.premium,
+		IlliquidityHaircut:  adj.haircut,
+		AverageDollarVolume: stockData.AverageDollarVolume(),
+
+		AdjustedValue: adjusted,
 	}
 }
 
 // calculateDCFValue calculates fair value using Discounted Cash Flow model
 func (c *Calculator) calculateDCFValue(stockData *models.StockData) float64 {
+	return c.dcfBreakdown(stockData).Value
+}
+
+// dcfBreakdown runs the DCF model and records its intermediate values
+func (c *Calculator) dcfBreakdown(stockData *models.StockData) DCFBreakdown {
+	// The cash flows of banks and insurers are those of their balance
+	// sheet, so they are valued on the book their returns justify
+	if pbroe := c.pbroeBreakdown(stockData); pbroe != nil {
+		floor := c.floor.Value(stockData)
+		return DCFBreakdown{
+			GrowthRate:    pbroe.GrowthRate,
+			Value:         math.Max(pbroe.Value, floor),
+			FlooredAtBook: pbroe.Value < floor,
+			PBROE:         pbroe,
+		}
+	}
+
 	fcfPerShare := stockData.FCFPerShare
 	growthRate := math.Min(stockData.GrowthRate, c.dcfParams.MaxGrowthRate)
+	breakdown := DCFBreakdown{}
+	
+	// Per-share FCF also grows as buybacks shrink the share count
+	if c.dcfParams.IncludeBuybacks {
+		if yield, ok := models.NetBuybackYield(stockData.ShareCounts); ok {
+			limit := c.dcfParams.MaxBuybackYield
+			breakdown.BuybackYield = math.Max(-limit, math.Min(yield, limit))
+			growthRate += breakdown.BuybackYield
+		}
+	}
+	breakdown.GrowthRate = growthRate
+	
+	// FCF of energy companies follows the oil price it was earned at, so
+	// it is scaled to the price of the scenario
+	if scale, ok := c.commodity.Scale(stockData); ok && fcfPerShare > 0 {
+		breakdown.Commodity = &CommodityScaling{
+			ReportedFCF:    fcfPerShare,
+			Scenario:       c.commodity.Scenario,
+			ReferencePrice: c.commodity.ReferencePrice,
+			Scale:          scale,
+		}
+		fcfPerShare *= scale
+	}
 	
-	// If FCF is negative or zero, use a conservative estimate
+	// If FCF is negative or zero, replace it as the fallback policy says;
+	// without a replacement the value falls to the floor
 	if fcfPerShare <= 0 {
-		fcfPerShare = 2.0 // Conservative fallback
+		fcfPerShare, breakdown.fallbackErr = c.fallbacks.ReplaceFCF(stockData)
+		breakdown.UsedFallbackFCF = true
+		breakdown.FallbackPolicy = c.fallbacks.FCF.Policy.Resolved()
 	}
+	breakdown.FCFPerShare = fcfPerShare
 	
 	// Project FCF for the specified number of years
 	var projectedFCF []float64
@@ -92,44 +353,112 @@ func (c *Calculator) calculateDCFValue(stockData *models.StockData) float64 {
 		fcf := fcfPerShare * math.Pow(1+growthRate, float64(year))
 		projectedFCF = append(projectedFCF, fcf)
 	}
+	breakdown.ProjectedFCF = projectedFCF
 	
 	// Calculate present value of projected FCF
 	var pvFCF float64
 	for i, fcf := range projectedFCF {
 		pvFCF += fcf / math.Pow(1+c.dcfParams.DiscountRate, float64(i+1))
 	}
+	breakdown.PVProjectedFCF = pvFCF
 	
 	// Calculate terminal value using Gordon Growth Model
 	terminalFCF := projectedFCF[len(projectedFCF)-1] * (1 + c.dcfParams.TerminalGrowthRate)
 	terminalValue := terminalFCF / (c.dcfParams.DiscountRate - c.dcfParams.TerminalGrowthRate)
 	pvTerminalValue := terminalValue / math.Pow(1+c.dcfParams.DiscountRate, float64(c.dcfParams.ProjectionYears))
+	breakdown.TerminalValue = terminalValue
+	breakdown.PVTerminalValue = pvTerminalValue
 	
 	// Total DCF value
 	dcfValue := pvFCF + pvTerminalValue
 	
-	// Use book value as floor
-	return math.Max(dcfValue, stockData.BookValue)
+	// Hold the value to the floor
+	floor := c.floor.Value(stockData)
+	breakdown.FlooredAtBook = dcfValue < floor
+	breakdown.Value = math.Max(dcfValue, floor)
+	return breakdown
+}
+
+// pbroeBreakdown runs the P/B-ROE model for financials with a positive
+// return on equity and book value, and returns nil for other stocks or
+// when the growth rate is not below the discount rate
+func (c *Calculator) pbroeBreakdown(stockData *models.StockData) *PBROEBreakdown {
+	book := stockData.TangibleBookValue
+	if book <= 0 {
+		book = stockData.BookValue
+	}
+	roe := stockData.ReturnOnEquity
+	growthRate := math.Min(stockData.GrowthRate, c.dcfParams.MaxGrowthRate)
+	discountRate := c.dcfParams.DiscountRate
+	if !stockData.IsFinancial() || roe <= 0 || book <= 0 || growthRate >= discountRate {
+		return nil
+	}
+
+	// A return below the growth rate destroys value as the book grows
+	justifiedPB := math.Max((roe-growthRate)/(discountRate-growthRate), 0)
+	return &PBROEBreakdown{
+		ReturnOnEquity: roe,
+		GrowthRate:     growthRate,
+		JustifiedPB:    justifiedPB,
+		BookValue:      book,
+		Value:          justifiedPB * book,
+	}
 }
 
 // calculateCompsValue calculates fair value using Comparable Company Analysis
 func (c *Calculator) calculateCompsValue(stockData *models.StockData) float64 {
+	return c.compsBreakdown(stockData).Value
+}
+
+// compsBreakdown runs the Comps model and records its intermediate values
+func (c *Calculator) compsBreakdown(stockData *models.StockData) CompsBreakdown {
 	eps := stockData.EPS
 	peRatio := stockData.PERatio
 	
+	// Anchor on the median P/E of the stock's peers when it has a group
+	var peers *models.PeerGroup
+	if stockData.Peers != nil && stockData.Peers.MedianPE > 0 {
+		peRatio = stockData.Peers.MedianPE
+		peers = stockData.Peers
+	}
+	breakdown := CompsBreakdown{PERatio: peRatio, Peers: peers}
+	
+	// Depreciation of property depresses the earnings of REITs, so they
+	// are valued on funds from operations at the median P/FFO of their
+	// peers, or of their sector when too few peers report FFO
+	if ffo := stockData.FFOPerShare; stockData.IsREIT() && ffo > 0 {
+		eps = ffo
+		breakdown = CompsBreakdown{PERatio: models.SectorMedianPFFO(stockData.Sector), FFOPerShare: ffo, Peers: stockData.Peers}
+		if stockData.Peers != nil && stockData.Peers.MedianPFFO > 0 {
+			breakdown.PERatio = stockData.Peers.MedianPFFO
+		}
+	}
+	
 	// Apply conservative adjustments to P/E ratio
-	conservativePE := peRatio * c.compsParams.PEConservativeFactor
+	conservativePE := breakdown.PERatio * c.compsParams.PEConservativeFactor
 	conservativePE = math.Max(c.compsParams.MinPERatio, math.Min(conservativePE, c.compsParams.MaxPERatio))
+	breakdown.ConservativePE = conservativePE
 	
-	// If EPS is negative, use a conservative approach
+	// If EPS is negative, replace it as the fallback policy says; without
+	// a replacement the value falls to the floor
 	if eps <= 0 {
-		eps = 1.0 // Conservative fallback
+		eps, breakdown.fallbackErr = c.fallbacks.ReplaceEPS(stockData)
+		breakdown.UsedFallbackEPS = true
+		breakdown.FallbackPolicy = c.fallbacks.EPS.Policy.Resolved()
 	}
+	breakdown.EPS = eps
 	
 	// Calculate value using P/E multiple
 	compsValue := eps * conservativePE
+	if c.decimal {
+		compsValue = models.MoneyFromFloat(eps).Mul(models.MoneyFromFloat(conservativePE)).Float()
+	}
 	
-	// Use book value as floor
-	return math.Max(compsValue, stockData.BookValue)
+	// Hold the value to the floor
+	floor := c.floor.Value(stockData)
+	breakdown.FlooredAtBook = compsValue < floor
+	breakdown.Value = math.Max(compsValue, floor)
+	return breakdown
 }
 
 // SetDCFParameters allows customization of DCF parameters
@@ -142,11 +471,64 @@ func (c *Calculator) SetCompsParameters(params models.CompsParameters) {
 	c.compsParams = params
 }
 
+// SetSentimentParameters sets how far extreme news sentiment moves fair
+// values; the zero value leaves them as they are
+func (c *Calculator) SetSentimentParameters(params models.SentimentParameters) {
+	c.sentiment = params
+}
+
+// SetSizeParameters sets how far fair values of small and thinly traded
+// companies are lowered; the zero value leaves them as they are
+func (c *Calculator) SetSizeParameters(params models.SizeParameters) {
+	c.size = params
+}
+
+// SetCommodityParameters sets the oil price scenario the FCF of energy
+// companies is scaled to; the zero value leaves it as it is
+func (c *Calculator) SetCommodityParameters(params models.CommodityParameters) {
+	c.commodity = params
+}
+
+// SetADRParameters sets the withholding tax and depositary fees taken
+// from the dividends of ADRs; the zero value leaves them as they are
+func (c *Calculator) SetADRParameters(params models.ADRParameters) {
+	c.adr = params
+}
+
 // SetWeights allows customization of valuation weights
 func (c *Calculator) SetWeights(weights models.ValuationWeights) {
 	c.weights = weights
 }
 
+// SetBookFloor sets the value fair values are never lowered below; the
+// zero value is models.FloorTangibleBook
+func (c *Calculator) SetBookFloor(floor models.BookFloor) {
+	c.floor = floor
+}
+
+// SetFallbackParameters sets how a free cash flow or EPS that is not
+// positive is replaced; the zero value replaces them with constants
+func (c *Calculator) SetFallbackParameters(params models.FallbackParameters) {
+	c.fallbacks = params
+}
+
+// CheckFallbacks returns an error wrapping models.ErrInsufficientData when
+// the free cash flow or EPS stockData would be valued on is not positive
+// and the fallback policies do not replace it, so the stock is reported
+// rather than valued at its floor
+func (c *Calculator) CheckFallbacks(stockData *models.StockData) error {
+	if err := c.dcfBreakdown(stockData).fallbackErr; err != nil {
+		return err
+	}
+	return c.compsBreakdown(stockData).fallbackErr
+}
+
+// SetDecimalMoney switches prices and per-share values to exact decimal
+// arithmetic, so results carry no binary floating point artifacts
+func (c *Calculator) SetDecimalMoney(enabled bool) {
+	c.decimal = enabled
+}
+
 // GetDCFParameters returns current DCF parameters
 func (c *Calculator) GetDCFParameters() models.DCFParameters {
 	return c.dcfParams
@@ -0,0 +1,113 @@
+package valuation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lesnerd/fair-stock-value/go/models"
+)
+
+// benchStockData returns complete stock data for ticker at price
+func benchStockData(ticker string, price float64) *models.StockData {
+	return &models.StockData{
+		Ticker:            ticker,
+		CompanyName:       ticker + " Inc.",
+		CurrentPrice:      price,
+		FCFPerShare:       price / 25,
+		EPS:               price / 20,
+		BookValue:         price / 8,
+		Sector:            models.SectorTechnology,
+		GrowthRate:        0.08,
+		PERatio:           20,
+		MarketCap:         int64(price * 1e9),
+		SharesOutstanding: 1_000_000_000,
+		DividendPerShare:  price / 100,
+		Beta:              1.1,
+		TotalDebt:         1e10,
+		Cash:              5e9,
+		EBITDA:            price * 1e8,
+		Revenue:           price * 4e8,
+		TangibleBookValue: price / 10,
+		Currency:          "USD",
+		FetchTime:         time.Now(),
+	}
+}
+
+// reitStockData returns a REIT at price with FFO per share ffo
+func reitStockData(ticker string, price, ffo float64) *models.StockData {
+	stock := benchStockData(ticker, price)
+	stock.Sector = models.SectorRealEstate
+	stock.FFOPerShare = ffo
+	return stock
+}
+
+func TestREITCompsOnSectorPFFO(t *testing.T) {
+	calculator := NewCalculator()
+	cheap := calculator.Explain(reitStockData("O", 40, 4)).Comps
+	dear := calculator.Explain(reitStockData("O", 80, 4)).Comps
+
+	if want := models.SectorMedianPFFO(models.SectorRealEstate); cheap.PERatio != want {
+		t.Errorf("P/FFO %.2f, want the sector median %.2f", cheap.PERatio, want)
+	}
+	if cheap.Value != dear.Value {
+		t.Errorf("Comps value %.2f at a price of 40 and %.2f at 80, want it not to follow the price", cheap.Value, dear.Value)
+	}
+	if cheap.FFOPerShare != 4 || cheap.EPS != 4 {
+		t.Errorf("valued on FFO %.2f and EPS %.2f, want both 4", cheap.FFOPerShare, cheap.EPS)
+	}
+}
+
+func TestREITCompsOnPeerPFFO(t *testing.T) {
+	stock := reitStockData("O", 60, 4)
+	stock.Peers = &models.PeerGroup{
+		Basis:      models.PeerBasisIndustry,
+		MedianPE:   30,
+		MedianPFFO: 12,
+		Peers:      []models.Peer{{Ticker: "NNN", PFFO: 11}, {Ticker: "ADC", PFFO: 12}, {Ticker: "EPRT", PFFO: 13}},
+	}
+	comps := NewCalculator().Explain(stock).Comps
+
+	if comps.PERatio != 12 {
+		t.Errorf("P/FFO %.2f, want the peers' median 12", comps.PERatio)
+	}
+	if comps.Peers != stock.Peers {
+		t.Errorf("breakdown peers %v, want the stock's", comps.Peers)
+	}
+
+	stock.Peers.MedianPFFO = 0
+	comps = NewCalculator().Explain(stock).Comps
+	if want := models.SectorMedianPFFO(stock.Sector); comps.PERatio != want {
+		t.Errorf("P/FFO %.2f with peers reporting no FFO, want the sector median %.2f", comps.PERatio, want)
+	}
+	if comps.Peers != stock.Peers {
+		t.Errorf("breakdown peers %v with peers reporting no FFO, want the stock's", comps.Peers)
+	}
+}
+
+func BenchmarkCalculateFairValue(b *testing.B) {
+	calculator := NewCalculator()
+	stock := benchStockData("AAPL", 180)
+	b.ReportAllocs()
+	for b.Loop() {
+		calculator.CalculateFairValue(stock)
+	}
+}
+
+func BenchmarkCalculateDecimal(b *testing.B) {
+	calculator := NewCalculator()
+	calculator.SetDecimalMoney(true)
+	stock := benchStockData("AAPL", 180)
+	b.ReportAllocs()
+	for b.Loop() {
+		calculator.CalculateFairValue(stock)
+	}
+}
+
+func BenchmarkCalculateExplain(b *testing.B) {
+	calculator := NewCalculator()
+	stock := benchStockData("AAPL", 180)
+	b.ReportAllocs()
+	for b.Loop() {
+		calculator.Explain(stock)
+	}
+}